@@ -7,9 +7,12 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/pow"
 	"github.com/Kash-Protocol/kashd/infrastructure/config"
 	"github.com/Kash-Protocol/kashd/infrastructure/db/database"
-	"github.com/Kash-Protocol/kashd/infrastructure/db/database/ldb"
+	_ "github.com/Kash-Protocol/kashd/infrastructure/db/database/boltdb" // Register the bbolt backend
+	_ "github.com/Kash-Protocol/kashd/infrastructure/db/database/ldb"    // Register the leveldb backend
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database/memdb"
 	"github.com/Kash-Protocol/kashd/infrastructure/logger"
 	"github.com/Kash-Protocol/kashd/infrastructure/os/execenv"
 	"github.com/Kash-Protocol/kashd/infrastructure/os/limits"
@@ -94,6 +97,17 @@ func (app *kashdApp) main(startedChan chan<- struct{}) error {
 		return nil
 	}
 
+	// Grow the RandomX VM pool so that proof of work is verified on all
+	// the VMs requested in the configuration
+	if app.cfg.RandomXVMs > pow.GlobalPoolSize() {
+		err := pow.ResizeGlobalPool(app.cfg.RandomXVMs)
+		if err != nil {
+			log.Errorf("Resizing the RandomX VM pool failed: %+v", err)
+			return err
+		}
+		log.Infof("Verifying proof of work with %d RandomX VMs", app.cfg.RandomXVMs)
+	}
+
 	if app.cfg.ResetDatabase {
 		err := removeDatabase(app.cfg)
 		if err != nil {
@@ -117,6 +131,25 @@ func (app *kashdApp) main(startedChan chan<- struct{}) error {
 		}
 	}()
 
+	if app.cfg.DryRunMigrations {
+		log.Infof("Finished the dry run of the database migrations")
+		return nil
+	}
+
+	if app.cfg.ExportSnapshot != "" {
+		domain, err := newDomain(app.cfg, databaseContext)
+		if err != nil {
+			log.Errorf("Unable to load the DAG: %+v", err)
+			return err
+		}
+		err = exportSnapshot(app.cfg, domain)
+		if err != nil {
+			log.Errorf("Exporting the snapshot failed: %+v", err)
+			return err
+		}
+		return nil
+	}
+
 	// Return now if an interrupt signal was triggered.
 	if signal.InterruptRequested(interrupt) {
 		return nil
@@ -172,16 +205,43 @@ func removeDatabase(cfg *config.Config) error {
 }
 
 func openDB(cfg *config.Config) (database.Database, error) {
+	if cfg.DbType == memdb.DBType {
+		log.Warnf("Using an in-memory database. All data will be lost on shutdown")
+		return database.Open(cfg.DbType, "", 0)
+	}
+
 	dbPath := databasePath(cfg)
 
-	err := checkDatabaseVersion(dbPath)
+	databaseVersion, err := checkDatabaseVersion(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	err = checkDatabaseType(dbPath, cfg.DbType)
+	if err != nil {
+		return nil, err
+	}
+
+	// The backup has to be taken while the database is closed
+	if databaseVersion < currentDatabaseVersion && !cfg.DryRunMigrations {
+		if cfg.NoDBBackup {
+			log.Warnf("Migrating the database from version %d without backing it up", databaseVersion)
+		} else {
+			err = backupDatabase(dbPath, databaseVersion)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	log.Infof("Loading %s database from '%s'", cfg.DbType, dbPath)
+	db, err := database.Open(cfg.DbType, dbPath, leveldbCacheSizeMiB)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Infof("Loading database from '%s'", dbPath)
-	db, err := ldb.NewLevelDB(dbPath, leveldbCacheSizeMiB)
+	err = migrateDatabase(db, dbPath, databaseVersion, cfg.DryRunMigrations)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
@@ -17,6 +17,11 @@ const MaxMessagePayload = 1024 * 1024 * 32 // 32MB
 type MessageCommand uint32
 
 func (cmd MessageCommand) String() string {
+	return fmt.Sprintf("%s [code %d]", cmd.Name(), uint8(cmd))
+}
+
+// Name returns the name of the command, without its code
+func (cmd MessageCommand) Name() string {
 	cmdString, ok := ProtocolMessageCommandToString[cmd]
 	if !ok {
 		cmdString, ok = RPCMessageCommandToString[cmd]
@@ -24,7 +29,7 @@ func (cmd MessageCommand) String() string {
 	if !ok {
 		cmdString = "unknown command"
 	}
-	return fmt.Sprintf("%s [code %d]", cmdString, uint8(cmd))
+	return cmdString
 }
 
 // Commands used in kaspa message headers which describe the type of message.
@@ -163,6 +168,10 @@ const (
 	CmdGetMempoolEntriesByAddressesResponseMessage
 	CmdGetCoinSupplyRequestMessage
 	CmdGetCoinSupplyResponseMessage
+	CmdGetLogLevelsRequestMessage
+	CmdGetLogLevelsResponseMessage
+	CmdSetLogLevelRequestMessage
+	CmdSetLogLevelResponseMessage
 )
 
 // ProtocolMessageCommandToString maps all MessageCommands to their string representation
@@ -300,6 +309,10 @@ var RPCMessageCommandToString = map[MessageCommand]string{
 	CmdGetMempoolEntriesByAddressesResponseMessage:                "GetMempoolEntriesByAddressesResponse",
 	CmdGetCoinSupplyRequestMessage:                                "GetCoinSupplyRequest",
 	CmdGetCoinSupplyResponseMessage:                               "GetCoinSupplyResponse",
+	CmdGetLogLevelsRequestMessage:                                 "GetLogLevelsRequest",
+	CmdGetLogLevelsResponseMessage:                                "GetLogLevelsResponse",
+	CmdSetLogLevelRequestMessage:                                  "SetLogLevelRequest",
+	CmdSetLogLevelResponseMessage:                                 "SetLogLevelResponse",
 }
 
 // Message is an interface that describes a kaspa message. A type that
@@ -5,26 +5,102 @@
 package appmessage
 
 import (
+	"bytes"
+	"encoding/base32"
 	"net"
+	"strconv"
+	"strings"
 
 	"github.com/Kash-Protocol/kashd/util/mstime"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
 )
 
+// NetAddressNetwork is the network an address belongs to. The values match
+// the network IDs defined in BIP155 (addrv2).
+type NetAddressNetwork uint8
+
+const (
+	// NetAddressNetworkIP is an IPv4 or IPv6 address. It is the zero value,
+	// so that addresses relayed by peers that predate overlay networks are
+	// interpreted as IP addresses.
+	NetAddressNetworkIP NetAddressNetwork = 0
+
+	// NetAddressNetworkTorV3 is a Tor v3 onion service address
+	NetAddressNetworkTorV3 NetAddressNetwork = 4
+
+	// NetAddressNetworkI2P is an I2P b32 address
+	NetAddressNetworkI2P NetAddressNetwork = 5
+)
+
+const (
+	// TorV3AddressLength is the length of a Tor v3 address - an ed25519 public key
+	TorV3AddressLength = 32
+
+	// I2PAddressLength is the length of an I2P address - a SHA256 hash of the destination
+	I2PAddressLength = 32
+
+	torV3Version    = 3
+	torV3HostSuffix = ".onion"
+	i2pHostSuffix   = ".b32.i2p"
+)
+
+var lowercaseBase32Encoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+func (network NetAddressNetwork) String() string {
+	switch network {
+	case NetAddressNetworkIP:
+		return "ip"
+	case NetAddressNetworkTorV3:
+		return "onion"
+	case NetAddressNetworkI2P:
+		return "i2p"
+	default:
+		return "unknown(" + strconv.Itoa(int(network)) + ")"
+	}
+}
+
 // NetAddress defines information about a peer on the network including the time
 // it was last seen, the services it supports, its IP address, and port.
 type NetAddress struct {
 	// Last time the address was seen.
 	Timestamp mstime.Time
 
-	// IP address of the peer.
+	// Network is the network the address belongs to.
+	Network NetAddressNetwork
+
+	// IP address of the peer. Only set for NetAddressNetworkIP.
 	IP net.IP
 
+	// Address is the raw address of the peer in overlay networks: the
+	// public key for Tor v3, or the destination hash for I2P.
+	Address []byte
+
 	// Port the peer is using. This is encoded in big endian on the appmessage
 	// which differs from most everything else.
 	Port uint16
 }
 
-// TCPAddress converts the NetAddress to *net.TCPAddr
+// IsOverlay returns whether the address belongs to an overlay network, such as Tor or I2P
+func (na *NetAddress) IsOverlay() bool {
+	return na.Network != NetAddressNetworkIP
+}
+
+// Host returns the host part of the address: the IP for IP addresses, or the
+// .onion/.b32.i2p host name for overlay addresses
+func (na *NetAddress) Host() string {
+	switch na.Network {
+	case NetAddressNetworkTorV3:
+		return encodeTorV3Host(na.Address)
+	case NetAddressNetworkI2P:
+		return lowercaseBase32Encoding.EncodeToString(na.Address) + i2pHostSuffix
+	default:
+		return na.IP.String()
+	}
+}
+
+// TCPAddress converts the NetAddress to *net.TCPAddr.
+// Overlay addresses have no TCP representation, and result in an empty IP.
 func (na *NetAddress) TCPAddress() *net.TCPAddr {
 	return &net.TCPAddr{
 		IP:   na.IP,
@@ -59,6 +135,100 @@ func NewNetAddress(addr *net.TCPAddr) *NetAddress {
 	return NewNetAddressIPPort(addr.IP, uint16(addr.Port))
 }
 
+// NewNetAddressOverlay returns a new NetAddress in the given overlay network,
+// using the provided raw address and port
+func NewNetAddressOverlay(network NetAddressNetwork, address []byte, port uint16) *NetAddress {
+	return &NetAddress{
+		Timestamp: mstime.Now(),
+		Network:   network,
+		Address:   address,
+		Port:      port,
+	}
+}
+
+// ParseNetAddress parses a <host>:<port> string, where host is either an IP
+// address, a Tor v3 .onion host or an I2P .b32.i2p host
+func ParseNetAddress(address string) (*NetAddress, error) {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid port in %s", address)
+	}
+	return ParseNetAddressHost(host, uint16(port))
+}
+
+// ParseNetAddressHost returns a new NetAddress out of the given host and port,
+// where host is either an IP address, a Tor v3 .onion host or an I2P .b32.i2p host
+func ParseNetAddressHost(host string, port uint16) (*NetAddress, error) {
+	lowercaseHost := strings.ToLower(host)
+	switch {
+	case strings.HasSuffix(lowercaseHost, torV3HostSuffix):
+		publicKey, err := decodeTorV3Host(lowercaseHost)
+		if err != nil {
+			return nil, err
+		}
+		return NewNetAddressOverlay(NetAddressNetworkTorV3, publicKey, port), nil
+	case strings.HasSuffix(lowercaseHost, i2pHostSuffix):
+		hash, err := lowercaseBase32Encoding.DecodeString(strings.TrimSuffix(lowercaseHost, i2pHostSuffix))
+		if err != nil || len(hash) != I2PAddressLength {
+			return nil, errors.Errorf("invalid I2P address %s", host)
+		}
+		return NewNetAddressOverlay(NetAddressNetworkI2P, hash, port), nil
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, errors.Errorf("%s is neither an IP address nor an overlay network address", host)
+	}
+	return NewNetAddressIPPort(ip, port), nil
+}
+
+// IsOverlayHost returns whether the given host name belongs to an overlay network
+func IsOverlayHost(host string) bool {
+	lowercaseHost := strings.ToLower(host)
+	return strings.HasSuffix(lowercaseHost, torV3HostSuffix) || strings.HasSuffix(lowercaseHost, i2pHostSuffix)
+}
+
+// encodeTorV3Host encodes the given public key as specified in rend-spec-v3:
+// base32(PUBKEY | CHECKSUM | VERSION) + ".onion"
+func encodeTorV3Host(publicKey []byte) string {
+	checksum := torV3Checksum(publicKey)
+	encoded := make([]byte, 0, TorV3AddressLength+len(checksum)+1)
+	encoded = append(encoded, publicKey...)
+	encoded = append(encoded, checksum...)
+	encoded = append(encoded, torV3Version)
+	return lowercaseBase32Encoding.EncodeToString(encoded) + torV3HostSuffix
+}
+
+func decodeTorV3Host(host string) ([]byte, error) {
+	decoded, err := lowercaseBase32Encoding.DecodeString(strings.TrimSuffix(host, torV3HostSuffix))
+	if err != nil || len(decoded) != TorV3AddressLength+3 {
+		return nil, errors.Errorf("invalid Tor v3 address %s", host)
+	}
+	publicKey := decoded[:TorV3AddressLength]
+	checksum := decoded[TorV3AddressLength : TorV3AddressLength+2]
+	version := decoded[TorV3AddressLength+2]
+	if version != torV3Version {
+		return nil, errors.Errorf("unsupported onion address version %d in %s", version, host)
+	}
+	if !bytes.Equal(checksum, torV3Checksum(publicKey)) {
+		return nil, errors.Errorf("invalid checksum in onion address %s", host)
+	}
+	return publicKey, nil
+}
+
+// torV3Checksum returns H(".onion checksum" | PUBKEY | VERSION)[:2]
+func torV3Checksum(publicKey []byte) []byte {
+	hasher := sha3.New256()
+	hasher.Write([]byte(".onion checksum"))
+	hasher.Write(publicKey)
+	hasher.Write([]byte{torV3Version})
+	return hasher.Sum(nil)[:2]
+}
+
 func (na NetAddress) String() string {
-	return na.TCPAddress().String()
+	return net.JoinHostPort(na.Host(), strconv.Itoa(int(na.Port)))
 }
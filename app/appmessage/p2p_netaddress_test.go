@@ -26,3 +26,61 @@ func TestNetAddress(t *testing.T) {
 			port)
 	}
 }
+
+// TestParseNetAddress tests parsing IP, Tor v3 and I2P addresses and
+// converting them back to strings.
+func TestParseNetAddress(t *testing.T) {
+	tests := []struct {
+		address         string
+		expectedNetwork NetAddressNetwork
+		expectedString  string
+		expectedError   bool
+	}{
+		{address: "127.0.0.1:16111", expectedNetwork: NetAddressNetworkIP, expectedString: "127.0.0.1:16111"},
+		{address: "[::1]:16111", expectedNetwork: NetAddressNetworkIP, expectedString: "[::1]:16111"},
+		{
+			address:         "pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion:16111",
+			expectedNetwork: NetAddressNetworkTorV3,
+			expectedString:  "pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion:16111",
+		},
+		{
+			address:         "DUCKDUCKGOGG42XJOC72X3SJASOWOARFBGCMVFIMAFTT6TWAGSWZCZAD.onion:443",
+			expectedNetwork: NetAddressNetworkTorV3,
+			expectedString:  "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.onion:443",
+		},
+		{
+			address:         "ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p:16111",
+			expectedNetwork: NetAddressNetworkI2P,
+			expectedString:  "ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p:16111",
+		},
+		// Bad checksum
+		{address: "pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryc.onion:16111", expectedError: true},
+		// Tor v2 addresses are no longer supported
+		{address: "expyuzz4wqqyqhjn.onion:16111", expectedError: true},
+		{address: "tooshort.b32.i2p:16111", expectedError: true},
+		{address: "example.com:16111", expectedError: true},
+		{address: "127.0.0.1", expectedError: true},
+	}
+
+	for _, test := range tests {
+		netAddress, err := ParseNetAddress(test.address)
+		if test.expectedError {
+			if err == nil {
+				t.Errorf("ParseNetAddress(%s): expected an error but got none", test.address)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseNetAddress(%s): unexpected error: %s", test.address, err)
+			continue
+		}
+		if netAddress.Network != test.expectedNetwork {
+			t.Errorf("ParseNetAddress(%s): wrong network - got %s, want %s",
+				test.address, netAddress.Network, test.expectedNetwork)
+		}
+		if netAddress.String() != test.expectedString {
+			t.Errorf("ParseNetAddress(%s): wrong string - got %s, want %s",
+				test.address, netAddress.String(), test.expectedString)
+		}
+	}
+}
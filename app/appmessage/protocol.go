@@ -13,7 +13,7 @@ import (
 const (
 	// DefaultServices describes the default services that are supported by
 	// the server.
-	DefaultServices = SFNodeNetwork | SFNodeBloom | SFNodeCF
+	DefaultServices = SFNodeNetwork | SFNodeBloom | SFNodeCF | SFNodeAddrV2
 )
 
 // ServiceFlag identifies services supported by a kaspa peer.
@@ -41,6 +41,10 @@ const (
 	// SFNodeCF is a flag used to indicate a peer supports committed
 	// filters (CFs).
 	SFNodeCF
+
+	// SFNodeAddrV2 is a flag used to indicate a peer understands overlay
+	// network addresses, such as Tor v3 and I2P, in address messages.
+	SFNodeAddrV2
 )
 
 // Map of service flags back to their constant names for pretty printing.
@@ -51,6 +55,7 @@ var sfStrings = map[ServiceFlag]string{
 	SFNodeXthin:   "SFNodeXthin",
 	SFNodeBit5:    "SFNodeBit5",
 	SFNodeCF:      "SFNodeCF",
+	SFNodeAddrV2:  "SFNodeAddrV2",
 }
 
 // orderedSFStrings is an ordered list of service flags from highest to
@@ -62,6 +67,7 @@ var orderedSFStrings = []ServiceFlag{
 	SFNodeXthin,
 	SFNodeBit5,
 	SFNodeCF,
+	SFNodeAddrV2,
 }
 
 // String returns the ServiceFlag in human-readable form.
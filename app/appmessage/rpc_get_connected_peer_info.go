@@ -47,4 +47,6 @@ type GetConnectedPeerInfoMessage struct {
 	AdvertisedProtocolVersion uint32
 	TimeConnected             int64
 	IsIBDPeer                 bool
+	TransportSecurity         string
+	IdentityKey               string
 }
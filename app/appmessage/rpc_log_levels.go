@@ -0,0 +1,81 @@
+package appmessage
+
+// GetLogLevelsRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetLogLevelsRequestMessage struct {
+	baseMessage
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetLogLevelsRequestMessage) Command() MessageCommand {
+	return CmdGetLogLevelsRequestMessage
+}
+
+// NewGetLogLevelsRequestMessage returns a instance of the message
+func NewGetLogLevelsRequestMessage() *GetLogLevelsRequestMessage {
+	return &GetLogLevelsRequestMessage{}
+}
+
+// SubsystemLogLevel is the log level of a logging subsystem
+type SubsystemLogLevel struct {
+	Subsystem string
+	Level     string
+}
+
+// GetLogLevelsResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetLogLevelsResponseMessage struct {
+	baseMessage
+	Subsystems []*SubsystemLogLevel
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetLogLevelsResponseMessage) Command() MessageCommand {
+	return CmdGetLogLevelsResponseMessage
+}
+
+// NewGetLogLevelsResponseMessage returns a instance of the message
+func NewGetLogLevelsResponseMessage(subsystems []*SubsystemLogLevel) *GetLogLevelsResponseMessage {
+	return &GetLogLevelsResponseMessage{
+		Subsystems: subsystems,
+	}
+}
+
+// SetLogLevelRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type SetLogLevelRequestMessage struct {
+	baseMessage
+	LogLevel string
+}
+
+// Command returns the protocol command string for the message
+func (msg *SetLogLevelRequestMessage) Command() MessageCommand {
+	return CmdSetLogLevelRequestMessage
+}
+
+// NewSetLogLevelRequestMessage returns a instance of the message
+func NewSetLogLevelRequestMessage(logLevel string) *SetLogLevelRequestMessage {
+	return &SetLogLevelRequestMessage{
+		LogLevel: logLevel,
+	}
+}
+
+// SetLogLevelResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type SetLogLevelResponseMessage struct {
+	baseMessage
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *SetLogLevelResponseMessage) Command() MessageCommand {
+	return CmdSetLogLevelResponseMessage
+}
+
+// NewSetLogLevelResponseMessage returns a instance of the message
+func NewSetLogLevelResponseMessage() *SetLogLevelResponseMessage {
+	return &SetLogLevelResponseMessage{}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/Kash-Protocol/kashd/domain/utxoindex"
 	"github.com/Kash-Protocol/kashd/infrastructure/config"
 	infrastructuredatabase "github.com/Kash-Protocol/kashd/infrastructure/db/database"
+	"github.com/Kash-Protocol/kashd/infrastructure/metrics"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/addressmanager"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/connmanager"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter"
@@ -30,6 +31,7 @@ type ComponentManager struct {
 	rpcManager        *rpc.Manager
 	connectionManager *connmanager.ConnectionManager
 	netAdapter        *netadapter.NetAdapter
+	metricsServer     *metrics.Server
 
 	started, shutdown int32
 }
@@ -49,6 +51,13 @@ func (a *ComponentManager) Start() {
 	}
 
 	a.connectionManager.Start()
+
+	if a.metricsServer != nil {
+		err := a.metricsServer.Start()
+		if err != nil {
+			panics.Exit(log, fmt.Sprintf("Error starting the metrics server: %+v", err))
+		}
+	}
 }
 
 // Stop gracefully shuts down all the kashd services.
@@ -61,6 +70,13 @@ func (a *ComponentManager) Stop() {
 
 	log.Warnf("Kashd shutting down")
 
+	if a.metricsServer != nil {
+		err := a.metricsServer.Stop()
+		if err != nil {
+			log.Errorf("Error stopping the metrics server: %+v", err)
+		}
+	}
+
 	a.connectionManager.Stop()
 
 	err := a.netAdapter.Stop()
@@ -79,20 +95,18 @@ func (a *ComponentManager) Stop() {
 func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database, interrupt chan<- struct{}) (
 	*ComponentManager, error) {
 
-	consensusConfig := consensus.Config{
-		Params:                          *cfg.ActiveNetParams,
-		IsArchival:                      cfg.IsArchivalNode,
-		EnableSanityCheckPruningUTXOSet: cfg.EnableSanityCheckPruningUTXOSet,
-	}
-	mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
-	mempoolConfig.MaximumOrphanTransactionCount = cfg.MaxOrphanTxs
-	mempoolConfig.MinimumRelayTransactionFee = cfg.MinRelayTxFee
-
-	domain, err := domain.New(&consensusConfig, mempoolConfig, db)
+	domain, err := newDomain(cfg, db)
 	if err != nil {
 		return nil, err
 	}
 
+	if cfg.ImportSnapshot != "" {
+		err = importSnapshot(cfg, domain)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	netAdapter, err := netadapter.NewNetAdapter(cfg)
 	if err != nil {
 		return nil, err
@@ -123,6 +137,15 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 	}
 	rpcManager := setupRPC(cfg, domain, netAdapter, protocolManager, connectionManager, addressManager, utxoIndex, domain.ConsensusEventsChannel(), interrupt)
 
+	var metricsServer *metrics.Server
+	if cfg.MetricsListen != "" {
+		metricsServer, err = metrics.NewServer(cfg.MetricsListen,
+			newNodeStateCollector(domain, protocolManager, utxoIndex, db))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &ComponentManager{
 		cfg:               cfg,
 		protocolManager:   protocolManager,
@@ -130,10 +153,24 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 		connectionManager: connectionManager,
 		netAdapter:        netAdapter,
 		addressManager:    addressManager,
+		metricsServer:     metricsServer,
 	}, nil
 
 }
 
+func newDomain(cfg *config.Config, db infrastructuredatabase.Database) (domain.Domain, error) {
+	consensusConfig := consensus.Config{
+		Params:                          *cfg.ActiveNetParams,
+		IsArchival:                      cfg.IsArchivalNode,
+		EnableSanityCheckPruningUTXOSet: cfg.EnableSanityCheckPruningUTXOSet,
+	}
+	mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
+	mempoolConfig.MaximumOrphanTransactionCount = cfg.MaxOrphanTxs
+	mempoolConfig.MinimumRelayTransactionFee = cfg.MinRelayTxFee
+
+	return domain.New(&consensusConfig, mempoolConfig, db)
+}
+
 func setupRPC(
 	cfg *config.Config,
 	domain domain.Domain,
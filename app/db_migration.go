@@ -0,0 +1,444 @@
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Kash-Protocol/kashd/domain/utxoindex"
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// databaseMigration upgrades the database from version toVersion-1 to toVersion.
+//
+// Migrations must be resumable: kashd may be shut down in the middle of one,
+// in which case the migration runs again from the start on the next startup,
+// and must complete the work that was left undone. migrateBucket takes care of
+// this for migrations that rewrite a bucket entry by entry.
+type databaseMigration struct {
+	toVersion   int
+	description string
+	migrate     func(m *migrationContext) error
+}
+
+// databaseMigrations are all the database migrations, ordered by toVersion.
+// To change the serialization of stored data, bump currentDatabaseVersion and
+// append a migration that converts the data of the previous version.
+var databaseMigrations = []*databaseMigration{
+	{
+		toVersion:   2,
+		description: "store the UTXO index entries without their script public keys",
+		migrate: func(m *migrationContext) error {
+			return migrateBucket(m, utxoindex.EntriesBucket(), removeUTXOIndexEntryScriptPublicKey)
+		},
+	},
+}
+
+// removeUTXOIndexEntryScriptPublicKey is the migrateEntryFunc of the migration to
+// version 2. The script public key of a UTXO index entry is already part of its key.
+func removeUTXOIndexEntryScriptPublicKey(_ *database.Key, value []byte) ([]byte, error) {
+	return utxoindex.RemoveScriptPublicKeyFromSerializedUTXOEntry(value)
+}
+
+// migrationBatchSize is the number of entries migrateBucket processes
+// within a single database transaction
+var migrationBatchSize = 10_000
+
+// migrationProgressLogInterval is the number of entries after which
+// migrateBucket logs its progress
+const migrationProgressLogInterval = 100_000
+
+// migrationProgressKey holds the key of the last entry migrateBucket has
+// processed, so that an interrupted migration doesn't process it twice
+var migrationProgressKey = database.MakeBucket(nil).Key([]byte("migration-progress"))
+
+// migrationContext is passed to migrations in order to access the database
+type migrationContext struct {
+	db        database.Database
+	migration *databaseMigration
+	isDryRun  bool
+
+	// changedEntries is the number of entries the migration has
+	// changed or, in dry-run mode, would have changed
+	changedEntries int
+}
+
+// commit commits the given transaction, or rolls it back in dry-run mode
+func (m *migrationContext) commit(dbTx database.Transaction) error {
+	if m.isDryRun {
+		return dbTx.Rollback()
+	}
+	return dbTx.Commit()
+}
+
+// migrateEntryFunc returns the new value of the entry with the given key.
+// Returning a nil value deletes the entry, and returning the given value
+// leaves it unchanged.
+type migrateEntryFunc func(key *database.Key, value []byte) ([]byte, error)
+
+// migrateBucket rewrites every entry in bucket using migrateEntry. The entries
+// are processed in batches, each of which is committed along with the key of
+// its last entry. This lets a resumed migration skip the entries it had
+// already processed, so migrateEntry need not be idempotent.
+func migrateBucket(m *migrationContext, bucket *database.Bucket, migrateEntry migrateEntryFunc) error {
+	lastProcessedKey, err := m.readProgress()
+	if err != nil {
+		return err
+	}
+	if lastProcessedKey != nil {
+		log.Infof("Resuming migration to version %d after key %x", m.migration.toVersion, lastProcessedKey)
+	}
+
+	cursor, err := m.db.Cursor(bucket)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	dbTx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTx.RollbackUnlessClosed()
+
+	processedEntries := 0
+	batchEntries := 0
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		if lastProcessedKey != nil && bytes.Compare(key.Bytes(), lastProcessedKey) <= 0 {
+			continue
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+
+		newValue, err := migrateEntry(key, value)
+		if err != nil {
+			return err
+		}
+		if newValue == nil {
+			err = dbTx.Delete(key)
+			m.changedEntries++
+		} else if !bytes.Equal(newValue, value) {
+			err = dbTx.Put(key, newValue)
+			m.changedEntries++
+		}
+		if err != nil {
+			return err
+		}
+
+		processedEntries++
+		batchEntries++
+		if batchEntries == migrationBatchSize {
+			err = m.writeProgress(dbTx, key.Bytes())
+			if err != nil {
+				return err
+			}
+			err = m.commit(dbTx)
+			if err != nil {
+				return err
+			}
+			dbTx, err = m.db.Begin()
+			if err != nil {
+				return err
+			}
+			batchEntries = 0
+		}
+		if processedEntries%migrationProgressLogInterval == 0 {
+			log.Infof("Migration to version %d: processed %d entries", m.migration.toVersion, processedEntries)
+		}
+	}
+
+	// The migration of the bucket is complete, so the next migration
+	// to call migrateBucket must start from scratch
+	err = dbTx.Delete(migrationProgressKey)
+	if err != nil {
+		return err
+	}
+	return m.commit(dbTx)
+}
+
+func (m *migrationContext) readProgress() ([]byte, error) {
+	progress, err := m.db.Get(migrationProgressKey)
+	if database.IsNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(progress) < 4 {
+		return nil, errors.Errorf("malformed migration progress %x", progress)
+	}
+	// Progress recorded by a different migration can only be left behind
+	// by a bug, since a migration only completes once its progress is deleted
+	progressVersion := int(binary.LittleEndian.Uint32(progress))
+	if progressVersion != m.migration.toVersion {
+		return nil, errors.Errorf("found the progress of the migration to version %d while "+
+			"migrating to version %d", progressVersion, m.migration.toVersion)
+	}
+	return progress[4:], nil
+}
+
+func (m *migrationContext) writeProgress(dbTx database.Transaction, lastProcessedKey []byte) error {
+	progress := make([]byte, 4+len(lastProcessedKey))
+	binary.LittleEndian.PutUint32(progress, uint32(m.migration.toVersion))
+	copy(progress[4:], lastProcessedKey)
+	return dbTx.Put(migrationProgressKey, progress)
+}
+
+// migrateDatabase brings the database in dbPath from databaseVersion up to
+// currentDatabaseVersion.
+//
+// Before the first migration runs, a marker file recording databaseVersion is
+// created, and it is removed only once all the migrations complete. While it
+// exists the database is partially migrated, and kashd resumes the migrations
+// on startup. To roll back instead, replace the database with the backup
+// backupDatabase took before the migrations began.
+//
+// In dry-run mode, the migrations run without committing any of their
+// changes, and only report what they would have changed. Note that since
+// every migration sees the database unchanged by the migrations before it,
+// the reports of all but the first migration may be inaccurate.
+func migrateDatabase(db database.Database, dbPath string, databaseVersion int, isDryRun bool) error {
+	return runDatabaseMigrations(db, dbPath, databaseVersion, isDryRun, databaseMigrations)
+}
+
+func runDatabaseMigrations(db database.Database, dbPath string, databaseVersion int, isDryRun bool,
+	migrations []*databaseMigration) error {
+
+	markerFileName := migrationMarkerFilePath(dbPath)
+	markerVersion, hasMarker, err := readMigrationMarker(markerFileName)
+	if err != nil {
+		return err
+	}
+	if hasMarker {
+		log.Warnf("The migration of the database from version %d was interrupted. Resuming it", markerVersion)
+	}
+
+	var pendingMigrations []*databaseMigration
+	for _, migration := range migrations {
+		if migration.toVersion > databaseVersion {
+			pendingMigrations = append(pendingMigrations, migration)
+		}
+	}
+	if len(pendingMigrations) == 0 {
+		if isDryRun {
+			log.Infof("The database is at version %d. There are no migrations to run", databaseVersion)
+		}
+		return nil
+	}
+
+	if isDryRun {
+		log.Infof("Dry run: the database would be migrated from version %d to version %d",
+			databaseVersion, pendingMigrations[len(pendingMigrations)-1].toVersion)
+	} else {
+		log.Infof("Migrating the database from version %d to version %d",
+			databaseVersion, pendingMigrations[len(pendingMigrations)-1].toVersion)
+		if !hasMarker {
+			err := writeDatabaseVersionFile(markerFileName, databaseVersion)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, migration := range pendingMigrations {
+		if migration.toVersion != databaseVersion+1 {
+			return errors.Errorf("missing migration from database version %d to version %d",
+				databaseVersion, databaseVersion+1)
+		}
+
+		log.Infof("Running migration to version %d: %s", migration.toVersion, migration.description)
+		m := &migrationContext{
+			db:        db,
+			migration: migration,
+			isDryRun:  isDryRun,
+		}
+		err := migration.migrate(m)
+		if err != nil {
+			return errors.Wrapf(err, "failed migrating the database to version %d", migration.toVersion)
+		}
+
+		if isDryRun {
+			log.Infof("Dry run: the migration to version %d would change %d entries",
+				migration.toVersion, m.changedEntries)
+		} else {
+			log.Infof("Migrated the database to version %d. Changed %d entries",
+				migration.toVersion, m.changedEntries)
+			err := writeDatabaseVersionFile(versionFilePath(dbPath), migration.toVersion)
+			if err != nil {
+				return err
+			}
+		}
+		databaseVersion = migration.toVersion
+	}
+
+	if isDryRun {
+		return nil
+	}
+	return os.Remove(markerFileName)
+}
+
+func readMigrationMarker(markerFileName string) (version int, exists bool, err error) {
+	markerBytes, err := os.ReadFile(markerFileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	version, err = strconv.Atoi(strings.TrimSpace(string(markerBytes)))
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "malformed migration marker %s", markerFileName)
+	}
+	return version, true, nil
+}
+
+func migrationMarkerFilePath(dbPath string) string {
+	return path.Join(dbPath, "migrating-from-version")
+}
+
+// backupDatabase copies the closed database in dbPath, whose version is
+// databaseVersion, to a backup directory next to it, unless such a backup
+// already exists. The existing backup is kept since it was taken by a
+// migration that was interrupted, before the database was changed.
+func backupDatabase(dbPath string, databaseVersion int) error {
+	// A partially migrated database is backed up under the version it was
+	// migrated from, so that its backup is found again when resuming
+	markerVersion, hasMarker, err := readMigrationMarker(migrationMarkerFilePath(dbPath))
+	if err != nil {
+		return err
+	}
+	if hasMarker {
+		databaseVersion = markerVersion
+	}
+
+	backupPath := databaseBackupPath(dbPath, databaseVersion)
+	_, err = os.Stat(backupPath)
+	if err == nil {
+		log.Infof("Keeping the existing backup of the database in %s", backupPath)
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	log.Infof("Backing up the database to %s before migrating it", backupPath)
+	// The backup is copied to a temporary directory first, so that a
+	// partial copy is never mistaken for a complete backup
+	temporaryBackupPath := backupPath + ".tmp"
+	err = os.RemoveAll(temporaryBackupPath)
+	if err != nil {
+		return err
+	}
+	err = checkBackupDiskSpace(dbPath)
+	if err != nil {
+		return err
+	}
+	err = copyDirectory(dbPath, temporaryBackupPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed backing up the database to %s", temporaryBackupPath)
+	}
+	err = os.Rename(temporaryBackupPath, backupPath)
+	if err != nil {
+		return err
+	}
+	log.Infof("The database was backed up to %s. It may be deleted once kashd runs "+
+		"with the migrated database as expected", backupPath)
+	return nil
+}
+
+// checkBackupDiskSpace returns an error if there isn't enough disk space
+// next to the database in dbPath to back it up
+func checkBackupDiskSpace(dbPath string) error {
+	databaseSize, err := directorySize(dbPath)
+	if err != nil {
+		return err
+	}
+	backupDirectory := filepath.Dir(dbPath)
+	availableBytes, ok, err := availableDiskSpace(backupDirectory)
+	if err != nil {
+		return errors.Wrapf(err, "failed checking the available disk space in %s", backupDirectory)
+	}
+	if !ok {
+		log.Warnf("Could not determine the available disk space in %s. Backing up %d bytes regardless",
+			backupDirectory, databaseSize)
+		return nil
+	}
+	if availableBytes < databaseSize {
+		return errors.Errorf("backing up the database before migrating it requires %d bytes, but only %d "+
+			"are available in %s. Free up disk space, or run kashd with --no-db-backup to migrate the "+
+			"database without backing it up", databaseSize, availableBytes, backupDirectory)
+	}
+	return nil
+}
+
+func directorySize(directoryPath string) (uint64, error) {
+	size := uint64(0)
+	err := filepath.Walk(directoryPath, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	return size, err
+}
+
+func databaseBackupPath(dbPath string, databaseVersion int) string {
+	return fmt.Sprintf("%s-backup-v%d", dbPath, databaseVersion)
+}
+
+func copyDirectory(sourcePath string, destinationPath string) error {
+	return filepath.Walk(sourcePath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(sourcePath, filePath)
+		if err != nil {
+			return err
+		}
+		targetPath := filepath.Join(destinationPath, relativePath)
+		if info.IsDir() {
+			return os.MkdirAll(targetPath, 0700)
+		}
+		return copyFile(filePath, targetPath)
+	})
+}
+
+func copyFile(sourcePath string, destinationPath string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := os.OpenFile(destinationPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(destination, source)
+	if err != nil {
+		destination.Close()
+		return err
+	}
+	err = destination.Sync()
+	if err != nil {
+		destination.Close()
+		return err
+	}
+	return destination.Close()
+}
@@ -0,0 +1,579 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/domain"
+	"github.com/Kash-Protocol/kashd/domain/consensus"
+	"github.com/Kash-Protocol/kashd/domain/consensus/database/serialization"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/Kash-Protocol/kashd/domain/miningmanager/mempool"
+	"github.com/Kash-Protocol/kashd/domain/prefixmanager/prefix"
+	"github.com/Kash-Protocol/kashd/domain/utxoindex"
+	"github.com/Kash-Protocol/kashd/infrastructure/config"
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database"
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database/boltdb"
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database/ldb"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+const fixtureBlockCount = 20
+
+// consensusBucket holds the data of the consensus the fixture database is created with
+var consensusBucket = database.MakeBucket((&prefix.Prefix{}).Serialize())
+
+// fixtureScriptPublicKey is the script public key the coinbase transactions of the
+// fixture database pay to, and so that of all the entries of its UTXO index
+var fixtureScriptPublicKey = &externalapi.ScriptPublicKey{
+	Script:  append(append([]byte{txscript.OpData32}, bytes.Repeat([]byte{1}, 32)...), txscript.OpCheckSig),
+	Version: 0,
+}
+
+func fixtureConsensusConfig() *consensus.Config {
+	consensusConfig := &consensus.Config{Params: dagconfig.SimnetParams}
+	consensusConfig.SkipProofOfWork = true
+	return consensusConfig
+}
+
+// prepareFixtureDatabase creates a version 1 database of the given type, the
+// way kashd does, with a chain of fixtureBlockCount blocks and a UTXO index,
+// and returns its path along with the hashes of the blocks. The database is
+// closed.
+func prepareFixtureDatabase(t *testing.T, dbType string) (dbPath string, blockHashes []*externalapi.DomainHash) {
+	dbPath = filepath.Join(t.TempDir(), defaultDataDirname)
+	err := os.MkdirAll(dbPath, 0700)
+	if err != nil {
+		t.Fatalf("MkdirAll: %+v", err)
+	}
+	err = writeDatabaseVersionFile(versionFilePath(dbPath), 1)
+	if err != nil {
+		t.Fatalf("writeDatabaseVersionFile: %+v", err)
+	}
+	err = checkDatabaseType(dbPath, dbType)
+	if err != nil {
+		t.Fatalf("checkDatabaseType: %+v", err)
+	}
+
+	db, err := database.Open(dbType, dbPath, 8)
+	if err != nil {
+		t.Fatalf("Open: %+v", err)
+	}
+	defer db.Close()
+
+	consensusConfig := fixtureConsensusConfig()
+	domainInstance, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), db)
+	if err != nil {
+		t.Fatalf("New: %+v", err)
+	}
+	coinbaseData := &externalapi.DomainCoinbaseData{
+		ScriptPublicKey: fixtureScriptPublicKey,
+		ExtraData:       []byte{},
+	}
+	for i := 0; i < fixtureBlockCount; i++ {
+		block, err := domainInstance.Consensus().BuildBlock(coinbaseData, nil)
+		if err != nil {
+			t.Fatalf("BuildBlock: %+v", err)
+		}
+		err = domainInstance.Consensus().ValidateAndInsertBlock(block, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertBlock: %+v", err)
+		}
+		blockHashes = append(blockHashes, consensushashing.BlockHash(block))
+	}
+
+	_, err = utxoindex.New(domainInstance, db)
+	if err != nil {
+		t.Fatalf("utxoindex.New: %+v", err)
+	}
+	addScriptPublicKeysToUTXOIndexEntries(t, db)
+	return dbPath, blockHashes
+}
+
+// addScriptPublicKeysToUTXOIndexEntries rewrites the entries of the UTXO index
+// along with their script public key, the way version 1 stored them
+func addScriptPublicKeysToUTXOIndexEntries(t *testing.T, db database.Database) {
+	for key, value := range readUTXOIndexEntries(t, db) {
+		var dbUTXOEntry serialization.DbUtxoEntry
+		err := proto.Unmarshal(value, &dbUTXOEntry)
+		if err != nil {
+			t.Fatalf("Unmarshal: %+v", err)
+		}
+		dbUTXOEntry.ScriptPublicKey = serialization.ScriptPublicKeyToDBScriptPublicKey(fixtureScriptPublicKey)
+		serializedUTXOEntry, err := proto.Marshal(&dbUTXOEntry)
+		if err != nil {
+			t.Fatalf("Marshal: %+v", err)
+		}
+		err = db.Put(database.MakeBucket(nil).Key([]byte(key)), serializedUTXOEntry)
+		if err != nil {
+			t.Fatalf("Put: %+v", err)
+		}
+	}
+}
+
+// checkFixtureDatabase opens the database in dbPath and verifies that kashd
+// can load it, that it contains all the given blocks, and that its UTXO index
+// holds the coinbase outputs the fixture created
+func checkFixtureDatabase(t *testing.T, dbType string, dbPath string, blockHashes []*externalapi.DomainHash) {
+	db, err := database.Open(dbType, dbPath, 8)
+	if err != nil {
+		t.Fatalf("Open: %+v", err)
+	}
+	defer db.Close()
+
+	consensusConfig := fixtureConsensusConfig()
+	domainInstance, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), db)
+	if err != nil {
+		t.Fatalf("New: %+v", err)
+	}
+	for _, blockHash := range blockHashes {
+		blockInfo, err := domainInstance.Consensus().GetBlockInfo(blockHash)
+		if err != nil {
+			t.Fatalf("GetBlockInfo: %+v", err)
+		}
+		if !blockInfo.Exists || blockInfo.BlockStatus != externalapi.StatusUTXOValid {
+			t.Fatalf("block %s has status %s, exists: %t", blockHash, blockInfo.BlockStatus, blockInfo.Exists)
+		}
+	}
+	virtualInfo, err := domainInstance.Consensus().GetVirtualInfo()
+	if err != nil {
+		t.Fatalf("GetVirtualInfo: %+v", err)
+	}
+	tip := blockHashes[len(blockHashes)-1]
+	if len(virtualInfo.ParentHashes) != 1 || !virtualInfo.ParentHashes[0].Equal(tip) {
+		t.Fatalf("unexpected virtual parents %s. Want: %s", virtualInfo.ParentHashes, tip)
+	}
+
+	utxoEntryCount := len(readUTXOIndexEntries(t, db))
+	utxoIndex, err := utxoindex.New(domainInstance, db)
+	if err != nil {
+		t.Fatalf("utxoindex.New: %+v", err)
+	}
+	utxos, err := utxoIndex.UTXOs(fixtureScriptPublicKey)
+	if err != nil {
+		t.Fatalf("UTXOs: %+v", err)
+	}
+	if len(utxos) == 0 || len(utxos) != utxoEntryCount {
+		t.Fatalf("the UTXO index returned %d UTXOs. Want: %d", len(utxos), utxoEntryCount)
+	}
+	for outpoint, utxoEntry := range utxos {
+		if !utxoEntry.ScriptPublicKey().Equal(fixtureScriptPublicKey) {
+			t.Fatalf("the UTXO %s has script public key %s. Want: %s",
+				outpoint, utxoEntry.ScriptPublicKey(), fixtureScriptPublicKey)
+		}
+	}
+}
+
+// readUTXOIndexEntries returns the entries of the UTXO index by their full key
+func readUTXOIndexEntries(t *testing.T, db database.Database) map[string][]byte {
+	return readBucketEntries(t, db, utxoindex.EntriesBucket())
+}
+
+// countEntriesWithScriptPublicKey returns the number of the given UTXO index
+// entries that are stored along with their script public key
+func countEntriesWithScriptPublicKey(t *testing.T, entries map[string][]byte) int {
+	count := 0
+	for _, value := range entries {
+		var dbUTXOEntry serialization.DbUtxoEntry
+		err := proto.Unmarshal(value, &dbUTXOEntry)
+		if err != nil {
+			t.Fatalf("Unmarshal: %+v", err)
+		}
+		if dbUTXOEntry.ScriptPublicKey != nil {
+			count++
+		}
+	}
+	return count
+}
+
+func readConsensusEntries(t *testing.T, db database.Database) map[string][]byte {
+	return readBucketEntries(t, db, consensusBucket)
+}
+
+func readBucketEntries(t *testing.T, db database.Database, bucket *database.Bucket) map[string][]byte {
+	cursor, err := db.Cursor(bucket)
+	if err != nil {
+		t.Fatalf("Cursor: %+v", err)
+	}
+	defer cursor.Close()
+
+	entries := make(map[string][]byte)
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			t.Fatalf("Key: %+v", err)
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			t.Fatalf("Value: %+v", err)
+		}
+		entries[string(key.Bytes())] = value
+	}
+	return entries
+}
+
+func readDatabaseVersionFile(t *testing.T, dbPath string) int {
+	versionBytes, err := os.ReadFile(versionFilePath(dbPath))
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+	version, err := strconv.Atoi(string(versionBytes))
+	if err != nil {
+		t.Fatalf("Atoi: %s", err)
+	}
+	return version
+}
+
+// invertConsensusEntries returns a migration that inverts the bits of every
+// consensus entry. Applying it to an entry twice leaves the entry unchanged,
+// so a migration that processes an entry more or less than once corrupts
+// the database.
+func invertConsensusEntries(toVersion int, interrupt func() error) *databaseMigration {
+	return &databaseMigration{
+		toVersion:   toVersion,
+		description: "invert the bits of every consensus entry",
+		migrate: func(m *migrationContext) error {
+			return migrateBucket(m, consensusBucket, func(key *database.Key, value []byte) ([]byte, error) {
+				if interrupt != nil {
+					err := interrupt()
+					if err != nil {
+						return nil, err
+					}
+				}
+				newValue := make([]byte, len(value))
+				for i := range value {
+					newValue[i] = ^value[i]
+				}
+				return newValue, nil
+			})
+		},
+	}
+}
+
+func TestMigrateDatabase(t *testing.T) {
+	for _, dbType := range []string{ldb.DBType, boltdb.DBType} {
+		dbPath, blockHashes := prepareFixtureDatabase(t, dbType)
+
+		db, err := database.Open(dbType, dbPath, 8)
+		if err != nil {
+			t.Fatalf("%s: Open: %+v", dbType, err)
+		}
+		entriesBefore := readUTXOIndexEntries(t, db)
+		if countEntriesWithScriptPublicKey(t, entriesBefore) != len(entriesBefore) {
+			t.Fatalf("%s: the UTXO index entries of the fixture aren't in the version 1 format", dbType)
+		}
+		err = migrateDatabase(db, dbPath, 1, false)
+		if err != nil {
+			t.Fatalf("%s: migrateDatabase: %+v", dbType, err)
+		}
+		entriesAfter := readUTXOIndexEntries(t, db)
+		if len(entriesAfter) != len(entriesBefore) {
+			t.Fatalf("%s: the migration changed the UTXO index entry count from %d to %d",
+				dbType, len(entriesBefore), len(entriesAfter))
+		}
+		if count := countEntriesWithScriptPublicKey(t, entriesAfter); count != 0 {
+			t.Fatalf("%s: %d UTXO index entries still have their script public key", dbType, count)
+		}
+
+		// The migrations of an up to date database must leave it as it is
+		err = migrateDatabase(db, dbPath, currentDatabaseVersion, false)
+		if err != nil {
+			t.Fatalf("%s: migrateDatabase: %+v", dbType, err)
+		}
+		err = db.Close()
+		if err != nil {
+			t.Fatalf("%s: Close: %+v", dbType, err)
+		}
+
+		if readDatabaseVersionFile(t, dbPath) != currentDatabaseVersion {
+			t.Fatalf("%s: unexpected version file %d. Want: %d",
+				dbType, readDatabaseVersionFile(t, dbPath), currentDatabaseVersion)
+		}
+		if _, err := os.Stat(migrationMarkerFilePath(dbPath)); !os.IsNotExist(err) {
+			t.Fatalf("%s: the migration marker was not removed", dbType)
+		}
+		checkFixtureDatabase(t, dbType, dbPath, blockHashes)
+	}
+}
+
+func TestResumeInterruptedUTXOIndexMigration(t *testing.T) {
+	originalMigrationBatchSize := migrationBatchSize
+	defer func() { migrationBatchSize = originalMigrationBatchSize }()
+	migrationBatchSize = 5
+
+	dbPath, blockHashes := prepareFixtureDatabase(t, ldb.DBType)
+	db, err := database.Open(ldb.DBType, dbPath, 8)
+	if err != nil {
+		t.Fatalf("Open: %+v", err)
+	}
+	defer db.Close()
+	entryCount := len(readUTXOIndexEntries(t, db))
+	if entryCount <= migrationBatchSize {
+		t.Fatalf("the fixture has only %d UTXO index entries", entryCount)
+	}
+
+	// Run the migration to version 2 up to the entry after its first batch
+	errInterrupted := errors.New("interrupted")
+	processedEntries := 0
+	interruptedMigration := &databaseMigration{
+		toVersion:   2,
+		description: databaseMigrations[0].description,
+		migrate: func(m *migrationContext) error {
+			return migrateBucket(m, utxoindex.EntriesBucket(), func(key *database.Key, value []byte) ([]byte, error) {
+				if processedEntries == migrationBatchSize {
+					return nil, errInterrupted
+				}
+				processedEntries++
+				return removeUTXOIndexEntryScriptPublicKey(key, value)
+			})
+		},
+	}
+	err = runDatabaseMigrations(db, dbPath, 1, false, []*databaseMigration{interruptedMigration})
+	if !errors.Is(err, errInterrupted) {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	remaining := countEntriesWithScriptPublicKey(t, readUTXOIndexEntries(t, db))
+	if remaining != entryCount-migrationBatchSize {
+		t.Fatalf("%d UTXO index entries were left unmigrated. Want: %d", remaining, entryCount-migrationBatchSize)
+	}
+	if readDatabaseVersionFile(t, dbPath) != 1 {
+		t.Fatalf("an interrupted migration changed the version file")
+	}
+
+	// The actual migration resumes where the interrupted one stopped
+	err = migrateDatabase(db, dbPath, 1, false)
+	if err != nil {
+		t.Fatalf("migrateDatabase: %+v", err)
+	}
+	if count := countEntriesWithScriptPublicKey(t, readUTXOIndexEntries(t, db)); count != 0 {
+		t.Fatalf("%d UTXO index entries still have their script public key", count)
+	}
+	hasProgress, err := db.Has(migrationProgressKey)
+	if err != nil {
+		t.Fatalf("Has: %+v", err)
+	}
+	if hasProgress {
+		t.Fatalf("the migration progress was not removed")
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("Close: %+v", err)
+	}
+
+	if readDatabaseVersionFile(t, dbPath) != 2 {
+		t.Fatalf("unexpected version file %d. Want: 2", readDatabaseVersionFile(t, dbPath))
+	}
+	if _, err := os.Stat(migrationMarkerFilePath(dbPath)); !os.IsNotExist(err) {
+		t.Fatalf("the migration marker was not removed")
+	}
+	checkFixtureDatabase(t, ldb.DBType, dbPath, blockHashes)
+}
+
+func TestOpenDBBacksUpDatabase(t *testing.T) {
+	for _, noDBBackup := range []bool{false, true} {
+		dbPath, blockHashes := prepareFixtureDatabase(t, ldb.DBType)
+		cfg := &config.Config{Flags: &config.Flags{
+			AppDir:     filepath.Dir(dbPath),
+			DbType:     ldb.DBType,
+			NoDBBackup: noDBBackup,
+		}}
+
+		db, err := openDB(cfg)
+		if err != nil {
+			t.Fatalf("openDB with --no-db-backup=%t: %+v", noDBBackup, err)
+		}
+		err = db.Close()
+		if err != nil {
+			t.Fatalf("Close: %+v", err)
+		}
+		if readDatabaseVersionFile(t, dbPath) != currentDatabaseVersion {
+			t.Fatalf("openDB with --no-db-backup=%t didn't migrate the database", noDBBackup)
+		}
+		checkFixtureDatabase(t, ldb.DBType, dbPath, blockHashes)
+
+		backupPath := databaseBackupPath(dbPath, 1)
+		_, err = os.Stat(backupPath)
+		if noDBBackup {
+			if !os.IsNotExist(err) {
+				t.Fatalf("openDB with --no-db-backup backed up the database: %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Stat: %+v", err)
+		}
+
+		// The backup holds the database as it was before the migration
+		if readDatabaseVersionFile(t, backupPath) != 1 {
+			t.Fatalf("unexpected version file in the backup")
+		}
+		backupDB, err := database.Open(ldb.DBType, backupPath, 8)
+		if err != nil {
+			t.Fatalf("Open: %+v", err)
+		}
+		entries := readUTXOIndexEntries(t, backupDB)
+		if countEntriesWithScriptPublicKey(t, entries) != len(entries) {
+			t.Fatalf("the backup has migrated UTXO index entries")
+		}
+		err = backupDB.Close()
+		if err != nil {
+			t.Fatalf("Close: %+v", err)
+		}
+		checkFixtureDatabase(t, ldb.DBType, backupPath, blockHashes)
+	}
+}
+
+func TestDryRunMigrations(t *testing.T) {
+	dbPath, blockHashes := prepareFixtureDatabase(t, ldb.DBType)
+
+	db, err := database.Open(ldb.DBType, dbPath, 8)
+	if err != nil {
+		t.Fatalf("Open: %+v", err)
+	}
+	entriesBefore := readConsensusEntries(t, db)
+
+	migrations := []*databaseMigration{invertConsensusEntries(2, nil)}
+	err = runDatabaseMigrations(db, dbPath, 1, true, migrations)
+	if err != nil {
+		t.Fatalf("runDatabaseMigrations: %+v", err)
+	}
+
+	entriesAfter := readConsensusEntries(t, db)
+	if len(entriesAfter) != len(entriesBefore) {
+		t.Fatalf("a dry run changed the entry count from %d to %d", len(entriesBefore), len(entriesAfter))
+	}
+	for key, value := range entriesBefore {
+		if !bytes.Equal(entriesAfter[key], value) {
+			t.Fatalf("a dry run changed the entry %x", key)
+		}
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("Close: %+v", err)
+	}
+
+	if readDatabaseVersionFile(t, dbPath) != 1 {
+		t.Fatalf("a dry run changed the version file")
+	}
+	if _, err := os.Stat(migrationMarkerFilePath(dbPath)); !os.IsNotExist(err) {
+		t.Fatalf("a dry run created the migration marker")
+	}
+	checkFixtureDatabase(t, ldb.DBType, dbPath, blockHashes)
+}
+
+func TestResumeInterruptedMigration(t *testing.T) {
+	originalMigrationBatchSize := migrationBatchSize
+	defer func() { migrationBatchSize = originalMigrationBatchSize }()
+	migrationBatchSize = 100
+
+	for _, dbType := range []string{ldb.DBType, boltdb.DBType} {
+		dbPath, blockHashes := prepareFixtureDatabase(t, dbType)
+
+		err := backupDatabase(dbPath, 1)
+		if err != nil {
+			t.Fatalf("%s: backupDatabase: %+v", dbType, err)
+		}
+
+		db, err := database.Open(dbType, dbPath, 8)
+		if err != nil {
+			t.Fatalf("%s: Open: %+v", dbType, err)
+		}
+		entriesBefore := readConsensusEntries(t, db)
+		if len(entriesBefore) <= migrationBatchSize {
+			t.Fatalf("%s: the fixture has only %d consensus entries", dbType, len(entriesBefore))
+		}
+
+		// The first migration fails after its first batch was committed.
+		// The second one reverts the first, so once both complete the
+		// database must be loadable as if it was never migrated.
+		errInterrupted := errors.New("interrupted")
+		shouldInterrupt := true
+		processedEntries := 0
+		interrupt := func() error {
+			if shouldInterrupt && processedEntries == migrationBatchSize+1 {
+				return errInterrupted
+			}
+			processedEntries++
+			return nil
+		}
+		migrations := []*databaseMigration{
+			invertConsensusEntries(2, interrupt),
+			invertConsensusEntries(3, nil),
+		}
+
+		err = runDatabaseMigrations(db, dbPath, 1, false, migrations)
+		if !errors.Is(err, errInterrupted) {
+			t.Fatalf("%s: unexpected error: %+v", dbType, err)
+		}
+		markerVersion, hasMarker, err := readMigrationMarker(migrationMarkerFilePath(dbPath))
+		if err != nil {
+			t.Fatalf("%s: readMigrationMarker: %+v", dbType, err)
+		}
+		if !hasMarker || markerVersion != 1 {
+			t.Fatalf("%s: unexpected migration marker: exists %t, version %d", dbType, hasMarker, markerVersion)
+		}
+		if readDatabaseVersionFile(t, dbPath) != 1 {
+			t.Fatalf("%s: an interrupted migration changed the version file", dbType)
+		}
+
+		// Resume the migrations the way kashd does on the next startup. The
+		// version file of a partially migrated database may be past the
+		// version of its backup, which must be kept nonetheless.
+		err = db.Close()
+		if err != nil {
+			t.Fatalf("%s: Close: %+v", dbType, err)
+		}
+		err = backupDatabase(dbPath, 2)
+		if err != nil {
+			t.Fatalf("%s: backupDatabase: %+v", dbType, err)
+		}
+		db, err = database.Open(dbType, dbPath, 8)
+		if err != nil {
+			t.Fatalf("%s: Open: %+v", dbType, err)
+		}
+		shouldInterrupt = false
+		processedEntries = 0
+		err = runDatabaseMigrations(db, dbPath, 1, false, migrations)
+		if err != nil {
+			t.Fatalf("%s: runDatabaseMigrations: %+v", dbType, err)
+		}
+		if processedEntries != len(entriesBefore)-migrationBatchSize {
+			t.Fatalf("%s: the resumed migration processed %d entries. Want: %d",
+				dbType, processedEntries, len(entriesBefore)-migrationBatchSize)
+		}
+		hasProgress, err := db.Has(migrationProgressKey)
+		if err != nil {
+			t.Fatalf("%s: Has: %+v", dbType, err)
+		}
+		if hasProgress {
+			t.Fatalf("%s: the migration progress was not removed", dbType)
+		}
+		err = db.Close()
+		if err != nil {
+			t.Fatalf("%s: Close: %+v", dbType, err)
+		}
+
+		if readDatabaseVersionFile(t, dbPath) != 3 {
+			t.Fatalf("%s: unexpected version file %d. Want: 3", dbType, readDatabaseVersionFile(t, dbPath))
+		}
+		if _, err := os.Stat(migrationMarkerFilePath(dbPath)); !os.IsNotExist(err) {
+			t.Fatalf("%s: the migration marker was not removed", dbType)
+		}
+		checkFixtureDatabase(t, dbType, dbPath, blockHashes)
+
+		// The backup taken before the interrupted migration holds the
+		// database as it was before it
+		backupPath := databaseBackupPath(dbPath, 1)
+		if readDatabaseVersionFile(t, backupPath) != 1 {
+			t.Fatalf("%s: unexpected version file in the backup", dbType)
+		}
+		checkFixtureDatabase(t, dbType, backupPath, blockHashes)
+	}
+}
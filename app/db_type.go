@@ -0,0 +1,64 @@
+package app
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database/ldb"
+	"github.com/pkg/errors"
+)
+
+// checkDatabaseType makes sure that the database in dbPath was created by the
+// given database backend, since backends cannot read each other's data.
+// Databases created before the type file was introduced are all leveldb.
+func checkDatabaseType(dbPath string, dbType string) error {
+	typeFileName := typeFilePath(dbPath)
+
+	typeBytes, err := os.ReadFile(typeFileName)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		isNewDatabase, err := isEmptyDatabaseDirectory(dbPath)
+		if err != nil {
+			return err
+		}
+		if !isNewDatabase {
+			typeBytes = []byte(ldb.DBType)
+		} else {
+			typeBytes = []byte(dbType)
+		}
+		err = os.WriteFile(typeFileName, typeBytes, 0600)
+		if err != nil {
+			return err
+		}
+	}
+
+	databaseType := strings.TrimSpace(string(typeBytes))
+	if databaseType != dbType {
+		return errors.Errorf("The database in %s was created with --dbtype=%s, but --dbtype=%s was given. "+
+			"Either use --dbtype=%s or remove the database with --reset-db", dbPath, databaseType, dbType, databaseType)
+	}
+
+	return nil
+}
+
+// isEmptyDatabaseDirectory returns whether dbPath contains nothing but the
+// database version file
+func isEmptyDatabaseDirectory(dbPath string) (bool, error) {
+	entries, err := os.ReadDir(dbPath)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if path.Join(dbPath, entry.Name()) != versionFilePath(dbPath) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func typeFilePath(dbPath string) string {
+	return path.Join(dbPath, "dbtype")
+}
@@ -4,34 +4,45 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 )
 
-const currentDatabaseVersion = 1
+// currentDatabaseVersion is the version of the database this version of
+// kashd works with. Older databases are brought up to it by the migrations
+// in databaseMigrations.
+const currentDatabaseVersion = 2
 
-func checkDatabaseVersion(dbPath string) (err error) {
+// checkDatabaseVersion returns the version of the database in dbPath. If the
+// version file doesn't exist, we assume that the database is new, and create
+// it with currentDatabaseVersion.
+func checkDatabaseVersion(dbPath string) (version int, err error) {
 	versionFileName := versionFilePath(dbPath)
 
 	versionBytes, err := os.ReadFile(versionFileName)
 	if err != nil {
-		if os.IsNotExist(err) { // If version file doesn't exist, we assume that the database is new
-			return createDatabaseVersionFile(dbPath, versionFileName)
+		if os.IsNotExist(err) {
+			err := createDatabaseVersionFile(dbPath, versionFileName)
+			if err != nil {
+				return 0, err
+			}
+			return currentDatabaseVersion, nil
 		}
-		return err
+		return 0, err
 	}
 
-	databaseVersion, err := strconv.Atoi(string(versionBytes))
+	databaseVersion, err := strconv.Atoi(strings.TrimSpace(string(versionBytes)))
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	if databaseVersion != currentDatabaseVersion {
-		// TODO: Once there's more then one database version, it might make sense to add upgrade logic at this point
-		return errors.Errorf("Invalid database version %d. Expected version: %d", databaseVersion, currentDatabaseVersion)
+	if databaseVersion > currentDatabaseVersion {
+		return 0, errors.Errorf("Invalid database version %d. The database was created by a newer "+
+			"version of kashd. Expected version: %d", databaseVersion, currentDatabaseVersion)
 	}
 
-	return nil
+	return databaseVersion, nil
 }
 
 func createDatabaseVersionFile(dbPath string, versionFileName string) error {
@@ -40,15 +51,12 @@ func createDatabaseVersionFile(dbPath string, versionFileName string) error {
 		return err
 	}
 
-	versionFile, err := os.Create(versionFileName)
-	if err != nil {
-		return nil
-	}
-	defer versionFile.Close()
+	return writeDatabaseVersionFile(versionFileName, currentDatabaseVersion)
+}
 
-	versionString := strconv.Itoa(currentDatabaseVersion)
-	_, err = versionFile.Write([]byte(versionString))
-	return err
+func writeDatabaseVersionFile(versionFileName string, version int) error {
+	versionString := strconv.Itoa(version)
+	return os.WriteFile(versionFileName, []byte(versionString), 0600)
 }
 
 func versionFilePath(dbPath string) string {
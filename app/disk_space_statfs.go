@@ -0,0 +1,18 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package app
+
+import "syscall"
+
+// availableDiskSpace returns the number of bytes available to kashd on the
+// file system of the given path, and whether it could be determined on
+// this platform.
+func availableDiskSpace(path string) (availableBytes uint64, ok bool, err error) {
+	var stat syscall.Statfs_t
+	err = syscall.Statfs(path, &stat)
+	if err != nil {
+		return 0, false, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), true, nil
+}
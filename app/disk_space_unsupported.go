@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package app
+
+// availableDiskSpace reports that the available disk space can't be
+// determined on this platform.
+func availableDiskSpace(string) (availableBytes uint64, ok bool, err error) {
+	return 0, false, nil
+}
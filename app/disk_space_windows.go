@@ -0,0 +1,18 @@
+package app
+
+import "golang.org/x/sys/windows"
+
+// availableDiskSpace returns the number of bytes available to kashd on the
+// file system of the given path, and whether it could be determined on
+// this platform.
+func availableDiskSpace(path string) (availableBytes uint64, ok bool, err error) {
+	pathPointer, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, false, err
+	}
+	err = windows.GetDiskFreeSpaceEx(pathPointer, &availableBytes, nil, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	return availableBytes, true, nil
+}
@@ -0,0 +1,200 @@
+package app
+
+import (
+	"strconv"
+
+	"github.com/Kash-Protocol/kashd/app/protocol"
+	"github.com/Kash-Protocol/kashd/domain"
+	"github.com/Kash-Protocol/kashd/domain/utxoindex"
+	infrastructuredatabase "github.com/Kash-Protocol/kashd/infrastructure/db/database"
+	"github.com/Kash-Protocol/kashd/infrastructure/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// nodeStateCollector reports the state of kashd's components whenever the metrics are scraped
+type nodeStateCollector struct {
+	domain          domain.Domain
+	protocolManager *protocol.Manager
+	utxoIndex       *utxoindex.UTXOIndex
+	db              infrastructuredatabase.Database
+
+	mempoolTransactions *prometheus.Desc
+	mempoolMass         *prometheus.Desc
+	orphanBlocks        *prometheus.Desc
+	peers               *prometheus.Desc
+	isIBDRunning        *prometheus.Desc
+	virtualDAAScore     *prometheus.Desc
+	utxoIndexLag        *prometheus.Desc
+
+	dbCompactions               *prometheus.Desc
+	dbLevelSize                 *prometheus.Desc
+	dbLevelTables               *prometheus.Desc
+	dbLevelCompactionDuration   *prometheus.Desc
+	dbLevelCompactionReadBytes  *prometheus.Desc
+	dbLevelCompactionWriteBytes *prometheus.Desc
+	dbIOReadBytes               *prometheus.Desc
+	dbIOWriteBytes              *prometheus.Desc
+	dbWriteDelays               *prometheus.Desc
+	dbWriteDelayDuration        *prometheus.Desc
+}
+
+// newNodeStateCollector returns a collector of the state of the given components.
+// utxoIndex is nil when the UTXO index is disabled.
+func newNodeStateCollector(domain domain.Domain, protocolManager *protocol.Manager, utxoIndex *utxoindex.UTXOIndex,
+	db infrastructuredatabase.Database) *nodeStateCollector {
+
+	newDesc := func(subsystem string, name string, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, subsystem, name), help, labels, nil)
+	}
+	return &nodeStateCollector{
+		domain:          domain,
+		protocolManager: protocolManager,
+		utxoIndex:       utxoIndex,
+		db:              db,
+
+		mempoolTransactions: newDesc("mempool", "transactions",
+			"The number of transactions in the mempool, by pool (transactions or orphans)", "pool"),
+		mempoolMass: newDesc("mempool", "mass",
+			"The total mass of the transactions in the mempool, by pool (transactions or orphans)", "pool"),
+		orphanBlocks: newDesc("protocol", "orphan_blocks",
+			"The number of blocks with missing parents waiting for their parents"),
+		peers: newDesc("network", "peers",
+			"The number of connected peers, by direction (inbound or outbound)", "direction"),
+		isIBDRunning: newDesc("ibd", "running",
+			"Whether IBD is currently running (1) or not (0)"),
+		virtualDAAScore: newDesc("consensus", "virtual_daa_score",
+			"The DAA score of the virtual block"),
+		utxoIndexLag: newDesc("utxoindex", "lag_daa_score",
+			"How far behind the virtual the UTXO index is, in DAA score"),
+
+		dbCompactions: newDesc("db", "compactions_total",
+			"The number of database compactions since kashd started, by kind", "kind"),
+		dbLevelSize: newDesc("db", "level_size_bytes",
+			"The size of the database tables, by level", "level"),
+		dbLevelTables: newDesc("db", "level_tables",
+			"The number of database tables, by level", "level"),
+		dbLevelCompactionDuration: newDesc("db", "level_compaction_seconds_total",
+			"The time spent compacting the database since kashd started, by level", "level"),
+		dbLevelCompactionReadBytes: newDesc("db", "level_compaction_read_bytes_total",
+			"The bytes read by database compactions since kashd started, by level", "level"),
+		dbLevelCompactionWriteBytes: newDesc("db", "level_compaction_write_bytes_total",
+			"The bytes written by database compactions since kashd started, by level", "level"),
+		dbIOReadBytes: newDesc("db", "io_read_bytes_total",
+			"The bytes read from the database storage since kashd started"),
+		dbIOWriteBytes: newDesc("db", "io_write_bytes_total",
+			"The bytes written to the database storage since kashd started"),
+		dbWriteDelays: newDesc("db", "write_delays_total",
+			"The number of database writes delayed for compactions to catch up since kashd started"),
+		dbWriteDelayDuration: newDesc("db", "write_delay_seconds_total",
+			"The time database writes were delayed for compactions to catch up since kashd started"),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *nodeStateCollector) Describe(descs chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		c.mempoolTransactions, c.mempoolMass, c.orphanBlocks, c.peers, c.isIBDRunning, c.virtualDAAScore,
+		c.utxoIndexLag, c.dbCompactions, c.dbLevelSize, c.dbLevelTables, c.dbLevelCompactionDuration,
+		c.dbLevelCompactionReadBytes, c.dbLevelCompactionWriteBytes, c.dbIOReadBytes, c.dbIOWriteBytes,
+		c.dbWriteDelays, c.dbWriteDelayDuration,
+	} {
+		descs <- desc
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *nodeStateCollector) Collect(collectedMetrics chan<- prometheus.Metric) {
+	c.collectMempool(collectedMetrics)
+	c.collectProtocol(collectedMetrics)
+	c.collectConsensus(collectedMetrics)
+	c.collectDatabase(collectedMetrics)
+}
+
+func (c *nodeStateCollector) collectMempool(collectedMetrics chan<- prometheus.Metric) {
+	miningManager := c.domain.MiningManager()
+	for _, pool := range []struct {
+		name                                      string
+		includeTransactionPool, includeOrphanPool bool
+	}{{"transactions", true, false}, {"orphans", false, true}} {
+		collectedMetrics <- prometheus.MustNewConstMetric(c.mempoolTransactions, prometheus.GaugeValue,
+			float64(miningManager.TransactionCount(pool.includeTransactionPool, pool.includeOrphanPool)), pool.name)
+		collectedMetrics <- prometheus.MustNewConstMetric(c.mempoolMass, prometheus.GaugeValue,
+			float64(miningManager.TransactionsMass(pool.includeTransactionPool, pool.includeOrphanPool)), pool.name)
+	}
+}
+
+func (c *nodeStateCollector) collectProtocol(collectedMetrics chan<- prometheus.Metric) {
+	collectedMetrics <- prometheus.MustNewConstMetric(c.orphanBlocks, prometheus.GaugeValue,
+		float64(c.protocolManager.Context().OrphanCount()))
+
+	inboundPeers, outboundPeers := 0, 0
+	for _, peer := range c.protocolManager.Peers() {
+		if peer.IsOutbound() {
+			outboundPeers++
+		} else {
+			inboundPeers++
+		}
+	}
+	collectedMetrics <- prometheus.MustNewConstMetric(c.peers, prometheus.GaugeValue, float64(inboundPeers), "inbound")
+	collectedMetrics <- prometheus.MustNewConstMetric(c.peers, prometheus.GaugeValue, float64(outboundPeers), "outbound")
+
+	isIBDRunning := 0.0
+	if c.protocolManager.IsIBDRunning() {
+		isIBDRunning = 1
+	}
+	collectedMetrics <- prometheus.MustNewConstMetric(c.isIBDRunning, prometheus.GaugeValue, isIBDRunning)
+}
+
+func (c *nodeStateCollector) collectConsensus(collectedMetrics chan<- prometheus.Metric) {
+	virtualDAAScore, err := c.domain.Consensus().GetVirtualDAAScore()
+	if err != nil {
+		collectedMetrics <- prometheus.NewInvalidMetric(c.virtualDAAScore, err)
+		return
+	}
+	collectedMetrics <- prometheus.MustNewConstMetric(c.virtualDAAScore, prometheus.GaugeValue, float64(virtualDAAScore))
+
+	if c.utxoIndex == nil {
+		return
+	}
+	// The UTXO index may be momentarily ahead when the virtual changed between the two reads
+	utxoIndexLag := 0.0
+	utxoIndexDAAScore := c.utxoIndex.VirtualDAAScore()
+	if virtualDAAScore > utxoIndexDAAScore {
+		utxoIndexLag = float64(virtualDAAScore - utxoIndexDAAScore)
+	}
+	collectedMetrics <- prometheus.MustNewConstMetric(c.utxoIndexLag, prometheus.GaugeValue, utxoIndexLag)
+}
+
+func (c *nodeStateCollector) collectDatabase(collectedMetrics chan<- prometheus.Metric) {
+	statsProvider, ok := c.db.(infrastructuredatabase.StatsProvider)
+	if !ok {
+		return
+	}
+	stats, err := statsProvider.Stats()
+	if err != nil {
+		collectedMetrics <- prometheus.NewInvalidMetric(c.dbCompactions, err)
+		return
+	}
+
+	for kind, count := range stats.Compactions {
+		collectedMetrics <- prometheus.MustNewConstMetric(c.dbCompactions, prometheus.CounterValue, float64(count), kind)
+	}
+	for level, levelStats := range stats.Levels {
+		levelLabel := strconv.Itoa(level)
+		collectedMetrics <- prometheus.MustNewConstMetric(c.dbLevelSize, prometheus.GaugeValue,
+			float64(levelStats.SizeBytes), levelLabel)
+		collectedMetrics <- prometheus.MustNewConstMetric(c.dbLevelTables, prometheus.GaugeValue,
+			float64(levelStats.TableCount), levelLabel)
+		collectedMetrics <- prometheus.MustNewConstMetric(c.dbLevelCompactionDuration, prometheus.CounterValue,
+			levelStats.CompactionDuration.Seconds(), levelLabel)
+		collectedMetrics <- prometheus.MustNewConstMetric(c.dbLevelCompactionReadBytes, prometheus.CounterValue,
+			float64(levelStats.CompactionReadBytes), levelLabel)
+		collectedMetrics <- prometheus.MustNewConstMetric(c.dbLevelCompactionWriteBytes, prometheus.CounterValue,
+			float64(levelStats.CompactionWriteBytes), levelLabel)
+	}
+	collectedMetrics <- prometheus.MustNewConstMetric(c.dbIOReadBytes, prometheus.CounterValue, float64(stats.IOReadBytes))
+	collectedMetrics <- prometheus.MustNewConstMetric(c.dbIOWriteBytes, prometheus.CounterValue, float64(stats.IOWriteBytes))
+	collectedMetrics <- prometheus.MustNewConstMetric(c.dbWriteDelays, prometheus.CounterValue, float64(stats.WriteDelayCount))
+	collectedMetrics <- prometheus.MustNewConstMetric(c.dbWriteDelayDuration, prometheus.CounterValue,
+		stats.WriteDelayDuration.Seconds())
+}
@@ -110,7 +110,8 @@ func (f *FlowContext) AddBlock(block *externalapi.DomainBlock) error {
 	err := f.Domain().Consensus().ValidateAndInsertBlock(block, true)
 	if err != nil {
 		if errors.As(err, &ruleerrors.RuleError{}) {
-			log.Warnf("Validation failed for block %s: %s", consensushashing.BlockHash(block), err)
+			blockHash := consensushashing.BlockHash(block)
+			log.WithBlockHash(blockHash).Warnf("Validation failed for block %s: %s", blockHash, err)
 		}
 		return err
 	}
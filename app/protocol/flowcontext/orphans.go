@@ -28,7 +28,7 @@ func (f *FlowContext) AddOrphan(orphanBlock *externalapi.DomainBlock) {
 		f.evictRandomOrphan()
 	}
 
-	log.Infof("Received a block with missing parents, adding to orphan pool: %s", orphanHash)
+	log.WithBlockHash(orphanHash).Infof("Received a block with missing parents, adding to orphan pool: %s", orphanHash)
 }
 
 func (f *FlowContext) evictRandomOrphan() {
@@ -50,6 +50,14 @@ func (f *FlowContext) IsOrphan(blockHash *externalapi.DomainHash) bool {
 	return ok
 }
 
+// OrphanCount returns the number of orphan blocks in the orphan set
+func (f *FlowContext) OrphanCount() int {
+	f.orphansMutex.RLock()
+	defer f.orphansMutex.RUnlock()
+
+	return len(f.orphans)
+}
+
 // UnorphanBlocks removes the block from the orphan set, and remove all of the blocks that are not orphans anymore.
 func (f *FlowContext) UnorphanBlocks(rootBlock *externalapi.DomainBlock) ([]*externalapi.DomainBlock, error) {
 	f.orphansMutex.Lock()
@@ -144,13 +152,13 @@ func (f *FlowContext) unorphanBlock(orphanHash externalapi.DomainHash) (bool, er
 	err := f.domain.Consensus().ValidateAndInsertBlock(orphanBlock, true)
 	if err != nil {
 		if errors.As(err, &ruleerrors.RuleError{}) {
-			log.Warnf("Validation failed for orphan block %s: %s", orphanHash, err)
+			log.WithBlockHash(orphanHash).Warnf("Validation failed for orphan block %s: %s", orphanHash, err)
 			return false, nil
 		}
 		return false, err
 	}
 
-	log.Infof("Unorphaned block %s", orphanHash)
+	log.WithBlockHash(orphanHash).Infof("Unorphaned block %s", orphanHash)
 	return true, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"math/rand"
 
 	"github.com/Kash-Protocol/kashd/app/appmessage"
+	peerpkg "github.com/Kash-Protocol/kashd/app/protocol/peer"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/addressmanager"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/router"
 )
@@ -14,7 +15,9 @@ type SendAddressesContext interface {
 }
 
 // SendAddresses sends addresses to a peer that requests it.
-func SendAddresses(context SendAddressesContext, incomingRoute *router.Route, outgoingRoute *router.Route) error {
+func SendAddresses(context SendAddressesContext, incomingRoute *router.Route, outgoingRoute *router.Route,
+	peer *peerpkg.Peer) error {
+
 	for {
 		_, err := incomingRoute.Dequeue()
 		if err != nil {
@@ -22,6 +25,9 @@ func SendAddresses(context SendAddressesContext, incomingRoute *router.Route, ou
 		}
 
 		addresses := context.AddressManager().Addresses()
+		if peer.Services()&appmessage.SFNodeAddrV2 == 0 {
+			addresses = ipAddresses(addresses)
+		}
 		msgAddresses := appmessage.NewMsgAddresses(shuffleAddresses(addresses))
 
 		err = outgoingRoute.Enqueue(msgAddresses)
@@ -31,6 +37,18 @@ func SendAddresses(context SendAddressesContext, incomingRoute *router.Route, ou
 	}
 }
 
+// ipAddresses filters out overlay network addresses, which peers that
+// predate them would misinterpret as empty IP addresses.
+func ipAddresses(addresses []*appmessage.NetAddress) []*appmessage.NetAddress {
+	filteredAddresses := make([]*appmessage.NetAddress, 0, len(addresses))
+	for _, address := range addresses {
+		if !address.IsOverlay() {
+			filteredAddresses = append(filteredAddresses, address)
+		}
+	}
+	return filteredAddresses
+}
+
 // shuffleAddresses randomizes the given addresses sent if there are more than the maximum allowed in one message.
 func shuffleAddresses(addresses []*appmessage.NetAddress) []*appmessage.NetAddress {
 	addressCount := len(addresses)
@@ -77,7 +77,8 @@ func (flow *handleRelayInvsFlow) start() error {
 			return err
 		}
 
-		log.Debugf("Got relay inv for block %s", inv.Hash)
+		blockLog := log.WithBlockHash(inv.Hash).WithPeerID(flow.peer.ID())
+		blockLog.Debugf("Got relay inv for block %s", inv.Hash)
 
 		blockInfo, err := flow.Domain().Consensus().GetBlockInfo(inv.Hash)
 		if err != nil {
@@ -88,7 +89,7 @@ func (flow *handleRelayInvsFlow) start() error {
 				return protocolerrors.Errorf(true, "sent inv of an invalid block %s",
 					inv.Hash)
 			}
-			log.Debugf("Block %s already exists. continuing...", inv.Hash)
+			blockLog.Debugf("Block %s already exists. continuing...", inv.Hash)
 			continue
 		}
 
@@ -99,12 +100,12 @@ func (flow *handleRelayInvsFlow) start() error {
 
 		if flow.IsOrphan(inv.Hash) {
 			if flow.Config().NetParams().DisallowDirectBlocksOnTopOfGenesis && !flow.Config().AllowSubmitBlockWhenNotSynced && isGenesisVirtualSelectedParent {
-				log.Infof("Cannot process orphan %s for a node with only the genesis block. The node needs to IBD "+
+				blockLog.Infof("Cannot process orphan %s for a node with only the genesis block. The node needs to IBD "+
 					"to the recent pruning point before normal operation can resume.", inv.Hash)
 				continue
 			}
 
-			log.Debugf("Block %s is a known orphan. Requesting its missing ancestors", inv.Hash)
+			blockLog.Debugf("Block %s is a known orphan. Requesting its missing ancestors", inv.Hash)
 			err := flow.AddOrphanRootsToQueue(inv.Hash)
 			if err != nil {
 				return err
@@ -119,18 +120,18 @@ func (flow *handleRelayInvsFlow) start() error {
 				return err
 			}
 			if !isNearlySynced {
-				log.Debugf("Got block %s while in IBD and the node is out of sync. Continuing...", inv.Hash)
+				blockLog.Debugf("Got block %s while in IBD and the node is out of sync. Continuing...", inv.Hash)
 				continue
 			}
 		}
 
-		log.Debugf("Requesting block %s", inv.Hash)
+		blockLog.Debugf("Requesting block %s", inv.Hash)
 		block, exists, err := flow.requestBlock(inv.Hash)
 		if err != nil {
 			return err
 		}
 		if exists {
-			log.Debugf("Aborting requesting block %s because it already exists", inv.Hash)
+			blockLog.Debugf("Aborting requesting block %s because it already exists", inv.Hash)
 			continue
 		}
 
@@ -140,7 +141,7 @@ func (flow *handleRelayInvsFlow) start() error {
 		}
 
 		if flow.Config().NetParams().DisallowDirectBlocksOnTopOfGenesis && !flow.Config().AllowSubmitBlockWhenNotSynced && !flow.Config().Devnet && flow.isChildOfGenesis(block) {
-			log.Infof("Cannot process %s because it's a direct child of genesis.", consensushashing.BlockHash(block))
+			blockLog.Infof("Cannot process %s because it's a direct child of genesis.", consensushashing.BlockHash(block))
 			continue
 		}
 
@@ -161,14 +162,14 @@ func (flow *handleRelayInvsFlow) start() error {
 				// block is not in the future of virtual's merge depth root, and thus cannot be merged unless
 				// other valid blocks Kosherize it, in which case it will be obtained once the merger is relayed
 				if block.Header.BlueWork().Cmp(mergeDepthRootHeader.BlueWork()) <= 0 {
-					log.Debugf("Block %s has lower blue work than virtual's merge root %s (%d <= %d), hence we are skipping it",
+					blockLog.Debugf("Block %s has lower blue work than virtual's merge root %s (%d <= %d), hence we are skipping it",
 						inv.Hash, virtualMergeDepthRoot, block.Header.BlueWork(), mergeDepthRootHeader.BlueWork())
 					continue
 				}
 			}
 		}
 
-		log.Debugf("Processing block %s", inv.Hash)
+		blockLog.Debugf("Processing block %s", inv.Hash)
 		oldVirtualInfo, err := flow.Domain().Consensus().GetVirtualInfo()
 		if err != nil {
 			return err
@@ -176,18 +177,18 @@ func (flow *handleRelayInvsFlow) start() error {
 		missingParents, err := flow.processBlock(block)
 		if err != nil {
 			if errors.Is(err, ruleerrors.ErrPrunedBlock) {
-				log.Infof("Ignoring pruned block %s", inv.Hash)
+				blockLog.Infof("Ignoring pruned block %s", inv.Hash)
 				continue
 			}
 
 			if errors.Is(err, ruleerrors.ErrDuplicateBlock) {
-				log.Infof("Ignoring duplicate block %s", inv.Hash)
+				blockLog.Infof("Ignoring duplicate block %s", inv.Hash)
 				continue
 			}
 			return err
 		}
 		if len(missingParents) > 0 {
-			log.Debugf("Block %s is orphan and has missing parents: %s", inv.Hash, missingParents)
+			blockLog.Debugf("Block %s is orphan and has missing parents: %s", inv.Hash, missingParents)
 			err := flow.processOrphan(block)
 			if err != nil {
 				return err
@@ -235,7 +236,7 @@ func (flow *handleRelayInvsFlow) start() error {
 			}
 		}
 
-		log.Infof("Accepted block %s via relay", inv.Hash)
+		blockLog.Infof("Accepted block %s via relay", inv.Hash)
 		err = flow.OnNewBlock(block)
 		if err != nil {
 			return err
@@ -10,6 +10,7 @@ import (
 	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
 	"github.com/Kash-Protocol/kashd/domain/consensus/ruleerrors"
 	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/pow"
 	"github.com/Kash-Protocol/kashd/infrastructure/config"
 	"github.com/Kash-Protocol/kashd/infrastructure/logger"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/router"
@@ -79,7 +80,8 @@ func (flow *handleIBDFlow) runIBDIfNotRunning(block *externalapi.DomainBlock) er
 
 	relayBlockHash := consensushashing.BlockHash(block)
 
-	log.Infof("IBD started with peer %s and relayBlockHash %s", flow.peer, relayBlockHash)
+	log.WithBlockHash(relayBlockHash).WithPeerID(flow.peer.ID()).
+		Infof("IBD started with peer %s and relayBlockHash %s", flow.peer, relayBlockHash)
 	log.Infof("Syncing blocks up to %s", relayBlockHash)
 	log.Infof("Trying to find highest known syncer chain block from peer %s with relay hash %s", flow.peer, relayBlockHash)
 
@@ -285,8 +287,8 @@ func (flow *handleIBDFlow) negotiateMissingSyncerChainSegment() (*externalapi.Do
 		}
 	}
 
-	log.Infof("Found highest known syncer chain block %s from peer %s",
-		highestKnownSyncerChainHash, flow.peer)
+	log.WithBlockHash(highestKnownSyncerChainHash).WithPeerID(flow.peer.ID()).
+		Infof("Found highest known syncer chain block %s from peer %s", highestKnownSyncerChainHash, flow.peer)
 
 	return syncerHeaderSelectedTipHash, highestKnownSyncerChainHash, nil
 }
@@ -361,33 +363,77 @@ func (flow *handleIBDFlow) syncPruningPointFutureHeaders(consensus externalapi.C
 	}
 	progressReporter := newIBDProgressReporter(highestSharedBlockHeader.DAAScore(), highBlockDAAScoreHint, "block headers")
 
-	// Keep a short queue of BlockHeadersMessages so that there's
-	// never a moment when the node is not validating and inserting
-	// headers
+	// Keep short queues of BlockHeadersMessages so that there's never
+	// a moment when the node is not validating and inserting headers.
+	// Received headers first go through a proof of work pre-validation
+	// stage, which checks the proof of work of a whole message in
+	// parallel, while the previous message is being inserted.
+	receivedBlockHeadersMessageChan := make(chan *appmessage.BlockHeadersMessage, 2)
 	blockHeadersMessageChan := make(chan *appmessage.BlockHeadersMessage, 2)
 	errChan := make(chan error)
+	// doneChan is closed once this function returns, so that the
+	// goroutines below don't block forever on a send nobody receives
+	doneChan := make(chan struct{})
+	defer close(doneChan)
+	sendErr := func(err error) {
+		select {
+		case errChan <- err:
+		case <-doneChan:
+		}
+	}
 	spawn("handleRelayInvsFlow-syncPruningPointFutureHeaders", func() {
 		for {
 			blockHeadersMessage, doneIBD, err := flow.receiveHeaders()
 			if err != nil {
-				errChan <- err
+				sendErr(err)
 				return
 			}
 			if doneIBD {
-				close(blockHeadersMessageChan)
+				close(receivedBlockHeadersMessageChan)
 				return
 			}
 			if len(blockHeadersMessage.BlockHeaders) == 0 {
 				// The syncer should have sent a done message if the search completed, and not an empty list
-				errChan <- protocolerrors.Errorf(true, "Received an empty headers message from peer %s", flow.peer)
+				sendErr(protocolerrors.Errorf(true, "Received an empty headers message from peer %s", flow.peer))
 				return
 			}
 
-			blockHeadersMessageChan <- blockHeadersMessage
+			select {
+			case receivedBlockHeadersMessageChan <- blockHeadersMessage:
+			case <-doneChan:
+				return
+			}
 
 			err = flow.outgoingRoute.Enqueue(appmessage.NewMsgRequestNextHeaders())
 			if err != nil {
-				errChan <- err
+				sendErr(err)
+				return
+			}
+		}
+	})
+	spawn("handleRelayInvsFlow-syncPruningPointFutureHeaders-proofOfWork", func() {
+		for {
+			var blockHeadersMessage *appmessage.BlockHeadersMessage
+			select {
+			case message, ok := <-receivedBlockHeadersMessageChan:
+				if !ok {
+					close(blockHeadersMessageChan)
+					return
+				}
+				blockHeadersMessage = message
+			case <-doneChan:
+				return
+			}
+
+			err := flow.prevalidateHeadersProofOfWork(blockHeadersMessage.BlockHeaders)
+			if err != nil {
+				sendErr(err)
+				return
+			}
+
+			select {
+			case blockHeadersMessageChan <- blockHeadersMessage:
+			case <-doneChan:
 				return
 			}
 		}
@@ -446,6 +492,10 @@ func (flow *handleIBDFlow) syncMissingRelayPast(consensus externalapi.Consensus,
 				"Expected only one anticone header chunk for past(%s) cap anticone(%s)",
 				relayBlockHash, syncerHeaderSelectedTipHash)
 		}
+		err = flow.prevalidateHeadersProofOfWork(anticoneHeadersMessage.BlockHeaders)
+		if err != nil {
+			return err
+		}
 		for _, header := range anticoneHeadersMessage.BlockHeaders {
 			err = flow.processHeader(consensus, header)
 			if err != nil {
@@ -500,6 +550,33 @@ func (flow *handleIBDFlow) receiveHeaders() (msgIBDBlock *appmessage.BlockHeader
 	}
 }
 
+// prevalidateHeadersProofOfWork checks the proof of work of the given headers in parallel. It's meant to
+// run before the headers are validated and inserted one by one, which then reuses the calculated proof of
+// work values instead of calculating them sequentially.
+func (flow *handleIBDFlow) prevalidateHeadersProofOfWork(msgBlockHeaders []*appmessage.MsgBlockHeader) error {
+	headers := make([]externalapi.BlockHeader, len(msgBlockHeaders))
+	for i, msgBlockHeader := range msgBlockHeaders {
+		headers[i] = appmessage.BlockHeaderToDomainBlockHeader(msgBlockHeader)
+	}
+	return flow.prevalidateDomainHeadersProofOfWork(headers)
+}
+
+func (flow *handleIBDFlow) prevalidateDomainHeadersProofOfWork(headers []externalapi.BlockHeader) error {
+	if flow.Config().NetParams().SkipProofOfWork {
+		return nil
+	}
+
+	isValid, firstInvalidIndex := pow.CheckProofOfWorkByBitsInParallel(headers)
+	if !isValid {
+		invalidHeaderHash := consensushashing.HeaderHash(headers[firstInvalidIndex])
+		log.WithBlockHash(invalidHeaderHash).WithPeerID(flow.peer.ID()).
+			Infof("Rejected block header %s from %s during IBD: invalid proof of work", invalidHeaderHash, flow.peer)
+		return protocolerrors.Errorf(true, "got block header %s with invalid proof of work during IBD",
+			invalidHeaderHash)
+	}
+	return nil
+}
+
 func (flow *handleIBDFlow) processHeader(consensus externalapi.Consensus, msgBlockHeader *appmessage.MsgBlockHeader) error {
 	header := appmessage.BlockHeaderToDomainBlockHeader(msgBlockHeader)
 	block := &externalapi.DomainBlock{
@@ -525,7 +602,8 @@ func (flow *handleIBDFlow) processHeader(consensus externalapi.Consensus, msgBlo
 		if errors.Is(err, ruleerrors.ErrDuplicateBlock) {
 			log.Debugf("Skipping block header %s as it is a duplicate", blockHash)
 		} else {
-			log.Infof("Rejected block header %s from %s during IBD: %s", blockHash, flow.peer, err)
+			log.WithBlockHash(blockHash).WithPeerID(flow.peer.ID()).
+				Infof("Rejected block header %s from %s during IBD: %s", blockHash, flow.peer, err)
 			return protocolerrors.Wrapf(true, err, "got invalid block header %s during IBD", blockHash)
 		}
 	}
@@ -1,5 +1,11 @@
 package blockrelay
 
+import (
+	"time"
+
+	"github.com/Kash-Protocol/kashd/infrastructure/metrics"
+)
+
 type ibdProgressReporter struct {
 	lowDAAScore                 uint64
 	highDAAScore                uint64
@@ -7,6 +13,8 @@ type ibdProgressReporter struct {
 	totalDAAScoreDifference     uint64
 	lastReportedProgressPercent int
 	processed                   int
+	lastReportedProcessed       int
+	lastReportTime              time.Time
 }
 
 func newIBDProgressReporter(lowDAAScore uint64, highDAAScore uint64, objectName string) *ibdProgressReporter {
@@ -21,6 +29,8 @@ func newIBDProgressReporter(lowDAAScore uint64, highDAAScore uint64, objectName
 		totalDAAScoreDifference:     highDAAScore - lowDAAScore,
 		lastReportedProgressPercent: 0,
 		processed:                   0,
+		lastReportedProcessed:       0,
+		lastReportTime:              time.Now(),
 	}
 }
 
@@ -37,9 +47,25 @@ func (ipr *ibdProgressReporter) reportProgress(processedDelta int, highestProces
 		// Avoid a negative diff
 		relativeDAAScore = highestProcessedDAAScore - ipr.lowDAAScore
 	}
-	progressPercent := int((float64(relativeDAAScore) / float64(ipr.totalDAAScoreDifference)) * 100)
+	progress := float64(relativeDAAScore) / float64(ipr.totalDAAScoreDifference)
+	metrics.SetIBDProgress(ipr.objectName, processedDelta, progress)
+
+	progressPercent := int(progress * 100)
 	if progressPercent > ipr.lastReportedProgressPercent {
-		log.Infof("IBD: Processed %d %s (%d%%)", ipr.processed, ipr.objectName, progressPercent)
+		now := time.Now()
+		log.Infof("IBD: Processed %d %s (%d%%, %.1f %s/s)", ipr.processed, ipr.objectName, progressPercent,
+			ipr.rate(now), ipr.objectName)
 		ipr.lastReportedProgressPercent = progressPercent
+		ipr.lastReportedProcessed = ipr.processed
+		ipr.lastReportTime = now
+	}
+}
+
+// rate returns the number of objects processed per second since the last report
+func (ipr *ibdProgressReporter) rate(now time.Time) float64 {
+	elapsed := now.Sub(ipr.lastReportTime)
+	if elapsed <= 0 {
+		return 0
 	}
+	return float64(ipr.processed-ipr.lastReportedProcessed) / elapsed.Seconds()
 }
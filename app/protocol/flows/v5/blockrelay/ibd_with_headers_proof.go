@@ -130,6 +130,16 @@ func (flow *handleIBDFlow) syncAndValidatePruningPointProof() (*externalapi.Doma
 			"expected: %s, got: %s", appmessage.CmdPruningPointProof, message.Command())
 	}
 	pruningPointProof := appmessage.MsgPruningPointProofToDomainPruningPointProof(pruningPointProofMessage)
+
+	var proofHeaders []externalapi.BlockHeader
+	for _, levelHeaders := range pruningPointProof.Headers {
+		proofHeaders = append(proofHeaders, levelHeaders...)
+	}
+	err = flow.prevalidateDomainHeadersProofOfWork(proofHeaders)
+	if err != nil {
+		return nil, err
+	}
+
 	err = flow.Domain().Consensus().ValidatePruningPointProof(pruningPointProof)
 	if err != nil {
 		if errors.As(err, &ruleerrors.RuleError{}) {
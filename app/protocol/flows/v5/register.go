@@ -41,7 +41,7 @@ func registerAddressFlows(m protocolManager, router *routerpkg.Router, isStoppin
 	return []*common.Flow{
 		m.RegisterFlow("SendAddresses", router, []appmessage.MessageCommand{appmessage.CmdRequestAddresses}, isStopping, errChan,
 			func(incomingRoute *routerpkg.Route, peer *peerpkg.Peer) error {
-				return addressexchange.SendAddresses(m.Context(), incomingRoute, outgoingRoute)
+				return addressexchange.SendAddresses(m.Context(), incomingRoute, outgoingRoute, peer)
 			},
 		),
 
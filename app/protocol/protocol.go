@@ -74,7 +74,8 @@ func (m *Manager) routerInitializer(router *routerpkg.Router, netConnection *net
 		defer m.context.RemoveFromPeers(peer)
 
 		var flows []*common.Flow
-		log.Infof("Registering p2p flows for peer %s for protocol version %d", peer, peer.ProtocolVersion())
+		log.WithPeerID(peer.ID()).
+			Infof("Registering p2p flows for peer %s for protocol version %d", peer, peer.ProtocolVersion())
 		switch peer.ProtocolVersion() {
 		case 5:
 			flows = v5.Register(m, router, errChan, &isStopping)
@@ -1,9 +1,12 @@
 package rpc
 
 import (
+	"time"
+
 	"github.com/Kash-Protocol/kashd/app/appmessage"
 	"github.com/Kash-Protocol/kashd/app/rpc/rpccontext"
 	"github.com/Kash-Protocol/kashd/app/rpc/rpchandlers"
+	"github.com/Kash-Protocol/kashd/infrastructure/metrics"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/router"
 	"github.com/pkg/errors"
@@ -51,6 +54,8 @@ var handlers = map[appmessage.MessageCommand]handler{
 	appmessage.CmdNotifyNewBlockTemplateRequestMessage:                      rpchandlers.HandleNotifyNewBlockTemplate,
 	appmessage.CmdGetCoinSupplyRequestMessage:                               rpchandlers.HandleGetCoinSupply,
 	appmessage.CmdGetMempoolEntriesByAddressesRequestMessage:                rpchandlers.HandleGetMempoolEntriesByAddresses,
+	appmessage.CmdGetLogLevelsRequestMessage:                                rpchandlers.HandleGetLogLevels,
+	appmessage.CmdSetLogLevelRequestMessage:                                 rpchandlers.HandleSetLogLevel,
 }
 
 func (m *Manager) routerInitializer(router *router.Router, netConnection *netadapter.NetConnection) {
@@ -83,10 +88,12 @@ func (m *Manager) handleIncomingMessages(router *router.Router, incomingRoute *r
 		if !ok {
 			return err
 		}
+		start := time.Now()
 		response, err := handler(m.context, router, request)
 		if err != nil {
 			return err
 		}
+		metrics.ObserveRPCRequest(request.Command().Name(), time.Since(start))
 		err = outgoingRoute.Enqueue(response)
 		if err != nil {
 			return err
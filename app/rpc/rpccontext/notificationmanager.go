@@ -452,9 +452,7 @@ func (nl *NotificationListener) scriptPubKeyStringToAddressString(scriptPublicKe
 	}
 
 	var addressString string
-	if scriptType == txscript.NonStandardTy {
-		addressString = ""
-	} else {
+	if scriptType != txscript.NonStandardTy && address != nil {
 		addressString = address.String()
 	}
 	return addressString, nil
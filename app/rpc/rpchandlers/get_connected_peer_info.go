@@ -1,6 +1,8 @@
 package rpchandlers
 
 import (
+	"encoding/hex"
+
 	"github.com/Kash-Protocol/kashd/app/appmessage"
 	"github.com/Kash-Protocol/kashd/app/rpc/rpccontext"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/router"
@@ -22,6 +24,8 @@ func HandleGetConnectedPeerInfo(context *rpccontext.Context, _ *router.Router, _
 			AdvertisedProtocolVersion: peer.AdvertisedProtocolVersion(),
 			TimeConnected:             peer.TimeConnected().Milliseconds(),
 			IsIBDPeer:                 peer == ibdPeer,
+			TransportSecurity:         string(peer.Connection().TransportSecurity()),
+			IdentityKey:               hex.EncodeToString(peer.Connection().RemoteIdentityKey()),
 		}
 		infos = append(infos, info)
 	}
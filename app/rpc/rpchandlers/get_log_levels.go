@@ -0,0 +1,25 @@
+package rpchandlers
+
+import (
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/app/rpc/rpccontext"
+	"github.com/Kash-Protocol/kashd/infrastructure/logger"
+	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/router"
+)
+
+// HandleGetLogLevels handles the respectively named RPC command
+func HandleGetLogLevels(_ *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	subsystems := logger.SupportedSubsystems()
+	subsystemLogLevels := make([]*appmessage.SubsystemLogLevel, 0, len(subsystems))
+	for _, subsystem := range subsystems {
+		level, ok := logger.SubsystemLevel(subsystem)
+		if !ok {
+			continue
+		}
+		subsystemLogLevels = append(subsystemLogLevels, &appmessage.SubsystemLogLevel{
+			Subsystem: subsystem,
+			Level:     level.Name(),
+		})
+	}
+	return appmessage.NewGetLogLevelsResponseMessage(subsystemLogLevels), nil
+}
@@ -76,7 +76,8 @@ func HandleSubmitBlock(context *rpccontext.Context, _ *router.Router, request ap
 		}, nil
 	}
 
-	log.Infof("Accepted block %s via submitBlock", consensushashing.BlockHash(domainBlock))
+	blockHash := consensushashing.BlockHash(domainBlock)
+	log.WithBlockHash(blockHash).Infof("Accepted block %s via submitBlock", blockHash)
 
 	response := appmessage.NewSubmitBlockResponseMessage()
 	return response, nil
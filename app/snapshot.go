@@ -0,0 +1,76 @@
+package app
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+
+	"github.com/Kash-Protocol/kashd/app/snapshot"
+	"github.com/Kash-Protocol/kashd/domain"
+	"github.com/Kash-Protocol/kashd/infrastructure/config"
+	"github.com/pkg/errors"
+)
+
+// exportSnapshot writes a snapshot of the pruning point to the file given by
+// --export-snapshot. The file is first written under a temporary name, so
+// that an interrupted export never leaves a truncated snapshot behind.
+func exportSnapshot(cfg *config.Config, domain domain.Domain) error {
+	temporaryFileName := cfg.ExportSnapshot + ".tmp"
+	file, err := os.Create(temporaryFileName)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.Remove(temporaryFileName)
+	defer file.Close()
+
+	hasher := sha256.New()
+	bufferedWriter := bufio.NewWriter(io.MultiWriter(file, hasher))
+	header, err := snapshot.Export(domain.Consensus(), cfg.ActiveNetParams, bufferedWriter)
+	if err != nil {
+		return err
+	}
+	err = bufferedWriter.Flush()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = file.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = os.Rename(temporaryFileName, cfg.ExportSnapshot)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Infof("Exported a snapshot of pruning point %s to %s", header.PruningPoint, cfg.ExportSnapshot)
+	log.Infof("Snapshot SHA256: %s", hex.EncodeToString(hasher.Sum(nil)))
+	return nil
+}
+
+// importSnapshot bootstraps the node from the snapshot file given by
+// --import-snapshot. Since the flag may be kept across restarts, a snapshot
+// the node was already bootstrapped from is skipped.
+func importSnapshot(cfg *config.Config, domain domain.Domain) error {
+	file, err := os.Open(cfg.ImportSnapshot)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer file.Close()
+
+	log.Infof("Importing the snapshot in %s", cfg.ImportSnapshot)
+	hasher := sha256.New()
+	reader := io.TeeReader(bufio.NewReader(file), hasher)
+	header, isImported, err := snapshot.Import(domain, cfg.ActiveNetParams, reader)
+	if err != nil {
+		return errors.Wrapf(err, "failed importing the snapshot in %s", cfg.ImportSnapshot)
+	}
+	if !isImported {
+		return nil
+	}
+
+	log.Infof("Imported a snapshot of pruning point %s. Snapshot SHA256: %s",
+		header.PruningPoint, hex.EncodeToString(hasher.Sum(nil)))
+	return nil
+}
@@ -0,0 +1,262 @@
+package snapshot
+
+import (
+	"io"
+
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/Kash-Protocol/kashd/infrastructure/logger"
+	"github.com/pkg/errors"
+)
+
+const (
+	// headersChunkSize is the maximum number of headers in a single record.
+	// It MUST be >= MergeSetSizeLimit + 1, see GetHashesBetween
+	headersChunkSize = 1 << 10
+
+	// utxoSetChunkSize is the maximum number of UTXOs in a single record
+	utxoSetChunkSize = 10_000
+)
+
+// Export writes a snapshot of the given consensus's pruning point to writer
+func Export(consensus externalapi.Consensus, params *dagconfig.Params, writer io.Writer) (*Header, error) {
+	onEnd := logger.LogAndMeasureExecutionTime(log, "snapshot.Export")
+	defer onEnd()
+
+	pruningPoint, err := consensus.PruningPoint()
+	if err != nil {
+		return nil, err
+	}
+	if pruningPoint.Equal(params.GenesisHash) {
+		return nil, errors.New("cannot export a snapshot while the pruning point is the genesis")
+	}
+	pruningPointHeader, err := consensus.GetBlockHeader(pruningPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	header := &Header{
+		NetworkName:    params.Name,
+		PruningPoint:   pruningPoint,
+		UTXOCommitment: pruningPointHeader.UTXOCommitment(),
+	}
+	log.Infof("Exporting a snapshot of pruning point %s", pruningPoint)
+	err = writeHeader(writer, header)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Exporting the pruning point proof")
+	pruningPointProof, err := consensus.BuildPruningPointProof()
+	if err != nil {
+		return nil, err
+	}
+	err = writeRecord(writer, appmessage.DomainPruningPointProofToMsgPruningPointProof(pruningPointProof))
+	if err != nil {
+		return nil, err
+	}
+
+	err = exportPruningPoints(consensus, writer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = exportPruningPointAndItsAnticone(consensus, params, writer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = exportPruningPointFutureHeaders(consensus, pruningPoint, writer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = exportPruningPointUTXOSet(consensus, pruningPoint, writer)
+	if err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+func exportPruningPoints(consensus externalapi.Consensus, writer io.Writer) error {
+	pruningPointHeaders, err := consensus.PruningPointHeaders()
+	if err != nil {
+		return err
+	}
+
+	msgPruningPointHeaders := make([]*appmessage.MsgBlockHeader, len(pruningPointHeaders))
+	for i, header := range pruningPointHeaders {
+		msgPruningPointHeaders[i] = appmessage.DomainBlockHeaderToBlockHeader(header)
+	}
+	return writeRecord(writer, appmessage.NewMsgPruningPoints(msgPruningPointHeaders))
+}
+
+// exportPruningPointAndItsAnticone writes the pruning point and its anticone
+// along with their trusted data. Like the syncer side of IBD, the DAA window
+// headers and GHOSTDAG data the blocks share are written only once, and every
+// block refers to them by index.
+func exportPruningPointAndItsAnticone(consensus externalapi.Consensus, params *dagconfig.Params, writer io.Writer) error {
+	pointAndItsAnticone, err := consensus.PruningPointAndItsAnticone()
+	if err != nil {
+		return err
+	}
+	log.Infof("Exporting the pruning point and its anticone (%d blocks)", len(pointAndItsAnticone))
+
+	windowSize := params.DifficultyAdjustmentWindowSize
+	daaWindowBlocks := make([]*externalapi.TrustedDataDataDAAHeader, 0, windowSize)
+	daaWindowHashesToIndex := make(map[externalapi.DomainHash]int, windowSize)
+	trustedDataDAABlockIndexes := make(map[externalapi.DomainHash][]uint64)
+
+	ghostdagData := make([]*externalapi.BlockGHOSTDAGDataHashPair, 0)
+	ghostdagDataHashToIndex := make(map[externalapi.DomainHash]int)
+	trustedDataGHOSTDAGDataIndexes := make(map[externalapi.DomainHash][]uint64)
+	for _, blockHash := range pointAndItsAnticone {
+		blockDAAWindowHashes, err := consensus.BlockDAAWindowHashes(blockHash)
+		if err != nil {
+			return err
+		}
+
+		trustedDataDAABlockIndexes[*blockHash] = make([]uint64, 0, windowSize)
+		for i, daaBlockHash := range blockDAAWindowHashes {
+			index, exists := daaWindowHashesToIndex[*daaBlockHash]
+			if !exists {
+				trustedDataDataDAAHeader, err := consensus.TrustedDataDataDAAHeader(blockHash, daaBlockHash, uint64(i))
+				if err != nil {
+					return err
+				}
+				daaWindowBlocks = append(daaWindowBlocks, trustedDataDataDAAHeader)
+				index = len(daaWindowBlocks) - 1
+				daaWindowHashesToIndex[*daaBlockHash] = index
+			}
+
+			trustedDataDAABlockIndexes[*blockHash] = append(trustedDataDAABlockIndexes[*blockHash], uint64(index))
+		}
+
+		ghostdagDataBlockHashes, err := consensus.TrustedBlockAssociatedGHOSTDAGDataBlockHashes(blockHash)
+		if err != nil {
+			return err
+		}
+
+		trustedDataGHOSTDAGDataIndexes[*blockHash] = make([]uint64, 0, params.K)
+		for _, ghostdagDataBlockHash := range ghostdagDataBlockHashes {
+			index, exists := ghostdagDataHashToIndex[*ghostdagDataBlockHash]
+			if !exists {
+				data, err := consensus.TrustedGHOSTDAGData(ghostdagDataBlockHash)
+				if err != nil {
+					return err
+				}
+				ghostdagData = append(ghostdagData, &externalapi.BlockGHOSTDAGDataHashPair{
+					Hash:         ghostdagDataBlockHash,
+					GHOSTDAGData: data,
+				})
+				index = len(ghostdagData) - 1
+				ghostdagDataHashToIndex[*ghostdagDataBlockHash] = index
+			}
+
+			trustedDataGHOSTDAGDataIndexes[*blockHash] = append(trustedDataGHOSTDAGDataIndexes[*blockHash], uint64(index))
+		}
+	}
+
+	err = writeRecord(writer, appmessage.DomainTrustedDataToTrustedData(daaWindowBlocks, ghostdagData))
+	if err != nil {
+		return err
+	}
+
+	for _, blockHash := range pointAndItsAnticone {
+		block, found, err := consensus.GetBlock(blockHash)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.Errorf("pruning point anticone block %s not found", blockHash)
+		}
+
+		err = writeRecord(writer, appmessage.DomainBlockWithTrustedDataToBlockWithTrustedDataV4(
+			block, trustedDataDAABlockIndexes[*blockHash], trustedDataGHOSTDAGDataIndexes[*blockHash]))
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeRecord(writer, appmessage.NewMsgDoneBlocksWithTrustedData())
+}
+
+// exportPruningPointFutureHeaders writes the headers between the pruning point
+// and the headers selected tip. The importing node needs them in order to
+// verify that the pruning point is deep enough in the DAG.
+func exportPruningPointFutureHeaders(consensus externalapi.Consensus, pruningPoint *externalapi.DomainHash,
+	writer io.Writer) error {
+
+	headersSelectedTip, err := consensus.GetHeadersSelectedTip()
+	if err != nil {
+		return err
+	}
+	log.Infof("Exporting the headers between the pruning point and %s", headersSelectedTip)
+
+	lowHash := pruningPoint
+	exportedHeaders := 0
+	for !lowHash.Equal(headersSelectedTip) {
+		blockHashes, _, err := consensus.GetHashesBetween(lowHash, headersSelectedTip, headersChunkSize)
+		if err != nil {
+			return err
+		}
+		if len(blockHashes) == 0 {
+			break
+		}
+
+		blockHeaders := make([]*appmessage.MsgBlockHeader, len(blockHashes))
+		for i, blockHash := range blockHashes {
+			blockHeader, err := consensus.GetBlockHeader(blockHash)
+			if err != nil {
+				return err
+			}
+			blockHeaders[i] = appmessage.DomainBlockHeaderToBlockHeader(blockHeader)
+		}
+		err = writeRecord(writer, appmessage.NewBlockHeadersMessage(blockHeaders))
+		if err != nil {
+			return err
+		}
+
+		exportedHeaders += len(blockHashes)
+		log.Debugf("Exported %d headers so far", exportedHeaders)
+
+		// The next lowHash is the last element in blockHashes
+		lowHash = blockHashes[len(blockHashes)-1]
+	}
+	log.Infof("Exported %d headers", exportedHeaders)
+
+	return writeRecord(writer, appmessage.NewMsgDoneHeaders())
+}
+
+func exportPruningPointUTXOSet(consensus externalapi.Consensus, pruningPoint *externalapi.DomainHash,
+	writer io.Writer) error {
+
+	log.Infof("Exporting the pruning point UTXO set")
+	var fromOutpoint *externalapi.DomainOutpoint
+	exportedUTXOs := 0
+	for {
+		pruningPointUTXOs, err := consensus.GetPruningPointUTXOs(pruningPoint, fromOutpoint, utxoSetChunkSize)
+		if err != nil {
+			return err
+		}
+
+		if len(pruningPointUTXOs) > 0 {
+			err = writeRecord(writer, appmessage.NewMsgPruningPointUTXOSetChunk(
+				appmessage.DomainOutpointAndUTXOEntryPairsToOutpointAndUTXOEntryPairs(pruningPointUTXOs)))
+			if err != nil {
+				return err
+			}
+			exportedUTXOs += len(pruningPointUTXOs)
+			fromOutpoint = pruningPointUTXOs[len(pruningPointUTXOs)-1].Outpoint
+		}
+
+		if len(pruningPointUTXOs) < utxoSetChunkSize {
+			break
+		}
+	}
+	log.Infof("Exported %d UTXOs", exportedUTXOs)
+
+	return writeRecord(writer, appmessage.NewMsgDonePruningPointUTXOSetChunks())
+}
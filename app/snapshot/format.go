@@ -0,0 +1,189 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// A snapshot file starts with a header, which consists of:
+//
+//	magic            8 bytes  "KASHSNAP"
+//	format version   uint32
+//	network name     uint32 length + bytes
+//	pruning point    32 bytes
+//	UTXO commitment  32 bytes
+//
+// The header is followed by a sequence of records, each of which is a
+// protowire.KashdMessage prefixed by its uint32 length. These are the same
+// messages a syncer sends during IBD with a headers proof, in this order:
+//
+//	MsgPruningPointProof
+//	MsgPruningPoints
+//	MsgTrustedData
+//	MsgBlockWithTrustedDataV4 for the pruning point and each block in its anticone
+//	MsgDoneBlocksWithTrustedData
+//	BlockHeadersMessage for the headers in the future of the pruning point, in chunks
+//	MsgDoneHeaders
+//	MsgPruningPointUTXOSetChunk for the pruning point UTXO set, in chunks
+//	MsgDonePruningPointUTXOSetChunks
+//
+// All integers are little-endian.
+
+var magic = [8]byte{'K', 'A', 'S', 'H', 'S', 'N', 'A', 'P'}
+
+const (
+	formatVersion = 1
+
+	// maxRecordSize is the maximum size of a single record. The largest
+	// record is the trusted data, whose size is bounded by the DAA window
+	// size and by the size of the pruning point anticone
+	maxRecordSize = 512 * 1024 * 1024
+
+	maxNetworkNameLength = 256
+)
+
+// Header is the header of a snapshot file
+type Header struct {
+	NetworkName    string
+	PruningPoint   *externalapi.DomainHash
+	UTXOCommitment *externalapi.DomainHash
+}
+
+func writeHeader(writer io.Writer, header *Header) error {
+	buffer := &bytes.Buffer{}
+	buffer.Write(magic[:])
+	writeUint32(buffer, formatVersion)
+	writeUint32(buffer, uint32(len(header.NetworkName)))
+	buffer.WriteString(header.NetworkName)
+	buffer.Write(header.PruningPoint.ByteSlice())
+	buffer.Write(header.UTXOCommitment.ByteSlice())
+	_, err := writer.Write(buffer.Bytes())
+	return errors.WithStack(err)
+}
+
+// ReadHeader reads the header of a snapshot file
+func ReadHeader(reader io.Reader) (*Header, error) {
+	var fileMagic [8]byte
+	_, err := io.ReadFull(reader, fileMagic[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading the snapshot header")
+	}
+	if fileMagic != magic {
+		return nil, errors.New("the file is not a kashd snapshot")
+	}
+	version, err := readUint32(reader)
+	if err != nil {
+		return nil, err
+	}
+	if version != formatVersion {
+		return nil, errors.Errorf("unsupported snapshot format version %d. Expected version: %d",
+			version, formatVersion)
+	}
+
+	networkNameLength, err := readUint32(reader)
+	if err != nil {
+		return nil, err
+	}
+	if networkNameLength > maxNetworkNameLength {
+		return nil, errors.Errorf("snapshot network name length %d exceeds the maximum of %d",
+			networkNameLength, maxNetworkNameLength)
+	}
+	networkName := make([]byte, networkNameLength)
+	_, err = io.ReadFull(reader, networkName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading the snapshot header")
+	}
+
+	pruningPoint, err := readHash(reader)
+	if err != nil {
+		return nil, err
+	}
+	utxoCommitment, err := readHash(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Header{
+		NetworkName:    string(networkName),
+		PruningPoint:   pruningPoint,
+		UTXOCommitment: utxoCommitment,
+	}, nil
+}
+
+func writeRecord(writer io.Writer, message appmessage.Message) error {
+	kashdMessage, err := protowire.FromAppMessage(message)
+	if err != nil {
+		return err
+	}
+	serializedMessage, err := proto.Marshal(kashdMessage)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(serializedMessage) > maxRecordSize {
+		return errors.Errorf("%s record of size %d exceeds the maximum record size of %d",
+			message.Command(), len(serializedMessage), maxRecordSize)
+	}
+
+	lengthBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(lengthBytes, uint32(len(serializedMessage)))
+	_, err = writer.Write(lengthBytes)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = writer.Write(serializedMessage)
+	return errors.WithStack(err)
+}
+
+func readRecord(reader io.Reader) (appmessage.Message, error) {
+	length, err := readUint32(reader)
+	if err != nil {
+		return nil, err
+	}
+	if length > maxRecordSize {
+		return nil, errors.Errorf("record of size %d exceeds the maximum record size of %d",
+			length, maxRecordSize)
+	}
+	serializedMessage := make([]byte, length)
+	_, err = io.ReadFull(reader, serializedMessage)
+	if err != nil {
+		return nil, errors.Wrap(err, "the snapshot is truncated")
+	}
+
+	kashdMessage := &protowire.KashdMessage{}
+	err = proto.Unmarshal(serializedMessage, kashdMessage)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed snapshot record")
+	}
+	return kashdMessage.ToAppMessage()
+}
+
+func writeUint32(buffer *bytes.Buffer, value uint32) {
+	var serializedValue [4]byte
+	binary.LittleEndian.PutUint32(serializedValue[:], value)
+	buffer.Write(serializedValue[:])
+}
+
+func readUint32(reader io.Reader) (uint32, error) {
+	var serializedValue [4]byte
+	_, err := io.ReadFull(reader, serializedValue[:])
+	if err != nil {
+		return 0, errors.Wrap(err, "the snapshot is truncated")
+	}
+	return binary.LittleEndian.Uint32(serializedValue[:]), nil
+}
+
+func readHash(reader io.Reader) (*externalapi.DomainHash, error) {
+	var hashBytes [externalapi.DomainHashSize]byte
+	_, err := io.ReadFull(reader, hashBytes[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "the snapshot is truncated")
+	}
+	return externalapi.NewDomainHashFromByteArray(&hashBytes), nil
+}
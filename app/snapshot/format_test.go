@@ -0,0 +1,108 @@
+package snapshot
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+)
+
+func TestHeaderSerialization(t *testing.T) {
+	header := &Header{
+		NetworkName:    dagconfig.MainnetParams.Name,
+		PruningPoint:   externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1, 2, 3}),
+		UTXOCommitment: externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{4, 5, 6}),
+	}
+
+	buffer := &bytes.Buffer{}
+	err := writeHeader(buffer, header)
+	if err != nil {
+		t.Fatalf("writeHeader: %s", err)
+	}
+	serializedHeader := buffer.Bytes()
+
+	deserializedHeader, err := ReadHeader(bytes.NewReader(serializedHeader))
+	if err != nil {
+		t.Fatalf("ReadHeader: %s", err)
+	}
+	if !reflect.DeepEqual(header, deserializedHeader) {
+		t.Fatalf("header and deserializedHeader are not equal\n"+
+			"header:%+v\ndeserializedHeader:%+v", header, deserializedHeader)
+	}
+
+	tests := []struct {
+		name          string
+		modify        func([]byte) []byte
+		expectedError string
+	}{
+		{
+			name:          "bad magic",
+			modify:        func(b []byte) []byte { b[0] = 'X'; return b },
+			expectedError: "not a kashd snapshot",
+		},
+		{
+			name:          "unsupported version",
+			modify:        func(b []byte) []byte { b[8] = formatVersion + 1; return b },
+			expectedError: "unsupported snapshot format version",
+		},
+		{
+			name:          "truncated",
+			modify:        func(b []byte) []byte { return b[:len(b)-1] },
+			expectedError: "truncated",
+		},
+	}
+	for _, test := range tests {
+		modifiedHeader := test.modify(append([]byte{}, serializedHeader...))
+		_, err := ReadHeader(bytes.NewReader(modifiedHeader))
+		if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+			t.Errorf("%s: expected an error containing %q, got: %v", test.name, test.expectedError, err)
+		}
+	}
+}
+
+func TestRecordSerialization(t *testing.T) {
+	messages := []appmessage.Message{
+		appmessage.NewMsgDoneBlocksWithTrustedData(),
+		appmessage.NewMsgDoneHeaders(),
+		appmessage.NewMsgPruningPointUTXOSetChunk([]*appmessage.OutpointAndUTXOEntryPair{{
+			Outpoint: &appmessage.Outpoint{
+				TxID:  *externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{7}),
+				Index: 3,
+			},
+			UTXOEntry: &appmessage.UTXOEntry{
+				Amount:          1000,
+				ScriptPublicKey: &externalapi.ScriptPublicKey{Script: []byte{1, 2}, Version: 0},
+				BlockDAAScore:   12,
+			},
+		}}),
+		appmessage.NewMsgDonePruningPointUTXOSetChunks(),
+	}
+
+	buffer := &bytes.Buffer{}
+	for _, message := range messages {
+		err := writeRecord(buffer, message)
+		if err != nil {
+			t.Fatalf("writeRecord: %s", err)
+		}
+	}
+
+	for _, message := range messages {
+		deserializedMessage, err := readRecord(buffer)
+		if err != nil {
+			t.Fatalf("readRecord: %s", err)
+		}
+		if !reflect.DeepEqual(message, deserializedMessage) {
+			t.Fatalf("message and deserializedMessage are not equal\n"+
+				"message:%+v\ndeserializedMessage:%+v", message, deserializedMessage)
+		}
+	}
+
+	_, err := readRecord(buffer)
+	if err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Fatalf("expected a truncation error, got: %v", err)
+	}
+}
@@ -0,0 +1,388 @@
+package snapshot
+
+import (
+	"io"
+
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/domain"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/ruleerrors"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/Kash-Protocol/kashd/infrastructure/logger"
+	"github.com/pkg/errors"
+)
+
+// Import replaces the consensus of the given domain with the one whose
+// pruning point snapshot is read from reader. The snapshot is trusted no more
+// than a syncing peer is: everything in it is validated the same way as
+// during IBD with a headers proof, and the current consensus is kept if
+// validation fails.
+//
+// If the snapshot pruning point is already one of the pruning points of the
+// domain, as it is when a node that was bootstrapped from the snapshot
+// restarts, the snapshot is not imported and isImported is false.
+func Import(domain domain.Domain, params *dagconfig.Params, reader io.Reader) (
+	header *Header, isImported bool, err error) {
+
+	onEnd := logger.LogAndMeasureExecutionTime(log, "snapshot.Import")
+	defer onEnd()
+
+	header, err = ReadHeader(reader)
+	if err != nil {
+		return nil, false, err
+	}
+	if header.NetworkName != params.Name {
+		return nil, false, errors.Errorf("the snapshot is of network %s, but the node is running on %s",
+			header.NetworkName, params.Name)
+	}
+	if header.PruningPoint.Equal(params.GenesisHash) {
+		return nil, false, errors.New("the genesis pruning point violates finality")
+	}
+	hasPruningPoint, err := hasPruningPoint(domain.Consensus(), header.PruningPoint)
+	if err != nil {
+		return nil, false, err
+	}
+	if hasPruningPoint {
+		log.Infof("Pruning point %s of the snapshot is already a pruning point of the node. "+
+			"Skipping the import", header.PruningPoint)
+		return header, false, nil
+	}
+	log.Infof("Importing a snapshot of pruning point %s", header.PruningPoint)
+
+	pruningPointProof, err := readPruningPointProof(reader)
+	if err != nil {
+		return nil, false, err
+	}
+	proofPruningPoint := consensushashing.HeaderHash(pruningPointProof.Headers[0][len(pruningPointProof.Headers[0])-1])
+	if !proofPruningPoint.Equal(header.PruningPoint) {
+		return nil, false, errors.Errorf("the pruning point proof is of pruning point %s instead of %s",
+			proofPruningPoint, header.PruningPoint)
+	}
+	err = domain.Consensus().ValidatePruningPointProof(pruningPointProof)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "pruning point proof validation failed")
+	}
+
+	err = domain.InitStagingConsensusWithoutGenesis()
+	if err != nil {
+		return nil, false, err
+	}
+	err = importIntoStagingConsensus(domain, header, pruningPointProof, reader)
+	if err != nil {
+		log.Infof("Importing the snapshot was unsuccessful. Deleting the staging consensus")
+		deleteStagingConsensusErr := domain.DeleteStagingConsensus()
+		if deleteStagingConsensusErr != nil {
+			return nil, false, deleteStagingConsensusErr
+		}
+		return nil, false, err
+	}
+
+	log.Infof("Committing the staging consensus and deleting the previous obsolete one")
+	err = domain.CommitStagingConsensus()
+	if err != nil {
+		return nil, false, err
+	}
+	return header, true, nil
+}
+
+// hasPruningPoint returns whether the given block is the current pruning
+// point of consensus or one of the pruning points before it
+func hasPruningPoint(consensus externalapi.Consensus, blockHash *externalapi.DomainHash) (bool, error) {
+	pruningPointHeaders, err := consensus.PruningPointHeaders()
+	if err != nil {
+		return false, err
+	}
+	for _, pruningPointHeader := range pruningPointHeaders {
+		if consensushashing.HeaderHash(pruningPointHeader).Equal(blockHash) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func importIntoStagingConsensus(domain domain.Domain, header *Header,
+	pruningPointProof *externalapi.PruningPointProof, reader io.Reader) error {
+
+	err := domain.StagingConsensus().ApplyPruningPointProof(pruningPointProof)
+	if err != nil {
+		return err
+	}
+
+	err = importPruningPoints(domain, header.PruningPoint, reader)
+	if err != nil {
+		return err
+	}
+
+	err = importPruningPointAndItsAnticone(domain.StagingConsensus(), header.PruningPoint, reader)
+	if err != nil {
+		return err
+	}
+
+	err = importPruningPointFutureHeaders(domain.StagingConsensus(), reader)
+	if err != nil {
+		return err
+	}
+
+	err = checkSnapshotIsAhead(domain)
+	if err != nil {
+		return err
+	}
+
+	isValid, err := domain.StagingConsensus().IsValidPruningPoint(header.PruningPoint)
+	if err != nil {
+		return err
+	}
+	if !isValid {
+		return errors.Errorf("invalid pruning point %s", header.PruningPoint)
+	}
+
+	pruningPointHeader, err := domain.StagingConsensus().GetBlockHeader(header.PruningPoint)
+	if err != nil {
+		return err
+	}
+	if !pruningPointHeader.UTXOCommitment().Equal(header.UTXOCommitment) {
+		return errors.Errorf("the snapshot claims UTXO commitment %s, but the pruning point commits to %s",
+			header.UTXOCommitment, pruningPointHeader.UTXOCommitment())
+	}
+
+	return importPruningPointUTXOSet(domain.StagingConsensus(), header.PruningPoint, reader)
+}
+
+// checkSnapshotIsAhead makes sure that importing the snapshot doesn't
+// replace the current DAG with one that has less blue work
+func checkSnapshotIsAhead(domain domain.Domain) error {
+	snapshotHeadersSelectedTip, err := domain.StagingConsensus().GetHeadersSelectedTip()
+	if err != nil {
+		return err
+	}
+	snapshotHeadersSelectedTipInfo, err := domain.StagingConsensus().GetBlockInfo(snapshotHeadersSelectedTip)
+	if err != nil {
+		return err
+	}
+	virtualSelectedParent, err := domain.Consensus().GetVirtualSelectedParent()
+	if err != nil {
+		return err
+	}
+	virtualSelectedParentInfo, err := domain.Consensus().GetBlockInfo(virtualSelectedParent)
+	if err != nil {
+		return err
+	}
+	if snapshotHeadersSelectedTipInfo.BlueWork.Cmp(virtualSelectedParentInfo.BlueWork) <= 0 {
+		return errors.New("the node's DAG has at least as much blue work as the snapshot")
+	}
+	return nil
+}
+
+func readPruningPointProof(reader io.Reader) (*externalapi.PruningPointProof, error) {
+	message, err := readRecord(reader)
+	if err != nil {
+		return nil, err
+	}
+	msgPruningPointProof, ok := message.(*appmessage.MsgPruningPointProof)
+	if !ok {
+		return nil, unexpectedRecordError(appmessage.CmdPruningPointProof, message)
+	}
+	pruningPointProof := appmessage.MsgPruningPointProofToDomainPruningPointProof(msgPruningPointProof)
+	if len(pruningPointProof.Headers) == 0 || len(pruningPointProof.Headers[0]) == 0 {
+		return nil, errors.New("the pruning point proof is empty")
+	}
+	return pruningPointProof, nil
+}
+
+func importPruningPoints(domain domain.Domain, proofPruningPoint *externalapi.DomainHash, reader io.Reader) error {
+	message, err := readRecord(reader)
+	if err != nil {
+		return err
+	}
+	msgPruningPoints, ok := message.(*appmessage.MsgPruningPoints)
+	if !ok {
+		return unexpectedRecordError(appmessage.CmdPruningPoints, message)
+	}
+	if len(msgPruningPoints.Headers) == 0 {
+		return errors.New("the snapshot contains no pruning points")
+	}
+
+	currentPruningPoint, err := domain.Consensus().PruningPoint()
+	if err != nil {
+		return err
+	}
+	if currentPruningPoint.Equal(proofPruningPoint) {
+		return errors.New("the snapshot pruning point is the same as the current pruning point")
+	}
+
+	headers := make([]externalapi.BlockHeader, len(msgPruningPoints.Headers))
+	for i, header := range msgPruningPoints.Headers {
+		headers[i] = appmessage.BlockHeaderToDomainBlockHeader(header)
+	}
+
+	arePruningPointsViolatingFinality, err := domain.Consensus().ArePruningPointsViolatingFinality(headers)
+	if err != nil {
+		return err
+	}
+	if arePruningPointsViolatingFinality {
+		return errors.New("the snapshot pruning points are violating finality")
+	}
+
+	lastPruningPoint := consensushashing.HeaderHash(headers[len(headers)-1])
+	if !lastPruningPoint.Equal(proofPruningPoint) {
+		return errors.New("the proof pruning point is not equal to the last pruning point in the list")
+	}
+
+	return domain.StagingConsensus().ImportPruningPoints(headers)
+}
+
+func importPruningPointAndItsAnticone(consensus externalapi.Consensus, proofPruningPoint *externalapi.DomainHash,
+	reader io.Reader) error {
+
+	message, err := readRecord(reader)
+	if err != nil {
+		return err
+	}
+	msgTrustedData, ok := message.(*appmessage.MsgTrustedData)
+	if !ok {
+		return unexpectedRecordError(appmessage.CmdTrustedData, message)
+	}
+
+	importedBlocks := 0
+	for ; ; importedBlocks++ {
+		message, err := readRecord(reader)
+		if err != nil {
+			return err
+		}
+		if _, ok := message.(*appmessage.MsgDoneBlocksWithTrustedData); ok {
+			break
+		}
+		block, ok := message.(*appmessage.MsgBlockWithTrustedDataV4)
+		if !ok {
+			return unexpectedRecordError(appmessage.CmdBlockWithTrustedDataV4, message)
+		}
+		if importedBlocks == 0 && !block.Block.Header.BlockHash().Equal(proofPruningPoint) {
+			return errors.New("the first block with trusted data is not the pruning point")
+		}
+
+		err = insertBlockWithTrustedData(consensus, block, msgTrustedData)
+		if err != nil {
+			return err
+		}
+	}
+	if importedBlocks == 0 {
+		return errors.New("the snapshot does not contain the pruning point")
+	}
+
+	log.Infof("Imported the pruning point and its anticone (%d blocks)", importedBlocks)
+	return nil
+}
+
+func insertBlockWithTrustedData(consensus externalapi.Consensus,
+	block *appmessage.MsgBlockWithTrustedDataV4, data *appmessage.MsgTrustedData) error {
+
+	blockWithTrustedData := &externalapi.BlockWithTrustedData{
+		Block:        appmessage.MsgBlockToDomainBlock(block.Block),
+		DAAWindow:    make([]*externalapi.TrustedDataDataDAAHeader, 0, len(block.DAAWindowIndices)),
+		GHOSTDAGData: make([]*externalapi.BlockGHOSTDAGDataHashPair, 0, len(block.GHOSTDAGDataIndices)),
+	}
+
+	for _, index := range block.DAAWindowIndices {
+		if index >= uint64(len(data.DAAWindow)) {
+			return errors.Errorf("DAA window index %d is out of range", index)
+		}
+		blockWithTrustedData.DAAWindow = append(blockWithTrustedData.DAAWindow,
+			appmessage.TrustedDataDataDAABlockV4ToTrustedDataDataDAAHeader(data.DAAWindow[index]))
+	}
+
+	for _, index := range block.GHOSTDAGDataIndices {
+		if index >= uint64(len(data.GHOSTDAGData)) {
+			return errors.Errorf("GHOSTDAG data index %d is out of range", index)
+		}
+		blockWithTrustedData.GHOSTDAGData = append(blockWithTrustedData.GHOSTDAGData,
+			appmessage.GHOSTDAGHashPairToDomainGHOSTDAGHashPair(data.GHOSTDAGData[index]))
+	}
+
+	err := consensus.ValidateAndInsertBlockWithTrustedData(blockWithTrustedData, false)
+	if err != nil {
+		return errors.Wrap(err, "failed validating block with trusted data")
+	}
+	return nil
+}
+
+func importPruningPointFutureHeaders(consensus externalapi.Consensus, reader io.Reader) error {
+	importedHeaders := 0
+	for {
+		message, err := readRecord(reader)
+		if err != nil {
+			return err
+		}
+		if _, ok := message.(*appmessage.MsgDoneHeaders); ok {
+			break
+		}
+		blockHeadersMessage, ok := message.(*appmessage.BlockHeadersMessage)
+		if !ok {
+			return unexpectedRecordError(appmessage.CmdBlockHeaders, message)
+		}
+
+		for _, msgBlockHeader := range blockHeadersMessage.BlockHeaders {
+			block := &externalapi.DomainBlock{
+				Header:       appmessage.BlockHeaderToDomainBlockHeader(msgBlockHeader),
+				Transactions: nil,
+			}
+			blockHash := consensushashing.BlockHash(block)
+			blockInfo, err := consensus.GetBlockInfo(blockHash)
+			if err != nil {
+				return err
+			}
+			if blockInfo.Exists {
+				continue
+			}
+
+			err = consensus.ValidateAndInsertBlock(block, false)
+			if err != nil && !errors.Is(err, ruleerrors.ErrDuplicateBlock) {
+				return errors.Wrapf(err, "invalid block header %s", blockHash)
+			}
+		}
+
+		importedHeaders += len(blockHeadersMessage.BlockHeaders)
+		log.Infof("Imported %d headers so far", importedHeaders)
+	}
+	return nil
+}
+
+func importPruningPointUTXOSet(consensus externalapi.Consensus, pruningPoint *externalapi.DomainHash,
+	reader io.Reader) (err error) {
+
+	defer func() {
+		clearErr := consensus.ClearImportedPruningPointData()
+		if err == nil {
+			err = clearErr
+		}
+	}()
+
+	importedUTXOs := 0
+	for {
+		message, err := readRecord(reader)
+		if err != nil {
+			return err
+		}
+		if _, ok := message.(*appmessage.MsgDonePruningPointUTXOSetChunks); ok {
+			break
+		}
+		chunk, ok := message.(*appmessage.MsgPruningPointUTXOSetChunk)
+		if !ok {
+			return unexpectedRecordError(appmessage.CmdPruningPointUTXOSetChunk, message)
+		}
+
+		err = consensus.AppendImportedPruningPointUTXOs(
+			appmessage.OutpointAndUTXOEntryPairsToDomainOutpointAndUTXOEntryPairs(chunk.OutpointAndUTXOEntryPairs))
+		if err != nil {
+			return err
+		}
+		importedUTXOs += len(chunk.OutpointAndUTXOEntryPairs)
+	}
+	log.Infof("Imported %d UTXOs. Validating them against the pruning point UTXO commitment", importedUTXOs)
+
+	return consensus.ValidateAndInsertImportedPruningPoint(pruningPoint)
+}
+
+func unexpectedRecordError(expectedCommand appmessage.MessageCommand, message appmessage.Message) error {
+	return errors.Errorf("unexpected snapshot record. Expected: %s, got: %s", expectedCommand, message.Command())
+}
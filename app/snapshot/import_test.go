@@ -0,0 +1,143 @@
+package snapshot
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/domain"
+	"github.com/Kash-Protocol/kashd/domain/consensus"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/Kash-Protocol/kashd/domain/miningmanager/mempool"
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database"
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database/ldb"
+)
+
+func snapshotTestConsensusConfig() *consensus.Config {
+	consensusConfig := &consensus.Config{Params: dagconfig.SimnetParams}
+	consensusConfig.SkipProofOfWork = true
+	// This is done to make a pruning depth of 6 blocks
+	consensusConfig.TargetTimePerBlock = time.Minute
+	consensusConfig.FinalityDuration = 2 * consensusConfig.TargetTimePerBlock
+	consensusConfig.K = 0
+	consensusConfig.PruningProofM = 20
+	return consensusConfig
+}
+
+func openTestDomain(t *testing.T, dbPath string) (domain.Domain, database.Database) {
+	db, err := ldb.NewLevelDB(dbPath, 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %+v", err)
+	}
+	consensusConfig := snapshotTestConsensusConfig()
+	domainInstance, err := domain.New(consensusConfig, mempool.DefaultConfig(&consensusConfig.Params), db)
+	if err != nil {
+		t.Fatalf("New: %+v", err)
+	}
+	return domainInstance, db
+}
+
+// addChain adds a chain of blockCount blocks to the consensus of
+// domainInstance, spaced TargetTimePerBlock apart
+func addChain(t *testing.T, domainInstance domain.Domain, blockCount int) {
+	consensusConfig := snapshotTestConsensusConfig()
+	coinbaseData := &externalapi.DomainCoinbaseData{
+		ScriptPublicKey: &externalapi.ScriptPublicKey{},
+		ExtraData:       []byte{},
+	}
+	for i := 0; i < blockCount; i++ {
+		block, err := domainInstance.Consensus().BuildBlock(coinbaseData, nil)
+		if err != nil {
+			t.Fatalf("BuildBlock: %+v", err)
+		}
+		selectedParent, err := domainInstance.Consensus().GetVirtualSelectedParent()
+		if err != nil {
+			t.Fatalf("GetVirtualSelectedParent: %+v", err)
+		}
+		selectedParentHeader, err := domainInstance.Consensus().GetBlockHeader(selectedParent)
+		if err != nil {
+			t.Fatalf("GetBlockHeader: %+v", err)
+		}
+		mutableHeader := block.Header.ToMutable()
+		mutableHeader.SetTimeInMilliseconds(
+			selectedParentHeader.TimeInMilliseconds() + consensusConfig.TargetTimePerBlock.Milliseconds())
+		block.Header = mutableHeader.ToImmutable()
+
+		err = domainInstance.Consensus().ValidateAndInsertBlock(block, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertBlock: %+v", err)
+		}
+	}
+}
+
+func TestImportOnRestart(t *testing.T) {
+	consensusConfig := snapshotTestConsensusConfig()
+
+	sourceDomain, sourceDB := openTestDomain(t, t.TempDir())
+	defer sourceDB.Close()
+	addChain(t, sourceDomain, 30)
+	sourcePruningPoint, err := sourceDomain.Consensus().PruningPoint()
+	if err != nil {
+		t.Fatalf("PruningPoint: %+v", err)
+	}
+
+	buffer := &bytes.Buffer{}
+	_, err = Export(sourceDomain.Consensus(), &consensusConfig.Params, buffer)
+	if err != nil {
+		t.Fatalf("Export: %+v", err)
+	}
+	snapshot := buffer.Bytes()
+
+	dbPath := t.TempDir()
+	importingDomain, importingDB := openTestDomain(t, dbPath)
+	header, isImported, err := Import(importingDomain, &consensusConfig.Params, bytes.NewReader(snapshot))
+	if err != nil {
+		t.Fatalf("Import: %+v", err)
+	}
+	if !isImported {
+		t.Fatalf("the snapshot was not imported into a new node")
+	}
+	if !header.PruningPoint.Equal(sourcePruningPoint) {
+		t.Fatalf("imported pruning point %s. Want: %s", header.PruningPoint, sourcePruningPoint)
+	}
+	err = importingDB.Close()
+	if err != nil {
+		t.Fatalf("Close: %+v", err)
+	}
+
+	// Restarting with the same snapshot keeps the imported consensus
+	restartedDomain, restartedDB := openTestDomain(t, dbPath)
+	defer restartedDB.Close()
+	_, isImported, err = Import(restartedDomain, &consensusConfig.Params, bytes.NewReader(snapshot))
+	if err != nil {
+		t.Fatalf("Import after restart: %+v", err)
+	}
+	if isImported {
+		t.Fatalf("the snapshot was imported again after a restart")
+	}
+	pruningPoint, err := restartedDomain.Consensus().PruningPoint()
+	if err != nil {
+		t.Fatalf("PruningPoint: %+v", err)
+	}
+	if !pruningPoint.Equal(sourcePruningPoint) {
+		t.Fatalf("unexpected pruning point %s after a restart. Want: %s", pruningPoint, sourcePruningPoint)
+	}
+
+	// The node keeps skipping the snapshot once its pruning point moves past it
+	addChain(t, restartedDomain, 10)
+	pruningPoint, err = restartedDomain.Consensus().PruningPoint()
+	if err != nil {
+		t.Fatalf("PruningPoint: %+v", err)
+	}
+	if pruningPoint.Equal(sourcePruningPoint) {
+		t.Fatalf("the pruning point did not move past the snapshot pruning point")
+	}
+	_, isImported, err = Import(restartedDomain, &consensusConfig.Params, bytes.NewReader(snapshot))
+	if err != nil {
+		t.Fatalf("Import after the pruning point moved: %+v", err)
+	}
+	if isImported {
+		t.Fatalf("the snapshot was imported after the pruning point moved past it")
+	}
+}
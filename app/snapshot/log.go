@@ -0,0 +1,7 @@
+package snapshot
+
+import (
+	"github.com/Kash-Protocol/kashd/infrastructure/logger"
+)
+
+var log = logger.RegisterSubSystem("SNAP")
@@ -15,13 +15,7 @@ import (
 func parseCommand(args []string, commandDescs []*commandDescription) (*protowire.KashdMessage, error) {
 	commandName, parameterStrings := args[0], args[1:]
 
-	var commandDesc *commandDescription
-	for _, cd := range commandDescs {
-		if cd.name == commandName {
-			commandDesc = cd
-			break
-		}
-	}
+	commandDesc := findCommandDescription(commandName, commandDescs)
 	if commandDesc == nil {
 		return nil, errors.Errorf("unknown command: %s. Use --list-commands to list all commands", commandName)
 	}
@@ -42,6 +36,15 @@ func parseCommand(args []string, commandDescs []*commandDescription) (*protowire
 	return generateKashdMessage(commandValue, commandDesc)
 }
 
+func findCommandDescription(commandName string, commandDescs []*commandDescription) *commandDescription {
+	for _, commandDesc := range commandDescs {
+		if commandDesc.name == commandName {
+			return commandDesc
+		}
+	}
+	return nil
+}
+
 func setField(commandValue reflect.Value, parameterValue reflect.Value, parameterDesc *parameterDescription) {
 	parameterField := commandValue.Elem().FieldByName(parameterDesc.name)
 
@@ -3,44 +3,54 @@ package main
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/server/grpcserver/protowire"
 )
 
-var commandTypes = []reflect.Type{
-	reflect.TypeOf(protowire.KashdMessage_AddPeerRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetConnectedPeerInfoRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetPeerAddressesRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetCurrentNetworkRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetInfoRequest{}),
-
-	reflect.TypeOf(protowire.KashdMessage_GetBlockRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetBlocksRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetHeadersRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetBlockCountRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetBlockDagInfoRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetSelectedTipHashRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetVirtualSelectedParentBlueScoreRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetVirtualSelectedParentChainFromBlockRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_ResolveFinalityConflictRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_EstimateNetworkHashesPerSecondRequest{}),
-
-	reflect.TypeOf(protowire.KashdMessage_GetBlockTemplateRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_SubmitBlockRequest{}),
-
-	reflect.TypeOf(protowire.KashdMessage_GetMempoolEntryRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetMempoolEntriesRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetMempoolEntriesByAddressesRequest{}),
-
-	reflect.TypeOf(protowire.KashdMessage_SubmitTransactionRequest{}),
-
-	reflect.TypeOf(protowire.KashdMessage_GetUtxosByAddressesRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetBalanceByAddressRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_GetCoinSupplyRequest{}),
-
-	reflect.TypeOf(protowire.KashdMessage_BanRequest{}),
-	reflect.TypeOf(protowire.KashdMessage_UnbanRequest{}),
+const (
+	notificationRequestPrefix     = "Notify"
+	stopNotifyingRequestPrefix    = "StopNotifying"
+	requestMessageSuffix          = "RequestMessage"
+	kashdMessagePayloadOneofField = "payload"
+)
+
+// commandTypes are the wrapped types of all the requests that get a single response, and
+// notificationTypes are the wrapped types of the requests that subscribe to notifications.
+// Both are reflected from the payload of KashdMessage, so new RPC requests are picked up automatically.
+var commandTypes, notificationTypes = requestTypes()
+
+// requestTypes returns the wrapped types of all the RPC requests in the payload of KashdMessage, sorted by name.
+// Requests that stop notifications are omitted, since they're only meaningful within a subscription.
+func requestTypes() (commandTypes []reflect.Type, notificationTypes []reflect.Type) {
+	kashdMessage := &protowire.KashdMessage{}
+	payloadFields := kashdMessage.ProtoReflect().Descriptor().Oneofs().ByName(kashdMessagePayloadOneofField).Fields()
+
+	names := make(map[reflect.Type]string)
+	for i := 0; i < payloadFields.Len(); i++ {
+		payloadField := payloadFields.Get(i)
+		name := string(payloadField.Message().Name())
+		if !strings.HasSuffix(name, requestMessageSuffix) || strings.HasPrefix(name, stopNotifyingRequestPrefix) {
+			continue
+		}
+
+		// Setting the field is the only way to get to the generated wrapper type of a oneof field
+		kashdMessage.ProtoReflect().Set(payloadField, kashdMessage.ProtoReflect().NewField(payloadField))
+		requestType := reflect.TypeOf(kashdMessage.Payload).Elem()
+		names[requestType] = strings.TrimSuffix(name, requestMessageSuffix)
+
+		if strings.HasPrefix(name, notificationRequestPrefix) {
+			notificationTypes = append(notificationTypes, requestType)
+		} else {
+			commandTypes = append(commandTypes, requestType)
+		}
+	}
+
+	for _, types := range [][]reflect.Type{commandTypes, notificationTypes} {
+		sort.Slice(types, func(i, j int) bool { return names[types[i]] < names[types[j]] })
+	}
+	return commandTypes, notificationTypes
 }
 
 type commandDescription struct {
@@ -55,6 +65,14 @@ type parameterDescription struct {
 }
 
 func commandDescriptions() []*commandDescription {
+	return describeCommands(commandTypes)
+}
+
+func notificationDescriptions() []*commandDescription {
+	return describeCommands(notificationTypes)
+}
+
+func describeCommands(commandTypes []reflect.Type) []*commandDescription {
 	commandDescriptions := make([]*commandDescription, len(commandTypes))
 
 	for i, commandTypeWrapped := range commandTypes {
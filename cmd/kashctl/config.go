@@ -15,7 +15,9 @@ type configFlags struct {
 	RPCServer                          string `short:"s" long:"rpcserver" description:"RPC server to connect to"`
 	Timeout                            uint64 `short:"t" long:"timeout" description:"Timeout for the request (in seconds)"`
 	RequestJSON                        string `short:"j" long:"json" description:"The request in JSON format"`
-	ListCommands                       bool   `short:"l" long:"list-commands" description:"List all commands and exit"`
+	ListCommands                       bool   `short:"l" long:"list-commands" description:"List all commands and notifications and exit"`
+	Subscribe                          string `long:"subscribe" description:"Subscribe to the given notification (e.g. NotifyBlockAdded) and print every notification as a JSON line until interrupted. The command parameters are passed to the notification request"`
+	Interactive                        bool   `short:"i" long:"interactive" description:"Start an interactive shell that posts commands and pretty-prints their responses"`
 	AllowConnectionToDifferentVersions bool   `short:"a" long:"allow-connection-to-different-versions" description:"Allow connections to versions different than kashctl's version'"`
 	CommandAndParameters               []string
 	config.NetworkFlags
@@ -27,7 +29,7 @@ func parseConfig() (*configFlags, error) {
 		Timeout:   defaultTimeout,
 	}
 	parser := flags.NewParser(cfg, flags.HelpFlag)
-	parser.Usage = "kashctl [OPTIONS] [COMMAND] [COMMAND PARAMETERS].\n\nCommand can be supplied only if --json is not used." +
+	parser.Usage = "kashctl [OPTIONS] [COMMAND] [COMMAND PARAMETERS].\n\nCommand can be supplied only if --json and --interactive are not used." +
 		"\n\nUse `kashctl --list-commands` to get a list of all commands and their parameters." +
 		"\nFor optional parameters- use '-' without quotes to not pass the parameter.\n"
 	remainingArgs, err := parser.Parse()
@@ -45,10 +47,22 @@ func parseConfig() (*configFlags, error) {
 	}
 
 	cfg.CommandAndParameters = remainingArgs
+	if cfg.Subscribe != "" {
+		if cfg.RequestJSON != "" || cfg.Interactive {
+			return nil, errors.New("--subscribe can't be used with --json or --interactive")
+		}
+		return cfg, nil
+	}
+	if cfg.Interactive {
+		if len(cfg.CommandAndParameters) > 0 || cfg.RequestJSON != "" {
+			return nil, errors.New("--interactive can't be used with --json or a command")
+		}
+		return cfg, nil
+	}
 	if len(cfg.CommandAndParameters) == 0 && cfg.RequestJSON == "" ||
 		len(cfg.CommandAndParameters) > 0 && cfg.RequestJSON != "" {
 
-		return nil, errors.New("Exactly one of --json or a command must be specified")
+		return nil, errors.New("Exactly one of --json, --subscribe, --interactive or a command must be specified")
 	}
 
 	return cfg, nil
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
+	"github.com/Kash-Protocol/kashd/infrastructure/config"
+	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/Kash-Protocol/kashd/infrastructure/network/rpcclient"
+	"github.com/Kash-Protocol/kashd/util"
+	"github.com/jessevdk/go-flags"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const debugScriptCommand = "debug-script"
+
+type debugScriptConfig struct {
+	RPCServer          string `short:"s" long:"rpcserver" description:"RPC server to fetch the transaction or the spent UTXO from"`
+	Transaction        string `long:"transaction" description:"The transaction in the JSON format of submitTransaction, e.g. {\"version\":0,\"inputs\":[...],...}"`
+	TransactionFile    string `long:"transaction-file" description:"A file containing the transaction in the JSON format of submitTransaction"`
+	TransactionID      string `long:"transaction-id" description:"The ID of a mempool transaction to fetch over RPC"`
+	InputIndex         uint32 `short:"i" long:"input" description:"The index of the input whose scripts are executed" default:"0"`
+	SpentScript        string `long:"spent-script" description:"The script public key of the UTXO spent by the input, in hex. If omitted, the UTXO is fetched over RPC"`
+	SpentScriptVersion uint16 `long:"spent-script-version" description:"The version of the script public key of the spent UTXO" default:"0"`
+	SpentAmount        uint64 `long:"spent-amount" description:"The amount of the spent UTXO, in sompi. Required with --spent-script"`
+	Address            string `long:"address" description:"The address of the spent UTXO, used to fetch it over RPC from the UTXO index (default: the pay-to-script-hash address of the redeem script in the signature script)"`
+	JSON               bool   `long:"json" description:"Print a JSON trace of the execution instead of a human readable one"`
+	Interactive        bool   `long:"interactive" description:"Wait for enter before executing every opcode"`
+	config.NetworkFlags
+}
+
+// scriptTraceStep is the state of the script engine after executing an opcode
+type scriptTraceStep struct {
+	Step            int      `json:"step"`
+	Script          string   `json:"script"`
+	ScriptIndex     int      `json:"scriptIndex"`
+	Offset          int      `json:"offset"`
+	Opcode          string   `json:"opcode"`
+	Stack           []string `json:"stack"`
+	AltStack        []string `json:"altStack"`
+	Conditionals    []string `json:"conditionals"`
+	BranchExecuting bool     `json:"branchExecuting"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// scriptTrace is the execution of the scripts of a single transaction input
+type scriptTrace struct {
+	TransactionID string             `json:"transactionId"`
+	InputIndex    uint32             `json:"inputIndex"`
+	Steps         []*scriptTraceStep `json:"steps"`
+	Success       bool               `json:"success"`
+	Error         string             `json:"error,omitempty"`
+}
+
+func parseDebugScriptConfig(args []string) (*debugScriptConfig, error) {
+	cfg := &debugScriptConfig{
+		RPCServer: defaultRPCServer,
+	}
+	parser := flags.NewParser(cfg, flags.HelpFlag)
+	parser.Usage = debugScriptCommand + " [OPTIONS]\n\nExecutes the scripts of a transaction input step by step, " +
+		"printing the executed opcode, the stacks and the state of the conditionals after every step."
+	_, err := parser.ParseArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cfg.ResolveNetwork(parser)
+	if err != nil {
+		return nil, err
+	}
+
+	numTransactionSources := 0
+	for _, source := range []string{cfg.Transaction, cfg.TransactionFile, cfg.TransactionID} {
+		if source != "" {
+			numTransactionSources++
+		}
+	}
+	if numTransactionSources != 1 {
+		return nil, errors.New("Exactly one of --transaction, --transaction-file or --transaction-id must be specified")
+	}
+	if cfg.SpentScript == "" && cfg.SpentAmount != 0 {
+		return nil, errors.New("--spent-amount requires --spent-script")
+	}
+	if cfg.JSON && cfg.Interactive {
+		return nil, errors.New("--json and --interactive cannot be used together")
+	}
+	return cfg, nil
+}
+
+// debugScript executes and prints the trace of the configured transaction input, and
+// returns whether its scripts executed successfully. It doesn't exit the process
+// itself, so that the RPC client is always disconnected.
+func debugScript(args []string) (isSuccess bool, err error) {
+	cfg, err := parseDebugScriptConfig(args)
+	if err != nil {
+		return false, err
+	}
+
+	var rpcClient *rpcclient.RPCClient
+	connect := func() (*rpcclient.RPCClient, error) {
+		if rpcClient != nil {
+			return rpcClient, nil
+		}
+		rpcAddress, err := cfg.NetParams().NormalizeRPCServerAddress(cfg.RPCServer)
+		if err != nil {
+			return nil, err
+		}
+		rpcClient, err = rpcclient.NewRPCClient(rpcAddress)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error connecting to the RPC server")
+		}
+		return rpcClient, nil
+	}
+	defer func() {
+		if rpcClient != nil {
+			rpcClient.Disconnect()
+		}
+	}()
+
+	transaction, err := loadDebuggedTransaction(cfg, connect)
+	if err != nil {
+		return false, err
+	}
+	if int(cfg.InputIndex) >= len(transaction.Inputs) {
+		return false, errors.Errorf("The transaction has only %d inputs", len(transaction.Inputs))
+	}
+	input := transaction.Inputs[cfg.InputIndex]
+
+	input.UTXOEntry, err = loadSpentUTXOEntry(cfg, input, connect)
+	if err != nil {
+		return false, err
+	}
+
+	trace := traceScript(cfg, transaction)
+	if cfg.JSON {
+		traceJSON, err := json.MarshalIndent(trace, "", "    ")
+		if err != nil {
+			return false, err
+		}
+		fmt.Println(string(traceJSON))
+	} else if trace.Success {
+		fmt.Println("The scripts executed successfully")
+	} else {
+		fmt.Printf("The scripts failed: %s\n", trace.Error)
+	}
+	return trace.Success, nil
+}
+
+func loadDebuggedTransaction(cfg *debugScriptConfig,
+	connect func() (*rpcclient.RPCClient, error)) (*externalapi.DomainTransaction, error) {
+
+	if cfg.TransactionID != "" {
+		client, err := connect()
+		if err != nil {
+			return nil, err
+		}
+		response, err := client.GetMempoolEntry(cfg.TransactionID, true, false)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not fetch transaction %s from the mempool", cfg.TransactionID)
+		}
+		return appmessage.RPCTransactionToDomainTransaction(response.Entry.Transaction)
+	}
+
+	transactionJSON := []byte(cfg.Transaction)
+	if cfg.TransactionFile != "" {
+		var err error
+		transactionJSON, err = ioutil.ReadFile(cfg.TransactionFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read %s", cfg.TransactionFile)
+		}
+	}
+
+	rpcTransaction := &protowire.RpcTransaction{}
+	err := protojson.Unmarshal(transactionJSON, rpcTransaction)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse the transaction")
+	}
+	// The transaction is converted the same way it is when submitted
+	message, err := (&protowire.KashdMessage{
+		Payload: &protowire.KashdMessage_SubmitTransactionRequest{
+			SubmitTransactionRequest: &protowire.SubmitTransactionRequestMessage{Transaction: rpcTransaction},
+		},
+	}).ToAppMessage()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse the transaction")
+	}
+	return appmessage.RPCTransactionToDomainTransaction(message.(*appmessage.SubmitTransactionRequestMessage).Transaction)
+}
+
+// loadSpentUTXOEntry returns the UTXO entry spent by the given input. It's taken from the command line if it's
+// given there, and otherwise fetched over RPC: first from the mempool, in case the spent transaction isn't
+// accepted yet, and then from the UTXO index.
+func loadSpentUTXOEntry(cfg *debugScriptConfig, input *externalapi.DomainTransactionInput,
+	connect func() (*rpcclient.RPCClient, error)) (externalapi.UTXOEntry, error) {
+
+	if cfg.SpentScript != "" {
+		script, err := hex.DecodeString(cfg.SpentScript)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse --spent-script")
+		}
+		scriptPublicKey := &externalapi.ScriptPublicKey{Script: script, Version: cfg.SpentScriptVersion}
+		return utxo.NewUTXOEntry(cfg.SpentAmount, scriptPublicKey, false, 0), nil
+	}
+
+	client, err := connect()
+	if err != nil {
+		return nil, err
+	}
+
+	spentTransactionID := input.PreviousOutpoint.TransactionID.String()
+	mempoolEntryResponse, err := client.GetMempoolEntry(spentTransactionID, true, false)
+	if err == nil {
+		outputs := mempoolEntryResponse.Entry.Transaction.Outputs
+		if int(input.PreviousOutpoint.Index) >= len(outputs) {
+			return nil, errors.Errorf("The spent transaction %s has only %d outputs", spentTransactionID, len(outputs))
+		}
+		output := outputs[input.PreviousOutpoint.Index]
+		return rpcUTXOEntry(output.Amount, output.ScriptPublicKey)
+	}
+
+	address := cfg.Address
+	if address == "" {
+		address, err = redeemScriptAddress(cfg, input.SignatureScript)
+		if err != nil {
+			return nil, err
+		}
+	}
+	utxosResponse, err := client.GetUTXOsByAddresses([]string{address})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not fetch the UTXOs of %s", address)
+	}
+	for _, entry := range utxosResponse.Entries {
+		if entry.Outpoint.TransactionID == spentTransactionID && entry.Outpoint.Index == input.PreviousOutpoint.Index {
+			return rpcUTXOEntry(entry.UTXOEntry.Amount, entry.UTXOEntry.ScriptPublicKey)
+		}
+	}
+	return nil, errors.Errorf("The UTXO %s is neither in the mempool nor in the UTXOs of %s. "+
+		"Use --spent-script and --spent-amount to specify it", input.PreviousOutpoint, address)
+}
+
+func rpcUTXOEntry(amount uint64, rpcScriptPublicKey *appmessage.RPCScriptPublicKey) (externalapi.UTXOEntry, error) {
+	script, err := hex.DecodeString(rpcScriptPublicKey.Script)
+	if err != nil {
+		return nil, err
+	}
+	scriptPublicKey := &externalapi.ScriptPublicKey{Script: script, Version: rpcScriptPublicKey.Version}
+	return utxo.NewUTXOEntry(amount, scriptPublicKey, false, 0), nil
+}
+
+// redeemScriptAddress returns the pay-to-script-hash address of the redeem script, which is the last data
+// pushed by the signature script of a pay-to-script-hash input
+func redeemScriptAddress(cfg *debugScriptConfig, signatureScript []byte) (string, error) {
+	pushedData, err := txscript.PushedData(signatureScript)
+	if err != nil || len(pushedData) == 0 {
+		return "", errors.New("The spent UTXO can't be fetched without --address, since the signature script " +
+			"doesn't end with a redeem script. Use --address, or --spent-script and --spent-amount")
+	}
+	address, err := util.NewAddressScriptHash(pushedData[len(pushedData)-1], cfg.NetParams().Prefix)
+	if err != nil {
+		return "", err
+	}
+	return address.String(), nil
+}
+
+// traceScript executes the scripts of the input one opcode at a time, and records the state of the
+// engine after every opcode. In interactive mode every step is printed as soon as it's executed.
+func traceScript(cfg *debugScriptConfig, transaction *externalapi.DomainTransaction) *scriptTrace {
+	trace := &scriptTrace{
+		TransactionID: consensushashing.TransactionID(transaction).String(),
+		InputIndex:    cfg.InputIndex,
+		Steps:         []*scriptTraceStep{},
+	}
+	scriptPublicKey := transaction.Inputs[cfg.InputIndex].UTXOEntry.ScriptPublicKey()
+	vm, err := txscript.NewEngine(scriptPublicKey, transaction, int(cfg.InputIndex), txscript.ScriptNoFlags,
+		nil, nil, &consensushashing.SighashReusedValues{})
+	if err != nil {
+		trace.Error = err.Error()
+		return trace
+	}
+	if vm.IsUnknownScriptVersion() {
+		// Scripts of unknown versions always succeed, so that they can be defined by a future soft fork
+		trace.Success = true
+		if !cfg.JSON {
+			fmt.Printf("The script public key version %d is unknown, so the scripts aren't executed\n",
+				scriptPublicKey.Version)
+		}
+		return trace
+	}
+
+	if !cfg.JSON {
+		printScripts(vm)
+	}
+	reader := bufio.NewReader(os.Stdin)
+	for done := false; !done; {
+		scriptIndex, offset, err := vm.ProgramCounter()
+		if err != nil {
+			trace.Error = err.Error()
+			return trace
+		}
+		disassembly, err := vm.DisasmPC()
+		if err != nil {
+			trace.Error = err.Error()
+			return trace
+		}
+		// DisasmPC prefixes the opcode with the script index and offset, which are traced separately
+		opcode := disassembly[strings.Index(disassembly, ": ")+2:]
+		if cfg.Interactive {
+			fmt.Printf("Next: %s (press enter to execute)", opcode)
+			_, err := reader.ReadString('\n')
+			if err != nil {
+				trace.Error = err.Error()
+				return trace
+			}
+		}
+
+		var stepErr error
+		done, stepErr = vm.Step()
+		step := &scriptTraceStep{
+			Step:            len(trace.Steps) + 1,
+			Script:          scriptName(scriptIndex),
+			ScriptIndex:     scriptIndex,
+			Offset:          offset,
+			Opcode:          opcode,
+			Stack:           hexStack(vm.GetStack()),
+			AltStack:        hexStack(vm.GetAltStack()),
+			Conditionals:    conditionalNames(vm.GetConditionalStack()),
+			BranchExecuting: vm.IsBranchExecuting(),
+		}
+		if stepErr != nil {
+			step.Error = stepErr.Error()
+		}
+		trace.Steps = append(trace.Steps, step)
+		if !cfg.JSON {
+			printTraceStep(step)
+		}
+		if stepErr != nil {
+			trace.Error = stepErr.Error()
+			return trace
+		}
+	}
+
+	err = vm.CheckErrorCondition(true)
+	if err != nil {
+		trace.Error = err.Error()
+		return trace
+	}
+	trace.Success = true
+	return trace
+}
+
+func scriptName(scriptIndex int) string {
+	switch scriptIndex {
+	case 0:
+		return "signature script"
+	case 1:
+		return "script public key"
+	default:
+		return "redeem script"
+	}
+}
+
+func hexStack(stack [][]byte) []string {
+	hexStack := make([]string, len(stack))
+	for i, item := range stack {
+		hexStack[i] = hex.EncodeToString(item)
+	}
+	return hexStack
+}
+
+func conditionalNames(conditionalStack []int) []string {
+	names := make([]string, len(conditionalStack))
+	for i, conditional := range conditionalStack {
+		switch conditional {
+		case txscript.OpCondTrue:
+			names[i] = "true"
+		case txscript.OpCondFalse:
+			names[i] = "false"
+		default:
+			names[i] = "skip"
+		}
+	}
+	return names
+}
+
+func printScripts(vm *txscript.Engine) {
+	for scriptIndex := 0; scriptIndex < 2; scriptIndex++ {
+		disassembly, err := vm.DisasmScript(scriptIndex)
+		if err != nil {
+			continue
+		}
+		fmt.Printf("%s:\n%s\n", strings.Title(scriptName(scriptIndex)), disassembly)
+	}
+}
+
+func printTraceStep(step *scriptTraceStep) {
+	fmt.Printf("Step %d (%s, offset %d): %s\n", step.Step, step.Script, step.Offset, step.Opcode)
+	if step.Error != "" {
+		fmt.Printf("\tError: %s\n", step.Error)
+		return
+	}
+	branchState := "executing"
+	if !step.BranchExecuting {
+		branchState = "skipped"
+	}
+	if len(step.Conditionals) > 0 {
+		branchState = fmt.Sprintf("%s (conditionals: %s)", branchState, strings.Join(step.Conditionals, ", "))
+	}
+	fmt.Printf("\tBranch: %s\n", branchState)
+	printStack("Stack", step.Stack)
+	printStack("Alt stack", step.AltStack)
+}
+
+// printStack prints the stack from its top to its bottom
+func printStack(name string, stack []string) {
+	if len(stack) == 0 {
+		fmt.Printf("\t%s: (empty)\n", name)
+		return
+	}
+	fmt.Printf("\t%s:\n", name)
+	for i := len(stack) - 1; i >= 0; i-- {
+		item := stack[i]
+		if item == "" {
+			item = "(empty)"
+		}
+		fmt.Printf("\t\t%d: %s\n", len(stack)-1-i, item)
+	}
+}
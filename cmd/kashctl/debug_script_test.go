@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/subnetworks"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
+)
+
+func TestTraceScriptP2SH(t *testing.T) {
+	// The redeem script takes the true branch, and checks that the value under the condition is 2
+	redeemScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OpIf).AddOp(txscript.Op2).
+		AddOp(txscript.OpElse).AddOp(txscript.Op3).
+		AddOp(txscript.OpEndIf).AddOp(txscript.OpEqual).Script()
+	if err != nil {
+		t.Fatalf("Script: %+v", err)
+	}
+	scriptPublicKey, err := txscript.PayToScriptHashScript(redeemScript)
+	if err != nil {
+		t.Fatalf("PayToScriptHashScript: %+v", err)
+	}
+
+	newTransaction := func(value int64) *externalapi.DomainTransaction {
+		signatureScript, err := txscript.NewScriptBuilder().
+			AddInt64(value).AddOp(txscript.OpTrue).AddData(redeemScript).Script()
+		if err != nil {
+			t.Fatalf("Script: %+v", err)
+		}
+		return &externalapi.DomainTransaction{
+			Inputs: []*externalapi.DomainTransactionInput{{
+				SignatureScript: signatureScript,
+				UTXOEntry: utxo.NewUTXOEntry(1000, &externalapi.ScriptPublicKey{Script: scriptPublicKey},
+					false, 0),
+			}},
+			Outputs:      []*externalapi.DomainTransactionOutput{{Value: 1000, ScriptPublicKey: &externalapi.ScriptPublicKey{}}},
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+		}
+	}
+
+	cfg := &debugScriptConfig{JSON: true}
+	trace := traceScript(cfg, newTransaction(2))
+	if !trace.Success || trace.Error != "" {
+		t.Fatalf("The scripts unexpectedly failed: %s", trace.Error)
+	}
+
+	// The trace is checked in its JSON form, which is what --json prints
+	traceJSON, err := json.Marshal(trace)
+	if err != nil {
+		t.Fatalf("Marshal: %+v", err)
+	}
+	var decodedTrace struct {
+		Success bool `json:"success"`
+		Steps   []struct {
+			Script          string   `json:"script"`
+			Opcode          string   `json:"opcode"`
+			Stack           []string `json:"stack"`
+			Conditionals    []string `json:"conditionals"`
+			BranchExecuting bool     `json:"branchExecuting"`
+		} `json:"steps"`
+	}
+	err = json.Unmarshal(traceJSON, &decodedTrace)
+	if err != nil {
+		t.Fatalf("Unmarshal: %+v", err)
+	}
+	if !decodedTrace.Success {
+		t.Fatalf("The JSON trace isn't successful")
+	}
+
+	type expectedStep struct {
+		script          string
+		opcode          string
+		stack           []string
+		conditionals    []string
+		branchExecuting bool
+	}
+	redeemScriptStep := func(opcode string, stack []string, conditionals []string, branchExecuting bool) expectedStep {
+		return expectedStep{"redeem script", opcode, stack, conditionals, branchExecuting}
+	}
+	// Only the steps of the redeem script are checked in full, since the data pushes of the
+	// other scripts depend on the redeem script hash
+	expectedRedeemScriptSteps := []expectedStep{
+		redeemScriptStep("OP_IF", []string{"02"}, []string{"true"}, true),
+		redeemScriptStep("OP_2", []string{"02", "02"}, []string{"true"}, true),
+		redeemScriptStep("OP_ELSE", []string{"02", "02"}, []string{"false"}, false),
+		redeemScriptStep("OP_3", []string{"02", "02"}, []string{"false"}, false),
+		redeemScriptStep("OP_ENDIF", []string{"02", "02"}, []string{}, true),
+		redeemScriptStep("OP_EQUAL", []string{"01"}, []string{}, true),
+	}
+
+	expectedScripts := []string{
+		"signature script", "signature script", "signature script",
+		"script public key", "script public key", "script public key",
+	}
+	if len(decodedTrace.Steps) != len(expectedScripts)+len(expectedRedeemScriptSteps) {
+		t.Fatalf("Expected %d steps, but got %d: %s",
+			len(expectedScripts)+len(expectedRedeemScriptSteps), len(decodedTrace.Steps), traceJSON)
+	}
+	for i, script := range expectedScripts {
+		if decodedTrace.Steps[i].Script != script {
+			t.Fatalf("Expected step %d to be in the %s, but it's in the %s", i+1, script, decodedTrace.Steps[i].Script)
+		}
+	}
+	if opcode := decodedTrace.Steps[1].Opcode; opcode != "OP_1" {
+		t.Fatalf("Expected the second step to be OP_1, but got %s", opcode)
+	}
+	if stack := decodedTrace.Steps[1].Stack; !reflect.DeepEqual(stack, []string{"02", "01"}) {
+		t.Fatalf("Unexpected stack %v after pushing the condition", stack)
+	}
+
+	for i, expected := range expectedRedeemScriptSteps {
+		step := decodedTrace.Steps[len(expectedScripts)+i]
+		if step.Script != expected.script || step.Opcode != expected.opcode ||
+			!reflect.DeepEqual(step.Stack, expected.stack) ||
+			!reflect.DeepEqual(step.Conditionals, expected.conditionals) ||
+			step.BranchExecuting != expected.branchExecuting {
+
+			t.Fatalf("Unexpected redeem script step %d: %+v. Want: %+v", i+1, step, expected)
+		}
+	}
+
+	// A value that fails the redeem script is traced up to the end, and reported as a failure
+	failedTrace := traceScript(cfg, newTransaction(3))
+	if failedTrace.Success || failedTrace.Error == "" {
+		t.Fatalf("The scripts unexpectedly succeeded with a wrong value")
+	}
+	lastStep := failedTrace.Steps[len(failedTrace.Steps)-1]
+	if lastStep.Opcode != "OP_EQUAL" || !reflect.DeepEqual(lastStep.Stack, []string{""}) {
+		t.Fatalf("Unexpected last step %+v of the failed trace", lastStep)
+	}
+}
@@ -15,6 +15,17 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == debugScriptCommand {
+		isSuccess, err := debugScript(os.Args[2:])
+		if err != nil {
+			printErrorAndExit(fmt.Sprintf("error debugging script: %s", err))
+		}
+		if !isSuccess {
+			os.Exit(1)
+		}
+		return
+	}
+
 	cfg, err := parseConfig()
 	if err != nil {
 		printErrorAndExit(fmt.Sprintf("error parsing command-line arguments: %s", err))
@@ -48,6 +59,21 @@ func main() {
 		}
 	}
 
+	if cfg.Subscribe != "" {
+		err := subscribe(cfg, client)
+		if err != nil {
+			printErrorAndExit(fmt.Sprintf("error subscribing to %s: %s", cfg.Subscribe, err))
+		}
+		return
+	}
+	if cfg.Interactive {
+		err := runREPL(cfg, client)
+		if err != nil {
+			printErrorAndExit(err.Error())
+		}
+		return
+	}
+
 	responseChan := make(chan string)
 
 	if cfg.RequestJSON != "" {
@@ -71,6 +97,12 @@ func printAllCommands() {
 	for _, requestDesc := range requestDescs {
 		fmt.Printf("\t%s\n", requestDesc.help())
 	}
+
+	fmt.Println("\nNotifications (use with --subscribe):")
+	notificationDescs := notificationDescriptions()
+	for _, notificationDesc := range notificationDescs {
+		fmt.Printf("\t%s\n", notificationDesc.help())
+	}
 }
 
 func postCommand(cfg *configFlags, client *grpcclient.GRPCClient, responseChan chan string) {
@@ -106,6 +138,10 @@ func prettifyResponse(response string) string {
 		printErrorAndExit(fmt.Sprintf("error parsing the response from the RPC server: %s", err))
 	}
 
+	return formatResponse(kashdMessage)
+}
+
+func formatResponse(kashdMessage *protowire.KashdMessage) string {
 	marshalOptions := &protojson.MarshalOptions{}
 	marshalOptions.Indent = "    "
 	marshalOptions.EmitUnpopulated = true
@@ -5,6 +5,7 @@ import (
 	"unicode"
 
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/pkg/errors"
 )
 
 // protobuf generates the command types with two types:
@@ -44,3 +45,23 @@ func pointerToValue(valuePointedTo reflect.Value) reflect.Value {
 	pointer.Elem().Set(valuePointedTo)
 	return pointer
 }
+
+// responseError returns the error that the RPC server set in the given response, if any.
+// All RPC responses carry their error in a field named `error`.
+func responseError(response *protowire.KashdMessage) error {
+	reflectedResponse := response.ProtoReflect()
+	payloadOneof := reflectedResponse.Descriptor().Oneofs().ByName(kashdMessagePayloadOneofField)
+	payloadField := reflectedResponse.WhichOneof(payloadOneof)
+	if payloadField == nil {
+		return errors.New("the RPC server sent an empty response")
+	}
+
+	payload := reflectedResponse.Get(payloadField).Message()
+	errorField := payload.Descriptor().Fields().ByName("error")
+	if errorField == nil || !payload.Has(errorField) {
+		return nil
+	}
+	rpcError := payload.Get(errorField).Message()
+	return errors.Errorf("the RPC server returned an error: %s",
+		rpcError.Get(rpcError.Descriptor().Fields().ByName("message")).String())
+}
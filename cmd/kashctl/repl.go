@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/infrastructure/network/rpcclient/grpcclient"
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+const (
+	replPrompt = "kashctl> "
+
+	replHelpCommand = "help"
+	replExitCommand = "exit"
+	replQuitCommand = "quit"
+
+	// replMaxLineLength is the maximum length of a line read when stdin is not a terminal,
+	// enough for the JSON of large transactions
+	replMaxLineLength = 10 * 1024 * 1024
+)
+
+var replKeywords = []string{replHelpCommand, replExitCommand, replQuitCommand}
+
+// runREPL runs an interactive shell that posts every command it reads to the RPC server, and pretty-prints
+// the responses. Parameters can be given either by position or as <name>=<value>, in which case the
+// omitted ones aren't passed.
+func runREPL(cfg *configFlags, client *grpcclient.GRPCClient) error {
+	commandDescs := commandDescriptions()
+	console, err := newREPLConsole(commandDescs)
+	if err != nil {
+		return err
+	}
+	defer console.close()
+
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	for {
+		line, err := console.readLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		args, err := splitCommandLine(line)
+		if err != nil {
+			console.println(err.Error())
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		switch args[0] {
+		case replExitCommand, replQuitCommand:
+			return nil
+		case replHelpCommand:
+			console.printHelp(commandDescs, args[1:])
+			continue
+		}
+
+		args, err = resolveNamedParameters(args, commandDescs)
+		if err != nil {
+			console.println(err.Error())
+			continue
+		}
+		message, err := parseCommand(args, commandDescs)
+		if err != nil {
+			console.println(err.Error())
+			continue
+		}
+		// A request that timed out may still be answered later, which would mix up the responses
+		// of the following requests, so timeouts and connection errors end the session
+		response, err := postWithTimeout(client, message, timeout)
+		if err != nil {
+			return err
+		}
+		console.println(formatResponse(response))
+	}
+}
+
+// replConsole reads the lines of the interactive shell with line editing, history and tab completion
+// when stdin is a terminal, and plainly line by line otherwise
+type replConsole struct {
+	terminal      *term.Terminal
+	terminalState *term.State
+	scanner       *bufio.Scanner
+	commandDescs  []*commandDescription
+}
+
+func newREPLConsole(commandDescs []*commandDescription) (*replConsole, error) {
+	console := &replConsole{commandDescs: commandDescs}
+
+	stdinFD := int(os.Stdin.Fd())
+	if !term.IsTerminal(stdinFD) {
+		console.scanner = bufio.NewScanner(os.Stdin)
+		console.scanner.Buffer(nil, replMaxLineLength)
+		return console, nil
+	}
+
+	terminalState, err := term.MakeRaw(stdinFD)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error setting up the terminal")
+	}
+	console.terminalState = terminalState
+	console.terminal = term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, replPrompt)
+	console.terminal.AutoCompleteCallback = console.autoComplete
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err == nil && width > 0 {
+		_ = console.terminal.SetSize(width, height)
+	}
+	return console, nil
+}
+
+func (c *replConsole) close() {
+	if c.terminalState != nil {
+		_ = term.Restore(int(os.Stdin.Fd()), c.terminalState)
+	}
+}
+
+func (c *replConsole) readLine() (string, error) {
+	if c.terminal != nil {
+		return c.terminal.ReadLine()
+	}
+	if !c.scanner.Scan() {
+		if c.scanner.Err() != nil {
+			return "", errors.WithStack(c.scanner.Err())
+		}
+		return "", io.EOF
+	}
+	return c.scanner.Text(), nil
+}
+
+func (c *replConsole) println(text string) {
+	if c.terminal != nil {
+		// The terminal translates line feeds to what the raw mode terminal expects
+		_, _ = fmt.Fprintln(c.terminal, text)
+		return
+	}
+	fmt.Println(text)
+}
+
+func (c *replConsole) printHelp(commandDescs []*commandDescription, prefixes []string) {
+	if len(prefixes) == 0 {
+		c.println("Usage: <command> [parameters...] or <command> [<parameter>=<value>...]")
+		c.println("Use '-' to not pass an optional parameter. Quote parameters that contain spaces.")
+		c.println(fmt.Sprintf("Type '%s <prefix>' to list the matching commands, and '%s' or '%s' to leave.",
+			replHelpCommand, replExitCommand, replQuitCommand))
+		c.println("Commands:")
+	}
+	for _, commandDesc := range commandDescs {
+		if len(prefixes) > 0 && !hasAnyPrefix(commandDesc.name, prefixes) {
+			continue
+		}
+		c.println("\t" + commandDesc.help())
+	}
+}
+
+// autoComplete completes the command name or the parameter name under the cursor when tab is pressed.
+// When several completions are possible it completes their common prefix, and lists them if there's
+// nothing more to complete.
+func (c *replConsole) autoComplete(line string, pos int, key rune) (newLine string, newPos int, ok bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	lineBeforeCursor := line[:pos]
+	wordStart := strings.LastIndexAny(lineBeforeCursor, " \t") + 1
+	word := lineBeforeCursor[wordStart:]
+	precedingWords := strings.Fields(lineBeforeCursor[:wordStart])
+
+	var candidates []string
+	suffix := " "
+	switch {
+	case len(precedingWords) == 0:
+		candidates = append(candidates, replKeywords...)
+		for _, commandDesc := range c.commandDescs {
+			candidates = append(candidates, commandDesc.name)
+		}
+	case precedingWords[0] == replHelpCommand:
+		for _, commandDesc := range c.commandDescs {
+			candidates = append(candidates, commandDesc.name)
+		}
+	default:
+		commandDesc := findCommandDescription(precedingWords[0], c.commandDescs)
+		if commandDesc == nil || strings.Contains(word, "=") {
+			return "", 0, false
+		}
+		for _, parameter := range commandDesc.parameters {
+			candidates = append(candidates, parameter.name)
+		}
+		suffix = "="
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+
+	completion := matches[0] + suffix
+	if len(matches) > 1 {
+		completion = longestCommonPrefix(matches)
+		if completion == word {
+			sort.Strings(matches)
+			c.println(strings.Join(matches, "  "))
+			return line, pos, true
+		}
+	}
+	newLine = lineBeforeCursor[:wordStart] + completion + line[pos:]
+	return newLine, wordStart + len(completion), true
+}
+
+// resolveNamedParameters converts the parameters given as <name>=<value> to positional parameters, passing '-'
+// for the omitted ones. Positional parameters may precede the named ones.
+func resolveNamedParameters(args []string, commandDescs []*commandDescription) ([]string, error) {
+	commandDesc := findCommandDescription(args[0], commandDescs)
+	if commandDesc == nil {
+		return args, nil
+	}
+
+	resolvedParameters := make([]string, len(commandDesc.parameters))
+	for i := range resolvedParameters {
+		resolvedParameters[i] = "-"
+	}
+	hasNamedParameters := false
+	for i, parameterString := range args[1:] {
+		parameterIndex, value, isNamed := parseNamedParameter(parameterString, commandDesc)
+		if !isNamed {
+			if hasNamedParameters {
+				return nil, errors.Errorf("positional parameter '%s' can't follow named parameters", parameterString)
+			}
+			if i >= len(resolvedParameters) {
+				// Let parseCommand report the wrong number of parameters
+				return args, nil
+			}
+			resolvedParameters[i] = parameterString
+			continue
+		}
+		hasNamedParameters = true
+		resolvedParameters[parameterIndex] = value
+	}
+
+	if !hasNamedParameters {
+		return args, nil
+	}
+	return append([]string{args[0]}, resolvedParameters...), nil
+}
+
+func parseNamedParameter(parameterString string, commandDesc *commandDescription) (parameterIndex int, value string, ok bool) {
+	name, value, found := strings.Cut(parameterString, "=")
+	if !found {
+		return 0, "", false
+	}
+	for i, parameter := range commandDesc.parameters {
+		if parameter.name == name {
+			return i, value, true
+		}
+	}
+	return 0, "", false
+}
+
+// splitCommandLine splits a line into words separated by whitespace. Single quotes keep their content as is,
+// and double quotes keep it as is except for backslash escapes, so JSON parameters can be passed in either.
+func splitCommandLine(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	isEscaped := false
+
+	for _, char := range line {
+		switch {
+		case isEscaped:
+			word.WriteRune(char)
+			isEscaped = false
+		case quote == '"' && char == '\\':
+			isEscaped = true
+		case quote != 0 && char == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(char)
+		case char == '\'' || char == '"':
+			quote = char
+			inWord = true
+		case char == ' ' || char == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(char)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+func longestCommonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/Kash-Protocol/kashd/infrastructure/network/rpcclient/grpcclient"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// subscribe posts the notification request named by --subscribe, and then prints every message
+// the RPC server sends as a JSON line until kashctl is interrupted or disconnected
+func subscribe(cfg *configFlags, client *grpcclient.GRPCClient) error {
+	notificationName := cfg.Subscribe
+	if !strings.HasPrefix(notificationName, notificationRequestPrefix) {
+		notificationName = notificationRequestPrefix + notificationName
+	}
+	args := append([]string{notificationName}, cfg.CommandAndParameters...)
+	message, err := parseCommand(args, notificationDescriptions())
+	if err != nil {
+		return err
+	}
+
+	response, err := postWithTimeout(client, message, time.Duration(cfg.Timeout)*time.Second)
+	if err != nil {
+		return err
+	}
+	err = responseError(response)
+	if err != nil {
+		return err
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	notificationChan := make(chan *protowire.KashdMessage)
+	errChan := make(chan error, 1)
+	go func() {
+		for {
+			notification, err := client.Receive()
+			if err != nil {
+				errChan <- err
+				return
+			}
+			notificationChan <- notification
+		}
+	}()
+
+	marshalOptions := protojson.MarshalOptions{EmitUnpopulated: true}
+	for {
+		select {
+		case <-interrupt:
+			return nil
+		case err := <-errChan:
+			return err
+		case notification := <-notificationChan:
+			notificationBytes, err := marshalOptions.Marshal(notification)
+			if err != nil {
+				return errors.Wrapf(err, "error parsing a notification from the RPC server")
+			}
+			fmt.Println(string(notificationBytes))
+		}
+	}
+}
+
+// postWithTimeout posts the given message and waits for its response for at most the given timeout
+func postWithTimeout(client *grpcclient.GRPCClient, message *protowire.KashdMessage,
+	timeout time.Duration) (*protowire.KashdMessage, error) {
+
+	type postResult struct {
+		response *protowire.KashdMessage
+		err      error
+	}
+	resultChan := make(chan postResult, 1)
+	go func() {
+		response, err := client.Post(message)
+		resultChan <- postResult{response: response, err: err}
+	}()
+
+	select {
+	case result := <-resultChan:
+		if result.err != nil {
+			return nil, errors.Wrapf(result.err, "error posting the request to the RPC server")
+		}
+		return result.response, nil
+	case <-time.After(timeout):
+		return nil, errors.Errorf("timeout of %s has been exceeded", timeout)
+	}
+}
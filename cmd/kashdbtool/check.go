@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/database"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/multiset"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
+	"github.com/pkg/errors"
+)
+
+// consistencyCheck is a check of the database that returns a description of the inconsistency it found,
+// if any. It returns an error only when it couldn't be completed.
+type consistencyCheck struct {
+	name  string
+	check func(stores *consensusStores) (inconsistency string, err error)
+}
+
+func check(stores *consensusStores, conf *checkConfig) error {
+	checks := []consistencyCheck{
+		{"Headers selected chain", checkHeadersSelectedChain},
+		{"DAG tips", checkTips},
+	}
+	if !conf.SkipUTXOCommitment {
+		checks = append(checks, consistencyCheck{"Pruning point UTXO commitment", checkPruningPointUTXOCommitment})
+	}
+
+	failedChecks := 0
+	for _, consistencyCheck := range checks {
+		inconsistency, err := consistencyCheck.check(stores)
+		if err != nil {
+			return errors.Wrapf(err, "error running the check '%s'", consistencyCheck.name)
+		}
+		if inconsistency != "" {
+			failedChecks++
+			fmt.Printf("%-32sFAIL: %s\n", consistencyCheck.name, inconsistency)
+			continue
+		}
+		fmt.Printf("%-32sOK\n", consistencyCheck.name)
+	}
+
+	if failedChecks > 0 {
+		return errors.Errorf("%d of %d checks failed", failedChecks, len(checks))
+	}
+	return nil
+}
+
+// checkPruningPointUTXOCommitment verifies that the multiset of the pruning point UTXO set matches the
+// UTXO commitment in the header of the pruning point, as done when the pruning point UTXO set is imported
+func checkPruningPointUTXOCommitment(stores *consensusStores) (string, error) {
+	pruningPoint, err := stores.pruningStore.PruningPoint(stores.dbContext, stores.stagingArea)
+	if err != nil {
+		return "", err
+	}
+	pruningPointHeader, err := stores.blockHeaderStore.BlockHeader(stores.dbContext, stores.stagingArea, pruningPoint)
+	if err != nil {
+		return "", err
+	}
+
+	iterator, err := stores.pruningStore.PruningPointUTXOIterator(stores.dbContext)
+	if err != nil {
+		return "", err
+	}
+	defer iterator.Close()
+
+	utxoSetMultiset := multiset.New()
+	utxoCount := 0
+	for ok := iterator.First(); ok; ok = iterator.Next() {
+		outpoint, entry, err := iterator.Get()
+		if err != nil {
+			return "", err
+		}
+		serializedUTXO, err := utxo.SerializeUTXO(entry, outpoint)
+		if err != nil {
+			return "", err
+		}
+		utxoSetMultiset.Add(serializedUTXO)
+		utxoCount++
+	}
+
+	utxoSetHash := utxoSetMultiset.Hash()
+	if !utxoSetHash.Equal(pruningPointHeader.UTXOCommitment()) {
+		return fmt.Sprintf("the multiset of the %d UTXOs of pruning point %s is %s, but its UTXO commitment is %s",
+			utxoCount, pruningPoint, utxoSetHash, pruningPointHeader.UTXOCommitment()), nil
+	}
+	return "", nil
+}
+
+// checkHeadersSelectedChain verifies that every block of the headers selected chain is the selected parent of
+// the next one, and that the chain ends at the headers selected tip
+func checkHeadersSelectedChain(stores *consensusStores) (string, error) {
+	headersSelectedTip, err := stores.headersSelectedTipStore.HeadersSelectedTip(stores.dbContext, stores.stagingArea)
+	if err != nil {
+		return "", err
+	}
+
+	var previousChainBlock *externalapi.DomainHash
+	for index := uint64(0); ; index++ {
+		chainBlock, err := stores.headersSelectedChainStore.GetHashByIndex(stores.dbContext, stores.stagingArea, index)
+		if database.IsNotFoundError(err) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if previousChainBlock != nil {
+			ghostdagData, err := stores.ghostdagDataStores[0].Get(stores.dbContext, stores.stagingArea, chainBlock, false)
+			if database.IsNotFoundError(err) {
+				return fmt.Sprintf("chain block %s at index %d has no GHOSTDAG data", chainBlock, index), nil
+			}
+			if err != nil {
+				return "", err
+			}
+			if !ghostdagData.SelectedParent().Equal(previousChainBlock) {
+				return fmt.Sprintf("the selected parent of chain block %s at index %d is %s, but the previous "+
+					"chain block is %s", chainBlock, index, ghostdagData.SelectedParent(), previousChainBlock), nil
+			}
+		}
+		previousChainBlock = chainBlock
+	}
+
+	if previousChainBlock == nil {
+		return "the headers selected chain is empty", nil
+	}
+	if !previousChainBlock.Equal(headersSelectedTip) {
+		return fmt.Sprintf("the headers selected chain ends at %s, but the headers selected tip is %s",
+			previousChainBlock, headersSelectedTip), nil
+	}
+	return "", nil
+}
+
+// checkTips verifies that the DAG tips are valid blocks with bodies. Tips may still be pending UTXO
+// verification, or disqualified from the chain, since they are added to the DAG before that.
+func checkTips(stores *consensusStores) (string, error) {
+	tips, err := stores.consensusStateStore.Tips(stores.stagingArea, stores.dbContext)
+	if err != nil {
+		return "", err
+	}
+	if len(tips) == 0 {
+		return "the DAG has no tips", nil
+	}
+
+	for _, tip := range tips {
+		blockStatus, err := stores.blockStatusStore.Get(stores.dbContext, stores.stagingArea, tip)
+		if database.IsNotFoundError(err) {
+			return fmt.Sprintf("tip %s has no status", tip), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if blockStatus == externalapi.StatusInvalid || blockStatus == externalapi.StatusHeaderOnly {
+			return fmt.Sprintf("tip %s has status %s", tip, blockStatus), nil
+		}
+	}
+	return "", nil
+}
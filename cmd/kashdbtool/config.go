@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/Kash-Protocol/kashd/infrastructure/config"
+	"github.com/jessevdk/go-flags"
+	"github.com/pkg/errors"
+)
+
+const (
+	headerSubCmd         = "header"
+	ghostdagSubCmd       = "ghostdag"
+	reachabilitySubCmd   = "reachability"
+	utxoDiffSubCmd       = "utxo-diff"
+	acceptanceDataSubCmd = "acceptance-data"
+	pruningSubCmd        = "pruning"
+	selectedChainSubCmd  = "selected-chain"
+	checkSubCmd          = "check"
+	dotSubCmd            = "dot"
+)
+
+// databaseDirname is the name of the database directory within the network directory of kashd
+const databaseDirname = "datadir2"
+
+type databaseFlags struct {
+	AppDir  string `long:"appdir" description:"The application directory of the stopped kashd instance to inspect (default: the default kashd application directory)"`
+	DataDir string `long:"datadir" description:"The database directory to inspect, overriding --appdir and the network (e.g. ~/.kashd/kash-mainnet/datadir2)"`
+	config.NetworkFlags
+}
+
+// databasePath returns the path of the database, the same way kashd builds it
+func (dbFlags *databaseFlags) databasePath() string {
+	if dbFlags.DataDir != "" {
+		return dbFlags.DataDir
+	}
+	appDir := dbFlags.AppDir
+	if appDir == "" {
+		appDir = config.DefaultAppDir
+	}
+	return filepath.Join(appDir, dbFlags.NetParams().Name, databaseDirname)
+}
+
+type blockConfig struct {
+	Block string `long:"block" description:"The hash of the block (encoded in hex)" required:"true"`
+	databaseFlags
+}
+
+type ghostdagConfig struct {
+	Block string `long:"block" description:"The hash of the block (encoded in hex)" required:"true"`
+	Level int    `long:"level" description:"The block level of the GHOSTDAG data, as used by the pruning proof"`
+	databaseFlags
+}
+
+type selectedChainConfig struct {
+	FromIndex uint64 `long:"from-index" description:"The index in the headers selected chain of the first block to print"`
+	Count     uint64 `long:"count" description:"The maximum number of blocks to print" default:"100"`
+	databaseFlags
+}
+
+type checkConfig struct {
+	SkipUTXOCommitment bool `long:"skip-utxo-commitment" description:"Skip verifying the pruning point UTXO set against its commitment, which reads the whole set"`
+	databaseFlags
+}
+
+type dotConfig struct {
+	Block  string `long:"block" description:"The hash of the block to start from (encoded in hex). Defaults to the DAG tips"`
+	Depth  uint64 `long:"depth" description:"How many levels of parents to include" default:"20"`
+	Output string `long:"output" short:"o" description:"The file to write the DOT script to (default: stdout)"`
+	SVG    string `long:"svg" description:"Render the DAG section to the given SVG file instead (requires graphviz)"`
+	databaseFlags
+}
+
+type pruningConfig struct {
+	databaseFlags
+}
+
+func parseCommandLine() (subCommand string, conf interface{}, dbFlags *databaseFlags) {
+	parser := flags.NewParser(&struct{}{}, flags.PrintErrors|flags.HelpFlag)
+	parser.Usage = "kashdbtool [OPTIONS] <command>\n\n" +
+		"Inspects the database of a stopped kashd instance without modifying it."
+
+	headerConf := &blockConfig{}
+	parser.AddCommand(headerSubCmd, "Print a block header",
+		"Print the header and the status of a block", headerConf)
+
+	ghostdagConf := &ghostdagConfig{}
+	parser.AddCommand(ghostdagSubCmd, "Print the GHOSTDAG data of a block",
+		"Print the blue score, blue work, selected parent and mergeset of a block", ghostdagConf)
+
+	reachabilityConf := &blockConfig{}
+	parser.AddCommand(reachabilitySubCmd, "Print the reachability data of a block",
+		"Print the reachability tree interval, parent, children and future covering set of a block", reachabilityConf)
+
+	utxoDiffConf := &blockConfig{}
+	parser.AddCommand(utxoDiffSubCmd, "Print the UTXO diff of a block",
+		"Print the UTXO diff of a block from its UTXO diff child (or from the virtual if it has none)", utxoDiffConf)
+
+	acceptanceDataConf := &blockConfig{}
+	parser.AddCommand(acceptanceDataSubCmd, "Print the acceptance data of a block",
+		"Print which transactions of the mergeset of a chain block were accepted", acceptanceDataConf)
+
+	pruningConf := &pruningConfig{}
+	parser.AddCommand(pruningSubCmd, "Print the pruning state",
+		"Print the pruning point, the pruning point candidate, the previous pruning points and the DAG tips",
+		pruningConf)
+
+	selectedChainConf := &selectedChainConfig{}
+	parser.AddCommand(selectedChainSubCmd, "Print the headers selected chain",
+		"Print the blocks of the headers selected chain by their index, from the pruning point on", selectedChainConf)
+
+	checkConf := &checkConfig{}
+	parser.AddCommand(checkSubCmd, "Run consistency checks",
+		"Check that the pruning point UTXO set matches its commitment, that the headers selected chain is "+
+			"consistent with the GHOSTDAG data, and that the DAG tips are valid blocks", checkConf)
+
+	dotConf := &dotConfig{}
+	parser.AddCommand(dotSubCmd, "Export a section of the DAG to DOT",
+		"Export the blocks down to --depth levels of parents from a block or from the DAG tips as a graphviz "+
+			"DOT script, with chain blocks in blue", dotConf)
+
+	_, err := parser.Parse()
+	if err != nil {
+		var flagsErr *flags.Error
+		if ok := errors.As(err, &flagsErr); ok && flagsErr.Type == flags.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(1)
+	}
+
+	switch parser.Command.Active.Name {
+	case headerSubCmd:
+		conf, dbFlags = headerConf, &headerConf.databaseFlags
+	case ghostdagSubCmd:
+		conf, dbFlags = ghostdagConf, &ghostdagConf.databaseFlags
+	case reachabilitySubCmd:
+		conf, dbFlags = reachabilityConf, &reachabilityConf.databaseFlags
+	case utxoDiffSubCmd:
+		conf, dbFlags = utxoDiffConf, &utxoDiffConf.databaseFlags
+	case acceptanceDataSubCmd:
+		conf, dbFlags = acceptanceDataConf, &acceptanceDataConf.databaseFlags
+	case pruningSubCmd:
+		conf, dbFlags = pruningConf, &pruningConf.databaseFlags
+	case selectedChainSubCmd:
+		conf, dbFlags = selectedChainConf, &selectedChainConf.databaseFlags
+	case checkSubCmd:
+		conf, dbFlags = checkConf, &checkConf.databaseFlags
+	case dotSubCmd:
+		conf, dbFlags = dotConf, &dotConf.databaseFlags
+	}
+
+	err = dbFlags.ResolveNetwork(parser)
+	if err != nil {
+		printErrorAndExit(err)
+	}
+	return parser.Command.Active.Name, conf, dbFlags
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"github.com/Kash-Protocol/kashd/domain/consensus/database"
+	"github.com/Kash-Protocol/kashd/domain/consensus/datastructures/acceptancedatastore"
+	"github.com/Kash-Protocol/kashd/domain/consensus/datastructures/blockheaderstore"
+	"github.com/Kash-Protocol/kashd/domain/consensus/datastructures/blockrelationstore"
+	"github.com/Kash-Protocol/kashd/domain/consensus/datastructures/blockstatusstore"
+	"github.com/Kash-Protocol/kashd/domain/consensus/datastructures/blockstore"
+	"github.com/Kash-Protocol/kashd/domain/consensus/datastructures/consensusstatestore"
+	"github.com/Kash-Protocol/kashd/domain/consensus/datastructures/ghostdagdatastore"
+	"github.com/Kash-Protocol/kashd/domain/consensus/datastructures/headersselectedchainstore"
+	"github.com/Kash-Protocol/kashd/domain/consensus/datastructures/headersselectedtipstore"
+	"github.com/Kash-Protocol/kashd/domain/consensus/datastructures/multisetstore"
+	"github.com/Kash-Protocol/kashd/domain/consensus/datastructures/pruningstore"
+	"github.com/Kash-Protocol/kashd/domain/consensus/datastructures/reachabilitydatastore"
+	"github.com/Kash-Protocol/kashd/domain/consensus/datastructures/utxodiffstore"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/Kash-Protocol/kashd/domain/prefixmanager"
+	infrastructuredatabase "github.com/Kash-Protocol/kashd/infrastructure/db/database"
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database/ldb"
+	"github.com/pkg/errors"
+)
+
+const (
+	// The tool reads every block at most a few times, so small caches are enough
+	cacheSizeMiB   = 64
+	storeCacheSize = 100
+)
+
+// consensusStores are the consensus data structures of the active consensus of a node database,
+// created the same way the consensus factory creates them
+type consensusStores struct {
+	db          infrastructuredatabase.Database
+	dbContext   model.DBManager
+	stagingArea *model.StagingArea
+	params      *dagconfig.Params
+
+	blockStore                model.BlockStore
+	blockHeaderStore          model.BlockHeaderStore
+	blockStatusStore          model.BlockStatusStore
+	blockRelationStores       []model.BlockRelationStore
+	ghostdagDataStores        []model.GHOSTDAGDataStore
+	reachabilityDataStore     model.ReachabilityDataStore
+	utxoDiffStore             model.UTXODiffStore
+	acceptanceDataStore       model.AcceptanceDataStore
+	multisetStore             model.MultisetStore
+	pruningStore              model.PruningStore
+	consensusStateStore       model.ConsensusStateStore
+	headersSelectedChainStore model.HeadersSelectedChainStore
+	headersSelectedTipStore   model.HeaderSelectedTipStore
+}
+
+// openConsensusStores opens the database at the given path read-only, and creates the stores of its
+// active consensus
+func openConsensusStores(path string, params *dagconfig.Params) (*consensusStores, error) {
+	db, err := ldb.NewLevelDBReadOnly(path, cacheSizeMiB)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening the database at %s. Make sure that the node is stopped", path)
+	}
+
+	activePrefix, exists, err := prefixmanager.ActivePrefix(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if !exists {
+		db.Close()
+		return nil, errors.Errorf("the database at %s has no active consensus", path)
+	}
+
+	dbContext := database.New(db)
+	prefixBucket := database.MakeBucket(activePrefix.Serialize())
+
+	stores := &consensusStores{
+		db:          db,
+		dbContext:   dbContext,
+		stagingArea: model.NewStagingArea(),
+		params:      params,
+
+		blockStatusStore:          blockstatusstore.New(prefixBucket, storeCacheSize, false),
+		utxoDiffStore:             utxodiffstore.New(prefixBucket, storeCacheSize, false),
+		acceptanceDataStore:       acceptancedatastore.New(prefixBucket, storeCacheSize, false),
+		multisetStore:             multisetstore.New(prefixBucket, storeCacheSize, false),
+		pruningStore:              pruningstore.New(prefixBucket, 2, false),
+		consensusStateStore:       consensusstatestore.New(prefixBucket, storeCacheSize, false),
+		headersSelectedChainStore: headersselectedchainstore.New(prefixBucket, storeCacheSize, false),
+		headersSelectedTipStore:   headersselectedtipstore.New(prefixBucket),
+	}
+
+	stores.blockStore, err = blockstore.New(dbContext, prefixBucket, storeCacheSize, false)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	stores.blockHeaderStore, err = blockheaderstore.New(dbContext, prefixBucket, storeCacheSize, false)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	stores.blockRelationStores = make([]model.BlockRelationStore, params.MaxBlockLevel+1)
+	stores.ghostdagDataStores = make([]model.GHOSTDAGDataStore, params.MaxBlockLevel+1)
+	reachabilityDataStores := make([]model.ReachabilityDataStore, params.MaxBlockLevel+1)
+	for i := 0; i <= params.MaxBlockLevel; i++ {
+		levelBucket := prefixBucket.Bucket([]byte{byte(i)})
+		stores.blockRelationStores[i] = blockrelationstore.New(levelBucket, storeCacheSize, false)
+		stores.ghostdagDataStores[i] = ghostdagdatastore.New(levelBucket, storeCacheSize, false)
+		reachabilityDataStores[i] = reachabilitydatastore.New(levelBucket, storeCacheSize, false)
+	}
+
+	// Databases created before the reachability data was moved out of the level buckets still
+	// keep it in the bucket of level 0, as handled by the consensus factory
+	isOldReachabilityInitialized, err := reachabilityDataStores[0].HasReachabilityData(dbContext, stores.stagingArea,
+		model.VirtualGenesisBlockHash)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if isOldReachabilityInitialized {
+		stores.reachabilityDataStore = reachabilityDataStores[0]
+	} else {
+		stores.reachabilityDataStore = reachabilitydatastore.New(prefixBucket, storeCacheSize, false)
+	}
+
+	return stores, nil
+}
+
+func (s *consensusStores) close() error {
+	return s.db.Close()
+}
+
+// notFoundError wraps errors of missing data with the name of the data, since the stores don't mention it
+func notFoundError(err error, description string, blockHash *externalapi.DomainHash) error {
+	if database.IsNotFoundError(err) {
+		return errors.Errorf("%s of block %s was not found in the database", description, blockHash)
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus"
+	"github.com/Kash-Protocol/kashd/domain/consensus/database"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+)
+
+// shortHashLength is the number of hex characters of the block hashes used as DOT labels
+const shortHashLength = 8
+
+func dot(stores *consensusStores, conf *dotConfig) error {
+	var startBlocks []*externalapi.DomainHash
+	if conf.Block != "" {
+		blockHash, err := parseBlockHash(conf.Block)
+		if err != nil {
+			return err
+		}
+		startBlocks = []*externalapi.DomainHash{blockHash}
+	} else {
+		tips, err := stores.consensusStateStore.Tips(stores.stagingArea, stores.dbContext)
+		if err != nil {
+			return err
+		}
+		startBlocks = tips
+	}
+
+	blockHashes, err := collectDAGSection(stores, startBlocks, conf.Depth)
+	if err != nil {
+		return err
+	}
+
+	// Only edges between blocks of the section are drawn, so that its oldest blocks don't point to
+	// blocks that aren't in the graph
+	inSection := make(map[externalapi.DomainHash]struct{}, len(blockHashes))
+	for _, blockHash := range blockHashes {
+		inSection[*blockHash] = struct{}{}
+	}
+	parents := func(blockHash *externalapi.DomainHash) ([]*externalapi.DomainHash, error) {
+		blockParents, err := blockParents(stores, blockHash)
+		if err != nil {
+			return nil, err
+		}
+		parentsInSection := make([]*externalapi.DomainHash, 0, len(blockParents))
+		for _, parent := range blockParents {
+			if _, ok := inSection[*parent]; ok {
+				parentsInSection = append(parentsInSection, parent)
+			}
+		}
+		return parentsInSection, nil
+	}
+
+	dotScript, err := consensus.ConvertDAGToDot(blockHashes, parents, func(blockHash *externalapi.DomainHash) (string, error) {
+		return dotNodeAttributes(stores, blockHash)
+	})
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case conf.SVG != "":
+		return consensus.RenderDotScript(dotScript, conf.SVG)
+	case conf.Output != "":
+		return ioutil.WriteFile(conf.Output, []byte(dotScript), 0600)
+	default:
+		fmt.Println(dotScript)
+		return nil
+	}
+}
+
+// collectDAGSection returns the given blocks and their ancestors down to the given depth of parents,
+// in breadth-first order
+func collectDAGSection(stores *consensusStores, startBlocks []*externalapi.DomainHash,
+	depth uint64) ([]*externalapi.DomainHash, error) {
+
+	visited := make(map[externalapi.DomainHash]struct{})
+	var blockHashes []*externalapi.DomainHash
+	currentLevel := make([]*externalapi.DomainHash, 0, len(startBlocks))
+	for _, blockHash := range startBlocks {
+		if _, ok := visited[*blockHash]; ok {
+			continue
+		}
+		visited[*blockHash] = struct{}{}
+		currentLevel = append(currentLevel, blockHash)
+	}
+
+	for level := uint64(0); len(currentLevel) > 0; level++ {
+		blockHashes = append(blockHashes, currentLevel...)
+		if level == depth {
+			break
+		}
+
+		var nextLevel []*externalapi.DomainHash
+		for _, blockHash := range currentLevel {
+			parents, err := blockParents(stores, blockHash)
+			if err != nil {
+				return nil, err
+			}
+			for _, parent := range parents {
+				if _, ok := visited[*parent]; ok {
+					continue
+				}
+				visited[*parent] = struct{}{}
+				nextLevel = append(nextLevel, parent)
+			}
+		}
+		currentLevel = nextLevel
+	}
+	return blockHashes, nil
+}
+
+// blockParents returns the parents of a block in the DAG, which are missing for blocks below the pruning point
+// whose relations were pruned. The virtual genesis is omitted since it isn't a real block.
+func blockParents(stores *consensusStores, blockHash *externalapi.DomainHash) ([]*externalapi.DomainHash, error) {
+	blockRelation, err := stores.blockRelationStores[0].BlockRelation(stores.dbContext, stores.stagingArea, blockHash)
+	if database.IsNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	parents := make([]*externalapi.DomainHash, 0, len(blockRelation.Parents))
+	for _, parent := range blockRelation.Parents {
+		if !parent.Equal(model.VirtualGenesisBlockHash) {
+			parents = append(parents, parent)
+		}
+	}
+	return parents, nil
+}
+
+// dotNodeAttributes labels a block with its short hash and blue score, and colors the headers selected chain blocks
+func dotNodeAttributes(stores *consensusStores, blockHash *externalapi.DomainHash) (string, error) {
+	label := blockHash.String()[:shortHashLength]
+	ghostdagData, err := stores.ghostdagDataStores[0].Get(stores.dbContext, stores.stagingArea, blockHash, false)
+	if err == nil {
+		label = fmt.Sprintf("%s\\n%d", label, ghostdagData.BlueScore())
+	} else if !database.IsNotFoundError(err) {
+		return "", err
+	}
+	attributes := fmt.Sprintf("label=\"%s\"", label)
+
+	_, err = stores.headersSelectedChainStore.GetIndexByHash(stores.dbContext, stores.stagingArea, blockHash)
+	if err == nil {
+		attributes += ", style=filled, fillcolor=lightblue"
+	} else if !database.IsNotFoundError(err) {
+		return "", err
+	}
+	return attributes, nil
+}
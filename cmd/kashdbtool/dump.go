@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/database"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/pkg/errors"
+)
+
+func printField(name string, value interface{}) {
+	fmt.Printf("%-32s%v\n", name+":", value)
+}
+
+func parseBlockHash(blockHashString string) (*externalapi.DomainHash, error) {
+	blockHash, err := externalapi.NewDomainHashFromString(blockHashString)
+	if err != nil {
+		return nil, errors.Wrapf(err, "'%s' is not a valid block hash", blockHashString)
+	}
+	return blockHash, nil
+}
+
+func header(stores *consensusStores, conf *blockConfig) error {
+	blockHash, err := parseBlockHash(conf.Block)
+	if err != nil {
+		return err
+	}
+	blockHeader, err := stores.blockHeaderStore.BlockHeader(stores.dbContext, stores.stagingArea, blockHash)
+	if err != nil {
+		return notFoundError(err, "the header", blockHash)
+	}
+	blockStatus, err := stores.blockStatusStore.Get(stores.dbContext, stores.stagingArea, blockHash)
+	if err != nil {
+		return notFoundError(err, "the status", blockHash)
+	}
+	hasBlock, err := stores.blockStore.HasBlock(stores.dbContext, stores.stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+
+	printField("Hash", blockHash)
+	printField("Status", blockStatus)
+	printField("Has body", hasBlock)
+	printField("Version", blockHeader.Version())
+	for level, levelParents := range blockHeader.Parents() {
+		printField(fmt.Sprintf("Parents (level %d)", level), levelParents)
+	}
+	printField("Hash merkle root", blockHeader.HashMerkleRoot())
+	printField("Accepted ID merkle root", blockHeader.AcceptedIDMerkleRoot())
+	printField("UTXO commitment", blockHeader.UTXOCommitment())
+	printField("Time", fmt.Sprintf("%d (%s)", blockHeader.TimeInMilliseconds(),
+		time.UnixMilli(blockHeader.TimeInMilliseconds()).UTC().Format(time.RFC3339Nano)))
+	printField("Bits", fmt.Sprintf("%08x", blockHeader.Bits()))
+	printField("Nonce", blockHeader.Nonce())
+	printField("DAA score", blockHeader.DAAScore())
+	printField("Blue score", blockHeader.BlueScore())
+	printField("Blue work", blockHeader.BlueWork().Text(16))
+	printField("Pruning point", blockHeader.PruningPoint())
+	printField("Block level", blockHeader.BlockLevel(stores.params.MaxBlockLevel))
+	return nil
+}
+
+func ghostdag(stores *consensusStores, conf *ghostdagConfig) error {
+	blockHash, err := parseBlockHash(conf.Block)
+	if err != nil {
+		return err
+	}
+	if conf.Level < 0 || conf.Level > stores.params.MaxBlockLevel {
+		return errors.Errorf("the level must be between 0 and %d", stores.params.MaxBlockLevel)
+	}
+
+	ghostdagDataStore := stores.ghostdagDataStores[conf.Level]
+	isTrustedData := false
+	ghostdagData, err := ghostdagDataStore.Get(stores.dbContext, stores.stagingArea, blockHash, false)
+	if database.IsNotFoundError(err) {
+		// Blocks received along with the pruning point have their GHOSTDAG data stored as trusted data
+		isTrustedData = true
+		ghostdagData, err = ghostdagDataStore.Get(stores.dbContext, stores.stagingArea, blockHash, true)
+	}
+	if err != nil {
+		return notFoundError(err, fmt.Sprintf("the GHOSTDAG data of level %d", conf.Level), blockHash)
+	}
+
+	printField("Hash", blockHash)
+	printField("Trusted data", isTrustedData)
+	printField("Blue score", ghostdagData.BlueScore())
+	printField("Blue work", ghostdagData.BlueWork().Text(16))
+	printField("Selected parent", ghostdagData.SelectedParent())
+	fmt.Printf("Mergeset blues (%d), with their anticone sizes:\n", len(ghostdagData.MergeSetBlues()))
+	bluesAnticoneSizes := ghostdagData.BluesAnticoneSizes()
+	for _, blue := range ghostdagData.MergeSetBlues() {
+		fmt.Printf("\t%s\t%d\n", blue, bluesAnticoneSizes[*blue])
+	}
+	fmt.Printf("Mergeset reds (%d):\n", len(ghostdagData.MergeSetReds()))
+	for _, red := range ghostdagData.MergeSetReds() {
+		fmt.Printf("\t%s\n", red)
+	}
+	return nil
+}
+
+func reachability(stores *consensusStores, conf *blockConfig) error {
+	blockHash, err := parseBlockHash(conf.Block)
+	if err != nil {
+		return err
+	}
+	reachabilityData, err := stores.reachabilityDataStore.ReachabilityData(stores.dbContext, stores.stagingArea, blockHash)
+	if err != nil {
+		return notFoundError(err, "the reachability data", blockHash)
+	}
+	reindexRoot, err := stores.reachabilityDataStore.ReachabilityReindexRoot(stores.dbContext, stores.stagingArea)
+	if err != nil {
+		return err
+	}
+
+	interval := reachabilityData.Interval()
+	printField("Hash", blockHash)
+	printField("Interval", fmt.Sprintf("[%d, %d] (size %d)", interval.Start, interval.End, interval.End-interval.Start+1))
+	printField("Tree parent", reachabilityData.Parent())
+	printField("Reindex root", reindexRoot)
+	fmt.Printf("Tree children (%d):\n", len(reachabilityData.Children()))
+	for _, child := range reachabilityData.Children() {
+		fmt.Printf("\t%s\n", child)
+	}
+	fmt.Printf("Future covering set (%d):\n", len(reachabilityData.FutureCoveringSet()))
+	for _, blockInFutureCoveringSet := range reachabilityData.FutureCoveringSet() {
+		fmt.Printf("\t%s\n", blockInFutureCoveringSet)
+	}
+	return nil
+}
+
+func utxoDiff(stores *consensusStores, conf *blockConfig) error {
+	blockHash, err := parseBlockHash(conf.Block)
+	if err != nil {
+		return err
+	}
+	diff, err := stores.utxoDiffStore.UTXODiff(stores.dbContext, stores.stagingArea, blockHash)
+	if err != nil {
+		return notFoundError(err, "the UTXO diff", blockHash)
+	}
+	hasDiffChild, err := stores.utxoDiffStore.HasUTXODiffChild(stores.dbContext, stores.stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+
+	printField("Hash", blockHash)
+	if hasDiffChild {
+		diffChild, err := stores.utxoDiffStore.UTXODiffChild(stores.dbContext, stores.stagingArea, blockHash)
+		if err != nil {
+			return err
+		}
+		printField("UTXO diff child", diffChild)
+	} else {
+		printField("UTXO diff child", "none (the diff is from the virtual UTXO set)")
+	}
+
+	for _, collection := range []struct {
+		name  string
+		utxos externalapi.UTXOCollection
+	}{{"To add", diff.ToAdd()}, {"To remove", diff.ToRemove()}} {
+		fmt.Printf("%s (%d):\n", collection.name, collection.utxos.Len())
+		err := printUTXOs(collection.utxos.Iterator())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printUTXOs prints the UTXOs of the given iterator sorted by outpoint, since UTXO collections are unordered
+func printUTXOs(iterator externalapi.ReadOnlyUTXOSetIterator) error {
+	defer iterator.Close()
+
+	var pairs []*externalapi.OutpointAndUTXOEntryPair
+	for ok := iterator.First(); ok; ok = iterator.Next() {
+		outpoint, entry, err := iterator.Get()
+		if err != nil {
+			return err
+		}
+		pairs = append(pairs, &externalapi.OutpointAndUTXOEntryPair{Outpoint: outpoint, UTXOEntry: entry})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Outpoint.String() < pairs[j].Outpoint.String()
+	})
+
+	for _, pair := range pairs {
+		fmt.Printf("\t%s\tamount: %d\tDAA score: %d\tcoinbase: %t\tscript: %x (version %d)\n",
+			pair.Outpoint, pair.UTXOEntry.Amount(), pair.UTXOEntry.BlockDAAScore(), pair.UTXOEntry.IsCoinbase(),
+			pair.UTXOEntry.ScriptPublicKey().Script, pair.UTXOEntry.ScriptPublicKey().Version)
+	}
+	return nil
+}
+
+func acceptanceData(stores *consensusStores, conf *blockConfig) error {
+	blockHash, err := parseBlockHash(conf.Block)
+	if err != nil {
+		return err
+	}
+	blockAcceptanceData, err := stores.acceptanceDataStore.Get(stores.dbContext, stores.stagingArea, blockHash)
+	if err != nil {
+		return notFoundError(err, "the acceptance data", blockHash)
+	}
+
+	printField("Hash", blockHash)
+	for _, mergedBlockAcceptanceData := range blockAcceptanceData {
+		fmt.Printf("Merged block %s (%d transactions):\n", mergedBlockAcceptanceData.BlockHash,
+			len(mergedBlockAcceptanceData.TransactionAcceptanceData))
+		for _, transactionAcceptanceData := range mergedBlockAcceptanceData.TransactionAcceptanceData {
+			fmt.Printf("\t%s\taccepted: %t\tfee: %d\n", consensushashing.TransactionID(transactionAcceptanceData.Transaction),
+				transactionAcceptanceData.IsAccepted, transactionAcceptanceData.Fee)
+		}
+	}
+	return nil
+}
+
+func pruning(stores *consensusStores, _ *pruningConfig) error {
+	pruningPoint, err := stores.pruningStore.PruningPoint(stores.dbContext, stores.stagingArea)
+	if err != nil {
+		return err
+	}
+	pruningPointIndex, err := stores.pruningStore.CurrentPruningPointIndex(stores.dbContext, stores.stagingArea)
+	if err != nil {
+		return err
+	}
+	printField("Pruning point", pruningPoint)
+	printField("Pruning point index", pruningPointIndex)
+
+	hasCandidate, err := stores.pruningStore.HasPruningPointCandidate(stores.dbContext, stores.stagingArea)
+	if err != nil {
+		return err
+	}
+	if hasCandidate {
+		candidate, err := stores.pruningStore.PruningPointCandidate(stores.dbContext, stores.stagingArea)
+		if err != nil {
+			return err
+		}
+		printField("Pruning point candidate", candidate)
+	}
+
+	isUpdatingPruningPointUTXOSet, err := stores.pruningStore.HadStartedUpdatingPruningPointUTXOSet(stores.dbContext)
+	if err != nil {
+		return err
+	}
+	printField("Updating pruning point UTXOs", isUpdatingPruningPointUTXOSet)
+	isImportingPruningPointUTXOSet, err := stores.consensusStateStore.HadStartedImportingPruningPointUTXOSet(stores.dbContext)
+	if err != nil {
+		return err
+	}
+	printField("Importing pruning point UTXOs", isImportingPruningPointUTXOSet)
+
+	headersSelectedTip, err := stores.headersSelectedTipStore.HeadersSelectedTip(stores.dbContext, stores.stagingArea)
+	if err != nil {
+		return err
+	}
+	printField("Headers selected tip", headersSelectedTip)
+
+	tips, err := stores.consensusStateStore.Tips(stores.stagingArea, stores.dbContext)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("DAG tips (%d):\n", len(tips))
+	for _, tip := range tips {
+		fmt.Printf("\t%s\n", tip)
+	}
+
+	fmt.Println("Pruning points by index:")
+	for index := pruningPointIndex; ; index-- {
+		pruningPointByIndex, err := stores.pruningStore.PruningPointByIndex(stores.dbContext, stores.stagingArea, index)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\t%d\t%s\n", index, pruningPointByIndex)
+		if index == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+func selectedChain(stores *consensusStores, conf *selectedChainConfig) error {
+	for index := conf.FromIndex; index < conf.FromIndex+conf.Count; index++ {
+		blockHash, err := stores.headersSelectedChainStore.GetHashByIndex(stores.dbContext, stores.stagingArea, index)
+		if database.IsNotFoundError(err) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		ghostdagData, err := stores.ghostdagDataStores[0].Get(stores.dbContext, stores.stagingArea, blockHash, false)
+		if err != nil {
+			return notFoundError(err, "the GHOSTDAG data", blockHash)
+		}
+		fmt.Printf("%d\t%s\tblue score: %d\n", index, blockHash, ghostdagData.BlueScore())
+	}
+	return nil
+}
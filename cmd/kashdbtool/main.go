@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	subCmd, conf, dbFlags := parseCommandLine()
+
+	stores, err := openConsensusStores(dbFlags.databasePath(), dbFlags.NetParams())
+	if err != nil {
+		printErrorAndExit(err)
+	}
+	defer stores.close()
+
+	switch subCmd {
+	case headerSubCmd:
+		err = header(stores, conf.(*blockConfig))
+	case ghostdagSubCmd:
+		err = ghostdag(stores, conf.(*ghostdagConfig))
+	case reachabilitySubCmd:
+		err = reachability(stores, conf.(*blockConfig))
+	case utxoDiffSubCmd:
+		err = utxoDiff(stores, conf.(*blockConfig))
+	case acceptanceDataSubCmd:
+		err = acceptanceData(stores, conf.(*blockConfig))
+	case pruningSubCmd:
+		err = pruning(stores, conf.(*pruningConfig))
+	case selectedChainSubCmd:
+		err = selectedChain(stores, conf.(*selectedChainConfig))
+	case checkSubCmd:
+		err = check(stores, conf.(*checkConfig))
+	case dotSubCmd:
+		err = dot(stores, conf.(*dotConfig))
+	default:
+		err = fmt.Errorf("unknown sub-command '%s'", subCmd)
+	}
+
+	if err != nil {
+		stores.close()
+		printErrorAndExit(err)
+	}
+}
+
+func printErrorAndExit(err error) {
+	fmt.Fprintf(os.Stderr, "%s\n", err)
+	os.Exit(1)
+}
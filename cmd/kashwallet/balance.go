@@ -4,13 +4,12 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/client"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/utils"
 )
 
 func balance(conf *balanceConfig) error {
-	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
 	if err != nil {
 		return err
 	}
@@ -6,13 +6,12 @@ import (
 	"io/ioutil"
 	"strings"
 
-	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/client"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
 	"github.com/pkg/errors"
 )
 
 func broadcast(conf *broadcastConfig) error {
-	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
 	if err != nil {
 		return err
 	}
@@ -42,7 +41,7 @@ func broadcast(conf *broadcastConfig) error {
 		return err
 	}
 
-	response, err := daemonClient.Broadcast(ctx, &pb.BroadcastRequest{Transactions: transactions})
+	response, err := daemonClient.Broadcast(ctx, &pb.BroadcastRequest{IsDomain: conf.IsDomain, Transactions: transactions})
 	if err != nil {
 		return err
 	}
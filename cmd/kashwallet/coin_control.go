@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/utils"
+	"github.com/pkg/errors"
+)
+
+func listUTXOs(conf *listUTXOsConfig) error {
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.ListUTXOs(ctx, &pb.ListUTXOsRequest{})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("UTXOs (%d):\n", len(response.Utxos))
+	total := uint64(0)
+	for _, utxo := range response.Utxos {
+		var flags []string
+		if utxo.IsFrozen {
+			flags = append(flags, "frozen")
+		}
+		if !utxo.IsSpendable {
+			flags = append(flags, "pending")
+		}
+		if utxo.UtxoEntry.IsCoinbase {
+			flags = append(flags, "coinbase")
+		}
+
+		fmt.Printf("%s:%d\t%s\t%s KSH\tDAA score: %d\t%s\n", utxo.Outpoint.TransactionId, utxo.Outpoint.Index,
+			utxo.Address, utils.FormatKas(utxo.UtxoEntry.Amount), utxo.UtxoEntry.BlockDaaScore, strings.Join(flags, ","))
+		total += utxo.UtxoEntry.Amount
+	}
+	fmt.Printf("Total: %s KSH\n", utils.FormatKas(total))
+
+	return nil
+}
+
+func freezeUTXOs(conf *freezeUTXOsConfig) error {
+	outpoints, err := parseOutpoints(conf.Outpoints)
+	if err != nil {
+		return err
+	}
+
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	_, err = daemonClient.FreezeUTXOs(ctx, &pb.FreezeUTXOsRequest{Outpoints: outpoints})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Froze %d UTXO(s)\n", len(outpoints))
+	return nil
+}
+
+func unfreezeUTXOs(conf *freezeUTXOsConfig) error {
+	outpoints, err := parseOutpoints(conf.Outpoints)
+	if err != nil {
+		return err
+	}
+
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	_, err = daemonClient.UnfreezeUTXOs(ctx, &pb.UnfreezeUTXOsRequest{Outpoints: outpoints})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Unfroze %d UTXO(s)\n", len(outpoints))
+	return nil
+}
+
+// parseOutpoints parses a comma separated list of outpoints of the form <transaction ID>:<index>
+func parseOutpoints(outpointsString string) ([]*pb.Outpoint, error) {
+	if outpointsString == "" {
+		return nil, nil
+	}
+
+	var outpoints []*pb.Outpoint
+	for _, outpointString := range strings.Split(outpointsString, ",") {
+		parts := strings.Split(strings.TrimSpace(outpointString), ":")
+		if len(parts) != 2 {
+			return nil, errors.Errorf("Outpoint %s is not of the form <transaction ID>:<index>", outpointString)
+		}
+		index, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid index in outpoint %s", outpointString)
+		}
+		outpoints = append(outpoints, &pb.Outpoint{TransactionId: parts[0], Index: uint32(index)})
+	}
+	return outpoints, nil
+}
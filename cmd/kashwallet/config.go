@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"time"
 
 	"github.com/Kash-Protocol/kashd/infrastructure/config"
 	"github.com/pkg/errors"
@@ -22,6 +23,44 @@ const (
 	newAddressSubCmd                = "new-address"
 	dumpUnencryptedDataSubCmd       = "dump-unencrypted-data"
 	startDaemonSubCmd               = "start-daemon"
+	softwareSignerSubCmd            = "software-signer"
+	listUTXOsSubCmd                 = "list-utxos"
+	freezeSubCmd                    = "freeze"
+	unfreezeSubCmd                  = "unfreeze"
+	requestPaymentSubCmd            = "request-payment"
+	invoicesSubCmd                  = "invoices"
+	historySubCmd                   = "history"
+	labelSubCmd                     = "label"
+	unlockSubCmd                    = "unlock"
+	lockSubCmd                      = "lock"
+	rescanSubCmd                    = "rescan"
+	psktSubCmd                      = "pskt"
+	multisigSubCmd                  = "multisig"
+	swapSubCmd                      = "swap"
+)
+
+// Sub-commands of swapSubCmd
+const (
+	swapInitiateSubCmd    = "initiate"
+	swapParticipateSubCmd = "participate"
+	swapRedeemSubCmd      = "redeem"
+	swapRefundSubCmd      = "refund"
+	swapAuditSubCmd       = "audit"
+)
+
+// Sub-commands of multisigSubCmd
+const (
+	multisigInitSubCmd     = "init"
+	multisigJoinSubCmd     = "join"
+	multisigFinalizeSubCmd = "finalize"
+)
+
+// Sub-commands of psktSubCmd
+const (
+	psktCombineSubCmd  = "combine"
+	psktInspectSubCmd  = "inspect"
+	psktFinalizeSubCmd = "finalize"
+	psktExtractSubCmd  = "extract"
 )
 
 const (
@@ -34,49 +73,59 @@ type configFlags struct {
 }
 
 type createConfig struct {
-	KeysFile          string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kashwallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
-	Password          string `long:"password" short:"p" description:"Wallet password"`
-	Yes               bool   `long:"yes" short:"y" description:"Assume \"yes\" to all questions"`
-	MinimumSignatures uint32 `long:"min-signatures" short:"m" description:"Minimum required signatures" default:"1"`
-	NumPrivateKeys    uint32 `long:"num-private-keys" short:"k" description:"Number of private keys" default:"1"`
-	NumPublicKeys     uint32 `long:"num-public-keys" short:"n" description:"Total number of keys" default:"1"`
-	ECDSA             bool   `long:"ecdsa" description:"Create an ECDSA wallet"`
-	Import            bool   `long:"import" short:"i" description:"Import private keys (as opposed to generating them)"`
+	KeysFile           string   `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kashwallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
+	Password           string   `long:"password" short:"p" description:"Wallet password"`
+	Yes                bool     `long:"yes" short:"y" description:"Assume \"yes\" to all questions"`
+	MinimumSignatures  uint32   `long:"min-signatures" short:"m" description:"Minimum required signatures" default:"1"`
+	NumPrivateKeys     uint32   `long:"num-private-keys" short:"k" description:"Number of private keys" default:"1"`
+	NumPublicKeys      uint32   `long:"num-public-keys" short:"n" description:"Total number of keys" default:"1"`
+	ECDSA              bool     `long:"ecdsa" description:"Create an ECDSA wallet"`
+	Import             bool     `long:"import" short:"i" description:"Import private keys (as opposed to generating them)"`
+	WatchOnly          bool     `long:"watch-only" description:"Create a watch-only wallet, which holds no private keys. It can show addresses and balances, and create unsigned transactions"`
+	ExtendedPublicKeys []string `long:"xpub" description:"Extended public key of a watch-only wallet. Specify it once for every cosigner of a multisig wallet (if omitted, --num-public-keys keys are read from stdin)"`
+	Birthday           uint64   `long:"birthday" description:"The DAA score at which the wallet was first used. Rescans skip the blocks before it"`
 	config.NetworkFlags
 }
 
 type balanceConfig struct {
-	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
-	Verbose       bool   `long:"verbose" short:"v" description:"Verbose: show addresses with balance"`
+	daemonConnectionFlags
+	Verbose bool `long:"verbose" short:"v" description:"Verbose: show addresses with balance"`
 	config.NetworkFlags
 }
 
 type sendConfig struct {
-	KeysFile                 string   `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kashwallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
-	Password                 string   `long:"password" short:"p" description:"Wallet password"`
-	DaemonAddress            string   `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	KeysFile string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kashwallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
+	Password string `long:"password" short:"p" description:"Wallet password"`
+	daemonConnectionFlags
 	ToAddress                string   `long:"to-address" short:"t" description:"The public address to send Kaspa to" required:"true"`
 	FromAddresses            []string `long:"from-address" short:"a" description:"Specific public address to send Kaspa from. Use multiple times to accept several addresses" required:"false"`
 	SendAmount               float64  `long:"send-amount" short:"v" description:"An amount to send in Kaspa (e.g. 1234.12345678)"`
 	IsSendAll                bool     `long:"send-all" description:"Send all the Kaspa in the wallet (mutually exclusive with --send-amount)"`
 	UseExistingChangeAddress bool     `long:"use-existing-change-address" short:"u" description:"Will use an existing change address (in case no change address was ever used, it will use a new one)"`
 	Verbose                  bool     `long:"show-serialized" short:"s" description:"Show a list of hex encoded sent transactions"`
+	Inputs                   string   `long:"inputs" description:"Spend exactly these UTXOs, as a comma separated list of <transaction ID>:<index> (mutually exclusive with --from-address and --selection-strategy)"`
+	SelectionStrategy        string   `long:"selection-strategy" description:"How to select the UTXOs to spend: largest-first (default), branch-and-bound (avoids change when possible) or group-by-address (spends all the UTXOs of an address together)"`
+	Signer                   string   `long:"signer" description:"Sign using the given external signer command instead of the private keys in the keys file"`
+	UseUnlocked              bool     `long:"use-unlocked" description:"Let the wallet daemon sign with the keys unlocked by 'unlock' instead of asking for the password"`
 	config.NetworkFlags
 }
 
 type sweepConfig struct {
-	PrivateKey    string `long:"private-key" short:"k" description:"Private key in hex format"`
-	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	PrivateKey string `long:"private-key" short:"k" description:"Private key in hex format"`
+	daemonConnectionFlags
+	Signer string `long:"signer" description:"Sweep the funds of the private key held by the given external signer command (mutually exclusive with --private-key)"`
 	config.NetworkFlags
 }
 
 type createUnsignedTransactionConfig struct {
-	DaemonAddress            string   `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	daemonConnectionFlags
 	ToAddress                string   `long:"to-address" short:"t" description:"The public address to send Kaspa to" required:"true"`
 	FromAddresses            []string `long:"from-address" short:"a" description:"Specific public address to send Kaspa from. Use multiple times to accept several addresses" required:"false"`
 	SendAmount               float64  `long:"send-amount" short:"v" description:"An amount to send in Kaspa (e.g. 1234.12345678)"`
 	IsSendAll                bool     `long:"send-all" description:"Send all the Kaspa in the wallet (mutually exclusive with --send-amount)"`
 	UseExistingChangeAddress bool     `long:"use-existing-change-address" short:"u" description:"Will use an existing change address (in case no change address was ever used, it will use a new one)"`
+	Inputs                   string   `long:"inputs" description:"Spend exactly these UTXOs, as a comma separated list of <transaction ID>:<index> (mutually exclusive with --from-address and --selection-strategy)"`
+	SelectionStrategy        string   `long:"selection-strategy" description:"How to select the UTXOs to spend: largest-first (default), branch-and-bound (avoids change when possible) or group-by-address (spends all the UTXOs of an address together)"`
 	config.NetworkFlags
 }
 
@@ -85,13 +134,107 @@ type signConfig struct {
 	Password        string `long:"password" short:"p" description:"Wallet password"`
 	Transaction     string `long:"transaction" short:"t" description:"The unsigned transaction(s) to sign on (encoded in hex)"`
 	TransactionFile string `long:"transaction-file" short:"F" description:"The file containing the unsigned transaction(s) to sign on (encoded in hex)"`
+	Signer          string `long:"signer" description:"Sign using the given external signer command instead of the private keys in the keys file"`
+	config.NetworkFlags
+}
+
+type softwareSignerConfig struct {
+	KeysFile   string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kashwallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
+	Password   string `long:"password" short:"p" description:"Wallet password. Required when signing with a keys file, since stdin is used by the signer protocol"`
+	PrivateKey string `long:"private-key" short:"k" description:"Schnorr private key in hex format to sign with instead of a keys file"`
 	config.NetworkFlags
 }
 
 type broadcastConfig struct {
-	DaemonAddress    string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	daemonConnectionFlags
 	Transactions     string `long:"transaction" short:"t" description:"The signed transaction to broadcast (encoded in hex)"`
 	TransactionsFile string `long:"transaction-file" short:"F" description:"The file containing the unsigned transaction to sign on (encoded in hex)"`
+	IsDomain         bool   `long:"domain" description:"The transactions are network-ready transactions, as output by 'pskt extract', rather than signed PSKTs"`
+	config.NetworkFlags
+}
+
+type multisigInitConfig struct {
+	KeysFile          string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kashwallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
+	Password          string `long:"password" short:"p" description:"Wallet password"`
+	Yes               bool   `long:"yes" short:"y" description:"Assume \"yes\" to all questions"`
+	Descriptor        string `long:"descriptor" short:"D" description:"The file to write the multisig setup descriptor to" required:"true"`
+	MinimumSignatures uint32 `long:"min-signatures" short:"m" description:"Minimum required signatures" required:"true"`
+	NumCosigners      uint32 `long:"num-cosigners" short:"n" description:"Total number of cosigners" required:"true"`
+	ECDSA             bool   `long:"ecdsa" description:"Create an ECDSA wallet"`
+	Import            bool   `long:"import" short:"i" description:"Import the private key of this cosigner (as opposed to generating it)"`
+	config.NetworkFlags
+}
+
+type multisigJoinConfig struct {
+	KeysFile   string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kashwallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
+	Password   string `long:"password" short:"p" description:"Wallet password"`
+	Yes        bool   `long:"yes" short:"y" description:"Assume \"yes\" to all questions"`
+	Descriptor string `long:"descriptor" short:"D" description:"The multisig setup descriptor file, which is updated in place" required:"true"`
+	Import     bool   `long:"import" short:"i" description:"Import the private key of this cosigner (as opposed to generating it)"`
+	config.NetworkFlags
+}
+
+type multisigFinalizeConfig struct {
+	KeysFile   string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kashwallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
+	Password   string `long:"password" short:"p" description:"Wallet password"`
+	Yes        bool   `long:"yes" short:"y" description:"Assume \"yes\" to all questions"`
+	Descriptor string `long:"descriptor" short:"D" description:"The multisig setup descriptor file, which is updated in place" required:"true"`
+	config.NetworkFlags
+}
+
+type swapInitiateConfig struct {
+	KeysFile string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kashwallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
+	Password string `long:"password" short:"p" description:"Wallet password"`
+	daemonConnectionFlags
+	To           string        `long:"to" short:"t" description:"The address of the participant, which can redeem the contract with the secret" required:"true"`
+	Amount       float64       `long:"amount" short:"v" description:"The amount to lock in the contract in Kash (e.g. 1234.12345678)" required:"true"`
+	LockDuration time.Duration `long:"lock-duration" description:"How long until the contract can be refunded (e.g. 48h). Should be about twice the lock duration of the participant" default:"48h"`
+	config.NetworkFlags
+}
+
+type swapParticipateConfig struct {
+	KeysFile string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kashwallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
+	Password string `long:"password" short:"p" description:"Wallet password"`
+	daemonConnectionFlags
+	To           string        `long:"to" short:"t" description:"The address of the initiator, which can redeem the contract with the secret" required:"true"`
+	Amount       float64       `long:"amount" short:"v" description:"The amount to lock in the contract in Kash (e.g. 1234.12345678)" required:"true"`
+	SecretHash   string        `long:"secret-hash" short:"s" description:"The secret hash of the contract of the initiator (encoded in hex)" required:"true"`
+	LockDuration time.Duration `long:"lock-duration" description:"How long until the contract can be refunded (e.g. 24h)" default:"24h"`
+	config.NetworkFlags
+}
+
+type swapRedeemConfig struct {
+	KeysFile string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kashwallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
+	Password string `long:"password" short:"p" description:"Wallet password"`
+	daemonConnectionFlags
+	Contract string `long:"contract" short:"c" description:"The contract to redeem (encoded in hex)" required:"true"`
+	Secret   string `long:"secret" short:"s" description:"The secret of the contract (encoded in hex). If omitted, it's looked for in the transactions that redeemed the contract of the counterparty"`
+	config.NetworkFlags
+}
+
+type swapRefundConfig struct {
+	KeysFile string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kashwallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
+	Password string `long:"password" short:"p" description:"Wallet password"`
+	daemonConnectionFlags
+	Contract string `long:"contract" short:"c" description:"The contract to refund (encoded in hex)" required:"true"`
+	config.NetworkFlags
+}
+
+type swapAuditConfig struct {
+	daemonConnectionFlags
+	Contract string `long:"contract" short:"c" description:"The contract to audit (encoded in hex)" required:"true"`
+	config.NetworkFlags
+}
+
+type psktCombineConfig struct {
+	Transactions     []string `long:"transaction" short:"t" description:"A PSKT to combine (encoded in hex). Use multiple times to combine several PSKTs"`
+	TransactionFiles []string `long:"transaction-file" short:"F" description:"A file containing a PSKT to combine (encoded in hex). Use multiple times to combine several PSKTs"`
+	config.NetworkFlags
+}
+
+type psktConfig struct {
+	Transaction     string `long:"transaction" short:"t" description:"The PSKT(s) (encoded in hex)"`
+	TransactionFile string `long:"transaction-file" short:"F" description:"The file containing the PSKT(s) (encoded in hex)"`
 	config.NetworkFlags
 }
 
@@ -103,12 +246,58 @@ type parseConfig struct {
 }
 
 type showAddressesConfig struct {
-	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	daemonConnectionFlags
+	config.NetworkFlags
+}
+
+type listUTXOsConfig struct {
+	daemonConnectionFlags
+	config.NetworkFlags
+}
+
+type freezeUTXOsConfig struct {
+	daemonConnectionFlags
+	Outpoints string `long:"outpoints" short:"o" description:"Comma separated list of UTXOs, each of the form <transaction ID>:<index>" required:"true"`
+	config.NetworkFlags
+}
+
+type requestPaymentConfig struct {
+	daemonConnectionFlags
+	Amount        float64       `long:"amount" short:"v" description:"The amount to request in KSH (e.g. 1234.12345678)" required:"true"`
+	Label         string        `long:"label" description:"A label for the recipient, shown to the payer"`
+	Message       string        `long:"message" description:"A message describing the payment, shown to the payer"`
+	Expiry        time.Duration `long:"expiry" description:"How long the invoice stays payable (e.g. 30m). Zero means it never expires" default:"1h"`
+	Confirmations uint64        `long:"confirmations" description:"The number of confirmations, measured in DAA score, after which the invoice is paid" default:"10"`
+	config.NetworkFlags
+}
+
+type invoicesConfig struct {
+	daemonConnectionFlags
+	Follow bool `long:"follow" short:"F" description:"Keep running, and print every invoice whose status changes"`
+	config.NetworkFlags
+}
+
+type historyConfig struct {
+	daemonConnectionFlags
+	Offset uint32 `long:"offset" description:"The number of newest transactions to skip"`
+	Limit  uint32 `long:"limit" short:"n" description:"The number of transactions to show" default:"20"`
+	All    bool   `long:"all" description:"Show all the transactions (overrides --offset and --limit)"`
+	Format string `long:"format" description:"The output format" choice:"table" choice:"csv" choice:"json" default:"table"`
+	Output string `long:"output" short:"o" description:"Write the output to the given file instead of stdout"`
+	config.NetworkFlags
+}
+
+type labelConfig struct {
+	daemonConnectionFlags
+	Address       string `long:"address" short:"a" description:"The address to label (mutually exclusive with --transaction-id)"`
+	TransactionID string `long:"transaction-id" short:"t" description:"The ID of the transaction to label (mutually exclusive with --address)"`
+	Label         string `long:"label" short:"l" description:"The label"`
+	Remove        bool   `long:"remove" description:"Remove the existing label (mutually exclusive with --label)"`
 	config.NetworkFlags
 }
 
 type newAddressConfig struct {
-	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	daemonConnectionFlags
 	config.NetworkFlags
 }
 
@@ -119,6 +308,31 @@ type startDaemonConfig struct {
 	Listen    string `long:"listen" short:"l" description:"Address to listen on (default: 0.0.0.0:8082)"`
 	Timeout   uint32 `long:"wait-timeout" short:"w" description:"Waiting timeout for RPC calls, seconds (default: 30 s)"`
 	Profile   string `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+	TLSCert   string `long:"tls-cert" description:"The TLS certificate to serve the API with (default: keys.daemon.cert next to the keys file, created if missing)"`
+	TLSKey    string `long:"tls-key" description:"The key of the TLS certificate (default: keys.daemon.key next to the keys file, created if missing)"`
+	NoTLS     bool   `long:"notls" description:"Serve the API without TLS. Passwords sent to the daemon are then unencrypted"`
+	TokenFile string `long:"token-file" description:"The file containing the API token clients must send (default: keys.daemon.token next to the keys file, created if missing)"`
+	NoAuth    bool   `long:"noauth" description:"Don't require an API token from clients"`
+	GapLimit  uint32 `long:"gap-limit" description:"The number of consecutive unused addresses after which address discovery stops" default:"1000"`
+	config.NetworkFlags
+}
+
+type unlockConfig struct {
+	daemonConnectionFlags
+	Password string        `long:"password" short:"p" description:"Wallet password"`
+	Timeout  time.Duration `long:"timeout" short:"t" description:"How long the wallet stays unlocked (e.g. 10m, at most 24h)" default:"5m"`
+	config.NetworkFlags
+}
+
+type lockConfig struct {
+	daemonConnectionFlags
+	config.NetworkFlags
+}
+
+type rescanConfig struct {
+	daemonConnectionFlags
+	Birthday uint64 `long:"birthday" description:"The DAA score at which the wallet was first used. It's saved in the keys file (default: the saved birthday)"`
+	GapLimit uint32 `long:"gap-limit" description:"The number of consecutive unused addresses after which the scan stops (default: the gap limit of the daemon)"`
 	config.NetworkFlags
 }
 
@@ -137,21 +351,21 @@ func parseCommandLine() (subCommand string, config interface{}) {
 	parser.AddCommand(createSubCmd, "Creates a new wallet",
 		"Creates a private key and 3 public addresses, one for each of MainNet, TestNet and DevNet", createConf)
 
-	balanceConf := &balanceConfig{DaemonAddress: defaultListen}
+	balanceConf := &balanceConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
 	parser.AddCommand(balanceSubCmd, "Shows the balance of a public address",
 		"Shows the balance for a public address in Kaspa", balanceConf)
 
-	sendConf := &sendConfig{DaemonAddress: defaultListen}
+	sendConf := &sendConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
 	parser.AddCommand(sendSubCmd, "Sends a Kaspa transaction to a public address",
 		"Sends a Kaspa transaction to a public address", sendConf)
 
-	sweepConf := &sweepConfig{DaemonAddress: defaultListen}
+	sweepConf := &sweepConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
 	parser.AddCommand(sweepSubCmd, "Sends all funds associated with the given schnorr private key to a new address of the current wallet",
 		"Sends all funds associated with the given schnorr private key to a newly created external (i.e. not a change) address of the "+
 			"keyfile that is under the daemon's contol. Can be used with a private key generated with the genkeypair utilily "+
 			"to send funds to your main wallet.", sweepConf)
 
-	createUnsignedTransactionConf := &createUnsignedTransactionConfig{DaemonAddress: defaultListen}
+	createUnsignedTransactionConf := &createUnsignedTransactionConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
 	parser.AddCommand(createUnsignedTransactionSubCmd, "Create an unsigned Kaspa transaction",
 		"Create an unsigned Kaspa transaction", createUnsignedTransactionConf)
 
@@ -159,7 +373,7 @@ func parseCommandLine() (subCommand string, config interface{}) {
 	parser.AddCommand(signSubCmd, "Sign the given partially signed transaction",
 		"Sign the given partially signed transaction", signConf)
 
-	broadcastConf := &broadcastConfig{DaemonAddress: defaultListen}
+	broadcastConf := &broadcastConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
 	parser.AddCommand(broadcastSubCmd, "Broadcast the given transaction",
 		"Broadcast the given transaction", broadcastConf)
 
@@ -167,14 +381,44 @@ func parseCommandLine() (subCommand string, config interface{}) {
 	parser.AddCommand(parseSubCmd, "Parse the given transaction and print its contents",
 		"Parse the given transaction and print its contents", parseConf)
 
-	showAddressesConf := &showAddressesConfig{DaemonAddress: defaultListen}
+	showAddressesConf := &showAddressesConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
 	parser.AddCommand(showAddressesSubCmd, "Shows all generated public addresses of the current wallet",
 		"Shows all generated public addresses of the current wallet", showAddressesConf)
 
-	newAddressConf := &newAddressConfig{DaemonAddress: defaultListen}
+	newAddressConf := &newAddressConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
 	parser.AddCommand(newAddressSubCmd, "Generates new public address of the current wallet and shows it",
 		"Generates new public address of the current wallet and shows it", newAddressConf)
 
+	listUTXOsConf := &listUTXOsConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	parser.AddCommand(listUTXOsSubCmd, "Lists the UTXOs of the current wallet",
+		"Lists the UTXOs of the current wallet, along with whether they're frozen or pending", listUTXOsConf)
+
+	freezeConf := &freezeUTXOsConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	parser.AddCommand(freezeSubCmd, "Freezes UTXOs so that they're not spent",
+		"Freezes the given UTXOs so that they're neither selected automatically nor spent with --inputs, until "+
+			"they're unfrozen. Frozen UTXOs are persisted next to the keys file", freezeConf)
+
+	unfreezeConf := &freezeUTXOsConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	parser.AddCommand(unfreezeSubCmd, "Unfreezes frozen UTXOs", "Unfreezes the given frozen UTXOs", unfreezeConf)
+
+	requestPaymentConf := &requestPaymentConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	parser.AddCommand(requestPaymentSubCmd, "Creates an invoice and shows its payment URI",
+		"Allocates a new address for a payment of the given amount, records it as an invoice that can be tracked with "+
+			"'invoices', and shows its kash: payment URI", requestPaymentConf)
+
+	invoicesConf := &invoicesConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	parser.AddCommand(invoicesSubCmd, "Shows the invoices and whether they're paid",
+		"Shows the invoices created by 'request-payment', and whether each is unpaid, pending, paid, overpaid, expired or late", invoicesConf)
+
+	historyConf := &historyConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	parser.AddCommand(historySubCmd, "Shows the transaction history of the current wallet",
+		"Shows the transactions the wallet sent and received, newest first, along with their labels. "+
+			"Use --format csv or --format json with --all and --output to export the whole history", historyConf)
+
+	labelConf := &labelConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	parser.AddCommand(labelSubCmd, "Labels an address or a transaction",
+		"Sets or removes the label of an address or a transaction, which is shown in the history", labelConf)
+
 	dumpUnencryptedDataConf := &dumpUnencryptedDataConfig{}
 	parser.AddCommand(dumpUnencryptedDataSubCmd, "Prints the unencrypted wallet data",
 		"Prints the unencrypted wallet data including its private keys. Anyone that sees it can access "+
@@ -184,9 +428,93 @@ func parseCommandLine() (subCommand string, config interface{}) {
 		RPCServer: defaultRPCServer,
 		Listen:    defaultListen,
 	}
-	parser.AddCommand(startDaemonSubCmd, "Start the wallet daemon", "Start the wallet daemon", startDaemonConf)
-
-	_, err := parser.Parse()
+	parser.AddCommand(startDaemonSubCmd, "Start the wallet daemon",
+		"Start the wallet daemon. By default its API is served over TLS and requires the API token, "+
+			"both of which are created next to the keys file", startDaemonConf)
+
+	unlockConf := &unlockConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	parser.AddCommand(unlockSubCmd, "Unlocks the wallet in the daemon",
+		"Lets the wallet daemon sign transactions without receiving the password, until the timeout elapses "+
+			"or 'lock' is called", unlockConf)
+
+	lockConf := &lockConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	parser.AddCommand(lockSubCmd, "Locks the wallet in the daemon",
+		"Makes the wallet daemon forget the keys unlocked by 'unlock'", lockConf)
+
+	rescanConf := &rescanConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	parser.AddCommand(rescanSubCmd, "Rescans the wallet addresses",
+		"Rediscovers the used addresses of the wallet, by scanning the blocks since the wallet birthday "+
+			"and the address balances until the gap limit is reached", rescanConf)
+
+	softwareSignerConf := &softwareSignerConfig{}
+	parser.AddCommand(softwareSignerSubCmd, "Run as an external signer that signs with software keys",
+		"Reads a single external signer request from stdin and writes the response to stdout, signing with the "+
+			"private keys of a keys file or with a raw private key. This is the reference implementation of the "+
+			"external signer protocol, and can be passed to --signer of the sign, send and sweep commands.", softwareSignerConf)
+
+	multisigCmd, err := parser.AddCommand(multisigSubCmd, "Multisig wallet setup",
+		"Sets up a multisig wallet by passing a setup descriptor file between the cosigners: one cosigner runs "+
+			"'init', every other cosigner runs 'join', and then all of them run 'finalize', which signs the "+
+			"descriptor and, once it's signed by everyone, writes the keys file.", &struct{}{})
+	if err != nil {
+		printErrorAndExit(err)
+	}
+	multisigInitConf := &multisigInitConfig{}
+	multisigCmd.AddCommand(multisigInitSubCmd, "Start a multisig setup",
+		"Create a setup descriptor for an m-of-n multisig wallet, and join it as its first cosigner", multisigInitConf)
+	multisigJoinConf := &multisigJoinConfig{}
+	multisigCmd.AddCommand(multisigJoinSubCmd, "Join a multisig setup",
+		"Add a new cosigner to the setup descriptor", multisigJoinConf)
+	multisigFinalizeConf := &multisigFinalizeConfig{}
+	multisigCmd.AddCommand(multisigFinalizeSubCmd, "Sign a multisig setup and write the keys file",
+		"Verify the setup descriptor and its addresses, sign it, and write the keys file once every cosigner "+
+			"signed it", multisigFinalizeConf)
+
+	swapCmd, err := parser.AddCommand(swapSubCmd, "Atomic swaps",
+		"Cross-chain atomic swaps using hash time locked contracts: the initiator locks funds to the participant "+
+			"with 'initiate', the participant audits that contract and locks funds to the initiator with "+
+			"'participate' using the same secret hash, the initiator redeems the contract of the participant, "+
+			"revealing the secret, and the participant then redeems the contract of the initiator with it. "+
+			"Unredeemed contracts can be refunded once their lock time has passed.", &struct{}{})
+	if err != nil {
+		printErrorAndExit(err)
+	}
+	swapInitiateConf := &swapInitiateConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	swapCmd.AddCommand(swapInitiateSubCmd, "Initiate an atomic swap",
+		"Generate a secret and fund a contract that the participant can redeem with it", swapInitiateConf)
+	swapParticipateConf := &swapParticipateConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	swapCmd.AddCommand(swapParticipateSubCmd, "Participate in an atomic swap",
+		"Fund a contract that the initiator can redeem with the secret of its own contract", swapParticipateConf)
+	swapRedeemConf := &swapRedeemConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	swapCmd.AddCommand(swapRedeemSubCmd, "Redeem an atomic swap contract",
+		"Redeem a contract paying to this wallet with its secret", swapRedeemConf)
+	swapRefundConf := &swapRefundConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	swapCmd.AddCommand(swapRefundSubCmd, "Refund an atomic swap contract",
+		"Refund a contract funded by this wallet once its lock time has passed", swapRefundConf)
+	swapAuditConf := &swapAuditConfig{daemonConnectionFlags: defaultDaemonConnectionFlags()}
+	swapCmd.AddCommand(swapAuditSubCmd, "Audit an atomic swap contract",
+		"Show the details and the funds of a contract, or its secret if it was already redeemed", swapAuditConf)
+
+	psktCmd, err := parser.AddCommand(psktSubCmd, "Partially signed Kash transaction (PSKT) tools",
+		"Tools for the combiner, finalizer and extractor roles of partially signed Kash transactions (PSKTs). "+
+			"PSKTs are created by create-unsigned-transaction and signed by sign.", &struct{}{})
+	if err != nil {
+		printErrorAndExit(err)
+	}
+	psktCombineConf := &psktCombineConfig{}
+	psktCmd.AddCommand(psktCombineSubCmd, "Combine the signatures of several copies of the same PSKT",
+		"Combine the signatures of several copies of the same PSKT, each signed by different cosigners", psktCombineConf)
+	psktInspectConf := &psktConfig{}
+	psktCmd.AddCommand(psktInspectSubCmd, "Show the contents and the signing status of a PSKT",
+		"Show the contents and the signing status of a PSKT", psktInspectConf)
+	psktFinalizeConf := &psktConfig{}
+	psktCmd.AddCommand(psktFinalizeSubCmd, "Finalize a fully signed PSKT",
+		"Build the signature scripts of a fully signed PSKT out of its signatures", psktFinalizeConf)
+	psktExtractConf := &psktConfig{}
+	psktCmd.AddCommand(psktExtractSubCmd, "Extract the network-ready transaction of a finalized PSKT",
+		"Extract the network-ready transaction of a finalized PSKT. It can be broadcast with 'broadcast --domain'", psktExtractConf)
+
+	_, err = parser.Parse()
 	if err != nil {
 		var flagsErr *flags.Error
 		if ok := errors.As(err, &flagsErr); ok && flagsErr.Type == flags.ErrHelp {
@@ -204,6 +532,10 @@ func parseCommandLine() (subCommand string, config interface{}) {
 		if err != nil {
 			printErrorAndExit(err)
 		}
+		err = validateCreateConfig(createConf)
+		if err != nil {
+			printErrorAndExit(err)
+		}
 		config = createConf
 	case balanceSubCmd:
 		combineNetworkFlags(&balanceConf.NetworkFlags, &cfg.NetworkFlags)
@@ -229,6 +561,10 @@ func parseCommandLine() (subCommand string, config interface{}) {
 		if err != nil {
 			printErrorAndExit(err)
 		}
+		err = validateSweepConfig(sweepConf)
+		if err != nil {
+			printErrorAndExit(err)
+		}
 		config = sweepConf
 	case createUnsignedTransactionSubCmd:
 		combineNetworkFlags(&createUnsignedTransactionConf.NetworkFlags, &cfg.NetworkFlags)
@@ -276,6 +612,63 @@ func parseCommandLine() (subCommand string, config interface{}) {
 			printErrorAndExit(err)
 		}
 		config = newAddressConf
+	case listUTXOsSubCmd:
+		combineNetworkFlags(&listUTXOsConf.NetworkFlags, &cfg.NetworkFlags)
+		err := listUTXOsConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = listUTXOsConf
+	case freezeSubCmd:
+		combineNetworkFlags(&freezeConf.NetworkFlags, &cfg.NetworkFlags)
+		err := freezeConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = freezeConf
+	case unfreezeSubCmd:
+		combineNetworkFlags(&unfreezeConf.NetworkFlags, &cfg.NetworkFlags)
+		err := unfreezeConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = unfreezeConf
+	case requestPaymentSubCmd:
+		combineNetworkFlags(&requestPaymentConf.NetworkFlags, &cfg.NetworkFlags)
+		err := requestPaymentConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		err = validateRequestPaymentConfig(requestPaymentConf)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = requestPaymentConf
+	case invoicesSubCmd:
+		combineNetworkFlags(&invoicesConf.NetworkFlags, &cfg.NetworkFlags)
+		err := invoicesConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = invoicesConf
+	case historySubCmd:
+		combineNetworkFlags(&historyConf.NetworkFlags, &cfg.NetworkFlags)
+		err := historyConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = historyConf
+	case labelSubCmd:
+		combineNetworkFlags(&labelConf.NetworkFlags, &cfg.NetworkFlags)
+		err := labelConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		err = validateLabelConfig(labelConf)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = labelConf
 	case dumpUnencryptedDataSubCmd:
 		combineNetworkFlags(&dumpUnencryptedDataConf.NetworkFlags, &cfg.NetworkFlags)
 		err := dumpUnencryptedDataConf.ResolveNetwork(parser)
@@ -289,19 +682,164 @@ func parseCommandLine() (subCommand string, config interface{}) {
 		if err != nil {
 			printErrorAndExit(err)
 		}
+		err = validateStartDaemonConfig(startDaemonConf)
+		if err != nil {
+			printErrorAndExit(err)
+		}
 		config = startDaemonConf
+	case unlockSubCmd:
+		combineNetworkFlags(&unlockConf.NetworkFlags, &cfg.NetworkFlags)
+		err := unlockConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = unlockConf
+	case lockSubCmd:
+		combineNetworkFlags(&lockConf.NetworkFlags, &cfg.NetworkFlags)
+		err := lockConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = lockConf
+	case rescanSubCmd:
+		combineNetworkFlags(&rescanConf.NetworkFlags, &cfg.NetworkFlags)
+		err := rescanConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = rescanConf
+	case softwareSignerSubCmd:
+		combineNetworkFlags(&softwareSignerConf.NetworkFlags, &cfg.NetworkFlags)
+		err := softwareSignerConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		err = validateSoftwareSignerConfig(softwareSignerConf)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = softwareSignerConf
+	case multisigSubCmd:
+		switch parser.Command.Active.Active.Name {
+		case multisigInitSubCmd:
+			combineNetworkFlags(&multisigInitConf.NetworkFlags, &cfg.NetworkFlags)
+			err := multisigInitConf.ResolveNetwork(parser)
+			if err != nil {
+				printErrorAndExit(err)
+			}
+			config = multisigInitConf
+		case multisigJoinSubCmd:
+			combineNetworkFlags(&multisigJoinConf.NetworkFlags, &cfg.NetworkFlags)
+			err := multisigJoinConf.ResolveNetwork(parser)
+			if err != nil {
+				printErrorAndExit(err)
+			}
+			config = multisigJoinConf
+		case multisigFinalizeSubCmd:
+			combineNetworkFlags(&multisigFinalizeConf.NetworkFlags, &cfg.NetworkFlags)
+			err := multisigFinalizeConf.ResolveNetwork(parser)
+			if err != nil {
+				printErrorAndExit(err)
+			}
+			config = multisigFinalizeConf
+		}
+		return multisigSubCmd + " " + parser.Command.Active.Active.Name, config
+	case swapSubCmd:
+		switch parser.Command.Active.Active.Name {
+		case swapInitiateSubCmd:
+			combineNetworkFlags(&swapInitiateConf.NetworkFlags, &cfg.NetworkFlags)
+			err := swapInitiateConf.ResolveNetwork(parser)
+			if err != nil {
+				printErrorAndExit(err)
+			}
+			config = swapInitiateConf
+		case swapParticipateSubCmd:
+			combineNetworkFlags(&swapParticipateConf.NetworkFlags, &cfg.NetworkFlags)
+			err := swapParticipateConf.ResolveNetwork(parser)
+			if err != nil {
+				printErrorAndExit(err)
+			}
+			config = swapParticipateConf
+		case swapRedeemSubCmd:
+			combineNetworkFlags(&swapRedeemConf.NetworkFlags, &cfg.NetworkFlags)
+			err := swapRedeemConf.ResolveNetwork(parser)
+			if err != nil {
+				printErrorAndExit(err)
+			}
+			config = swapRedeemConf
+		case swapRefundSubCmd:
+			combineNetworkFlags(&swapRefundConf.NetworkFlags, &cfg.NetworkFlags)
+			err := swapRefundConf.ResolveNetwork(parser)
+			if err != nil {
+				printErrorAndExit(err)
+			}
+			config = swapRefundConf
+		case swapAuditSubCmd:
+			combineNetworkFlags(&swapAuditConf.NetworkFlags, &cfg.NetworkFlags)
+			err := swapAuditConf.ResolveNetwork(parser)
+			if err != nil {
+				printErrorAndExit(err)
+			}
+			config = swapAuditConf
+		}
+		return swapSubCmd + " " + parser.Command.Active.Active.Name, config
+	case psktSubCmd:
+		psktSubCommand := parser.Command.Active.Active.Name
+		if psktSubCommand == psktCombineSubCmd {
+			combineNetworkFlags(&psktCombineConf.NetworkFlags, &cfg.NetworkFlags)
+			err := psktCombineConf.ResolveNetwork(parser)
+			if err != nil {
+				printErrorAndExit(err)
+			}
+			config = psktCombineConf
+		} else {
+			psktConf := map[string]*psktConfig{
+				psktInspectSubCmd:  psktInspectConf,
+				psktFinalizeSubCmd: psktFinalizeConf,
+				psktExtractSubCmd:  psktExtractConf,
+			}[psktSubCommand]
+			combineNetworkFlags(&psktConf.NetworkFlags, &cfg.NetworkFlags)
+			err := psktConf.ResolveNetwork(parser)
+			if err != nil {
+				printErrorAndExit(err)
+			}
+			config = psktConf
+		}
+		return psktSubCmd + " " + parser.Command.Active.Active.Name, config
 	}
 
 	return parser.Command.Active.Name, config
 }
 
+func validateCreateConfig(conf *createConfig) error {
+	if len(conf.ExtendedPublicKeys) > 0 && !conf.WatchOnly {
+		return errors.New("'--xpub' can only be used along with '--watch-only'")
+	}
+	if !conf.WatchOnly {
+		return nil
+	}
+	if conf.Import {
+		return errors.New("'--watch-only' and '--import' cannot be used together")
+	}
+
+	conf.NumPrivateKeys = 0
+	if len(conf.ExtendedPublicKeys) > 0 {
+		conf.NumPublicKeys = uint32(len(conf.ExtendedPublicKeys))
+	}
+	if conf.MinimumSignatures > conf.NumPublicKeys {
+		return errors.Errorf("'--min-signatures' (%d) cannot be greater than the number of keys (%d)",
+			conf.MinimumSignatures, conf.NumPublicKeys)
+	}
+	return nil
+}
+
 func validateCreateUnsignedTransactionConf(conf *createUnsignedTransactionConfig) error {
 	if (!conf.IsSendAll && conf.SendAmount == 0) ||
 		(conf.IsSendAll && conf.SendAmount > 0) {
 
 		return errors.New("exactly one of '--send-amount' or '--all' must be specified")
 	}
-	return nil
+	return validateCoinControlFlags(conf.Inputs, conf.FromAddresses, conf.SelectionStrategy)
 }
 
 func validateSendConfig(conf *sendConfig) error {
@@ -310,6 +848,70 @@ func validateSendConfig(conf *sendConfig) error {
 
 		return errors.New("exactly one of '--send-amount' or '--all' must be specified")
 	}
+	if conf.UseUnlocked && (conf.Signer != "" || conf.Password != "") {
+		return errors.New("'--use-unlocked' cannot be used along with '--signer' or '--password'")
+	}
+	return validateCoinControlFlags(conf.Inputs, conf.FromAddresses, conf.SelectionStrategy)
+}
+
+func validateCoinControlFlags(inputs string, fromAddresses []string, selectionStrategy string) error {
+	if inputs == "" {
+		return nil
+	}
+	if len(fromAddresses) > 0 {
+		return errors.New("'--inputs' cannot be used along with '--from-address'")
+	}
+	if selectionStrategy != "" {
+		return errors.New("'--inputs' cannot be used along with '--selection-strategy'")
+	}
+	_, err := parseOutpoints(inputs)
+	return err
+}
+
+func validateRequestPaymentConfig(conf *requestPaymentConfig) error {
+	if conf.Amount <= 0 {
+		return errors.New("'--amount' must be positive")
+	}
+	if conf.Expiry < 0 {
+		return errors.New("'--expiry' cannot be negative")
+	}
+	return nil
+}
+
+func validateLabelConfig(conf *labelConfig) error {
+	if (conf.Address == "") == (conf.TransactionID == "") {
+		return errors.New("exactly one of '--address' or '--transaction-id' must be specified")
+	}
+	if (conf.Label == "") == !conf.Remove {
+		return errors.New("exactly one of '--label' or '--remove' must be specified")
+	}
+	return nil
+}
+
+func validateSweepConfig(conf *sweepConfig) error {
+	if (conf.PrivateKey == "") == (conf.Signer == "") {
+		return errors.New("exactly one of '--private-key' or '--signer' must be specified")
+	}
+	return nil
+}
+
+func validateStartDaemonConfig(conf *startDaemonConfig) error {
+	if conf.GapLimit == 0 {
+		return errors.New("'--gap-limit' must be positive")
+	}
+	return nil
+}
+
+func validateSoftwareSignerConfig(conf *softwareSignerConfig) error {
+	if conf.PrivateKey != "" {
+		if conf.KeysFile != "" || conf.Password != "" {
+			return errors.New("'--private-key' cannot be used along with '--keys-file' or '--password'")
+		}
+		return nil
+	}
+	if conf.Password == "" {
+		return errors.New("'--password' is required when signing with a keys file")
+	}
 	return nil
 }
 
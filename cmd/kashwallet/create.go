@@ -6,7 +6,6 @@ import (
 	"os"
 
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
-	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/bip32"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/utils"
 	"github.com/pkg/errors"
 
@@ -18,36 +17,46 @@ func create(conf *createConfig) error {
 	var signerExtendedPublicKeys []string
 	var err error
 	isMultisig := conf.NumPublicKeys > 1
-	if !conf.Import {
-		encryptedMnemonics, signerExtendedPublicKeys, err = keys.CreateMnemonics(conf.NetParams(), conf.NumPrivateKeys, conf.Password, isMultisig)
-	} else {
-		encryptedMnemonics, signerExtendedPublicKeys, err = keys.ImportMnemonics(conf.NetParams(), conf.NumPrivateKeys, conf.Password, isMultisig)
-	}
-	if err != nil {
-		return err
-	}
+	// A watch-only wallet holds no mnemonics, only the extended public keys of its cosigners
+	if !conf.WatchOnly {
+		if !conf.Import {
+			encryptedMnemonics, signerExtendedPublicKeys, err = keys.CreateMnemonics(conf.NetParams(), conf.NumPrivateKeys, conf.Password, isMultisig)
+		} else {
+			encryptedMnemonics, signerExtendedPublicKeys, err = keys.ImportMnemonics(conf.NetParams(), conf.NumPrivateKeys, conf.Password, isMultisig)
+		}
+		if err != nil {
+			return err
+		}
 
-	for i, extendedPublicKey := range signerExtendedPublicKeys {
-		fmt.Printf("Extended public key of mnemonic #%d:\n%s\n\n", i+1, extendedPublicKey)
-	}
+		for i, extendedPublicKey := range signerExtendedPublicKeys {
+			fmt.Printf("Extended public key of mnemonic #%d:\n%s\n\n", i+1, extendedPublicKey)
+		}
 
-	fmt.Printf("Notice the above is neither a secret key to your wallet " +
-		"(use \"kashwallet dump-unencrypted-data\" to see a secret seed phrase) " +
-		"nor a wallet public address (use \"kashwallet new-address\" to create and see one)\n\n")
+		fmt.Printf("Notice the above is neither a secret key to your wallet " +
+			"(use \"kashwallet dump-unencrypted-data\" to see a secret seed phrase) " +
+			"nor a wallet public address (use \"kashwallet new-address\" to create and see one)\n\n")
+	}
 
 	extendedPublicKeys := make([]string, conf.NumPrivateKeys, conf.NumPublicKeys)
 	copy(extendedPublicKeys, signerExtendedPublicKeys)
+	for _, extendedPublicKey := range conf.ExtendedPublicKeys {
+		err = libkashwallet.ValidateExtendedPublicKey(conf.NetParams(), extendedPublicKey)
+		if err != nil {
+			return err
+		}
+		extendedPublicKeys = append(extendedPublicKeys, extendedPublicKey)
+	}
 	reader := bufio.NewReader(os.Stdin)
-	for i := conf.NumPrivateKeys; i < conf.NumPublicKeys; i++ {
+	for i := uint32(len(extendedPublicKeys)); i < conf.NumPublicKeys; i++ {
 		fmt.Printf("Enter public key #%d here:\n", i+1)
 		extendedPublicKey, err := utils.ReadLine(reader)
 		if err != nil {
 			return err
 		}
 
-		_, err = bip32.DeserializeExtendedKey(string(extendedPublicKey))
+		err = libkashwallet.ValidateExtendedPublicKey(conf.NetParams(), string(extendedPublicKey))
 		if err != nil {
-			return errors.Wrapf(err, "%s is invalid extended public key", string(extendedPublicKey))
+			return err
 		}
 
 		fmt.Println()
@@ -55,6 +64,14 @@ func create(conf *createConfig) error {
 		extendedPublicKeys = append(extendedPublicKeys, string(extendedPublicKey))
 	}
 
+	seenExtendedPublicKeys := make(map[string]struct{}, len(extendedPublicKeys))
+	for _, extendedPublicKey := range extendedPublicKeys {
+		if _, ok := seenExtendedPublicKeys[extendedPublicKey]; ok {
+			return errors.Errorf("extended public key %s was given more than once", extendedPublicKey)
+		}
+		seenExtendedPublicKeys[extendedPublicKey] = struct{}{}
+	}
+
 	// For a read only wallet the cosigner index is 0
 	cosignerIndex := uint32(0)
 	if len(signerExtendedPublicKeys) > 0 {
@@ -71,6 +88,7 @@ func create(conf *createConfig) error {
 		MinimumSignatures:  conf.MinimumSignatures,
 		CosignerIndex:      cosignerIndex,
 		ECDSA:              conf.ECDSA,
+		BirthdayDAAScore:   conf.Birthday,
 	}
 
 	err = file.SetPath(conf.NetParams(), conf.KeysFile, conf.Yes)
@@ -5,13 +5,12 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/client"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
 	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
 )
 
 func createUnsignedTransaction(conf *createUnsignedTransactionConfig) error {
-	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
 	if err != nil {
 		return err
 	}
@@ -20,6 +19,11 @@ func createUnsignedTransaction(conf *createUnsignedTransactionConfig) error {
 	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
 	defer cancel()
 
+	inputs, err := parseOutpoints(conf.Inputs)
+	if err != nil {
+		return err
+	}
+
 	sendAmountSompi := uint64(conf.SendAmount * constants.SompiPerKaspa)
 	response, err := daemonClient.CreateUnsignedTransactions(ctx, &pb.CreateUnsignedTransactionsRequest{
 		From:                     conf.FromAddresses,
@@ -27,6 +31,8 @@ func createUnsignedTransaction(conf *createUnsignedTransactionConfig) error {
 		Amount:                   sendAmountSompi,
 		IsSendAll:                conf.IsSendAll,
 		UseExistingChangeAddress: conf.UseExistingChangeAddress,
+		Inputs:                   inputs,
+		SelectionStrategy:        conf.SelectionStrategy,
 	})
 	if err != nil {
 		return err
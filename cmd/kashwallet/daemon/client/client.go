@@ -1,26 +1,52 @@
 package client
 
 import (
+	"bytes"
 	"context"
-	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/server"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/server"
+	"google.golang.org/grpc/credentials"
+
 	"github.com/pkg/errors"
 
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
 	"google.golang.org/grpc"
 )
 
+// Security defines how the connection to the kashwalletd server is secured
+type Security struct {
+	// TLSCertFile is the certificate of the daemon, which is pinned: the daemon must present
+	// exactly this certificate. An empty path means that the connection isn't encrypted
+	TLSCertFile string
+	// APITokenFile is the file containing the token that is sent with every call.
+	// An empty path means that no token is sent
+	APITokenFile string
+}
+
 // Connect connects to the kashwalletd server, and returns the client instance
-func Connect(address string) (pb.KaspawalletdClient, func(), error) {
+func Connect(address string, security *Security) (pb.KaspawalletdClient, func(), error) {
+	dialOptions, err := securityDialOptions(security)
+	if err != nil {
+		return nil, nil, err
+	}
+	dialOptions = append(dialOptions, grpc.WithBlock(),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(server.MaxDaemonSendMsgSize)))
+
 	// Connection is local, so 1 second timeout is sufficient
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
 
-	conn, err := grpc.DialContext(ctx, address, grpc.WithInsecure(), grpc.WithBlock(), grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(server.MaxDaemonSendMsgSize)))
+	conn, err := grpc.DialContext(ctx, address, dialOptions...)
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
-			return nil, nil, errors.New("kashwallet daemon is not running, start it with `kashwallet start-daemon`")
+			return nil, nil, errors.New("kashwallet daemon is not running, start it with `kashwallet start-daemon`. " +
+				"If it is running, check that its TLS settings match the --daemon-* flags")
 		}
 		return nil, nil, err
 	}
@@ -29,3 +55,68 @@ func Connect(address string) (pb.KaspawalletdClient, func(), error) {
 		conn.Close()
 	}, nil
 }
+
+func securityDialOptions(security *Security) ([]grpc.DialOption, error) {
+	var dialOptions []grpc.DialOption
+
+	if security.TLSCertFile == "" {
+		dialOptions = append(dialOptions, grpc.WithInsecure())
+	} else {
+		pinnedCertificate, err := readCertificate(security.TLSCertFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig := &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			// The certificate is verified by VerifyPeerCertificate instead, so that a self-signed
+			// certificate can be used regardless of the address the daemon is reached by
+			InsecureSkipVerify: true,
+			VerifyPeerCertificate: func(rawCertificates [][]byte, _ [][]*x509.Certificate) error {
+				if len(rawCertificates) == 0 || !bytes.Equal(rawCertificates[0], pinnedCertificate) {
+					return errors.Errorf("The daemon's TLS certificate doesn't match %s", security.TLSCertFile)
+				}
+				return nil
+			},
+		}
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	if security.APITokenFile != "" {
+		tokenBytes, err := os.ReadFile(security.APITokenFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error reading the daemon's API token from %s", security.APITokenFile)
+		}
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(&apiToken{
+			token:                    strings.TrimSpace(string(tokenBytes)),
+			requireTransportSecurity: security.TLSCertFile != "",
+		}))
+	}
+
+	return dialOptions, nil
+}
+
+func readCertificate(path string) ([]byte, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error reading the daemon's TLS certificate from %s", path)
+	}
+	block, _ := pem.Decode(pemBytes)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.Errorf("%s does not contain a PEM-encoded certificate", path)
+	}
+	return block.Bytes, nil
+}
+
+// apiToken implements credentials.PerRPCCredentials
+type apiToken struct {
+	token                    string
+	requireTransportSecurity bool
+}
+
+func (t *apiToken) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{server.APITokenMetadataKey: t.token}, nil
+}
+
+func (t *apiToken) RequireTransportSecurity() bool {
+	return t.requireTransportSecurity
+}
@@ -25,10 +25,29 @@ type KaspawalletdClient interface {
 	NewAddress(ctx context.Context, in *NewAddressRequest, opts ...grpc.CallOption) (*NewAddressResponse, error)
 	Shutdown(ctx context.Context, in *ShutdownRequest, opts ...grpc.CallOption) (*ShutdownResponse, error)
 	Broadcast(ctx context.Context, in *BroadcastRequest, opts ...grpc.CallOption) (*BroadcastResponse, error)
-	// Since SendRequest contains a password - this command should only be used on a trusted or secure connection
+	// Since SendRequest contains a password - this command should only be used on a trusted or secure connection.
+	// The password may be omitted while the wallet is unlocked.
 	Send(ctx context.Context, in *SendRequest, opts ...grpc.CallOption) (*SendResponse, error)
-	// Since SignRequest contains a password - this command should only be used on a trusted or secure connection
+	// Since SignRequest contains a password - this command should only be used on a trusted or secure connection.
+	// The password may be omitted while the wallet is unlocked.
 	Sign(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*SignResponse, error)
+	// Unlock keeps the decrypted keys in the daemon's memory until the timeout elapses or Lock is called
+	Unlock(ctx context.Context, in *UnlockRequest, opts ...grpc.CallOption) (*UnlockResponse, error)
+	Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error)
+	ListUTXOs(ctx context.Context, in *ListUTXOsRequest, opts ...grpc.CallOption) (*ListUTXOsResponse, error)
+	FreezeUTXOs(ctx context.Context, in *FreezeUTXOsRequest, opts ...grpc.CallOption) (*FreezeUTXOsResponse, error)
+	UnfreezeUTXOs(ctx context.Context, in *UnfreezeUTXOsRequest, opts ...grpc.CallOption) (*UnfreezeUTXOsResponse, error)
+	CreateInvoice(ctx context.Context, in *CreateInvoiceRequest, opts ...grpc.CallOption) (*CreateInvoiceResponse, error)
+	GetInvoices(ctx context.Context, in *GetInvoicesRequest, opts ...grpc.CallOption) (*GetInvoicesResponse, error)
+	// SubscribeInvoices sends all the invoices, and then every invoice whose status or received amount changes
+	SubscribeInvoices(ctx context.Context, in *SubscribeInvoicesRequest, opts ...grpc.CallOption) (Kaspawalletd_SubscribeInvoicesClient, error)
+	ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error)
+	SetLabel(ctx context.Context, in *SetLabelRequest, opts ...grpc.CallOption) (*SetLabelResponse, error)
+	// Rescan rediscovers the used addresses of both keychains up to the gap limit, and streams its progress
+	Rescan(ctx context.Context, in *RescanRequest, opts ...grpc.CallOption) (Kaspawalletd_RescanClient, error)
+	// FindSwapSecret looks for a transaction, in the mempool or in the blocks since the pruning point, that reveals
+	// the secret of an atomic swap by redeeming a contract locked to the given secret hash
+	FindSwapSecret(ctx context.Context, in *FindSwapSecretRequest, opts ...grpc.CallOption) (*FindSwapSecretResponse, error)
 }
 
 type kashwalletdClient struct {
@@ -120,6 +139,160 @@ func (c *kashwalletdClient) Sign(ctx context.Context, in *SignRequest, opts ...g
 	return out, nil
 }
 
+func (c *kashwalletdClient) Unlock(ctx context.Context, in *UnlockRequest, opts ...grpc.CallOption) (*UnlockResponse, error) {
+	out := new(UnlockResponse)
+	err := c.cc.Invoke(ctx, "/kashwalletd.kashwalletd/Unlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kashwalletdClient) Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error) {
+	out := new(LockResponse)
+	err := c.cc.Invoke(ctx, "/kashwalletd.kashwalletd/Lock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kashwalletdClient) ListUTXOs(ctx context.Context, in *ListUTXOsRequest, opts ...grpc.CallOption) (*ListUTXOsResponse, error) {
+	out := new(ListUTXOsResponse)
+	err := c.cc.Invoke(ctx, "/kashwalletd.kashwalletd/ListUTXOs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kashwalletdClient) FreezeUTXOs(ctx context.Context, in *FreezeUTXOsRequest, opts ...grpc.CallOption) (*FreezeUTXOsResponse, error) {
+	out := new(FreezeUTXOsResponse)
+	err := c.cc.Invoke(ctx, "/kashwalletd.kashwalletd/FreezeUTXOs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kashwalletdClient) UnfreezeUTXOs(ctx context.Context, in *UnfreezeUTXOsRequest, opts ...grpc.CallOption) (*UnfreezeUTXOsResponse, error) {
+	out := new(UnfreezeUTXOsResponse)
+	err := c.cc.Invoke(ctx, "/kashwalletd.kashwalletd/UnfreezeUTXOs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kashwalletdClient) CreateInvoice(ctx context.Context, in *CreateInvoiceRequest, opts ...grpc.CallOption) (*CreateInvoiceResponse, error) {
+	out := new(CreateInvoiceResponse)
+	err := c.cc.Invoke(ctx, "/kashwalletd.kashwalletd/CreateInvoice", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kashwalletdClient) GetInvoices(ctx context.Context, in *GetInvoicesRequest, opts ...grpc.CallOption) (*GetInvoicesResponse, error) {
+	out := new(GetInvoicesResponse)
+	err := c.cc.Invoke(ctx, "/kashwalletd.kashwalletd/GetInvoices", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kashwalletdClient) SubscribeInvoices(ctx context.Context, in *SubscribeInvoicesRequest, opts ...grpc.CallOption) (Kaspawalletd_SubscribeInvoicesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Kaspawalletd_ServiceDesc.Streams[0], "/kashwalletd.kashwalletd/SubscribeInvoices", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &kashwalletdSubscribeInvoicesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Kaspawalletd_SubscribeInvoicesClient interface {
+	Recv() (*Invoice, error)
+	grpc.ClientStream
+}
+
+type kashwalletdSubscribeInvoicesClient struct {
+	grpc.ClientStream
+}
+
+func (x *kashwalletdSubscribeInvoicesClient) Recv() (*Invoice, error) {
+	m := new(Invoice)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *kashwalletdClient) ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error) {
+	out := new(ListTransactionsResponse)
+	err := c.cc.Invoke(ctx, "/kashwalletd.kashwalletd/ListTransactions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kashwalletdClient) SetLabel(ctx context.Context, in *SetLabelRequest, opts ...grpc.CallOption) (*SetLabelResponse, error) {
+	out := new(SetLabelResponse)
+	err := c.cc.Invoke(ctx, "/kashwalletd.kashwalletd/SetLabel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kashwalletdClient) Rescan(ctx context.Context, in *RescanRequest, opts ...grpc.CallOption) (Kaspawalletd_RescanClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Kaspawalletd_ServiceDesc.Streams[1], "/kashwalletd.kashwalletd/Rescan", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &kashwalletdRescanClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Kaspawalletd_RescanClient interface {
+	Recv() (*RescanProgress, error)
+	grpc.ClientStream
+}
+
+type kashwalletdRescanClient struct {
+	grpc.ClientStream
+}
+
+func (x *kashwalletdRescanClient) Recv() (*RescanProgress, error) {
+	m := new(RescanProgress)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *kashwalletdClient) FindSwapSecret(ctx context.Context, in *FindSwapSecretRequest, opts ...grpc.CallOption) (*FindSwapSecretResponse, error) {
+	out := new(FindSwapSecretResponse)
+	err := c.cc.Invoke(ctx, "/kashwalletd.kashwalletd/FindSwapSecret", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // KaspawalletdServer is the server API for Kaspawalletd service.
 // All implementations must embed UnimplementedKaspawalletdServer
 // for forward compatibility
@@ -131,10 +304,29 @@ type KaspawalletdServer interface {
 	NewAddress(context.Context, *NewAddressRequest) (*NewAddressResponse, error)
 	Shutdown(context.Context, *ShutdownRequest) (*ShutdownResponse, error)
 	Broadcast(context.Context, *BroadcastRequest) (*BroadcastResponse, error)
-	// Since SendRequest contains a password - this command should only be used on a trusted or secure connection
+	// Since SendRequest contains a password - this command should only be used on a trusted or secure connection.
+	// The password may be omitted while the wallet is unlocked.
 	Send(context.Context, *SendRequest) (*SendResponse, error)
-	// Since SignRequest contains a password - this command should only be used on a trusted or secure connection
+	// Since SignRequest contains a password - this command should only be used on a trusted or secure connection.
+	// The password may be omitted while the wallet is unlocked.
 	Sign(context.Context, *SignRequest) (*SignResponse, error)
+	// Unlock keeps the decrypted keys in the daemon's memory until the timeout elapses or Lock is called
+	Unlock(context.Context, *UnlockRequest) (*UnlockResponse, error)
+	Lock(context.Context, *LockRequest) (*LockResponse, error)
+	ListUTXOs(context.Context, *ListUTXOsRequest) (*ListUTXOsResponse, error)
+	FreezeUTXOs(context.Context, *FreezeUTXOsRequest) (*FreezeUTXOsResponse, error)
+	UnfreezeUTXOs(context.Context, *UnfreezeUTXOsRequest) (*UnfreezeUTXOsResponse, error)
+	CreateInvoice(context.Context, *CreateInvoiceRequest) (*CreateInvoiceResponse, error)
+	GetInvoices(context.Context, *GetInvoicesRequest) (*GetInvoicesResponse, error)
+	// SubscribeInvoices sends all the invoices, and then every invoice whose status or received amount changes
+	SubscribeInvoices(*SubscribeInvoicesRequest, Kaspawalletd_SubscribeInvoicesServer) error
+	ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error)
+	SetLabel(context.Context, *SetLabelRequest) (*SetLabelResponse, error)
+	// Rescan rediscovers the used addresses of both keychains up to the gap limit, and streams its progress
+	Rescan(*RescanRequest, Kaspawalletd_RescanServer) error
+	// FindSwapSecret looks for a transaction, in the mempool or in the blocks since the pruning point, that reveals
+	// the secret of an atomic swap by redeeming a contract locked to the given secret hash
+	FindSwapSecret(context.Context, *FindSwapSecretRequest) (*FindSwapSecretResponse, error)
 	mustEmbedUnimplementedKaspawalletdServer()
 }
 
@@ -169,6 +361,42 @@ func (UnimplementedKaspawalletdServer) Send(context.Context, *SendRequest) (*Sen
 func (UnimplementedKaspawalletdServer) Sign(context.Context, *SignRequest) (*SignResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Sign not implemented")
 }
+func (UnimplementedKaspawalletdServer) Unlock(context.Context, *UnlockRequest) (*UnlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unlock not implemented")
+}
+func (UnimplementedKaspawalletdServer) Lock(context.Context, *LockRequest) (*LockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Lock not implemented")
+}
+func (UnimplementedKaspawalletdServer) ListUTXOs(context.Context, *ListUTXOsRequest) (*ListUTXOsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUTXOs not implemented")
+}
+func (UnimplementedKaspawalletdServer) FreezeUTXOs(context.Context, *FreezeUTXOsRequest) (*FreezeUTXOsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FreezeUTXOs not implemented")
+}
+func (UnimplementedKaspawalletdServer) UnfreezeUTXOs(context.Context, *UnfreezeUTXOsRequest) (*UnfreezeUTXOsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnfreezeUTXOs not implemented")
+}
+func (UnimplementedKaspawalletdServer) CreateInvoice(context.Context, *CreateInvoiceRequest) (*CreateInvoiceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateInvoice not implemented")
+}
+func (UnimplementedKaspawalletdServer) GetInvoices(context.Context, *GetInvoicesRequest) (*GetInvoicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInvoices not implemented")
+}
+func (UnimplementedKaspawalletdServer) SubscribeInvoices(*SubscribeInvoicesRequest, Kaspawalletd_SubscribeInvoicesServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeInvoices not implemented")
+}
+func (UnimplementedKaspawalletdServer) ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTransactions not implemented")
+}
+func (UnimplementedKaspawalletdServer) SetLabel(context.Context, *SetLabelRequest) (*SetLabelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLabel not implemented")
+}
+func (UnimplementedKaspawalletdServer) Rescan(*RescanRequest, Kaspawalletd_RescanServer) error {
+	return status.Errorf(codes.Unimplemented, "method Rescan not implemented")
+}
+func (UnimplementedKaspawalletdServer) FindSwapSecret(context.Context, *FindSwapSecretRequest) (*FindSwapSecretResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindSwapSecret not implemented")
+}
 func (UnimplementedKaspawalletdServer) mustEmbedUnimplementedKaspawalletdServer() {}
 
 // UnsafeKaspawalletdServer may be embedded to opt out of forward compatibility for this service.
@@ -344,6 +572,228 @@ func _Kaspawalletd_Sign_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Kaspawalletd_Unlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).Unlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kashwalletd.kashwalletd/Unlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).Unlock(ctx, req.(*UnlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_Lock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).Lock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kashwalletd.kashwalletd/Lock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).Lock(ctx, req.(*LockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_ListUTXOs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUTXOsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).ListUTXOs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kashwalletd.kashwalletd/ListUTXOs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).ListUTXOs(ctx, req.(*ListUTXOsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_FreezeUTXOs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FreezeUTXOsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).FreezeUTXOs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kashwalletd.kashwalletd/FreezeUTXOs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).FreezeUTXOs(ctx, req.(*FreezeUTXOsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_UnfreezeUTXOs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnfreezeUTXOsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).UnfreezeUTXOs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kashwalletd.kashwalletd/UnfreezeUTXOs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).UnfreezeUTXOs(ctx, req.(*UnfreezeUTXOsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_CreateInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateInvoiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).CreateInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kashwalletd.kashwalletd/CreateInvoice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).CreateInvoice(ctx, req.(*CreateInvoiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_GetInvoices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInvoicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).GetInvoices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kashwalletd.kashwalletd/GetInvoices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).GetInvoices(ctx, req.(*GetInvoicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_SubscribeInvoices_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeInvoicesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KaspawalletdServer).SubscribeInvoices(m, &kashwalletdSubscribeInvoicesServer{stream})
+}
+
+type Kaspawalletd_SubscribeInvoicesServer interface {
+	Send(*Invoice) error
+	grpc.ServerStream
+}
+
+type kashwalletdSubscribeInvoicesServer struct {
+	grpc.ServerStream
+}
+
+func (x *kashwalletdSubscribeInvoicesServer) Send(m *Invoice) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Kaspawalletd_ListTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).ListTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kashwalletd.kashwalletd/ListTransactions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).ListTransactions(ctx, req.(*ListTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_SetLabel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLabelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).SetLabel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kashwalletd.kashwalletd/SetLabel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).SetLabel(ctx, req.(*SetLabelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kaspawalletd_Rescan_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RescanRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KaspawalletdServer).Rescan(m, &kashwalletdRescanServer{stream})
+}
+
+type Kaspawalletd_RescanServer interface {
+	Send(*RescanProgress) error
+	grpc.ServerStream
+}
+
+type kashwalletdRescanServer struct {
+	grpc.ServerStream
+}
+
+func (x *kashwalletdRescanServer) Send(m *RescanProgress) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Kaspawalletd_FindSwapSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindSwapSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KaspawalletdServer).FindSwapSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kashwalletd.kashwalletd/FindSwapSecret",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KaspawalletdServer).FindSwapSecret(ctx, req.(*FindSwapSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Kaspawalletd_ServiceDesc is the grpc.ServiceDesc for Kaspawalletd service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -387,7 +837,58 @@ var Kaspawalletd_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Sign",
 			Handler:    _Kaspawalletd_Sign_Handler,
 		},
+		{
+			MethodName: "Unlock",
+			Handler:    _Kaspawalletd_Unlock_Handler,
+		},
+		{
+			MethodName: "Lock",
+			Handler:    _Kaspawalletd_Lock_Handler,
+		},
+		{
+			MethodName: "ListUTXOs",
+			Handler:    _Kaspawalletd_ListUTXOs_Handler,
+		},
+		{
+			MethodName: "FreezeUTXOs",
+			Handler:    _Kaspawalletd_FreezeUTXOs_Handler,
+		},
+		{
+			MethodName: "UnfreezeUTXOs",
+			Handler:    _Kaspawalletd_UnfreezeUTXOs_Handler,
+		},
+		{
+			MethodName: "CreateInvoice",
+			Handler:    _Kaspawalletd_CreateInvoice_Handler,
+		},
+		{
+			MethodName: "GetInvoices",
+			Handler:    _Kaspawalletd_GetInvoices_Handler,
+		},
+		{
+			MethodName: "ListTransactions",
+			Handler:    _Kaspawalletd_ListTransactions_Handler,
+		},
+		{
+			MethodName: "SetLabel",
+			Handler:    _Kaspawalletd_SetLabel_Handler,
+		},
+		{
+			MethodName: "FindSwapSecret",
+			Handler:    _Kaspawalletd_FindSwapSecret_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeInvoices",
+			Handler:       _Kaspawalletd_SubscribeInvoices_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Rescan",
+			Handler:       _Kaspawalletd_Rescan_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "kashwalletd.proto",
 }
@@ -75,18 +75,28 @@ func (s *server) NewAddress(_ context.Context, request *pb.NewAddressRequest) (*
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
+	_, address, err := s.newAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.NewAddressResponse{Address: address}, nil
+}
+
+// newAddress allocates the next external address of the wallet
+func (s *server) newAddress() (*walletAddress, string, error) {
 	if !s.isSynced() {
-		return nil, errors.Errorf("wallet daemon is not synced yet, %s", s.formatSyncStateReport())
+		return nil, "", errors.Errorf("wallet daemon is not synced yet, %s", s.formatSyncStateReport())
 	}
 
 	err := s.keysFile.SetLastUsedExternalIndex(s.keysFile.LastUsedExternalIndex() + 1)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	err = s.keysFile.Save()
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	walletAddr := &walletAddress{
@@ -94,13 +104,12 @@ func (s *server) NewAddress(_ context.Context, request *pb.NewAddressRequest) (*
 		cosignerIndex: s.keysFile.CosignerIndex,
 		keyChain:      libkashwallet.ExternalKeychain,
 	}
-	path := s.walletAddressPath(walletAddr)
-	address, err := libkashwallet.Address(s.params, s.keysFile.ExtendedPublicKeys, s.keysFile.MinimumSignatures, path, s.keysFile.ECDSA)
+	address, err := s.walletAddressString(walletAddr)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return &pb.NewAddressResponse{Address: address.String()}, nil
+	return walletAddr, address, nil
 }
 
 func (s *server) walletAddressString(wAddr *walletAddress) (string, error) {
@@ -0,0 +1,43 @@
+package server
+
+import "github.com/Kash-Protocol/kashd/app/appmessage"
+
+// walkBlocks walks the blocks the node has, starting at the pruning point of dagInfo, one
+// GetBlocks page at a time. handlePage is called with the blocks of every page, including
+// their transactions, and stops the walk by returning true. Otherwise the walk stops after
+// the page that reaches one of the tips or virtual parents of dagInfo, or the last page.
+func (s *server) walkBlocks(dagInfo *appmessage.GetBlockDAGInfoResponseMessage,
+	handlePage func(blocks []*appmessage.RPCBlock) (isDone bool, err error)) error {
+
+	stopHashes := make(map[string]struct{}, len(dagInfo.TipHashes)+len(dagInfo.VirtualParentHashes))
+	for _, hash := range append(dagInfo.TipHashes, dagInfo.VirtualParentHashes...) {
+		stopHashes[hash] = struct{}{}
+	}
+
+	lowHash := dagInfo.PruningPointHash
+	for {
+		getBlocksResponse, err := s.rpcClient.GetBlocks(lowHash, true, true)
+		if err != nil {
+			return err
+		}
+
+		isDone, err := handlePage(getBlocksResponse.Blocks)
+		if err != nil {
+			return err
+		}
+		if isDone {
+			return nil
+		}
+
+		isLastPage := len(getBlocksResponse.BlockHashes) <= 1
+		for _, hash := range getBlocksResponse.BlockHashes {
+			if _, ok := stopHashes[hash]; ok {
+				isLastPage = true
+			}
+		}
+		if isLastPage {
+			return nil
+		}
+		lowHash = getBlocksResponse.BlockHashes[len(getBlocksResponse.BlockHashes)-1]
+	}
+}
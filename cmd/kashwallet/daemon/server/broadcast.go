@@ -49,15 +49,20 @@ func (s *server) broadcast(transactions [][]byte, isDomain bool) ([]string, erro
 			return nil, err
 		}
 
+		// The transaction was already sent, so failing to record it isn't reported to the caller
+		err = s.recordSentTransaction(tx)
+		if err != nil {
+			log.Errorf("Error recording transaction %s in the wallet history: %s", txIDs[i], err)
+		}
+
 		for _, input := range tx.Inputs {
 			s.usedOutpoints[input.PreviousOutpoint] = time.Now()
+			// The spent UTXOs are removed by a UTXOsChanged notification only once the transaction
+			// is accepted, so they're removed here to avoid spending them again in the meantime.
+			delete(s.utxos, input.PreviousOutpoint)
 		}
 	}
-
-	err = s.refreshUTXOs()
-	if err != nil {
-		return nil, err
-	}
+	s.sortUTXOs()
 
 	return txIDs, nil
 }
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
@@ -23,7 +24,7 @@ func (s *server) CreateUnsignedTransactions(_ context.Context, request *pb.Creat
 	defer s.lock.Unlock()
 
 	unsignedTransactions, err := s.createUnsignedTransactions(request.Address, request.Amount, request.IsSendAll,
-		request.From, request.UseExistingChangeAddress)
+		request.From, request.UseExistingChangeAddress, request.Inputs, request.SelectionStrategy)
 	if err != nil {
 		return nil, err
 	}
@@ -31,19 +32,20 @@ func (s *server) CreateUnsignedTransactions(_ context.Context, request *pb.Creat
 	return &pb.CreateUnsignedTransactionsResponse{UnsignedTransactions: unsignedTransactions}, nil
 }
 
-func (s *server) createUnsignedTransactions(address string, amount uint64, isSendAll bool, fromAddressesString []string, useExistingChangeAddress bool) ([][]byte, error) {
+func (s *server) createUnsignedTransactions(address string, amount uint64, isSendAll bool, fromAddressesString []string,
+	useExistingChangeAddress bool, inputs []*pb.Outpoint, selectionStrategy string) ([][]byte, error) {
+
 	if !s.isSynced() {
 		return nil, errors.Errorf("wallet daemon is not synced yet, %s", s.formatSyncStateReport())
 	}
-
-	// make sure address string is correct before proceeding to a
-	// potentially long UTXO refreshment operation
-	toAddress, err := util.DecodeAddress(address, s.params.Prefix)
-	if err != nil {
-		return nil, err
+	if len(inputs) > 0 && len(fromAddressesString) > 0 {
+		return nil, errors.Errorf("explicit inputs and from addresses cannot be used together")
+	}
+	if len(inputs) > 0 && selectionStrategy != "" {
+		return nil, errors.Errorf("a selection strategy cannot be used with explicit inputs")
 	}
 
-	err = s.refreshUTXOs()
+	toAddress, err := util.DecodeAddress(address, s.params.Prefix)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +59,14 @@ func (s *server) createUnsignedTransactions(address string, amount uint64, isSen
 		fromAddresses = append(fromAddresses, fromAddress)
 	}
 
-	selectedUTXOs, spendValue, changeSompi, err := s.selectUTXOs(amount, isSendAll, feePerInput, fromAddresses)
+	var selectedUTXOs []*libkashwallet.UTXO
+	var spendValue, changeSompi uint64
+	if len(inputs) > 0 {
+		selectedUTXOs, spendValue, changeSompi, err = s.selectExplicitUTXOs(inputs, amount, isSendAll, feePerInput)
+	} else {
+		selectedUTXOs, spendValue, changeSompi, err = s.selectUTXOs(amount, isSendAll, feePerInput, fromAddresses,
+			selectionStrategy)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -92,26 +101,41 @@ func (s *server) createUnsignedTransactions(address string, amount uint64, isSen
 	if err != nil {
 		return nil, err
 	}
+
+	for i, unsignedTransaction := range unsignedTransactions {
+		unsignedTransactions[i], err = libkashwallet.CreatePSKT(s.params, s.keysFile.ECDSA, unsignedTransaction)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return unsignedTransactions, nil
 }
 
-func (s *server) selectUTXOs(spendAmount uint64, isSendAll bool, feePerInput uint64, fromAddresses []*walletAddress) (
-	selectedUTXOs []*libkashwallet.UTXO, totalReceived uint64, changeSompi uint64, err error) {
+func (s *server) selectUTXOs(spendAmount uint64, isSendAll bool, feePerInput uint64, fromAddresses []*walletAddress,
+	selectionStrategy string) (selectedUTXOs []*libkashwallet.UTXO, totalReceived uint64, changeSompi uint64, err error) {
 
-	selectedUTXOs = []*libkashwallet.UTXO{}
-	totalValue := uint64(0)
+	strategy, ok := utxoSelectionStrategies[selectionStrategy]
+	if !ok {
+		return nil, 0, 0, errors.Errorf("unknown selection strategy %s, expected one of: %s",
+			selectionStrategy, strings.Join(utxoSelectionStrategyNames(), ", "))
+	}
 
 	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
 	if err != nil {
 		return nil, 0, 0, err
 	}
 
+	candidates := []*walletUTXO{}
 	for _, utxo := range s.utxosSortedByAmount {
 		if (fromAddresses != nil && !slices.Contains(fromAddresses, utxo.address)) ||
 			!isUTXOSpendable(utxo, dagInfo.VirtualDAAScore, s.params.BlockCoinbaseMaturity) {
 			continue
 		}
 
+		if _, ok := s.frozenOutpoints[*utxo.Outpoint]; ok {
+			continue
+		}
+
 		if broadcastTime, ok := s.usedOutpoints[*utxo.Outpoint]; ok {
 			if time.Since(broadcastTime) > time.Minute {
 				delete(s.usedOutpoints, *utxo.Outpoint)
@@ -120,19 +144,66 @@ func (s *server) selectUTXOs(spendAmount uint64, isSendAll bool, feePerInput uin
 			}
 		}
 
-		selectedUTXOs = append(selectedUTXOs, &libkashwallet.UTXO{
+		candidates = append(candidates, utxo)
+	}
+
+	if isSendAll {
+		return s.selectionResult(candidates, spendAmount, isSendAll, feePerInput, false)
+	}
+	selected, isChangeless := strategy(candidates, spendAmount, feePerInput)
+	return s.selectionResult(selected, spendAmount, isSendAll, feePerInput, isChangeless)
+}
+
+// selectExplicitUTXOs selects exactly the given outpoints, which must all be spendable UTXOs of the wallet
+func (s *server) selectExplicitUTXOs(inputs []*pb.Outpoint, spendAmount uint64, isSendAll bool, feePerInput uint64) (
+	selectedUTXOs []*libkashwallet.UTXO, totalReceived uint64, changeSompi uint64, err error) {
+
+	outpoints, err := s.walletOutpoints(inputs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	selected := make([]*walletUTXO, 0, len(outpoints))
+	for _, outpoint := range outpoints {
+		utxo := s.utxos[*outpoint]
+		if slices.Contains(selected, utxo) {
+			return nil, 0, 0, errors.Errorf("input %s is given more than once", formatOutpoint(outpoint))
+		}
+		if _, ok := s.frozenOutpoints[*outpoint]; ok {
+			return nil, 0, 0, errors.Errorf("input %s is frozen", formatOutpoint(outpoint))
+		}
+		if !isUTXOSpendable(utxo, dagInfo.VirtualDAAScore, s.params.BlockCoinbaseMaturity) {
+			return nil, 0, 0, errors.Errorf("input %s is an immature coinbase output", formatOutpoint(outpoint))
+		}
+		if broadcastTime, ok := s.usedOutpoints[*outpoint]; ok && time.Since(broadcastTime) <= time.Minute {
+			return nil, 0, 0, errors.Errorf("input %s is spent by a recently broadcast transaction", formatOutpoint(outpoint))
+		}
+		selected = append(selected, utxo)
+	}
+
+	return s.selectionResult(selected, spendAmount, isSendAll, feePerInput, false)
+}
+
+// selectionResult checks that the selected UTXOs cover the payment and its fee, and returns them along with
+// the amount received by the recipient and the change. If isChangeless is set, the excess, which is lower
+// than the cost of a change output, is left to the fee instead of creating a change output.
+func (s *server) selectionResult(selected []*walletUTXO, spendAmount uint64, isSendAll bool, feePerInput uint64,
+	isChangeless bool) (selectedUTXOs []*libkashwallet.UTXO, totalReceived uint64, changeSompi uint64, err error) {
+
+	selectedUTXOs = make([]*libkashwallet.UTXO, len(selected))
+	totalValue := uint64(0)
+	for i, utxo := range selected {
+		selectedUTXOs[i] = &libkashwallet.UTXO{
 			Outpoint:       utxo.Outpoint,
 			UTXOEntry:      utxo.UTXOEntry,
 			DerivationPath: s.walletAddressPath(utxo.address),
-		})
-
-		totalValue += utxo.UTXOEntry.Amount()
-
-		fee := feePerInput * uint64(len(selectedUTXOs))
-		totalSpend := spendAmount + fee
-		if !isSendAll && totalValue >= totalSpend {
-			break
 		}
+		totalValue += utxo.UTXOEntry.Amount()
 	}
 
 	fee := feePerInput * uint64(len(selectedUTXOs))
@@ -149,5 +220,8 @@ func (s *server) selectUTXOs(spendAmount uint64, isSendAll bool, feePerInput uin
 			float64(totalSpend)/constants.SompiPerKaspa, float64(totalValue)/constants.SompiPerKaspa)
 	}
 
+	if isChangeless {
+		return selectedUTXOs, totalReceived, 0, nil
+	}
 	return selectedUTXOs, totalReceived, totalValue - totalSpend, nil
 }
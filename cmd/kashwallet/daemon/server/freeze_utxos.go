@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+)
+
+// walletDataFilePath returns the path of a file where the daemon persists wallet data
+// that isn't part of the keys file, which is next to the keys file: <keys file name>.<name>.json
+func walletDataFilePath(keysFilePath string, name string) string {
+	return strings.TrimSuffix(keysFilePath, filepath.Ext(keysFilePath)) + "." + name + ".json"
+}
+
+// writeWalletDataFile replaces the content of a wallet data file. The data is written
+// to a temporary file which is then renamed over the file, so that a crash in the
+// middle of a write never leaves a truncated file behind.
+func writeWalletDataFile(path string, data []byte) error {
+	tempPath := path + ".tmp"
+	err := ioutil.WriteFile(tempPath, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tempPath, path)
+}
+
+// frozenOutpointsFilePath returns the path of the file where the frozen outpoints are persisted
+func frozenOutpointsFilePath(keysFilePath string) string {
+	return walletDataFilePath(keysFilePath, "frozen")
+}
+
+func loadFrozenOutpoints(path string) (map[externalapi.DomainOutpoint]struct{}, error) {
+	frozenOutpoints := make(map[externalapi.DomainOutpoint]struct{})
+
+	frozenOutpointsBytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return frozenOutpoints, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var outpointStrings []string
+	err = json.Unmarshal(frozenOutpointsBytes, &outpointStrings)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing the frozen outpoints file %s", path)
+	}
+	for _, outpointString := range outpointStrings {
+		outpoint, err := parseOutpoint(outpointString)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing the frozen outpoints file %s", path)
+		}
+		frozenOutpoints[*outpoint] = struct{}{}
+	}
+
+	return frozenOutpoints, nil
+}
+
+func (s *server) saveFrozenOutpoints() error {
+	outpointStrings := make([]string, 0, len(s.frozenOutpoints))
+	for outpoint := range s.frozenOutpoints {
+		outpointStrings = append(outpointStrings, formatOutpoint(&outpoint))
+	}
+
+	frozenOutpointsBytes, err := json.MarshalIndent(outpointStrings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeWalletDataFile(s.frozenOutpointsFilePath, frozenOutpointsBytes)
+}
+
+func formatOutpoint(outpoint *externalapi.DomainOutpoint) string {
+	return fmt.Sprintf("%s:%d", outpoint.TransactionID, outpoint.Index)
+}
+
+func parseOutpoint(outpointString string) (*externalapi.DomainOutpoint, error) {
+	parts := strings.Split(outpointString, ":")
+	if len(parts) != 2 {
+		return nil, errors.Errorf("outpoint %s is not of the form <transaction ID>:<index>", outpointString)
+	}
+	index, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid index in outpoint %s", outpointString)
+	}
+	return pbOutpointToDomainOutpoint(&pb.Outpoint{TransactionId: parts[0], Index: uint32(index)})
+}
+
+func pbOutpointToDomainOutpoint(outpoint *pb.Outpoint) (*externalapi.DomainOutpoint, error) {
+	transactionID, err := externalapi.NewDomainTransactionIDFromString(outpoint.TransactionId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid transaction ID %s", outpoint.TransactionId)
+	}
+	return externalapi.NewDomainOutpoint(transactionID, outpoint.Index), nil
+}
+
+func (s *server) FreezeUTXOs(_ context.Context, request *pb.FreezeUTXOsRequest) (*pb.FreezeUTXOsResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	outpoints, err := s.walletOutpoints(request.Outpoints)
+	if err != nil {
+		return nil, err
+	}
+	for _, outpoint := range outpoints {
+		s.frozenOutpoints[*outpoint] = struct{}{}
+	}
+
+	err = s.saveFrozenOutpoints()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.FreezeUTXOsResponse{}, nil
+}
+
+func (s *server) UnfreezeUTXOs(_ context.Context, request *pb.UnfreezeUTXOsRequest) (*pb.UnfreezeUTXOsResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, pbOutpoint := range request.Outpoints {
+		outpoint, err := pbOutpointToDomainOutpoint(pbOutpoint)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.frozenOutpoints[*outpoint]; !ok {
+			return nil, errors.Errorf("outpoint %s is not frozen", formatOutpoint(outpoint))
+		}
+	}
+	for _, pbOutpoint := range request.Outpoints {
+		outpoint, _ := pbOutpointToDomainOutpoint(pbOutpoint)
+		delete(s.frozenOutpoints, *outpoint)
+	}
+
+	err := s.saveFrozenOutpoints()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UnfreezeUTXOsResponse{}, nil
+}
+
+// walletOutpoints converts the given outpoints, and makes sure they're all UTXOs of the wallet
+func (s *server) walletOutpoints(pbOutpoints []*pb.Outpoint) ([]*externalapi.DomainOutpoint, error) {
+	if len(pbOutpoints) == 0 {
+		return nil, errors.New("no outpoints were given")
+	}
+
+	outpoints := make([]*externalapi.DomainOutpoint, len(pbOutpoints))
+	for i, pbOutpoint := range pbOutpoints {
+		outpoint, err := pbOutpointToDomainOutpoint(pbOutpoint)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.utxos[*outpoint]; !ok {
+			return nil, errors.Errorf("outpoint %s is not an unspent output of the wallet", formatOutpoint(outpoint))
+		}
+		outpoints[i] = outpoint
+	}
+	return outpoints, nil
+}
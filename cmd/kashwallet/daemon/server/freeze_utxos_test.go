@@ -0,0 +1,54 @@
+package server
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+)
+
+func TestFrozenOutpointsPersistence(t *testing.T) {
+	path := frozenOutpointsFilePath(filepath.Join(t.TempDir(), "keys.json"))
+	if filepath.Base(path) != "keys.frozen.json" {
+		t.Fatalf("Unexpected frozen outpoints file name %s", filepath.Base(path))
+	}
+
+	frozenOutpoints, err := loadFrozenOutpoints(path)
+	if err != nil {
+		t.Fatalf("loadFrozenOutpoints: %s", err)
+	}
+	if len(frozenOutpoints) != 0 {
+		t.Fatalf("Expected no frozen outpoints before the file is created")
+	}
+
+	outpoint := externalapi.NewDomainOutpoint(
+		externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{1, 2, 3}), 7)
+	serverInstance := &server{
+		frozenOutpoints:         map[externalapi.DomainOutpoint]struct{}{*outpoint: {}},
+		frozenOutpointsFilePath: path,
+	}
+	err = serverInstance.saveFrozenOutpoints()
+	if err != nil {
+		t.Fatalf("saveFrozenOutpoints: %s", err)
+	}
+
+	frozenOutpoints, err = loadFrozenOutpoints(path)
+	if err != nil {
+		t.Fatalf("loadFrozenOutpoints: %s", err)
+	}
+	if _, ok := frozenOutpoints[*outpoint]; !ok || len(frozenOutpoints) != 1 {
+		t.Fatalf("The frozen outpoints weren't loaded as saved: %v", frozenOutpoints)
+	}
+
+	parsedOutpoint, err := parseOutpoint(formatOutpoint(outpoint))
+	if err != nil {
+		t.Fatalf("parseOutpoint: %s", err)
+	}
+	if !parsedOutpoint.Equal(outpoint) {
+		t.Fatalf("Expected %s, but got %s", outpoint, parsedOutpoint)
+	}
+	_, err = parseOutpoint("not-an-outpoint")
+	if err == nil {
+		t.Fatalf("parseOutpoint unexpectedly parsed a malformed outpoint")
+	}
+}
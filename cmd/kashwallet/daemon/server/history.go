@@ -0,0 +1,317 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
+	"github.com/Kash-Protocol/kashd/util"
+	"github.com/pkg/errors"
+)
+
+// walletHistory is the local wallet database of the daemon: the transactions the wallet
+// broadcast, the outputs it received, and the user's labels of addresses and transactions.
+// Received outputs are recorded as they're seen in the UTXO set, so outputs that were
+// received and spent while the daemon wasn't running are missing.
+type walletHistory struct {
+	Transactions      []*historyTransaction `json:"transactions"`
+	AddressLabels     map[string]string     `json:"addressLabels"`
+	TransactionLabels map[string]string     `json:"transactionLabels"`
+
+	transactionsByID map[string]*historyTransaction
+	path             string
+	// isChanged is set when received outputs were recorded but not saved yet
+	isChanged bool
+}
+
+type historyTransaction struct {
+	TransactionID string `json:"transactionId"`
+	Timestamp     int64  `json:"timestamp"`
+	BlockDAAScore uint64 `json:"blockDaaScore,omitempty"`
+	IsSent        bool   `json:"isSent,omitempty"`
+	SentAmount    uint64 `json:"sentAmount,omitempty"`
+	Fee           uint64 `json:"fee,omitempty"`
+	IsFeeKnown    bool   `json:"isFeeKnown,omitempty"`
+	// Recipients are the outputs to addresses outside the wallet
+	Recipients []*historyOutput `json:"recipients,omitempty"`
+	// ReceivedOutputs are the outputs to the wallet by their outpoint
+	ReceivedOutputs map[string]*historyOutput `json:"receivedOutputs,omitempty"`
+}
+
+type historyOutput struct {
+	Address string `json:"address"`
+	Amount  uint64 `json:"amount"`
+}
+
+func (tx *historyTransaction) receivedAmount() uint64 {
+	receivedAmount := uint64(0)
+	for _, output := range tx.ReceivedOutputs {
+		receivedAmount += output.Amount
+	}
+	return receivedAmount
+}
+
+func historyFilePath(keysFilePath string) string {
+	return walletDataFilePath(keysFilePath, "history")
+}
+
+func loadWalletHistory(path string) (*walletHistory, error) {
+	history := &walletHistory{
+		AddressLabels:     make(map[string]string),
+		TransactionLabels: make(map[string]string),
+	}
+
+	historyBytes, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = json.Unmarshal(historyBytes, history)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing the wallet history file %s", path)
+		}
+	}
+
+	history.path = path
+	history.transactionsByID = make(map[string]*historyTransaction, len(history.Transactions))
+	for _, tx := range history.Transactions {
+		history.transactionsByID[tx.TransactionID] = tx
+	}
+	if history.AddressLabels == nil {
+		history.AddressLabels = make(map[string]string)
+	}
+	if history.TransactionLabels == nil {
+		history.TransactionLabels = make(map[string]string)
+	}
+	return history, nil
+}
+
+func (h *walletHistory) save() error {
+	historyBytes, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = writeWalletDataFile(h.path, historyBytes)
+	if err != nil {
+		return err
+	}
+	h.isChanged = false
+	return nil
+}
+
+// saveIfChanged saves the history if it has unsaved received outputs. Received
+// outputs are saved in batches, since they're recorded on every UTXO change.
+func (h *walletHistory) saveIfChanged() error {
+	if !h.isChanged {
+		return nil
+	}
+	return h.save()
+}
+
+func (h *walletHistory) transaction(transactionID string) *historyTransaction {
+	tx, ok := h.transactionsByID[transactionID]
+	if !ok {
+		tx = &historyTransaction{
+			TransactionID:   transactionID,
+			Timestamp:       time.Now().Unix(),
+			ReceivedOutputs: make(map[string]*historyOutput),
+		}
+		h.Transactions = append(h.Transactions, tx)
+		h.transactionsByID[transactionID] = tx
+	}
+	if tx.ReceivedOutputs == nil {
+		tx.ReceivedOutputs = make(map[string]*historyOutput)
+	}
+	return tx
+}
+
+// recordReceivedOutputs records the given wallet UTXOs as received outputs of their transactions.
+// They're saved by the next call to saveIfChanged.
+func (h *walletHistory) recordReceivedOutputs(entries []*appmessage.UTXOsByAddressesEntry) {
+	for _, entry := range entries {
+		outpoint := fmt.Sprintf("%s:%d", entry.Outpoint.TransactionID, entry.Outpoint.Index)
+		if tx, ok := h.transactionsByID[entry.Outpoint.TransactionID]; ok {
+			if _, ok := tx.ReceivedOutputs[outpoint]; ok && tx.BlockDAAScore != 0 {
+				continue
+			}
+		}
+
+		tx := h.transaction(entry.Outpoint.TransactionID)
+		tx.ReceivedOutputs[outpoint] = &historyOutput{Address: entry.Address, Amount: entry.UTXOEntry.Amount}
+		if tx.BlockDAAScore == 0 {
+			tx.BlockDAAScore = entry.UTXOEntry.BlockDAAScore
+		}
+		h.isChanged = true
+	}
+}
+
+func (s *server) saveHistoryIfChangedWithLock() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.history.saveIfChanged()
+}
+
+// recordSentTransaction records a transaction broadcast by the wallet. It must be
+// called before the UTXOs the transaction spends are removed from the UTXO set.
+func (s *server) recordSentTransaction(tx *externalapi.DomainTransaction) error {
+	transactionID := consensushashing.TransactionID(tx).String()
+	historyTx := s.history.transaction(transactionID)
+	historyTx.IsSent = true
+
+	sentAmount := uint64(0)
+	isFeeKnown := true
+	for _, input := range tx.Inputs {
+		utxo, ok := s.utxos[input.PreviousOutpoint]
+		if !ok {
+			// An input that isn't the wallet's, such as one spent by sweep
+			isFeeKnown = false
+			continue
+		}
+		sentAmount += utxo.UTXOEntry.Amount()
+	}
+
+	outputsAmount := uint64(0)
+	historyTx.Recipients = nil
+	for i, output := range tx.Outputs {
+		outputsAmount += output.Value
+
+		addressString := ""
+		_, address, err := txscript.ExtractScriptPubKeyAddress(output.ScriptPublicKey, s.params)
+		if err == nil && address != nil {
+			addressString = address.String()
+		}
+
+		historyOutput := &historyOutput{Address: addressString, Amount: output.Value}
+		if s.isWalletAddress(addressString) {
+			historyTx.ReceivedOutputs[fmt.Sprintf("%s:%d", transactionID, i)] = historyOutput
+		} else {
+			historyTx.Recipients = append(historyTx.Recipients, historyOutput)
+		}
+	}
+
+	historyTx.SentAmount = sentAmount
+	historyTx.IsFeeKnown = isFeeKnown && sentAmount >= outputsAmount
+	if historyTx.IsFeeKnown {
+		historyTx.Fee = sentAmount - outputsAmount
+	}
+	return s.history.save()
+}
+
+func (s *server) isWalletAddress(address string) bool {
+	if _, ok := s.addressSet[address]; ok {
+		return true
+	}
+	_, ok := s.watchedAddresses[address]
+	return ok
+}
+
+func (s *server) ListTransactions(_ context.Context, request *pb.ListTransactionsRequest) (*pb.ListTransactionsResponse, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	transactions := make([]*historyTransaction, len(s.history.Transactions))
+	copy(transactions, s.history.Transactions)
+	// Newest first. The history is in the order the transactions were seen, which breaks ties.
+	sort.SliceStable(transactions, func(i, j int) bool { return transactions[i].Timestamp > transactions[j].Timestamp })
+
+	total := uint32(len(transactions))
+	start := request.Offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if request.Limit > 0 && start+request.Limit < total {
+		end = start + request.Limit
+	}
+
+	pbTransactions := make([]*pb.WalletTransaction, 0, end-start)
+	for _, tx := range transactions[start:end] {
+		pbTransactions = append(pbTransactions, s.historyTransactionToPB(tx))
+	}
+	return &pb.ListTransactionsResponse{Transactions: pbTransactions, Total: total}, nil
+}
+
+func (s *server) historyTransactionToPB(tx *historyTransaction) *pb.WalletTransaction {
+	outputToPB := func(output *historyOutput) *pb.TransactionOutput {
+		return &pb.TransactionOutput{
+			Address: output.Address,
+			Amount:  output.Amount,
+			Label:   s.history.AddressLabels[output.Address],
+		}
+	}
+
+	recipients := make([]*pb.TransactionOutput, len(tx.Recipients))
+	for i, output := range tx.Recipients {
+		recipients[i] = outputToPB(output)
+	}
+	outpoints := make([]string, 0, len(tx.ReceivedOutputs))
+	for outpoint := range tx.ReceivedOutputs {
+		outpoints = append(outpoints, outpoint)
+	}
+	sort.Strings(outpoints)
+	receivedOutputs := make([]*pb.TransactionOutput, len(outpoints))
+	for i, outpoint := range outpoints {
+		receivedOutputs[i] = outputToPB(tx.ReceivedOutputs[outpoint])
+	}
+
+	return &pb.WalletTransaction{
+		TransactionId:   tx.TransactionID,
+		Timestamp:       tx.Timestamp,
+		BlockDaaScore:   tx.BlockDAAScore,
+		IsSent:          tx.IsSent,
+		SentAmount:      tx.SentAmount,
+		ReceivedAmount:  tx.receivedAmount(),
+		Fee:             tx.Fee,
+		IsFeeKnown:      tx.IsFeeKnown,
+		Recipients:      recipients,
+		ReceivedOutputs: receivedOutputs,
+		Label:           s.history.TransactionLabels[tx.TransactionID],
+	}
+}
+
+func (s *server) SetLabel(_ context.Context, request *pb.SetLabelRequest) (*pb.SetLabelResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if (request.Address == "") == (request.TransactionId == "") {
+		return nil, errors.New("exactly one of an address or a transaction ID must be labeled")
+	}
+
+	labels := s.history.AddressLabels
+	key := request.Address
+	if request.Address != "" {
+		_, err := util.DecodeAddress(request.Address, s.params.Prefix)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		_, err := externalapi.NewDomainTransactionIDFromString(request.TransactionId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid transaction ID %s", request.TransactionId)
+		}
+		labels = s.history.TransactionLabels
+		key = request.TransactionId
+	}
+
+	if request.Label == "" {
+		delete(labels, key)
+	} else {
+		labels[key] = request.Label
+	}
+
+	err := s.history.save()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SetLabelResponse{}, nil
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/Kash-Protocol/kashd/util"
+)
+
+func TestWalletHistory(t *testing.T) {
+	params := &dagconfig.SimnetParams
+	path := filepath.Join(t.TempDir(), "keys.history.json")
+	history, err := loadWalletHistory(path)
+	if err != nil {
+		t.Fatalf("loadWalletHistory: %s", err)
+	}
+
+	newAddress := func(seed byte) util.Address {
+		publicKey := make([]byte, 32)
+		publicKey[0] = seed
+		address, err := util.NewAddressPublicKey(publicKey, params.Prefix)
+		if err != nil {
+			t.Fatalf("NewAddressPublicKey: %s", err)
+		}
+		return address
+	}
+	walletAddress1 := newAddress(1)
+	changeAddress := newAddress(2)
+	recipientAddress := newAddress(3)
+
+	serverInstance := &server{
+		params:           params,
+		history:          history,
+		utxos:            map[externalapi.DomainOutpoint]*walletUTXO{},
+		addressSet:       walletAddressSet{walletAddress1.String(): {index: 1}},
+		watchedAddresses: walletAddressSet{changeAddress.String(): {index: 2}},
+	}
+
+	receivedTransactionID := externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{1})
+	history.recordReceivedOutputs([]*appmessage.UTXOsByAddressesEntry{{
+		Address:   walletAddress1.String(),
+		Outpoint:  &appmessage.RPCOutpoint{TransactionID: receivedTransactionID.String(), Index: 0},
+		UTXOEntry: &appmessage.RPCUTXOEntry{Amount: 1000, BlockDAAScore: 50},
+	}})
+	// Received outputs are saved in batches
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("The history was saved before saveIfChanged was called")
+	}
+	err = history.saveIfChanged()
+	if err != nil {
+		t.Fatalf("saveIfChanged: %s", err)
+	}
+	reloadedHistory, err := loadWalletHistory(path)
+	if err != nil {
+		t.Fatalf("loadWalletHistory: %s", err)
+	}
+	if len(reloadedHistory.Transactions) != 1 {
+		t.Fatalf("Expected the received transaction to be saved, but got %d transactions",
+			len(reloadedHistory.Transactions))
+	}
+
+	// Spend the received output to the recipient, with change
+	receivedOutpoint := externalapi.NewDomainOutpoint(receivedTransactionID, 0)
+	serverInstance.utxos[*receivedOutpoint] = &walletUTXO{
+		Outpoint:  receivedOutpoint,
+		UTXOEntry: utxo.NewUTXOEntry(1000, &externalapi.ScriptPublicKey{}, false, 50),
+	}
+	scriptPublicKey := func(address util.Address) *externalapi.ScriptPublicKey {
+		scriptPublicKey, err := txscript.PayToAddrScript(address)
+		if err != nil {
+			t.Fatalf("PayToAddrScript: %s", err)
+		}
+		return scriptPublicKey
+	}
+	sentTransaction := &externalapi.DomainTransaction{
+		Inputs: []*externalapi.DomainTransactionInput{{PreviousOutpoint: *receivedOutpoint}},
+		Outputs: []*externalapi.DomainTransactionOutput{
+			{Value: 600, ScriptPublicKey: scriptPublicKey(recipientAddress)},
+			{Value: 390, ScriptPublicKey: scriptPublicKey(changeAddress)},
+		},
+	}
+	err = serverInstance.recordSentTransaction(sentTransaction)
+	if err != nil {
+		t.Fatalf("recordSentTransaction: %s", err)
+	}
+
+	_, err = serverInstance.SetLabel(context.Background(), &pb.SetLabelRequest{
+		Address: recipientAddress.String(),
+		Label:   "Landlord",
+	})
+	if err != nil {
+		t.Fatalf("SetLabel: %s", err)
+	}
+	sentTransactionID := consensushashing.TransactionID(sentTransaction).String()
+	_, err = serverInstance.SetLabel(context.Background(), &pb.SetLabelRequest{
+		TransactionId: sentTransactionID,
+		Label:         "Rent",
+	})
+	if err != nil {
+		t.Fatalf("SetLabel: %s", err)
+	}
+	_, err = serverInstance.SetLabel(context.Background(), &pb.SetLabelRequest{Label: "Nothing"})
+	if err == nil {
+		t.Fatalf("SetLabel unexpectedly accepted a request without an address or a transaction ID")
+	}
+
+	// Reload the history, to make sure everything was persisted
+	serverInstance.history, err = loadWalletHistory(path)
+	if err != nil {
+		t.Fatalf("loadWalletHistory: %s", err)
+	}
+
+	response, err := serverInstance.ListTransactions(context.Background(), &pb.ListTransactionsRequest{})
+	if err != nil {
+		t.Fatalf("ListTransactions: %s", err)
+	}
+	if response.Total != 2 || len(response.Transactions) != 2 {
+		t.Fatalf("Expected 2 transactions, but got %d of %d", len(response.Transactions), response.Total)
+	}
+
+	var sent *pb.WalletTransaction
+	for _, tx := range response.Transactions {
+		if tx.TransactionId == sentTransactionID {
+			sent = tx
+		}
+	}
+	if sent == nil {
+		t.Fatalf("The sent transaction is missing from the history")
+	}
+	if !sent.IsSent || sent.SentAmount != 1000 || sent.ReceivedAmount != 390 || !sent.IsFeeKnown || sent.Fee != 10 {
+		t.Fatalf("Unexpected sent transaction %+v", sent)
+	}
+	if len(sent.Recipients) != 1 || sent.Recipients[0].Label != "Landlord" || sent.Recipients[0].Amount != 600 {
+		t.Fatalf("Unexpected recipients %+v", sent.Recipients)
+	}
+	if sent.Label != "Rent" {
+		t.Fatalf("Expected the sent transaction to be labeled Rent, but got %s", sent.Label)
+	}
+
+	page, err := serverInstance.ListTransactions(context.Background(), &pb.ListTransactionsRequest{Offset: 1, Limit: 5})
+	if err != nil {
+		t.Fatalf("ListTransactions: %s", err)
+	}
+	if page.Total != 2 || len(page.Transactions) != 1 {
+		t.Fatalf("Expected the second page to hold 1 of 2 transactions, but got %d of %d",
+			len(page.Transactions), page.Total)
+	}
+	page, err = serverInstance.ListTransactions(context.Background(), &pb.ListTransactionsRequest{Offset: 5, Limit: 5})
+	if err != nil {
+		t.Fatalf("ListTransactions: %s", err)
+	}
+	if len(page.Transactions) != 0 {
+		t.Fatalf("Expected no transactions past the end, but got %d", len(page.Transactions))
+	}
+}
@@ -0,0 +1,411 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	invoiceStatusUnpaid   = "unpaid"
+	invoiceStatusPending  = "pending"
+	invoiceStatusPaid     = "paid"
+	invoiceStatusOverpaid = "overpaid"
+	invoiceStatusExpired  = "expired"
+	invoiceStatusLate     = "late"
+)
+
+const defaultInvoiceRequiredConfirmations = 10
+
+// invoice is a payment request to a dedicated address. The payments it received are
+// recorded as they're seen, so that spending them later doesn't make the invoice unpaid.
+// ExpiresAtDAAScore estimates the DAA score at ExpiresAt, so that payments from blocks at
+// or above it are known to have arrived after the invoice expired.
+type invoice struct {
+	ID                    uint64                     `json:"id"`
+	Address               string                     `json:"address"`
+	AddressIndex          uint32                     `json:"addressIndex"`
+	CosignerIndex         uint32                     `json:"cosignerIndex"`
+	Amount                uint64                     `json:"amount"`
+	Label                 string                     `json:"label,omitempty"`
+	Message               string                     `json:"message,omitempty"`
+	CreatedAt             int64                      `json:"createdAt"`
+	ExpiresAt             int64                      `json:"expiresAt,omitempty"`
+	ExpiresAtDAAScore     uint64                     `json:"expiresAtDaaScore,omitempty"`
+	RequiredConfirmations uint64                     `json:"requiredConfirmations"`
+	Payments              map[string]*invoicePayment `json:"payments"`
+}
+
+type invoicePayment struct {
+	Amount        uint64 `json:"amount"`
+	BlockDAAScore uint64 `json:"blockDaaScore"`
+}
+
+func (inv *invoice) walletAddress() walletAddress {
+	return walletAddress{
+		index:         inv.AddressIndex,
+		cosignerIndex: inv.CosignerIndex,
+		keyChain:      libkashwallet.ExternalKeychain,
+	}
+}
+
+// isLate returns whether the given payment arrived after the invoice expired
+func (inv *invoice) isLate(payment *invoicePayment) bool {
+	return inv.ExpiresAtDAAScore != 0 && payment.BlockDAAScore >= inv.ExpiresAtDAAScore
+}
+
+// status returns the status of the invoice, the amount it received, and the
+// confirmations of its least confirmed payment, as of the given virtual DAA score.
+// An invoice that is fully paid only thanks to payments that arrived after it
+// expired is late rather than paid.
+func (inv *invoice) status(virtualDAAScore uint64, now time.Time) (status string, receivedAmount uint64, confirmations uint64) {
+	confirmedAmount := uint64(0)
+	onTimeAmount := uint64(0)
+	for _, payment := range inv.Payments {
+		paymentConfirmations := uint64(0)
+		if virtualDAAScore > payment.BlockDAAScore {
+			paymentConfirmations = virtualDAAScore - payment.BlockDAAScore
+		}
+		if receivedAmount == 0 || paymentConfirmations < confirmations {
+			confirmations = paymentConfirmations
+		}
+
+		receivedAmount += payment.Amount
+		if !inv.isLate(payment) {
+			onTimeAmount += payment.Amount
+		}
+		if paymentConfirmations >= inv.RequiredConfirmations {
+			confirmedAmount += payment.Amount
+		}
+	}
+
+	isExpired := inv.ExpiresAt != 0 && now.Unix() >= inv.ExpiresAt ||
+		inv.ExpiresAtDAAScore != 0 && virtualDAAScore >= inv.ExpiresAtDAAScore
+	switch {
+	case receivedAmount < inv.Amount && isExpired:
+		return invoiceStatusExpired, receivedAmount, confirmations
+	case onTimeAmount < inv.Amount && isExpired:
+		return invoiceStatusLate, receivedAmount, confirmations
+	case receivedAmount < inv.Amount:
+		return invoiceStatusUnpaid, receivedAmount, confirmations
+	case confirmedAmount < inv.Amount:
+		return invoiceStatusPending, receivedAmount, confirmations
+	case receivedAmount > inv.Amount:
+		return invoiceStatusOverpaid, receivedAmount, confirmations
+	default:
+		return invoiceStatusPaid, receivedAmount, confirmations
+	}
+}
+
+func invoicesFilePath(keysFilePath string) string {
+	return walletDataFilePath(keysFilePath, "invoices")
+}
+
+func loadInvoices(path string) ([]*invoice, error) {
+	invoicesBytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []*invoice{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var invoices []*invoice
+	err = json.Unmarshal(invoicesBytes, &invoices)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing the invoices file %s", path)
+	}
+	for _, inv := range invoices {
+		if inv.Payments == nil {
+			inv.Payments = make(map[string]*invoicePayment)
+		}
+	}
+	return invoices, nil
+}
+
+func (s *server) saveInvoices() error {
+	invoicesBytes, err := json.MarshalIndent(s.invoices, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeWalletDataFile(s.invoicesFilePath, invoicesBytes)
+}
+
+// recordInvoicePayments records the given wallet UTXOs that pay invoices as their
+// payments. It's called whenever UTXOs are added to the wallet, so that a payment is
+// recorded even if it's spent before the invoices are queried, and invoice subscribers
+// are notified of it.
+func (s *server) recordInvoicePayments(entries []*appmessage.UTXOsByAddressesEntry) error {
+	if len(s.invoices) == 0 {
+		return nil
+	}
+
+	invoicesByAddress := make(map[string]*invoice, len(s.invoices))
+	for _, inv := range s.invoices {
+		invoicesByAddress[inv.Address] = inv
+	}
+
+	isUpdated := false
+	for _, entry := range entries {
+		inv, ok := invoicesByAddress[entry.Address]
+		if !ok {
+			continue
+		}
+		domainOutpoint, err := appmessage.RPCOutpointToDomainOutpoint(entry.Outpoint)
+		if err != nil {
+			return err
+		}
+		outpoint := formatOutpoint(domainOutpoint)
+		if _, ok := inv.Payments[outpoint]; ok {
+			continue
+		}
+		inv.Payments[outpoint] = &invoicePayment{
+			Amount:        entry.UTXOEntry.Amount,
+			BlockDAAScore: entry.UTXOEntry.BlockDAAScore,
+		}
+		isUpdated = true
+	}
+
+	if !isUpdated {
+		return nil
+	}
+	s.notifyInvoiceSubscribers()
+	return s.saveInvoices()
+}
+
+// addInvoiceSubscriberWithLock returns a channel that is signalled whenever the status of
+// the invoices may have changed. Signals are coalesced, so a subscriber that is busy
+// gets a single signal for all the changes it missed.
+func (s *server) addInvoiceSubscriberWithLock() chan struct{} {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	subscriber := make(chan struct{}, 1)
+	s.invoiceSubscribers[subscriber] = struct{}{}
+	return subscriber
+}
+
+func (s *server) removeInvoiceSubscriberWithLock(subscriber chan struct{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.invoiceSubscribers, subscriber)
+}
+
+func (s *server) notifyInvoiceSubscribers() {
+	for subscriber := range s.invoiceSubscribers {
+		select {
+		case subscriber <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// handleVirtualDAAScoreChanged keeps the virtual DAA score up to date, since
+// the confirmations of invoice payments are counted from it
+func (s *server) handleVirtualDAAScoreChanged(notification *appmessage.VirtualDaaScoreChangedNotificationMessage) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.virtualDAAScore = notification.VirtualDaaScore
+	if len(s.invoices) > 0 {
+		s.notifyInvoiceSubscribers()
+	}
+}
+
+func (s *server) invoiceToPB(inv *invoice, virtualDAAScore uint64, now time.Time) (*pb.Invoice, error) {
+	address, err := util.DecodeAddress(inv.Address, s.params.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	paymentURI := &libkashwallet.PaymentURI{
+		Address: address,
+		Amount:  inv.Amount,
+		Label:   inv.Label,
+		Message: inv.Message,
+	}
+
+	status, receivedAmount, confirmations := inv.status(virtualDAAScore, now)
+	return &pb.Invoice{
+		Id:                    inv.ID,
+		Address:               inv.Address,
+		Amount:                inv.Amount,
+		Label:                 inv.Label,
+		Message:               inv.Message,
+		CreatedAt:             inv.CreatedAt,
+		ExpiresAt:             inv.ExpiresAt,
+		RequiredConfirmations: inv.RequiredConfirmations,
+		Uri:                   paymentURI.String(),
+		Status:                status,
+		ReceivedAmount:        receivedAmount,
+		Confirmations:         confirmations,
+	}, nil
+}
+
+func (s *server) CreateInvoice(_ context.Context, request *pb.CreateInvoiceRequest) (*pb.CreateInvoiceResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if request.Amount == 0 {
+		return nil, errors.New("the amount of an invoice must be positive")
+	}
+
+	walletAddr, address, err := s.newAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	requiredConfirmations := request.RequiredConfirmations
+	if requiredConfirmations == 0 {
+		requiredConfirmations = defaultInvoiceRequiredConfirmations
+	}
+	now := time.Now()
+	expiresAt := int64(0)
+	expiresAtDAAScore := uint64(0)
+	if request.ExpirySeconds > 0 {
+		expiry := time.Duration(request.ExpirySeconds) * time.Second
+		expiresAt = now.Add(expiry).Unix()
+		expiresAtDAAScore = s.virtualDAAScore + uint64(expiry/s.params.TargetTimePerBlock)
+	}
+
+	id := uint64(1)
+	if len(s.invoices) > 0 {
+		id = s.invoices[len(s.invoices)-1].ID + 1
+	}
+	inv := &invoice{
+		ID:                    id,
+		Address:               address,
+		AddressIndex:          walletAddr.index,
+		CosignerIndex:         walletAddr.cosignerIndex,
+		Amount:                request.Amount,
+		Label:                 request.Label,
+		Message:               request.Message,
+		CreatedAt:             now.Unix(),
+		ExpiresAt:             expiresAt,
+		ExpiresAtDAAScore:     expiresAtDAAScore,
+		RequiredConfirmations: requiredConfirmations,
+		Payments:              make(map[string]*invoicePayment),
+	}
+	s.invoices = append(s.invoices, inv)
+	err = s.saveInvoices()
+	if err != nil {
+		return nil, err
+	}
+	s.notifyInvoiceSubscribers()
+
+	pbInvoice, err := s.invoiceToPB(inv, s.virtualDAAScore, now)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateInvoiceResponse{Invoice: pbInvoice}, nil
+}
+
+func (s *server) GetInvoices(_ context.Context, _ *pb.GetInvoicesRequest) (*pb.GetInvoicesResponse, error) {
+	invoices, err := s.invoicesWithLock()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetInvoicesResponse{Invoices: invoices}, nil
+}
+
+// SubscribeInvoices sends all the invoices, and then every invoice whose status or received
+// amount changes. It wakes up when payments are recorded, when the virtual DAA score changes,
+// and when the next unpaid invoice expires.
+func (s *server) SubscribeInvoices(_ *pb.SubscribeInvoicesRequest, stream pb.Kaspawalletd_SubscribeInvoicesServer) error {
+	type invoiceState struct {
+		status         string
+		receivedAmount uint64
+	}
+	sentStates := make(map[uint64]invoiceState)
+
+	subscriber := s.addInvoiceSubscriberWithLock()
+	defer s.removeInvoiceSubscriberWithLock(subscriber)
+
+	var expiryTimer *time.Timer
+	defer func() {
+		if expiryTimer != nil {
+			expiryTimer.Stop()
+		}
+	}()
+	for {
+		invoices, err := s.invoicesWithLock()
+		if err != nil {
+			return err
+		}
+		for _, inv := range invoices {
+			state := invoiceState{status: inv.Status, receivedAmount: inv.ReceivedAmount}
+			if sentState, ok := sentStates[inv.Id]; ok && sentState == state {
+				continue
+			}
+			err := stream.Send(inv)
+			if err != nil {
+				return err
+			}
+			sentStates[inv.Id] = state
+		}
+
+		if expiryTimer != nil {
+			expiryTimer.Stop()
+		}
+		var expired <-chan time.Time
+		nextExpiry, ok := nextInvoiceExpiry(invoices, time.Now())
+		if ok {
+			expiryTimer = time.NewTimer(time.Until(nextExpiry))
+			expired = expiryTimer.C
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.shutdown:
+			return nil
+		case <-subscriber:
+		case <-expired:
+		}
+	}
+}
+
+// nextInvoiceExpiry returns the earliest time at which one of the given unpaid
+// invoices expires, if any of them is yet to expire
+func nextInvoiceExpiry(invoices []*pb.Invoice, now time.Time) (nextExpiry time.Time, ok bool) {
+	for _, inv := range invoices {
+		if inv.Status != invoiceStatusUnpaid || inv.ExpiresAt == 0 {
+			continue
+		}
+		expiry := time.Unix(inv.ExpiresAt, 0)
+		if expiry.Before(now) {
+			expiry = now
+		}
+		if !ok || expiry.Before(nextExpiry) {
+			nextExpiry, ok = expiry, true
+		}
+	}
+	return nextExpiry, ok
+}
+
+// invoicesWithLock returns all the invoices by ID
+func (s *server) invoicesWithLock() ([]*pb.Invoice, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	now := time.Now()
+	invoices := make([]*pb.Invoice, len(s.invoices))
+	for i, inv := range s.invoices {
+		var err error
+		invoices[i], err = s.invoiceToPB(inv, s.virtualDAAScore, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(invoices, func(i, j int) bool { return invoices[i].Id < invoices[j].Id })
+	return invoices, nil
+}
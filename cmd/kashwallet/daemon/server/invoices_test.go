@@ -0,0 +1,151 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+)
+
+func TestInvoiceStatus(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	newInvoice := func(expiresAt int64, payments ...*invoicePayment) *invoice {
+		inv := &invoice{
+			Amount:                100,
+			ExpiresAt:             expiresAt,
+			RequiredConfirmations: 10,
+			Payments:              make(map[string]*invoicePayment),
+		}
+		for i, payment := range payments {
+			inv.Payments[string(rune('a'+i))] = payment
+		}
+		return inv
+	}
+	newLateInvoice := func(expiresAt int64, payments ...*invoicePayment) *invoice {
+		inv := newInvoice(expiresAt, payments...)
+		inv.ExpiresAtDAAScore = 180
+		return inv
+	}
+
+	tests := []struct {
+		name                   string
+		invoice                *invoice
+		virtualDAAScore        uint64
+		expectedStatus         string
+		expectedReceivedAmount uint64
+		expectedConfirmations  uint64
+	}{
+		{
+			name:           "no payments",
+			invoice:        newInvoice(now.Unix() + 1),
+			expectedStatus: invoiceStatusUnpaid,
+		},
+		{
+			name:                   "partial payment",
+			invoice:                newInvoice(0, &invoicePayment{Amount: 60, BlockDAAScore: 100}),
+			virtualDAAScore:        200,
+			expectedStatus:         invoiceStatusUnpaid,
+			expectedReceivedAmount: 60,
+			expectedConfirmations:  100,
+		},
+		{
+			name:                   "expired",
+			invoice:                newInvoice(now.Unix(), &invoicePayment{Amount: 60, BlockDAAScore: 100}),
+			virtualDAAScore:        200,
+			expectedStatus:         invoiceStatusExpired,
+			expectedReceivedAmount: 60,
+			expectedConfirmations:  100,
+		},
+		{
+			name: "pending",
+			invoice: newInvoice(now.Unix(),
+				&invoicePayment{Amount: 60, BlockDAAScore: 100}, &invoicePayment{Amount: 40, BlockDAAScore: 195}),
+			virtualDAAScore:        200,
+			expectedStatus:         invoiceStatusPending,
+			expectedReceivedAmount: 100,
+			expectedConfirmations:  5,
+		},
+		{
+			name: "paid",
+			invoice: newInvoice(now.Unix(),
+				&invoicePayment{Amount: 60, BlockDAAScore: 100}, &invoicePayment{Amount: 40, BlockDAAScore: 190}),
+			virtualDAAScore:        200,
+			expectedStatus:         invoiceStatusPaid,
+			expectedReceivedAmount: 100,
+			expectedConfirmations:  10,
+		},
+		{
+			name: "paid late",
+			invoice: newLateInvoice(now.Unix(),
+				&invoicePayment{Amount: 60, BlockDAAScore: 100}, &invoicePayment{Amount: 40, BlockDAAScore: 180}),
+			virtualDAAScore:        200,
+			expectedStatus:         invoiceStatusLate,
+			expectedReceivedAmount: 100,
+			expectedConfirmations:  20,
+		},
+		{
+			name: "paid on time before the expiry DAA score",
+			invoice: newLateInvoice(now.Unix(),
+				&invoicePayment{Amount: 60, BlockDAAScore: 100}, &invoicePayment{Amount: 40, BlockDAAScore: 179}),
+			virtualDAAScore:        200,
+			expectedStatus:         invoiceStatusPaid,
+			expectedReceivedAmount: 100,
+			expectedConfirmations:  21,
+		},
+		{
+			name:                   "late payment before the expiry time",
+			invoice:                newLateInvoice(now.Unix()+1, &invoicePayment{Amount: 100, BlockDAAScore: 190}),
+			virtualDAAScore:        200,
+			expectedStatus:         invoiceStatusLate,
+			expectedReceivedAmount: 100,
+			expectedConfirmations:  10,
+		},
+		{
+			name:                   "expired by DAA score",
+			invoice:                newLateInvoice(now.Unix()+1, &invoicePayment{Amount: 60, BlockDAAScore: 100}),
+			virtualDAAScore:        200,
+			expectedStatus:         invoiceStatusExpired,
+			expectedReceivedAmount: 60,
+			expectedConfirmations:  100,
+		},
+		{
+			name:                   "overpaid",
+			invoice:                newInvoice(0, &invoicePayment{Amount: 150, BlockDAAScore: 100}),
+			virtualDAAScore:        200,
+			expectedStatus:         invoiceStatusOverpaid,
+			expectedReceivedAmount: 150,
+			expectedConfirmations:  100,
+		},
+	}
+
+	for _, test := range tests {
+		status, receivedAmount, confirmations := test.invoice.status(test.virtualDAAScore, now)
+		if status != test.expectedStatus || receivedAmount != test.expectedReceivedAmount ||
+			confirmations != test.expectedConfirmations {
+
+			t.Errorf("%s: expected (%s, %d, %d), but got (%s, %d, %d)", test.name, test.expectedStatus,
+				test.expectedReceivedAmount, test.expectedConfirmations, status, receivedAmount, confirmations)
+		}
+	}
+}
+
+func TestNextInvoiceExpiry(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	invoices := []*pb.Invoice{
+		{Id: 1, Status: invoiceStatusPaid, ExpiresAt: now.Unix() + 10},
+		{Id: 2, Status: invoiceStatusUnpaid},
+		{Id: 3, Status: invoiceStatusUnpaid, ExpiresAt: now.Unix() + 30},
+		{Id: 4, Status: invoiceStatusUnpaid, ExpiresAt: now.Unix() + 20},
+		{Id: 5, Status: invoiceStatusExpired, ExpiresAt: now.Unix() - 10},
+	}
+
+	nextExpiry, ok := nextInvoiceExpiry(invoices, now)
+	if !ok || nextExpiry.Unix() != now.Unix()+20 {
+		t.Fatalf("Unexpected next expiry (%s, %t). Want: %s", nextExpiry, ok, time.Unix(now.Unix()+20, 0))
+	}
+
+	_, ok = nextInvoiceExpiry(invoices[:2], now)
+	if ok {
+		t.Fatalf("Got a next expiry even though no unpaid invoice expires")
+	}
+}
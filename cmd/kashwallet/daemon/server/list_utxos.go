@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/pkg/errors"
+)
+
+func (s *server) ListUTXOs(_ context.Context, _ *pb.ListUTXOsRequest) (*pb.ListUTXOsResponse, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if !s.isSynced() {
+		return nil, errors.Errorf("wallet daemon is not synced yet, %s", s.formatSyncStateReport())
+	}
+
+	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	utxos := make([]*pb.WalletUtxo, len(s.utxosSortedByAmount))
+	for i, utxo := range s.utxosSortedByAmount {
+		address, err := s.walletAddressString(utxo.address)
+		if err != nil {
+			return nil, err
+		}
+		_, isFrozen := s.frozenOutpoints[*utxo.Outpoint]
+		_, isUsed := s.usedOutpoints[*utxo.Outpoint]
+
+		utxos[i] = &pb.WalletUtxo{
+			Outpoint: &pb.Outpoint{
+				TransactionId: utxo.Outpoint.TransactionID.String(),
+				Index:         utxo.Outpoint.Index,
+			},
+			Address: address,
+			UtxoEntry: &pb.UtxoEntry{
+				Amount: utxo.UTXOEntry.Amount(),
+				ScriptPublicKey: &pb.ScriptPublicKey{
+					Version:         uint32(utxo.UTXOEntry.ScriptPublicKey().Version),
+					ScriptPublicKey: hex.EncodeToString(utxo.UTXOEntry.ScriptPublicKey().Script),
+				},
+				BlockDaaScore: utxo.UTXOEntry.BlockDAAScore(),
+				IsCoinbase:    utxo.UTXOEntry.IsCoinbase(),
+			},
+			IsSpendable: !isUsed && isUTXOSpendable(utxo, dagInfo.VirtualDAAScore, s.params.BlockCoinbaseMaturity),
+			IsFrozen:    isFrozen,
+		}
+	}
+
+	return &pb.ListUTXOsResponse{Utxos: utxos}, nil
+}
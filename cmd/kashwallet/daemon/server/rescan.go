@@ -0,0 +1,200 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/pkg/errors"
+)
+
+const (
+	rescanStageBlocks    = "blocks"
+	rescanStageAddresses = "addresses"
+	rescanStageDone      = "done"
+)
+
+// Rescan rediscovers the used addresses. An address is considered used if it has a balance, or
+// if it received funds in a block since the wallet birthday that the node still has. The address
+// indexes of both keychains are scanned until the gap limit is reached past the last used index.
+func (s *server) Rescan(request *pb.RescanRequest, stream pb.Kaspawalletd_RescanServer) error {
+	gapLimit := request.GapLimit
+	if gapLimit == 0 {
+		gapLimit = s.gapLimit
+	}
+
+	s.lock.Lock()
+	if request.BirthdayDaaScore != 0 {
+		err := s.keysFile.SetBirthdayDAAScore(request.BirthdayDaaScore)
+		if err != nil {
+			s.lock.Unlock()
+			return err
+		}
+	}
+	birthdayDAAScore := s.keysFile.BirthdayDAAScore
+	s.lock.Unlock()
+
+	log.Infof("Rescanning with a gap limit of %d and a wallet birthday at DAA score %d", gapLimit, birthdayDAAScore)
+	receivingAddresses, err := s.collectReceivingAddressesSince(birthdayDAAScore, stream)
+	if err != nil {
+		return err
+	}
+
+	isUsed := func(addresses walletAddressSet) (walletAddressSet, error) {
+		getBalancesByAddressesResponse, err := s.rpcClient.GetBalancesByAddresses(addresses.strings())
+		if err != nil {
+			return nil, err
+		}
+		usedAddresses := make(walletAddressSet)
+		for _, entry := range getBalancesByAddressesResponse.Entries {
+			if entry.Balance > 0 {
+				usedAddresses[entry.Address] = addresses[entry.Address]
+			}
+		}
+		for addressString, address := range addresses {
+			if _, ok := receivingAddresses[addressString]; ok {
+				usedAddresses[addressString] = address
+			}
+		}
+		return usedAddresses, nil
+	}
+	reportProgress := func(scanned, total uint32) error {
+		log.Infof("Rescan: scanned %d out of %d address indexes", scanned, total)
+		return stream.Send(&pb.RescanProgress{
+			Stage:     rescanStageAddresses,
+			Processed: uint64(scanned),
+			Total:     uint64(total),
+		})
+	}
+	usedAddresses, err := s.discoverUsedAddresses(gapLimit, isUsed, reportProgress)
+	if err != nil {
+		return err
+	}
+
+	err = s.markAddressesUsedWithLock(usedAddresses)
+	if err != nil {
+		return err
+	}
+	err = s.watchNewAddressesWithLock()
+	if err != nil {
+		return err
+	}
+	err = s.refreshExistingUTXOsWithLock()
+	if err != nil {
+		return err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	log.Infof("Rescan done: found %d used addresses, the last used external index is %d and the last used "+
+		"internal index is %d", len(usedAddresses), s.keysFile.LastUsedExternalIndex(), s.keysFile.LastUsedInternalIndex())
+	return stream.Send(&pb.RescanProgress{
+		Stage:                 rescanStageDone,
+		UsedAddresses:         uint32(len(usedAddresses)),
+		LastUsedExternalIndex: s.keysFile.LastUsedExternalIndex(),
+		LastUsedInternalIndex: s.keysFile.LastUsedInternalIndex(),
+	})
+}
+
+// collectReceivingAddressesSince walks the blocks the node has, starting at the pruning point, and
+// returns the addresses that received outputs in blocks whose DAA score isn't below the birthday.
+// Outputs that were both created and spent below the pruning point can't be found this way.
+func (s *server) collectReceivingAddressesSince(birthdayDAAScore uint64, stream pb.Kaspawalletd_RescanServer) (
+	map[string]struct{}, error) {
+
+	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	receivingAddresses := make(map[string]struct{})
+	err = s.walkBlocks(dagInfo, func(blocks []*appmessage.RPCBlock) (bool, error) {
+		var processedDAAScore uint64
+		for _, block := range blocks {
+			if block.Header.DAAScore > processedDAAScore {
+				processedDAAScore = block.Header.DAAScore
+			}
+			if block.VerboseData != nil && block.VerboseData.Hash == dagInfo.PruningPointHash &&
+				block.Header.DAAScore > birthdayDAAScore {
+
+				message := fmt.Sprintf("The wallet birthday (DAA score %d) is before the pruning point (DAA score %d): "+
+					"addresses whose funds were spent before the pruning point are only found within the gap limit",
+					birthdayDAAScore, block.Header.DAAScore)
+				log.Warnf("%s", message)
+				err := stream.Send(&pb.RescanProgress{Stage: rescanStageBlocks, Message: message})
+				if err != nil {
+					return false, err
+				}
+			}
+			if block.Header.DAAScore < birthdayDAAScore {
+				continue
+			}
+			for _, transaction := range block.Transactions {
+				for _, output := range transaction.Outputs {
+					if output.VerboseData != nil {
+						receivingAddresses[output.VerboseData.ScriptPublicKeyAddress] = struct{}{}
+					}
+				}
+			}
+		}
+
+		log.Infof("Rescan: processed blocks up to DAA score %d out of %d", processedDAAScore, dagInfo.VirtualDAAScore)
+		return false, stream.Send(&pb.RescanProgress{
+			Stage:     rescanStageBlocks,
+			Processed: processedDAAScore,
+			Total:     dagInfo.VirtualDAAScore,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return receivingAddresses, nil
+}
+
+// discoverUsedAddresses scans the address indexes from zero, and returns the used addresses among them,
+// as determined by isUsed. The scan stops once gapLimit consecutive indexes after the last used index of
+// either keychain are unused.
+func (s *server) discoverUsedAddresses(gapLimit uint32,
+	isUsed func(addresses walletAddressSet) (walletAddressSet, error),
+	reportProgress func(scanned, total uint32) error) (walletAddressSet, error) {
+
+	if gapLimit == 0 {
+		return nil, errors.New("The gap limit must be positive")
+	}
+
+	usedAddresses := make(walletAddressSet)
+	scanUntilIndex := gapLimit
+	for start := uint32(0); start < scanUntilIndex; {
+		end := start + gapLimit
+		if end > scanUntilIndex {
+			end = scanUntilIndex
+		}
+
+		s.lock.RLock()
+		addresses, err := s.addressesToQuery(start, end)
+		s.lock.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+		batchUsedAddresses, err := isUsed(addresses)
+		if err != nil {
+			return nil, err
+		}
+		for addressString, address := range batchUsedAddresses {
+			usedAddresses[addressString] = address
+			if address.index+1+gapLimit > scanUntilIndex {
+				scanUntilIndex = address.index + 1 + gapLimit
+			}
+		}
+
+		start = end
+		err = reportProgress(start, scanUntilIndex)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return usedAddresses, nil
+}
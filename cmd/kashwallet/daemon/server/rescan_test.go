@@ -0,0 +1,113 @@
+package server
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/keys"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+)
+
+func TestDiscoverUsedAddresses(t *testing.T) {
+	params := &dagconfig.SimnetParams
+
+	mnemonic, err := libkashwallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %s", err)
+	}
+	extendedPublicKey, err := libkashwallet.MasterPublicKeyFromMnemonic(params, mnemonic, false)
+	if err != nil {
+		t.Fatalf("MasterPublicKeyFromMnemonic: %s", err)
+	}
+	keysFile := &keys.File{
+		ExtendedPublicKeys: []string{extendedPublicKey},
+		MinimumSignatures:  1,
+	}
+	err = keysFile.SetPath(params, filepath.Join(t.TempDir(), "keys.json"), true)
+	if err != nil {
+		t.Fatalf("SetPath: %s", err)
+	}
+	serverInstance := &server{
+		params:   params,
+		keysFile: keysFile,
+	}
+
+	const gapLimit = 10
+	tests := []struct {
+		name                   string
+		usedExternalIndexes    []uint32
+		usedInternalIndexes    []uint32
+		expectedUsedAddresses  int
+		expectedScannedIndexes uint32
+	}{
+		{
+			name:                   "no used addresses",
+			expectedUsedAddresses:  0,
+			expectedScannedIndexes: gapLimit,
+		},
+		{
+			name:                   "within the gap limit",
+			usedExternalIndexes:    []uint32{3, 12},
+			usedInternalIndexes:    []uint32{20},
+			expectedUsedAddresses:  3,
+			expectedScannedIndexes: 31,
+		},
+		{
+			name:                   "beyond the gap limit",
+			usedExternalIndexes:    []uint32{3, 14},
+			expectedUsedAddresses:  1,
+			expectedScannedIndexes: 14,
+		},
+	}
+	for _, test := range tests {
+		used := make(map[string]struct{})
+		for _, index := range test.usedExternalIndexes {
+			addressString, err := serverInstance.walletAddressString(
+				&walletAddress{index: index, keyChain: libkashwallet.ExternalKeychain})
+			if err != nil {
+				t.Fatalf("walletAddressString: %s", err)
+			}
+			used[addressString] = struct{}{}
+		}
+		for _, index := range test.usedInternalIndexes {
+			addressString, err := serverInstance.walletAddressString(
+				&walletAddress{index: index, keyChain: libkashwallet.InternalKeychain})
+			if err != nil {
+				t.Fatalf("walletAddressString: %s", err)
+			}
+			used[addressString] = struct{}{}
+		}
+
+		isUsed := func(addresses walletAddressSet) (walletAddressSet, error) {
+			usedAddresses := make(walletAddressSet)
+			for addressString, address := range addresses {
+				if _, ok := used[addressString]; ok {
+					usedAddresses[addressString] = address
+				}
+			}
+			return usedAddresses, nil
+		}
+		var scannedIndexes uint32
+		reportProgress := func(scanned, total uint32) error {
+			scannedIndexes = scanned
+			return nil
+		}
+
+		usedAddresses, err := serverInstance.discoverUsedAddresses(gapLimit, isUsed, reportProgress)
+		if err != nil {
+			t.Fatalf("%s: discoverUsedAddresses: %s", test.name, err)
+		}
+		if len(usedAddresses) != test.expectedUsedAddresses {
+			t.Fatalf("%s: expected %d used addresses but got %d", test.name, test.expectedUsedAddresses, len(usedAddresses))
+		}
+		if scannedIndexes != test.expectedScannedIndexes {
+			t.Fatalf("%s: expected %d scanned indexes but got %d", test.name, test.expectedScannedIndexes, scannedIndexes)
+		}
+	}
+
+	_, err = serverInstance.discoverUsedAddresses(0, nil, nil)
+	if err == nil {
+		t.Fatalf("Expected an error for a zero gap limit")
+	}
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// APITokenMetadataKey is the gRPC metadata key under which clients send the API token
+const APITokenMetadataKey = "authorization"
+
+const (
+	apiTokenSize           = 32
+	tlsCertificateValidity = 10 * 365 * 24 * time.Hour
+)
+
+// SecurityConfig defines how the daemon's API is protected
+type SecurityConfig struct {
+	// DisableTLS makes the daemon listen without TLS. Passwords sent to Send, Sign and Unlock are then unencrypted
+	DisableTLS bool
+	// TLSCertFile and TLSKeyFile default to files next to the keys file, which are created if they don't exist
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// DisableAuth makes the daemon accept calls without an API token
+	DisableAuth bool
+	// APITokenFile defaults to a file next to the keys file, which is created if it doesn't exist
+	APITokenFile string
+}
+
+func daemonFilePath(keysFilePath string, name string) string {
+	return strings.TrimSuffix(keysFilePath, filepath.Ext(keysFilePath)) + "." + name
+}
+
+// TLSCertFilePath returns the default path of the daemon's TLS certificate for the given keys file
+func TLSCertFilePath(keysFilePath string) string {
+	return daemonFilePath(keysFilePath, "daemon.cert")
+}
+
+// TLSKeyFilePath returns the default path of the daemon's TLS key for the given keys file
+func TLSKeyFilePath(keysFilePath string) string {
+	return daemonFilePath(keysFilePath, "daemon.key")
+}
+
+// APITokenFilePath returns the default path of the daemon's API token for the given keys file
+func APITokenFilePath(keysFilePath string) string {
+	return daemonFilePath(keysFilePath, "daemon.token")
+}
+
+// securityServerOptions returns the gRPC server options that apply the given security config.
+// Missing TLS certificates and API tokens are created next to the keys file.
+func securityServerOptions(security *SecurityConfig, keysFilePath string, listen string) ([]grpc.ServerOption, error) {
+	var options []grpc.ServerOption
+
+	if security.DisableTLS {
+		log.Warnf("TLS is disabled. The daemon API, including wallet passwords, is sent in plaintext")
+	} else {
+		certFile, keyFile := security.TLSCertFile, security.TLSKeyFile
+		if certFile == "" {
+			certFile = TLSCertFilePath(keysFilePath)
+		}
+		if keyFile == "" {
+			keyFile = TLSKeyFilePath(keysFilePath)
+		}
+		certificate, err := loadOrCreateTLSCertificate(certFile, keyFile, listen)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpc.Creds(credentials.NewServerTLSFromCert(certificate)))
+		log.Infof("Serving TLS with the certificate in %s", certFile)
+	}
+
+	if security.DisableAuth {
+		log.Warnf("API token authentication is disabled. Anyone who can reach the daemon can use it")
+	} else {
+		tokenFile := security.APITokenFile
+		if tokenFile == "" {
+			tokenFile = APITokenFilePath(keysFilePath)
+		}
+		token, err := loadOrCreateAPIToken(tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options,
+			grpc.UnaryInterceptor(func(ctx context.Context, request interface{}, _ *grpc.UnaryServerInfo,
+				handler grpc.UnaryHandler) (interface{}, error) {
+
+				err := authenticate(ctx, token)
+				if err != nil {
+					return nil, err
+				}
+				return handler(ctx, request)
+			}),
+			grpc.StreamInterceptor(func(server interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo,
+				handler grpc.StreamHandler) error {
+
+				err := authenticate(stream.Context(), token)
+				if err != nil {
+					return err
+				}
+				return handler(server, stream)
+			}))
+		log.Infof("Requiring the API token in %s", tokenFile)
+	}
+
+	return options, nil
+}
+
+func authenticate(ctx context.Context, token string) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if ok {
+		for _, value := range md.Get(APITokenMetadataKey) {
+			if subtle.ConstantTimeCompare([]byte(value), []byte(token)) == 1 {
+				return nil
+			}
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid API token")
+}
+
+// loadOrCreateAPIToken reads the API token from the given file, creating a random one if the file doesn't exist
+func loadOrCreateAPIToken(path string) (string, error) {
+	tokenBytes, err := os.ReadFile(path)
+	if err == nil {
+		token := strings.TrimSpace(string(tokenBytes))
+		if token == "" {
+			return "", errors.Errorf("The API token file %s is empty", path)
+		}
+		return token, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", errors.Wrapf(err, "Error reading the API token from %s", path)
+	}
+
+	randomBytes := make([]byte, apiTokenSize)
+	_, err = rand.Read(randomBytes)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	token := hex.EncodeToString(randomBytes)
+	err = os.WriteFile(path, []byte(token+"\n"), 0600)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error writing the API token to %s", path)
+	}
+	log.Infof("Created a new API token in %s", path)
+
+	return token, nil
+}
+
+// loadOrCreateTLSCertificate loads the given certificate and key, creating a self-signed pair
+// if neither of them exists
+func loadOrCreateTLSCertificate(certFile, keyFile string, listen string) (*tls.Certificate, error) {
+	_, certErr := os.Stat(certFile)
+	_, keyErr := os.Stat(keyFile)
+	if os.IsNotExist(certErr) && os.IsNotExist(keyErr) {
+		err := createTLSCertificate(certFile, keyFile, listen)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("Created a new self-signed TLS certificate in %s", certFile)
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error loading the TLS certificate %s and key %s", certFile, keyFile)
+	}
+	return &certificate, nil
+}
+
+func createTLSCertificate(certFile, keyFile string, listen string) error {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{Organization: []string{"kashwallet daemon"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(tlsCertificateValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	hosts := []string{}
+	if hostname, err := os.Hostname(); err == nil {
+		hosts = append(hosts, hostname)
+	}
+	if host, _, err := net.SplitHostPort(listen); err == nil && host != "" {
+		hosts = append(hosts, host)
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			if !ip.IsUnspecified() {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+		} else if host != "localhost" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600)
+	if err != nil {
+		return errors.Wrapf(err, "Error writing the TLS key to %s", keyFile)
+	}
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0644)
+	if err != nil {
+		return errors.Wrapf(err, "Error writing the TLS certificate to %s", certFile)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/keys"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAPIToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "keys.daemon.token")
+
+	token, err := loadOrCreateAPIToken(tokenFile)
+	if err != nil {
+		t.Fatalf("loadOrCreateAPIToken: %+v", err)
+	}
+	if len(token) != apiTokenSize*2 {
+		t.Fatalf("Unexpected token length %d", len(token))
+	}
+	info, err := os.Stat(tokenFile)
+	if err != nil {
+		t.Fatalf("Stat: %+v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("Unexpected token file permissions %s", info.Mode().Perm())
+	}
+
+	reloadedToken, err := loadOrCreateAPIToken(tokenFile)
+	if err != nil {
+		t.Fatalf("loadOrCreateAPIToken: %+v", err)
+	}
+	if reloadedToken != token {
+		t.Fatalf("The token was not reused: %s != %s", reloadedToken, token)
+	}
+
+	tests := []struct {
+		name          string
+		md            metadata.MD
+		expectedError bool
+	}{
+		{name: "no metadata", md: nil, expectedError: true},
+		{name: "wrong token", md: metadata.Pairs(APITokenMetadataKey, "wrong"), expectedError: true},
+		{name: "valid token", md: metadata.Pairs(APITokenMetadataKey, token), expectedError: false},
+	}
+	for _, test := range tests {
+		ctx := context.Background()
+		if test.md != nil {
+			ctx = metadata.NewIncomingContext(ctx, test.md)
+		}
+		err := authenticate(ctx, token)
+		if !test.expectedError {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %+v", test.name, err)
+			}
+			continue
+		}
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("%s: expected an Unauthenticated error but got %v", test.name, err)
+		}
+	}
+}
+
+func TestTLSCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile := TLSCertFilePath(filepath.Join(dir, "keys.json"))
+	keyFile := TLSKeyFilePath(filepath.Join(dir, "keys.json"))
+	if filepath.Base(certFile) != "keys.daemon.cert" || filepath.Base(keyFile) != "keys.daemon.key" {
+		t.Fatalf("Unexpected paths %s and %s", certFile, keyFile)
+	}
+
+	certificate, err := loadOrCreateTLSCertificate(certFile, keyFile, "192.0.2.1:8082")
+	if err != nil {
+		t.Fatalf("loadOrCreateTLSCertificate: %+v", err)
+	}
+	parsedCertificate, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		t.Fatalf("ParseCertificate: %+v", err)
+	}
+	for _, host := range []string{"localhost", "127.0.0.1", "192.0.2.1"} {
+		err := parsedCertificate.VerifyHostname(host)
+		if err != nil {
+			t.Fatalf("The certificate isn't valid for %s: %+v", host, err)
+		}
+	}
+
+	reloadedCertificate, err := loadOrCreateTLSCertificate(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("loadOrCreateTLSCertificate: %+v", err)
+	}
+	if string(reloadedCertificate.Certificate[0]) != string(certificate.Certificate[0]) {
+		t.Fatalf("The certificate was not reused")
+	}
+
+	err = os.Remove(keyFile)
+	if err != nil {
+		t.Fatalf("Remove: %+v", err)
+	}
+	_, err = loadOrCreateTLSCertificate(certFile, keyFile, "")
+	if err == nil {
+		t.Fatalf("Expected an error when the TLS key is missing")
+	}
+}
+
+func TestUnlockedMnemonics(t *testing.T) {
+	const password = "password"
+	encryptedMnemonics, _, err := keys.CreateMnemonics(&dagconfig.SimnetParams, 1, password, false)
+	if err != nil {
+		t.Fatalf("CreateMnemonics: %+v", err)
+	}
+	s := &server{keysFile: &keys.File{Version: keys.LastVersion, EncryptedMnemonics: encryptedMnemonics}}
+
+	_, err = s.mnemonics("")
+	if err == nil {
+		t.Fatalf("Expected an error when the wallet is locked")
+	}
+	decryptedMnemonics, err := s.mnemonics(password)
+	if err != nil {
+		t.Fatalf("mnemonics: %+v", err)
+	}
+
+	s.unlockedMnemonics = []string{"mnemonic"}
+	s.unlockedUntil = time.Now().Add(time.Minute)
+	mnemonics, err := s.mnemonics("")
+	if err != nil {
+		t.Fatalf("mnemonics: %+v", err)
+	}
+	if len(mnemonics) != 1 || mnemonics[0] != "mnemonic" {
+		t.Fatalf("Unexpected mnemonics %v", mnemonics)
+	}
+
+	// A password is used even when the wallet is unlocked
+	mnemonics, err = s.mnemonics(password)
+	if err != nil {
+		t.Fatalf("mnemonics: %+v", err)
+	}
+	if len(mnemonics) != 1 || mnemonics[0] != decryptedMnemonics[0] {
+		t.Fatalf("Unexpected mnemonics %v", mnemonics)
+	}
+
+	s.unlockedUntil = time.Now().Add(-time.Second)
+	_, err = s.mnemonics("")
+	if err == nil {
+		t.Fatalf("Expected an error after the unlock timeout elapsed")
+	}
+
+	s.unlockedUntil = time.Now().Add(time.Minute)
+	s.lockWallet()
+	_, err = s.mnemonics("")
+	if err == nil {
+		t.Fatalf("Expected an error after the wallet was locked")
+	}
+
+	// A wallet without mnemonics to decrypt needs no password even when it's locked
+	s.keysFile = &keys.File{}
+	_, err = s.mnemonics("")
+	if err != nil {
+		t.Fatalf("mnemonics: %+v", err)
+	}
+}
@@ -4,14 +4,21 @@ import (
 	"context"
 
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/keys"
 )
 
 func (s *server) Send(_ context.Context, request *pb.SendRequest) (*pb.SendResponse, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
+	// Fail before creating the transactions, so that their inputs aren't
+	// marked as used for nothing
+	if s.keysFile.IsWatchOnly() {
+		return nil, keys.ErrWatchOnly
+	}
+
 	unsignedTransactions, err := s.createUnsignedTransactions(request.ToAddress, request.Amount, request.IsSendAll,
-		request.From, request.UseExistingChangeAddress)
+		request.From, request.UseExistingChangeAddress, request.Inputs, request.SelectionStrategy)
 
 	if err != nil {
 		return nil, err
@@ -31,14 +31,39 @@ type server struct {
 	params    *dagconfig.Params
 
 	lock                sync.RWMutex
+	utxos               map[externalapi.DomainOutpoint]*walletUTXO
 	utxosSortedByAmount []*walletUTXO
 	nextSyncStartIndex  uint32
+	watchedAddresses    walletAddressSet
+	watchedUntilIndex   uint32
+	gapLimit            uint32
 	keysFile            *keys.File
 	shutdown            chan struct{}
 	addressSet          walletAddressSet
 	txMassCalculator    *txmass.Calculator
 	usedOutpoints       map[externalapi.DomainOutpoint]time.Time
 
+	// utxoSetVersion is incremented on every change to utxos, to detect
+	// changes made while the lock was released
+	utxoSetVersion  uint64
+	resubscribeLock sync.Mutex
+
+	frozenOutpoints         map[externalapi.DomainOutpoint]struct{}
+	frozenOutpointsFilePath string
+
+	invoices           []*invoice
+	invoicesFilePath   string
+	invoiceSubscribers map[chan struct{}]struct{}
+
+	// virtualDAAScore is kept up to date by VirtualDaaScoreChanged notifications
+	virtualDAAScore uint64
+
+	history *walletHistory
+
+	unlockedMnemonics []string
+	unlockedUntil     time.Time
+	lockTimer         *time.Timer
+
 	isLogFinalProgressLineShown bool
 	maxUsedAddressesForLog      uint32
 	maxProcessedAddressesForLog uint32
@@ -49,7 +74,9 @@ type server struct {
 const MaxDaemonSendMsgSize = 100_000_000
 
 // Start starts the kashwalletd server
-func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath string, profile string, timeout uint32) error {
+func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath string, profile string, timeout uint32,
+	gapLimit uint32, security *SecurityConfig) error {
+
 	initLog(defaultLogFile, defaultErrLogFile)
 
 	defer panics.HandlePanic(log, "MAIN", nil)
@@ -82,16 +109,47 @@ func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath stri
 		return err
 	}
 
+	frozenOutpointsFilePath := frozenOutpointsFilePath(keysFile.Path())
+	frozenOutpoints, err := loadFrozenOutpoints(frozenOutpointsFilePath)
+	if err != nil {
+		return err
+	}
+
+	invoicesFilePath := invoicesFilePath(keysFile.Path())
+	invoices, err := loadInvoices(invoicesFilePath)
+	if err != nil {
+		return err
+	}
+
+	history, err := loadWalletHistory(historyFilePath(keysFile.Path()))
+	if err != nil {
+		return err
+	}
+
+	securityOptions, err := securityServerOptions(security, keysFile.Path(), listen)
+	if err != nil {
+		return err
+	}
+
 	serverInstance := &server{
 		rpcClient:                   rpcClient,
 		params:                      params,
+		utxos:                       map[externalapi.DomainOutpoint]*walletUTXO{},
 		utxosSortedByAmount:         []*walletUTXO{},
 		nextSyncStartIndex:          0,
+		watchedAddresses:            make(walletAddressSet),
+		gapLimit:                    gapLimit,
 		keysFile:                    keysFile,
 		shutdown:                    make(chan struct{}),
 		addressSet:                  make(walletAddressSet),
-		txMassCalculator:            txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp),
+		txMassCalculator:            txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp, params.MassPerSpliceOp),
 		usedOutpoints:               map[externalapi.DomainOutpoint]time.Time{},
+		frozenOutpoints:             frozenOutpoints,
+		frozenOutpointsFilePath:     frozenOutpointsFilePath,
+		invoices:                    invoices,
+		invoicesFilePath:            invoicesFilePath,
+		invoiceSubscribers:          make(map[chan struct{}]struct{}),
+		history:                     history,
 		isLogFinalProgressLineShown: false,
 		maxUsedAddressesForLog:      0,
 		maxProcessedAddressesForLog: 0,
@@ -105,7 +163,7 @@ func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath stri
 		}
 	})
 
-	grpcServer := grpc.NewServer(grpc.MaxSendMsgSize(MaxDaemonSendMsgSize))
+	grpcServer := grpc.NewServer(append(securityOptions, grpc.MaxSendMsgSize(MaxDaemonSendMsgSize))...)
 	pb.RegisterKaspawalletdServer(grpcServer, serverInstance)
 
 	spawn("grpcServer.Serve", func() {
@@ -134,6 +192,11 @@ func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath stri
 		}
 	}
 
+	err = serverInstance.saveHistoryIfChangedWithLock()
+	if err != nil {
+		log.Errorf("Error saving the wallet history: %s", err)
+	}
+
 	return nil
 }
 
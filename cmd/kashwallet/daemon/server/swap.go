@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/pkg/errors"
+)
+
+// FindSwapSecret looks for the secret of an atomic swap in the signature scripts of the transactions in the
+// mempool, and then of the transactions in the blocks the node has, starting at the pruning point.
+func (s *server) FindSwapSecret(_ context.Context, request *pb.FindSwapSecretRequest) (*pb.FindSwapSecretResponse, error) {
+	if len(request.SecretHash) != sha256.Size {
+		return nil, errors.Errorf("The secret hash must be %d bytes long", sha256.Size)
+	}
+
+	getMempoolEntriesResponse, err := s.rpcClient.GetMempoolEntries(false, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range getMempoolEntriesResponse.Entries {
+		secret, transactionID := findSwapSecretInTransaction(entry.Transaction, request.SecretHash)
+		if secret != nil {
+			return &pb.FindSwapSecretResponse{Found: true, Secret: secret, TransactionId: transactionID}, nil
+		}
+	}
+
+	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		return nil, err
+	}
+	response := &pb.FindSwapSecretResponse{Found: false}
+	err = s.walkBlocks(dagInfo, func(blocks []*appmessage.RPCBlock) (bool, error) {
+		for _, block := range blocks {
+			for _, transaction := range block.Transactions {
+				secret, transactionID := findSwapSecretInTransaction(transaction, request.SecretHash)
+				if secret != nil {
+					response = &pb.FindSwapSecretResponse{Found: true, Secret: secret, TransactionId: transactionID}
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func findSwapSecretInTransaction(transaction *appmessage.RPCTransaction, secretHash []byte) ([]byte, string) {
+	for _, input := range transaction.Inputs {
+		signatureScript, err := hex.DecodeString(input.SignatureScript)
+		if err != nil {
+			continue
+		}
+		secret := libkashwallet.ExtractSwapSecret(signatureScript, secretHash)
+		if secret == nil {
+			continue
+		}
+
+		var transactionID string
+		if transaction.VerboseData != nil {
+			transactionID = transaction.VerboseData.TransactionID
+		}
+		return secret, transactionID
+	}
+	return nil, ""
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
 
 	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
 	"github.com/pkg/errors"
 )
 
@@ -32,7 +33,8 @@ func (s *server) sync() error {
 		return err
 	}
 
-	err = s.refreshExistingUTXOsWithLock()
+	s.rpcClient.SetOnReconnectedHandler(s.handleReconnected)
+	err = s.subscribeToUTXOChangesWithLock()
 	if err != nil {
 		return err
 	}
@@ -43,23 +45,281 @@ func (s *server) sync() error {
 			return err
 		}
 
-		err = s.collectRecentAddresses()
+		err = s.watchNewAddressesWithLock()
 		if err != nil {
 			return err
 		}
 
-		err = s.refreshExistingUTXOsWithLock()
+		// A failed save is retried on the next tick
+		err = s.saveHistoryIfChangedWithLock()
 		if err != nil {
-			return err
+			log.Errorf("Error saving the wallet history: %s", err)
 		}
 	}
 
 	return nil
 }
 
+// subscribeToUTXOChangesWithLock registers for UTXOsChanged notifications of all the watched addresses,
+// for pruning point UTXO set override notifications and for virtual DAA score changes, and then rebuilds
+// the UTXO set from scratch.
+// From then on, the UTXO set is kept up to date by applying the notifications incrementally.
+// Registering before refreshing makes sure no change is missed between the two.
+func (s *server) subscribeToUTXOChangesWithLock() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.watchedAddresses = make(walletAddressSet)
+	s.watchedUntilIndex = 0
+	addressesToWatch, err := s.addressesToWatch()
+	if err != nil {
+		return err
+	}
+
+	err = s.rpcClient.RegisterForUTXOsChangedNotifications(addressesToWatch.strings(), s.handleUTXOsChanged)
+	if err != nil {
+		return err
+	}
+	s.addWatchedAddresses(addressesToWatch)
+
+	err = s.rpcClient.RegisterPruningPointUTXOSetNotifications(s.handlePruningPointUTXOSetOverride)
+	if err != nil {
+		return err
+	}
+
+	err = s.rpcClient.RegisterForVirtualDaaScoreChangedNotifications(s.handleVirtualDAAScoreChanged)
+	if err != nil {
+		return err
+	}
+	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		return err
+	}
+	s.virtualDAAScore = dagInfo.VirtualDAAScore
+
+	return s.refreshUTXOs()
+}
+
+// handleReconnected is called after the RPC client reconnects to the node. Notification
+// registrations don't survive a reconnect, and changes may have been missed while
+// disconnected, so this registers again and rebuilds the UTXO set.
+func (s *server) handleReconnected() {
+	log.Infof("Reconnected to the node, resubscribing and refreshing the UTXO set")
+	s.resubscribeToUTXOChanges()
+}
+
+// handlePruningPointUTXOSetOverride is called when the node replaces its whole UTXO set, in which
+// case UTXOsChanged notifications aren't sent for the difference, so the UTXO set is rebuilt.
+func (s *server) handlePruningPointUTXOSetOverride() {
+	log.Infof("The node's pruning point UTXO set was overridden, refreshing the UTXO set")
+	err := s.refreshExistingUTXOsWithLock()
+	if err != nil {
+		log.Errorf("Error refreshing the UTXO set: %s", err)
+		spawn("resubscribeToUTXOChanges", s.resubscribeToUTXOChanges)
+	}
+}
+
+func (s *server) handleUTXOsChanged(notification *appmessage.UTXOsChangedNotificationMessage) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	err := s.applyUTXOsChanged(notification)
+	if err != nil {
+		log.Errorf("Error applying UTXO changes: %s", err)
+		spawn("resubscribeToUTXOChanges", s.resubscribeToUTXOChanges)
+	}
+}
+
+const resubscribeRetryDelay = 10 * time.Second
+
+// resubscribeToUTXOChanges registers for UTXO change notifications again and rebuilds the
+// UTXO set, retrying until it succeeds. It's used when a change couldn't be applied or
+// after a reconnect, so that the daemon keeps running and recovers a consistent UTXO set.
+func (s *server) resubscribeToUTXOChanges() {
+	// A resubscription that is already in progress rebuilds everything from scratch anyway
+	if !s.resubscribeLock.TryLock() {
+		return
+	}
+	defer s.resubscribeLock.Unlock()
+
+	for {
+		err := s.subscribeToUTXOChangesWithLock()
+		if err == nil {
+			return
+		}
+		log.Errorf("Error resubscribing to UTXO changes: %s", err)
+		log.Infof("Retrying in %s", resubscribeRetryDelay)
+
+		select {
+		case <-s.shutdown:
+			return
+		case <-time.After(resubscribeRetryDelay):
+		}
+	}
+}
+
+// applyUTXOsChanged applies the removed and added UTXOs of the given notification to
+// the UTXO set. An address that receives its first UTXO becomes a used address.
+func (s *server) applyUTXOsChanged(notification *appmessage.UTXOsChangedNotificationMessage) error {
+	for _, entry := range notification.Removed {
+		outpoint, err := appmessage.RPCOutpointToDomainOutpoint(entry.Outpoint)
+		if err != nil {
+			return err
+		}
+		delete(s.utxos, *outpoint)
+	}
+
+	newlyUsedAddresses := make(walletAddressSet)
+	for _, entry := range notification.Added {
+		address, ok := s.addressSet[entry.Address]
+		if !ok {
+			address, ok = s.watchedAddresses[entry.Address]
+			if !ok {
+				return errors.Errorf("Got a UTXO change of address %s even though it isn't watched", entry.Address)
+			}
+			newlyUsedAddresses[entry.Address] = address
+		}
+
+		utxo, err := s.rpcEntryToWalletUTXO(entry, address)
+		if err != nil {
+			return err
+		}
+		s.utxos[*utxo.Outpoint] = utxo
+	}
+	s.utxoSetVersion++
+
+	if len(newlyUsedAddresses) > 0 {
+		err := s.markAddressesUsed(newlyUsedAddresses)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.sortUTXOs()
+	return s.recordReceivedOutputs(notification.Added)
+}
+
+// addressesToWatch returns the addresses that should be watched but aren't yet:
+// the used addresses, and every address up to the gap limit
+// indexes after the last used one, so that payments to new addresses are noticed.
+func (s *server) addressesToWatch() (walletAddressSet, error) {
+	addresses := make(walletAddressSet)
+	watchUntilIndex := s.maxUsedIndex() + s.gapLimit
+	if watchUntilIndex > s.watchedUntilIndex {
+		var err error
+		addresses, err = s.addressesToQuery(s.watchedUntilIndex, watchUntilIndex)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for addressString, address := range s.addressSet {
+		if _, ok := s.watchedAddresses[addressString]; !ok {
+			addresses[addressString] = address
+		}
+	}
+
+	return addresses, nil
+}
+
+func (s *server) addWatchedAddresses(addresses walletAddressSet) {
+	for addressString, address := range addresses {
+		s.watchedAddresses[addressString] = address
+	}
+	watchUntilIndex := s.maxUsedIndex() + s.gapLimit
+	if watchUntilIndex > s.watchedUntilIndex {
+		s.watchedUntilIndex = watchUntilIndex
+	}
+}
+
+// watchNewAddressesWithLock adds the addresses that should be watched but aren't yet to the
+// UTXOsChanged notification addresses, and adds the UTXOs the used ones among them
+// already have to the UTXO set. The lock isn't held during the RPC calls, so that
+// notifications and wallet requests aren't blocked on the node.
+func (s *server) watchNewAddressesWithLock() error {
+	s.lock.Lock()
+	addressesToWatch, err := s.addressesToWatch()
+	if err != nil {
+		s.lock.Unlock()
+		return err
+	}
+	if len(addressesToWatch) == 0 {
+		s.lock.Unlock()
+		return nil
+	}
+
+	// The addresses are marked as watched before registering them, so that
+	// notifications that arrive right after the registration are accepted
+	previousWatchedUntilIndex := s.watchedUntilIndex
+	s.addWatchedAddresses(addressesToWatch)
+	usedAddresses := make([]string, 0)
+	for addressString := range addressesToWatch {
+		if _, ok := s.addressSet[addressString]; ok {
+			usedAddresses = append(usedAddresses, addressString)
+		}
+	}
+	s.lock.Unlock()
+
+	err = s.rpcClient.AddUTXOsChangedNotificationAddresses(addressesToWatch.strings())
+	if err != nil {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		for addressString := range addressesToWatch {
+			delete(s.watchedAddresses, addressString)
+		}
+		s.watchedUntilIndex = previousWatchedUntilIndex
+		return err
+	}
+	if len(usedAddresses) == 0 {
+		return nil
+	}
+
+	for {
+		s.lock.RLock()
+		utxoSetVersion := s.utxoSetVersion
+		s.lock.RUnlock()
+
+		getUTXOsByAddressesResponse, err := s.rpcClient.GetUTXOsByAddresses(usedAddresses)
+		if err != nil {
+			return err
+		}
+
+		s.lock.Lock()
+		// A UTXO in the response may have been spent by a change applied while the
+		// response was on its way, in which case the UTXOs are queried again
+		if s.utxoSetVersion != utxoSetVersion {
+			s.lock.Unlock()
+			continue
+		}
+		err = s.addUTXOsOfUsedAddresses(getUTXOsByAddressesResponse.Entries)
+		s.lock.Unlock()
+		return err
+	}
+}
+
+// addUTXOsOfUsedAddresses adds the given UTXOs of used addresses to the UTXO set
+func (s *server) addUTXOsOfUsedAddresses(entries []*appmessage.UTXOsByAddressesEntry) error {
+	for _, entry := range entries {
+		address, ok := s.addressSet[entry.Address]
+		if !ok {
+			return errors.Errorf("Got result from address %s even though it wasn't requested", entry.Address)
+		}
+		utxo, err := s.rpcEntryToWalletUTXO(entry, address)
+		if err != nil {
+			return err
+		}
+		s.utxos[*utxo.Outpoint] = utxo
+	}
+
+	s.sortUTXOs()
+	return s.recordReceivedOutputs(entries)
+}
+
 const (
-	numIndexesToQueryForFarAddresses    = 100
-	numIndexesToQueryForRecentAddresses = 1000
+	numIndexesToQueryForFarAddresses = 100
+	// DefaultGapLimit is the default number of unused address indexes after the last used one
+	// that are scanned and watched
+	DefaultGapLimit = 1000
 )
 
 // addressesToQuery scans the addresses in the given range. Because
@@ -88,20 +348,42 @@ func (s *server) addressesToQuery(start, end uint32) (walletAddressSet, error) {
 	return addresses, nil
 }
 
-// collectFarAddresses collects numIndexesToQueryForFarAddresses addresses
+// collectFarAddresses collects up to numIndexesToQueryForFarAddresses addresses
 // from the last point it stopped in the previous call.
 func (s *server) collectFarAddresses() error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
-	err := s.collectAddresses(s.nextSyncStartIndex, s.nextSyncStartIndex+numIndexesToQueryForFarAddresses)
+
+	start, end := s.farAddressesToCollect()
+	if start == end {
+		return nil
+	}
+	err := s.collectAddresses(start, end)
 	if err != nil {
 		return err
 	}
 
-	s.nextSyncStartIndex += numIndexesToQueryForFarAddresses
+	s.nextSyncStartIndex = end
 	return nil
 }
 
+// farAddressesToCollect returns the range of address indexes collectFarAddresses
+// should collect next. Addresses are only collected up to the gap limit after the
+// last used one, which makes the range empty once it's reached. Payments to the
+// addresses within the gap limit are noticed through UTXOsChanged notifications,
+// and using one of them moves the gap limit forward, along with the range.
+func (s *server) farAddressesToCollect() (start, end uint32) {
+	start = s.nextSyncStartIndex
+	end = s.maxUsedIndex() + s.gapLimit
+	if start >= end {
+		return start, start
+	}
+	if end-start > numIndexesToQueryForFarAddresses {
+		end = start + numIndexesToQueryForFarAddresses
+	}
+	return start, end
+}
+
 func (s *server) maxUsedIndexWithLock() uint32 {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
@@ -119,14 +401,14 @@ func (s *server) maxUsedIndex() uint32 {
 }
 
 // collectRecentAddresses collects addresses from used addresses until
-// the address with the index of the last used address + numIndexesToQueryForRecentAddresses.
-// collectRecentAddresses scans addresses in batches of numIndexesToQueryForRecentAddresses,
+// the address with the index of the last used address + the gap limit.
+// collectRecentAddresses scans addresses in batches of the gap limit size,
 // and releases the lock between scans.
 func (s *server) collectRecentAddresses() error {
 	index := uint32(0)
 	maxUsedIndex := uint32(0)
-	for ; index < maxUsedIndex+numIndexesToQueryForRecentAddresses; index += numIndexesToQueryForRecentAddresses {
-		err := s.collectAddressesWithLock(index, index+numIndexesToQueryForRecentAddresses)
+	for ; index < maxUsedIndex+s.gapLimit; index += s.gapLimit {
+		err := s.collectAddressesWithLock(index, index+s.gapLimit)
 
 		if err != nil {
 			return err
@@ -173,9 +455,8 @@ func (s *server) collectAddresses(start, end uint32) error {
 
 func (s *server) updateAddressesAndLastUsedIndexes(requestedAddressSet walletAddressSet,
 	getBalancesByAddressesResponse *appmessage.GetBalancesByAddressesResponseMessage) error {
-	lastUsedExternalIndex := s.keysFile.LastUsedExternalIndex()
-	lastUsedInternalIndex := s.keysFile.LastUsedInternalIndex()
 
+	usedAddresses := make(walletAddressSet)
 	for _, entry := range getBalancesByAddressesResponse.Entries {
 		walletAddress, ok := requestedAddressSet[entry.Address]
 		if !ok {
@@ -186,7 +467,30 @@ func (s *server) updateAddressesAndLastUsedIndexes(requestedAddressSet walletAdd
 			continue
 		}
 
-		s.addressSet[entry.Address] = walletAddress
+		usedAddresses[entry.Address] = walletAddress
+	}
+
+	return s.markAddressesUsed(usedAddresses)
+}
+
+func (s *server) markAddressesUsedWithLock(usedAddresses walletAddressSet) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.markAddressesUsed(usedAddresses)
+}
+
+// markAddressesUsed adds the given addresses to the used address set, and
+// advances the last used indexes of the keys file past them.
+func (s *server) markAddressesUsed(usedAddresses walletAddressSet) error {
+	lastUsedExternalIndex := s.keysFile.LastUsedExternalIndex()
+	lastUsedInternalIndex := s.keysFile.LastUsedInternalIndex()
+
+	for addressString, walletAddress := range usedAddresses {
+		// Existing entries are kept, since UTXOs and from addresses are matched by pointer
+		if _, ok := s.addressSet[addressString]; !ok {
+			s.addressSet[addressString] = walletAddress
+		}
 
 		if walletAddress.keyChain == libkashwallet.ExternalKeychain {
 			if walletAddress.index > lastUsedExternalIndex {
@@ -217,7 +521,7 @@ func (s *server) refreshExistingUTXOsWithLock() error {
 
 // updateUTXOSet clears the current UTXO set, and re-fills it with the given entries
 func (s *server) updateUTXOSet(entries []*appmessage.UTXOsByAddressesEntry, mempoolEntries []*appmessage.MempoolEntryByAddress) error {
-	utxos := make([]*walletUTXO, 0, len(entries))
+	utxos := make(map[externalapi.DomainOutpoint]*walletUTXO, len(entries))
 
 	exclude := make(map[appmessage.RPCOutpoint]struct{})
 	for _, entriesByAddress := range mempoolEntries {
@@ -233,32 +537,59 @@ func (s *server) updateUTXOSet(entries []*appmessage.UTXOsByAddressesEntry, memp
 			continue
 		}
 
-		outpoint, err := appmessage.RPCOutpointToDomainOutpoint(entry.Outpoint)
-		if err != nil {
-			return err
+		address, ok := s.addressSet[entry.Address]
+		if !ok {
+			return errors.Errorf("Got result from address %s even though it wasn't requested", entry.Address)
 		}
-
-		utxoEntry, err := appmessage.RPCUTXOEntryToUTXOEntry(entry.UTXOEntry)
+		utxo, err := s.rpcEntryToWalletUTXO(entry, address)
 		if err != nil {
 			return err
 		}
+		utxos[*utxo.Outpoint] = utxo
+	}
 
-		address, ok := s.addressSet[entry.Address]
-		if !ok {
-			return errors.Errorf("Got result from address %s even though it wasn't requested", entry.Address)
-		}
-		utxos = append(utxos, &walletUTXO{
-			Outpoint:  outpoint,
-			UTXOEntry: utxoEntry,
-			address:   address,
-		})
+	s.utxos = utxos
+	s.utxoSetVersion++
+	s.sortUTXOs()
+
+	return s.recordReceivedOutputs(entries)
+}
+
+// recordReceivedOutputs records the given UTXOs, which were just added to the wallet,
+// in the transaction history and as invoice payments
+func (s *server) recordReceivedOutputs(entries []*appmessage.UTXOsByAddressesEntry) error {
+	s.history.recordReceivedOutputs(entries)
+	return s.recordInvoicePayments(entries)
+}
+
+func (s *server) rpcEntryToWalletUTXO(entry *appmessage.UTXOsByAddressesEntry, address *walletAddress) (*walletUTXO, error) {
+	outpoint, err := appmessage.RPCOutpointToDomainOutpoint(entry.Outpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	utxoEntry, err := appmessage.RPCUTXOEntryToUTXOEntry(entry.UTXOEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &walletUTXO{
+		Outpoint:  outpoint,
+		UTXOEntry: utxoEntry,
+		address:   address,
+	}, nil
+}
+
+// sortUTXOs rebuilds utxosSortedByAmount out of the UTXO set
+func (s *server) sortUTXOs() {
+	utxos := make([]*walletUTXO, 0, len(s.utxos))
+	for _, utxo := range s.utxos {
+		utxos = append(utxos, utxo)
 	}
 
 	sort.Slice(utxos, func(i, j int) bool { return utxos[i].UTXOEntry.Amount() > utxos[j].UTXOEntry.Amount() })
 
 	s.utxosSortedByAmount = utxos
-
-	return nil
 }
 
 func (s *server) refreshUTXOs() error {
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/keys"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+)
+
+func TestApplyUTXOsChanged(t *testing.T) {
+	params := &dagconfig.SimnetParams
+
+	mnemonic, err := libkashwallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %s", err)
+	}
+	extendedPublicKey, err := libkashwallet.MasterPublicKeyFromMnemonic(params, mnemonic, false)
+	if err != nil {
+		t.Fatalf("MasterPublicKeyFromMnemonic: %s", err)
+	}
+	keysFile := &keys.File{
+		ExtendedPublicKeys: []string{extendedPublicKey},
+		MinimumSignatures:  1,
+	}
+	err = keysFile.SetPath(params, filepath.Join(t.TempDir(), "keys.json"), true)
+	if err != nil {
+		t.Fatalf("SetPath: %s", err)
+	}
+
+	history, err := loadWalletHistory(filepath.Join(t.TempDir(), "keys.history.json"))
+	if err != nil {
+		t.Fatalf("loadWalletHistory: %s", err)
+	}
+
+	serverInstance := &server{
+		params:           params,
+		keysFile:         keysFile,
+		history:          history,
+		utxos:            map[externalapi.DomainOutpoint]*walletUTXO{},
+		addressSet:       make(walletAddressSet),
+		watchedAddresses: make(walletAddressSet),
+		gapLimit:         DefaultGapLimit,
+		invoicesFilePath: filepath.Join(t.TempDir(), "keys.invoices.json"),
+	}
+	addressesToWatch, err := serverInstance.addressesToWatch()
+	if err != nil {
+		t.Fatalf("addressesToWatch: %s", err)
+	}
+	serverInstance.addWatchedAddresses(addressesToWatch)
+
+	addressString := func(keyChain uint8, index uint32) string {
+		addressString, err := serverInstance.walletAddressString(&walletAddress{index: index, keyChain: keyChain})
+		if err != nil {
+			t.Fatalf("walletAddressString: %s", err)
+		}
+		return addressString
+	}
+	entry := func(address string, transactionIndex byte, amount uint64) *appmessage.UTXOsByAddressesEntry {
+		transactionID := externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{transactionIndex})
+		return &appmessage.UTXOsByAddressesEntry{
+			Address:  address,
+			Outpoint: &appmessage.RPCOutpoint{TransactionID: transactionID.String(), Index: 0},
+			UTXOEntry: &appmessage.RPCUTXOEntry{
+				Amount:          amount,
+				ScriptPublicKey: &appmessage.RPCScriptPublicKey{Script: "00", Version: 0},
+			},
+		}
+	}
+
+	firstAddress := addressString(libkashwallet.ExternalKeychain, 5)
+	secondAddress := addressString(libkashwallet.InternalKeychain, 7)
+	firstAddressInvoice := &invoice{
+		ID:           1,
+		Address:      firstAddress,
+		AddressIndex: 5,
+		Amount:       100,
+		Payments:     make(map[string]*invoicePayment),
+	}
+	serverInstance.invoices = []*invoice{firstAddressInvoice}
+	err = serverInstance.applyUTXOsChanged(&appmessage.UTXOsChangedNotificationMessage{
+		Added: []*appmessage.UTXOsByAddressesEntry{entry(firstAddress, 1, 10), entry(secondAddress, 2, 20)},
+	})
+	if err != nil {
+		t.Fatalf("applyUTXOsChanged: %s", err)
+	}
+	if len(serverInstance.utxosSortedByAmount) != 2 || serverInstance.utxosSortedByAmount[0].UTXOEntry.Amount() != 20 {
+		t.Fatalf("Unexpected UTXO set after adding UTXOs: %v", serverInstance.utxosSortedByAmount)
+	}
+	if keysFile.LastUsedExternalIndex() != 5 || keysFile.LastUsedInternalIndex() != 7 {
+		t.Fatalf("Unexpected last used indexes %d and %d",
+			keysFile.LastUsedExternalIndex(), keysFile.LastUsedInternalIndex())
+	}
+	if _, ok := serverInstance.addressSet[firstAddress]; !ok {
+		t.Fatalf("The address that received a UTXO is not marked as used")
+	}
+
+	err = serverInstance.applyUTXOsChanged(&appmessage.UTXOsChangedNotificationMessage{
+		Added:   []*appmessage.UTXOsByAddressesEntry{entry(firstAddress, 3, 30)},
+		Removed: []*appmessage.UTXOsByAddressesEntry{entry(secondAddress, 2, 20)},
+	})
+	if err != nil {
+		t.Fatalf("applyUTXOsChanged: %s", err)
+	}
+	amounts := make([]uint64, len(serverInstance.utxosSortedByAmount))
+	for i, utxo := range serverInstance.utxosSortedByAmount {
+		amounts[i] = utxo.UTXOEntry.Amount()
+	}
+	if len(amounts) != 2 || amounts[0] != 30 || amounts[1] != 10 {
+		t.Fatalf("Unexpected UTXO amounts after applying a delta: %v", amounts)
+	}
+
+	// Both payments to the invoice address are recorded as they're added, even before the invoice is queried
+	err = serverInstance.applyUTXOsChanged(&appmessage.UTXOsChangedNotificationMessage{
+		Removed: []*appmessage.UTXOsByAddressesEntry{entry(firstAddress, 1, 10)},
+	})
+	if err != nil {
+		t.Fatalf("applyUTXOsChanged: %s", err)
+	}
+	invoices, err := loadInvoices(serverInstance.invoicesFilePath)
+	if err != nil {
+		t.Fatalf("loadInvoices: %s", err)
+	}
+	if len(invoices) != 1 || len(invoices[0].Payments) != 2 {
+		t.Fatalf("Unexpected invoice payments: %v", invoices)
+	}
+	_, receivedAmount, _ := invoices[0].status(0, time.Now())
+	if receivedAmount != 40 {
+		t.Fatalf("Unexpected invoice received amount %d after spending a payment. Want: 40", receivedAmount)
+	}
+
+	unwatchedAddress := addressString(libkashwallet.ExternalKeychain, DefaultGapLimit*2)
+	err = serverInstance.applyUTXOsChanged(&appmessage.UTXOsChangedNotificationMessage{
+		Added: []*appmessage.UTXOsByAddressesEntry{entry(unwatchedAddress, 4, 40)},
+	})
+	if err == nil {
+		t.Fatalf("applyUTXOsChanged unexpectedly accepted a UTXO of an unwatched address")
+	}
+
+	addressesToWatch, err = serverInstance.addressesToWatch()
+	if err != nil {
+		t.Fatalf("addressesToWatch: %s", err)
+	}
+	if len(addressesToWatch) != 7*len(keyChains) {
+		t.Fatalf("Expected the watched window to be extended by 7 indexes, but got %d new addresses",
+			len(addressesToWatch))
+	}
+
+	// Far addresses are only collected up to the gap limit after the last used index
+	serverInstance.nextSyncStartIndex = DefaultGapLimit
+	start, end := serverInstance.farAddressesToCollect()
+	if start != DefaultGapLimit || end != DefaultGapLimit+7 {
+		t.Fatalf("Unexpected far addresses to collect [%d, %d). Want: [%d, %d)",
+			start, end, DefaultGapLimit, DefaultGapLimit+7)
+	}
+	serverInstance.nextSyncStartIndex = end
+	start, end = serverInstance.farAddressesToCollect()
+	if start != end {
+		t.Fatalf("Far addresses [%d, %d) are collected past the gap limit", start, end)
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/keys"
+	"github.com/pkg/errors"
+)
+
+// maxUnlockTimeout is the longest time the wallet can stay unlocked
+const maxUnlockTimeout = 24 * time.Hour
+
+func (s *server) Unlock(_ context.Context, request *pb.UnlockRequest) (*pb.UnlockResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.keysFile.IsWatchOnly() {
+		return nil, keys.ErrWatchOnly
+	}
+	timeout := time.Duration(request.TimeoutSeconds) * time.Second
+	if timeout == 0 || timeout > maxUnlockTimeout {
+		return nil, errors.Errorf("The timeout must be between 1 second and %s", maxUnlockTimeout)
+	}
+
+	mnemonics, err := s.keysFile.DecryptMnemonics(request.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lockWallet()
+	s.unlockedMnemonics = mnemonics
+	s.unlockedUntil = time.Now().Add(timeout)
+	s.lockTimer = time.AfterFunc(timeout, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		if s.unlockedMnemonics != nil && !time.Now().Before(s.unlockedUntil) {
+			s.lockWallet()
+			log.Infof("The wallet was locked after its unlock timeout elapsed")
+		}
+	})
+	log.Infof("The wallet was unlocked until %s", s.unlockedUntil.Format(time.RFC3339))
+
+	return &pb.UnlockResponse{UnlockedUntil: s.unlockedUntil.Unix()}, nil
+}
+
+func (s *server) Lock(_ context.Context, _ *pb.LockRequest) (*pb.LockResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.lockWallet()
+	return &pb.LockResponse{}, nil
+}
+
+// lockWallet forgets the unlocked mnemonics. It must be called while holding s.lock
+func (s *server) lockWallet() {
+	if s.lockTimer != nil {
+		s.lockTimer.Stop()
+		s.lockTimer = nil
+	}
+	s.unlockedMnemonics = nil
+	s.unlockedUntil = time.Time{}
+}
+
+// mnemonics returns the wallet's mnemonics, decrypting them with the given password,
+// or taking the unlocked ones if the password is empty and the wallet is unlocked.
+// An empty password is still tried on a locked wallet, since a wallet may be created
+// with an empty password. It must be called while holding s.lock
+func (s *server) mnemonics(password string) ([]string, error) {
+	if password == "" && s.unlockedMnemonics != nil && time.Now().Before(s.unlockedUntil) {
+		return s.unlockedMnemonics, nil
+	}
+	mnemonics, err := s.keysFile.DecryptMnemonics(password)
+	if err != nil {
+		if password == "" {
+			return nil, errors.Wrap(err, "The wallet is locked: either send the password or unlock the wallet first")
+		}
+		return nil, err
+	}
+	return mnemonics, nil
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"sort"
+)
+
+// utxoSelectionStrategy selects which of the given candidates, sorted by amount in descending
+// order, to spend in order to pay spendAmount plus feePerInput for every selected UTXO. If the
+// candidates are insufficient, it may return any subset of them. isChangeless means the
+// selection was chosen so that no change output is required.
+type utxoSelectionStrategy func(candidates []*walletUTXO, spendAmount uint64, feePerInput uint64) (
+	selected []*walletUTXO, isChangeless bool)
+
+const (
+	selectionStrategyLargestFirst   = "largest-first"
+	selectionStrategyBranchAndBound = "branch-and-bound"
+	selectionStrategyGroupByAddress = "group-by-address"
+)
+
+var utxoSelectionStrategies = map[string]utxoSelectionStrategy{
+	"":                              selectLargestFirst,
+	selectionStrategyLargestFirst:   selectLargestFirst,
+	selectionStrategyBranchAndBound: selectBranchAndBound,
+	selectionStrategyGroupByAddress: selectGroupedByAddress,
+}
+
+func utxoSelectionStrategyNames() []string {
+	return []string{selectionStrategyLargestFirst, selectionStrategyBranchAndBound, selectionStrategyGroupByAddress}
+}
+
+func coversPayment(utxos []*walletUTXO, spendAmount uint64, feePerInput uint64) bool {
+	return totalAmount(utxos) >= spendAmount+feePerInput*uint64(len(utxos))
+}
+
+func totalAmount(utxos []*walletUTXO) uint64 {
+	total := uint64(0)
+	for _, utxo := range utxos {
+		total += utxo.UTXOEntry.Amount()
+	}
+	return total
+}
+
+// selectLargestFirst selects the largest UTXOs until the payment is covered, which
+// minimizes the number of inputs
+func selectLargestFirst(candidates []*walletUTXO, spendAmount uint64, feePerInput uint64) ([]*walletUTXO, bool) {
+	selected := []*walletUTXO{}
+	for _, utxo := range candidates {
+		selected = append(selected, utxo)
+		if coversPayment(selected, spendAmount, feePerInput) {
+			break
+		}
+	}
+	return selected, false
+}
+
+// maxBranchAndBoundTries bounds the number of selections selectBranchAndBound checks,
+// since the search is exponential in the number of candidates
+const maxBranchAndBoundTries = 100_000
+
+// selectBranchAndBound searches for a selection whose value, after the input fees, exceeds the
+// payment by less than the cost of a change output, so that no change output is created and the
+// excess is left to the fee. The cost of a change output is taken as the fee of spending it later.
+// If no such selection is found it falls back to selectLargestFirst.
+func selectBranchAndBound(candidates []*walletUTXO, spendAmount uint64, feePerInput uint64) ([]*walletUTXO, bool) {
+	costOfChange := feePerInput
+
+	// The effective value of a UTXO is its amount minus the fee of spending it.
+	// UTXOs that cost more to spend than they're worth are never worth selecting.
+	var utxos []*walletUTXO
+	var effectiveValues []uint64
+	remainingValue := uint64(0)
+	for _, utxo := range candidates {
+		if utxo.UTXOEntry.Amount() <= feePerInput {
+			continue
+		}
+		utxos = append(utxos, utxo)
+		effectiveValues = append(effectiveValues, utxo.UTXOEntry.Amount()-feePerInput)
+		remainingValue += utxo.UTXOEntry.Amount() - feePerInput
+	}
+
+	var bestSelection []int
+	bestExcess := uint64(0)
+	selection := []int{}
+	tries := 0
+
+	var search func(index int, selectedValue uint64, remainingValue uint64)
+	search = func(index int, selectedValue uint64, remainingValue uint64) {
+		tries++
+		if tries > maxBranchAndBoundTries || (bestSelection != nil && bestExcess == 0) {
+			return
+		}
+		if selectedValue > spendAmount+costOfChange {
+			return
+		}
+		if selectedValue >= spendAmount {
+			excess := selectedValue - spendAmount
+			if bestSelection == nil || excess < bestExcess {
+				bestSelection = append([]int{}, selection...)
+				bestExcess = excess
+			}
+			return
+		}
+		if index == len(utxos) || selectedValue+remainingValue < spendAmount {
+			return
+		}
+
+		selection = append(selection, index)
+		search(index+1, selectedValue+effectiveValues[index], remainingValue-effectiveValues[index])
+		selection = selection[:len(selection)-1]
+		search(index+1, selectedValue, remainingValue-effectiveValues[index])
+	}
+	search(0, 0, remainingValue)
+
+	if bestSelection == nil {
+		return selectLargestFirst(candidates, spendAmount, feePerInput)
+	}
+
+	selected := make([]*walletUTXO, len(bestSelection))
+	for i, index := range bestSelection {
+		selected[i] = utxos[index]
+	}
+	return selected, true
+}
+
+// selectGroupedByAddress always spends all the UTXOs of an address together, so that a transaction
+// never reveals that some UTXOs of an address belong to the same wallet as other UTXOs left unspent.
+// It prefers the smallest single address that covers the payment, so that a single address is linked
+// to it. Otherwise, it combines whole addresses, largest first.
+func selectGroupedByAddress(candidates []*walletUTXO, spendAmount uint64, feePerInput uint64) ([]*walletUTXO, bool) {
+	groupIndexes := make(map[walletAddress]int)
+	var groups [][]*walletUTXO
+	for _, utxo := range candidates {
+		index, ok := groupIndexes[*utxo.address]
+		if !ok {
+			index = len(groups)
+			groupIndexes[*utxo.address] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], utxo)
+	}
+
+	var bestGroup []*walletUTXO
+	for _, group := range groups {
+		if coversPayment(group, spendAmount, feePerInput) &&
+			(bestGroup == nil || totalAmount(group) < totalAmount(bestGroup)) {
+			bestGroup = group
+		}
+	}
+	if bestGroup != nil {
+		return bestGroup, false
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool { return totalAmount(groups[i]) > totalAmount(groups[j]) })
+	selected := []*walletUTXO{}
+	for _, group := range groups {
+		selected = append(selected, group...)
+		if coversPayment(selected, spendAmount, feePerInput) {
+			break
+		}
+	}
+	return selected, false
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
+)
+
+func testUTXOs(addresses []*walletAddress, amounts []uint64) []*walletUTXO {
+	utxos := make([]*walletUTXO, len(amounts))
+	for i, amount := range amounts {
+		utxos[i] = &walletUTXO{
+			Outpoint: externalapi.NewDomainOutpoint(
+				externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{byte(i)}), 0),
+			UTXOEntry: utxo.NewUTXOEntry(amount, &externalapi.ScriptPublicKey{}, false, 0),
+			address:   addresses[i],
+		}
+	}
+	return utxos
+}
+
+func selectedAmounts(selected []*walletUTXO) []uint64 {
+	amounts := make([]uint64, len(selected))
+	for i, utxo := range selected {
+		amounts[i] = utxo.UTXOEntry.Amount()
+	}
+	return amounts
+}
+
+func equalAmounts(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestUTXOSelectionStrategies(t *testing.T) {
+	const feePerInput = 10
+
+	first := &walletAddress{index: 1}
+	second := &walletAddress{index: 2}
+	third := &walletAddress{index: 3}
+	// Sorted by amount in descending order, like utxosSortedByAmount
+	candidates := testUTXOs(
+		[]*walletAddress{first, second, third, first, second, third},
+		[]uint64{1000, 700, 500, 310, 200, 5})
+
+	tests := []struct {
+		name                 string
+		strategy             utxoSelectionStrategy
+		spendAmount          uint64
+		expectedAmounts      []uint64
+		expectedIsChangeless bool
+	}{
+		{
+			name:            "largest-first",
+			strategy:        selectLargestFirst,
+			spendAmount:     1200,
+			expectedAmounts: []uint64{1000, 700},
+		},
+		{
+			name:                 "branch-and-bound exact match",
+			strategy:             selectBranchAndBound,
+			spendAmount:          500 + 310 - 2*feePerInput,
+			expectedAmounts:      []uint64{500, 310},
+			expectedIsChangeless: true,
+		},
+		{
+			name:                 "branch-and-bound match within the cost of change",
+			strategy:             selectBranchAndBound,
+			spendAmount:          985,
+			expectedAmounts:      []uint64{1000},
+			expectedIsChangeless: true,
+		},
+		{
+			name:            "branch-and-bound falls back to largest-first",
+			strategy:        selectBranchAndBound,
+			spendAmount:     1250,
+			expectedAmounts: []uint64{1000, 700},
+		},
+		{
+			name:            "group-by-address picks the smallest sufficient address",
+			strategy:        selectGroupedByAddress,
+			spendAmount:     800,
+			expectedAmounts: []uint64{700, 200},
+		},
+		{
+			name:            "group-by-address combines whole addresses",
+			strategy:        selectGroupedByAddress,
+			spendAmount:     1500,
+			expectedAmounts: []uint64{1000, 310, 700, 200},
+		},
+	}
+
+	for _, test := range tests {
+		selected, isChangeless := test.strategy(candidates, test.spendAmount, feePerInput)
+		if !equalAmounts(selectedAmounts(selected), test.expectedAmounts) {
+			t.Errorf("%s: expected %v to be selected, but got %v", test.name, test.expectedAmounts, selectedAmounts(selected))
+		}
+		if isChangeless != test.expectedIsChangeless {
+			t.Errorf("%s: expected isChangeless to be %t", test.name, test.expectedIsChangeless)
+		}
+		if !coversPayment(selected, test.spendAmount, feePerInput) {
+			t.Errorf("%s: the selection doesn't cover the payment", test.name)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/client"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/server"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/keys"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+)
+
+// daemonConnectionFlags are the flags of every sub-command that talks to the wallet daemon
+type daemonConnectionFlags struct {
+	DaemonAddress   string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	DaemonCert      string `long:"daemon-cert" description:"The TLS certificate of the wallet daemon (default: keys.daemon.cert next to the keys file)"`
+	DaemonNoTLS     bool   `long:"daemon-notls" description:"Connect to a wallet daemon that was started with --notls"`
+	DaemonTokenFile string `long:"daemon-token-file" description:"The API token file of the wallet daemon (default: keys.daemon.token next to the keys file)"`
+	DaemonNoAuth    bool   `long:"daemon-noauth" description:"Connect to a wallet daemon that was started with --noauth"`
+}
+
+func defaultDaemonConnectionFlags() daemonConnectionFlags {
+	return daemonConnectionFlags{DaemonAddress: defaultListen}
+}
+
+// connectToDaemon connects to the wallet daemon. The TLS certificate and API token are looked
+// for next to the given keys file, or next to the default keys file if it's empty
+func connectToDaemon(flags *daemonConnectionFlags, params *dagconfig.Params, keysFilePath string) (
+	pb.KaspawalletdClient, func(), error) {
+
+	if keysFilePath == "" {
+		keysFilePath = keys.DefaultKeysFile(params)
+	}
+
+	security := &client.Security{}
+	if !flags.DaemonNoTLS {
+		security.TLSCertFile = flags.DaemonCert
+		if security.TLSCertFile == "" {
+			security.TLSCertFile = server.TLSCertFilePath(keysFilePath)
+		}
+	}
+	if !flags.DaemonNoAuth {
+		security.APITokenFile = flags.DaemonTokenFile
+		if security.APITokenFile == "" {
+			security.APITokenFile = server.APITokenFilePath(keysFilePath)
+		}
+	}
+
+	return client.Connect(flags.DaemonAddress, security)
+}
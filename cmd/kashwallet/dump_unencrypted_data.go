@@ -25,7 +25,7 @@ func dumpUnencryptedData(conf *dumpUnencryptedDataConfig) error {
 		return err
 	}
 
-	if len(conf.Password) == 0 {
+	if len(conf.Password) == 0 && !keysFile.IsWatchOnly() {
 		conf.Password = keys.GetPassword("Password:")
 	}
 	mnemonics, err := keysFile.DecryptMnemonics(conf.Password)
@@ -55,6 +55,10 @@ func dumpUnencryptedData(conf *dumpUnencryptedDataConfig) error {
 	}
 
 	fmt.Printf("Minimum number of signatures: %d\n", keysFile.MinimumSignatures)
+	if len(keysFile.ExtendedPublicKeys) > 1 {
+		fmt.Printf("Multisig descriptor fingerprint: %s\n", libkashwallet.MultisigFingerprint(conf.NetParams().Name,
+			keysFile.MinimumSignatures, keysFile.ExtendedPublicKeys, keysFile.ECDSA))
+	}
 	return nil
 }
 
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/utils"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
+	"github.com/pkg/errors"
+)
+
+const (
+	historyFormatTable = "table"
+	historyFormatCSV   = "csv"
+	historyFormatJSON  = "json"
+)
+
+func history(conf *historyConfig) error {
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	request := &pb.ListTransactionsRequest{Offset: conf.Offset, Limit: conf.Limit}
+	if conf.All {
+		request = &pb.ListTransactionsRequest{}
+	}
+	response, err := daemonClient.ListTransactions(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	var writer io.Writer = os.Stdout
+	if conf.Output != "" {
+		file, err := os.Create(conf.Output)
+		if err != nil {
+			return errors.Wrapf(err, "Could not create %s", conf.Output)
+		}
+		defer file.Close()
+		writer = file
+	}
+
+	switch conf.Format {
+	case historyFormatCSV:
+		err = writeHistoryCSV(writer, response.Transactions)
+	case historyFormatJSON:
+		err = writeHistoryJSON(writer, response.Transactions)
+	default:
+		printHistoryTable(writer, response)
+	}
+	if err != nil {
+		return err
+	}
+
+	if conf.Output != "" {
+		fmt.Printf("Exported %d transactions to %s\n", len(response.Transactions), conf.Output)
+	}
+	return nil
+}
+
+func printHistoryTable(writer io.Writer, response *pb.ListTransactionsResponse) {
+	for _, tx := range response.Transactions {
+		direction := "received"
+		if tx.IsSent {
+			direction = "sent    "
+		}
+		fee := "unknown"
+		if tx.IsFeeKnown {
+			fee = formatSompi(tx.Fee)
+		}
+		if !tx.IsSent {
+			fee = "-"
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s KSH\tFee: %s\t%s\t%s\n", time.Unix(tx.Timestamp, 0).Format(time.RFC3339),
+			direction, formatNetAmount(tx), fee, tx.TransactionId, tx.Label)
+		for _, recipient := range tx.Recipients {
+			label := ""
+			if recipient.Label != "" {
+				label = fmt.Sprintf(" (%s)", recipient.Label)
+			}
+			fmt.Fprintf(writer, "\t-> %s%s %s KSH\n", recipient.Address, label, utils.FormatKas(recipient.Amount))
+		}
+	}
+	fmt.Fprintf(writer, "Showing %d of %d transactions\n", len(response.Transactions), response.Total)
+}
+
+func writeHistoryCSV(writer io.Writer, transactions []*pb.WalletTransaction) error {
+	csvWriter := csv.NewWriter(writer)
+	err := csvWriter.Write([]string{"transaction_id", "time", "block_daa_score", "direction", "net_amount",
+		"sent_amount", "received_amount", "fee", "recipients", "label"})
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range transactions {
+		direction := "received"
+		if tx.IsSent {
+			direction = "sent"
+		}
+		fee := ""
+		if tx.IsSent && tx.IsFeeKnown {
+			fee = formatSompi(tx.Fee)
+		}
+		recipients := make([]string, len(tx.Recipients))
+		for i, recipient := range tx.Recipients {
+			recipients[i] = recipient.Address + "=" + formatSompi(recipient.Amount)
+		}
+
+		err := csvWriter.Write([]string{
+			tx.TransactionId,
+			time.Unix(tx.Timestamp, 0).UTC().Format(time.RFC3339),
+			strconv.FormatUint(tx.BlockDaaScore, 10),
+			direction,
+			formatNetAmount(tx),
+			formatSompi(tx.SentAmount),
+			formatSompi(tx.ReceivedAmount),
+			fee,
+			strings.Join(recipients, ";"),
+			tx.Label,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func writeHistoryJSON(writer io.Writer, transactions []*pb.WalletTransaction) error {
+	if transactions == nil {
+		transactions = []*pb.WalletTransaction{}
+	}
+	transactionsJSON, err := json.MarshalIndent(transactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(writer, string(transactionsJSON))
+	return err
+}
+
+// formatNetAmount returns the change of the wallet's balance by the transaction, in KSH
+func formatNetAmount(tx *pb.WalletTransaction) string {
+	if tx.SentAmount > tx.ReceivedAmount {
+		return "-" + formatSompi(tx.SentAmount-tx.ReceivedAmount)
+	}
+	return formatSompi(tx.ReceivedAmount - tx.SentAmount)
+}
+
+// formatSompi formats an amount of sompi in KSH with all 8 decimal places, unlike
+// utils.FormatKas it isn't padded, which makes it suitable for exports
+func formatSompi(amount uint64) string {
+	return fmt.Sprintf("%d.%08d", amount/constants.SompiPerKaspa, amount%constants.SompiPerKaspa)
+}
+
+func label(conf *labelConfig) error {
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	_, err = daemonClient.SetLabel(ctx, &pb.SetLabelRequest{
+		Address:       conf.Address,
+		TransactionId: conf.TransactionID,
+		Label:         conf.Label,
+	})
+	if err != nil {
+		return err
+	}
+
+	if conf.Remove {
+		fmt.Println("The label was removed")
+	} else {
+		fmt.Println("The label was set")
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/utils"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
+)
+
+func requestPayment(conf *requestPaymentConfig) error {
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.CreateInvoice(ctx, &pb.CreateInvoiceRequest{
+		Amount:                uint64(conf.Amount * constants.SompiPerKaspa),
+		Label:                 conf.Label,
+		Message:               conf.Message,
+		ExpirySeconds:         uint64(conf.Expiry.Seconds()),
+		RequiredConfirmations: conf.Confirmations,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Invoice #%d for %s KSH to %s\n", response.Invoice.Id,
+		utils.FormatKas(response.Invoice.Amount), response.Invoice.Address)
+	if response.Invoice.ExpiresAt != 0 {
+		fmt.Printf("Expires at %s\n", time.Unix(response.Invoice.ExpiresAt, 0).Format(time.RFC3339))
+	}
+	fmt.Println(response.Invoice.Uri)
+	return nil
+}
+
+func invoices(conf *invoicesConfig) error {
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	if conf.Follow {
+		stream, err := daemonClient.SubscribeInvoices(context.Background(), &pb.SubscribeInvoicesRequest{})
+		if err != nil {
+			return err
+		}
+		for {
+			invoice, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			printInvoice(invoice)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.GetInvoices(ctx, &pb.GetInvoicesRequest{})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Invoices (%d):\n", len(response.Invoices))
+	for _, invoice := range response.Invoices {
+		printInvoice(invoice)
+	}
+	return nil
+}
+
+func printInvoice(invoice *pb.Invoice) {
+	status := invoice.Status
+	if invoice.ReceivedAmount > 0 {
+		status = fmt.Sprintf("%s (%d/%d confirmations)", status, invoice.Confirmations, invoice.RequiredConfirmations)
+	}
+	fmt.Printf("#%d\t%s\tRequested: %s KSH\tReceived: %s KSH\t%s\t%s\n", invoice.Id, invoice.Address,
+		utils.FormatKas(invoice.Amount), utils.FormatKas(invoice.ReceivedAmount), status, invoice.Label)
+}
@@ -28,7 +28,8 @@ var (
 // LastVersion is the most up to date file format version
 const LastVersion = 1
 
-func defaultKeysFile(netParams *dagconfig.Params) string {
+// DefaultKeysFile returns the path of the keys file that is used when none is specified
+func DefaultKeysFile(netParams *dagconfig.Params) string {
 	return filepath.Join(defaultAppDir, netParams.Name, "keys.json")
 }
 
@@ -47,6 +48,7 @@ type keysFileJSON struct {
 	LastUsedExternalIndex uint32                     `json:"lastUsedExternalIndex"`
 	LastUsedInternalIndex uint32                     `json:"lastUsedInternalIndex"`
 	ECDSA                 bool                       `json:"ecdsa"`
+	BirthdayDAAScore      uint64                     `json:"birthdayDAAScore,omitempty"`
 }
 
 // EncryptedMnemonic represents an encrypted mnemonic
@@ -66,7 +68,9 @@ type File struct {
 	lastUsedExternalIndex uint32
 	lastUsedInternalIndex uint32
 	ECDSA                 bool
-	path                  string
+	// BirthdayDAAScore is the DAA score before which the wallet received no funds. Zero means it's unknown
+	BirthdayDAAScore uint64
+	path             string
 }
 
 func (d *File) toJSON() *keysFileJSON {
@@ -88,6 +92,7 @@ func (d *File) toJSON() *keysFileJSON {
 		CosignerIndex:         d.CosignerIndex,
 		LastUsedExternalIndex: d.lastUsedExternalIndex,
 		LastUsedInternalIndex: d.lastUsedInternalIndex,
+		BirthdayDAAScore:      d.BirthdayDAAScore,
 	}
 }
 
@@ -117,6 +122,7 @@ func (d *File) fromJSON(fileJSON *keysFileJSON) error {
 	d.CosignerIndex = fileJSON.CosignerIndex
 	d.lastUsedExternalIndex = fileJSON.LastUsedExternalIndex
 	d.lastUsedInternalIndex = fileJSON.LastUsedInternalIndex
+	d.BirthdayDAAScore = fileJSON.BirthdayDAAScore
 
 	d.EncryptedMnemonics = make([]*EncryptedMnemonic, len(fileJSON.EncryptedPrivateKeys))
 	for i, encryptedPrivateKeyJSON := range fileJSON.EncryptedPrivateKeys {
@@ -142,7 +148,7 @@ func (d *File) fromJSON(fileJSON *keysFileJSON) error {
 // SetPath sets the path where the file is saved to.
 func (d *File) SetPath(params *dagconfig.Params, path string, forceOverride bool) error {
 	if path == "" {
-		path = defaultKeysFile(params)
+		path = DefaultKeysFile(params)
 	}
 
 	if !forceOverride {
@@ -205,6 +211,27 @@ func (d *File) LastUsedInternalIndex() uint32 {
 	return d.lastUsedInternalIndex
 }
 
+// SetBirthdayDAAScore sets the wallet birthday, and saves the file.
+func (d *File) SetBirthdayDAAScore(birthdayDAAScore uint64) error {
+	if d.BirthdayDAAScore == birthdayDAAScore {
+		return nil
+	}
+
+	d.BirthdayDAAScore = birthdayDAAScore
+	return d.Save()
+}
+
+// IsWatchOnly returns whether the file holds no private keys, in which case
+// the wallet can only track its addresses and create unsigned transactions
+func (d *File) IsWatchOnly() bool {
+	return len(d.EncryptedMnemonics) == 0
+}
+
+// ErrWatchOnly is returned when trying to sign with a watch-only wallet
+var ErrWatchOnly = errors.New("this is a watch-only wallet, which holds no private keys and cannot sign " +
+	"transactions. Use create-unsigned-transaction instead, and sign its output with a wallet that holds " +
+	"the private keys")
+
 // DecryptMnemonics asks the user to enter the password for the private keys and
 // returns the decrypted private keys.
 func (d *File) DecryptMnemonics(password string) ([]string, error) {
@@ -234,7 +261,7 @@ func (d *File) DecryptMnemonics(password string) ([]string, error) {
 // ReadKeysFile returns the data related to the keys file
 func ReadKeysFile(netParams *dagconfig.Params, path string) (*File, error) {
 	if path == "" {
-		path = defaultKeysFile(netParams)
+		path = DefaultKeysFile(netParams)
 	}
 
 	file, err := os.Open(path)
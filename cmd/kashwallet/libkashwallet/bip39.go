@@ -80,3 +80,40 @@ func versionFromParams(params *dagconfig.Params) ([4]byte, error) {
 
 	return [4]byte{}, errors.Errorf("unknown network %s", params.Name)
 }
+
+func publicVersionFromParams(params *dagconfig.Params) ([4]byte, error) {
+	switch params.Name {
+	case dagconfig.MainnetParams.Name:
+		return bip32.KaspaMainnetPublic, nil
+	case dagconfig.TestnetParams.Name:
+		return bip32.KaspaTestnetPublic, nil
+	case dagconfig.DevnetParams.Name:
+		return bip32.KaspaDevnetPublic, nil
+	case dagconfig.SimnetParams.Name:
+		return bip32.KaspaSimnetPublic, nil
+	}
+
+	return [4]byte{}, errors.Errorf("unknown network %s", params.Name)
+}
+
+// ValidateExtendedPublicKey returns an error if the given string is not an
+// extended public key of the given network. Extended private keys are
+// rejected as well, so that they never end up in a watch-only wallet.
+func ValidateExtendedPublicKey(params *dagconfig.Params, extendedPublicKey string) error {
+	extendedKey, err := bip32.DeserializeExtendedKey(extendedPublicKey)
+	if err != nil {
+		return errors.Wrapf(err, "%s is invalid extended public key", extendedPublicKey)
+	}
+	if extendedKey.IsPrivate() {
+		return errors.New("an extended private key was given where an extended public key was expected")
+	}
+
+	version, err := publicVersionFromParams(params)
+	if err != nil {
+		return err
+	}
+	if extendedKey.Version != version {
+		return errors.Errorf("%s is not an extended public key of network %s", extendedPublicKey, params.Name)
+	}
+	return nil
+}
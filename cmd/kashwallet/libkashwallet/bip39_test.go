@@ -0,0 +1,47 @@
+package libkashwallet_test
+
+import (
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/bip32"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+)
+
+func TestValidateExtendedPublicKey(t *testing.T) {
+	mnemonic, err := libkashwallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+
+	for _, isMultisig := range []bool{false, true} {
+		extendedPublicKey, err := libkashwallet.MasterPublicKeyFromMnemonic(&dagconfig.MainnetParams, mnemonic, isMultisig)
+		if err != nil {
+			t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+		}
+
+		err = libkashwallet.ValidateExtendedPublicKey(&dagconfig.MainnetParams, extendedPublicKey)
+		if err != nil {
+			t.Fatalf("ValidateExtendedPublicKey unexpectedly failed: %+v", err)
+		}
+
+		err = libkashwallet.ValidateExtendedPublicKey(&dagconfig.TestnetParams, extendedPublicKey)
+		if err == nil {
+			t.Fatalf("ValidateExtendedPublicKey unexpectedly accepted a mainnet key on testnet")
+		}
+	}
+
+	extendedPrivateKey, err := bip32.NewMasterWithPath(make([]byte, 32), bip32.KaspaMainnetPrivate, "m/44'/111111'/0'")
+	if err != nil {
+		t.Fatalf("NewMasterWithPath: %+v", err)
+	}
+	err = libkashwallet.ValidateExtendedPublicKey(&dagconfig.MainnetParams, extendedPrivateKey.String())
+	if err == nil {
+		t.Fatalf("ValidateExtendedPublicKey unexpectedly accepted an extended private key")
+	}
+
+	err = libkashwallet.ValidateExtendedPublicKey(&dagconfig.MainnetParams, "kpub-invalid")
+	if err == nil {
+		t.Fatalf("ValidateExtendedPublicKey unexpectedly accepted an invalid key")
+	}
+}
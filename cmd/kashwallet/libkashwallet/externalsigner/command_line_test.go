@@ -0,0 +1,43 @@
+package externalsigner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		command       string
+		expectedWords []string
+		expectedError bool
+	}{
+		{command: "", expectedWords: nil},
+		{command: "  signer  --flag value ", expectedWords: []string{"signer", "--flag", "value"}},
+		{command: `"/path with spaces/signer" --name 'my device'`,
+			expectedWords: []string{"/path with spaces/signer", "--name", "my device"}},
+		{command: `/path\ with\ spaces/signer`, expectedWords: []string{"/path with spaces/signer"}},
+		{command: `signer "a \"quoted\" \w" 'single \ "quotes"'`,
+			expectedWords: []string{"signer", `a "quoted" \w`, `single \ "quotes"`}},
+		{command: `signer --empty "" --joined a'b'"c"`, expectedWords: []string{"signer", "--empty", "", "--joined", "abc"}},
+		{command: `signer 'unterminated`, expectedError: true},
+		{command: `signer "unterminated`, expectedError: true},
+		{command: `signer \`, expectedError: true},
+	}
+
+	for _, test := range tests {
+		words, err := splitCommandLine(test.command)
+		if test.expectedError {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.command)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: splitCommandLine: %+v", test.command, err)
+			continue
+		}
+		if !reflect.DeepEqual(words, test.expectedWords) {
+			t.Errorf("%s: got words %q. Want: %q", test.command, words, test.expectedWords)
+		}
+	}
+}
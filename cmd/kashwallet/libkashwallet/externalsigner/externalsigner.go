@@ -0,0 +1,250 @@
+package externalsigner
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/serialization"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+// Signer is an external signer: a subprocess that receives a single JSON Request on its
+// stdin and writes a single JSON Response to its stdout. The subprocess' stderr is passed
+// through, so it can be used to prompt the user (e.g. to confirm on a hardware device).
+type Signer struct {
+	path string
+	args []string
+}
+
+// New returns a Signer that runs the given command line. The command line is split
+// into words the way a POSIX shell splits it, so a path or an argument that contains
+// spaces can be quoted or escaped. No other shell expansion is done.
+func New(command string) (*Signer, error) {
+	words, err := splitCommandLine(command)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed external signer command")
+	}
+	if len(words) == 0 {
+		return nil, errors.New("the external signer command is empty")
+	}
+	return &Signer{path: words[0], args: words[1:]}, nil
+}
+
+// splitCommandLine splits command into words separated by whitespace. Within single quotes
+// every character is literal. Within double quotes a backslash escapes only '"', '\', '$'
+// and '`'. Outside quotes a backslash escapes any character.
+func splitCommandLine(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	isInWord := false
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, errors.New("the command ends with a backslash")
+			}
+			i++
+			word.WriteRune(runes[i])
+			isInWord = true
+		case r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, errors.New("unterminated single quote")
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			i = end
+			isInWord = true
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+				}
+				word.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, errors.New("unterminated double quote")
+			}
+			isInWord = true
+		case unicode.IsSpace(r):
+			if isInWord {
+				words = append(words, word.String())
+				word.Reset()
+				isInWord = false
+			}
+		default:
+			word.WriteRune(r)
+			isInWord = true
+		}
+	}
+	if isInWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// Sign asks the signer to sign the given serialized PSKT (or legacy PartiallySignedTransaction),
+// and returns it in the same format with the signer's signatures added.
+func (s *Signer) Sign(params *dagconfig.Params, serializedPSTx []byte, ecdsa bool) ([]byte, error) {
+	pskt, err := serialization.DeserializePSKT(serializedPSTx)
+	if err != nil {
+		return nil, err
+	}
+	partiallySignedTransaction := pskt.PartiallySignedTransaction
+
+	request := &Request{
+		Version:     ProtocolVersion,
+		Command:     CommandSign,
+		Network:     params.Name,
+		ECDSA:       ecdsa,
+		Transaction: hex.EncodeToString(serializedPSTx),
+		Inputs:      make([]*InputKeys, len(partiallySignedTransaction.PartiallySignedInputs)),
+	}
+	for i, input := range partiallySignedTransaction.PartiallySignedInputs {
+		publicKeys := make([]string, len(input.PubKeySignaturePairs))
+		for j, pair := range input.PubKeySignaturePairs {
+			publicKeys[j] = pair.ExtendedPublicKey
+		}
+		request.Inputs[i] = &InputKeys{
+			Index:             uint32(i),
+			DerivationPath:    input.DerivationPath,
+			MinimumSignatures: input.MinimumSignatures,
+			PublicKeys:        publicKeys,
+		}
+	}
+
+	response, err := s.call(request)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Signatures) == 0 {
+		return nil, errors.New("the external signer didn't return any signatures")
+	}
+
+	err = applySignatures(partiallySignedTransaction, response.Signatures, ecdsa)
+	if err != nil {
+		return nil, err
+	}
+	return serialization.SerializePSKT(pskt)
+}
+
+// PublicKeys asks the signer for the master extended public keys and the
+// plain public key it holds. Either of them may be empty.
+func (s *Signer) PublicKeys(params *dagconfig.Params) (extendedPublicKeys []string, publicKey string, err error) {
+	response, err := s.call(&Request{
+		Version: ProtocolVersion,
+		Command: CommandGetPublicKeys,
+		Network: params.Name,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return response.ExtendedPublicKeys, response.PublicKey, nil
+}
+
+func (s *Signer) call(request *Request) (*Response, error) {
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(s.path, s.args...)
+	cmd.Stdin = bytes.NewReader(requestBytes)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error running the external signer %s", s.path)
+	}
+
+	response := &Response{}
+	err = json.Unmarshal(stdout.Bytes(), response)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing the response of the external signer %s", s.path)
+	}
+	if response.Error != "" {
+		return nil, errors.Errorf("the external signer %s returned an error: %s", s.path, response.Error)
+	}
+	return response, nil
+}
+
+// applySignatures adds the given signatures to the transaction. Every signature is
+// verified first, so that a faulty or malicious signer can't make the wallet
+// broadcast a transaction that is invalid or signs something else.
+func applySignatures(partiallySignedTransaction *serialization.PartiallySignedTransaction,
+	signatures []*Signature, ecdsa bool) error {
+
+	for _, signature := range signatures {
+		if int(signature.InputIndex) >= len(partiallySignedTransaction.PartiallySignedInputs) {
+			return errors.Errorf("the external signer returned a signature for input %d, but the "+
+				"transaction has only %d inputs", signature.InputIndex, len(partiallySignedTransaction.PartiallySignedInputs))
+		}
+		signatureBytes, err := hex.DecodeString(signature.Signature)
+		if err != nil {
+			return errors.Wrapf(err, "the external signer returned a malformed signature for input %d", signature.InputIndex)
+		}
+
+		var matchingPairs []*serialization.PubKeySignaturePair
+		input := partiallySignedTransaction.PartiallySignedInputs[signature.InputIndex]
+		for _, pair := range input.PubKeySignaturePairs {
+			if pair.ExtendedPublicKey == signature.PublicKey {
+				matchingPairs = append(matchingPairs, pair)
+			}
+		}
+		if len(matchingPairs) == 0 {
+			return errors.Errorf("the external signer returned a signature for input %d with "+
+				"the unexpected public key %s", signature.InputIndex, signature.PublicKey)
+		}
+
+		err = libkashwallet.VerifySignature(partiallySignedTransaction, int(signature.InputIndex),
+			signature.PublicKey, signatureBytes, ecdsa)
+		if err != nil {
+			return errors.Wrapf(err, "the external signer returned an invalid signature for input %d",
+				signature.InputIndex)
+		}
+		for _, pair := range matchingPairs {
+			pair.Signature = signatureBytes
+		}
+	}
+	return nil
+}
+
+// AddedSignatures returns the signatures that exist in the after transaction but not in
+// the before transaction. It can be used by signers to build their Response.
+func AddedSignatures(before, after *serialization.PartiallySignedTransaction) ([]*Signature, error) {
+	if len(before.PartiallySignedInputs) != len(after.PartiallySignedInputs) {
+		return nil, errors.New("the transactions have a different number of inputs")
+	}
+
+	var signatures []*Signature
+	for i, afterInput := range after.PartiallySignedInputs {
+		beforeInput := before.PartiallySignedInputs[i]
+		if len(beforeInput.PubKeySignaturePairs) != len(afterInput.PubKeySignaturePairs) {
+			return nil, errors.Errorf("input %d has a different number of public keys", i)
+		}
+		for j, afterPair := range afterInput.PubKeySignaturePairs {
+			if afterPair.Signature == nil || beforeInput.PubKeySignaturePairs[j].Signature != nil {
+				continue
+			}
+			signatures = append(signatures, &Signature{
+				InputIndex: uint32(i),
+				PublicKey:  afterPair.ExtendedPublicKey,
+				Signature:  hex.EncodeToString(afterPair.Signature),
+			})
+		}
+	}
+	return signatures, nil
+}
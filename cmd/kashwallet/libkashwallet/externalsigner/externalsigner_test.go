@@ -0,0 +1,189 @@
+package externalsigner_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/externalsigner"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/serialization"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+)
+
+// testSignerMnemonicEnv makes the test binary act as an external signer
+// holding the given mnemonic, instead of running the tests.
+const testSignerMnemonicEnv = "KASHWALLET_TEST_SIGNER_MNEMONIC"
+
+// testSignerTamperEnv makes the test external signer corrupt the signatures it returns
+const testSignerTamperEnv = "KASHWALLET_TEST_SIGNER_TAMPER"
+
+func TestMain(m *testing.M) {
+	mnemonic := os.Getenv(testSignerMnemonicEnv)
+	if mnemonic == "" {
+		os.Exit(m.Run())
+	}
+
+	params := &dagconfig.SimnetParams
+	err := externalsigner.Serve(os.Stdin, os.Stdout, func(request *externalsigner.Request) (*externalsigner.Response, error) {
+		if request.Command != externalsigner.CommandSign {
+			return nil, fmt.Errorf("unknown command '%s'", request.Command)
+		}
+		serializedPSTx, err := hex.DecodeString(request.Transaction)
+		if err != nil {
+			return nil, err
+		}
+		signedSerializedPSTx, err := libkashwallet.Sign(params, []string{mnemonic}, serializedPSTx, request.ECDSA)
+		if err != nil {
+			return nil, err
+		}
+		before, err := serialization.DeserializePartiallySignedTransaction(serializedPSTx)
+		if err != nil {
+			return nil, err
+		}
+		after, err := serialization.DeserializePartiallySignedTransaction(signedSerializedPSTx)
+		if err != nil {
+			return nil, err
+		}
+		signatures, err := externalsigner.AddedSignatures(before, after)
+		if err != nil {
+			return nil, err
+		}
+		if os.Getenv(testSignerTamperEnv) != "" {
+			for _, signature := range signatures {
+				signatureBytes, err := hex.DecodeString(signature.Signature)
+				if err != nil {
+					return nil, err
+				}
+				// The last byte is the hash type, so the one before it is corrupted
+				signatureBytes[len(signatureBytes)-2] ^= 1
+				signature.Signature = hex.EncodeToString(signatureBytes)
+			}
+		}
+		return &externalsigner.Response{Signatures: signatures}, nil
+	})
+	if err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestSign(t *testing.T) {
+	params := &dagconfig.SimnetParams
+	mnemonic, err := libkashwallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	publicKey, err := libkashwallet.MasterPublicKeyFromMnemonic(params, mnemonic, false)
+	if err != nil {
+		t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+	}
+
+	const path = "m/0/1"
+	address, err := libkashwallet.Address(params, []string{publicKey}, 1, path, false)
+	if err != nil {
+		t.Fatalf("Address: %+v", err)
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %+v", err)
+	}
+	unsignedTransaction, err := libkashwallet.CreateUnsignedTransaction([]string{publicKey}, 1,
+		[]*libkashwallet.Payment{{Address: address, Amount: 10}},
+		[]*libkashwallet.UTXO{{
+			Outpoint:       &externalapi.DomainOutpoint{Index: 0},
+			UTXOEntry:      utxo.NewUTXOEntry(100, scriptPublicKey, false, 0),
+			DerivationPath: path,
+		}})
+	if err != nil {
+		t.Fatalf("CreateUnsignedTransaction: %+v", err)
+	}
+
+	err = os.Setenv(testSignerMnemonicEnv, mnemonic)
+	if err != nil {
+		t.Fatalf("Setenv: %+v", err)
+	}
+	defer os.Unsetenv(testSignerMnemonicEnv)
+
+	signer, err := externalsigner.New(os.Args[0])
+	if err != nil {
+		t.Fatalf("New: %+v", err)
+	}
+	signedTransaction, err := signer.Sign(params, unsignedTransaction, false)
+	if err != nil {
+		t.Fatalf("Sign: %+v", err)
+	}
+
+	isFullySigned, err := libkashwallet.IsTransactionFullySigned(signedTransaction)
+	if err != nil {
+		t.Fatalf("IsTransactionFullySigned: %+v", err)
+	}
+	if !isFullySigned {
+		t.Fatalf("The transaction is expected to be fully signed")
+	}
+	_, err = libkashwallet.ExtractTransaction(signedTransaction, false)
+	if err != nil {
+		t.Fatalf("ExtractTransaction: %+v", err)
+	}
+
+	// A signer that doesn't hold the transaction keys is expected to fail
+	otherMnemonic, err := libkashwallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	err = os.Setenv(testSignerMnemonicEnv, otherMnemonic)
+	if err != nil {
+		t.Fatalf("Setenv: %+v", err)
+	}
+	_, err = signer.Sign(params, unsignedTransaction, false)
+	if err == nil || !strings.Contains(err.Error(), "Public key doesn't match") {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	// A signature that doesn't verify is rejected
+	err = os.Setenv(testSignerMnemonicEnv, mnemonic)
+	if err != nil {
+		t.Fatalf("Setenv: %+v", err)
+	}
+	err = os.Setenv(testSignerTamperEnv, "1")
+	if err != nil {
+		t.Fatalf("Setenv: %+v", err)
+	}
+	defer os.Unsetenv(testSignerTamperEnv)
+	_, err = signer.Sign(params, unsignedTransaction, false)
+	if err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+}
+
+func TestServeUnsupportedVersion(t *testing.T) {
+	requestBytes, err := json.Marshal(&externalsigner.Request{Version: externalsigner.ProtocolVersion + 1})
+	if err != nil {
+		t.Fatalf("Marshal: %+v", err)
+	}
+
+	var out bytes.Buffer
+	err = externalsigner.Serve(bytes.NewReader(requestBytes), &out, func(*externalsigner.Request) (*externalsigner.Response, error) {
+		t.Fatalf("The handler is not expected to be called")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("Serve: %+v", err)
+	}
+
+	response := &externalsigner.Response{}
+	err = json.Unmarshal(out.Bytes(), response)
+	if err != nil {
+		t.Fatalf("Unmarshal: %+v", err)
+	}
+	if !strings.Contains(response.Error, "unsupported protocol version") {
+		t.Fatalf("Unexpected response error: %s", response.Error)
+	}
+}
@@ -0,0 +1,70 @@
+package externalsigner
+
+// ProtocolVersion is the version of the external signer protocol implemented
+// by this package. A signer must reject requests with a version it doesn't know.
+const ProtocolVersion = 1
+
+const (
+	// CommandSign asks the signer to sign a partially signed transaction
+	CommandSign = "sign"
+
+	// CommandGetPublicKeys asks the signer for the public keys it holds
+	CommandGetPublicKeys = "getpublickeys"
+)
+
+// Request is a single request sent to an external signer.
+// It is written as JSON to the signer's stdin, which is then closed.
+type Request struct {
+	Version uint32 `json:"version"`
+	Command string `json:"command"`
+	Network string `json:"network"`
+	ECDSA   bool   `json:"ecdsa,omitempty"`
+
+	// Transaction is the hex encoded serialized PartiallySignedTransaction to sign.
+	// Only set for CommandSign.
+	Transaction string `json:"transaction,omitempty"`
+
+	// Inputs describe the keys that are expected to sign every input of Transaction.
+	// Only set for CommandSign.
+	Inputs []*InputKeys `json:"inputs,omitempty"`
+}
+
+// InputKeys describes the keys that may sign a single transaction input
+type InputKeys struct {
+	Index uint32 `json:"index"`
+
+	// DerivationPath is informational only: it's the path from the master keys at which
+	// PublicKeys were derived, which a signer may use to find its own private key.
+	DerivationPath string `json:"derivationPath"`
+
+	MinimumSignatures uint32 `json:"minimumSignatures"`
+
+	// PublicKeys are the signing public keys of the input, already derived from the
+	// cosigners' extended public keys. They're serialized extended keys at DerivationPath,
+	// and must not be derived any further. Inputs that are locked to a plain key (e.g. when
+	// sweeping) have an empty DerivationPath and carry the hex encoded public key itself.
+	PublicKeys []string `json:"publicKeys"`
+}
+
+// Response is the response of an external signer to a single Request.
+// It is read as JSON from the signer's stdout.
+type Response struct {
+	// Error is set if the signer failed to handle the request
+	Error string `json:"error,omitempty"`
+
+	// Signatures are the signatures made by the signer. Set for CommandSign.
+	Signatures []*Signature `json:"signatures,omitempty"`
+
+	// ExtendedPublicKeys are the master extended public keys of the signer. Set for CommandGetPublicKeys.
+	ExtendedPublicKeys []string `json:"extendedPublicKeys,omitempty"`
+
+	// PublicKey is the hex encoded plain public key of the signer, if it has one. Set for CommandGetPublicKeys.
+	PublicKey string `json:"publicKey,omitempty"`
+}
+
+// Signature is a signature made by an external signer on a single input
+type Signature struct {
+	InputIndex uint32 `json:"inputIndex"`
+	PublicKey  string `json:"publicKey"`
+	Signature  string `json:"signature"`
+}
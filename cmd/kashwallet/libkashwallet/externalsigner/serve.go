@@ -0,0 +1,37 @@
+package externalsigner
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Handler handles a single Request on the signer side
+type Handler func(request *Request) (*Response, error)
+
+// Serve implements the signer side of the protocol: it reads a single Request from reader,
+// handles it with handler and writes the Response to writer. Errors returned from handler
+// are reported to the caller of the signer in Response.Error.
+func Serve(reader io.Reader, writer io.Writer, handler Handler) error {
+	request := &Request{}
+	err := json.NewDecoder(reader).Decode(request)
+	if err != nil {
+		return writeResponse(writer, &Response{Error: errors.Wrap(err, "malformed request").Error()})
+	}
+	if request.Version != ProtocolVersion {
+		return writeResponse(writer, &Response{
+			Error: errors.Errorf("unsupported protocol version %d (expected %d)", request.Version, ProtocolVersion).Error(),
+		})
+	}
+
+	response, err := handler(request)
+	if err != nil {
+		return writeResponse(writer, &Response{Error: err.Error()})
+	}
+	return writeResponse(writer, response)
+}
+
+func writeResponse(writer io.Writer, response *Response) error {
+	return json.NewEncoder(writer).Encode(response)
+}
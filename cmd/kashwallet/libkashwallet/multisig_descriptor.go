@@ -0,0 +1,355 @@
+package libkashwallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/bip32"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/pkg/errors"
+)
+
+// MultisigDescriptorVersion is the version of the multisig setup descriptor format
+const MultisigDescriptorVersion = 1
+
+// multisigDescriptorAddressesPerCosigner is the number of receive addresses of every cosigner
+// that are recorded in a complete descriptor, so that all the parties can compare them
+const multisigDescriptorAddressesPerCosigner = 3
+
+const multisigDescriptorDomain = "kashwallet multisig descriptor"
+
+// MultisigDescriptor describes the setup of a multisig wallet. It's passed between the
+// cosigners, each of whom adds their extended public key, and once all the keys are in, signs it.
+type MultisigDescriptor struct {
+	Version           uint32 `json:"version"`
+	Network           string `json:"network"`
+	MinimumSignatures uint32 `json:"minimumSignatures"`
+	NumCosigners      uint32 `json:"numCosigners"`
+	ECDSA             bool   `json:"ecdsa"`
+	// ExtendedPublicKeys are ordered by the time the cosigners joined
+	ExtendedPublicKeys []string `json:"extendedPublicKeys"`
+	// Addresses holds the first receive addresses of every cosigner index, once the descriptor is complete
+	Addresses [][]string `json:"addresses,omitempty"`
+	// Signatures maps extended public keys to their hex encoded Schnorr signature on the descriptor
+	Signatures map[string]string `json:"signatures,omitempty"`
+}
+
+// NewMultisigDescriptor returns a descriptor of a minimumSignatures-of-numCosigners wallet that has no cosigners yet
+func NewMultisigDescriptor(params *dagconfig.Params, minimumSignatures, numCosigners uint32, ecdsa bool) (
+	*MultisigDescriptor, error) {
+
+	descriptor := &MultisigDescriptor{
+		Version:            MultisigDescriptorVersion,
+		Network:            params.Name,
+		MinimumSignatures:  minimumSignatures,
+		NumCosigners:       numCosigners,
+		ECDSA:              ecdsa,
+		ExtendedPublicKeys: []string{},
+	}
+	err := descriptor.validateParameters(params)
+	if err != nil {
+		return nil, err
+	}
+	return descriptor, nil
+}
+
+// ParseMultisigDescriptor parses the given serialized descriptor and validates it, including its
+// addresses and signatures
+func ParseMultisigDescriptor(params *dagconfig.Params, serializedDescriptor []byte) (*MultisigDescriptor, error) {
+	descriptor := &MultisigDescriptor{}
+	decoder := json.NewDecoder(bytes.NewReader(serializedDescriptor))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(descriptor)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing the multisig descriptor")
+	}
+	err = descriptor.Validate(params)
+	if err != nil {
+		return nil, err
+	}
+	return descriptor, nil
+}
+
+// Serialize serializes the descriptor
+func (d *MultisigDescriptor) Serialize() ([]byte, error) {
+	serializedDescriptor, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return append(serializedDescriptor, '\n'), nil
+}
+
+func (d *MultisigDescriptor) validateParameters(params *dagconfig.Params) error {
+	if d.Version != MultisigDescriptorVersion {
+		return errors.Errorf("unsupported multisig descriptor version %d", d.Version)
+	}
+	if d.Network != params.Name {
+		return errors.Errorf("the multisig descriptor is for network %s rather than %s", d.Network, params.Name)
+	}
+	if d.NumCosigners < 2 {
+		return errors.Errorf("a multisig wallet must have at least 2 cosigners, but the descriptor has %d",
+			d.NumCosigners)
+	}
+	if d.MinimumSignatures < 1 || d.MinimumSignatures > d.NumCosigners {
+		return errors.Errorf("the minimum number of signatures must be between 1 and %d, but the descriptor has %d",
+			d.NumCosigners, d.MinimumSignatures)
+	}
+	return nil
+}
+
+// Validate checks that the descriptor is well formed and belongs to the given network, that its
+// addresses match its extended public keys, and that all of its signatures are valid
+func (d *MultisigDescriptor) Validate(params *dagconfig.Params) error {
+	err := d.validateParameters(params)
+	if err != nil {
+		return err
+	}
+	if uint32(len(d.ExtendedPublicKeys)) > d.NumCosigners {
+		return errors.Errorf("the multisig descriptor has %d extended public keys but only %d cosigners",
+			len(d.ExtendedPublicKeys), d.NumCosigners)
+	}
+	seenExtendedPublicKeys := make(map[string]struct{}, len(d.ExtendedPublicKeys))
+	for _, extendedPublicKey := range d.ExtendedPublicKeys {
+		err := ValidateExtendedPublicKey(params, extendedPublicKey)
+		if err != nil {
+			return err
+		}
+		if _, ok := seenExtendedPublicKeys[extendedPublicKey]; ok {
+			return errors.Errorf("extended public key %s appears more than once in the multisig descriptor",
+				extendedPublicKey)
+		}
+		seenExtendedPublicKeys[extendedPublicKey] = struct{}{}
+	}
+
+	if !d.IsComplete() {
+		if len(d.Addresses) > 0 || len(d.Signatures) > 0 {
+			return errors.New("an incomplete multisig descriptor cannot have addresses or signatures")
+		}
+		return nil
+	}
+
+	addresses, err := d.deriveAddresses(params)
+	if err != nil {
+		return err
+	}
+	if !equalAddresses(d.Addresses, addresses) {
+		return errors.New("the addresses in the multisig descriptor don't match its extended public keys")
+	}
+	for extendedPublicKey, signature := range d.Signatures {
+		if _, ok := seenExtendedPublicKeys[extendedPublicKey]; !ok {
+			return errors.Errorf("the multisig descriptor is signed by %s, which is not one of its cosigners",
+				extendedPublicKey)
+		}
+		err := d.verifySignature(extendedPublicKey, signature)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsComplete returns whether all the cosigners added their extended public keys
+func (d *MultisigDescriptor) IsComplete() bool {
+	return uint32(len(d.ExtendedPublicKeys)) == d.NumCosigners
+}
+
+// IsFullySigned returns whether all the cosigners signed the descriptor
+func (d *MultisigDescriptor) IsFullySigned() bool {
+	return d.IsComplete() && uint32(len(d.Signatures)) == d.NumCosigners
+}
+
+// IsSignedBy returns whether the given extended public key signed the descriptor
+func (d *MultisigDescriptor) IsSignedBy(extendedPublicKey string) bool {
+	_, ok := d.Signatures[extendedPublicKey]
+	return ok
+}
+
+// AddExtendedPublicKey adds a cosigner to the descriptor. The addresses are recorded once the last
+// cosigner is added.
+func (d *MultisigDescriptor) AddExtendedPublicKey(params *dagconfig.Params, extendedPublicKey string) error {
+	if d.IsComplete() {
+		return errors.Errorf("all the %d cosigners already joined the multisig descriptor", d.NumCosigners)
+	}
+	err := ValidateExtendedPublicKey(params, extendedPublicKey)
+	if err != nil {
+		return err
+	}
+	for _, existingExtendedPublicKey := range d.ExtendedPublicKeys {
+		if existingExtendedPublicKey == extendedPublicKey {
+			return errors.Errorf("extended public key %s already joined the multisig descriptor", extendedPublicKey)
+		}
+	}
+
+	d.ExtendedPublicKeys = append(d.ExtendedPublicKeys, extendedPublicKey)
+	if d.IsComplete() {
+		d.Addresses, err = d.deriveAddresses(params)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sign signs the descriptor with the multisig key of the given mnemonic, which must be one of its cosigners
+func (d *MultisigDescriptor) Sign(params *dagconfig.Params, mnemonic string) error {
+	if !d.IsComplete() {
+		return errors.Errorf("only %d of the %d cosigners joined the multisig descriptor",
+			len(d.ExtendedPublicKeys), d.NumCosigners)
+	}
+
+	extendedKey, err := extendedKeyFromMnemonicAndPath(mnemonic, defaultPath(true), params)
+	if err != nil {
+		return err
+	}
+	extendedPublicKey, err := extendedKey.Public()
+	if err != nil {
+		return err
+	}
+	if !d.hasExtendedPublicKey(extendedPublicKey.String()) {
+		return errors.New("the mnemonic doesn't belong to any of the cosigners of the multisig descriptor")
+	}
+
+	keyPair, err := extendedKey.PrivateKey().ToSchnorr()
+	if err != nil {
+		return err
+	}
+	hash := d.hash()
+	signature, err := keyPair.SchnorrSign(&hash)
+	if err != nil {
+		return err
+	}
+
+	if d.Signatures == nil {
+		d.Signatures = make(map[string]string)
+	}
+	d.Signatures[extendedPublicKey.String()] = hex.EncodeToString(signature.Serialize()[:])
+	return nil
+}
+
+func (d *MultisigDescriptor) verifySignature(extendedPublicKeyString string, signatureHex string) error {
+	signatureBytes, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return errors.Wrapf(err, "the signature of %s is not valid hex", extendedPublicKeyString)
+	}
+	signature, err := secp256k1.DeserializeSchnorrSignatureFromSlice(signatureBytes)
+	if err != nil {
+		return errors.Wrapf(err, "the signature of %s is malformed", extendedPublicKeyString)
+	}
+
+	extendedPublicKey, err := bip32.DeserializeExtendedKey(extendedPublicKeyString)
+	if err != nil {
+		return err
+	}
+	publicKey, err := extendedPublicKey.PublicKey()
+	if err != nil {
+		return err
+	}
+	schnorrPublicKey, err := publicKey.ToSchnorr()
+	if err != nil {
+		return err
+	}
+
+	hash := d.hash()
+	if !schnorrPublicKey.SchnorrVerify(&hash, signature) {
+		return errors.Errorf("the signature of %s on the multisig descriptor is invalid", extendedPublicKeyString)
+	}
+	return nil
+}
+
+func (d *MultisigDescriptor) hasExtendedPublicKey(extendedPublicKey string) bool {
+	for _, existingExtendedPublicKey := range d.ExtendedPublicKeys {
+		if existingExtendedPublicKey == extendedPublicKey {
+			return true
+		}
+	}
+	return false
+}
+
+// hash is the digest the cosigners sign. It commits to everything the addresses of the wallet depend on.
+func (d *MultisigDescriptor) hash() secp256k1.Hash {
+	return multisigHash(d.Network, d.MinimumSignatures, d.ExtendedPublicKeys, d.ECDSA)
+}
+
+// Fingerprint returns a short identifier of the wallet described by the descriptor
+func (d *MultisigDescriptor) Fingerprint() string {
+	return MultisigFingerprint(d.Network, d.MinimumSignatures, d.ExtendedPublicKeys, d.ECDSA)
+}
+
+// MultisigFingerprint returns a short identifier of the multisig wallet with the given parameters. It
+// doesn't depend on the order of the extended public keys, so all the cosigners see the same fingerprint.
+func MultisigFingerprint(network string, minimumSignatures uint32, extendedPublicKeys []string, ecdsa bool) string {
+	hash := multisigHash(network, minimumSignatures, extendedPublicKeys, ecdsa)
+	return hex.EncodeToString(hash[:8])
+}
+
+func multisigHash(network string, minimumSignatures uint32, extendedPublicKeys []string, ecdsa bool) secp256k1.Hash {
+	sortedExtendedPublicKeys := make([]string, len(extendedPublicKeys))
+	copy(sortedExtendedPublicKeys, extendedPublicKeys)
+	sortPublicKeys(sortedExtendedPublicKeys)
+
+	hasher := sha256.New()
+	writeHashString := func(s string) {
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(len(s)))
+		hasher.Write(length[:])
+		hasher.Write([]byte(s))
+	}
+	writeHashString(multisigDescriptorDomain)
+	writeHashString(network)
+	writeHashString(fmt.Sprintf("%d-of-%d", minimumSignatures, len(sortedExtendedPublicKeys)))
+	if ecdsa {
+		writeHashString("ecdsa")
+	} else {
+		writeHashString("schnorr")
+	}
+	for _, extendedPublicKey := range sortedExtendedPublicKeys {
+		writeHashString(extendedPublicKey)
+	}
+
+	var hash secp256k1.Hash
+	copy(hash[:], hasher.Sum(nil))
+	return hash
+}
+
+// deriveAddresses returns the first receive addresses of every cosigner index
+func (d *MultisigDescriptor) deriveAddresses(params *dagconfig.Params) ([][]string, error) {
+	addresses := make([][]string, d.NumCosigners)
+	for cosignerIndex := uint32(0); cosignerIndex < d.NumCosigners; cosignerIndex++ {
+		addresses[cosignerIndex] = make([]string, multisigDescriptorAddressesPerCosigner)
+		for index := uint32(0); index < multisigDescriptorAddressesPerCosigner; index++ {
+			// Address sorts the keys in place, so it gets a copy to keep the join order
+			extendedPublicKeys := make([]string, len(d.ExtendedPublicKeys))
+			copy(extendedPublicKeys, d.ExtendedPublicKeys)
+
+			path := fmt.Sprintf("m/%d/%d/%d", cosignerIndex, ExternalKeychain, index)
+			address, err := Address(params, extendedPublicKeys, d.MinimumSignatures, path, d.ECDSA)
+			if err != nil {
+				return nil, err
+			}
+			addresses[cosignerIndex][index] = address.String()
+		}
+	}
+	return addresses, nil
+}
+
+func equalAddresses(a, b [][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if len(a[i]) != len(b[i]) {
+			return false
+		}
+		for j := range a[i] {
+			if a[i][j] != b[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package libkashwallet
+
+import (
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+)
+
+func TestMultisigDescriptor(t *testing.T) {
+	params := &dagconfig.DevnetParams
+
+	const numCosigners = 3
+	mnemonics := make([]string, numCosigners)
+	extendedPublicKeys := make([]string, numCosigners)
+	for i := range mnemonics {
+		var err error
+		mnemonics[i], err = CreateMnemonic()
+		if err != nil {
+			t.Fatalf("CreateMnemonic: %+v", err)
+		}
+		extendedPublicKeys[i], err = MasterPublicKeyFromMnemonic(params, mnemonics[i], true)
+		if err != nil {
+			t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+		}
+	}
+
+	_, err := NewMultisigDescriptor(params, 3, 2, false)
+	if err == nil {
+		t.Fatalf("Expected an error for more required signatures than cosigners")
+	}
+
+	descriptor, err := NewMultisigDescriptor(params, 2, numCosigners, false)
+	if err != nil {
+		t.Fatalf("NewMultisigDescriptor: %+v", err)
+	}
+
+	// The descriptor is passed between the cosigners in its serialized form
+	roundTrip := func(descriptor *MultisigDescriptor) *MultisigDescriptor {
+		serializedDescriptor, err := descriptor.Serialize()
+		if err != nil {
+			t.Fatalf("Serialize: %+v", err)
+		}
+		parsedDescriptor, err := ParseMultisigDescriptor(params, serializedDescriptor)
+		if err != nil {
+			t.Fatalf("ParseMultisigDescriptor: %+v", err)
+		}
+		return parsedDescriptor
+	}
+
+	for i, extendedPublicKey := range extendedPublicKeys {
+		err := descriptor.Sign(params, mnemonics[0])
+		if err == nil {
+			t.Fatalf("Expected an error when signing an incomplete descriptor")
+		}
+		err = descriptor.AddExtendedPublicKey(params, extendedPublicKey)
+		if err != nil {
+			t.Fatalf("AddExtendedPublicKey: %+v", err)
+		}
+		err = descriptor.AddExtendedPublicKey(params, extendedPublicKey)
+		if err == nil {
+			t.Fatalf("Expected an error when adding cosigner #%d twice", i+1)
+		}
+		descriptor = roundTrip(descriptor)
+	}
+	if !descriptor.IsComplete() {
+		t.Fatalf("Expected the descriptor to be complete")
+	}
+	if len(descriptor.Addresses) != numCosigners ||
+		len(descriptor.Addresses[0]) != multisigDescriptorAddressesPerCosigner {
+		t.Fatalf("Unexpected addresses %v", descriptor.Addresses)
+	}
+	expectedAddress, err := Address(params, extendedPublicKeys, 2, "m/1/0/0", false)
+	if err != nil {
+		t.Fatalf("Address: %+v", err)
+	}
+	if descriptor.Addresses[1][0] != expectedAddress.String() {
+		t.Fatalf("Expected address %s but got %s", expectedAddress, descriptor.Addresses[1][0])
+	}
+
+	foreignMnemonic, err := CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	err = descriptor.Sign(params, foreignMnemonic)
+	if err == nil {
+		t.Fatalf("Expected an error when signing with a mnemonic of a non-cosigner")
+	}
+
+	for i, mnemonic := range mnemonics {
+		if descriptor.IsFullySigned() {
+			t.Fatalf("The descriptor is fully signed after %d signatures", i)
+		}
+		err := descriptor.Sign(params, mnemonic)
+		if err != nil {
+			t.Fatalf("Sign: %+v", err)
+		}
+		descriptor = roundTrip(descriptor)
+	}
+	if !descriptor.IsFullySigned() {
+		t.Fatalf("Expected the descriptor to be fully signed")
+	}
+
+	reversedExtendedPublicKeys := []string{extendedPublicKeys[2], extendedPublicKeys[1], extendedPublicKeys[0]}
+	if descriptor.Fingerprint() != MultisigFingerprint(params.Name, 2, reversedExtendedPublicKeys, false) {
+		t.Fatalf("The fingerprint depends on the order of the extended public keys")
+	}
+	if descriptor.Fingerprint() == MultisigFingerprint(params.Name, 3, extendedPublicKeys, false) {
+		t.Fatalf("The fingerprint doesn't depend on the minimum number of signatures")
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(descriptor *MultisigDescriptor)
+	}{
+		{
+			name:   "different minimum signatures",
+			tamper: func(descriptor *MultisigDescriptor) { descriptor.MinimumSignatures = 3 },
+		},
+		{
+			name:   "different address",
+			tamper: func(descriptor *MultisigDescriptor) { descriptor.Addresses[0][0] = descriptor.Addresses[0][1] },
+		},
+		{
+			name: "swapped signatures",
+			tamper: func(descriptor *MultisigDescriptor) {
+				descriptor.Signatures[extendedPublicKeys[0]], descriptor.Signatures[extendedPublicKeys[1]] =
+					descriptor.Signatures[extendedPublicKeys[1]], descriptor.Signatures[extendedPublicKeys[0]]
+			},
+		},
+		{
+			name:   "different network",
+			tamper: func(descriptor *MultisigDescriptor) { descriptor.Network = dagconfig.MainnetParams.Name },
+		},
+	}
+	for _, test := range tests {
+		tamperedDescriptor := roundTrip(descriptor)
+		test.tamper(tamperedDescriptor)
+		serializedDescriptor, err := tamperedDescriptor.Serialize()
+		if err != nil {
+			t.Fatalf("Serialize: %+v", err)
+		}
+		_, err = ParseMultisigDescriptor(params, serializedDescriptor)
+		if err == nil {
+			t.Fatalf("%s: expected an error", test.name)
+		}
+	}
+}
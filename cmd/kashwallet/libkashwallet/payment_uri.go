@@ -0,0 +1,127 @@
+package libkashwallet
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
+	"github.com/Kash-Protocol/kashd/util"
+	"github.com/pkg/errors"
+)
+
+// PaymentURI is a payment request, encoded as a URI of the form
+// <address>?amount=<KSH>&label=<label>&message=<message>. The address includes its
+// prefix (e.g. kash:qz...), which serves as the URI scheme. All the parameters are optional.
+type PaymentURI struct {
+	Address util.Address
+	// Amount is in sompi. Zero means the amount is unspecified.
+	Amount  uint64
+	Label   string
+	Message string
+}
+
+// String returns the URI encoding of the payment request
+func (p *PaymentURI) String() string {
+	var parameters []string
+	if p.Amount > 0 {
+		parameters = append(parameters, "amount="+formatPaymentURIAmount(p.Amount))
+	}
+	if p.Label != "" {
+		parameters = append(parameters, "label="+escapePaymentURIParameter(p.Label))
+	}
+	if p.Message != "" {
+		parameters = append(parameters, "message="+escapePaymentURIParameter(p.Message))
+	}
+
+	uri := p.Address.String()
+	if len(parameters) > 0 {
+		uri += "?" + strings.Join(parameters, "&")
+	}
+	return uri
+}
+
+// ParsePaymentURI parses a payment URI of an address with the given prefix. As in BIP21, unknown
+// parameters are ignored, unless they start with "req-", which marks them as required.
+func ParsePaymentURI(uri string, prefix util.Bech32Prefix) (*PaymentURI, error) {
+	addressString, query, _ := strings.Cut(uri, "?")
+	address, err := util.DecodeAddress(addressString, prefix)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid address in payment URI")
+	}
+
+	parameters, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid parameters in payment URI")
+	}
+
+	paymentURI := &PaymentURI{Address: address}
+	for name, values := range parameters {
+		if len(values) != 1 {
+			return nil, errors.Errorf("the parameter %s appears more than once in the payment URI", name)
+		}
+		value := values[0]
+
+		switch name {
+		case "amount":
+			paymentURI.Amount, err = parsePaymentURIAmount(value)
+			if err != nil {
+				return nil, err
+			}
+		case "label":
+			paymentURI.Label = value
+		case "message":
+			paymentURI.Message = value
+		default:
+			if strings.HasPrefix(name, "req-") {
+				return nil, errors.Errorf("the payment URI requires the unsupported parameter %s", name)
+			}
+		}
+	}
+
+	return paymentURI, nil
+}
+
+// formatPaymentURIAmount formats an amount of sompi in KSH, without trailing zeros
+func formatPaymentURIAmount(amount uint64) string {
+	whole := amount / constants.SompiPerKaspa
+	fraction := amount % constants.SompiPerKaspa
+	if fraction == 0 {
+		return strconv.FormatUint(whole, 10)
+	}
+	return strings.TrimRight(fmt.Sprintf("%d.%08d", whole, fraction), "0")
+}
+
+// parsePaymentURIAmount parses an amount in KSH into sompi. It's parsed as
+// a decimal rather than as a float, so no precision is lost.
+func parsePaymentURIAmount(amountString string) (uint64, error) {
+	const maxDecimals = 8
+
+	wholeString, fractionString, hasFraction := strings.Cut(amountString, ".")
+	if wholeString == "" || (hasFraction && fractionString == "") || len(fractionString) > maxDecimals {
+		return 0, errors.Errorf("invalid amount %s in payment URI", amountString)
+	}
+
+	whole, err := strconv.ParseUint(wholeString, 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid amount %s in payment URI", amountString)
+	}
+	fraction := uint64(0)
+	if hasFraction {
+		fraction, err = strconv.ParseUint(fractionString+strings.Repeat("0", maxDecimals-len(fractionString)), 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("invalid amount %s in payment URI", amountString)
+		}
+	}
+
+	if whole > (^uint64(0)-fraction)/constants.SompiPerKaspa {
+		return 0, errors.Errorf("amount %s in payment URI is too large", amountString)
+	}
+	return whole*constants.SompiPerKaspa + fraction, nil
+}
+
+func escapePaymentURIParameter(value string) string {
+	// QueryEscape encodes spaces as '+', which BIP21 wallets don't all decode as a space
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
@@ -0,0 +1,71 @@
+package libkashwallet
+
+import (
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/util"
+)
+
+func TestPaymentURI(t *testing.T) {
+	address, err := util.NewAddressPublicKey(make([]byte, 32), util.Bech32PrefixKash)
+	if err != nil {
+		t.Fatalf("NewAddressPublicKey: %s", err)
+	}
+
+	tests := []struct {
+		paymentURI  *PaymentURI
+		expectedURI string
+	}{
+		{
+			paymentURI:  &PaymentURI{Address: address},
+			expectedURI: address.String(),
+		},
+		{
+			paymentURI:  &PaymentURI{Address: address, Amount: 150_000_000},
+			expectedURI: address.String() + "?amount=1.5",
+		},
+		{
+			paymentURI:  &PaymentURI{Address: address, Amount: 1, Label: "Coffee shop", Message: "Order #12 & tip"},
+			expectedURI: address.String() + "?amount=0.00000001&label=Coffee%20shop&message=Order%20%2312%20%26%20tip",
+		},
+	}
+
+	for _, test := range tests {
+		uri := test.paymentURI.String()
+		if uri != test.expectedURI {
+			t.Errorf("Expected URI %s, but got %s", test.expectedURI, uri)
+		}
+
+		parsed, err := ParsePaymentURI(uri, util.Bech32PrefixKash)
+		if err != nil {
+			t.Fatalf("ParsePaymentURI(%s): %s", uri, err)
+		}
+		if parsed.Address.String() != test.paymentURI.Address.String() || parsed.Amount != test.paymentURI.Amount ||
+			parsed.Label != test.paymentURI.Label || parsed.Message != test.paymentURI.Message {
+			t.Errorf("Parsing %s returned %+v, expected %+v", uri, parsed, test.paymentURI)
+		}
+	}
+
+	invalidURIs := []string{
+		address.String() + "?amount=1.123456789",
+		address.String() + "?amount=-1",
+		address.String() + "?amount=1.",
+		address.String() + "?amount=1&amount=2",
+		address.String() + "?req-somethingnew=1",
+		"kash:notanaddress?amount=1",
+	}
+	for _, uri := range invalidURIs {
+		_, err := ParsePaymentURI(uri, util.Bech32PrefixKash)
+		if err == nil {
+			t.Errorf("ParsePaymentURI unexpectedly parsed %s", uri)
+		}
+	}
+
+	parsed, err := ParsePaymentURI(address.String()+"?amount=2&somethingnew=1", util.Bech32PrefixKash)
+	if err != nil {
+		t.Fatalf("ParsePaymentURI with an unknown optional parameter: %s", err)
+	}
+	if parsed.Amount != 200_000_000 {
+		t.Errorf("Expected an amount of 200000000 sompi, but got %d", parsed.Amount)
+	}
+}
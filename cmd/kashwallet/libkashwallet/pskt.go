@@ -0,0 +1,206 @@
+package libkashwallet
+
+import (
+	"bytes"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/serialization"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+// CreatePSKT implements the PSKT creator role: it wraps an unsigned transaction, as
+// returned from CreateUnsignedTransaction, in a PSKT with the given global metadata.
+func CreatePSKT(params *dagconfig.Params, ecdsa bool, unsignedTransaction []byte) ([]byte, error) {
+	if serialization.IsPSKT(unsignedTransaction) {
+		return nil, errors.New("the transaction is already a PSKT")
+	}
+	partiallySignedTransaction, err := serialization.DeserializePartiallySignedTransaction(unsignedTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return serialization.SerializePSKT(&serialization.PSKT{
+		Version: serialization.CurrentPSKTVersion,
+		Global: &serialization.PSKTGlobal{
+			Network:     params.Name,
+			ECDSA:       ecdsa,
+			Proprietary: map[string][]byte{},
+		},
+		PartiallySignedTransaction: partiallySignedTransaction,
+	})
+}
+
+// UpdatePSKTInput implements the PSKT updater role: it sets the spent UTXO of the given input,
+// along with the keys that may sign it, which signers require in order to sign the input.
+func UpdatePSKTInput(serializedPSKT []byte, inputIndex int, extendedPublicKeys []string, minimumSignatures uint32,
+	utxo *UTXO) ([]byte, error) {
+
+	pskt, err := serialization.DeserializePSKT(serializedPSKT)
+	if err != nil {
+		return nil, err
+	}
+	partiallySignedTransaction := pskt.PartiallySignedTransaction
+	if inputIndex < 0 || inputIndex >= len(partiallySignedTransaction.PartiallySignedInputs) {
+		return nil, errors.Errorf("input %d is out of range", inputIndex)
+	}
+	if !partiallySignedTransaction.Tx.Inputs[inputIndex].PreviousOutpoint.Equal(utxo.Outpoint) {
+		return nil, errors.Errorf("input %d doesn't spend the outpoint %s", inputIndex, utxo.Outpoint)
+	}
+
+	input := partiallySignedTransaction.PartiallySignedInputs[inputIndex]
+	if isInputFinalized(partiallySignedTransaction, inputIndex) || numSignatures(input) > 0 {
+		return nil, errors.Errorf("input %d is already signed, and can no longer be updated", inputIndex)
+	}
+
+	pubKeySignaturePairs, err := emptyPubKeySignaturePairs(extendedPublicKeys, utxo.DerivationPath)
+	if err != nil {
+		return nil, err
+	}
+	input.PrevOutput = &externalapi.DomainTransactionOutput{
+		Value:           utxo.UTXOEntry.Amount(),
+		ScriptPublicKey: utxo.UTXOEntry.ScriptPublicKey(),
+	}
+	input.MinimumSignatures = minimumSignatures
+	input.PubKeySignaturePairs = pubKeySignaturePairs
+	input.DerivationPath = utxo.DerivationPath
+
+	return serialization.SerializePSKT(pskt)
+}
+
+// CombinePSKTs implements the PSKT combiner role: it merges the signatures of several
+// copies of the same PSKT, each signed by a different subset of the cosigners.
+func CombinePSKTs(serializedPSKTs [][]byte) ([]byte, error) {
+	if len(serializedPSKTs) == 0 {
+		return nil, errors.New("no PSKTs to combine")
+	}
+
+	combined, err := serialization.DeserializePSKT(serializedPSKTs[0])
+	if err != nil {
+		return nil, err
+	}
+	for i, serializedPSKT := range serializedPSKTs[1:] {
+		pskt, err := serialization.DeserializePSKT(serializedPSKT)
+		if err != nil {
+			return nil, err
+		}
+		err = combinePSKT(combined, pskt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot combine PSKT #%d", i+2)
+		}
+	}
+
+	return serialization.SerializePSKT(combined)
+}
+
+func combinePSKT(combined, pskt *serialization.PSKT) error {
+	if combined.Version != pskt.Version {
+		return errors.Errorf("mismatching versions %d and %d", combined.Version, pskt.Version)
+	}
+	if combined.Global != nil {
+		if combined.Global.Network != pskt.Global.Network || combined.Global.ECDSA != pskt.Global.ECDSA {
+			return errors.New("mismatching global metadata")
+		}
+		for key, value := range pskt.Global.Proprietary {
+			existingValue, ok := combined.Global.Proprietary[key]
+			if ok && !bytes.Equal(existingValue, value) {
+				return errors.Errorf("mismatching values for the proprietary key %s", key)
+			}
+			combined.Global.Proprietary[key] = value
+		}
+	}
+
+	combinedTransaction := combined.PartiallySignedTransaction
+	transaction := pskt.PartiallySignedTransaction
+	if !consensushashing.TransactionID(combinedTransaction.Tx).Equal(consensushashing.TransactionID(transaction.Tx)) {
+		return errors.New("the PSKTs are of different transactions")
+	}
+
+	for i, combinedInput := range combinedTransaction.PartiallySignedInputs {
+		input := transaction.PartiallySignedInputs[i]
+		if combinedInput.MinimumSignatures != input.MinimumSignatures ||
+			combinedInput.DerivationPath != input.DerivationPath ||
+			!combinedInput.PrevOutput.Equal(input.PrevOutput) ||
+			len(combinedInput.PubKeySignaturePairs) != len(input.PubKeySignaturePairs) {
+
+			return errors.Errorf("mismatching data for input %d", i)
+		}
+
+		for j, combinedPair := range combinedInput.PubKeySignaturePairs {
+			pair := input.PubKeySignaturePairs[j]
+			if combinedPair.ExtendedPublicKey != pair.ExtendedPublicKey {
+				return errors.Errorf("mismatching public keys for input %d", i)
+			}
+			// Schnorr signatures aren't deterministic, so two different signatures
+			// of the same key are both valid. We keep the first one.
+			if combinedPair.Signature == nil && pair.Signature != nil {
+				combinedPair.Signature = append([]byte{}, pair.Signature...)
+			}
+		}
+
+		if !isInputFinalized(combinedTransaction, i) && isInputFinalized(transaction, i) {
+			combinedTransaction.Tx.Inputs[i].SignatureScript = append([]byte{}, transaction.Tx.Inputs[i].SignatureScript...)
+		}
+	}
+
+	return nil
+}
+
+// FinalizePSKT implements the PSKT finalizer role: it builds the signature script of every
+// input out of the signatures collected so far. All inputs must be sufficiently signed.
+func FinalizePSKT(serializedPSKT []byte) ([]byte, error) {
+	pskt, err := serialization.DeserializePSKT(serializedPSKT)
+	if err != nil {
+		return nil, err
+	}
+	if pskt.Version == serialization.LegacyPSKTVersion {
+		return nil, errors.New("cannot finalize a legacy partially signed transaction, since it doesn't " +
+			"record whether it's signed with ECDSA")
+	}
+	if !isTransactionFullySigned(pskt.PartiallySignedTransaction) {
+		return nil, errors.New("the transaction is not fully signed")
+	}
+
+	finalTransaction, err := ExtractTransactionDeserialized(pskt.PartiallySignedTransaction.Clone(), pskt.Global.ECDSA)
+	if err != nil {
+		return nil, err
+	}
+	for i, input := range pskt.PartiallySignedTransaction.Tx.Inputs {
+		input.SignatureScript = finalTransaction.Inputs[i].SignatureScript
+	}
+
+	return serialization.SerializePSKT(pskt)
+}
+
+// ExtractPSKT implements the PSKT extractor role: it returns the network-ready
+// transaction of a PSKT after all of its inputs were finalized.
+func ExtractPSKT(serializedPSKT []byte) (*externalapi.DomainTransaction, error) {
+	pskt, err := serialization.DeserializePSKT(serializedPSKT)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pskt.PartiallySignedTransaction.Tx.Inputs {
+		if !isInputFinalized(pskt.PartiallySignedTransaction, i) {
+			return nil, errors.Errorf("input %d is not finalized", i)
+		}
+	}
+	return pskt.PartiallySignedTransaction.Tx, nil
+}
+
+func checkPSKTNetwork(params *dagconfig.Params, pskt *serialization.PSKT) error {
+	if pskt.Global != nil && pskt.Global.Network != params.Name {
+		return errors.Errorf("the PSKT is for network %s, but the wallet is on %s", pskt.Global.Network, params.Name)
+	}
+	return nil
+}
+
+func numSignatures(input *serialization.PartiallySignedInput) int {
+	count := 0
+	for _, pair := range input.PubKeySignaturePairs {
+		if pair.Signature != nil {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,312 @@
+package libkashwallet_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/serialization"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/serialization/protoserialization"
+	"github.com/Kash-Protocol/kashd/domain/consensus"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/testutils"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPSKTRoles(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		params := &consensusConfig.Params
+		forSchnorrAndECDSA(t, func(t *testing.T, ecdsa bool) {
+			consensusConfig.BlockCoinbaseMaturity = 0
+			tc, teardown, err := consensus.NewFactory().NewTestConsensus(consensusConfig, "TestPSKTRoles")
+			if err != nil {
+				t.Fatalf("Error setting up tc: %+v", err)
+			}
+			defer teardown(false)
+
+			const numKeys = 3
+			mnemonics := make([]string, numKeys)
+			publicKeys := make([]string, numKeys)
+			for i := 0; i < numKeys; i++ {
+				mnemonics[i], err = libkashwallet.CreateMnemonic()
+				if err != nil {
+					t.Fatalf("CreateMnemonic: %+v", err)
+				}
+				publicKeys[i], err = libkashwallet.MasterPublicKeyFromMnemonic(params, mnemonics[i], true)
+				if err != nil {
+					t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+				}
+			}
+
+			const minimumSignatures = 2
+			const path = "m/1/2/3"
+			address, err := libkashwallet.Address(params, publicKeys, minimumSignatures, path, ecdsa)
+			if err != nil {
+				t.Fatalf("Address: %+v", err)
+			}
+			scriptPublicKey, err := txscript.PayToAddrScript(address)
+			if err != nil {
+				t.Fatalf("PayToAddrScript: %+v", err)
+			}
+
+			fundingBlockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{consensusConfig.GenesisHash},
+				&externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey}, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			block1Hash, _, err := tc.AddBlock([]*externalapi.DomainHash{fundingBlockHash}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			block1, _, err := tc.GetBlock(block1Hash)
+			if err != nil {
+				t.Fatalf("GetBlock: %+v", err)
+			}
+			block1TxOut := block1.Transactions[0].Outputs[0]
+
+			// The transaction is created with stale input data, which the updater then fixes
+			spentUTXO := &libkashwallet.UTXO{
+				Outpoint: &externalapi.DomainOutpoint{
+					TransactionID: *consensushashing.TransactionID(block1.Transactions[0]),
+					Index:         0,
+				},
+				UTXOEntry:      utxo.NewUTXOEntry(block1TxOut.Value, block1TxOut.ScriptPublicKey, true, 0),
+				DerivationPath: path,
+			}
+			staleUTXO := &libkashwallet.UTXO{
+				Outpoint:       spentUTXO.Outpoint,
+				UTXOEntry:      utxo.NewUTXOEntry(block1TxOut.Value, block1TxOut.ScriptPublicKey, true, 0),
+				DerivationPath: "m/0/0",
+			}
+			unsignedTransaction, err := libkashwallet.CreateUnsignedTransaction(publicKeys, minimumSignatures,
+				[]*libkashwallet.Payment{{Address: address, Amount: 10}}, []*libkashwallet.UTXO{staleUTXO})
+			if err != nil {
+				t.Fatalf("CreateUnsignedTransaction: %+v", err)
+			}
+
+			// Creator
+			pskt, err := libkashwallet.CreatePSKT(params, ecdsa, unsignedTransaction)
+			if err != nil {
+				t.Fatalf("CreatePSKT: %+v", err)
+			}
+			if !serialization.IsPSKT(pskt) {
+				t.Fatalf("CreatePSKT didn't return a PSKT")
+			}
+			_, err = libkashwallet.CreatePSKT(params, ecdsa, pskt)
+			if err == nil {
+				t.Fatalf("CreatePSKT unexpectedly succeeded on a PSKT")
+			}
+
+			// Updater
+			pskt, err = libkashwallet.UpdatePSKTInput(pskt, 0, publicKeys, minimumSignatures, spentUTXO)
+			if err != nil {
+				t.Fatalf("UpdatePSKTInput: %+v", err)
+			}
+			deserialized, err := serialization.DeserializePSKT(pskt)
+			if err != nil {
+				t.Fatalf("DeserializePSKT: %+v", err)
+			}
+			if deserialized.PartiallySignedTransaction.PartiallySignedInputs[0].DerivationPath != path {
+				t.Fatalf("The updater didn't set the derivation path")
+			}
+
+			// Signers, each signing a copy of the PSKT on their own
+			signedBy0, err := libkashwallet.Sign(params, mnemonics[:1], pskt, ecdsa)
+			if err != nil {
+				t.Fatalf("Sign: %+v", err)
+			}
+			signedBy2, err := libkashwallet.Sign(params, mnemonics[2:], pskt, ecdsa)
+			if err != nil {
+				t.Fatalf("Sign: %+v", err)
+			}
+			if !serialization.IsPSKT(signedBy0) || !serialization.IsPSKT(signedBy2) {
+				t.Fatalf("Sign is expected to keep the PSKT format")
+			}
+			_, err = libkashwallet.UpdatePSKTInput(signedBy0, 0, publicKeys, minimumSignatures, spentUTXO)
+			if err == nil {
+				t.Fatalf("UpdatePSKTInput unexpectedly succeeded on a signed input")
+			}
+			_, err = libkashwallet.FinalizePSKT(signedBy0)
+			if err == nil {
+				t.Fatalf("FinalizePSKT unexpectedly succeeded on a partially signed PSKT")
+			}
+
+			// Combiner
+			combined, err := libkashwallet.CombinePSKTs([][]byte{signedBy0, signedBy2})
+			if err != nil {
+				t.Fatalf("CombinePSKTs: %+v", err)
+			}
+			isFullySigned, err := libkashwallet.IsTransactionFullySigned(combined)
+			if err != nil {
+				t.Fatalf("IsTransactionFullySigned: %+v", err)
+			}
+			if !isFullySigned {
+				t.Fatalf("The combined PSKT is expected to be fully signed")
+			}
+
+			// Extractor, before finalization
+			_, err = libkashwallet.ExtractPSKT(combined)
+			if err == nil || !strings.Contains(err.Error(), "not finalized") {
+				t.Fatalf("ExtractPSKT unexpectedly succeeded on a PSKT that isn't finalized: %+v", err)
+			}
+
+			// Finalizer
+			finalized, err := libkashwallet.FinalizePSKT(combined)
+			if err != nil {
+				t.Fatalf("FinalizePSKT: %+v", err)
+			}
+
+			// Extractor
+			transaction, err := libkashwallet.ExtractPSKT(finalized)
+			if err != nil {
+				t.Fatalf("ExtractPSKT: %+v", err)
+			}
+
+			_, virtualChangeSet, err := tc.AddBlock([]*externalapi.DomainHash{block1Hash}, nil,
+				[]*externalapi.DomainTransaction{transaction})
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+			addedUTXO := &externalapi.DomainOutpoint{
+				TransactionID: *consensushashing.TransactionID(transaction),
+				Index:         0,
+			}
+			if !virtualChangeSet.VirtualUTXODiff.ToAdd().Contains(addedUTXO) {
+				t.Fatalf("Transaction wasn't accepted in the DAG")
+			}
+		})
+	})
+}
+
+func TestPSKTSerializationRoundTrip(t *testing.T) {
+	params := &dagconfig.SimnetParams
+	mnemonic, err := libkashwallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	publicKey, err := libkashwallet.MasterPublicKeyFromMnemonic(params, mnemonic, false)
+	if err != nil {
+		t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+	}
+	address, err := libkashwallet.Address(params, []string{publicKey}, 1, "m/0/0", false)
+	if err != nil {
+		t.Fatalf("Address: %+v", err)
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %+v", err)
+	}
+	legacyTransaction, err := libkashwallet.CreateUnsignedTransaction([]string{publicKey}, 1,
+		[]*libkashwallet.Payment{{Address: address, Amount: 10}},
+		[]*libkashwallet.UTXO{{
+			Outpoint:       &externalapi.DomainOutpoint{Index: 1},
+			UTXOEntry:      utxo.NewUTXOEntry(100, scriptPublicKey, false, 0),
+			DerivationPath: "m/0/0",
+		}})
+	if err != nil {
+		t.Fatalf("CreateUnsignedTransaction: %+v", err)
+	}
+
+	// The legacy format is kept as is
+	legacy, err := serialization.DeserializePSKT(legacyTransaction)
+	if err != nil {
+		t.Fatalf("DeserializePSKT: %+v", err)
+	}
+	if legacy.Version != serialization.LegacyPSKTVersion {
+		t.Fatalf("Unexpected version %d for a legacy transaction", legacy.Version)
+	}
+	reserializedLegacy, err := serialization.SerializePSKT(legacy)
+	if err != nil {
+		t.Fatalf("SerializePSKT: %+v", err)
+	}
+	if !bytes.Equal(reserializedLegacy, legacyTransaction) {
+		t.Fatalf("The legacy transaction changed after a round trip")
+	}
+	_, err = libkashwallet.FinalizePSKT(legacyTransaction)
+	if err == nil {
+		t.Fatalf("FinalizePSKT unexpectedly succeeded on a legacy transaction")
+	}
+
+	pskt, err := libkashwallet.CreatePSKT(params, true, legacyTransaction)
+	if err != nil {
+		t.Fatalf("CreatePSKT: %+v", err)
+	}
+	deserialized, err := serialization.DeserializePSKT(pskt)
+	if err != nil {
+		t.Fatalf("DeserializePSKT: %+v", err)
+	}
+	if deserialized.Version != serialization.CurrentPSKTVersion || deserialized.Global.Network != params.Name ||
+		!deserialized.Global.ECDSA {
+		t.Fatalf("Unexpected PSKT metadata: version %d, %+v", deserialized.Version, deserialized.Global)
+	}
+	deserialized.Global.Proprietary["test"] = []byte{1, 2, 3}
+	reserialized, err := serialization.SerializePSKT(deserialized)
+	if err != nil {
+		t.Fatalf("SerializePSKT: %+v", err)
+	}
+	redeserialized, err := serialization.DeserializePSKT(reserialized)
+	if err != nil {
+		t.Fatalf("DeserializePSKT: %+v", err)
+	}
+	if !bytes.Equal(redeserialized.Global.Proprietary["test"], []byte{1, 2, 3}) {
+		t.Fatalf("The proprietary field was not preserved")
+	}
+
+	// The combiner refuses to mix PSKTs of different networks
+	otherNetworkPSKT, err := libkashwallet.CreatePSKT(&dagconfig.TestnetParams, true, legacyTransaction)
+	if err != nil {
+		t.Fatalf("CreatePSKT: %+v", err)
+	}
+	_, err = libkashwallet.CombinePSKTs([][]byte{pskt, otherNetworkPSKT})
+	if err == nil {
+		t.Fatalf("CombinePSKTs unexpectedly succeeded on PSKTs of different networks")
+	}
+
+	// Signers refuse PSKTs of a different network
+	_, err = libkashwallet.Sign(&dagconfig.TestnetParams, []string{mnemonic}, pskt, true)
+	if err == nil {
+		t.Fatalf("Sign unexpectedly succeeded on a PSKT of a different network")
+	}
+
+	// Partially signed inputs without a previous output are rejected, as well as a
+	// number of partially signed inputs that differs from the number of inputs
+	protoLegacyTransaction := &protoserialization.PartiallySignedTransaction{}
+	err = proto.Unmarshal(legacyTransaction, protoLegacyTransaction)
+	if err != nil {
+		t.Fatalf("Unmarshal: %+v", err)
+	}
+	protoLegacyTransaction.PartiallySignedInputs[0].PrevOutput = nil
+	withoutPrevOutput, err := proto.Marshal(protoLegacyTransaction)
+	if err != nil {
+		t.Fatalf("Marshal: %+v", err)
+	}
+	_, err = serialization.DeserializePSKT(withoutPrevOutput)
+	if err == nil {
+		t.Fatalf("DeserializePSKT unexpectedly succeeded on an input without a previous output")
+	}
+	protoLegacyTransaction.PartiallySignedInputs = nil
+	withoutPartiallySignedInputs, err := proto.Marshal(protoLegacyTransaction)
+	if err != nil {
+		t.Fatalf("Marshal: %+v", err)
+	}
+	_, err = serialization.DeserializePSKT(withoutPartiallySignedInputs)
+	if err == nil {
+		t.Fatalf("DeserializePSKT unexpectedly succeeded without partially signed inputs")
+	}
+
+	// Future versions are rejected
+	deserialized.Version = serialization.CurrentPSKTVersion + 1
+	futureVersion, err := serialization.SerializePSKT(deserialized)
+	if err != nil {
+		t.Fatalf("SerializePSKT: %+v", err)
+	}
+	_, err = serialization.DeserializePSKT(futureVersion)
+	if err == nil {
+		t.Fatalf("DeserializePSKT unexpectedly succeeded on a future version")
+	}
+}
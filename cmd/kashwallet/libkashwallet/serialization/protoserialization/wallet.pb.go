@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
 // 	protoc-gen-go v1.26.0
-// 	protoc        v3.21.12
+// 	protoc        v3.17.2
 // source: wallet.proto
 
 package protoserialization
@@ -634,6 +634,134 @@ func (x *TransactionOutput) GetScriptPublicKey() *ScriptPublicKey {
 	return nil
 }
 
+// PartiallySignedKashTransaction (PSKT) is the versioned wallet interchange format of
+// partially signed transactions. Its serialization is prefixed by the "pskt" magic.
+type PartiallySignedKashTransaction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version                    uint32                      `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Global                     *PsktGlobal                 `protobuf:"bytes,2,opt,name=global,proto3" json:"global,omitempty"`
+	PartiallySignedTransaction *PartiallySignedTransaction `protobuf:"bytes,3,opt,name=partiallySignedTransaction,proto3" json:"partiallySignedTransaction,omitempty"`
+}
+
+func (x *PartiallySignedKashTransaction) Reset() {
+	*x = PartiallySignedKashTransaction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_wallet_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PartiallySignedKashTransaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartiallySignedKashTransaction) ProtoMessage() {}
+
+func (x *PartiallySignedKashTransaction) ProtoReflect() protoreflect.Message {
+	mi := &file_wallet_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartiallySignedKashTransaction.ProtoReflect.Descriptor instead.
+func (*PartiallySignedKashTransaction) Descriptor() ([]byte, []int) {
+	return file_wallet_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PartiallySignedKashTransaction) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *PartiallySignedKashTransaction) GetGlobal() *PsktGlobal {
+	if x != nil {
+		return x.Global
+	}
+	return nil
+}
+
+func (x *PartiallySignedKashTransaction) GetPartiallySignedTransaction() *PartiallySignedTransaction {
+	if x != nil {
+		return x.PartiallySignedTransaction
+	}
+	return nil
+}
+
+type PsktGlobal struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Network     string            `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	Ecdsa       bool              `protobuf:"varint,2,opt,name=ecdsa,proto3" json:"ecdsa,omitempty"`
+	Proprietary map[string][]byte `protobuf:"bytes,3,rep,name=proprietary,proto3" json:"proprietary,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *PsktGlobal) Reset() {
+	*x = PsktGlobal{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_wallet_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PsktGlobal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PsktGlobal) ProtoMessage() {}
+
+func (x *PsktGlobal) ProtoReflect() protoreflect.Message {
+	mi := &file_wallet_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PsktGlobal.ProtoReflect.Descriptor instead.
+func (*PsktGlobal) Descriptor() ([]byte, []int) {
+	return file_wallet_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PsktGlobal) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *PsktGlobal) GetEcdsa() bool {
+	if x != nil {
+		return x.Ecdsa
+	}
+	return false
+}
+
+func (x *PsktGlobal) GetProprietary() map[string][]byte {
+	if x != nil {
+		return x.Proprietary
+	}
+	return nil
+}
+
 var File_wallet_proto protoreflect.FileDescriptor
 
 var file_wallet_proto_rawDesc = []byte{
@@ -731,13 +859,41 @@ var file_wallet_proto_rawDesc = []byte{
 	0x0b, 0x32, 0x23, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x69,
 	0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x63, 0x72, 0x69, 0x70, 0x74, 0x50, 0x75, 0x62,
 	0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x52, 0x0f, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x50, 0x75,
-	0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x42, 0x5c, 0x5a, 0x5a, 0x67, 0x69, 0x74, 0x68, 0x75,
-	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6b, 0x61, 0x73, 0x70, 0x61, 0x6e, 0x65, 0x74, 0x2f, 0x6b,
-	0x61, 0x73, 0x70, 0x61, 0x64, 0x2f, 0x63, 0x6d, 0x64, 0x2f, 0x6b, 0x61, 0x73, 0x70, 0x61, 0x77,
-	0x61, 0x6c, 0x6c, 0x65, 0x74, 0x2f, 0x6c, 0x69, 0x62, 0x6b, 0x61, 0x73, 0x70, 0x61, 0x77, 0x61,
-	0x6c, 0x6c, 0x65, 0x74, 0x2f, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x69, 0x7a,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x22, 0xe2, 0x01, 0x0a, 0x1e, 0x50, 0x61, 0x72, 0x74,
+	0x69, 0x61, 0x6c, 0x6c, 0x79, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x4b, 0x61, 0x73, 0x68, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x36, 0x0a, 0x06, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x65, 0x72, 0x69,
+	0x61, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x50, 0x73, 0x6b, 0x74, 0x47, 0x6c,
+	0x6f, 0x62, 0x61, 0x6c, 0x52, 0x06, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x12, 0x6e, 0x0a, 0x1a,
+	0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x6c, 0x79, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x2e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x69, 0x7a,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x6c, 0x79, 0x53,
+	0x69, 0x67, 0x6e, 0x65, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x1a, 0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x6c, 0x79, 0x53, 0x69, 0x67, 0x6e, 0x65,
+	0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xcf, 0x01, 0x0a,
+	0x0a, 0x50, 0x73, 0x6b, 0x74, 0x47, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x6e,
+	0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65,
+	0x74, 0x77, 0x6f, 0x72, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x63, 0x64, 0x73, 0x61, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x65, 0x63, 0x64, 0x73, 0x61, 0x12, 0x51, 0x0a, 0x0b, 0x70,
+	0x72, 0x6f, 0x70, 0x72, 0x69, 0x65, 0x74, 0x61, 0x72, 0x79, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x2f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x69, 0x7a,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x50, 0x73, 0x6b, 0x74, 0x47, 0x6c, 0x6f, 0x62, 0x61, 0x6c,
+	0x2e, 0x50, 0x72, 0x6f, 0x70, 0x72, 0x69, 0x65, 0x74, 0x61, 0x72, 0x79, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x0b, 0x70, 0x72, 0x6f, 0x70, 0x72, 0x69, 0x65, 0x74, 0x61, 0x72, 0x79, 0x1a, 0x3e,
+	0x0a, 0x10, 0x50, 0x72, 0x6f, 0x70, 0x72, 0x69, 0x65, 0x74, 0x61, 0x72, 0x79, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x5e,
+	0x5a, 0x5c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x4b, 0x61, 0x73,
+	0x68, 0x2d, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x2f, 0x6b, 0x61, 0x73, 0x68, 0x64,
+	0x2f, 0x63, 0x6d, 0x64, 0x2f, 0x6b, 0x61, 0x73, 0x68, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x2f,
+	0x6c, 0x69, 0x62, 0x6b, 0x61, 0x73, 0x68, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x2f, 0x73, 0x65,
+	0x72, 0x69, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -752,18 +908,21 @@ func file_wallet_proto_rawDescGZIP() []byte {
 	return file_wallet_proto_rawDescData
 }
 
-var file_wallet_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_wallet_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_wallet_proto_goTypes = []interface{}{
-	(*PartiallySignedTransaction)(nil), // 0: protoserialization.PartiallySignedTransaction
-	(*PartiallySignedInput)(nil),       // 1: protoserialization.PartiallySignedInput
-	(*PubKeySignaturePair)(nil),        // 2: protoserialization.PubKeySignaturePair
-	(*SubnetworkId)(nil),               // 3: protoserialization.SubnetworkId
-	(*TransactionMessage)(nil),         // 4: protoserialization.TransactionMessage
-	(*TransactionInput)(nil),           // 5: protoserialization.TransactionInput
-	(*Outpoint)(nil),                   // 6: protoserialization.Outpoint
-	(*TransactionId)(nil),              // 7: protoserialization.TransactionId
-	(*ScriptPublicKey)(nil),            // 8: protoserialization.ScriptPublicKey
-	(*TransactionOutput)(nil),          // 9: protoserialization.TransactionOutput
+	(*PartiallySignedTransaction)(nil),     // 0: protoserialization.PartiallySignedTransaction
+	(*PartiallySignedInput)(nil),           // 1: protoserialization.PartiallySignedInput
+	(*PubKeySignaturePair)(nil),            // 2: protoserialization.PubKeySignaturePair
+	(*SubnetworkId)(nil),                   // 3: protoserialization.SubnetworkId
+	(*TransactionMessage)(nil),             // 4: protoserialization.TransactionMessage
+	(*TransactionInput)(nil),               // 5: protoserialization.TransactionInput
+	(*Outpoint)(nil),                       // 6: protoserialization.Outpoint
+	(*TransactionId)(nil),                  // 7: protoserialization.TransactionId
+	(*ScriptPublicKey)(nil),                // 8: protoserialization.ScriptPublicKey
+	(*TransactionOutput)(nil),              // 9: protoserialization.TransactionOutput
+	(*PartiallySignedKashTransaction)(nil), // 10: protoserialization.PartiallySignedKashTransaction
+	(*PsktGlobal)(nil),                     // 11: protoserialization.PsktGlobal
+	nil,                                    // 12: protoserialization.PsktGlobal.ProprietaryEntry
 }
 var file_wallet_proto_depIdxs = []int32{
 	4,  // 0: protoserialization.PartiallySignedTransaction.tx:type_name -> protoserialization.TransactionMessage
@@ -776,11 +935,14 @@ var file_wallet_proto_depIdxs = []int32{
 	6,  // 7: protoserialization.TransactionInput.previousOutpoint:type_name -> protoserialization.Outpoint
 	7,  // 8: protoserialization.Outpoint.transactionId:type_name -> protoserialization.TransactionId
 	8,  // 9: protoserialization.TransactionOutput.scriptPublicKey:type_name -> protoserialization.ScriptPublicKey
-	10, // [10:10] is the sub-list for method output_type
-	10, // [10:10] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	11, // 10: protoserialization.PartiallySignedKashTransaction.global:type_name -> protoserialization.PsktGlobal
+	0,  // 11: protoserialization.PartiallySignedKashTransaction.partiallySignedTransaction:type_name -> protoserialization.PartiallySignedTransaction
+	12, // 12: protoserialization.PsktGlobal.proprietary:type_name -> protoserialization.PsktGlobal.ProprietaryEntry
+	13, // [13:13] is the sub-list for method output_type
+	13, // [13:13] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_wallet_proto_init() }
@@ -909,6 +1071,30 @@ func file_wallet_proto_init() {
 				return nil
 			}
 		}
+		file_wallet_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PartiallySignedKashTransaction); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_wallet_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PsktGlobal); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -916,7 +1102,7 @@ func file_wallet_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_wallet_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,138 @@
+package serialization
+
+import (
+	"bytes"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/serialization/protoserialization"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// LegacyPSKTVersion is the version of a PSKT that was deserialized from (and
+	// will be serialized to) the legacy unversioned PartiallySignedTransaction format.
+	// Such a PSKT has no global metadata.
+	LegacyPSKTVersion = 0
+
+	// CurrentPSKTVersion is the latest PSKT version that this package knows how to handle
+	CurrentPSKTVersion = 1
+)
+
+// psktMagic prefixes every serialized PSKT, so it can be told apart from the legacy format.
+// The 0xff byte can't start a valid legacy protobuf message.
+var psktMagic = []byte{'p', 's', 'k', 't', 0xff}
+
+// PSKT is a partially signed Kash transaction: a PartiallySignedTransaction along
+// with a version and global metadata, passed between the creator, updater, signers,
+// combiner, finalizer and extractor of a transaction.
+type PSKT struct {
+	Version uint32
+	Global  *PSKTGlobal
+
+	// PartiallySignedTransaction holds the transaction and the per-input signing data.
+	// An input whose SignatureScript is set in PartiallySignedTransaction.Tx has been finalized.
+	PartiallySignedTransaction *PartiallySignedTransaction
+}
+
+// PSKTGlobal is the metadata of a PSKT that doesn't belong to a specific input
+type PSKTGlobal struct {
+	Network string
+	ECDSA   bool
+
+	// Proprietary holds application specific key-value pairs. They are preserved,
+	// but otherwise ignored, by all roles.
+	Proprietary map[string][]byte
+}
+
+// Clone creates a deep-clone of this PSKT
+func (p *PSKT) Clone() *PSKT {
+	clone := &PSKT{
+		Version:                    p.Version,
+		PartiallySignedTransaction: p.PartiallySignedTransaction.Clone(),
+	}
+	if p.Global != nil {
+		clone.Global = &PSKTGlobal{
+			Network:     p.Global.Network,
+			ECDSA:       p.Global.ECDSA,
+			Proprietary: make(map[string][]byte, len(p.Global.Proprietary)),
+		}
+		for key, value := range p.Global.Proprietary {
+			clone.Global.Proprietary[key] = append([]byte{}, value...)
+		}
+	}
+	return clone
+}
+
+// IsPSKT returns whether the given bytes are a serialized PSKT, as opposed
+// to a legacy serialized PartiallySignedTransaction
+func IsPSKT(serialized []byte) bool {
+	return bytes.HasPrefix(serialized, psktMagic)
+}
+
+// DeserializePSKT deserializes a byte slice into a PSKT. A legacy serialized PartiallySignedTransaction
+// is accepted as well, and results in a PSKT with LegacyPSKTVersion.
+func DeserializePSKT(serialized []byte) (*PSKT, error) {
+	if !IsPSKT(serialized) {
+		partiallySignedTransaction, err := deserializeLegacyPartiallySignedTransaction(serialized)
+		if err != nil {
+			return nil, err
+		}
+		return &PSKT{Version: LegacyPSKTVersion, PartiallySignedTransaction: partiallySignedTransaction}, nil
+	}
+
+	protoPSKT := &protoserialization.PartiallySignedKashTransaction{}
+	err := proto.Unmarshal(serialized[len(psktMagic):], protoPSKT)
+	if err != nil {
+		return nil, err
+	}
+	if protoPSKT.Version == LegacyPSKTVersion || protoPSKT.Version > CurrentPSKTVersion {
+		return nil, errors.Errorf("unsupported PSKT version %d", protoPSKT.Version)
+	}
+	if protoPSKT.Global == nil || protoPSKT.PartiallySignedTransaction == nil {
+		return nil, errors.New("the PSKT is missing required fields")
+	}
+
+	partiallySignedTransaction, err := partiallySignedTransactionFromProto(protoPSKT.PartiallySignedTransaction)
+	if err != nil {
+		return nil, err
+	}
+	proprietary := protoPSKT.Global.Proprietary
+	if proprietary == nil {
+		proprietary = map[string][]byte{}
+	}
+
+	return &PSKT{
+		Version: protoPSKT.Version,
+		Global: &PSKTGlobal{
+			Network:     protoPSKT.Global.Network,
+			ECDSA:       protoPSKT.Global.Ecdsa,
+			Proprietary: proprietary,
+		},
+		PartiallySignedTransaction: partiallySignedTransaction,
+	}, nil
+}
+
+// SerializePSKT serializes a PSKT. A PSKT with LegacyPSKTVersion is serialized
+// in the legacy PartiallySignedTransaction format.
+func SerializePSKT(pskt *PSKT) ([]byte, error) {
+	if pskt.Version == LegacyPSKTVersion {
+		return SerializePartiallySignedTransaction(pskt.PartiallySignedTransaction)
+	}
+	if pskt.Global == nil {
+		return nil, errors.New("the PSKT is missing its global metadata")
+	}
+
+	serialized, err := proto.Marshal(&protoserialization.PartiallySignedKashTransaction{
+		Version: pskt.Version,
+		Global: &protoserialization.PsktGlobal{
+			Network:     pskt.Global.Network,
+			Ecdsa:       pskt.Global.ECDSA,
+			Proprietary: pskt.Global.Proprietary,
+		},
+		PartiallySignedTransaction: partiallySignedTransactionToProto(pskt.PartiallySignedTransaction),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, psktMagic...), serialized...), nil
+}
@@ -73,7 +73,19 @@ func (psp PubKeySignaturePair) Clone() *PubKeySignaturePair {
 }
 
 // DeserializePartiallySignedTransaction deserializes a byte slice into PartiallySignedTransaction.
+// The byte slice may be either a serialized PSKT or a legacy serialized PartiallySignedTransaction.
 func DeserializePartiallySignedTransaction(serializedPartiallySignedTransaction []byte) (*PartiallySignedTransaction, error) {
+	if IsPSKT(serializedPartiallySignedTransaction) {
+		pskt, err := DeserializePSKT(serializedPartiallySignedTransaction)
+		if err != nil {
+			return nil, err
+		}
+		return pskt.PartiallySignedTransaction, nil
+	}
+	return deserializeLegacyPartiallySignedTransaction(serializedPartiallySignedTransaction)
+}
+
+func deserializeLegacyPartiallySignedTransaction(serializedPartiallySignedTransaction []byte) (*PartiallySignedTransaction, error) {
 	protoPartiallySignedTransaction := &protoserialization.PartiallySignedTransaction{}
 	err := proto.Unmarshal(serializedPartiallySignedTransaction, protoPartiallySignedTransaction)
 	if err != nil {
@@ -105,6 +117,20 @@ func SerializeDomainTransaction(tx *externalapi.DomainTransaction) ([]byte, erro
 }
 
 func partiallySignedTransactionFromProto(protoPartiallySignedTransaction *protoserialization.PartiallySignedTransaction) (*PartiallySignedTransaction, error) {
+	if protoPartiallySignedTransaction.Tx == nil {
+		return nil, errors.New("the partially signed transaction is missing its transaction")
+	}
+	if len(protoPartiallySignedTransaction.PartiallySignedInputs) != len(protoPartiallySignedTransaction.Tx.Inputs) {
+		return nil, errors.Errorf("the partially signed transaction has %d partially signed inputs, while its "+
+			"transaction has %d inputs", len(protoPartiallySignedTransaction.PartiallySignedInputs),
+			len(protoPartiallySignedTransaction.Tx.Inputs))
+	}
+	for i, protoInput := range protoPartiallySignedTransaction.PartiallySignedInputs {
+		if protoInput.PrevOutput == nil || protoInput.PrevOutput.ScriptPublicKey == nil {
+			return nil, errors.Errorf("partially signed input #%d is missing its previous output", i)
+		}
+	}
+
 	tx, err := transactionFromProto(protoPartiallySignedTransaction.Tx)
 	if err != nil {
 		return nil, err
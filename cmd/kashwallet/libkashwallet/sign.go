@@ -1,6 +1,8 @@
 package libkashwallet
 
 import (
+	"encoding/hex"
+
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/bip32"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/serialization"
 	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
@@ -8,6 +10,7 @@ import (
 	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
 	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
 	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/kaspanet/go-secp256k1"
 	"github.com/pkg/errors"
 )
 
@@ -27,20 +30,25 @@ func rawTxInSignature(extendedKey *bip32.ExtendedKey, tx *externalapi.DomainTran
 	return txscript.RawTxInSignature(tx, idx, hashType, schnorrKeyPair, sighashReusedValues)
 }
 
-// Sign signs the transaction with the given private keys
+// Sign signs the transaction with the given private keys. The transaction may be either a PSKT
+// or a legacy partially signed transaction, and it's returned in the same format.
 func Sign(params *dagconfig.Params, mnemonics []string, serializedPSTx []byte, ecdsa bool) ([]byte, error) {
-	partiallySignedTransaction, err := serialization.DeserializePartiallySignedTransaction(serializedPSTx)
+	pskt, err := serialization.DeserializePSKT(serializedPSTx)
+	if err != nil {
+		return nil, err
+	}
+	err = checkPSKTNetwork(params, pskt)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, mnemonic := range mnemonics {
-		err = sign(params, mnemonic, partiallySignedTransaction, ecdsa)
+		err = sign(params, mnemonic, pskt.PartiallySignedTransaction, ecdsa)
 		if err != nil {
 			return nil, err
 		}
 	}
-	return serialization.SerializePartiallySignedTransaction(partiallySignedTransaction)
+	return serialization.SerializePSKT(pskt)
 }
 
 func sign(params *dagconfig.Params, mnemonic string, partiallySignedTransaction *serialization.PartiallySignedTransaction, ecdsa bool) error {
@@ -49,16 +57,7 @@ func sign(params *dagconfig.Params, mnemonic string, partiallySignedTransaction
 	}
 
 	sighashReusedValues := &consensushashing.SighashReusedValues{}
-	for i, partiallySignedInput := range partiallySignedTransaction.PartiallySignedInputs {
-		prevOut := partiallySignedInput.PrevOutput
-		partiallySignedTransaction.Tx.Inputs[i].UTXOEntry = utxo.NewUTXOEntry(
-			prevOut.Value,
-			prevOut.ScriptPublicKey,
-			false, // This is a fake value, because it's irrelevant for the signature
-			0,     // This is a fake value, because it's irrelevant for the signature
-		)
-		partiallySignedTransaction.Tx.Inputs[i].SigOpCount = byte(len(partiallySignedInput.PubKeySignaturePairs))
-	}
+	populateUTXOEntries(partiallySignedTransaction)
 
 	signed := false
 	for i, partiallySignedInput := range partiallySignedTransaction.PartiallySignedInputs {
@@ -97,3 +96,149 @@ func sign(params *dagconfig.Params, mnemonic string, partiallySignedTransaction
 
 	return nil
 }
+
+func populateUTXOEntries(partiallySignedTransaction *serialization.PartiallySignedTransaction) {
+	for i, partiallySignedInput := range partiallySignedTransaction.PartiallySignedInputs {
+		prevOut := partiallySignedInput.PrevOutput
+		partiallySignedTransaction.Tx.Inputs[i].UTXOEntry = utxo.NewUTXOEntry(
+			prevOut.Value,
+			prevOut.ScriptPublicKey,
+			false, // This is a fake value, because it's irrelevant for the signature
+			0,     // This is a fake value, because it's irrelevant for the signature
+		)
+		partiallySignedTransaction.Tx.Inputs[i].SigOpCount = byte(len(partiallySignedInput.PubKeySignaturePairs))
+	}
+}
+
+// SignWithPrivateKey signs the transaction inputs that are locked to the public key of the
+// given schnorr private key. Such inputs carry the hex encoded public key in place of an
+// extended public key, as done by the sweep command.
+func SignWithPrivateKey(serializedPSTx []byte, privateKeyBytes []byte) ([]byte, error) {
+	pskt, err := serialization.DeserializePSKT(serializedPSTx)
+	if err != nil {
+		return nil, err
+	}
+	partiallySignedTransaction := pskt.PartiallySignedTransaction
+
+	schnorrKeyPair, err := secp256k1.DeserializeSchnorrPrivateKeyFromSlice(privateKeyBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to deserialize private key")
+	}
+	publicKey, err := PublicKeyFromPrivateKey(privateKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKeyHex := hex.EncodeToString(publicKey)
+
+	populateUTXOEntries(partiallySignedTransaction)
+	sighashReusedValues := &consensushashing.SighashReusedValues{}
+	signed := false
+	for i, partiallySignedInput := range partiallySignedTransaction.PartiallySignedInputs {
+		for _, pair := range partiallySignedInput.PubKeySignaturePairs {
+			if pair.ExtendedPublicKey != publicKeyHex {
+				continue
+			}
+			pair.Signature, err = txscript.RawTxInSignature(partiallySignedTransaction.Tx, i,
+				consensushashing.SigHashAll, schnorrKeyPair, sighashReusedValues)
+			if err != nil {
+				return nil, err
+			}
+			signed = true
+		}
+	}
+
+	if !signed {
+		return nil, errors.Errorf("Public key doesn't match any of the transaction public keys")
+	}
+
+	return serialization.SerializePSKT(pskt)
+}
+
+// VerifySignature verifies that signature is a valid SigHashAll signature of input
+// inputIndex of partiallySignedTransaction by the given public key. The public key is
+// either a derived extended public key, or a hex encoded schnorr public key, like the
+// ones in PubKeySignaturePairs.
+func VerifySignature(partiallySignedTransaction *serialization.PartiallySignedTransaction, inputIndex int,
+	publicKey string, signature []byte, ecdsa bool) error {
+
+	for i, partiallySignedInput := range partiallySignedTransaction.PartiallySignedInputs {
+		if partiallySignedInput.PrevOutput == nil {
+			return errors.Errorf("input %d is missing its previous output", i)
+		}
+	}
+	if len(signature) < 1 {
+		return errors.New("the signature is empty")
+	}
+	hashType := consensushashing.SigHashType(signature[len(signature)-1])
+	if hashType != consensushashing.SigHashAll {
+		return errors.Errorf("the signature has hash type 0x%x instead of SigHashAll", hashType)
+	}
+	signatureBytes := signature[:len(signature)-1]
+
+	populateUTXOEntries(partiallySignedTransaction)
+	tx := partiallySignedTransaction.Tx
+	sighashReusedValues := &consensushashing.SighashReusedValues{}
+
+	if ecdsa {
+		ecdsaPublicKey, err := ecdsaPublicKeyFromString(publicKey)
+		if err != nil {
+			return err
+		}
+		sigHash, err := consensushashing.CalculateSignatureHashECDSA(tx, inputIndex, hashType, sighashReusedValues)
+		if err != nil {
+			return err
+		}
+		ecdsaSignature, err := secp256k1.DeserializeECDSASignatureFromSlice(signatureBytes)
+		if err != nil {
+			return errors.Wrap(err, "malformed signature")
+		}
+		secpHash := secp256k1.Hash(*sigHash.ByteArray())
+		if !ecdsaPublicKey.ECDSAVerify(&secpHash, ecdsaSignature) {
+			return errors.Errorf("invalid signature of input %d by %s", inputIndex, publicKey)
+		}
+		return nil
+	}
+
+	schnorrPublicKey, err := schnorrPublicKeyFromString(publicKey)
+	if err != nil {
+		return err
+	}
+	sigHash, err := consensushashing.CalculateSignatureHashSchnorr(tx, inputIndex, hashType, sighashReusedValues)
+	if err != nil {
+		return err
+	}
+	schnorrSignature, err := secp256k1.DeserializeSchnorrSignatureFromSlice(signatureBytes)
+	if err != nil {
+		return errors.Wrap(err, "malformed signature")
+	}
+	secpHash := secp256k1.Hash(*sigHash.ByteArray())
+	if !schnorrPublicKey.SchnorrVerify(&secpHash, schnorrSignature) {
+		return errors.Errorf("invalid signature of input %d by %s", inputIndex, publicKey)
+	}
+	return nil
+}
+
+func ecdsaPublicKeyFromString(publicKey string) (*secp256k1.ECDSAPublicKey, error) {
+	extendedKey, err := bip32.DeserializeExtendedKey(publicKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed extended public key %s", publicKey)
+	}
+	return extendedKey.PublicKey()
+}
+
+func schnorrPublicKeyFromString(publicKey string) (*secp256k1.SchnorrPublicKey, error) {
+	extendedKey, err := bip32.DeserializeExtendedKey(publicKey)
+	if err != nil {
+		// Inputs that are locked to a plain public key carry it hex encoded, see SignWithPrivateKey
+		publicKeyBytes, hexErr := hex.DecodeString(publicKey)
+		if hexErr != nil {
+			return nil, errors.Wrapf(err, "malformed public key %s", publicKey)
+		}
+		return secp256k1.DeserializeSchnorrPubKey(publicKeyBytes)
+	}
+	ecdsaPublicKey, err := extendedKey.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return ecdsaPublicKey.ToSchnorr()
+}
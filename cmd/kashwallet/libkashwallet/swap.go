@@ -0,0 +1,243 @@
+package libkashwallet
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/subnetworks"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/Kash-Protocol/kashd/util"
+	"github.com/Kash-Protocol/kashd/util/txmass"
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/pkg/errors"
+)
+
+// swapSignatureLength is the length of a schnorr signature followed by its sighash type
+const swapSignatureLength = 65
+
+// NewSwapSecret generates a random atomic swap secret, and returns it along with its SHA256 hash
+func NewSwapSecret() ([]byte, [sha256.Size]byte, error) {
+	secret := make([]byte, txscript.HTLCSecretSize)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return nil, [sha256.Size]byte{}, errors.Wrap(err, "Failed to generate the swap secret")
+	}
+	return secret, sha256.Sum256(secret), nil
+}
+
+// SwapContract is an atomic swap contract: an HTLC script that's paid to through its
+// pay-to-script-hash address. It can be redeemed by the recipient with the secret,
+// or refunded to the refund address once its lock time is reached.
+type SwapContract struct {
+	Script           []byte
+	Address          *util.AddressScriptHash
+	RecipientAddress *util.AddressPublicKey
+	RefundAddress    *util.AddressPublicKey
+	SecretHash       [sha256.Size]byte
+	LockTime         uint64
+}
+
+// NewSwapContract creates an atomic swap contract between the given schnorr
+// pay-to-pubkey addresses
+func NewSwapContract(params *dagconfig.Params, recipientAddress, refundAddress util.Address,
+	secretHash []byte, lockTime uint64) (*SwapContract, error) {
+
+	recipientPublicKey, ok := recipientAddress.(*util.AddressPublicKey)
+	if !ok {
+		return nil, errors.Errorf("The recipient address %s is not a schnorr public key address", recipientAddress)
+	}
+	refundPublicKey, ok := refundAddress.(*util.AddressPublicKey)
+	if !ok {
+		return nil, errors.Errorf("The refund address %s is not a schnorr public key address", refundAddress)
+	}
+	if len(secretHash) != sha256.Size {
+		return nil, errors.Errorf("The secret hash must be %d bytes long", sha256.Size)
+	}
+	if lockTime < constants.LockTimeThreshold {
+		return nil, errors.Errorf("The lock time must be a timestamp in milliseconds")
+	}
+
+	script, err := txscript.HTLCScript(recipientPublicKey.ScriptAddress(), refundPublicKey.ScriptAddress(), secretHash, lockTime)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSwapContract(params, script)
+}
+
+// ParseSwapContract parses the given HTLC script
+func ParseSwapContract(params *dagconfig.Params, script []byte) (*SwapContract, error) {
+	pushes, err := txscript.ExtractHTLCDataPushes(script)
+	if err != nil {
+		return nil, err
+	}
+	if pushes == nil {
+		return nil, errors.Errorf("The script is not an atomic swap contract")
+	}
+
+	address, err := util.NewAddressScriptHash(script, params.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	recipientAddress, err := util.NewAddressPublicKey(pushes.RecipientPubKey[:], params.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	refundAddress, err := util.NewAddressPublicKey(pushes.RefundPubKey[:], params.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SwapContract{
+		Script:           script,
+		Address:          address,
+		RecipientAddress: recipientAddress,
+		RefundAddress:    refundAddress,
+		SecretHash:       pushes.SecretHash,
+		LockTime:         pushes.LockTime,
+	}, nil
+}
+
+// CreateSpendTransaction creates a transaction that spends the given contract UTXOs to toAddress,
+// paying a fee of one sompi per gram of mass. A refund transaction has the contract lock time, and
+// can only be accepted once it's reached.
+func (c *SwapContract) CreateSpendTransaction(params *dagconfig.Params, utxos []*UTXO, toAddress util.Address,
+	isRefund bool) (*externalapi.DomainTransaction, error) {
+
+	if len(utxos) == 0 {
+		return nil, errors.Errorf("The contract %s has no UTXOs to spend", c.Address)
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(toAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &externalapi.DomainTransaction{
+		Version:      constants.MaxTransactionVersion,
+		Inputs:       make([]*externalapi.DomainTransactionInput, len(utxos)),
+		Outputs:      []*externalapi.DomainTransactionOutput{{ScriptPublicKey: scriptPublicKey}},
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+	}
+	if isRefund {
+		tx.LockTime = c.LockTime
+	}
+
+	totalAmount := uint64(0)
+	for i, contractUTXO := range utxos {
+		if !bytes.Equal(contractUTXO.UTXOEntry.ScriptPublicKey().Script, c.scriptPublicKey().Script) {
+			return nil, errors.Errorf("The UTXO %s doesn't belong to the contract", contractUTXO.Outpoint)
+		}
+		totalAmount += contractUTXO.UTXOEntry.Amount()
+		tx.Inputs[i] = &externalapi.DomainTransactionInput{
+			PreviousOutpoint: *contractUTXO.Outpoint,
+			// The sequence must not be final for OP_CHECKLOCKTIMEVERIFY to pass
+			Sequence: 0,
+			UTXOEntry: utxo.NewUTXOEntry(contractUTXO.UTXOEntry.Amount(), contractUTXO.UTXOEntry.ScriptPublicKey(),
+				false, constants.UnacceptedDAAScore),
+			SigOpCount: 1,
+		}
+	}
+
+	// Estimate the mass with dummy signature scripts of the final length
+	var dummySecret []byte
+	if !isRefund {
+		dummySecret = make([]byte, txscript.HTLCSecretSize)
+	}
+	for _, input := range tx.Inputs {
+		input.SignatureScript, err = c.signatureScript(make([]byte, swapSignatureLength), dummySecret)
+		if err != nil {
+			return nil, err
+		}
+	}
+	massCalculator := txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp,
+		params.MassPerSpliceOp)
+	fee := massCalculator.CalculateTransactionMass(tx)
+	for _, input := range tx.Inputs {
+		input.SignatureScript = nil
+	}
+
+	if totalAmount <= fee {
+		return nil, errors.Errorf("The contract amount %d is not enough to pay the fee of %d sompi", totalAmount, fee)
+	}
+	tx.Outputs[0].Value = totalAmount - fee
+	return tx, nil
+}
+
+// SignSpendTransaction signs all the inputs of a transaction created by CreateSpendTransaction with the
+// given key, and sets their signature scripts. A nil secret creates a refund.
+func (c *SwapContract) SignSpendTransaction(tx *externalapi.DomainTransaction, keyPair *secp256k1.SchnorrKeyPair,
+	secret []byte) error {
+
+	if secret != nil && sha256.Sum256(secret) != c.SecretHash {
+		return errors.Errorf("The secret doesn't match the secret hash of the contract")
+	}
+
+	sighashReusedValues := &consensushashing.SighashReusedValues{}
+	for i, input := range tx.Inputs {
+		signature, err := txscript.RawTxInSignature(tx, i, consensushashing.SigHashAll, keyPair, sighashReusedValues)
+		if err != nil {
+			return err
+		}
+		input.SignatureScript, err = c.signatureScript(signature, secret)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *SwapContract) signatureScript(signature []byte, secret []byte) ([]byte, error) {
+	var htlcSignatureScript []byte
+	var err error
+	if secret != nil {
+		htlcSignatureScript, err = txscript.HTLCRedeemSignatureScript(signature, secret)
+	} else {
+		htlcSignatureScript, err = txscript.HTLCRefundSignatureScript(signature)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return txscript.PayToScriptHashSignatureScript(c.Script, htlcSignatureScript)
+}
+
+func (c *SwapContract) scriptPublicKey() *externalapi.ScriptPublicKey {
+	scriptPublicKey, _ := txscript.PayToAddrScript(c.Address)
+	return scriptPublicKey
+}
+
+// ExtractSwapSecret returns the secret of the given SHA256 hash if it's pushed by the given signature
+// script, as done when an atomic swap contract is redeemed. Otherwise it returns nil.
+func ExtractSwapSecret(signatureScript []byte, secretHash []byte) []byte {
+	pushedData, err := txscript.PushedData(signatureScript)
+	if err != nil {
+		return nil
+	}
+	for _, data := range pushedData {
+		if len(data) != txscript.HTLCSecretSize {
+			continue
+		}
+		hash := sha256.Sum256(data)
+		if bytes.Equal(hash[:], secretHash) {
+			return data
+		}
+	}
+	return nil
+}
+
+// SwapKeyPair returns the schnorr key pair of a single signer wallet at the given derivation path
+func SwapKeyPair(params *dagconfig.Params, mnemonic string, derivationPath string) (*secp256k1.SchnorrKeyPair, error) {
+	extendedKey, err := extendedKeyFromMnemonicAndPath(mnemonic, defaultPath(false), params)
+	if err != nil {
+		return nil, err
+	}
+	derivedKey, err := extendedKey.DeriveFromPath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	return derivedKey.PrivateKey().ToSchnorr()
+}
@@ -0,0 +1,167 @@
+package libkashwallet_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/domain/consensus"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/testutils"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
+	"github.com/Kash-Protocol/kashd/util"
+)
+
+func TestSwapContract(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		params := &consensusConfig.Params
+		consensusConfig.BlockCoinbaseMaturity = 0
+
+		const path = "m/0/1"
+		newWallet := func() (string, util.Address) {
+			mnemonic, err := libkashwallet.CreateMnemonic()
+			if err != nil {
+				t.Fatalf("CreateMnemonic: %+v", err)
+			}
+			publicKey, err := libkashwallet.MasterPublicKeyFromMnemonic(params, mnemonic, false)
+			if err != nil {
+				t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+			}
+			address, err := libkashwallet.Address(params, []string{publicKey}, 1, path, false)
+			if err != nil {
+				t.Fatalf("Address: %+v", err)
+			}
+			return mnemonic, address
+		}
+		recipientMnemonic, recipientAddress := newWallet()
+		refundMnemonic, refundAddress := newWallet()
+
+		secret, secretHash, err := libkashwallet.NewSwapSecret()
+		if err != nil {
+			t.Fatalf("NewSwapSecret: %+v", err)
+		}
+
+		tests := []struct {
+			name     string
+			mnemonic string
+			lockTime uint64
+			isRefund bool
+		}{
+			{
+				name:     "redeem",
+				mnemonic: recipientMnemonic,
+				lockTime: uint64(time.Now().Add(48 * time.Hour).UnixMilli()),
+			},
+			{
+				name:     "refund",
+				mnemonic: refundMnemonic,
+				lockTime: constants.LockTimeThreshold,
+				isRefund: true,
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				tc, teardown, err := consensus.NewFactory().NewTestConsensus(consensusConfig, "TestSwapContract")
+				if err != nil {
+					t.Fatalf("Error setting up tc: %+v", err)
+				}
+				defer teardown(false)
+
+				contract, err := libkashwallet.NewSwapContract(params, recipientAddress, refundAddress, secretHash[:],
+					test.lockTime)
+				if err != nil {
+					t.Fatalf("NewSwapContract: %+v", err)
+				}
+				parsedContract, err := libkashwallet.ParseSwapContract(params, contract.Script)
+				if err != nil {
+					t.Fatalf("ParseSwapContract: %+v", err)
+				}
+				if parsedContract.RecipientAddress.String() != recipientAddress.String() ||
+					parsedContract.RefundAddress.String() != refundAddress.String() ||
+					parsedContract.SecretHash != secretHash || parsedContract.LockTime != test.lockTime {
+					t.Fatalf("The parsed contract doesn't match the original one")
+				}
+
+				scriptPublicKey, err := txscript.PayToAddrScript(contract.Address)
+				if err != nil {
+					t.Fatalf("PayToAddrScript: %+v", err)
+				}
+				fundingBlockHash, _, err := tc.AddBlock([]*externalapi.DomainHash{consensusConfig.GenesisHash},
+					&externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey}, nil)
+				if err != nil {
+					t.Fatalf("AddBlock: %+v", err)
+				}
+				block1Hash, _, err := tc.AddBlock([]*externalapi.DomainHash{fundingBlockHash}, nil, nil)
+				if err != nil {
+					t.Fatalf("AddBlock: %+v", err)
+				}
+				block1, _, err := tc.GetBlock(block1Hash)
+				if err != nil {
+					t.Fatalf("GetBlock: %+v", err)
+				}
+				block1TxOut := block1.Transactions[0].Outputs[0]
+				contractUTXOs := []*libkashwallet.UTXO{
+					{
+						Outpoint: &externalapi.DomainOutpoint{
+							TransactionID: *consensushashing.TransactionID(block1.Transactions[0]),
+							Index:         0,
+						},
+						UTXOEntry: utxo.NewUTXOEntry(block1TxOut.Value, block1TxOut.ScriptPublicKey, true, 0),
+					},
+				}
+
+				tx, err := contract.CreateSpendTransaction(params, contractUTXOs, recipientAddress, test.isRefund)
+				if err != nil {
+					t.Fatalf("CreateSpendTransaction: %+v", err)
+				}
+				keyPair, err := libkashwallet.SwapKeyPair(params, test.mnemonic, path)
+				if err != nil {
+					t.Fatalf("SwapKeyPair: %+v", err)
+				}
+
+				err = contract.SignSpendTransaction(tx, keyPair, make([]byte, txscript.HTLCSecretSize))
+				if err == nil {
+					t.Fatalf("SignSpendTransaction unexpectedly accepted a wrong secret")
+				}
+				var spendSecret []byte
+				if !test.isRefund {
+					spendSecret = secret
+				}
+				err = contract.SignSpendTransaction(tx, keyPair, spendSecret)
+				if err != nil {
+					t.Fatalf("SignSpendTransaction: %+v", err)
+				}
+
+				extractedSecret := libkashwallet.ExtractSwapSecret(tx.Inputs[0].SignatureScript, secretHash[:])
+				if test.isRefund && extractedSecret != nil {
+					t.Fatalf("ExtractSwapSecret unexpectedly found a secret in a refund")
+				}
+				if !test.isRefund && !bytes.Equal(extractedSecret, secret) {
+					t.Fatalf("ExtractSwapSecret: expected %x but got %x", secret, extractedSecret)
+				}
+				otherHash := sha256.Sum256(secretHash[:])
+				if libkashwallet.ExtractSwapSecret(tx.Inputs[0].SignatureScript, otherHash[:]) != nil {
+					t.Fatalf("ExtractSwapSecret unexpectedly found a secret of another hash")
+				}
+
+				_, virtualChangeSet, err := tc.AddBlock([]*externalapi.DomainHash{block1Hash}, nil,
+					[]*externalapi.DomainTransaction{tx})
+				if err != nil {
+					t.Fatalf("AddBlock: %+v", err)
+				}
+				addedUTXO := &externalapi.DomainOutpoint{
+					TransactionID: *consensushashing.TransactionID(tx),
+					Index:         0,
+				}
+				if !virtualChangeSet.VirtualUTXODiff.ToAdd().Contains(addedUTXO) {
+					t.Fatalf("Transaction wasn't accepted in the DAG")
+				}
+			})
+		}
+	})
+}
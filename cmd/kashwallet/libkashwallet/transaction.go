@@ -103,21 +103,9 @@ func createUnsignedTransaction(
 	inputs := make([]*externalapi.DomainTransactionInput, len(selectedUTXOs))
 	partiallySignedInputs := make([]*serialization.PartiallySignedInput, len(selectedUTXOs))
 	for i, utxo := range selectedUTXOs {
-		emptyPubKeySignaturePairs := make([]*serialization.PubKeySignaturePair, len(extendedPublicKeys))
-		for i, extendedPublicKey := range extendedPublicKeys {
-			extendedKey, err := bip32.DeserializeExtendedKey(extendedPublicKey)
-			if err != nil {
-				return nil, err
-			}
-
-			derivedKey, err := extendedKey.DeriveFromPath(utxo.DerivationPath)
-			if err != nil {
-				return nil, err
-			}
-
-			emptyPubKeySignaturePairs[i] = &serialization.PubKeySignaturePair{
-				ExtendedPublicKey: derivedKey.String(),
-			}
+		emptyPubKeySignaturePairs, err := emptyPubKeySignaturePairs(extendedPublicKeys, utxo.DerivationPath)
+		if err != nil {
+			return nil, err
 		}
 
 		inputs[i] = &externalapi.DomainTransactionInput{PreviousOutpoint: *utxo.Outpoint}
@@ -162,6 +150,26 @@ func createUnsignedTransaction(
 
 }
 
+func emptyPubKeySignaturePairs(extendedPublicKeys []string, derivationPath string) ([]*serialization.PubKeySignaturePair, error) {
+	pairs := make([]*serialization.PubKeySignaturePair, len(extendedPublicKeys))
+	for i, extendedPublicKey := range extendedPublicKeys {
+		extendedKey, err := bip32.DeserializeExtendedKey(extendedPublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		derivedKey, err := extendedKey.DeriveFromPath(derivationPath)
+		if err != nil {
+			return nil, err
+		}
+
+		pairs[i] = &serialization.PubKeySignaturePair{
+			ExtendedPublicKey: derivedKey.String(),
+		}
+	}
+	return pairs, nil
+}
+
 // IsTransactionFullySigned returns whether the transaction is fully signed and ready to broadcast.
 func IsTransactionFullySigned(partiallySignedTransactionBytes []byte) (bool, error) {
 	partiallySignedTransaction, err := serialization.DeserializePartiallySignedTransaction(partiallySignedTransactionBytes)
@@ -173,14 +181,11 @@ func IsTransactionFullySigned(partiallySignedTransactionBytes []byte) (bool, err
 }
 
 func isTransactionFullySigned(partiallySignedTransaction *serialization.PartiallySignedTransaction) bool {
-	for _, input := range partiallySignedTransaction.PartiallySignedInputs {
-		numSignatures := 0
-		for _, pair := range input.PubKeySignaturePairs {
-			if pair.Signature != nil {
-				numSignatures++
-			}
+	for i, input := range partiallySignedTransaction.PartiallySignedInputs {
+		if isInputFinalized(partiallySignedTransaction, i) {
+			continue
 		}
-		if uint32(numSignatures) < input.MinimumSignatures {
+		if uint32(numSignatures(input)) < input.MinimumSignatures {
 			return false
 		}
 	}
@@ -204,6 +209,9 @@ func ExtractTransactionDeserialized(partiallySignedTransaction *serialization.Pa
 	*externalapi.DomainTransaction, error) {
 
 	for i, input := range partiallySignedTransaction.PartiallySignedInputs {
+		if isInputFinalized(partiallySignedTransaction, i) {
+			continue
+		}
 		isMultisig := len(input.PubKeySignaturePairs) > 1
 		scriptBuilder := txscript.NewScriptBuilder()
 		if isMultisig {
@@ -251,6 +259,11 @@ func ExtractTransactionDeserialized(partiallySignedTransaction *serialization.Pa
 	return partiallySignedTransaction.Tx, nil
 }
 
+// isInputFinalized returns whether the signature script of the given input was already set by a PSKT finalizer
+func isInputFinalized(partiallySignedTransaction *serialization.PartiallySignedTransaction, inputIndex int) bool {
+	return len(partiallySignedTransaction.Tx.Inputs[inputIndex].SignatureScript) > 0
+}
+
 func partiallySignedInputMultisigRedeemScript(input *serialization.PartiallySignedInput, ecdsa bool) ([]byte, error) {
 	extendedPublicKeys := make([]string, len(input.PubKeySignaturePairs))
 	for i, pair := range input.PubKeySignaturePairs {
@@ -25,12 +25,58 @@ func main() {
 		err = showAddresses(config.(*showAddressesConfig))
 	case newAddressSubCmd:
 		err = newAddress(config.(*newAddressConfig))
+	case listUTXOsSubCmd:
+		err = listUTXOs(config.(*listUTXOsConfig))
+	case freezeSubCmd:
+		err = freezeUTXOs(config.(*freezeUTXOsConfig))
+	case unfreezeSubCmd:
+		err = unfreezeUTXOs(config.(*freezeUTXOsConfig))
+	case requestPaymentSubCmd:
+		err = requestPayment(config.(*requestPaymentConfig))
+	case invoicesSubCmd:
+		err = invoices(config.(*invoicesConfig))
+	case historySubCmd:
+		err = history(config.(*historyConfig))
+	case labelSubCmd:
+		err = label(config.(*labelConfig))
 	case dumpUnencryptedDataSubCmd:
 		err = dumpUnencryptedData(config.(*dumpUnencryptedDataConfig))
 	case startDaemonSubCmd:
 		err = startDaemon(config.(*startDaemonConfig))
+	case unlockSubCmd:
+		err = unlock(config.(*unlockConfig))
+	case lockSubCmd:
+		err = lock(config.(*lockConfig))
+	case rescanSubCmd:
+		err = rescan(config.(*rescanConfig))
 	case sweepSubCmd:
 		err = sweep(config.(*sweepConfig))
+	case softwareSignerSubCmd:
+		err = softwareSigner(config.(*softwareSignerConfig))
+	case multisigSubCmd + " " + multisigInitSubCmd:
+		err = multisigInit(config.(*multisigInitConfig))
+	case multisigSubCmd + " " + multisigJoinSubCmd:
+		err = multisigJoin(config.(*multisigJoinConfig))
+	case multisigSubCmd + " " + multisigFinalizeSubCmd:
+		err = multisigFinalize(config.(*multisigFinalizeConfig))
+	case swapSubCmd + " " + swapInitiateSubCmd:
+		err = swapInitiate(config.(*swapInitiateConfig))
+	case swapSubCmd + " " + swapParticipateSubCmd:
+		err = swapParticipate(config.(*swapParticipateConfig))
+	case swapSubCmd + " " + swapRedeemSubCmd:
+		err = swapRedeem(config.(*swapRedeemConfig))
+	case swapSubCmd + " " + swapRefundSubCmd:
+		err = swapRefund(config.(*swapRefundConfig))
+	case swapSubCmd + " " + swapAuditSubCmd:
+		err = swapAudit(config.(*swapAuditConfig))
+	case psktSubCmd + " " + psktCombineSubCmd:
+		err = psktCombine(config.(*psktCombineConfig))
+	case psktSubCmd + " " + psktInspectSubCmd:
+		err = psktInspect(config.(*psktConfig))
+	case psktSubCmd + " " + psktFinalizeSubCmd:
+		err = psktFinalize(config.(*psktConfig))
+	case psktSubCmd + " " + psktExtractSubCmd:
+		err = psktExtract(config.(*psktConfig))
 	default:
 		err = errors.Errorf("Unknown sub-command '%s'\n", subCmd)
 	}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/keys"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/utils"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+// pendingMultisigKeysFilePath returns the path where the keys of a cosigner are kept between
+// joining a multisig setup and finalizing it
+func pendingMultisigKeysFilePath(params *dagconfig.Params, keysFilePath string) string {
+	if keysFilePath == "" {
+		keysFilePath = keys.DefaultKeysFile(params)
+	}
+	return strings.TrimSuffix(keysFilePath, filepath.Ext(keysFilePath)) + ".multisig-pending.json"
+}
+
+func multisigInit(conf *multisigInitConfig) error {
+	descriptor, err := libkashwallet.NewMultisigDescriptor(conf.NetParams(), conf.MinimumSignatures, conf.NumCosigners, conf.ECDSA)
+	if err != nil {
+		return err
+	}
+
+	err = joinMultisigDescriptor(conf.NetParams(), descriptor, conf.KeysFile, conf.Password, conf.Import, conf.Yes)
+	if err != nil {
+		return err
+	}
+	return writeMultisigDescriptor(descriptor, conf.Descriptor)
+}
+
+func multisigJoin(conf *multisigJoinConfig) error {
+	descriptor, err := readMultisigDescriptor(conf.NetParams(), conf.Descriptor)
+	if err != nil {
+		return err
+	}
+	printMultisigDescriptor(descriptor)
+
+	err = joinMultisigDescriptor(conf.NetParams(), descriptor, conf.KeysFile, conf.Password, conf.Import, conf.Yes)
+	if err != nil {
+		return err
+	}
+	return writeMultisigDescriptor(descriptor, conf.Descriptor)
+}
+
+// joinMultisigDescriptor creates or imports the cosigner's mnemonic, keeps it in the pending
+// keys file, and adds its extended public key to the descriptor
+func joinMultisigDescriptor(params *dagconfig.Params, descriptor *libkashwallet.MultisigDescriptor,
+	keysFilePath string, password string, isImport bool, yes bool) error {
+
+	var encryptedMnemonics []*keys.EncryptedMnemonic
+	var extendedPublicKeys []string
+	var err error
+	if !isImport {
+		encryptedMnemonics, extendedPublicKeys, err = keys.CreateMnemonics(params, 1, password, true)
+	} else {
+		encryptedMnemonics, extendedPublicKeys, err = keys.ImportMnemonics(params, 1, password, true)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = descriptor.AddExtendedPublicKey(params, extendedPublicKeys[0])
+	if err != nil {
+		return err
+	}
+
+	pendingFile := keys.File{
+		Version:            keys.LastVersion,
+		EncryptedMnemonics: encryptedMnemonics,
+		ExtendedPublicKeys: extendedPublicKeys,
+		MinimumSignatures:  descriptor.MinimumSignatures,
+		ECDSA:              descriptor.ECDSA,
+	}
+	err = pendingFile.SetPath(params, pendingMultisigKeysFilePath(params, keysFilePath), yes)
+	if err != nil {
+		return err
+	}
+	err = pendingFile.Save()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Joined as cosigner #%d of %d with extended public key:\n%s\n\n",
+		len(descriptor.ExtendedPublicKeys), descriptor.NumCosigners, extendedPublicKeys[0])
+	fmt.Printf("Your keys are kept in %s until the setup is finalized. Use \"kashwallet dump-unencrypted-data "+
+		"--keys-file %s\" to back up your mnemonic\n\n", pendingFile.Path(), pendingFile.Path())
+	if descriptor.IsComplete() {
+		fmt.Println("All the cosigners joined. Every cosigner should now run \"kashwallet multisig finalize\" " +
+			"on the descriptor, one after the other, and then once more on the fully signed descriptor")
+	} else {
+		fmt.Printf("Pass the descriptor to the next cosigner, who should run \"kashwallet multisig join\" (%d more to join)\n",
+			descriptor.NumCosigners-uint32(len(descriptor.ExtendedPublicKeys)))
+	}
+	return nil
+}
+
+func multisigFinalize(conf *multisigFinalizeConfig) error {
+	descriptor, err := readMultisigDescriptor(conf.NetParams(), conf.Descriptor)
+	if err != nil {
+		return err
+	}
+	if !descriptor.IsComplete() {
+		return errors.Errorf("Only %d of the %d cosigners joined the descriptor",
+			len(descriptor.ExtendedPublicKeys), descriptor.NumCosigners)
+	}
+
+	pendingFilePath := pendingMultisigKeysFilePath(conf.NetParams(), conf.KeysFile)
+	pendingFile, err := keys.ReadKeysFile(conf.NetParams(), pendingFilePath)
+	if err != nil {
+		return errors.Wrapf(err, "Could not read the keys of this cosigner from %s", pendingFilePath)
+	}
+	extendedPublicKey := pendingFile.ExtendedPublicKeys[0]
+	isCosigner := false
+	for _, descriptorExtendedPublicKey := range descriptor.ExtendedPublicKeys {
+		if descriptorExtendedPublicKey == extendedPublicKey {
+			isCosigner = true
+			break
+		}
+	}
+	if !isCosigner {
+		return errors.Errorf("The keys in %s don't belong to any of the cosigners of the descriptor", pendingFilePath)
+	}
+
+	printMultisigDescriptor(descriptor)
+
+	if !descriptor.IsSignedBy(extendedPublicKey) {
+		if !conf.Yes {
+			err := confirmMultisigDescriptor("Sign the descriptor")
+			if err != nil {
+				return err
+			}
+		}
+		if len(conf.Password) == 0 {
+			conf.Password = keys.GetPassword("Password:")
+		}
+		mnemonics, err := pendingFile.DecryptMnemonics(conf.Password)
+		if err != nil {
+			return err
+		}
+		err = descriptor.Sign(conf.NetParams(), mnemonics[0])
+		if err != nil {
+			return err
+		}
+		err = writeMultisigDescriptor(descriptor, conf.Descriptor)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Signed the descriptor")
+	}
+
+	if !descriptor.IsFullySigned() {
+		fmt.Printf("%d of the %d cosigners signed the descriptor. Pass it to the next cosigner, and run "+
+			"\"kashwallet multisig finalize\" again once it's fully signed\n", len(descriptor.Signatures), descriptor.NumCosigners)
+		return nil
+	}
+
+	cosignerIndex, err := libkashwallet.MinimumCosignerIndex(pendingFile.ExtendedPublicKeys, descriptor.ExtendedPublicKeys)
+	if err != nil {
+		return err
+	}
+	file := keys.File{
+		Version:            keys.LastVersion,
+		EncryptedMnemonics: pendingFile.EncryptedMnemonics,
+		ExtendedPublicKeys: descriptor.ExtendedPublicKeys,
+		MinimumSignatures:  descriptor.MinimumSignatures,
+		CosignerIndex:      cosignerIndex,
+		ECDSA:              descriptor.ECDSA,
+	}
+	err = file.SetPath(conf.NetParams(), conf.KeysFile, conf.Yes)
+	if err != nil {
+		return err
+	}
+	err = file.TryLock()
+	if err != nil {
+		return err
+	}
+	err = file.Save()
+	if err != nil {
+		return err
+	}
+	err = os.Remove(pendingFilePath)
+	if err != nil {
+		return errors.Wrapf(err, "Could not remove %s", pendingFilePath)
+	}
+
+	fmt.Printf("The descriptor is signed by all the cosigners. Wrote the keys into %s\n", file.Path())
+	return nil
+}
+
+func readMultisigDescriptor(params *dagconfig.Params, path string) (*libkashwallet.MultisigDescriptor, error) {
+	serializedDescriptor, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not read the descriptor from %s", path)
+	}
+	return libkashwallet.ParseMultisigDescriptor(params, serializedDescriptor)
+}
+
+func writeMultisigDescriptor(descriptor *libkashwallet.MultisigDescriptor, path string) error {
+	serializedDescriptor, err := descriptor.Serialize()
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(path, serializedDescriptor, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "Could not write the descriptor to %s", path)
+	}
+	fmt.Printf("Wrote the descriptor into %s\n", path)
+	return nil
+}
+
+func printMultisigDescriptor(descriptor *libkashwallet.MultisigDescriptor) {
+	signatureScheme := "Schnorr"
+	if descriptor.ECDSA {
+		signatureScheme = "ECDSA"
+	}
+	fmt.Printf("Multisig descriptor: %d-of-%d %s wallet on %s\n", descriptor.MinimumSignatures,
+		descriptor.NumCosigners, signatureScheme, descriptor.Network)
+	for i, extendedPublicKey := range descriptor.ExtendedPublicKeys {
+		signed := ""
+		if descriptor.IsSignedBy(extendedPublicKey) {
+			signed = " (signed)"
+		}
+		fmt.Printf("\tCosigner #%d: %s%s\n", i+1, extendedPublicKey, signed)
+	}
+	if !descriptor.IsComplete() {
+		fmt.Println()
+		return
+	}
+
+	fmt.Printf("Fingerprint: %s\n", descriptor.Fingerprint())
+	fmt.Println("First receive addresses (compare them with the other cosigners):")
+	for cosignerIndex, addresses := range descriptor.Addresses {
+		for _, address := range addresses {
+			fmt.Printf("\tCosigner index %d: %s\n", cosignerIndex, address)
+		}
+	}
+	fmt.Println()
+}
+
+func confirmMultisigDescriptor(action string) error {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("%s only if the other cosigners confirmed the same fingerprint and addresses. "+
+		"Proceed (y/N)? ", action)
+	line, err := utils.ReadLine(reader)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+
+	if string(line) != "y" {
+		return errors.Errorf("Aborted by user")
+	}
+	return nil
+}
@@ -65,10 +65,16 @@ func parse(conf *parseConfig) error {
 				return err
 			}
 
-			addressString := scriptPublicKeyAddress.EncodeAddress()
-			if scriptPublicKeyType == txscript.NonStandardTy {
+			var addressString string
+			switch {
+			case scriptPublicKeyType == txscript.NonStandardTy:
 				scriptPublicKeyHex := hex.EncodeToString(output.ScriptPublicKey.Script)
 				addressString = fmt.Sprintf("<Non-standard transaction script public key: %s>", scriptPublicKeyHex)
+			case scriptPublicKeyAddress == nil:
+				scriptPublicKeyHex := hex.EncodeToString(output.ScriptPublicKey.Script)
+				addressString = fmt.Sprintf("<%s script public key: %s>", scriptPublicKeyType, scriptPublicKeyHex)
+			default:
+				addressString = scriptPublicKeyAddress.EncodeAddress()
 			}
 
 			fmt.Printf("Output %d: \tRecipient: %s \tAmount: %.2f Kaspa\n",
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/serialization"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/utils"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+func psktCombine(conf *psktCombineConfig) error {
+	transactionsHexes := conf.Transactions
+	for _, transactionFile := range conf.TransactionFiles {
+		transactionsHex, err := readTransactionsHexFile(transactionFile)
+		if err != nil {
+			return err
+		}
+		transactionsHexes = append(transactionsHexes, transactionsHex)
+	}
+	if len(transactionsHexes) < 2 {
+		return errors.Errorf("At least two PSKTs are required, using --transaction or --transaction-file")
+	}
+
+	// Every hex string may hold several transactions, so the PSKTs are combined index by index
+	var transactionsToCombine [][][]byte
+	for i, transactionsHex := range transactionsHexes {
+		transactions, err := decodeTransactionsFromHex(transactionsHex)
+		if err != nil {
+			return err
+		}
+		if i > 0 && len(transactions) != len(transactionsToCombine[0]) {
+			return errors.Errorf("PSKT #%d holds %d transactions, while PSKT #1 holds %d",
+				i+1, len(transactions), len(transactionsToCombine[0]))
+		}
+		transactionsToCombine = append(transactionsToCombine, transactions)
+	}
+
+	combinedTransactions := make([][]byte, len(transactionsToCombine[0]))
+	for i := range combinedTransactions {
+		psktsToCombine := make([][]byte, len(transactionsToCombine))
+		for j, transactions := range transactionsToCombine {
+			psktsToCombine[j] = transactions[i]
+		}
+
+		var err error
+		combinedTransactions[i], err = libkashwallet.CombinePSKTs(psktsToCombine)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Println(encodeTransactionsToHex(combinedTransactions))
+	return nil
+}
+
+func psktInspect(conf *psktConfig) error {
+	transactions, err := readPSKTs(conf)
+	if err != nil {
+		return err
+	}
+
+	for i, transaction := range transactions {
+		pskt, err := serialization.DeserializePSKT(transaction)
+		if err != nil {
+			return err
+		}
+		printPSKT(conf.NetParams(), i+1, pskt)
+	}
+	return nil
+}
+
+func printPSKT(params *dagconfig.Params, index int, pskt *serialization.PSKT) {
+	partiallySignedTransaction := pskt.PartiallySignedTransaction
+
+	fmt.Printf("PSKT #%d\n", index)
+	if pskt.Version == serialization.LegacyPSKTVersion {
+		fmt.Println("Version:\tlegacy (no global metadata)")
+	} else {
+		fmt.Printf("Version:\t%d\n", pskt.Version)
+		fmt.Printf("Network:\t%s\n", pskt.Global.Network)
+		fmt.Printf("ECDSA:\t\t%t\n", pskt.Global.ECDSA)
+
+		proprietaryKeys := make([]string, 0, len(pskt.Global.Proprietary))
+		for key := range pskt.Global.Proprietary {
+			proprietaryKeys = append(proprietaryKeys, key)
+		}
+		sort.Strings(proprietaryKeys)
+		for _, key := range proprietaryKeys {
+			fmt.Printf("Proprietary:\t%s = %x\n", key, pskt.Global.Proprietary[key])
+		}
+	}
+	fmt.Printf("Transaction ID:\t%s\n", consensushashing.TransactionID(partiallySignedTransaction.Tx))
+	fmt.Println()
+
+	allInputSompi := uint64(0)
+	missingSignatures := uint32(0)
+	finalizedInputs := 0
+	for i, input := range partiallySignedTransaction.Tx.Inputs {
+		partiallySignedInput := partiallySignedTransaction.PartiallySignedInputs[i]
+		allInputSompi += partiallySignedInput.PrevOutput.Value
+
+		signatureCount := uint32(0)
+		for _, pair := range partiallySignedInput.PubKeySignaturePairs {
+			if pair.Signature != nil {
+				signatureCount++
+			}
+		}
+
+		status := fmt.Sprintf("%d of %d required signatures", signatureCount, partiallySignedInput.MinimumSignatures)
+		if len(input.SignatureScript) > 0 {
+			status = "finalized"
+			finalizedInputs++
+		} else if signatureCount < partiallySignedInput.MinimumSignatures {
+			missingSignatures += partiallySignedInput.MinimumSignatures - signatureCount
+		}
+
+		fmt.Printf("Input %d:\tOutpoint: %s:%d\tAmount: %s KSH\tPath: %s\tStatus: %s\n", i,
+			input.PreviousOutpoint.TransactionID, input.PreviousOutpoint.Index,
+			utils.FormatKas(partiallySignedInput.PrevOutput.Value), partiallySignedInput.DerivationPath, status)
+	}
+	fmt.Println()
+
+	allOutputSompi := uint64(0)
+	for i, output := range partiallySignedTransaction.Tx.Outputs {
+		addressString := fmt.Sprintf("<Non-standard script public key: %s>", hex.EncodeToString(output.ScriptPublicKey.Script))
+		scriptPublicKeyType, address, err := txscript.ExtractScriptPubKeyAddress(output.ScriptPublicKey, params)
+		if err == nil && scriptPublicKeyType != txscript.NonStandardTy {
+			if address != nil {
+				addressString = address.EncodeAddress()
+			} else {
+				addressString = fmt.Sprintf("<%s script public key: %s>", scriptPublicKeyType, hex.EncodeToString(output.ScriptPublicKey.Script))
+			}
+		}
+		fmt.Printf("Output %d:\tRecipient: %s\tAmount: %s KSH\n", i, addressString, utils.FormatKas(output.Value))
+		allOutputSompi += output.Value
+	}
+	fmt.Println()
+	fmt.Printf("Fee:\t\t%d Sompi\n", allInputSompi-allOutputSompi)
+
+	switch {
+	case finalizedInputs == len(partiallySignedTransaction.Tx.Inputs):
+		fmt.Println("Status:\t\tfinalized, ready to be extracted")
+	case missingSignatures > 0:
+		fmt.Printf("Status:\t\tmissing %d signatures\n", missingSignatures)
+	default:
+		fmt.Println("Status:\t\tfully signed, ready to be finalized")
+	}
+	fmt.Println()
+}
+
+func psktFinalize(conf *psktConfig) error {
+	transactions, err := readPSKTs(conf)
+	if err != nil {
+		return err
+	}
+
+	finalizedTransactions := make([][]byte, len(transactions))
+	for i, transaction := range transactions {
+		finalizedTransactions[i], err = libkashwallet.FinalizePSKT(transaction)
+		if err != nil {
+			return errors.Wrapf(err, "Cannot finalize PSKT #%d", i+1)
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "The transaction is finalized and ready to be extracted")
+	fmt.Println(encodeTransactionsToHex(finalizedTransactions))
+	return nil
+}
+
+func psktExtract(conf *psktConfig) error {
+	transactions, err := readPSKTs(conf)
+	if err != nil {
+		return err
+	}
+
+	extractedTransactions := make([][]byte, len(transactions))
+	for i, transaction := range transactions {
+		domainTransaction, err := libkashwallet.ExtractPSKT(transaction)
+		if err != nil {
+			return errors.Wrapf(err, "Cannot extract PSKT #%d", i+1)
+		}
+		extractedTransactions[i], err = serialization.SerializeDomainTransaction(domainTransaction)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "The transaction can be broadcast with 'broadcast --domain'")
+	fmt.Println(encodeTransactionsToHex(extractedTransactions))
+	return nil
+}
+
+func readPSKTs(conf *psktConfig) ([][]byte, error) {
+	if conf.Transaction == "" && conf.TransactionFile == "" {
+		return nil, errors.Errorf("Either --transaction or --transaction-file is required")
+	}
+	if conf.Transaction != "" && conf.TransactionFile != "" {
+		return nil, errors.Errorf("Both --transaction and --transaction-file cannot be passed at the same time")
+	}
+
+	transactionsHex := conf.Transaction
+	if conf.TransactionFile != "" {
+		var err error
+		transactionsHex, err = readTransactionsHexFile(conf.TransactionFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return decodeTransactionsFromHex(transactionsHex)
+}
+
+func readTransactionsHexFile(path string) (string, error) {
+	transactionHexBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "Could not read hex from %s", path)
+	}
+	return strings.TrimSpace(string(transactionHexBytes)), nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+)
+
+func rescan(conf *rescanConfig) error {
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	// A rescan walks the whole DAG the node has, so it isn't bound by daemonTimeout
+	stream, err := daemonClient.Rescan(context.Background(), &pb.RescanRequest{
+		BirthdayDaaScore: conf.Birthday,
+		GapLimit:         conf.GapLimit,
+	})
+	if err != nil {
+		return err
+	}
+
+	lastStage := ""
+	for {
+		progress, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if progress.Message != "" {
+			fmt.Printf("\n%s\n", progress.Message)
+			continue
+		}
+		if lastStage != "" && progress.Stage != lastStage {
+			fmt.Println()
+		}
+		lastStage = progress.Stage
+		switch progress.Stage {
+		case "blocks":
+			fmt.Printf("\rScanning blocks: DAA score %d of %d", progress.Processed, progress.Total)
+		case "addresses":
+			fmt.Printf("\rScanning addresses: %d of %d indexes  ", progress.Processed, progress.Total)
+		case "done":
+			fmt.Printf("Found %d used addresses. The last used external index is %d and the last used "+
+				"internal index is %d\n", progress.UsedAddresses, progress.LastUsedExternalIndex, progress.LastUsedInternalIndex)
+		}
+	}
+}
@@ -6,10 +6,10 @@ import (
 	"os"
 	"strings"
 
-	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/client"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/keys"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/externalsigner"
 	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
 	"github.com/pkg/errors"
 )
@@ -20,11 +20,16 @@ func send(conf *sendConfig) error {
 		return err
 	}
 
-	if len(keysFile.ExtendedPublicKeys) > len(keysFile.EncryptedMnemonics) {
-		return errors.Errorf("Cannot use 'send' command for multisig wallet without all of the keys")
+	if conf.Signer == "" {
+		if keysFile.IsWatchOnly() {
+			return keys.ErrWatchOnly
+		}
+		if len(keysFile.ExtendedPublicKeys) > len(keysFile.EncryptedMnemonics) {
+			return errors.Errorf("Cannot use 'send' command for multisig wallet without all of the keys")
+		}
 	}
 
-	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), keysFile.Path())
 	if err != nil {
 		return err
 	}
@@ -33,6 +38,11 @@ func send(conf *sendConfig) error {
 	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
 	defer cancel()
 
+	inputs, err := parseOutpoints(conf.Inputs)
+	if err != nil {
+		return err
+	}
+
 	var sendAmountSompi uint64
 	if !conf.IsSendAll {
 		sendAmountSompi = uint64(conf.SendAmount * constants.SompiPerKaspa)
@@ -45,30 +55,27 @@ func send(conf *sendConfig) error {
 			Amount:                   sendAmountSompi,
 			IsSendAll:                conf.IsSendAll,
 			UseExistingChangeAddress: conf.UseExistingChangeAddress,
+			Inputs:                   inputs,
+			SelectionStrategy:        conf.SelectionStrategy,
 		})
 	if err != nil {
 		return err
 	}
 
-	if len(conf.Password) == 0 {
-		conf.Password = keys.GetPassword("Password:")
-	}
-	mnemonics, err := keysFile.DecryptMnemonics(conf.Password)
-	if err != nil {
-		if strings.Contains(err.Error(), "message authentication failed") {
-			fmt.Fprintf(os.Stderr, "Password decryption failed. Sometimes this is a result of not "+
-				"specifying the same keys file used by the wallet daemon process.\n")
+	var signedTransactions [][]byte
+	if conf.UseUnlocked {
+		signResponse, err := daemonClient.Sign(ctx, &pb.SignRequest{
+			UnsignedTransactions: createUnsignedTransactionsResponse.UnsignedTransactions,
+		})
+		if err != nil {
+			return err
 		}
-		return err
-	}
-
-	signedTransactions := make([][]byte, len(createUnsignedTransactionsResponse.UnsignedTransactions))
-	for i, unsignedTransaction := range createUnsignedTransactionsResponse.UnsignedTransactions {
-		signedTransaction, err := libkashwallet.Sign(conf.NetParams(), mnemonics, unsignedTransaction, keysFile.ECDSA)
+		signedTransactions = signResponse.SignedTransactions
+	} else {
+		signedTransactions, err = signForSend(conf, keysFile, createUnsignedTransactionsResponse.UnsignedTransactions)
 		if err != nil {
 			return err
 		}
-		signedTransactions[i] = signedTransaction
 	}
 
 	if len(signedTransactions) > 1 {
@@ -99,3 +106,49 @@ func send(conf *sendConfig) error {
 
 	return nil
 }
+
+func signForSend(conf *sendConfig, keysFile *keys.File, unsignedTransactions [][]byte) ([][]byte, error) {
+	signedTransactions := make([][]byte, len(unsignedTransactions))
+	if conf.Signer != "" {
+		signer, err := externalsigner.New(conf.Signer)
+		if err != nil {
+			return nil, err
+		}
+		for i, unsignedTransaction := range unsignedTransactions {
+			signedTransactions[i], err = signer.Sign(conf.NetParams(), unsignedTransaction, keysFile.ECDSA)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, signedTransaction := range signedTransactions {
+			isFullySigned, err := libkashwallet.IsTransactionFullySigned(signedTransaction)
+			if err != nil {
+				return nil, err
+			}
+			if !isFullySigned {
+				return nil, errors.Errorf("The external signer didn't sign on all of the transaction inputs")
+			}
+		}
+		return signedTransactions, nil
+	}
+
+	if len(conf.Password) == 0 {
+		conf.Password = keys.GetPassword("Password:")
+	}
+	mnemonics, err := keysFile.DecryptMnemonics(conf.Password)
+	if err != nil {
+		if strings.Contains(err.Error(), "message authentication failed") {
+			fmt.Fprintf(os.Stderr, "Password decryption failed. Sometimes this is a result of not "+
+				"specifying the same keys file used by the wallet daemon process.\n")
+		}
+		return nil, err
+	}
+
+	for i, unsignedTransaction := range unsignedTransactions {
+		signedTransactions[i], err = libkashwallet.Sign(conf.NetParams(), mnemonics, unsignedTransaction, keysFile.ECDSA)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return signedTransactions, nil
+}
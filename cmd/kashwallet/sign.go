@@ -8,6 +8,7 @@ import (
 
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/keys"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/externalsigner"
 	"github.com/pkg/errors"
 )
 
@@ -24,12 +25,30 @@ func sign(conf *signConfig) error {
 		return err
 	}
 
-	if len(conf.Password) == 0 {
-		conf.Password = keys.GetPassword("Password:")
-	}
-	privateKeys, err := keysFile.DecryptMnemonics(conf.Password)
-	if err != nil {
-		return err
+	var signFunc func(partiallySignedTransaction []byte) ([]byte, error)
+	if conf.Signer != "" {
+		signer, err := externalsigner.New(conf.Signer)
+		if err != nil {
+			return err
+		}
+		signFunc = func(partiallySignedTransaction []byte) ([]byte, error) {
+			return signer.Sign(conf.NetParams(), partiallySignedTransaction, keysFile.ECDSA)
+		}
+	} else {
+		if keysFile.IsWatchOnly() {
+			return keys.ErrWatchOnly
+		}
+
+		if len(conf.Password) == 0 {
+			conf.Password = keys.GetPassword("Password:")
+		}
+		privateKeys, err := keysFile.DecryptMnemonics(conf.Password)
+		if err != nil {
+			return err
+		}
+		signFunc = func(partiallySignedTransaction []byte) ([]byte, error) {
+			return libkashwallet.Sign(conf.NetParams(), privateKeys, partiallySignedTransaction, keysFile.ECDSA)
+		}
 	}
 
 	transactionsHex := conf.Transaction
@@ -47,8 +66,7 @@ func sign(conf *signConfig) error {
 
 	updatedPartiallySignedTransactions := make([][]byte, len(partiallySignedTransactions))
 	for i, partiallySignedTransaction := range partiallySignedTransactions {
-		updatedPartiallySignedTransactions[i], err =
-			libkashwallet.Sign(conf.NetParams(), privateKeys, partiallySignedTransaction, keysFile.ECDSA)
+		updatedPartiallySignedTransactions[i], err = signFunc(partiallySignedTransaction)
 		if err != nil {
 			return err
 		}
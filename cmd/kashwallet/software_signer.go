@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/hex"
+	"os"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/keys"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/externalsigner"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/serialization"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+func softwareSigner(conf *softwareSignerConfig) error {
+	var handler externalsigner.Handler
+	if conf.PrivateKey != "" {
+		privateKeyBytes, err := hex.DecodeString(conf.PrivateKey)
+		if err != nil {
+			return err
+		}
+		handler = privateKeySignerHandler(conf.NetParams(), privateKeyBytes)
+	} else {
+		keysFile, err := keys.ReadKeysFile(conf.NetParams(), conf.KeysFile)
+		if err != nil {
+			return err
+		}
+		if keysFile.IsWatchOnly() {
+			return keys.ErrWatchOnly
+		}
+		mnemonics, err := keysFile.DecryptMnemonics(conf.Password)
+		if err != nil {
+			return err
+		}
+		isMultisig := len(keysFile.ExtendedPublicKeys) > 1
+		handler = mnemonicsSignerHandler(conf.NetParams(), mnemonics, isMultisig)
+	}
+
+	return externalsigner.Serve(os.Stdin, os.Stdout, handler)
+}
+
+func mnemonicsSignerHandler(params *dagconfig.Params, mnemonics []string, isMultisig bool) externalsigner.Handler {
+	return func(request *externalsigner.Request) (*externalsigner.Response, error) {
+		err := checkSignerRequestNetwork(params, request)
+		if err != nil {
+			return nil, err
+		}
+
+		switch request.Command {
+		case externalsigner.CommandGetPublicKeys:
+			extendedPublicKeys := make([]string, len(mnemonics))
+			for i, mnemonic := range mnemonics {
+				extendedPublicKeys[i], err = libkashwallet.MasterPublicKeyFromMnemonic(params, mnemonic, isMultisig)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return &externalsigner.Response{ExtendedPublicKeys: extendedPublicKeys}, nil
+		case externalsigner.CommandSign:
+			return signRequest(request, func(serializedPSTx []byte) ([]byte, error) {
+				return libkashwallet.Sign(params, mnemonics, serializedPSTx, request.ECDSA)
+			})
+		default:
+			return nil, errors.Errorf("unknown command '%s'", request.Command)
+		}
+	}
+}
+
+func privateKeySignerHandler(params *dagconfig.Params, privateKeyBytes []byte) externalsigner.Handler {
+	return func(request *externalsigner.Request) (*externalsigner.Response, error) {
+		err := checkSignerRequestNetwork(params, request)
+		if err != nil {
+			return nil, err
+		}
+
+		switch request.Command {
+		case externalsigner.CommandGetPublicKeys:
+			publicKey, err := libkashwallet.PublicKeyFromPrivateKey(privateKeyBytes)
+			if err != nil {
+				return nil, err
+			}
+			return &externalsigner.Response{PublicKey: hex.EncodeToString(publicKey)}, nil
+		case externalsigner.CommandSign:
+			if request.ECDSA {
+				return nil, errors.New("signing with a raw private key is only supported for schnorr")
+			}
+			return signRequest(request, func(serializedPSTx []byte) ([]byte, error) {
+				return libkashwallet.SignWithPrivateKey(serializedPSTx, privateKeyBytes)
+			})
+		default:
+			return nil, errors.Errorf("unknown command '%s'", request.Command)
+		}
+	}
+}
+
+func checkSignerRequestNetwork(params *dagconfig.Params, request *externalsigner.Request) error {
+	if request.Network != params.Name {
+		return errors.Errorf("the request is for network %s, but the signer is on %s", request.Network, params.Name)
+	}
+	return nil
+}
+
+func signRequest(request *externalsigner.Request,
+	signFunc func(serializedPSTx []byte) ([]byte, error)) (*externalsigner.Response, error) {
+
+	serializedPSTx, err := hex.DecodeString(request.Transaction)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed transaction")
+	}
+	before, err := serialization.DeserializePartiallySignedTransaction(serializedPSTx)
+	if err != nil {
+		return nil, err
+	}
+
+	signedSerializedPSTx, err := signFunc(serializedPSTx)
+	if err != nil {
+		return nil, err
+	}
+	after, err := serialization.DeserializePartiallySignedTransaction(signedSerializedPSTx)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures, err := externalsigner.AddedSignatures(before, after)
+	if err != nil {
+		return nil, err
+	}
+	return &externalsigner.Response{Signatures: signatures}, nil
+}
@@ -3,5 +3,13 @@ package main
 import "github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/server"
 
 func startDaemon(conf *startDaemonConfig) error {
-	return server.Start(conf.NetParams(), conf.Listen, conf.RPCServer, conf.KeysFile, conf.Profile, conf.Timeout)
+	security := &server.SecurityConfig{
+		DisableTLS:   conf.NoTLS,
+		TLSCertFile:  conf.TLSCert,
+		TLSKeyFile:   conf.TLSKey,
+		DisableAuth:  conf.NoAuth,
+		APITokenFile: conf.TokenFile,
+	}
+	return server.Start(conf.NetParams(), conf.Listen, conf.RPCServer, conf.KeysFile, conf.Profile, conf.Timeout,
+		conf.GapLimit, security)
 }
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/keys"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/serialization"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/utils"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
+	"github.com/Kash-Protocol/kashd/domain/dagconfig"
+	"github.com/Kash-Protocol/kashd/util"
+	"github.com/pkg/errors"
+)
+
+func swapInitiate(conf *swapInitiateConfig) error {
+	secret, secretHash, err := libkashwallet.NewSwapSecret()
+	if err != nil {
+		return err
+	}
+
+	contract, txIDs, err := fundSwapContract(conf.NetParams(), &conf.daemonConnectionFlags, conf.KeysFile, conf.Password,
+		conf.To, conf.Amount, secretHash[:], conf.LockDuration)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Secret:\t\t\t%x\n", secret)
+	fmt.Println("Keep the secret private. Use it to redeem the contract of the participant once you audited it.")
+	fmt.Println()
+	printSwapContract(contract)
+	printSwapTransactionIDs(txIDs)
+	return nil
+}
+
+func swapParticipate(conf *swapParticipateConfig) error {
+	secretHash, err := hex.DecodeString(conf.SecretHash)
+	if err != nil {
+		return errors.Wrap(err, "The secret hash is not valid hex")
+	}
+
+	contract, txIDs, err := fundSwapContract(conf.NetParams(), &conf.daemonConnectionFlags, conf.KeysFile, conf.Password,
+		conf.To, conf.Amount, secretHash, conf.LockDuration)
+	if err != nil {
+		return err
+	}
+
+	printSwapContract(contract)
+	printSwapTransactionIDs(txIDs)
+	return nil
+}
+
+// fundSwapContract creates a contract that the given recipient can redeem, and that can be refunded
+// to a new address of the wallet, and pays the given amount to it
+func fundSwapContract(params *dagconfig.Params, daemonFlags *daemonConnectionFlags, keysFilePath string, password string,
+	recipient string, amount float64, secretHash []byte, lockDuration time.Duration) (*libkashwallet.SwapContract, []string, error) {
+
+	keysFile, err := readSwapKeysFile(params, keysFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	recipientAddress, err := util.DecodeAddress(recipient, params.Prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	if lockDuration <= 0 {
+		return nil, nil, errors.Errorf("The lock duration must be positive")
+	}
+
+	daemonClient, tearDown, err := connectToDaemon(daemonFlags, params, keysFile.Path())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	newAddressResponse, err := daemonClient.NewAddress(ctx, &pb.NewAddressRequest{})
+	if err != nil {
+		return nil, nil, err
+	}
+	refundAddress, err := util.DecodeAddress(newAddressResponse.Address, params.Prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lockTime := uint64(time.Now().Add(lockDuration).UnixMilli())
+	contract, err := libkashwallet.NewSwapContract(params, recipientAddress, refundAddress, secretHash, lockTime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	createUnsignedTransactionsResponse, err :=
+		daemonClient.CreateUnsignedTransactions(ctx, &pb.CreateUnsignedTransactionsRequest{
+			Address: contract.Address.String(),
+			Amount:  uint64(amount * constants.SompiPerKaspa),
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mnemonics, err := decryptSwapMnemonics(keysFile, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	signedTransactions := make([][]byte, len(createUnsignedTransactionsResponse.UnsignedTransactions))
+	for i, unsignedTransaction := range createUnsignedTransactionsResponse.UnsignedTransactions {
+		signedTransactions[i], err = libkashwallet.Sign(params, mnemonics, unsignedTransaction, keysFile.ECDSA)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Since we waited for user input when getting the password, which could take unbound amount of time -
+	// create a new context for broadcast, to reset the timeout.
+	broadcastCtx, broadcastCancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer broadcastCancel()
+
+	response, err := daemonClient.Broadcast(broadcastCtx, &pb.BroadcastRequest{Transactions: signedTransactions})
+	if err != nil {
+		return nil, nil, err
+	}
+	return contract, response.TxIDs, nil
+}
+
+func swapRedeem(conf *swapRedeemConfig) error {
+	return spendSwapContract(conf.NetParams(), &conf.daemonConnectionFlags, conf.KeysFile, conf.Password,
+		conf.Contract, conf.Secret, false)
+}
+
+func swapRefund(conf *swapRefundConfig) error {
+	return spendSwapContract(conf.NetParams(), &conf.daemonConnectionFlags, conf.KeysFile, conf.Password,
+		conf.Contract, "", true)
+}
+
+// spendSwapContract redeems or refunds all the UTXOs of a contract to a new address of the wallet.
+// When redeeming without a secret, it's looked for in the transactions that redeemed other contracts
+// with the same secret hash.
+func spendSwapContract(params *dagconfig.Params, daemonFlags *daemonConnectionFlags, keysFilePath string, password string,
+	contractHex string, secretHex string, isRefund bool) error {
+
+	contract, err := parseSwapContract(params, contractHex)
+	if err != nil {
+		return err
+	}
+	keysFile, err := readSwapKeysFile(params, keysFilePath)
+	if err != nil {
+		return err
+	}
+
+	spenderAddress := contract.RecipientAddress
+	if isRefund {
+		spenderAddress = contract.RefundAddress
+		lockTime := time.UnixMilli(int64(contract.LockTime))
+		if time.Now().Before(lockTime) {
+			return errors.Errorf("The contract can't be refunded before its lock time (%s)", lockTime.Format(time.RFC3339))
+		}
+	}
+	derivationPath, err := walletAddressDerivationPath(params, keysFile, spenderAddress)
+	if err != nil {
+		return err
+	}
+
+	daemonClient, tearDown, err := connectToDaemon(daemonFlags, params, keysFile.Path())
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	var secret []byte
+	if !isRefund {
+		if secretHex != "" {
+			secret, err = hex.DecodeString(secretHex)
+			if err != nil {
+				return errors.Wrap(err, "The secret is not valid hex")
+			}
+		} else {
+			findSwapSecretResponse, err := daemonClient.FindSwapSecret(ctx, &pb.FindSwapSecretRequest{SecretHash: contract.SecretHash[:]})
+			if err != nil {
+				return err
+			}
+			if !findSwapSecretResponse.Found {
+				return errors.Errorf("The secret of the contract wasn't revealed yet. Pass it with --secret if you know it")
+			}
+			fmt.Printf("Found the secret in transaction %s\n", findSwapSecretResponse.TransactionId)
+			secret = findSwapSecretResponse.Secret
+		}
+	}
+
+	getExternalSpendableUTXOsResponse, err := daemonClient.GetExternalSpendableUTXOs(ctx, &pb.GetExternalSpendableUTXOsRequest{
+		Address: contract.Address.String(),
+	})
+	if err != nil {
+		return err
+	}
+	contractUTXOs, err := libkashwallet.KaspawalletdUTXOsTolibkashwalletUTXOs(getExternalSpendableUTXOsResponse.Entries)
+	if err != nil {
+		return err
+	}
+
+	newAddressResponse, err := daemonClient.NewAddress(ctx, &pb.NewAddressRequest{})
+	if err != nil {
+		return err
+	}
+	toAddress, err := util.DecodeAddress(newAddressResponse.Address, params.Prefix)
+	if err != nil {
+		return err
+	}
+
+	tx, err := contract.CreateSpendTransaction(params, contractUTXOs, toAddress, isRefund)
+	if err != nil {
+		return err
+	}
+
+	mnemonics, err := decryptSwapMnemonics(keysFile, password)
+	if err != nil {
+		return err
+	}
+	keyPair, err := libkashwallet.SwapKeyPair(params, mnemonics[0], derivationPath)
+	if err != nil {
+		return err
+	}
+	err = contract.SignSpendTransaction(tx, keyPair, secret)
+	if err != nil {
+		return err
+	}
+	serializedTransaction, err := serialization.SerializeDomainTransaction(tx)
+	if err != nil {
+		return err
+	}
+
+	broadcastCtx, broadcastCancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer broadcastCancel()
+
+	response, err := daemonClient.Broadcast(broadcastCtx, &pb.BroadcastRequest{
+		IsDomain:     true,
+		Transactions: [][]byte{serializedTransaction},
+	})
+	if err != nil {
+		return err
+	}
+
+	action := "Redeemed"
+	if isRefund {
+		action = "Refunded"
+	}
+	fmt.Printf("%s %s KSH to %s\n", action, utils.FormatKas(tx.Outputs[0].Value), toAddress)
+	printSwapTransactionIDs(response.TxIDs)
+	return nil
+}
+
+func swapAudit(conf *swapAuditConfig) error {
+	params := conf.NetParams()
+	contract, err := parseSwapContract(params, conf.Contract)
+	if err != nil {
+		return err
+	}
+	printSwapContract(contract)
+	fmt.Println()
+
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, params, "")
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	getExternalSpendableUTXOsResponse, err := daemonClient.GetExternalSpendableUTXOs(ctx, &pb.GetExternalSpendableUTXOsRequest{
+		Address: contract.Address.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(getExternalSpendableUTXOsResponse.Entries) > 0 {
+		totalAmount := uint64(0)
+		fmt.Println("Contract UTXOs:")
+		for _, entry := range getExternalSpendableUTXOsResponse.Entries {
+			fmt.Printf("\t%s:%d\t%s KSH\n", entry.Outpoint.TransactionId, entry.Outpoint.Index,
+				utils.FormatKas(entry.UtxoEntry.Amount))
+			totalAmount += entry.UtxoEntry.Amount
+		}
+		fmt.Printf("Locked amount:\t\t%s KSH\n", utils.FormatKas(totalAmount))
+		return nil
+	}
+
+	findSwapSecretResponse, err := daemonClient.FindSwapSecret(ctx, &pb.FindSwapSecretRequest{SecretHash: contract.SecretHash[:]})
+	if err != nil {
+		return err
+	}
+	if findSwapSecretResponse.Found {
+		fmt.Printf("The secret was revealed in transaction %s\n", findSwapSecretResponse.TransactionId)
+		fmt.Printf("Secret:\t\t\t%x\n", findSwapSecretResponse.Secret)
+		return nil
+	}
+	fmt.Println("The contract is not funded, or it was already refunded")
+	return nil
+}
+
+func parseSwapContract(params *dagconfig.Params, contractHex string) (*libkashwallet.SwapContract, error) {
+	script, err := hex.DecodeString(strings.TrimSpace(contractHex))
+	if err != nil {
+		return nil, errors.Wrap(err, "The contract is not valid hex")
+	}
+	return libkashwallet.ParseSwapContract(params, script)
+}
+
+func printSwapContract(contract *libkashwallet.SwapContract) {
+	fmt.Printf("Contract:\t\t%x\n", contract.Script)
+	fmt.Printf("Contract address:\t%s\n", contract.Address)
+	fmt.Printf("Recipient address:\t%s\n", contract.RecipientAddress)
+	fmt.Printf("Refund address:\t\t%s\n", contract.RefundAddress)
+	fmt.Printf("Secret hash:\t\t%x\n", contract.SecretHash)
+	fmt.Printf("Lock time:\t\t%s\n", time.UnixMilli(int64(contract.LockTime)).Format(time.RFC3339))
+}
+
+func printSwapTransactionIDs(txIDs []string) {
+	fmt.Println("Transaction ID(s): ")
+	for _, txID := range txIDs {
+		fmt.Printf("\t%s\n", txID)
+	}
+}
+
+// readSwapKeysFile reads the keys file and makes sure it can take part in atomic swaps, whose contracts
+// lock to a single schnorr public key
+func readSwapKeysFile(params *dagconfig.Params, keysFilePath string) (*keys.File, error) {
+	keysFile, err := keys.ReadKeysFile(params, keysFilePath)
+	if err != nil {
+		return nil, err
+	}
+	if keysFile.IsWatchOnly() {
+		return nil, keys.ErrWatchOnly
+	}
+	if len(keysFile.ExtendedPublicKeys) > 1 || keysFile.ECDSA {
+		return nil, errors.Errorf("Atomic swaps are only supported by single signer schnorr wallets")
+	}
+	return keysFile, nil
+}
+
+func decryptSwapMnemonics(keysFile *keys.File, password string) ([]string, error) {
+	if len(password) == 0 {
+		password = keys.GetPassword("Password:")
+	}
+	mnemonics, err := keysFile.DecryptMnemonics(password)
+	if err != nil {
+		if strings.Contains(err.Error(), "message authentication failed") {
+			fmt.Fprintf(os.Stderr, "Password decryption failed. Sometimes this is a result of not "+
+				"specifying the same keys file used by the wallet daemon process.\n")
+		}
+		return nil, err
+	}
+	return mnemonics, nil
+}
+
+// walletAddressDerivationPath returns the derivation path of the given address among the addresses
+// the wallet used so far
+func walletAddressDerivationPath(params *dagconfig.Params, keysFile *keys.File, address util.Address) (string, error) {
+	lastUsedIndexes := map[uint32]uint32{
+		libkashwallet.ExternalKeychain: keysFile.LastUsedExternalIndex(),
+		libkashwallet.InternalKeychain: keysFile.LastUsedInternalIndex(),
+	}
+	for _, keychain := range []uint32{libkashwallet.ExternalKeychain, libkashwallet.InternalKeychain} {
+		for index := uint32(0); index <= lastUsedIndexes[keychain]; index++ {
+			path := fmt.Sprintf("m/%d/%d", keychain, index)
+			walletAddress, err := libkashwallet.Address(params, keysFile.ExtendedPublicKeys, keysFile.MinimumSignatures,
+				path, keysFile.ECDSA)
+			if err != nil {
+				return "", err
+			}
+			if walletAddress.String() == address.String() {
+				return path, nil
+			}
+		}
+	}
+	return "", errors.Errorf("The address %s doesn't belong to the wallet", address)
+}
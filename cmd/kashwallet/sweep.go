@@ -5,9 +5,9 @@ import (
 	"encoding/hex"
 	"fmt"
 
-	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/client"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/externalsigner"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/libkashwallet/serialization"
 	"github.com/Kash-Protocol/kashd/cmd/kashwallet/utils"
 	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
@@ -27,15 +27,35 @@ import (
 const feePerInput = 10000
 
 func sweep(conf *sweepConfig) error {
+	var signer *externalsigner.Signer
+	var privateKeyBytes, publicKeybytes []byte
+	var err error
+	if conf.Signer != "" {
+		signer, err = externalsigner.New(conf.Signer)
+		if err != nil {
+			return err
+		}
+		_, publicKeyHex, err := signer.PublicKeys(conf.NetParams())
+		if err != nil {
+			return err
+		}
+		if publicKeyHex == "" {
+			return errors.Errorf("The external signer doesn't hold a private key that can be swept")
+		}
+		publicKeybytes, err = hex.DecodeString(publicKeyHex)
+		if err != nil {
+			return errors.Wrapf(err, "The external signer returned a malformed public key")
+		}
+	} else {
+		privateKeyBytes, err = hex.DecodeString(conf.PrivateKey)
+		if err != nil {
+			return err
+		}
 
-	privateKeyBytes, err := hex.DecodeString(conf.PrivateKey)
-	if err != nil {
-		return err
-	}
-
-	publicKeybytes, err := libkashwallet.PublicKeyFromPrivateKey(privateKeyBytes)
-	if err != nil {
-		return err
+		publicKeybytes, err = libkashwallet.PublicKeyFromPrivateKey(privateKeyBytes)
+		if err != nil {
+			return err
+		}
 	}
 
 	addressPubKey, err := util.NewAddressPublicKey(publicKeybytes, conf.NetParams().Prefix)
@@ -48,7 +68,7 @@ func sweep(conf *sweepConfig) error {
 		return err
 	}
 
-	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
 	if err != nil {
 		return err
 	}
@@ -94,7 +114,12 @@ func sweep(conf *sweepConfig) error {
 		return err
 	}
 
-	serializedSplitTransactions, err := signWithSchnorrPrivateKey(conf.NetParams(), privateKeyBytes, splitTransactions)
+	var serializedSplitTransactions [][]byte
+	if signer != nil {
+		serializedSplitTransactions, err = signWithExternalSigner(conf.NetParams(), signer, publicKeybytes, splitTransactions)
+	} else {
+		serializedSplitTransactions, err = signWithSchnorrPrivateKey(conf.NetParams(), privateKeyBytes, splitTransactions)
+	}
 	if err != nil {
 		return err
 	}
@@ -148,7 +173,8 @@ func createSplitTransactionsWithSchnorrPrivteKey(
 
 	extraMass := uint64(7000) // Account for future signatures.
 
-	massCalculater := txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp)
+	massCalculater := txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp,
+		params.MassPerSpliceOp)
 
 	scriptPublicKey, err := txscript.PayToAddrScript(toAddress)
 	if err != nil {
@@ -240,3 +266,45 @@ func signWithSchnorrPrivateKey(params *dagconfig.Params, privateKeyBytes []byte,
 
 	return serializedDomainTransactions, nil
 }
+
+func signWithExternalSigner(params *dagconfig.Params, signer *externalsigner.Signer, publicKey []byte,
+	domainTransactions []*externalapi.DomainTransaction) ([][]byte, error) {
+
+	publicKeyHex := hex.EncodeToString(publicKey)
+	serializedDomainTransactions := make([][]byte, len(domainTransactions))
+	for i, domainTransaction := range domainTransactions {
+		partiallySignedTransaction := &serialization.PartiallySignedTransaction{
+			Tx:                    domainTransaction,
+			PartiallySignedInputs: make([]*serialization.PartiallySignedInput, len(domainTransaction.Inputs)),
+		}
+		for j, input := range domainTransaction.Inputs {
+			partiallySignedTransaction.PartiallySignedInputs[j] = &serialization.PartiallySignedInput{
+				PrevOutput: &externalapi.DomainTransactionOutput{
+					Value:           input.UTXOEntry.Amount(),
+					ScriptPublicKey: input.UTXOEntry.ScriptPublicKey(),
+				},
+				MinimumSignatures:    1,
+				PubKeySignaturePairs: []*serialization.PubKeySignaturePair{{ExtendedPublicKey: publicKeyHex}},
+			}
+		}
+
+		serializedPSTx, err := serialization.SerializePartiallySignedTransaction(partiallySignedTransaction)
+		if err != nil {
+			return nil, err
+		}
+		signedSerializedPSTx, err := signer.Sign(params, serializedPSTx, false)
+		if err != nil {
+			return nil, err
+		}
+		signedTransaction, err := libkashwallet.ExtractTransaction(signedSerializedPSTx, false)
+		if err != nil {
+			return nil, err
+		}
+		serializedDomainTransactions[i], err = serialization.SerializeDomainTransaction(signedTransaction)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return serializedDomainTransactions, nil
+}
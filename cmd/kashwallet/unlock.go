@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/daemon/pb"
+	"github.com/Kash-Protocol/kashd/cmd/kashwallet/keys"
+)
+
+func unlock(conf *unlockConfig) error {
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	if len(conf.Password) == 0 {
+		conf.Password = keys.GetPassword("Password:")
+	}
+
+	// The password prompt could take an unbound amount of time, so the timeout starts after it
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.Unlock(ctx, &pb.UnlockRequest{
+		Password:       conf.Password,
+		TimeoutSeconds: uint32(conf.Timeout / time.Second),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("The wallet is unlocked until %s\n", time.Unix(response.UnlockedUntil, 0).Format(time.RFC3339))
+	return nil
+}
+
+func lock(conf *lockConfig) error {
+	daemonClient, tearDown, err := connectToDaemon(&conf.daemonConnectionFlags, conf.NetParams(), "")
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	_, err = daemonClient.Lock(ctx, &pb.LockRequest{})
+	if err != nil {
+		return err
+	}
+	fmt.Println("The wallet is locked")
+	return nil
+}
@@ -0,0 +1,84 @@
+package consensus
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+)
+
+// ConvertDAGToDot returns a graphviz DOT script of the given blocks, with an edge from every block to each
+// of its parents. nodeAttributes may be nil, or return the DOT attributes of a block, such as its label.
+func ConvertDAGToDot(blockHashes []*externalapi.DomainHash,
+	parents func(blockHash *externalapi.DomainHash) ([]*externalapi.DomainHash, error),
+	nodeAttributes func(blockHash *externalapi.DomainHash) (string, error)) (string, error) {
+
+	var dotScriptBuilder strings.Builder
+	dotScriptBuilder.WriteString("digraph {\n\trankdir = TB; \n")
+
+	edges := []string{}
+
+	for _, hash := range blockHashes {
+		attributes := ""
+		if nodeAttributes != nil {
+			var err error
+			attributes, err = nodeAttributes(hash)
+			if err != nil {
+				return "", err
+			}
+		}
+		if attributes != "" {
+			dotScriptBuilder.WriteString(fmt.Sprintf("\t\"%s\" [%s];\n", hash, attributes))
+		} else {
+			dotScriptBuilder.WriteString(fmt.Sprintf("\t\"%s\";\n", hash))
+		}
+
+		blockParents, err := parents(hash)
+		if err != nil {
+			return "", err
+		}
+
+		for _, parentHash := range blockParents {
+			edges = append(edges, fmt.Sprintf("\t\"%s\" -> \"%s\";", hash, parentHash))
+		}
+	}
+
+	dotScriptBuilder.WriteString("\n")
+
+	dotScriptBuilder.WriteString(strings.Join(edges, "\n"))
+
+	dotScriptBuilder.WriteString("\n}")
+
+	return dotScriptBuilder.String(), nil
+}
+
+// RenderDotScript renders the given DOT script to an SVG file.
+// It requires graphviz installed.
+func RenderDotScript(dotScript string, filename string) error {
+	command := exec.Command("dot", "-Tsvg")
+	stdin, err := command.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("Error creating stdin pipe: %s", err)
+	}
+	spawn("renderDotScript", func() {
+		defer stdin.Close()
+
+		_, err = io.WriteString(stdin, dotScript)
+		if err != nil {
+			panic(fmt.Errorf("Error writing dotScript into stdin pipe: %s", err))
+		}
+	})
+
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+	svg, err := command.Output()
+	if err != nil {
+		return fmt.Errorf("Error getting output of dot: %s\nstderr:\n%s", err, stderr.String())
+	}
+
+	return ioutil.WriteFile(filename, svg, 0600)
+}
@@ -0,0 +1,58 @@
+package consensus_test
+
+import (
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus"
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+)
+
+func TestConvertDAGToDot(t *testing.T) {
+	genesis := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1})
+	blockA := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{2})
+	blockB := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{3})
+	parents := map[externalapi.DomainHash][]*externalapi.DomainHash{
+		*genesis: {},
+		*blockA:  {genesis},
+		*blockB:  {blockA, genesis},
+	}
+	getParents := func(blockHash *externalapi.DomainHash) ([]*externalapi.DomainHash, error) {
+		return parents[*blockHash], nil
+	}
+
+	dotScript, err := consensus.ConvertDAGToDot([]*externalapi.DomainHash{genesis, blockA, blockB}, getParents, nil)
+	if err != nil {
+		t.Fatalf("ConvertDAGToDot: %+v", err)
+	}
+	expectedDotScript := "digraph {\n\trankdir = TB; \n" +
+		"\t\"" + genesis.String() + "\";\n" +
+		"\t\"" + blockA.String() + "\";\n" +
+		"\t\"" + blockB.String() + "\";\n" +
+		"\n" +
+		"\t\"" + blockA.String() + "\" -> \"" + genesis.String() + "\";\n" +
+		"\t\"" + blockB.String() + "\" -> \"" + blockA.String() + "\";\n" +
+		"\t\"" + blockB.String() + "\" -> \"" + genesis.String() + "\";\n" +
+		"}"
+	if dotScript != expectedDotScript {
+		t.Fatalf("unexpected DOT script. Want:\n%s\nGot:\n%s", expectedDotScript, dotScript)
+	}
+
+	nodeAttributes := func(blockHash *externalapi.DomainHash) (string, error) {
+		if blockHash.Equal(blockA) {
+			return "label=\"A\"", nil
+		}
+		return "", nil
+	}
+	dotScript, err = consensus.ConvertDAGToDot([]*externalapi.DomainHash{blockA}, getParents, nodeAttributes)
+	if err != nil {
+		t.Fatalf("ConvertDAGToDot: %+v", err)
+	}
+	expectedDotScript = "digraph {\n\trankdir = TB; \n" +
+		"\t\"" + blockA.String() + "\" [label=\"A\"];\n" +
+		"\n" +
+		"\t\"" + blockA.String() + "\" -> \"" + genesis.String() + "\";\n" +
+		"}"
+	if dotScript != expectedDotScript {
+		t.Fatalf("unexpected DOT script. Want:\n%s\nGot:\n%s", expectedDotScript, dotScript)
+	}
+}
@@ -12,5 +12,8 @@ type TransactionValidator interface {
 		povBlockHash *externalapi.DomainHash, povBlockPastMedianTime int64) error
 	ValidateTransactionInContextAndPopulateFee(stagingArea *StagingArea,
 		tx *externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash) error
+	ValidateTransactionInContextIgnoringScriptsAndPopulateFee(stagingArea *StagingArea,
+		tx *externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash) error
+	ValidateTransactionsScripts(transactions []*externalapi.DomainTransaction) error
 	PopulateMass(transaction *externalapi.DomainTransaction)
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
 	"github.com/Kash-Protocol/kashd/domain/consensus/processes/blockprocessor/blocklogger"
 	"github.com/Kash-Protocol/kashd/infrastructure/logger"
+	"github.com/Kash-Protocol/kashd/infrastructure/metrics"
 )
 
 // blockProcessor is responsible for processing incoming blocks
@@ -149,8 +150,11 @@ func (bp *blockProcessor) ValidateAndInsertBlock(block *externalapi.DomainBlock,
 	onEnd := logger.LogAndMeasureExecutionTime(log, "ValidateAndInsertBlock")
 	defer onEnd()
 
+	start := time.Now()
 	stagingArea := model.NewStagingArea()
-	return bp.validateAndInsertBlock(stagingArea, block, false, shouldValidateAgainstUTXO, false)
+	virtualChangeSet, blockStatus, err := bp.validateAndInsertBlock(stagingArea, block, false, shouldValidateAgainstUTXO, false)
+	metrics.ObserveBlockProcessed(isHeaderOnlyBlock(block), err == nil, time.Since(start))
+	return virtualChangeSet, blockStatus, err
 }
 
 func (bp *blockProcessor) ValidateAndInsertImportedPruningPoint(newPruningPoint *externalapi.DomainHash) error {
@@ -166,7 +170,9 @@ func (bp *blockProcessor) ValidateAndInsertBlockWithTrustedData(block *externala
 	onEnd := logger.LogAndMeasureExecutionTime(log, "ValidateAndInsertBlockWithTrustedData")
 	defer onEnd()
 
+	start := time.Now()
 	stagingArea := model.NewStagingArea()
-
-	return bp.validateAndInsertBlockWithTrustedData(stagingArea, block, shouldValidateAgainstUTXO)
+	virtualChangeSet, blockStatus, err := bp.validateAndInsertBlockWithTrustedData(stagingArea, block, shouldValidateAgainstUTXO)
+	metrics.ObserveBlockProcessed(isHeaderOnlyBlock(block.Block), err == nil, time.Since(start))
+	return virtualChangeSet, blockStatus, err
 }
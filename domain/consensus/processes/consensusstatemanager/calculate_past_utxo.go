@@ -164,6 +164,46 @@ func (csm *consensusStateManager) applyMergeSetBlocks(stagingArea *model.Staging
 	}
 	log.Tracef("The past median time for block %s is: %d", blockHash, selectedParentMedianTime)
 
+	// The scripts of the merge set transactions are first skipped, and then verified all at once in parallel.
+	// Transactions with invalid scripts are rare, since they make the blocks that contain them invalid, so
+	// only if one of the accepted transactions turns out to have invalid scripts, the merge set is applied
+	// again, this time verifying the scripts of every transaction before accepting it.
+	multiblockAcceptanceData, accumulatedUTXODiff, err := csm.acceptMergeSetTransactions(stagingArea, blockHash,
+		mergeSetBlocks, selectedParentPastUTXODiff, selectedParentMedianTime, daaScore, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var acceptedTransactions []*externalapi.DomainTransaction
+	for _, blockAcceptanceData := range multiblockAcceptanceData {
+		for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+			if transactionAcceptanceData.IsAccepted && !transactionhelper.IsCoinBase(transactionAcceptanceData.Transaction) {
+				acceptedTransactions = append(acceptedTransactions, transactionAcceptanceData.Transaction)
+			}
+		}
+	}
+	err = csm.transactionValidator.ValidateTransactionsScripts(acceptedTransactions)
+	if err == nil {
+		return multiblockAcceptanceData, accumulatedUTXODiff, nil
+	}
+	if !errors.As(err, &(ruleerrors.RuleError{})) {
+		return nil, nil, err
+	}
+
+	log.Debugf("An accepted transaction in the merge set of block %s has invalid scripts: %s. "+
+		"Applying the merge set again while verifying the scripts of every transaction", blockHash, err)
+	return csm.acceptMergeSetTransactions(stagingArea, blockHash,
+		mergeSetBlocks, selectedParentPastUTXODiff, selectedParentMedianTime, daaScore, true)
+}
+
+// acceptMergeSetTransactions applies the transactions of the given merge set blocks, in order, on top of
+// selectedParentPastUTXODiff. If shouldVerifyScripts is false the transaction scripts aren't verified, and
+// it's up to the caller to verify the scripts of the accepted transactions.
+func (csm *consensusStateManager) acceptMergeSetTransactions(stagingArea *model.StagingArea,
+	blockHash *externalapi.DomainHash, mergeSetBlocks []*externalapi.DomainBlock,
+	selectedParentPastUTXODiff externalapi.UTXODiff, selectedParentMedianTime int64, daaScore uint64,
+	shouldVerifyScripts bool) (externalapi.AcceptanceData, externalapi.MutableUTXODiff, error) {
+
 	multiblockAcceptanceData := make(externalapi.AcceptanceData, len(mergeSetBlocks))
 	accumulatedUTXODiff := selectedParentPastUTXODiff.CloneMutable()
 	accumulatedMass := uint64(0)
@@ -184,8 +224,10 @@ func (csm *consensusStateManager) applyMergeSetBlocks(stagingArea *model.Staging
 			log.Tracef("Attempting to accept transaction %s in block %s",
 				transactionID, mergeSetBlockHash)
 
+			var err error
 			isAccepted, accumulatedMass, err = csm.maybeAcceptTransaction(stagingArea, transaction, blockHash,
-				isSelectedParent, accumulatedUTXODiff, accumulatedMass, selectedParentMedianTime, daaScore)
+				isSelectedParent, accumulatedUTXODiff, accumulatedMass, selectedParentMedianTime, daaScore,
+				shouldVerifyScripts)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -216,7 +258,7 @@ func (csm *consensusStateManager) applyMergeSetBlocks(stagingArea *model.Staging
 func (csm *consensusStateManager) maybeAcceptTransaction(stagingArea *model.StagingArea,
 	transaction *externalapi.DomainTransaction, blockHash *externalapi.DomainHash, isSelectedParent bool,
 	accumulatedUTXODiff externalapi.MutableUTXODiff, accumulatedMassBefore uint64, selectedParentPastMedianTime int64,
-	blockDAAScore uint64) (isAccepted bool, accumulatedMassAfter uint64, err error) {
+	blockDAAScore uint64, shouldVerifyScripts bool) (isAccepted bool, accumulatedMassAfter uint64, err error) {
 
 	transactionID := consensushashing.TransactionID(transaction)
 	log.Tracef("maybeAcceptTransaction start for transaction %s in block %s", transactionID, blockHash)
@@ -243,8 +285,13 @@ func (csm *consensusStateManager) maybeAcceptTransaction(stagingArea *model.Stag
 		log.Tracef("Transaction %s is the coinbase of block %s", transactionID, blockHash)
 	} else {
 		log.Tracef("Validating transaction %s in block %s", transactionID, blockHash)
-		err = csm.transactionValidator.ValidateTransactionInContextAndPopulateFee(
-			stagingArea, transaction, blockHash)
+		if shouldVerifyScripts {
+			err = csm.transactionValidator.ValidateTransactionInContextAndPopulateFee(
+				stagingArea, transaction, blockHash)
+		} else {
+			err = csm.transactionValidator.ValidateTransactionInContextIgnoringScriptsAndPopulateFee(
+				stagingArea, transaction, blockHash)
+		}
 		if err != nil {
 			if !errors.As(err, &(ruleerrors.RuleError{})) {
 				return false, 0, err
@@ -9,6 +9,7 @@ import (
 type consensusStateManager struct {
 	maxBlockParents   externalapi.KType
 	mergeSetSizeLimit uint64
+	maxBlockMass      uint64
 	genesisHash       *externalapi.DomainHash
 	databaseContext   model.DBManager
 
@@ -43,6 +44,7 @@ func New(
 	databaseContext model.DBManager,
 	maxBlockParents externalapi.KType,
 	mergeSetSizeLimit uint64,
+	maxBlockMass uint64,
 	genesisHash *externalapi.DomainHash,
 
 	ghostdagManager model.GHOSTDAGManager,
@@ -71,6 +73,7 @@ func New(
 	csm := &consensusStateManager{
 		maxBlockParents:   maxBlockParents,
 		mergeSetSizeLimit: mergeSetSizeLimit,
+		maxBlockMass:      maxBlockMass,
 		genesisHash:       genesisHash,
 
 		databaseContext: databaseContext,
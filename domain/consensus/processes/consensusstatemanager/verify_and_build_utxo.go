@@ -80,21 +80,58 @@ func (csm *consensusStateManager) validateBlockTransactionsAgainstPastUTXO(stagi
 		log.Tracef("Populating transaction %s with UTXO entries", transactionID)
 		err = csm.populateTransactionWithUTXOEntriesFromVirtualOrDiff(stagingArea, transaction, pastUTXODiff)
 		if err != nil {
-			return err
+			return csm.firstBlockTransactionError(block, i, err)
 		}
 
 		log.Tracef("Validating transaction %s and populating it with fee", transactionID)
-		err = csm.transactionValidator.ValidateTransactionInContextAndPopulateFee(
+		err = csm.transactionValidator.ValidateTransactionInContextIgnoringScriptsAndPopulateFee(
 			stagingArea, transaction, blockHash)
 		if err != nil {
-			return err
+			return csm.firstBlockTransactionError(block, i, err)
 		}
 		log.Tracef("Validation against the block's past UTXO "+
-			"passed for transaction %s in block %s", transactionID, blockHash)
+			"passed for transaction %s in block %s, except for its scripts", transactionID, blockHash)
+	}
+
+	err = csm.checkBlockMassInContext(block)
+	if err != nil {
+		return err
+	}
+
+	log.Tracef("Validating the scripts of the transactions in block %s", blockHash)
+	return csm.transactionValidator.ValidateTransactionsScripts(
+		block.Transactions[transactionhelper.CoinbaseTransactionIndex+1:])
+}
+
+// checkBlockMassInContext checks the mass of the block against the mass limit again, now that the
+// mass of its transactions includes the parts that depend on the UTXO entries they spend.
+func (csm *consensusStateManager) checkBlockMassInContext(block *externalapi.DomainBlock) error {
+	mass := uint64(0)
+	for _, transaction := range block.Transactions {
+		massBefore := mass
+		mass += transaction.Mass
+		if mass > csm.maxBlockMass || mass < massBefore {
+			return errors.Wrapf(ruleerrors.ErrBlockMassTooHigh, "block exceeded the mass limit of %d",
+				csm.maxBlockMass)
+		}
 	}
 	return nil
 }
 
+// firstBlockTransactionError returns the error sequential validation of the block transactions would
+// have returned, given that the transaction at failedTransactionIndex failed with err, and that the
+// scripts of the transactions before it weren't verified yet.
+func (csm *consensusStateManager) firstBlockTransactionError(block *externalapi.DomainBlock,
+	failedTransactionIndex int, err error) error {
+
+	scriptsErr := csm.transactionValidator.ValidateTransactionsScripts(
+		block.Transactions[transactionhelper.CoinbaseTransactionIndex+1 : failedTransactionIndex])
+	if scriptsErr != nil {
+		return scriptsErr
+	}
+	return err
+}
+
 func (csm *consensusStateManager) validateAcceptedIDMerkleRoot(block *externalapi.DomainBlock,
 	blockHash *externalapi.DomainHash, acceptanceData externalapi.AcceptanceData) error {
 
@@ -1,7 +1,9 @@
 package transactionvalidator
 
 import (
+	"github.com/Kash-Protocol/kashd/domain/consensus/model"
 	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
 )
 
 // PopulateMass calculates and populates the mass of the given transaction
@@ -11,3 +13,25 @@ func (v *transactionValidator) PopulateMass(transaction *externalapi.DomainTrans
 	}
 	transaction.Mass = v.txMassCalculator.CalculateTransactionMass(transaction)
 }
+
+// populateMassInContext adds to the mass of the given covenants transaction the mass of the splice
+// ops in the redeem scripts it executes, which is only known once it's populated with the UTXO
+// entries it spends. The mass is recalculated rather than added to, since a transaction may be
+// validated in context more than once.
+func (v *transactionValidator) populateMassInContext(stagingArea *model.StagingArea,
+	tx *externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash) error {
+
+	if tx.Version < constants.CovenantsTransactionVersion {
+		return nil
+	}
+	povDAAScore, err := v.daaBlocksStore.DAAScore(v.databaseContext, stagingArea, povBlockHash)
+	if err != nil {
+		return err
+	}
+	if povDAAScore < v.covenantsActivationDAAScore {
+		return nil
+	}
+
+	tx.Mass = v.txMassCalculator.CalculateTransactionMass(tx) + v.txMassCalculator.CalculateRedeemScriptsSpliceOpMass(tx)
+	return nil
+}
@@ -0,0 +1,196 @@
+package transactionvalidator
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/ruleerrors"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
+	"github.com/pkg/errors"
+)
+
+// minInputsPerScriptVerificationJob is the minimum number of inputs of a single
+// transaction that are verified together. Every job recalculates the sighash values
+// that are shared between the inputs of a transaction, so splitting a transaction
+// into too many jobs would cost more than it saves.
+const minInputsPerScriptVerificationJob = 8
+
+// scriptVerificationJob is a range of inputs of a single transaction, verified by
+// a single worker. The jobs of a batch are ordered by transaction and input index.
+type scriptVerificationJob struct {
+	transactionIndex int
+	transaction      *externalapi.DomainTransaction
+	startInputIndex  int
+	endInputIndex    int
+}
+
+// scriptVerificationResult is the outcome of a scriptVerificationJob
+type scriptVerificationResult struct {
+	// err is the error of the first input of the job that failed verification
+	err error
+
+	// missingOutpoints are the outpoints of the inputs of the job that have no UTXO entry
+	missingOutpoints []*externalapi.DomainOutpoint
+}
+
+// ValidateTransactionsScripts verifies the scripts of all the inputs of the given
+// transactions in a bounded pool of workers. If several inputs are invalid, the
+// error of the first of them (by transaction index and then by input index) is
+// returned, which is the same error sequential verification would return.
+func (v *transactionValidator) ValidateTransactionsScripts(transactions []*externalapi.DomainTransaction) error {
+	jobs := v.scriptVerificationJobs(transactions)
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	results := make([]scriptVerificationResult, len(jobs))
+	if len(jobs) == 1 || v.scriptVerificationWorkers <= 1 {
+		for i, job := range jobs {
+			results[i] = v.verifyScripts(job)
+			if results[i].err != nil {
+				break
+			}
+		}
+	} else {
+		v.verifyScriptsInParallel(jobs, results)
+	}
+
+	// Like in sequential verification, the missing UTXO entries of a transaction
+	// are reported only if none of its inputs failed verification
+	missingOutpointsTransactionIndex := -1
+	var missingOutpoints []*externalapi.DomainOutpoint
+	for i, job := range jobs {
+		if missingOutpointsTransactionIndex != -1 && job.transactionIndex != missingOutpointsTransactionIndex {
+			break
+		}
+		if results[i].err != nil {
+			return results[i].err
+		}
+		if len(results[i].missingOutpoints) > 0 {
+			missingOutpointsTransactionIndex = job.transactionIndex
+			missingOutpoints = append(missingOutpoints, results[i].missingOutpoints...)
+		}
+	}
+	if len(missingOutpoints) > 0 {
+		return ruleerrors.NewErrMissingTxOut(missingOutpoints)
+	}
+	return nil
+}
+
+// scriptVerificationJobs splits the inputs of the given transactions into jobs,
+// such that the inputs of a big transaction can be verified by all the workers.
+func (v *transactionValidator) scriptVerificationJobs(
+	transactions []*externalapi.DomainTransaction) []*scriptVerificationJob {
+
+	workers := v.scriptVerificationWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var jobs []*scriptVerificationJob
+	for transactionIndex, transaction := range transactions {
+		numInputs := len(transaction.Inputs)
+		inputsPerJob := int(math.Ceil(float64(numInputs) / float64(workers)))
+		if inputsPerJob < minInputsPerScriptVerificationJob {
+			inputsPerJob = minInputsPerScriptVerificationJob
+		}
+		for start := 0; start < numInputs; start += inputsPerJob {
+			end := start + inputsPerJob
+			if end > numInputs {
+				end = numInputs
+			}
+			jobs = append(jobs, &scriptVerificationJob{
+				transactionIndex: transactionIndex,
+				transaction:      transaction,
+				startInputIndex:  start,
+				endInputIndex:    end,
+			})
+		}
+	}
+	return jobs
+}
+
+// verifyScriptsInParallel verifies the given jobs in scriptVerificationWorkers
+// goroutines, and sets the result of every job in results. Once a job fails,
+// the jobs that come after it are skipped, since their results are never
+// reported.
+func (v *transactionValidator) verifyScriptsInParallel(jobs []*scriptVerificationJob, results []scriptVerificationResult) {
+	numWorkers := v.scriptVerificationWorkers
+	if numWorkers > len(jobs) {
+		numWorkers = len(jobs)
+	}
+
+	firstFailedJobIndex := int64(len(jobs))
+	nextJobIndex := int64(-1)
+	waitGroup := sync.WaitGroup{}
+	waitGroup.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			defer waitGroup.Done()
+			for {
+				jobIndex := atomic.AddInt64(&nextJobIndex, 1)
+				if jobIndex >= atomic.LoadInt64(&firstFailedJobIndex) {
+					return
+				}
+
+				results[jobIndex] = v.verifyScripts(jobs[jobIndex])
+				if results[jobIndex].err == nil {
+					continue
+				}
+				for {
+					currentFirstFailedJobIndex := atomic.LoadInt64(&firstFailedJobIndex)
+					if jobIndex >= currentFirstFailedJobIndex ||
+						atomic.CompareAndSwapInt64(&firstFailedJobIndex, currentFirstFailedJobIndex, jobIndex) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	waitGroup.Wait()
+}
+
+// verifyScripts verifies the scripts of the inputs of the given job, up to the
+// first input that fails verification.
+func (v *transactionValidator) verifyScripts(job *scriptVerificationJob) scriptVerificationResult {
+	var missingOutpoints []*externalapi.DomainOutpoint
+	sighashReusedValues := &consensushashing.SighashReusedValues{}
+
+	for i := job.startInputIndex; i < job.endInputIndex; i++ {
+		input := job.transaction.Inputs[i]
+
+		// Create a new script engine for the script pair.
+		sigScript := input.SignatureScript
+		utxoEntry := input.UTXOEntry
+		if utxoEntry == nil {
+			missingOutpoints = append(missingOutpoints, &input.PreviousOutpoint)
+			continue
+		}
+
+		scriptPubKey := utxoEntry.ScriptPublicKey()
+		vm, err := txscript.NewEngine(scriptPubKey, job.transaction, i, txscript.ScriptNoFlags,
+			v.sigCache, v.sigCacheECDSA, sighashReusedValues)
+		if err != nil {
+			return scriptVerificationResult{err: errors.Wrapf(ruleerrors.ErrScriptMalformed, "failed to parse input "+
+				"%d which references output %s - "+
+				"%s (input script bytes %x, prev "+
+				"output script bytes %x)",
+				i,
+				input.PreviousOutpoint, err, sigScript, scriptPubKey)}
+		}
+
+		// Execute the script pair.
+		if err := vm.Execute(); err != nil {
+			return scriptVerificationResult{err: errors.Wrapf(ruleerrors.ErrScriptValidation, "failed to validate input "+
+				"%d which references output %s - "+
+				"%s (input script bytes %x, prev output "+
+				"script bytes %x)",
+				i,
+				input.PreviousOutpoint, err, sigScript, scriptPubKey)}
+		}
+	}
+	return scriptVerificationResult{missingOutpoints: missingOutpoints}
+}
@@ -0,0 +1,222 @@
+package transactionvalidator
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/ruleerrors"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/subnetworks"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
+	"github.com/Kash-Protocol/kashd/util"
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/pkg/errors"
+)
+
+func newScriptVerificationTestValidator(workers int) *transactionValidator {
+	v := New(0, false, 0, 0, 0, nil, nil, nil, nil, nil, 0).(*transactionValidator)
+	v.scriptVerificationWorkers = workers
+	return v
+}
+
+// createSignedTransactions creates numTransactions transactions with numInputs inputs each,
+// all of them spending pay-to-pubkey outputs and signed with a single key
+func createSignedTransactions(t testing.TB, numTransactions int, numInputs int) []*externalapi.DomainTransaction {
+	privateKey, err := secp256k1.GenerateSchnorrKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateSchnorrKeyPair: %+v", err)
+	}
+	publicKey, err := privateKey.SchnorrPublicKey()
+	if err != nil {
+		t.Fatalf("SchnorrPublicKey: %+v", err)
+	}
+	publicKeySerialized, err := publicKey.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize: %+v", err)
+	}
+	address, err := util.NewAddressPublicKey(publicKeySerialized[:], util.Bech32PrefixKashSim)
+	if err != nil {
+		t.Fatalf("NewAddressPublicKey: %+v", err)
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %+v", err)
+	}
+
+	transactions := make([]*externalapi.DomainTransaction, numTransactions)
+	for i := range transactions {
+		inputs := make([]*externalapi.DomainTransactionInput, numInputs)
+		for j := range inputs {
+			inputs[j] = &externalapi.DomainTransactionInput{
+				PreviousOutpoint: externalapi.DomainOutpoint{
+					TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{byte(i), byte(j)}),
+					Index:         uint32(j),
+				},
+				Sequence:   constants.MaxTxInSequenceNum,
+				SigOpCount: 1,
+				UTXOEntry:  utxo.NewUTXOEntry(uint64(1000+j), scriptPublicKey, false, 0),
+			}
+		}
+		transaction := &externalapi.DomainTransaction{
+			Version: constants.MaxTransactionVersion,
+			Inputs:  inputs,
+			Outputs: []*externalapi.DomainTransactionOutput{{
+				Value:           1,
+				ScriptPublicKey: scriptPublicKey,
+			}},
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+		}
+
+		sighashReusedValues := &consensushashing.SighashReusedValues{}
+		for j, input := range transaction.Inputs {
+			input.SignatureScript, err = txscript.SignatureScript(transaction, j, consensushashing.SigHashAll,
+				privateKey, sighashReusedValues)
+			if err != nil {
+				t.Fatalf("SignatureScript: %+v", err)
+			}
+		}
+		transactions[i] = transaction
+	}
+	return transactions
+}
+
+func TestValidateTransactionsScripts(t *testing.T) {
+	const numTransactions = 5
+	const numInputs = 20
+
+	tests := []struct {
+		name string
+
+		// invalidInputs are the inputs whose signature is replaced with the signature of another input
+		invalidInputs [][2]int
+
+		// missingInputs are the inputs whose UTXO entry is removed
+		missingInputs [][2]int
+
+		expectedError             error
+		expectedFailedInputIndex  int
+		expectedMissingOutpoints  int
+		expectedFailedTransaction int
+	}{
+		{
+			name: "all valid",
+		},
+		{
+			name:                      "single invalid input",
+			invalidInputs:             [][2]int{{3, 17}},
+			expectedError:             ruleerrors.ErrScriptValidation,
+			expectedFailedTransaction: 3,
+			expectedFailedInputIndex:  17,
+		},
+		{
+			name:                      "the first invalid input is reported",
+			invalidInputs:             [][2]int{{4, 0}, {2, 19}, {2, 5}, {3, 1}},
+			expectedError:             ruleerrors.ErrScriptValidation,
+			expectedFailedTransaction: 2,
+			expectedFailedInputIndex:  5,
+		},
+		{
+			name:                     "missing UTXO entries",
+			missingInputs:            [][2]int{{1, 2}, {1, 15}, {3, 4}},
+			expectedMissingOutpoints: 2,
+		},
+		{
+			name:                      "an invalid input of the same transaction precedes missing UTXO entries",
+			missingInputs:             [][2]int{{1, 2}},
+			invalidInputs:             [][2]int{{1, 18}},
+			expectedError:             ruleerrors.ErrScriptValidation,
+			expectedFailedTransaction: 1,
+			expectedFailedInputIndex:  18,
+		},
+		{
+			name:                     "missing UTXO entries precede an invalid input of a later transaction",
+			missingInputs:            [][2]int{{1, 2}},
+			invalidInputs:            [][2]int{{2, 0}},
+			expectedMissingOutpoints: 1,
+		},
+	}
+
+	for _, workers := range []int{1, 2, 16} {
+		for _, test := range tests {
+			transactions := createSignedTransactions(t, numTransactions, numInputs)
+			for _, invalidInput := range test.invalidInputs {
+				inputs := transactions[invalidInput[0]].Inputs
+				inputs[invalidInput[1]].SignatureScript = inputs[(invalidInput[1]+1)%numInputs].SignatureScript
+			}
+			for _, missingInput := range test.missingInputs {
+				transactions[missingInput[0]].Inputs[missingInput[1]].UTXOEntry = nil
+			}
+
+			v := newScriptVerificationTestValidator(workers)
+			err := v.ValidateTransactionsScripts(transactions)
+
+			testName := fmt.Sprintf("%s with %d workers", test.name, workers)
+			if test.expectedMissingOutpoints > 0 {
+				var errMissingTxOut ruleerrors.ErrMissingTxOut
+				if !errors.As(err, &errMissingTxOut) {
+					t.Fatalf("%s: expected ErrMissingTxOut but got %v", testName, err)
+				}
+				if len(errMissingTxOut.MissingOutpoints) != test.expectedMissingOutpoints {
+					t.Fatalf("%s: expected %d missing outpoints but got %d", testName,
+						test.expectedMissingOutpoints, len(errMissingTxOut.MissingOutpoints))
+				}
+				continue
+			}
+			if test.expectedError == nil {
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %+v", testName, err)
+				}
+				continue
+			}
+			if !errors.Is(err, test.expectedError) {
+				t.Fatalf("%s: expected %s but got %v", testName, test.expectedError, err)
+			}
+
+			// The error must be the one the failed input gets when it's verified on its own
+			failedTransaction := transactions[test.expectedFailedTransaction]
+			expectedErr := v.verifyScripts(&scriptVerificationJob{
+				transaction:     failedTransaction,
+				startInputIndex: test.expectedFailedInputIndex,
+				endInputIndex:   test.expectedFailedInputIndex + 1,
+			}).err
+			if expectedErr == nil || err.Error() != expectedErr.Error() {
+				t.Fatalf("%s: expected the error %v but got %v", testName, expectedErr, err)
+			}
+		}
+	}
+}
+
+func benchmarkValidateTransactionsScripts(b *testing.B, numTransactions int, numInputs int, workers int) {
+	transactions := createSignedTransactions(b, numTransactions, numInputs)
+	v := newScriptVerificationTestValidator(workers)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// A fresh cache in every iteration, so that the signatures are actually verified
+		v.sigCache = txscript.NewSigCache(sigCacheSize)
+		err := v.ValidateTransactionsScripts(transactions)
+		if err != nil {
+			b.Fatalf("ValidateTransactionsScripts: %+v", err)
+		}
+	}
+}
+
+func BenchmarkValidateTransactionsScriptsSequential(b *testing.B) {
+	benchmarkValidateTransactionsScripts(b, 100, 2, 1)
+}
+
+func BenchmarkValidateTransactionsScriptsParallel(b *testing.B) {
+	benchmarkValidateTransactionsScripts(b, 100, 2, runtime.NumCPU())
+}
+
+func BenchmarkValidateTransactionsScriptsManyInputsSequential(b *testing.B) {
+	benchmarkValidateTransactionsScripts(b, 1, 200, 1)
+}
+
+func BenchmarkValidateTransactionsScriptsManyInputsParallel(b *testing.B) {
+	benchmarkValidateTransactionsScripts(b, 1, 200, runtime.NumCPU())
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/Kash-Protocol/kashd/domain/consensus/model"
 	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
 	"github.com/Kash-Protocol/kashd/domain/consensus/ruleerrors"
-	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
 	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
 	"github.com/Kash-Protocol/kashd/domain/consensus/utils/transactionhelper"
 	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
@@ -58,16 +57,36 @@ func (v *transactionValidator) ValidateTransactionInContextIgnoringUTXO(stagingA
 		return errors.Wrapf(ruleerrors.ErrUnfinalizedTx, "unfinalized transaction %v", tx)
 	}
 
+	if tx.Version >= constants.CovenantsTransactionVersion && povBlockDAAScore < v.covenantsActivationDAAScore {
+		return errors.Wrapf(ruleerrors.ErrTransactionVersionIsUnknown, "transaction version %d is not active "+
+			"before DAA score %d", tx.Version, v.covenantsActivationDAAScore)
+	}
+
 	return nil
 }
 
 // ValidateTransactionInContextAndPopulateFee validates the transaction against its referenced UTXO, and
-// populates its fee field.
+// populates its fee field. The mass of a covenants transaction is updated to include the splice ops of
+// the redeem scripts it executes.
 //
 // Note: if the function fails, there's no guarantee that the transaction fee field will remain unaffected.
 func (v *transactionValidator) ValidateTransactionInContextAndPopulateFee(stagingArea *model.StagingArea,
 	tx *externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash) error {
 
+	err := v.ValidateTransactionInContextIgnoringScriptsAndPopulateFee(stagingArea, tx, povBlockHash)
+	if err != nil {
+		return err
+	}
+
+	return v.ValidateTransactionsScripts([]*externalapi.DomainTransaction{tx})
+}
+
+// ValidateTransactionInContextIgnoringScriptsAndPopulateFee is like ValidateTransactionInContextAndPopulateFee,
+// except that it doesn't verify the scripts of the transaction inputs. It's meant for callers that verify the
+// scripts of many transactions at once with ValidateTransactionsScripts.
+func (v *transactionValidator) ValidateTransactionInContextIgnoringScriptsAndPopulateFee(stagingArea *model.StagingArea,
+	tx *externalapi.DomainTransaction, povBlockHash *externalapi.DomainHash) error {
+
 	err := v.checkTransactionCoinbaseMaturity(stagingArea, povBlockHash, tx)
 	if err != nil {
 		return err
@@ -95,12 +114,7 @@ func (v *transactionValidator) ValidateTransactionInContextAndPopulateFee(stagin
 		return err
 	}
 
-	err = v.validateTransactionScripts(tx)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return v.populateMassInContext(stagingArea, tx, povBlockHash)
 }
 
 func (v *transactionValidator) checkTransactionCoinbaseMaturity(stagingArea *model.StagingArea,
@@ -225,46 +239,6 @@ func (v *transactionValidator) checkTransactionSequenceLock(stagingArea *model.S
 	return nil
 }
 
-func (v *transactionValidator) validateTransactionScripts(tx *externalapi.DomainTransaction) error {
-	var missingOutpoints []*externalapi.DomainOutpoint
-	sighashReusedValues := &consensushashing.SighashReusedValues{}
-
-	for i, input := range tx.Inputs {
-		// Create a new script engine for the script pair.
-		sigScript := input.SignatureScript
-		utxoEntry := input.UTXOEntry
-		if utxoEntry == nil {
-			missingOutpoints = append(missingOutpoints, &input.PreviousOutpoint)
-			continue
-		}
-
-		scriptPubKey := utxoEntry.ScriptPublicKey()
-		vm, err := txscript.NewEngine(scriptPubKey, tx, i, txscript.ScriptNoFlags, v.sigCache, v.sigCacheECDSA, sighashReusedValues)
-		if err != nil {
-			return errors.Wrapf(ruleerrors.ErrScriptMalformed, "failed to parse input "+
-				"%d which references output %s - "+
-				"%s (input script bytes %x, prev "+
-				"output script bytes %x)",
-				i,
-				input.PreviousOutpoint, err, sigScript, scriptPubKey)
-		}
-
-		// Execute the script pair.
-		if err := vm.Execute(); err != nil {
-			return errors.Wrapf(ruleerrors.ErrScriptValidation, "failed to validate input "+
-				"%d which references output %s - "+
-				"%s (input script bytes %x, prev output "+
-				"script bytes %x)",
-				i,
-				input.PreviousOutpoint, err, sigScript, scriptPubKey)
-		}
-	}
-	if len(missingOutpoints) > 0 {
-		return ruleerrors.NewErrMissingTxOut(missingOutpoints)
-	}
-	return nil
-}
-
 func (v *transactionValidator) calcTxSequenceLockFromReferencedUTXOEntries(stagingArea *model.StagingArea,
 	povBlockHash *externalapi.DomainHash, tx *externalapi.DomainTransaction) (*sequenceLock, error) {
 
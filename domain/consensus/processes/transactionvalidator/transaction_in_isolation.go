@@ -47,7 +47,13 @@ func (v *transactionValidator) ValidateTransactionInIsolation(tx *externalapi.Do
 		return err
 	}
 
-	if tx.Version > constants.MaxTransactionVersion {
+	// Covenants transactions are validated against the activation DAA score in context.
+	// There's no use for them as coinbase transactions.
+	maxTransactionVersion := constants.CovenantsTransactionVersion
+	if transactionhelper.IsCoinBase(tx) {
+		maxTransactionVersion = constants.MaxTransactionVersion
+	}
+	if tx.Version > maxTransactionVersion {
 		return errors.Wrapf(ruleerrors.ErrTransactionVersionIsUnknown, "validation failed: unknown transaction version. ")
 	}
 
@@ -112,6 +112,30 @@ func TestValidateTransactionInIsolationAndPopulateMass(t *testing.T) {
 					tx.Payload = []byte{1}
 				},
 				ruleerrors.ErrInvalidPayload, 0},
+			{"covenants transaction", 1, 1, 1,
+				subnetworks.SubnetworkIDNative,
+				nil,
+				func(tx *externalapi.DomainTransaction) {
+					tx.Version = constants.CovenantsTransactionVersion
+				},
+				nil, 0},
+			{"unknown transaction version", 1, 1, 1,
+				subnetworks.SubnetworkIDNative,
+				nil,
+				func(tx *externalapi.DomainTransaction) {
+					tx.Version = constants.CovenantsTransactionVersion + 1
+				},
+				ruleerrors.ErrTransactionVersionIsUnknown, 0},
+			{"covenants coinbase",
+				0,
+				1,
+				1,
+				subnetworks.SubnetworkIDNative,
+				&txSubnetworkData{subnetworks.SubnetworkIDCoinbase, 0, nil},
+				func(tx *externalapi.DomainTransaction) {
+					tx.Version = constants.CovenantsTransactionVersion
+				},
+				ruleerrors.ErrTransactionVersionIsUnknown, 0},
 		}
 
 		for _, test := range tests {
@@ -1,6 +1,8 @@
 package transactionvalidator
 
 import (
+	"runtime"
+
 	"github.com/Kash-Protocol/kashd/domain/consensus/model"
 	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
 	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
@@ -24,6 +26,8 @@ type transactionValidator struct {
 	sigCache                                *txscript.SigCache
 	sigCacheECDSA                           *txscript.SigCacheECDSA
 	txMassCalculator                        *txmass.Calculator
+	scriptVerificationWorkers               int
+	covenantsActivationDAAScore             uint64
 }
 
 // New instantiates a new TransactionValidator
@@ -36,7 +40,8 @@ func New(blockCoinbaseMaturity uint64,
 	pastMedianTimeManager model.PastMedianTimeManager,
 	ghostdagDataStore model.GHOSTDAGDataStore,
 	daaBlocksStore model.DAABlocksStore,
-	txMassCalculator *txmass.Calculator) model.TransactionValidator {
+	txMassCalculator *txmass.Calculator,
+	covenantsActivationDAAScore uint64) model.TransactionValidator {
 
 	return &transactionValidator{
 		blockCoinbaseMaturity:                   blockCoinbaseMaturity,
@@ -51,5 +56,7 @@ func New(blockCoinbaseMaturity uint64,
 		sigCache:                                txscript.NewSigCache(sigCacheSize),
 		sigCacheECDSA:                           txscript.NewSigCacheECDSA(sigCacheSize),
 		txMassCalculator:                        txMassCalculator,
+		scriptVerificationWorkers:               runtime.NumCPU(),
+		covenantsActivationDAAScore:             covenantsActivationDAAScore,
 	}
 }
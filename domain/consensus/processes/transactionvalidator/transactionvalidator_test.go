@@ -518,3 +518,59 @@ func TestSigningTwoInputsECDSA(t *testing.T) {
 		}
 	})
 }
+
+func TestCovenantsActivation(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		const activationDAAScore = 100
+		consensusConfig.CovenantsActivationDAAScore = activationDAAScore
+
+		factory := consensus.NewFactory()
+		tc, tearDown, err := factory.NewTestConsensus(consensusConfig, "TestCovenantsActivation")
+		if err != nil {
+			t.Fatalf("Failed create a NewTestConsensus: %s", err)
+		}
+		defer tearDown(false)
+
+		stagingArea := model.NewStagingArea()
+		beforeActivationBlockHash := externalapi.NewDomainHashFromByteArray(&[32]byte{0x01})
+		tc.DAABlocksStore().StageDAAScore(stagingArea, beforeActivationBlockHash, activationDAAScore-1)
+		activationBlockHash := externalapi.NewDomainHashFromByteArray(&[32]byte{0x02})
+		tc.DAABlocksStore().StageDAAScore(stagingArea, activationBlockHash, activationDAAScore)
+
+		tests := []struct {
+			name         string
+			txVersion    uint16
+			povBlockHash *externalapi.DomainHash
+			expectedErr  error
+		}{
+			{
+				name:         "covenants transaction before activation",
+				txVersion:    constants.CovenantsTransactionVersion,
+				povBlockHash: beforeActivationBlockHash,
+				expectedErr:  ruleerrors.ErrTransactionVersionIsUnknown,
+			},
+			{
+				name:         "covenants transaction after activation",
+				txVersion:    constants.CovenantsTransactionVersion,
+				povBlockHash: activationBlockHash,
+			},
+			{
+				name:         "regular transaction before activation",
+				txVersion:    constants.MaxTransactionVersion,
+				povBlockHash: beforeActivationBlockHash,
+			},
+		}
+
+		for _, test := range tests {
+			tx := &externalapi.DomainTransaction{
+				Version:      test.txVersion,
+				Inputs:       []*externalapi.DomainTransactionInput{{Sequence: constants.MaxTxInSequenceNum}},
+				SubnetworkID: subnetworks.SubnetworkIDNative,
+			}
+			err := tc.TransactionValidator().ValidateTransactionInContextIgnoringUTXO(stagingArea, tx, test.povBlockHash, 0)
+			if !errors.Is(err, test.expectedErr) {
+				t.Errorf("%s: expected error %v but got %+v", test.name, test.expectedErr, err)
+			}
+		}
+	})
+}
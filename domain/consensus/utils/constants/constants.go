@@ -9,6 +9,11 @@ const (
 	// MaxTransactionVersion is the current latest supported transaction version.
 	MaxTransactionVersion uint16 = 0
 
+	// CovenantsTransactionVersion is the transaction version whose scripts may use the
+	// transaction introspection opcodes, OP_CAT and OP_SUBSTR. It's valid only from the
+	// covenants activation DAA score of the network.
+	CovenantsTransactionVersion uint16 = 1
+
 	// MaxScriptPublicKeyVersion is the current latest supported public key script version.
 	MaxScriptPublicKeyVersion uint16 = 0
 
@@ -0,0 +1,116 @@
+package pow
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+)
+
+// randomXHashCacheSize is the maximum number of RandomX hashes kept by
+// globalRandomXHashCache. It's large enough to hold the hashes of the headers
+// that are queued for insertion during IBD.
+const randomXHashCacheSize = 1 << 16
+
+// globalRandomXHashCache keeps the RandomX hashes calculated by
+// CheckProofOfWorkByBitsInParallel, so that validating the same headers
+// later on doesn't calculate them again.
+var globalRandomXHashCache = newRandomXHashCache(randomXHashCacheSize)
+
+// randomXHashCache maps the PoW hash of a header (see State.calculatePoWHash)
+// to its RandomX hash. It's safe for concurrent access.
+type randomXHashCache struct {
+	sync.RWMutex
+	hashes     map[externalapi.DomainHash]*externalapi.DomainHash
+	maxEntries int
+}
+
+func newRandomXHashCache(maxEntries int) *randomXHashCache {
+	return &randomXHashCache{
+		hashes:     make(map[externalapi.DomainHash]*externalapi.DomainHash),
+		maxEntries: maxEntries,
+	}
+}
+
+func (c *randomXHashCache) get(powHash *externalapi.DomainHash) (*externalapi.DomainHash, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	randomxHash, ok := c.hashes[*powHash]
+	return randomxHash, ok
+}
+
+func (c *randomXHashCache) add(powHash *externalapi.DomainHash, randomxHash *externalapi.DomainHash) {
+	c.Lock()
+	defer c.Unlock()
+
+	// Evict a random entry if the cache is full. Headers are usually validated
+	// soon after their hash was calculated, so the exact eviction policy
+	// doesn't matter much.
+	if len(c.hashes) >= c.maxEntries {
+		for powHash := range c.hashes {
+			delete(c.hashes, powHash)
+			break
+		}
+	}
+	c.hashes[*powHash] = randomxHash
+}
+
+// CheckProofOfWorkByBitsInParallel checks the proof of work of the given headers like
+// CheckProofOfWorkByBits does, while calculating up to as many RandomX hashes at once as
+// there are VMs in the global pool. The calculated hashes are cached, so that validating
+// the headers afterwards doesn't calculate them again.
+//
+// It returns whether all the headers have a valid proof of work, and if not, the index of
+// the first header that doesn't. The proof of work of headers without parents (i.e. the
+// genesis) isn't checked.
+func CheckProofOfWorkByBitsInParallel(headers []externalapi.BlockHeader) (isValid bool, firstInvalidIndex int) {
+	numWorkers := globalRxVMPool.Size()
+	if numWorkers > len(headers) {
+		numWorkers = len(headers)
+	}
+
+	// isHeaderValid is written by the workers, each header index by a single worker
+	isHeaderValid := make([]bool, len(headers))
+	nextHeaderIndex := int64(-1)
+	waitGroup := sync.WaitGroup{}
+	waitGroup.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			defer waitGroup.Done()
+			for {
+				headerIndex := int(atomic.AddInt64(&nextHeaderIndex, 1))
+				if headerIndex >= len(headers) {
+					return
+				}
+				header := headers[headerIndex]
+				if len(header.DirectParents()) == 0 {
+					isHeaderValid[headerIndex] = true
+					continue
+				}
+
+				state := NewState(header.ToMutable())
+				powHash := state.calculatePoWHash()
+				randomxHash, ok := globalRandomXHashCache.get(powHash)
+				if !ok {
+					var err error
+					randomxHash, err = externalapi.NewDomainHashFromByteSlice(CalcGlobalVMHash(powHash.ByteSlice()))
+					if err != nil {
+						panic(errors.Wrap(err, "this should never happen. Hash digest should never return an error"))
+					}
+					globalRandomXHashCache.add(powHash, randomxHash)
+				}
+				isHeaderValid[headerIndex] = toBig(randomxHash).Cmp(&state.Target) <= 0
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	for i, isValid := range isHeaderValid {
+		if !isValid {
+			return false, i
+		}
+	}
+	return true, -1
+}
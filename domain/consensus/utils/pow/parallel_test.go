@@ -0,0 +1,63 @@
+package pow_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/blockheader"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/pow"
+)
+
+func TestCheckProofOfWorkByBitsInParallel(t *testing.T) {
+	const (
+		// easyBits is a target that any hash meets
+		easyBits = 0x2200ffff
+		// impossibleBits is a target of 1, that no hash meets in practice
+		impossibleBits = 0x03000001
+	)
+
+	parent := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1})
+	newHeader := func(nonce uint64, bits uint32, hasParents bool) externalapi.BlockHeader {
+		var parents []externalapi.BlockLevelParents
+		if hasParents {
+			parents = []externalapi.BlockLevelParents{{parent}}
+		}
+		return blockheader.NewImmutableBlockHeader(0, parents, &externalapi.DomainHash{}, &externalapi.DomainHash{},
+			&externalapi.DomainHash{}, 1000, bits, nonce, 0, 0, big.NewInt(0), &externalapi.DomainHash{})
+	}
+
+	headers := make([]externalapi.BlockHeader, 10)
+	for i := range headers {
+		headers[i] = newHeader(uint64(i), easyBits, true)
+	}
+	isValid, firstInvalidIndex := pow.CheckProofOfWorkByBitsInParallel(headers)
+	if !isValid || firstInvalidIndex != -1 {
+		t.Fatalf("Expected all the headers to be valid, but header #%d is invalid", firstInvalidIndex)
+	}
+
+	// The cached values must be the ones that are calculated without the cache
+	for _, header := range headers {
+		cachedValue := pow.NewState(header.ToMutable()).CalculateProofOfWorkValue()
+		uncachedValue := pow.NewState(newHeader(header.Nonce(), easyBits, true).ToMutable()).CalculateProofOfWorkValue()
+		if cachedValue.Cmp(uncachedValue) != 0 {
+			t.Fatalf("The cached proof of work value %x differs from the calculated value %x", cachedValue, uncachedValue)
+		}
+	}
+
+	headers[3] = newHeader(100, impossibleBits, true)
+	headers[7] = newHeader(101, impossibleBits, true)
+	isValid, firstInvalidIndex = pow.CheckProofOfWorkByBitsInParallel(headers)
+	if isValid || firstInvalidIndex != 3 {
+		t.Fatalf("Expected header #3 to be the first invalid header, but got %t and %d", isValid, firstInvalidIndex)
+	}
+	if pow.CheckProofOfWorkByBits(headers[3].ToMutable()) {
+		t.Fatalf("CheckProofOfWorkByBits disagrees with CheckProofOfWorkByBitsInParallel")
+	}
+
+	// Headers without parents aren't checked
+	isValid, _ = pow.CheckProofOfWorkByBitsInParallel([]externalapi.BlockHeader{newHeader(102, impossibleBits, false)})
+	if !isValid {
+		t.Fatalf("Expected a header without parents to be skipped")
+	}
+}
@@ -40,6 +40,23 @@ func NewState(header externalapi.MutableBlockHeader) *State {
 
 // CalculateProofOfWorkValue hashes the internal header and returns its big.Int value
 func (state *State) CalculateProofOfWorkValue() *big.Int {
+	powHash := state.calculatePoWHash()
+
+	// Use RandomX to calculate the hash, unless it was already calculated by CheckProofOfWorkByBitsInParallel
+	randomxHash, ok := globalRandomXHashCache.get(powHash)
+	if !ok {
+		var err error
+		randomxHash, err = externalapi.NewDomainHashFromByteSlice(CalcGlobalVMHash(powHash.ByteSlice()))
+		if err != nil {
+			panic(errors.Wrap(err, "this should never happen. Hash digest should never return an error"))
+		}
+	}
+
+	return toBig(randomxHash)
+}
+
+// calculatePoWHash returns the hash of the header fields that RandomX hashes into the proof of work value
+func (state *State) calculatePoWHash() *externalapi.DomainHash {
 	// PRE_POW_HASH || TIME || 32 zero byte padding || NONCE
 	writer := hashes.NewPoWHashWriter()
 	writer.InfallibleWrite(state.prePowHash.ByteSlice())
@@ -53,17 +70,7 @@ func (state *State) CalculateProofOfWorkValue() *big.Int {
 	if err != nil {
 		panic(errors.Wrap(err, "this should never happen. Hash digest should never return an error"))
 	}
-	powHash := writer.Finalize()
-
-	// Use RandomX to calculate the hash
-	randomxHash := CalcGlobalVMHash(powHash.ByteSlice())
-
-	domainHash, err := externalapi.NewDomainHashFromByteSlice(randomxHash)
-	if err != nil {
-		panic(errors.Wrap(err, "this should never happen. Hash digest should never return an error"))
-	}
-
-	return toBig(domainHash)
+	return writer.Finalize()
 }
 
 // IncrementNonce the nonce in State by 1
@@ -1,15 +1,19 @@
 package pow
 
 import (
+	"github.com/Kash-Protocol/kashd/infrastructure/metrics"
 	"github.com/Kash-Protocol/kashd/util/randomx"
 	"github.com/pkg/errors"
 	"sync"
 )
 
 // RxVMPool represents a pool of RandomX VMs.
+// All the VMs of the pool share a single RandomX cache and dataset, which take
+// most of the memory of a VM, so adding VMs to the pool only costs their scratchpads.
 type RxVMPool struct {
-	vmChan chan *randomx.RxVM
-	size   int
+	vmChan    chan *randomx.RxVM
+	size      int
+	rxDataset *randomx.RxDataset
 }
 
 var (
@@ -30,31 +34,31 @@ func init() {
 
 // CalcGlobalVMHash calculates the hash using one of the RandomX VMs from the global pool.
 func CalcGlobalVMHash(data []byte) []byte {
+	metrics.IncRandomXHashes()
 	return globalRxVMPool.CalcHash(data)
 }
 
 // NewRxVMPool initializes a new pool of RandomX VMs with the given size.
 func NewRxVMPool(poolSize int) (*RxVMPool, error) {
-	vmChan := make(chan *randomx.RxVM, poolSize)
+	rxDataset, err := randomx.NewRxDataset(randomx.FlagDefault)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create RandomX dataset")
+	}
 
+	vmChan := make(chan *randomx.RxVM, poolSize)
 	for i := 0; i < poolSize; i++ {
-		vm, err := createRxVM()
+		vm, err := createRxVM(rxDataset)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create RandomX VM")
 		}
 		vmChan <- vm
 	}
 
-	return &RxVMPool{vmChan: vmChan, size: poolSize}, nil
+	return &RxVMPool{vmChan: vmChan, size: poolSize, rxDataset: rxDataset}, nil
 }
 
-// createRxVM creates a new RandomX VM instance.
-func createRxVM() (*randomx.RxVM, error) {
-	rxDataset, err := randomx.NewRxDataset(randomx.FlagDefault)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create RandomX dataset")
-	}
-
+// createRxVM creates a new RandomX VM instance that uses the given dataset.
+func createRxVM(rxDataset *randomx.RxDataset) (*randomx.RxVM, error) {
 	vm, err := randomx.NewRxVM(rxDataset, randomx.FlagDefault)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create RandomX VM")
@@ -71,6 +75,12 @@ func (p *RxVMPool) CalcHash(data []byte) []byte {
 	return hash
 }
 
+// Size returns the number of RandomX VMs in the pool, which is the maximum
+// number of hashes it can calculate concurrently.
+func (p *RxVMPool) Size() int {
+	return p.size
+}
+
 // ResizePool adjusts the size of the RxVMPool to the specified size.
 // This function increases the pool size by creating new RandomX VM instances
 // and transferring existing ones to a new channel with the desired size.
@@ -112,7 +122,7 @@ func (p *RxVMPool) ResizePool(newSize int) error {
 
 	// Add new VMs to the pool
 	for i := 0; i < diff; i++ {
-		vm, err := createRxVM()
+		vm, err := createRxVM(p.rxDataset)
 		if err != nil {
 			return errors.Wrap(err, "failed to create RandomX VM during pool resize")
 		}
@@ -131,7 +141,12 @@ func ResizeGlobalPool(newSize int) error {
 	return globalRxVMPool.ResizePool(newSize)
 }
 
-// Cleanup safely destroys all RandomX VMs in the pool.
+// GlobalPoolSize returns the number of RandomX VMs in the global RxVMPool.
+func GlobalPoolSize() int {
+	return globalRxVMPool.Size()
+}
+
+// Cleanup safely destroys all RandomX VMs in the pool, and then the dataset they share.
 func (p *RxVMPool) Cleanup() {
 	close(p.vmChan) // Close the channel before cleanup
 	for vm := range p.vmChan {
@@ -139,4 +154,5 @@ func (p *RxVMPool) Cleanup() {
 			vm.Close()
 		}
 	}
+	p.rxDataset.Close()
 }
@@ -67,12 +67,19 @@ func (vm *Engine) isBranchExecuting() bool {
 	return vm.condStack[len(vm.condStack)-1] == OpCondTrue
 }
 
+// isCovenantsTransaction returns whether the scripts are executed for a
+// transaction of constants.CovenantsTransactionVersion or above, in which the
+// transaction introspection opcodes, OP_CAT and OP_SUBSTR are enabled.
+func (vm *Engine) isCovenantsTransaction() bool {
+	return vm.tx.Version >= constants.CovenantsTransactionVersion
+}
+
 // executeOpcode peforms execution on the passed opcode. It takes into account
 // whether or not it is hidden by conditionals, but some rules still must be
 // tested in this case.
 func (vm *Engine) executeOpcode(pop *parsedOpcode) error {
 	// Disabled opcodes are fail on program counter.
-	if pop.isDisabled() {
+	if pop.isDisabled(vm.isCovenantsTransaction()) {
 		str := fmt.Sprintf("attempt to execute disabled opcode %s",
 			pop.opcode.name)
 		return scriptError(ErrDisabledOpcode, str)
@@ -165,6 +172,36 @@ func (vm *Engine) DisasmPC() (string, error) {
 	return vm.disasm(scriptIdx, scriptOff), nil
 }
 
+// ProgramCounter returns the index of the script and the offset of the opcode
+// within it that will be next to execute when Step() is called. Index 0 is the
+// signature script, 1 is the public key script and 2 is the redeem script of a
+// pay-to-script-hash input.
+func (vm *Engine) ProgramCounter() (scriptIdx int, scriptOff int, err error) {
+	return vm.curPC()
+}
+
+// IsBranchExecuting returns whether or not the current conditional branch is
+// actively executing.
+func (vm *Engine) IsBranchExecuting() bool {
+	return vm.isBranchExecuting()
+}
+
+// GetConditionalStack returns the state of the conditionals that are being
+// executed, from the outermost to the innermost. Each element is one of
+// OpCondFalse, OpCondTrue and OpCondSkip.
+func (vm *Engine) GetConditionalStack() []int {
+	conditionalStack := make([]int, len(vm.condStack))
+	copy(conditionalStack, vm.condStack)
+	return conditionalStack
+}
+
+// IsUnknownScriptVersion returns whether the version of the public key script
+// is higher than the known versions, in which case Execute succeeds without
+// executing any opcode.
+func (vm *Engine) IsUnknownScriptVersion() bool {
+	return vm.scriptVersion > constants.MaxScriptPublicKeyVersion
+}
+
 // DisasmScript returns the disassembly string for the script at the requested
 // offset index. Index 0 is the signature script and 1 is the public key
 // script.
@@ -499,6 +536,10 @@ func NewEngine(scriptPubKey *externalapi.ScriptPublicKey, tx *externalapi.Domain
 
 	vm.tx = *tx
 	vm.txIdx = txIdx
+	if vm.isCovenantsTransaction() {
+		vm.dstack.allowLargeNumbers = true
+		vm.astack.allowLargeNumbers = true
+	}
 
 	vm.sigHashReusedValues = sighashReusedValues
 
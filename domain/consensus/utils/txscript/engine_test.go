@@ -5,6 +5,7 @@
 package txscript
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
@@ -342,3 +343,71 @@ func TestDisasmScript(t *testing.T) {
 		}
 	}
 }
+
+// TestConditionalStack ensures the program counter and the conditional state
+// are exposed as expected while stepping through a script.
+func TestConditionalStack(t *testing.T) {
+	tx := &externalapi.DomainTransaction{
+		Version: 1,
+		Inputs: []*externalapi.DomainTransactionInput{{
+			PreviousOutpoint: externalapi.DomainOutpoint{Index: 0},
+			Sequence:         4294967295,
+		}},
+		Outputs: []*externalapi.DomainTransactionOutput{{Value: 1}},
+	}
+	scriptPubKey := &externalapi.ScriptPublicKey{
+		Script:  mustParseShortForm("OP_1 OP_IF OP_0 OP_IF OP_2 OP_ENDIF OP_1 OP_ENDIF", 0),
+		Version: 0,
+	}
+	vm, err := NewEngine(scriptPubKey, tx, 0, 0, nil, nil, &consensushashing.SighashReusedValues{})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	if vm.IsUnknownScriptVersion() {
+		t.Fatalf("Unexpected unknown script version")
+	}
+
+	tests := []struct {
+		expectedOffset            int
+		expectedConditionalStack  []int
+		expectedBranchIsExecuting bool
+	}{
+		{expectedOffset: 0, expectedConditionalStack: []int{}, expectedBranchIsExecuting: true},
+		{expectedOffset: 1, expectedConditionalStack: []int{}, expectedBranchIsExecuting: true},
+		{expectedOffset: 2, expectedConditionalStack: []int{OpCondTrue}, expectedBranchIsExecuting: true},
+		{expectedOffset: 3, expectedConditionalStack: []int{OpCondTrue}, expectedBranchIsExecuting: true},
+		{expectedOffset: 4, expectedConditionalStack: []int{OpCondTrue, OpCondFalse}, expectedBranchIsExecuting: false},
+		{expectedOffset: 5, expectedConditionalStack: []int{OpCondTrue, OpCondFalse}, expectedBranchIsExecuting: false},
+		{expectedOffset: 6, expectedConditionalStack: []int{OpCondTrue}, expectedBranchIsExecuting: true},
+		{expectedOffset: 7, expectedConditionalStack: []int{OpCondTrue}, expectedBranchIsExecuting: true},
+	}
+	for i, test := range tests {
+		scriptIdx, scriptOff, err := vm.ProgramCounter()
+		if err != nil {
+			t.Fatalf("%d: ProgramCounter: %v", i, err)
+		}
+		if scriptIdx != 1 || scriptOff != test.expectedOffset {
+			t.Fatalf("%d: expected the program counter 1:%d but got %d:%d", i, test.expectedOffset, scriptIdx, scriptOff)
+		}
+		if !reflect.DeepEqual(vm.GetConditionalStack(), test.expectedConditionalStack) {
+			t.Fatalf("%d: expected the conditional stack %v but got %v", i,
+				test.expectedConditionalStack, vm.GetConditionalStack())
+		}
+		if vm.IsBranchExecuting() != test.expectedBranchIsExecuting {
+			t.Fatalf("%d: expected IsBranchExecuting to be %t", i, test.expectedBranchIsExecuting)
+		}
+		_, err = vm.Step()
+		if err != nil {
+			t.Fatalf("%d: Step: %v", i, err)
+		}
+	}
+
+	_, _, err = vm.ProgramCounter()
+	if !IsErrorCode(err, ErrInvalidProgramCounter) {
+		t.Fatalf("Expected ErrInvalidProgramCounter after the script ended but got %v", err)
+	}
+	err = vm.CheckErrorCondition(true)
+	if err != nil {
+		t.Fatalf("CheckErrorCondition: %v", err)
+	}
+}
@@ -10,7 +10,9 @@ import (
 	"encoding/binary"
 	"fmt"
 	"hash"
+	"math"
 
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
 	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
 
 	"golang.org/x/crypto/blake2b"
@@ -214,23 +216,23 @@ const (
 	OpCheckLockTimeVerify = 0xb0 // 176
 	OpCheckSequenceVerify = 0xb1 // 177
 	OpUnknown178          = 0xb2 // 178
-	OpUnknown179          = 0xb3 // 179
-	OpUnknown180          = 0xb4 // 180
+	OpTxInputCount        = 0xb3 // 179
+	OpTxOutputCount       = 0xb4 // 180
 	OpUnknown181          = 0xb5 // 181
 	OpUnknown182          = 0xb6 // 182
 	OpUnknown183          = 0xb7 // 183
 	OpUnknown184          = 0xb8 // 184
-	OpUnknown185          = 0xb9 // 185
+	OpTxInputIndex        = 0xb9 // 185
 	OpUnknown186          = 0xba // 186
 	OpUnknown187          = 0xbb // 187
 	OpUnknown188          = 0xbc // 188
 	OpUnknown189          = 0xbd // 189
-	OpUnknown190          = 0xbe // 190
-	OpUnknown191          = 0xbf // 191
+	OpTxInputAmount       = 0xbe // 190
+	OpTxInputSPK          = 0xbf // 191
 	OpUnknown192          = 0xc0 // 192
 	OpUnknown193          = 0xc1 // 193
-	OpUnknown194          = 0xc2 // 194
-	OpUnknown195          = 0xc3 // 195
+	OpTxOutputAmount      = 0xc2 // 194
+	OpTxOutputSPK         = 0xc3 // 195
 	OpUnknown196          = 0xc4 // 196
 	OpUnknown197          = 0xc5 // 197
 	OpUnknown198          = 0xc6 // 198
@@ -439,8 +441,8 @@ var opcodeArray = [256]opcode{
 	OpTuck:         {OpTuck, "OP_TUCK", 1, opcodeTuck},
 
 	// Splice opcodes.
-	OpCat:    {OpCat, "OP_CAT", 1, opcodeDisabled},
-	OpSubStr: {OpSubStr, "OP_SUBSTR", 1, opcodeDisabled},
+	OpCat:    {OpCat, "OP_CAT", 1, opcodeCat},
+	OpSubStr: {OpSubStr, "OP_SUBSTR", 1, opcodeSubStr},
 	OpLeft:   {OpLeft, "OP_LEFT", 1, opcodeDisabled},
 	OpRight:  {OpRight, "OP_RIGHT", 1, opcodeDisabled},
 	OpSize:   {OpSize, "OP_SIZE", 1, opcodeSize},
@@ -494,27 +496,29 @@ var opcodeArray = [256]opcode{
 	OpCheckMultiSig:       {OpCheckMultiSig, "OP_CHECKMULTISIG", 1, opcodeCheckMultiSig},
 	OpCheckMultiSigVerify: {OpCheckMultiSigVerify, "OP_CHECKMULTISIGVERIFY", 1, opcodeCheckMultiSigVerify},
 
+	// Transaction introspection opcodes, which are valid only in covenants transactions.
+	OpTxInputCount:   {OpTxInputCount, "OP_TXINPUTCOUNT", 1, opcodeTxInputCount},
+	OpTxOutputCount:  {OpTxOutputCount, "OP_TXOUTPUTCOUNT", 1, opcodeTxOutputCount},
+	OpTxInputIndex:   {OpTxInputIndex, "OP_TXINPUTINDEX", 1, opcodeTxInputIndex},
+	OpTxInputAmount:  {OpTxInputAmount, "OP_TXINPUTAMOUNT", 1, opcodeTxInputAmount},
+	OpTxInputSPK:     {OpTxInputSPK, "OP_TXINPUTSPK", 1, opcodeTxInputSPK},
+	OpTxOutputAmount: {OpTxOutputAmount, "OP_TXOUTPUTAMOUNT", 1, opcodeTxOutputAmount},
+	OpTxOutputSPK:    {OpTxOutputSPK, "OP_TXOUTPUTSPK", 1, opcodeTxOutputSPK},
+
 	// Undefined opcodes.
 	OpUnknown166: {OpUnknown166, "OP_UNKNOWN166", 1, opcodeInvalid},
 	OpUnknown167: {OpUnknown167, "OP_UNKNOWN167", 1, opcodeInvalid},
-	OpUnknown178: {OpUnknown188, "OP_UNKNOWN178", 1, opcodeInvalid},
-	OpUnknown179: {OpUnknown189, "OP_UNKNOWN179", 1, opcodeInvalid},
-	OpUnknown180: {OpUnknown190, "OP_UNKNOWN180", 1, opcodeInvalid},
-	OpUnknown181: {OpUnknown191, "OP_UNKNOWN181", 1, opcodeInvalid},
-	OpUnknown182: {OpUnknown192, "OP_UNKNOWN182", 1, opcodeInvalid},
-	OpUnknown183: {OpUnknown193, "OP_UNKNOWN183", 1, opcodeInvalid},
-	OpUnknown184: {OpUnknown194, "OP_UNKNOWN184", 1, opcodeInvalid},
-	OpUnknown185: {OpUnknown195, "OP_UNKNOWN185", 1, opcodeInvalid},
-	OpUnknown186: {OpUnknown196, "OP_UNKNOWN186", 1, opcodeInvalid},
-	OpUnknown187: {OpUnknown197, "OP_UNKNOWN187", 1, opcodeInvalid},
+	OpUnknown178: {OpUnknown178, "OP_UNKNOWN178", 1, opcodeInvalid},
+	OpUnknown181: {OpUnknown181, "OP_UNKNOWN181", 1, opcodeInvalid},
+	OpUnknown182: {OpUnknown182, "OP_UNKNOWN182", 1, opcodeInvalid},
+	OpUnknown183: {OpUnknown183, "OP_UNKNOWN183", 1, opcodeInvalid},
+	OpUnknown184: {OpUnknown184, "OP_UNKNOWN184", 1, opcodeInvalid},
+	OpUnknown186: {OpUnknown186, "OP_UNKNOWN186", 1, opcodeInvalid},
+	OpUnknown187: {OpUnknown187, "OP_UNKNOWN187", 1, opcodeInvalid},
 	OpUnknown188: {OpUnknown188, "OP_UNKNOWN188", 1, opcodeInvalid},
 	OpUnknown189: {OpUnknown189, "OP_UNKNOWN189", 1, opcodeInvalid},
-	OpUnknown190: {OpUnknown190, "OP_UNKNOWN190", 1, opcodeInvalid},
-	OpUnknown191: {OpUnknown191, "OP_UNKNOWN191", 1, opcodeInvalid},
 	OpUnknown192: {OpUnknown192, "OP_UNKNOWN192", 1, opcodeInvalid},
 	OpUnknown193: {OpUnknown193, "OP_UNKNOWN193", 1, opcodeInvalid},
-	OpUnknown194: {OpUnknown194, "OP_UNKNOWN194", 1, opcodeInvalid},
-	OpUnknown195: {OpUnknown195, "OP_UNKNOWN195", 1, opcodeInvalid},
 	OpUnknown196: {OpUnknown196, "OP_UNKNOWN196", 1, opcodeInvalid},
 	OpUnknown197: {OpUnknown197, "OP_UNKNOWN197", 1, opcodeInvalid},
 	OpUnknown198: {OpUnknown198, "OP_UNKNOWN198", 1, opcodeInvalid},
@@ -613,12 +617,13 @@ type parsedOpcode struct {
 
 // isDisabled returns whether or not the opcode is disabled and thus is always
 // bad to see in the instruction stream (even if turned off by a conditional).
-func (pop *parsedOpcode) isDisabled() bool {
+// OP_CAT and OP_SUBSTR are enabled in covenants transactions.
+func (pop *parsedOpcode) isDisabled(isCovenantsTransaction bool) bool {
 	switch pop.opcode.value {
 	case OpCat:
-		return true
+		return !isCovenantsTransaction
 	case OpSubStr:
-		return true
+		return !isCovenantsTransaction
 	case OpLeft:
 		return true
 	case OpRight:
@@ -1385,6 +1390,68 @@ func opcodeTuck(op *parsedOpcode, vm *Engine) error {
 	return vm.dstack.Tuck()
 }
 
+// opcodeCat removes the top two items of the data stack and pushes their
+// concatenation. The concatenation must not exceed MaxScriptElementSize.
+//
+// Stack transformation: [... x1 x2] -> [... x1||x2]
+func opcodeCat(op *parsedOpcode, vm *Engine) error {
+	x2, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+	x1, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+
+	if len(x1)+len(x2) > MaxScriptElementSize {
+		str := fmt.Sprintf("concatenated element size %d exceeds max "+
+			"allowed size %d", len(x1)+len(x2), MaxScriptElementSize)
+		return scriptError(ErrElementTooBig, str)
+	}
+
+	// Copy both items, since stack items might share their underlying array
+	// with the script or with other stack items
+	concatenated := make([]byte, 0, len(x1)+len(x2))
+	concatenated = append(concatenated, x1...)
+	concatenated = append(concatenated, x2...)
+	vm.dstack.PushByteArray(concatenated)
+	return nil
+}
+
+// opcodeSubStr removes the top three items of the data stack, treats the top
+// two as a begin offset and a size, and pushes the part of the third item
+// that starts at the begin offset and has the given size.
+//
+// Stack transformation: [... x1 begin size] -> [... x1[begin:begin+size]]
+func opcodeSubStr(op *parsedOpcode, vm *Engine) error {
+	size, err := vm.dstack.PopInt()
+	if err != nil {
+		return err
+	}
+	begin, err := vm.dstack.PopInt()
+	if err != nil {
+		return err
+	}
+	data, err := vm.dstack.PopByteArray()
+	if err != nil {
+		return err
+	}
+
+	// The size is compared to the remaining length rather than begin+size to the length,
+	// since the addition overflows for 8-byte script numbers
+	if begin < 0 || size < 0 || int64(begin) > int64(len(data)) || int64(size) > int64(len(data))-int64(begin) {
+		str := fmt.Sprintf("substring of %d bytes at offset %d is out of the bounds "+
+			"of an element of %d bytes", size, begin, len(data))
+		return scriptError(ErrInvalidIndex, str)
+	}
+
+	substring := make([]byte, size)
+	copy(substring, data[begin:begin+size])
+	vm.dstack.PushByteArray(substring)
+	return nil
+}
+
 // opcodeSize pushes the size of the top item of the data stack onto the data
 // stack.
 //
@@ -1442,8 +1509,7 @@ func opcode1Add(op *parsedOpcode, vm *Engine) error {
 		return err
 	}
 
-	vm.dstack.PushInt(m + 1)
-	return nil
+	return vm.pushCheckedSum(m, 1)
 }
 
 // opcode1Sub treats the top item on the data stack as an integer and replaces
@@ -1455,9 +1521,7 @@ func opcode1Sub(op *parsedOpcode, vm *Engine) error {
 	if err != nil {
 		return err
 	}
-	vm.dstack.PushInt(m - 1)
-
-	return nil
+	return vm.pushCheckedSum(m, -1)
 }
 
 // opcodeNegate treats the top item on the data stack as an integer and replaces
@@ -1551,8 +1615,7 @@ func opcodeAdd(op *parsedOpcode, vm *Engine) error {
 		return err
 	}
 
-	vm.dstack.PushInt(v0 + v1)
-	return nil
+	return vm.pushCheckedSum(v0, v1)
 }
 
 // opcodeSub treats the top two items on the data stack as integers and replaces
@@ -1571,7 +1634,20 @@ func opcodeSub(op *parsedOpcode, vm *Engine) error {
 		return err
 	}
 
-	vm.dstack.PushInt(v1 - v0)
+	return vm.pushCheckedSum(v1, -v0)
+}
+
+// pushCheckedSum pushes the sum of the given numbers onto the data stack. The
+// sum of numbers of up to 4 bytes can't overflow, but the sum of the 8 byte
+// numbers that covenants transactions allow can, in which case an error is
+// returned.
+func (vm *Engine) pushCheckedSum(a, b scriptNum) error {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) || sum == math.MinInt64 {
+		str := fmt.Sprintf("the sum of %d and %d overflows", a, b)
+		return scriptError(ErrNumberTooBig, str)
+	}
+	vm.dstack.PushInt(sum)
 	return nil
 }
 
@@ -2479,6 +2555,150 @@ func opcodeCheckMultiSigVerify(op *parsedOpcode, vm *Engine) error {
 	return err
 }
 
+// opcodeTxInputCount pushes the number of inputs of the transaction. It's
+// valid only in covenants transactions.
+//
+// Stack transformation: [...] -> [... count]
+func opcodeTxInputCount(op *parsedOpcode, vm *Engine) error {
+	if !vm.isCovenantsTransaction() {
+		return opcodeInvalid(op, vm)
+	}
+	vm.dstack.PushInt(scriptNum(len(vm.tx.Inputs)))
+	return nil
+}
+
+// opcodeTxOutputCount pushes the number of outputs of the transaction. It's
+// valid only in covenants transactions.
+//
+// Stack transformation: [...] -> [... count]
+func opcodeTxOutputCount(op *parsedOpcode, vm *Engine) error {
+	if !vm.isCovenantsTransaction() {
+		return opcodeInvalid(op, vm)
+	}
+	vm.dstack.PushInt(scriptNum(len(vm.tx.Outputs)))
+	return nil
+}
+
+// opcodeTxInputIndex pushes the index of the input whose scripts are executed.
+// It's valid only in covenants transactions.
+//
+// Stack transformation: [...] -> [... index]
+func opcodeTxInputIndex(op *parsedOpcode, vm *Engine) error {
+	if !vm.isCovenantsTransaction() {
+		return opcodeInvalid(op, vm)
+	}
+	vm.dstack.PushInt(scriptNum(vm.txIdx))
+	return nil
+}
+
+// opcodeTxInputAmount replaces the input index on top of the data stack with
+// the amount of the UTXO spent by that input. It's valid only in covenants
+// transactions.
+//
+// Stack transformation: [... index] -> [... amount]
+func opcodeTxInputAmount(op *parsedOpcode, vm *Engine) error {
+	if !vm.isCovenantsTransaction() {
+		return opcodeInvalid(op, vm)
+	}
+	utxoEntry, err := vm.popInputUTXOEntry()
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushInt(scriptNum(utxoEntry.Amount()))
+	return nil
+}
+
+// opcodeTxInputSPK replaces the input index on top of the data stack with the
+// script public key of the UTXO spent by that input. It's valid only in
+// covenants transactions.
+//
+// Stack transformation: [... index] -> [... version||script]
+func opcodeTxInputSPK(op *parsedOpcode, vm *Engine) error {
+	if !vm.isCovenantsTransaction() {
+		return opcodeInvalid(op, vm)
+	}
+	utxoEntry, err := vm.popInputUTXOEntry()
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushByteArray(serializeScriptPublicKey(utxoEntry.ScriptPublicKey()))
+	return nil
+}
+
+// opcodeTxOutputAmount replaces the output index on top of the data stack with
+// the amount of that output. It's valid only in covenants transactions.
+//
+// Stack transformation: [... index] -> [... amount]
+func opcodeTxOutputAmount(op *parsedOpcode, vm *Engine) error {
+	if !vm.isCovenantsTransaction() {
+		return opcodeInvalid(op, vm)
+	}
+	output, err := vm.popOutput()
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushInt(scriptNum(output.Value))
+	return nil
+}
+
+// opcodeTxOutputSPK replaces the output index on top of the data stack with
+// the script public key of that output. It's valid only in covenants
+// transactions.
+//
+// Stack transformation: [... index] -> [... version||script]
+func opcodeTxOutputSPK(op *parsedOpcode, vm *Engine) error {
+	if !vm.isCovenantsTransaction() {
+		return opcodeInvalid(op, vm)
+	}
+	output, err := vm.popOutput()
+	if err != nil {
+		return err
+	}
+	vm.dstack.PushByteArray(serializeScriptPublicKey(output.ScriptPublicKey))
+	return nil
+}
+
+// popInputUTXOEntry pops an input index off the data stack and returns the
+// UTXO entry spent by that input.
+func (vm *Engine) popInputUTXOEntry() (externalapi.UTXOEntry, error) {
+	index, err := vm.dstack.PopInt()
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || int64(index) >= int64(len(vm.tx.Inputs)) {
+		str := fmt.Sprintf("input index %d is out of the range of %d inputs", index, len(vm.tx.Inputs))
+		return nil, scriptError(ErrInvalidIndex, str)
+	}
+	utxoEntry := vm.tx.Inputs[index].UTXOEntry
+	if utxoEntry == nil {
+		str := fmt.Sprintf("the UTXO entry of input %d is missing", index)
+		return nil, scriptError(ErrInternal, str)
+	}
+	return utxoEntry, nil
+}
+
+// popOutput pops an output index off the data stack and returns that output.
+func (vm *Engine) popOutput() (*externalapi.DomainTransactionOutput, error) {
+	index, err := vm.dstack.PopInt()
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || int64(index) >= int64(len(vm.tx.Outputs)) {
+		str := fmt.Sprintf("output index %d is out of the range of %d outputs", index, len(vm.tx.Outputs))
+		return nil, scriptError(ErrInvalidIndex, str)
+	}
+	return vm.tx.Outputs[index], nil
+}
+
+// serializeScriptPublicKey serializes a script public key as its version,
+// in big endian, followed by its script.
+func serializeScriptPublicKey(scriptPublicKey *externalapi.ScriptPublicKey) []byte {
+	serialized := make([]byte, 2+len(scriptPublicKey.Script))
+	binary.BigEndian.PutUint16(serialized, scriptPublicKey.Version)
+	copy(serialized[2:], scriptPublicKey.Script)
+	return serialized
+}
+
 // OpcodeByName is a map that can be used to lookup an opcode by its
 // human-readable name (OP_CHECKMULTISIG, OP_CHECKSIG, etc).
 var OpcodeByName = make(map[string]byte)
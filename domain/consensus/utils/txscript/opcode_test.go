@@ -10,6 +10,11 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
 )
 
 // TestOpcodeDisabled tests the opcodeDisabled function manually because all
@@ -76,6 +81,9 @@ func TestOpcodeDisasm(t *testing.T) {
 		0xab: "OP_CHECKSIGECDSA", 0xac: "OP_CHECKSIG", 0xad: "OP_CHECKSIGVERIFY",
 		0xae: "OP_CHECKMULTISIG", 0xaf: "OP_CHECKMULTISIGVERIFY",
 		0xb0: "OP_CHECKLOCKTIMEVERIFY", 0xb1: "OP_CHECKSEQUENCEVERIFY",
+		0xb3: "OP_TXINPUTCOUNT", 0xb4: "OP_TXOUTPUTCOUNT", 0xb9: "OP_TXINPUTINDEX",
+		0xbe: "OP_TXINPUTAMOUNT", 0xbf: "OP_TXINPUTSPK", 0xc2: "OP_TXOUTPUTAMOUNT",
+		0xc3: "OP_TXOUTPUTSPK",
 		0xfa: "OP_SMALLINTEGER", 0xfb: "OP_PUBKEYS",
 		0xfd: "OP_PUBKEYHASH", 0xfe: "OP_PUBKEY",
 		0xff: "OP_INVALIDOPCODE",
@@ -178,6 +186,190 @@ func TestOpcodeDisasm(t *testing.T) {
 }
 
 func isOpUnknown(opcodeVal int) bool {
+	switch opcodeVal {
+	case OpTxInputCount, OpTxOutputCount, OpTxInputIndex, OpTxInputAmount, OpTxInputSPK,
+		OpTxOutputAmount, OpTxOutputSPK:
+		return false
+	}
 	return opcodeVal >= 0xb2 && opcodeVal <= 0xf9 || opcodeVal == 0xfc ||
 		opcodeVal == 0xa6 || opcodeVal == 0xa7
 }
+
+// TestCovenantsOpcodes tests the transaction introspection opcodes, OP_CAT and
+// OP_SUBSTR in covenants transactions and outside of them.
+func TestCovenantsOpcodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		script      string
+		txVersion   uint16
+		expectedErr error
+	}{
+		{
+			name:      "input count",
+			script:    "TXINPUTCOUNT 2 EQUAL",
+			txVersion: constants.CovenantsTransactionVersion,
+		},
+		{
+			name:        "input count in a non covenants transaction",
+			script:      "TXINPUTCOUNT 2 EQUAL",
+			txVersion:   constants.MaxTransactionVersion,
+			expectedErr: scriptError(ErrReservedOpcode, ""),
+		},
+		{
+			name:      "output count",
+			script:    "TXOUTPUTCOUNT 2 EQUAL",
+			txVersion: constants.CovenantsTransactionVersion,
+		},
+		{
+			name:      "input index",
+			script:    "TXINPUTINDEX 1 EQUAL",
+			txVersion: constants.CovenantsTransactionVersion,
+		},
+		{
+			name:      "current input amount",
+			script:    "TXINPUTINDEX TXINPUTAMOUNT 3000000000 EQUAL",
+			txVersion: constants.CovenantsTransactionVersion,
+		},
+		{
+			name:      "input script public key",
+			script:    "0 TXINPUTSPK 0x03 0x000051 EQUAL",
+			txVersion: constants.CovenantsTransactionVersion,
+		},
+		{
+			name: "arithmetic on amounts",
+			script: "0 TXOUTPUTAMOUNT 1 TXOUTPUTAMOUNT ADD " +
+				"0 TXINPUTAMOUNT 1 TXINPUTAMOUNT ADD LESSTHAN",
+			txVersion: constants.CovenantsTransactionVersion,
+		},
+		{
+			name:      "output script public key",
+			script:    "0 TXOUTPUTSPK 0x04 0x00005152 EQUAL",
+			txVersion: constants.CovenantsTransactionVersion,
+		},
+		{
+			name:        "output index out of range",
+			script:      "2 TXOUTPUTAMOUNT",
+			txVersion:   constants.CovenantsTransactionVersion,
+			expectedErr: scriptError(ErrInvalidIndex, ""),
+		},
+		{
+			name:        "negative input index",
+			script:      "-1 TXINPUTAMOUNT",
+			txVersion:   constants.CovenantsTransactionVersion,
+			expectedErr: scriptError(ErrInvalidIndex, ""),
+		},
+		{
+			name:      "cat",
+			script:    "'ab' 'cd' CAT 'abcd' EQUAL",
+			txVersion: constants.CovenantsTransactionVersion,
+		},
+		{
+			name:        "cat in a non covenants transaction",
+			script:      "'ab' 'cd' CAT 'abcd' EQUAL",
+			txVersion:   constants.MaxTransactionVersion,
+			expectedErr: scriptError(ErrDisabledOpcode, ""),
+		},
+		{
+			name:        "cat in an unexecuted branch of a non covenants transaction",
+			script:      "0 IF CAT ENDIF 1",
+			txVersion:   constants.MaxTransactionVersion,
+			expectedErr: scriptError(ErrDisabledOpcode, ""),
+		},
+		{
+			name:      "cat in an unexecuted branch of a covenants transaction",
+			script:    "0 IF CAT ENDIF 1",
+			txVersion: constants.CovenantsTransactionVersion,
+		},
+		{
+			name:        "cat exceeding the max element size",
+			script:      "'" + strings.Repeat("a", 300) + "' DUP CAT",
+			txVersion:   constants.CovenantsTransactionVersion,
+			expectedErr: scriptError(ErrElementTooBig, ""),
+		},
+		{
+			name:      "substr",
+			script:    "'abcdef' 1 3 SUBSTR 'bcd' EQUAL",
+			txVersion: constants.CovenantsTransactionVersion,
+		},
+		{
+			name:      "empty substr at the end",
+			script:    "'abc' 3 0 SUBSTR 0 EQUAL",
+			txVersion: constants.CovenantsTransactionVersion,
+		},
+		{
+			name:        "substr out of bounds",
+			script:      "'abc' 2 2 SUBSTR",
+			txVersion:   constants.CovenantsTransactionVersion,
+			expectedErr: scriptError(ErrInvalidIndex, ""),
+		},
+		{
+			name:        "substr with a size that overflows the end offset",
+			script:      "'abc' 1 9223372036854775807 SUBSTR",
+			txVersion:   constants.CovenantsTransactionVersion,
+			expectedErr: scriptError(ErrInvalidIndex, ""),
+		},
+		{
+			name:        "substr with a begin offset beyond the element",
+			script:      "'abc' 9223372036854775807 0 SUBSTR",
+			txVersion:   constants.CovenantsTransactionVersion,
+			expectedErr: scriptError(ErrInvalidIndex, ""),
+		},
+		{
+			name:        "substr with a negative size",
+			script:      "'abc' 1 -1 SUBSTR",
+			txVersion:   constants.CovenantsTransactionVersion,
+			expectedErr: scriptError(ErrInvalidIndex, ""),
+		},
+		{
+			name:        "5 byte numbers in a non covenants transaction",
+			script:      "3000000000 1ADD",
+			txVersion:   constants.MaxTransactionVersion,
+			expectedErr: scriptError(ErrNumberTooBig, ""),
+		},
+		{
+			name:        "addition overflow",
+			script:      "9223372036854775807 1ADD",
+			txVersion:   constants.CovenantsTransactionVersion,
+			expectedErr: scriptError(ErrNumberTooBig, ""),
+		},
+		{
+			name:        "subtraction overflow",
+			script:      "-9223372036854775807 1 SUB",
+			txVersion:   constants.CovenantsTransactionVersion,
+			expectedErr: scriptError(ErrNumberTooBig, ""),
+		},
+	}
+
+	for _, test := range tests {
+		scriptPublicKey := &externalapi.ScriptPublicKey{Script: mustParseShortForm(test.script, 0), Version: 0}
+		tx := &externalapi.DomainTransaction{
+			Version: test.txVersion,
+			Inputs: []*externalapi.DomainTransactionInput{
+				{
+					PreviousOutpoint: externalapi.DomainOutpoint{Index: 0},
+					UTXOEntry: utxo.NewUTXOEntry(7_000_000_000,
+						&externalapi.ScriptPublicKey{Script: []byte{Op1}, Version: 0}, false, 0),
+				},
+				{
+					PreviousOutpoint: externalapi.DomainOutpoint{Index: 1},
+					UTXOEntry:        utxo.NewUTXOEntry(3_000_000_000, scriptPublicKey, false, 0),
+				},
+			},
+			Outputs: []*externalapi.DomainTransactionOutput{
+				{Value: 6_000_000_000, ScriptPublicKey: &externalapi.ScriptPublicKey{Script: []byte{Op1, Op2}, Version: 0}},
+				{Value: 3_999_990_000, ScriptPublicKey: scriptPublicKey},
+			},
+		}
+
+		vm, err := NewEngine(scriptPublicKey, tx, 1, ScriptNoFlags, nil, nil, &consensushashing.SighashReusedValues{})
+		if err != nil {
+			t.Fatalf("%s: NewEngine: %s", test.name, err)
+		}
+		err = vm.Execute()
+		if err := checkScriptError(err, test.expectedErr); err != nil {
+			t.Errorf("%s: %s", test.name, err)
+		}
+	}
+}
@@ -226,7 +226,7 @@ func createSpendingTx(sigScript []byte, scriptPubKey *externalapi.ScriptPublicKe
 	}
 	output := &externalapi.DomainTransactionOutput{Value: 0, ScriptPublicKey: scriptPubKey}
 	coinbaseTx := &externalapi.DomainTransaction{
-		Version: 1,
+		Version: constants.MaxTransactionVersion,
 		Inputs:  []*externalapi.DomainTransactionInput{input},
 		Outputs: []*externalapi.DomainTransactionOutput{output},
 	}
@@ -242,7 +242,7 @@ func createSpendingTx(sigScript []byte, scriptPubKey *externalapi.ScriptPublicKe
 	}
 	output = &externalapi.DomainTransactionOutput{Value: 0, ScriptPublicKey: nil}
 	spendingTx := &externalapi.DomainTransaction{
-		Version: 1,
+		Version: constants.MaxTransactionVersion,
 		Inputs:  []*externalapi.DomainTransactionInput{input},
 		Outputs: []*externalapi.DomainTransactionOutput{output},
 	}
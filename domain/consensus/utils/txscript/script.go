@@ -275,6 +275,22 @@ func GetSigOpCount(script []byte) int {
 	return getSigOpCount(pops, false)
 }
 
+// GetSpliceOpCount returns the number of OP_CAT and OP_SUBSTR operations in a
+// script. If the script fails to parse, then the count up to the point of
+// failure is returned.
+func GetSpliceOpCount(script []byte) int {
+	// Don't check error since parseScript returns the parsed-up-to-error
+	// list of pops.
+	pops, _ := parseScript(script)
+	nSpliceOps := 0
+	for _, pop := range pops {
+		if pop.opcode.value == OpCat || pop.opcode.value == OpSubStr {
+			nSpliceOps++
+		}
+	}
+	return nSpliceOps
+}
+
 // GetPreciseSigOpCount returns the number of signature operations in
 // scriptPubKey. If p2sh is true then scriptSig may be searched for the
 // Pay-To-Script-Hash script in order to find the precise number of signature
@@ -15,6 +15,10 @@ const (
 	// defaultScriptNumLen is the default number of bytes
 	// data being interpreted as an integer may be.
 	defaultScriptNumLen = 4
+
+	// covenantsScriptNumLen is the number of bytes data being interpreted
+	// as an integer may be in covenants transactions, so that amounts fit in it.
+	covenantsScriptNumLen = 8
 )
 
 // scriptNum represents a numeric value used in the scripting engine with
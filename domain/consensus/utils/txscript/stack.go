@@ -37,6 +37,10 @@ func fromBool(v bool) []byte {
 // stack.
 type stack struct {
 	stk [][]byte
+
+	// allowLargeNumbers is whether data interpreted as an integer may be up to
+	// covenantsScriptNumLen bytes rather than defaultScriptNumLen bytes
+	allowLargeNumbers bool
 }
 
 // Depth returns the number of items on the stack.
@@ -85,7 +89,16 @@ func (s *stack) PopInt() (scriptNum, error) {
 		return 0, err
 	}
 
-	return makeScriptNum(so, defaultScriptNumLen)
+	return makeScriptNum(so, s.scriptNumLen())
+}
+
+// scriptNumLen returns the maximum number of bytes data interpreted as an
+// integer may be.
+func (s *stack) scriptNumLen() int {
+	if s.allowLargeNumbers {
+		return covenantsScriptNumLen
+	}
+	return defaultScriptNumLen
 }
 
 // PopBool pops the value off the top of the stack, converts it into a bool, and
@@ -122,7 +135,7 @@ func (s *stack) PeekInt(idx int32) (scriptNum, error) {
 		return 0, err
 	}
 
-	return makeScriptNum(so, defaultScriptNumLen)
+	return makeScriptNum(so, s.scriptNumLen())
 }
 
 // PeekBool returns the Nth item on the stack as a bool without removing it.
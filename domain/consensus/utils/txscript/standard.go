@@ -5,6 +5,7 @@
 package txscript
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
@@ -20,12 +21,21 @@ type ScriptClass byte
 
 // Classes of script payment known about in the blockDAG.
 const (
-	NonStandardTy ScriptClass = iota // None of the recognized forms.
-	PubKeyTy                         // Pay to pubkey.
-	PubKeyECDSATy                    // Pay to pubkey ECDSA.
-	ScriptHashTy                     // Pay to script hash.
+	NonStandardTy    ScriptClass = iota // None of the recognized forms.
+	PubKeyTy                            // Pay to pubkey.
+	PubKeyECDSATy                       // Pay to pubkey ECDSA.
+	ScriptHashTy                        // Pay to script hash.
+	MultiSigTy                          // Bare m-of-n Schnorr multisig.
+	MultiSigECDSATy                     // Bare m-of-n ECDSA multisig.
+	PubKeyLockTimeTy                    // Pay to pubkey after an absolute lock time (CLTV).
+	PubKeySequenceTy                    // Pay to pubkey after a relative lock time (CSV).
+	HTLCTy                              // Hash-time-locked contract.
 )
 
+// MaxPubKeysPerStandardMultiSig is the maximum number of public keys in a
+// standard multisig script, since the key count is pushed as a small integer.
+const MaxPubKeysPerStandardMultiSig = 16
+
 // Script public key versions for address types.
 const (
 	addressPublicKeyScriptPublicKeyVersion      = 0
@@ -36,17 +46,22 @@ const (
 // scriptClassToName houses the human-readable strings which describe each
 // script class.
 var scriptClassToName = []string{
-	NonStandardTy: "nonstandard",
-	PubKeyTy:      "pubkey",
-	PubKeyECDSATy: "pubkeyecdsa",
-	ScriptHashTy:  "scripthash",
+	NonStandardTy:    "nonstandard",
+	PubKeyTy:         "pubkey",
+	PubKeyECDSATy:    "pubkeyecdsa",
+	ScriptHashTy:     "scripthash",
+	MultiSigTy:       "multisig",
+	MultiSigECDSATy:  "multisigecdsa",
+	PubKeyLockTimeTy: "pubkeylocktime",
+	PubKeySequenceTy: "pubkeysequence",
+	HTLCTy:           "htlc",
 }
 
 // String implements the Stringer interface by returning the name of
 // the enum script class. If the enum is invalid then "Invalid" will be
 // returned.
 func (t ScriptClass) String() string {
-	if int(t) > len(scriptClassToName) || int(t) < 0 {
+	if int(t) >= len(scriptClassToName) || int(t) < 0 {
 		return "Invalid"
 	}
 	return scriptClassToName[t]
@@ -69,6 +84,100 @@ func isPayToPubkeyECDSA(pops []parsedOpcode) bool {
 
 }
 
+// isMultiSig returns true if the passed script is an m-of-n multisig
+// transaction using the given public key push and multisig opcodes, false
+// otherwise. A multisig script is of the form:
+// <m> <pubkey 1> ... <pubkey n> <n> OP_CHECKMULTISIG
+func isMultiSig(pops []parsedOpcode, pubKeyOpcode byte, checkMultiSigOpcode byte) bool {
+	// The absolute minimum is 1 pubkey:
+	// OP_1 <pubkey> OP_1 OP_CHECKMULTISIG
+	numPops := len(pops)
+	if numPops < 4 {
+		return false
+	}
+	if !isSmallInt(pops[0].opcode) || !isSmallInt(pops[numPops-2].opcode) {
+		return false
+	}
+	if pops[numPops-1].opcode.value != checkMultiSigOpcode {
+		return false
+	}
+
+	// Verify the number of pubkeys specified matches the actual number
+	// of pubkeys provided and that at least one signature is required.
+	numPubKeys := asSmallInt(pops[numPops-2].opcode)
+	numSigs := asSmallInt(pops[0].opcode)
+	if numPubKeys != numPops-3 || numSigs < 1 || numSigs > numPubKeys {
+		return false
+	}
+	for _, pop := range pops[1 : numPops-2] {
+		if pop.opcode.value != pubKeyOpcode {
+			return false
+		}
+	}
+	return true
+}
+
+// lockTimeOrSequenceFromPush returns the lock time or sequence pushed by
+// pop, and whether pop is a canonical push of one as produced by
+// ScriptBuilder.AddLockTimeNumber and ScriptBuilder.AddSequenceNumber.
+func lockTimeOrSequenceFromPush(pop parsedOpcode) (uint64, bool) {
+	if isSmallInt(pop.opcode) {
+		value := asSmallInt(pop.opcode)
+		return uint64(value), value != 0
+	}
+	if pop.opcode.value < OpData1 || pop.opcode.value > OpData8 || !canonicalPush(pop) ||
+		pop.data[len(pop.data)-1] == 0 {
+		return 0, false
+	}
+	paddedData := make([]byte, 8)
+	copy(paddedData, pop.data)
+	return binary.LittleEndian.Uint64(paddedData), true
+}
+
+// isTimeLockedPubKey returns true if the passed script is a pay-to-pubkey
+// transaction guarded by the given lock time verification opcode, false
+// otherwise. A timelocked pay-to-pubkey script is of the form:
+// <lock time> OP_CHECKLOCKTIMEVERIFY <pubkey> OP_CHECKSIG
+// or, for relative lock times:
+// <sequence> OP_CHECKSEQUENCEVERIFY <pubkey> OP_CHECKSIG
+// Note that unlike in Bitcoin, the lock time opcodes pop their argument, so
+// no OP_DROP is needed.
+func isTimeLockedPubKey(pops []parsedOpcode, lockOpcode byte) bool {
+	if len(pops) != 4 {
+		return false
+	}
+	_, isLockTime := lockTimeOrSequenceFromPush(pops[0])
+	return isLockTime &&
+		pops[1].opcode.value == lockOpcode &&
+		isPayToPubkey(pops[2:])
+}
+
+// isHTLC returns true if the passed script is a hash-time-locked contract,
+// false otherwise. An HTLC script is of the form:
+// OP_IF OP_SIZE <32> OP_EQUALVERIFY OP_SHA256 <secret hash> OP_EQUALVERIFY <recipient pubkey>
+// OP_ELSE <lock time> OP_CHECKLOCKTIMEVERIFY <refund pubkey>
+// OP_ENDIF OP_CHECKSIG
+func isHTLC(pops []parsedOpcode) bool {
+	if len(pops) != 14 {
+		return false
+	}
+	_, isLockTime := lockTimeOrSequenceFromPush(pops[9])
+	return pops[0].opcode.value == OpIf &&
+		pops[1].opcode.value == OpSize &&
+		pops[2].opcode.value == OpData1 && pops[2].data[0] == HTLCSecretSize &&
+		pops[3].opcode.value == OpEqualVerify &&
+		pops[4].opcode.value == OpSHA256 &&
+		pops[5].opcode.value == OpData32 &&
+		pops[6].opcode.value == OpEqualVerify &&
+		pops[7].opcode.value == OpData32 &&
+		pops[8].opcode.value == OpElse &&
+		isLockTime &&
+		pops[10].opcode.value == OpCheckLockTimeVerify &&
+		pops[11].opcode.value == OpData32 &&
+		pops[12].opcode.value == OpEndIf &&
+		pops[13].opcode.value == OpCheckSig
+}
+
 // scriptType returns the type of the script being inspected from the known
 // standard types.
 func typeOfScript(pops []parsedOpcode) ScriptClass {
@@ -79,6 +188,16 @@ func typeOfScript(pops []parsedOpcode) ScriptClass {
 		return PubKeyECDSATy
 	case isScriptHash(pops):
 		return ScriptHashTy
+	case isMultiSig(pops, OpData32, OpCheckMultiSig):
+		return MultiSigTy
+	case isMultiSig(pops, OpData33, OpCheckMultiSigECDSA):
+		return MultiSigECDSATy
+	case isTimeLockedPubKey(pops, OpCheckLockTimeVerify):
+		return PubKeyLockTimeTy
+	case isTimeLockedPubKey(pops, OpCheckSequenceVerify):
+		return PubKeySequenceTy
+	case isHTLC(pops):
+		return HTLCTy
 	}
 	return NonStandardTy
 }
@@ -102,13 +221,25 @@ func GetScriptClass(script []byte) ScriptClass {
 func expectedInputs(pops []parsedOpcode, class ScriptClass) int {
 	switch class {
 
-	case PubKeyTy:
+	case PubKeyTy, PubKeyECDSATy, PubKeyLockTimeTy, PubKeySequenceTy:
 		return 1
 
 	case ScriptHashTy:
 		// Not including script. That is handled by the caller.
 		return 1
 
+	case MultiSigTy, MultiSigECDSATy:
+		// Standard multisig has a push of the number of required
+		// signatures at the beginning of the script. Unlike in Bitcoin,
+		// OP_CHECKMULTISIG does not consume an extra dummy element.
+		return asSmallInt(pops[0].opcode)
+
+	case HTLCTy:
+		// The redeem path takes the signature, the secret and OP_TRUE,
+		// while the refund path takes the signature and OP_FALSE, so
+		// the number of inputs can't be determined from the script.
+		return -1
+
 	default:
 		return -1
 	}
@@ -303,7 +434,10 @@ func PushedData(script []byte) ([][]byte, error) {
 
 // ExtractScriptPubKeyAddress returns the type of script and its addresses.
 // Note that it only works for 'standard' transaction script types. Any data such
-// as public keys which are invalid will return a nil address.
+// as public keys which are invalid will return a nil address. Script types which
+// are not paid to a single address (multisig, timelocked and HTLC scripts) also
+// return a nil address - use ExtractMultiSigAddresses,
+// ExtractTimeLockedScriptPubKeyAddress and ExtractHTLCDataPushes for those.
 func ExtractScriptPubKeyAddress(scriptPubKey *externalapi.ScriptPublicKey, dagParams *dagconfig.Params) (ScriptClass, util.Address, error) {
 	if scriptPubKey.Version > constants.MaxScriptPublicKeyVersion {
 		return NonStandardTy, nil, nil
@@ -352,6 +486,11 @@ func ExtractScriptPubKeyAddress(scriptPubKey *externalapi.ScriptPublicKey, dagPa
 		}
 		return scriptClass, addr, nil
 
+	case MultiSigTy, MultiSigECDSATy, PubKeyLockTimeTy, PubKeySequenceTy, HTLCTy:
+		// These scripts aren't paid to a single address. Their keys are
+		// available through the dedicated extraction helpers.
+		return scriptClass, nil, nil
+
 	case NonStandardTy:
 		// Don't attempt to extract addresses or required signatures for
 		// nonstandard transactions.
@@ -361,6 +500,251 @@ func ExtractScriptPubKeyAddress(scriptPubKey *externalapi.ScriptPublicKey, dagPa
 	return NonStandardTy, nil, errors.Errorf("Cannot handle script class %s", scriptClass)
 }
 
+// MultiSigScript returns a valid script for a multisignature redemption where
+// nRequired of the keys in pubKeys are required to have signed the transaction
+// for success. The keys are 32-byte Schnorr public keys, or 33-byte ECDSA
+// public keys if ecdsa is set. An Error with the error code
+// ErrTooManyRequiredSigs will be returned if nRequired is larger than the
+// number of keys provided.
+func MultiSigScript(pubKeys [][]byte, nRequired int, ecdsa bool) ([]byte, error) {
+	if len(pubKeys) < nRequired {
+		str := fmt.Sprintf("unable to generate multisig script with "+
+			"%d required signatures when there are only %d public "+
+			"keys available", nRequired, len(pubKeys))
+		return nil, scriptError(ErrTooManyRequiredSigs, str)
+	}
+	if nRequired < 1 || len(pubKeys) > MaxPubKeysPerStandardMultiSig {
+		str := fmt.Sprintf("unable to generate a standard %d-of-%d multisig script",
+			nRequired, len(pubKeys))
+		return nil, scriptError(ErrInvalidPubKeyCount, str)
+	}
+
+	pubKeyLength := 32
+	var checkMultiSigOpcode byte = OpCheckMultiSig
+	if ecdsa {
+		pubKeyLength = 33
+		checkMultiSigOpcode = OpCheckMultiSigECDSA
+	}
+
+	builder := NewScriptBuilder().AddInt64(int64(nRequired))
+	for _, pubKey := range pubKeys {
+		if len(pubKey) != pubKeyLength {
+			str := fmt.Sprintf("public key %x is not %d bytes long", pubKey, pubKeyLength)
+			return nil, scriptError(ErrUnsupportedAddress, str)
+		}
+		builder.AddData(pubKey)
+	}
+	builder.AddInt64(int64(len(pubKeys)))
+	builder.AddOp(checkMultiSigOpcode)
+
+	return builder.Script()
+}
+
+// CalcMultiSigStats returns the number of public keys and signatures from
+// a multi-signature transaction script. The passed script MUST already be
+// known to be a multi-signature script.
+func CalcMultiSigStats(script []byte) (int, int, error) {
+	pops, err := parseScript(script)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	scriptClass := typeOfScript(pops)
+	if scriptClass != MultiSigTy && scriptClass != MultiSigECDSATy {
+		str := fmt.Sprintf("script %x is not a multisig script", script)
+		return 0, 0, scriptError(ErrNotMultisigScript, str)
+	}
+
+	numPubKeys := asSmallInt(pops[len(pops)-2].opcode)
+	numSigs := asSmallInt(pops[0].opcode)
+	return numPubKeys, numSigs, nil
+}
+
+// ExtractMultiSigAddresses returns the class of a bare multisig script public
+// key, the number of signatures it requires and the addresses of its public
+// keys, in script order. If the script public key is not a multisig script,
+// NonStandardTy is returned along with no addresses.
+func ExtractMultiSigAddresses(scriptPubKey *externalapi.ScriptPublicKey, dagParams *dagconfig.Params) (
+	ScriptClass, int, []util.Address, error) {
+
+	if scriptPubKey.Version > constants.MaxScriptPublicKeyVersion {
+		return NonStandardTy, 0, nil, nil
+	}
+	pops, err := parseScript(scriptPubKey.Script)
+	if err != nil {
+		return NonStandardTy, 0, nil, err
+	}
+
+	scriptClass := typeOfScript(pops)
+	if scriptClass != MultiSigTy && scriptClass != MultiSigECDSATy {
+		return NonStandardTy, 0, nil, nil
+	}
+
+	numSigs := asSmallInt(pops[0].opcode)
+	pubKeyPops := pops[1 : len(pops)-2]
+	addresses := make([]util.Address, 0, len(pubKeyPops))
+	for _, pop := range pubKeyPops {
+		var address util.Address
+		if scriptClass == MultiSigTy {
+			address, err = util.NewAddressPublicKey(pop.data, dagParams.Prefix)
+		} else {
+			address, err = util.NewAddressPublicKeyECDSA(pop.data, dagParams.Prefix)
+		}
+		if err != nil {
+			return scriptClass, numSigs, nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	return scriptClass, numSigs, addresses, nil
+}
+
+// PayToPubKeyLockTimeScript creates a new script that pays to a 32-byte
+// pubkey once the transaction lock time has reached lockTime, which is
+// either a DAA score or a timestamp as in OP_CHECKLOCKTIMEVERIFY.
+func PayToPubKeyLockTimeScript(pubKey []byte, lockTime uint64) ([]byte, error) {
+	return timeLockedPubKeyScript(pubKey, lockTime, OpCheckLockTimeVerify)
+}
+
+// PayToPubKeySequenceScript creates a new script that pays to a 32-byte
+// pubkey once the spending input's sequence satisfies sequence, as in
+// OP_CHECKSEQUENCEVERIFY.
+func PayToPubKeySequenceScript(pubKey []byte, sequence uint64) ([]byte, error) {
+	return timeLockedPubKeyScript(pubKey, sequence, OpCheckSequenceVerify)
+}
+
+func timeLockedPubKeyScript(pubKey []byte, lockTimeOrSequence uint64, lockOpcode byte) ([]byte, error) {
+	if lockTimeOrSequence == 0 {
+		return nil, errors.New("a timelocked script requires a non-zero lock time")
+	}
+	return NewScriptBuilder().
+		AddLockTimeNumber(lockTimeOrSequence).
+		AddOp(lockOpcode).
+		AddData(pubKey).
+		AddOp(OpCheckSig).
+		Script()
+}
+
+// ExtractTimeLockedScriptPubKeyAddress returns the class of a timelocked
+// pay-to-pubkey script public key, the address of its public key and its lock
+// time (for PubKeyLockTimeTy) or sequence (for PubKeySequenceTy). If the script
+// public key is not a timelocked pay-to-pubkey script, NonStandardTy is
+// returned along with a nil address.
+func ExtractTimeLockedScriptPubKeyAddress(scriptPubKey *externalapi.ScriptPublicKey, dagParams *dagconfig.Params) (
+	ScriptClass, util.Address, uint64, error) {
+
+	if scriptPubKey.Version > constants.MaxScriptPublicKeyVersion {
+		return NonStandardTy, nil, 0, nil
+	}
+	pops, err := parseScript(scriptPubKey.Script)
+	if err != nil {
+		return NonStandardTy, nil, 0, err
+	}
+
+	scriptClass := typeOfScript(pops)
+	if scriptClass != PubKeyLockTimeTy && scriptClass != PubKeySequenceTy {
+		return NonStandardTy, nil, 0, nil
+	}
+
+	lockTimeOrSequence, _ := lockTimeOrSequenceFromPush(pops[0])
+	address, err := util.NewAddressPublicKey(pops[2].data, dagParams.Prefix)
+	if err != nil {
+		return scriptClass, nil, lockTimeOrSequence, nil
+	}
+	return scriptClass, address, lockTimeOrSequence, nil
+}
+
+// HTLCSecretSize is the size of the secret an HTLC script can be redeemed with.
+const HTLCSecretSize = 32
+
+// HTLCScript creates a hash-time-locked contract script. The script can be
+// spent by recipientPubKey with the preimage of secretHash (SHA256), or by
+// refundPubKey once the transaction lock time has reached lockTime.
+func HTLCScript(recipientPubKey, refundPubKey, secretHash []byte, lockTime uint64) ([]byte, error) {
+	if lockTime == 0 {
+		return nil, errors.New("an HTLC script requires a non-zero lock time")
+	}
+	return NewScriptBuilder().
+		AddOp(OpIf).
+		AddOp(OpSize).AddInt64(HTLCSecretSize).AddOp(OpEqualVerify).
+		AddOp(OpSHA256).AddData(secretHash).AddOp(OpEqualVerify).
+		AddData(recipientPubKey).
+		AddOp(OpElse).
+		AddLockTimeNumber(lockTime).AddOp(OpCheckLockTimeVerify).
+		AddData(refundPubKey).
+		AddOp(OpEndIf).
+		AddOp(OpCheckSig).
+		Script()
+}
+
+// HTLCRedeemSignatureScript creates the part of a signature script that spends
+// an HTLC script through its secret path. For a P2SH-wrapped HTLC, the redeem
+// script should be appended with PayToScriptHashSignatureScript.
+func HTLCRedeemSignatureScript(signature, secret []byte) ([]byte, error) {
+	return NewScriptBuilder().AddData(signature).AddData(secret).AddOp(OpTrue).Script()
+}
+
+// HTLCRefundSignatureScript creates the part of a signature script that spends
+// an HTLC script through its refund path. For a P2SH-wrapped HTLC, the redeem
+// script should be appended with PayToScriptHashSignatureScript.
+func HTLCRefundSignatureScript(signature []byte) ([]byte, error) {
+	return NewScriptBuilder().AddData(signature).AddOp(OpFalse).Script()
+}
+
+// HTLCDataPushes houses the data pushes found in HTLC scripts.
+type HTLCDataPushes struct {
+	RecipientPubKey [32]byte
+	RefundPubKey    [32]byte
+	SecretHash      [32]byte
+	LockTime        uint64
+}
+
+// ExtractHTLCDataPushes returns the data pushes from an HTLC script. If the
+// script is not an HTLC script, ExtractHTLCDataPushes returns (nil, nil).
+// Non-nil errors are returned for unparsable scripts.
+func ExtractHTLCDataPushes(script []byte) (*HTLCDataPushes, error) {
+	pops, err := parseScript(script)
+	if err != nil {
+		return nil, err
+	}
+	if !isHTLC(pops) {
+		return nil, nil
+	}
+
+	pushes := new(HTLCDataPushes)
+	copy(pushes.SecretHash[:], pops[5].data)
+	copy(pushes.RecipientPubKey[:], pops[7].data)
+	copy(pushes.RefundPubKey[:], pops[11].data)
+	pushes.LockTime, _ = lockTimeOrSequenceFromPush(pops[9])
+	return pushes, nil
+}
+
+// maxTemplateSignaturePushLength is the length of a push of a 64-byte Schnorr
+// or ECDSA signature followed by its sighash type.
+const maxTemplateSignaturePushLength = 1 + 64 + 1
+
+// MaxTemplateSignatureScriptLength returns the maximum length of a signature
+// script spending script, if script is one of the multisig, timelocked or HTLC
+// templates. For pay-to-script-hash spends the length does not include the push
+// of the redeem script itself. The second return value is false for scripts of
+// any other class.
+func MaxTemplateSignatureScriptLength(script []byte) (int, bool) {
+	pops, err := parseScript(script)
+	if err != nil {
+		return 0, false
+	}
+
+	switch typeOfScript(pops) {
+	case PubKeyLockTimeTy, PubKeySequenceTy:
+		return maxTemplateSignaturePushLength, true
+	case MultiSigTy, MultiSigECDSATy:
+		return asSmallInt(pops[0].opcode) * maxTemplateSignaturePushLength, true
+	case HTLCTy:
+		// <signature> <secret> OP_TRUE is longer than the refund path.
+		return maxTemplateSignaturePushLength + 1 + HTLCSecretSize + 1, true
+	}
+	return 0, false
+}
+
 // AtomicSwapDataPushes houses the data pushes found in atomic swap contracts.
 type AtomicSwapDataPushes struct {
 	RecipientBlake2b [32]byte
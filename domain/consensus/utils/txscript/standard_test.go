@@ -6,12 +6,16 @@ package txscript
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
 	"reflect"
 	"testing"
 
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/consensushashing"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
 	"github.com/Kash-Protocol/kashd/domain/dagconfig"
 	"github.com/Kash-Protocol/kashd/util"
+	"github.com/kaspanet/go-secp256k1"
 )
 
 // mustParseShortForm parses the passed short form script and returns the
@@ -486,6 +490,51 @@ var scriptClassTests = []struct {
 		script: "1 CHECKMULTISIG",
 		class:  NonStandardTy,
 	},
+	{
+		name: "Schnorr multisig",
+		script: "1 DATA_32 0x89ac24ea10bb751af4939623ccc5e550d96842b64e8fca0f63e94b4373fd555e " +
+			"DATA_32 0x2454a285d8566b0cb2792919536ee0f1b6f69b58ba59e9850ecbc91eef722dae 2 CHECKMULTISIG",
+		class: MultiSigTy,
+	},
+	{
+		name: "ECDSA multisig",
+		script: "2 DATA_33 0x0232abdc893e7f0631364d7fd01cb33d24da45329a00357b3a7886211ab414d55a " +
+			"DATA_33 0x02c08f3de8ee2de9be7bd770f4c10eb0d6ff1dd81ee96eedd3a9d4aeaf86695e80 2 CHECKMULTISIGECDSA",
+		class: MultiSigECDSATy,
+	},
+	{
+		name: "multisig requiring no signatures",
+		script: "0 DATA_32 0x89ac24ea10bb751af4939623ccc5e550d96842b64e8fca0f63e94b4373fd555e " +
+			"1 CHECKMULTISIG",
+		class: NonStandardTy,
+	},
+	{
+		name: "CLTV pay pubkey",
+		script: "DATA_2 0xe803 CHECKLOCKTIMEVERIFY " +
+			"DATA_32 0x89ac24ea10bb751af4939623ccc5e550d96842b64e8fca0f63e94b4373fd555e CHECKSIG",
+		class: PubKeyLockTimeTy,
+	},
+	{
+		name: "CSV pay pubkey",
+		script: "10 CHECKSEQUENCEVERIFY " +
+			"DATA_32 0x89ac24ea10bb751af4939623ccc5e550d96842b64e8fca0f63e94b4373fd555e CHECKSIG",
+		class: PubKeySequenceTy,
+	},
+	{
+		name: "CLTV pay pubkey with a padded lock time",
+		script: "DATA_2 0x0a00 CHECKLOCKTIMEVERIFY " +
+			"DATA_32 0x89ac24ea10bb751af4939623ccc5e550d96842b64e8fca0f63e94b4373fd555e CHECKSIG",
+		class: NonStandardTy,
+	},
+	{
+		name: "HTLC",
+		script: "IF SIZE DATA_1 0x20 EQUALVERIFY SHA256 " +
+			"DATA_32 0x433ec2ac1ffa1b7b7d027f564529c57197fa1b7b7d027f564529c57197f9ae88 EQUALVERIFY " +
+			"DATA_32 0x89ac24ea10bb751af4939623ccc5e550d96842b64e8fca0f63e94b4373fd555e " +
+			"ELSE DATA_2 0xe803 CHECKLOCKTIMEVERIFY " +
+			"DATA_32 0x2454a285d8566b0cb2792919536ee0f1b6f69b58ba59e9850ecbc91eef722dae ENDIF CHECKSIG",
+		class: HTLCTy,
+	},
 	{
 		name:   "doesn't parse",
 		script: "DATA_5 0x01020304",
@@ -551,6 +600,31 @@ func TestStringifyClass(t *testing.T) {
 			class:    ScriptHashTy,
 			stringed: "scripthash",
 		},
+		{
+			name:     "multisig",
+			class:    MultiSigTy,
+			stringed: "multisig",
+		},
+		{
+			name:     "multisigecdsa",
+			class:    MultiSigECDSATy,
+			stringed: "multisigecdsa",
+		},
+		{
+			name:     "pubkeylocktime",
+			class:    PubKeyLockTimeTy,
+			stringed: "pubkeylocktime",
+		},
+		{
+			name:     "pubkeysequence",
+			class:    PubKeySequenceTy,
+			stringed: "pubkeysequence",
+		},
+		{
+			name:     "htlc",
+			class:    HTLCTy,
+			stringed: "htlc",
+		},
 		{
 			name:     "broken",
 			class:    ScriptClass(255),
@@ -566,3 +640,161 @@ func TestStringifyClass(t *testing.T) {
 		}
 	}
 }
+
+// TestStandardTemplates ensures the multisig, timelocked and HTLC templates are
+// recognized, that their data can be extracted, and that they can be spent.
+func TestStandardTemplates(t *testing.T) {
+	t.Parallel()
+
+	keys := make([]*secp256k1.SchnorrKeyPair, 3)
+	pubKeys := make([][]byte, 3)
+	for i := range keys {
+		key, err := secp256k1.GenerateSchnorrKeyPair()
+		if err != nil {
+			t.Fatalf("GenerateSchnorrKeyPair: %s", err)
+		}
+		publicKey, err := key.SchnorrPublicKey()
+		if err != nil {
+			t.Fatalf("SchnorrPublicKey: %s", err)
+		}
+		serializedPublicKey, err := publicKey.Serialize()
+		if err != nil {
+			t.Fatalf("Serialize: %s", err)
+		}
+		keys[i] = key
+		pubKeys[i] = serializedPublicKey[:]
+	}
+
+	spend := func(script []byte, lockTime uint64, signatureScript func(tx *externalapi.DomainTransaction) []byte) error {
+		scriptPublicKey := &externalapi.ScriptPublicKey{Script: script, Version: 0}
+		tx := &externalapi.DomainTransaction{
+			Inputs: []*externalapi.DomainTransactionInput{{
+				UTXOEntry: utxo.NewUTXOEntry(500, scriptPublicKey, false, 100),
+				Sequence:  0,
+			}},
+			Outputs:  []*externalapi.DomainTransactionOutput{{Value: 400, ScriptPublicKey: scriptPublicKey}},
+			LockTime: lockTime,
+		}
+		return checkScripts("template", tx, 0, signatureScript(tx), scriptPublicKey)
+	}
+	sign := func(tx *externalapi.DomainTransaction, key *secp256k1.SchnorrKeyPair) []byte {
+		signature, err := RawTxInSignature(tx, 0, consensushashing.SigHashAll, key, &consensushashing.SighashReusedValues{})
+		if err != nil {
+			t.Fatalf("RawTxInSignature: %s", err)
+		}
+		return signature
+	}
+
+	// 2-of-3 multisig
+	multiSigScript, err := MultiSigScript(pubKeys, 2, false)
+	if err != nil {
+		t.Fatalf("MultiSigScript: %s", err)
+	}
+	class, numSigs, addresses, err := ExtractMultiSigAddresses(
+		&externalapi.ScriptPublicKey{Script: multiSigScript}, &dagconfig.MainnetParams)
+	if err != nil || class != MultiSigTy || numSigs != 2 || len(addresses) != 3 ||
+		!bytes.Equal(addresses[1].ScriptAddress(), pubKeys[1]) {
+		t.Fatalf("ExtractMultiSigAddresses: unexpected result %s, %d, %v, %v", class, numSigs, addresses, err)
+	}
+	_, err = MultiSigScript(pubKeys, 4, false)
+	if !IsErrorCode(err, ErrTooManyRequiredSigs) {
+		t.Fatalf("MultiSigScript: expected ErrTooManyRequiredSigs, got %v", err)
+	}
+	err = spend(multiSigScript, 0, func(tx *externalapi.DomainTransaction) []byte {
+		signatureScript, err := NewScriptBuilder().AddData(sign(tx, keys[0])).AddData(sign(tx, keys[2])).Script()
+		if err != nil {
+			t.Fatalf("Script: %s", err)
+		}
+		return signatureScript
+	})
+	if err != nil {
+		t.Fatalf("Spending multisig: %s", err)
+	}
+
+	// Timelocked pay-to-pubkey
+	lockTimeScript, err := PayToPubKeyLockTimeScript(pubKeys[0], 1000)
+	if err != nil {
+		t.Fatalf("PayToPubKeyLockTimeScript: %s", err)
+	}
+	class, address, lockTime, err := ExtractTimeLockedScriptPubKeyAddress(
+		&externalapi.ScriptPublicKey{Script: lockTimeScript}, &dagconfig.MainnetParams)
+	if err != nil || class != PubKeyLockTimeTy || lockTime != 1000 || !bytes.Equal(address.ScriptAddress(), pubKeys[0]) {
+		t.Fatalf("ExtractTimeLockedScriptPubKeyAddress: unexpected result %s, %v, %d, %v", class, address, lockTime, err)
+	}
+	signLockTime := func(tx *externalapi.DomainTransaction) []byte {
+		signatureScript, err := NewScriptBuilder().AddData(sign(tx, keys[0])).Script()
+		if err != nil {
+			t.Fatalf("Script: %s", err)
+		}
+		return signatureScript
+	}
+	err = spend(lockTimeScript, 1000, signLockTime)
+	if err != nil {
+		t.Fatalf("Spending timelocked pay-to-pubkey: %s", err)
+	}
+	err = spend(lockTimeScript, 999, signLockTime)
+	if err == nil {
+		t.Fatalf("Spending timelocked pay-to-pubkey before its lock time unexpectedly succeeded")
+	}
+
+	sequenceScript, err := PayToPubKeySequenceScript(pubKeys[0], 10)
+	if err != nil {
+		t.Fatalf("PayToPubKeySequenceScript: %s", err)
+	}
+	if GetScriptClass(sequenceScript) != PubKeySequenceTy {
+		t.Fatalf("PayToPubKeySequenceScript: unexpected class %s", GetScriptClass(sequenceScript))
+	}
+
+	// HTLC
+	secret := bytes.Repeat([]byte{0x42}, HTLCSecretSize)
+	secretHash := sha256.Sum256(secret)
+	htlcScript, err := HTLCScript(pubKeys[0], pubKeys[1], secretHash[:], 1000)
+	if err != nil {
+		t.Fatalf("HTLCScript: %s", err)
+	}
+	pushes, err := ExtractHTLCDataPushes(htlcScript)
+	if err != nil || pushes == nil {
+		t.Fatalf("ExtractHTLCDataPushes: unexpected result %v, %v", pushes, err)
+	}
+	if pushes.SecretHash != secretHash || pushes.LockTime != 1000 ||
+		!bytes.Equal(pushes.RecipientPubKey[:], pubKeys[0]) || !bytes.Equal(pushes.RefundPubKey[:], pubKeys[1]) {
+		t.Fatalf("ExtractHTLCDataPushes: unexpected pushes %+v", pushes)
+	}
+	err = spend(htlcScript, 0, func(tx *externalapi.DomainTransaction) []byte {
+		signatureScript, err := HTLCRedeemSignatureScript(sign(tx, keys[0]), secret)
+		if err != nil {
+			t.Fatalf("HTLCRedeemSignatureScript: %s", err)
+		}
+		return signatureScript
+	})
+	if err != nil {
+		t.Fatalf("Redeeming HTLC: %s", err)
+	}
+	err = spend(htlcScript, 1000, func(tx *externalapi.DomainTransaction) []byte {
+		signatureScript, err := HTLCRefundSignatureScript(sign(tx, keys[1]))
+		if err != nil {
+			t.Fatalf("HTLCRefundSignatureScript: %s", err)
+		}
+		return signatureScript
+	})
+	if err != nil {
+		t.Fatalf("Refunding HTLC: %s", err)
+	}
+	err = spend(htlcScript, 0, func(tx *externalapi.DomainTransaction) []byte {
+		signatureScript, err := HTLCRedeemSignatureScript(sign(tx, keys[0]), bytes.Repeat([]byte{0x43}, HTLCSecretSize))
+		if err != nil {
+			t.Fatalf("HTLCRedeemSignatureScript: %s", err)
+		}
+		return signatureScript
+	})
+	if err == nil {
+		t.Fatalf("Redeeming HTLC with the wrong secret unexpectedly succeeded")
+	}
+
+	for _, script := range [][]byte{multiSigScript, lockTimeScript, htlcScript} {
+		class, address, err := ExtractScriptPubKeyAddress(&externalapi.ScriptPublicKey{Script: script}, &dagconfig.MainnetParams)
+		if err != nil || class == NonStandardTy || address != nil {
+			t.Fatalf("ExtractScriptPubKeyAddress: unexpected result %s, %v, %v", class, address, err)
+		}
+	}
+}
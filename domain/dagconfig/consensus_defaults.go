@@ -1,8 +1,10 @@
 package dagconfig
 
 import (
-	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
+	"math"
 	"time"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
 )
 
 // The documentation refers to the following constants which aren't explicated in the code:
@@ -32,6 +34,9 @@ const (
 	defaultMassPerTxByte           = 1
 	defaultMassPerScriptPubKeyByte = 10
 	defaultMassPerSigOp            = 1000
+	// defaultMassPerSpliceOp is the number of grams that any OP_CAT or OP_SUBSTR in the scripts of a
+	// covenants transaction adds to it. Each of them may allocate a stack element of up to 520 bytes.
+	defaultMassPerSpliceOp = 100
 	// defaultMaxBlockParents is the number of blocks any block can point to.
 	// Should be about d/defaultTargetTimePerBlock where d is a bound on the round trip time of a block.
 	defaultMaxBlockParents = 10
@@ -84,4 +89,8 @@ const (
 	defaultDeflationaryPhaseDaaScore = 15778800 - 259200
 
 	defaultMergeDepth = 3600
+
+	// defaultCovenantsActivationDAAScore is the DAA score from which covenants transactions are
+	// valid on networks where they're not scheduled yet.
+	defaultCovenantsActivationDAAScore = math.MaxUint64
 )
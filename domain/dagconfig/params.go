@@ -169,6 +169,10 @@ type Params struct {
 	// signature operation adds to a transaction.
 	MassPerSigOp uint64
 
+	// MassPerSpliceOp is the number of grams that any OP_CAT or OP_SUBSTR
+	// in the scripts of a covenants transaction adds to it.
+	MassPerSpliceOp uint64
+
 	// MergeSetSizeLimit is the maximum number of blocks in a block's merge set
 	MergeSetSizeLimit uint64
 
@@ -182,6 +186,11 @@ type Params struct {
 	// to its deflationary phase
 	DeflationaryPhaseDaaScore uint64
 
+	// CovenantsActivationDAAScore is the DAA score from which transactions of
+	// constants.CovenantsTransactionVersion, which may use the transaction
+	// introspection opcodes, OP_CAT and OP_SUBSTR, are valid
+	CovenantsActivationDAAScore uint64
+
 	DisallowDirectBlocksOnTopOfGenesis bool
 
 	// MaxBlockLevel is the maximum possible block level.
@@ -259,10 +268,12 @@ var MainnetParams = Params{
 	MassPerTxByte:                           defaultMassPerTxByte,
 	MassPerScriptPubKeyByte:                 defaultMassPerScriptPubKeyByte,
 	MassPerSigOp:                            defaultMassPerSigOp,
+	MassPerSpliceOp:                         defaultMassPerSpliceOp,
 	MergeSetSizeLimit:                       defaultMergeSetSizeLimit,
 	CoinbasePayloadScriptPublicKeyMaxLength: defaultCoinbasePayloadScriptPublicKeyMaxLength,
 	PruningProofM:                           defaultPruningProofM,
 	DeflationaryPhaseDaaScore:               defaultDeflationaryPhaseDaaScore,
+	CovenantsActivationDAAScore:             defaultCovenantsActivationDAAScore,
 	DisallowDirectBlocksOnTopOfGenesis:      true,
 
 	// This is technically 255, but we clamped it at 256 - block level of mainnet genesis
@@ -324,10 +335,12 @@ var TestnetParams = Params{
 	MassPerTxByte:                           defaultMassPerTxByte,
 	MassPerScriptPubKeyByte:                 defaultMassPerScriptPubKeyByte,
 	MassPerSigOp:                            defaultMassPerSigOp,
+	MassPerSpliceOp:                         defaultMassPerSpliceOp,
 	MergeSetSizeLimit:                       defaultMergeSetSizeLimit,
 	CoinbasePayloadScriptPublicKeyMaxLength: defaultCoinbasePayloadScriptPublicKeyMaxLength,
 	PruningProofM:                           defaultPruningProofM,
 	DeflationaryPhaseDaaScore:               defaultDeflationaryPhaseDaaScore,
+	CovenantsActivationDAAScore:             defaultCovenantsActivationDAAScore,
 
 	MaxBlockLevel: 250,
 	MergeDepth:    defaultMergeDepth,
@@ -390,10 +403,12 @@ var SimnetParams = Params{
 	MassPerTxByte:                           defaultMassPerTxByte,
 	MassPerScriptPubKeyByte:                 defaultMassPerScriptPubKeyByte,
 	MassPerSigOp:                            defaultMassPerSigOp,
+	MassPerSpliceOp:                         defaultMassPerSpliceOp,
 	MergeSetSizeLimit:                       defaultMergeSetSizeLimit,
 	CoinbasePayloadScriptPublicKeyMaxLength: defaultCoinbasePayloadScriptPublicKeyMaxLength,
 	PruningProofM:                           defaultPruningProofM,
 	DeflationaryPhaseDaaScore:               defaultDeflationaryPhaseDaaScore,
+	CovenantsActivationDAAScore:             0,
 
 	MaxBlockLevel: 250,
 	MergeDepth:    defaultMergeDepth,
@@ -452,10 +467,12 @@ var DevnetParams = Params{
 	MassPerTxByte:                           defaultMassPerTxByte,
 	MassPerScriptPubKeyByte:                 defaultMassPerScriptPubKeyByte,
 	MassPerSigOp:                            defaultMassPerSigOp,
+	MassPerSpliceOp:                         defaultMassPerSpliceOp,
 	MergeSetSizeLimit:                       defaultMergeSetSizeLimit,
 	CoinbasePayloadScriptPublicKeyMaxLength: defaultCoinbasePayloadScriptPublicKeyMaxLength,
 	PruningProofM:                           defaultPruningProofM,
 	DeflationaryPhaseDaaScore:               defaultDeflationaryPhaseDaaScore,
+	CovenantsActivationDAAScore:             0,
 
 	MaxBlockLevel: 250,
 	MergeDepth:    defaultMergeDepth,
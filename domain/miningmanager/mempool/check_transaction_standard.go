@@ -17,6 +17,12 @@ const (
 	// that are considered standard in a pay-to-script-hash script.
 	maxStandardP2SHSigOps = 15
 
+	// maxStandardBareMultiSigPubKeys is the maximum number of public keys
+	// allowed in a bare (non pay-to-script-hash) multisig output to be
+	// considered standard. Larger multisigs should be wrapped in
+	// pay-to-script-hash, so that their keys don't bloat the UTXO set.
+	maxStandardBareMultiSigPubKeys = 3
+
 	// maximumStandardSignatureScriptSize is the maximum size allowed for a
 	// transaction input signature script to be considered standard. This
 	// value allows for a 15-of-15 CHECKMULTISIG pay-to-script-hash with
@@ -82,9 +88,21 @@ func (mp *mempool) checkTransactionStandardInIsolation(transaction *externalapi.
 			return transactionRuleError(RejectNonstandard, "The version of the scriptPublicKey is higher than the known version.")
 		}
 		scriptClass := txscript.GetScriptClass(output.ScriptPublicKey.Script)
-		if scriptClass == txscript.NonStandardTy {
+		switch scriptClass {
+		case txscript.NonStandardTy:
 			str := fmt.Sprintf("transaction output %d: non-standard script form", i)
 			return transactionRuleError(RejectNonstandard, str)
+
+		case txscript.MultiSigTy, txscript.MultiSigECDSATy:
+			numPubKeys, _, err := txscript.CalcMultiSigStats(output.ScriptPublicKey.Script)
+			if err != nil {
+				return err
+			}
+			if numPubKeys > maxStandardBareMultiSigPubKeys {
+				str := fmt.Sprintf("transaction output %d: bare multisig has %d public keys which is more "+
+					"than the allowed max amount of %d", i, numPubKeys, maxStandardBareMultiSigPubKeys)
+				return transactionRuleError(RejectNonstandard, str)
+			}
 		}
 
 		if mp.IsTransactionOutputDust(output) {
@@ -148,7 +166,9 @@ func (mp *mempool) IsTransactionOutputDust(output *externalapi.DomainTransaction
 // inputs to ensure they are "standard". A standard transaction input within the
 // context of this function is one whose referenced public key script is of a
 // standard form and, for pay-to-script-hash, does not have more than
-// maxStandardP2SHSigOps signature operations.
+// maxStandardP2SHSigOps signature operations. Inputs spending a multisig,
+// timelocked or HTLC template, either bare or as a pay-to-script-hash redeem
+// script, must also not carry more signature script mass than the template needs.
 // In addition, makes sure that the transaction's fee is above the minimum for acceptance
 // into the mempool and relay
 func (mp *mempool) checkTransactionStandardInContext(transaction *externalapi.DomainTransaction) error {
@@ -168,9 +188,30 @@ func (mp *mempool) checkTransactionStandardInContext(transaction *externalapi.Do
 				return transactionRuleError(RejectNonstandard, str)
 			}
 
+			pushedData, err := txscript.PushedData(input.SignatureScript)
+			if err != nil || len(pushedData) == 0 {
+				str := fmt.Sprintf("transaction input #%d has a malformed pay-to-script-hash signature script", i)
+				return transactionRuleError(RejectNonstandard, str)
+			}
+			redeemScript := pushedData[len(pushedData)-1]
+			redeemScriptPush, err := txscript.NewScriptBuilder().AddData(redeemScript).Script()
+			if err != nil {
+				return err
+			}
+			err = checkTemplateSignatureScriptLength(i, redeemScript, len(input.SignatureScript)-len(redeemScriptPush))
+			if err != nil {
+				return err
+			}
+
 		case txscript.NonStandardTy:
 			str := fmt.Sprintf("transaction input #%d has a non-standard script form", i)
 			return transactionRuleError(RejectNonstandard, str)
+
+		default:
+			err := checkTemplateSignatureScriptLength(i, originScriptPubKey.Script, len(input.SignatureScript))
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -184,6 +225,24 @@ func (mp *mempool) checkTransactionStandardInContext(transaction *externalapi.Do
 	return nil
 }
 
+// checkTemplateSignatureScriptLength makes sure that an input spending script
+// with a signature script of signatureScriptLength bytes (not counting a
+// pay-to-script-hash redeem script push) does not exceed the length required
+// by script's template. Since every signature script byte adds to the
+// transaction's mass, this bounds the mass of template spends and keeps them
+// from being padded with extra data. Scripts which are not templates are not
+// limited here.
+func checkTemplateSignatureScriptLength(inputIndex int, script []byte, signatureScriptLength int) error {
+	maxSignatureScriptLength, isTemplate := txscript.MaxTemplateSignatureScriptLength(script)
+	if !isTemplate || signatureScriptLength <= maxSignatureScriptLength {
+		return nil
+	}
+	str := fmt.Sprintf("transaction input #%d has a signature script of %d bytes which is more than the "+
+		"%d bytes needed to spend its %s script", inputIndex, signatureScriptLength, maxSignatureScriptLength,
+		txscript.GetScriptClass(script))
+	return transactionRuleError(RejectNonstandard, str)
+}
+
 // minimumRequiredTransactionRelayFee returns the minimum transaction fee required for a
 // transaction with the passed mass to be accepted into the mampool and relayed.
 func (mp *mempool) minimumRequiredTransactionRelayFee(mass uint64) uint64 {
@@ -218,6 +218,15 @@ func TestCheckTransactionStandardInIsolation(t *testing.T) {
 		Value:           100000000, // 1 KSH
 		ScriptPublicKey: dummyScriptPublicKey,
 	}
+	templateTxOut := func(script []byte, err error) *externalapi.DomainTransactionOutput {
+		if err != nil {
+			t.Fatalf("Failed to build template script: %v", err)
+		}
+		return &externalapi.DomainTransactionOutput{Value: 100000000, ScriptPublicKey: &externalapi.ScriptPublicKey{Script: script, Version: 0}}
+	}
+	pubKeys := [][]byte{bytes.Repeat([]byte{0x01}, 32), bytes.Repeat([]byte{0x02}, 32),
+		bytes.Repeat([]byte{0x03}, 32), bytes.Repeat([]byte{0x04}, 32)}
+	secretHash := bytes.Repeat([]byte{0x05}, 32)
 
 	tests := []struct {
 		name       string
@@ -234,7 +243,7 @@ func TestCheckTransactionStandardInIsolation(t *testing.T) {
 		},
 		{
 			name:       "Transaction version too high",
-			tx:         &externalapi.DomainTransaction{Version: constants.MaxTransactionVersion + 1, Inputs: []*externalapi.DomainTransactionInput{&dummyTxIn}, Outputs: []*externalapi.DomainTransactionOutput{&dummyTxOut}},
+			tx:         &externalapi.DomainTransaction{Version: constants.CovenantsTransactionVersion + 1, Inputs: []*externalapi.DomainTransactionInput{&dummyTxIn}, Outputs: []*externalapi.DomainTransactionOutput{&dummyTxOut}},
 			height:     300000,
 			isStandard: false,
 			code:       RejectNonstandard,
@@ -271,6 +280,31 @@ func TestCheckTransactionStandardInIsolation(t *testing.T) {
 			isStandard: false,
 			code:       RejectNonstandard,
 		},
+		{
+			name:       "Bare 2-of-3 multisig output",
+			tx:         &externalapi.DomainTransaction{Version: 0, Inputs: []*externalapi.DomainTransactionInput{&dummyTxIn}, Outputs: []*externalapi.DomainTransactionOutput{templateTxOut(txscript.MultiSigScript(pubKeys[:3], 2, false))}},
+			height:     300000,
+			isStandard: true,
+		},
+		{
+			name:       "Bare multisig output with too many public keys",
+			tx:         &externalapi.DomainTransaction{Version: 0, Inputs: []*externalapi.DomainTransactionInput{&dummyTxIn}, Outputs: []*externalapi.DomainTransactionOutput{templateTxOut(txscript.MultiSigScript(pubKeys, 2, false))}},
+			height:     300000,
+			isStandard: false,
+			code:       RejectNonstandard,
+		},
+		{
+			name:       "Timelocked pay-to-pubkey outputs",
+			tx:         &externalapi.DomainTransaction{Version: 0, Inputs: []*externalapi.DomainTransactionInput{&dummyTxIn}, Outputs: []*externalapi.DomainTransactionOutput{templateTxOut(txscript.PayToPubKeyLockTimeScript(pubKeys[0], 1000)), templateTxOut(txscript.PayToPubKeySequenceScript(pubKeys[0], 10))}},
+			height:     300000,
+			isStandard: true,
+		},
+		{
+			name:       "HTLC output",
+			tx:         &externalapi.DomainTransaction{Version: 0, Inputs: []*externalapi.DomainTransactionInput{&dummyTxIn}, Outputs: []*externalapi.DomainTransactionOutput{templateTxOut(txscript.HTLCScript(pubKeys[0], pubKeys[1], secretHash, 1000))}},
+			height:     300000,
+			isStandard: true,
+		},
 		{ //Todo : check on ScriptPublicKey type.
 			name: "Dust output",
 			tx: &externalapi.DomainTransaction{Version: 0, Inputs: []*externalapi.DomainTransactionInput{&dummyTxIn}, Outputs: []*externalapi.DomainTransactionOutput{{
@@ -350,3 +384,43 @@ func TestCheckTransactionStandardInIsolation(t *testing.T) {
 		}
 	})
 }
+
+func TestCheckTemplateSignatureScriptLength(t *testing.T) {
+	pubKey := bytes.Repeat([]byte{0x01}, 32)
+	multiSigScript, err := txscript.MultiSigScript([][]byte{pubKey, pubKey, pubKey}, 2, false)
+	if err != nil {
+		t.Fatalf("MultiSigScript: %v", err)
+	}
+	lockTimeScript, err := txscript.PayToPubKeyLockTimeScript(pubKey, 1000)
+	if err != nil {
+		t.Fatalf("PayToPubKeyLockTimeScript: %v", err)
+	}
+	htlcScript, err := txscript.HTLCScript(pubKey, pubKey, bytes.Repeat([]byte{0x02}, 32), 1000)
+	if err != nil {
+		t.Fatalf("HTLCScript: %v", err)
+	}
+
+	tests := []struct {
+		name                  string
+		script                []byte
+		signatureScriptLength int
+		isStandard            bool
+	}{
+		{"multisig with 2 signatures", multiSigScript, 2 * 66, true},
+		{"padded multisig", multiSigScript, 2*66 + 1, false},
+		{"timelocked pay-to-pubkey", lockTimeScript, 66, true},
+		{"padded timelocked pay-to-pubkey", lockTimeScript, 67, false},
+		{"HTLC redeem", htlcScript, 66 + 33 + 1, true},
+		{"padded HTLC", htlcScript, 66 + 33 + 2, false},
+		{"not a template", []byte{txscript.OpTrue}, 1000, true},
+	}
+	for _, test := range tests {
+		err := checkTemplateSignatureScriptLength(0, test.script, test.signatureScriptLength)
+		if test.isStandard && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if !test.isStandard && err == nil {
+			t.Errorf("%s: expected a nonstandard error", test.name)
+		}
+	}
+}
@@ -29,10 +29,10 @@ const (
 
 	// Standard transaction version range might be different from what consensus accepts, therefore
 	// we define separate values in mempool.
-	// However, currently there's exactly one transaction version, so mempool accepts the same version
-	// as consensus.
+	// However, currently mempool accepts the same versions as consensus. Covenants transactions are
+	// rejected by consensus until they're activated.
 	defaultMinimumStandardTransactionVersion = constants.MaxTransactionVersion
-	defaultMaximumStandardTransactionVersion = constants.MaxTransactionVersion
+	defaultMaximumStandardTransactionVersion = constants.CovenantsTransactionVersion
 )
 
 // Config represents a mempool configuration
@@ -130,6 +130,22 @@ func (mp *mempool) TransactionCount(includeTransactionPool bool, includeOrphanPo
 	return transactionCount
 }
 
+func (mp *mempool) TransactionsMass(includeTransactionPool bool, includeOrphanPool bool) uint64 {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	transactionsMass := uint64(0)
+
+	if includeOrphanPool {
+		transactionsMass += mp.orphansPool.orphanTransactionsMass()
+	}
+	if includeTransactionPool {
+		transactionsMass += mp.transactionsPool.transactionsMass()
+	}
+
+	return transactionsMass
+}
+
 func (mp *mempool) HandleNewBlockTransactions(transactions []*externalapi.DomainTransaction) (
 	acceptedOrphans []*externalapi.DomainTransaction, err error) {
 
@@ -250,3 +250,11 @@ func (tp *transactionsPool) getAllTransactions() []*externalapi.DomainTransactio
 func (tp *transactionsPool) transactionCount() int {
 	return len(tp.allTransactions)
 }
+
+func (tp *transactionsPool) transactionsMass() uint64 {
+	mass := uint64(0)
+	for _, mempoolTransaction := range tp.allTransactions {
+		mass += mempoolTransaction.Transaction().Mass
+	}
+	return mass
+}
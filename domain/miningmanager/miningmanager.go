@@ -29,6 +29,7 @@ type MiningManager interface {
 		transactionPoolTransactions []*externalapi.DomainTransaction,
 		orphanPoolTransactions []*externalapi.DomainTransaction)
 	TransactionCount(includeTransactionPool bool, includeOrphanPool bool) int
+	TransactionsMass(includeTransactionPool bool, includeOrphanPool bool) uint64
 	HandleNewBlockTransactions(txs []*externalapi.DomainTransaction) ([]*externalapi.DomainTransaction, error)
 	ValidateAndInsertTransaction(transaction *externalapi.DomainTransaction, isHighPriority bool, allowOrphan bool) (
 		acceptedTransactions []*externalapi.DomainTransaction, err error)
@@ -149,6 +150,10 @@ func (mm *miningManager) TransactionCount(includeTransactionPool bool, includeOr
 	return mm.mempool.TransactionCount(includeTransactionPool, includeOrphanPool)
 }
 
+func (mm *miningManager) TransactionsMass(includeTransactionPool bool, includeOrphanPool bool) uint64 {
+	return mm.mempool.TransactionsMass(includeTransactionPool, includeOrphanPool)
+}
+
 func (mm *miningManager) RevalidateHighPriorityTransactions() (
 	validTransactions []*externalapi.DomainTransaction, err error) {
 
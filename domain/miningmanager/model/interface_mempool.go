@@ -37,6 +37,9 @@ type Mempool interface {
 	TransactionCount(
 		includeTransactionPool bool,
 		includeOrphanPool bool) int
+	TransactionsMass(
+		includeTransactionPool bool,
+		includeOrphanPool bool) uint64
 	RevalidateHighPriorityTransactions() (validTransactions []*externalapi.DomainTransaction, err error)
 	IsTransactionOutputDust(output *externalapi.DomainTransactionOutput) bool
 }
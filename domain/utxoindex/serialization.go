@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"github.com/Kash-Protocol/kashd/domain/consensus/database/serialization"
 	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
 	"github.com/pkg/errors"
 	"google.golang.org/protobuf/proto"
 	"io"
@@ -23,18 +24,48 @@ func deserializeOutpoint(serializedOutpoint []byte) (*externalapi.DomainOutpoint
 	return serialization.DbOutpointToDomainOutpoint(&dbOutpoint)
 }
 
+// serializeUTXOEntry serializes the given UTXO entry without its script public key,
+// which is already part of the key of the entry in the UTXO index
 func serializeUTXOEntry(utxoEntry externalapi.UTXOEntry) ([]byte, error) {
 	dbUTXOEntry := serialization.UTXOEntryToDBUTXOEntry(utxoEntry)
+	dbUTXOEntry.ScriptPublicKey = nil
 	return proto.Marshal(dbUTXOEntry)
 }
 
-func deserializeUTXOEntry(serializedUTXOEntry []byte) (externalapi.UTXOEntry, error) {
+func deserializeUTXOEntry(serializedUTXOEntry []byte,
+	scriptPublicKey *externalapi.ScriptPublicKey) (externalapi.UTXOEntry, error) {
+
 	var dbUTXOEntry serialization.DbUtxoEntry
 	err := proto.Unmarshal(serializedUTXOEntry, &dbUTXOEntry)
 	if err != nil {
 		return nil, err
 	}
-	return serialization.DBUTXOEntryToUTXOEntry(&dbUTXOEntry)
+	return utxo.NewUTXOEntry(dbUTXOEntry.Amount, scriptPublicKey, dbUTXOEntry.IsCoinbase, dbUTXOEntry.BlockDaaScore), nil
+}
+
+func deserializeUTXOEntryAmount(serializedUTXOEntry []byte) (uint64, error) {
+	var dbUTXOEntry serialization.DbUtxoEntry
+	err := proto.Unmarshal(serializedUTXOEntry, &dbUTXOEntry)
+	if err != nil {
+		return 0, err
+	}
+	return dbUTXOEntry.Amount, nil
+}
+
+// RemoveScriptPublicKeyFromSerializedUTXOEntry re-serializes an entry of the UTXO index
+// without its script public key. Up to database version 1, the UTXO index stored the script
+// public key of every entry, even though it's already part of its key.
+func RemoveScriptPublicKeyFromSerializedUTXOEntry(serializedUTXOEntry []byte) ([]byte, error) {
+	var dbUTXOEntry serialization.DbUtxoEntry
+	err := proto.Unmarshal(serializedUTXOEntry, &dbUTXOEntry)
+	if err != nil {
+		return nil, err
+	}
+	if dbUTXOEntry.ScriptPublicKey == nil {
+		return serializedUTXOEntry, nil
+	}
+	dbUTXOEntry.ScriptPublicKey = nil
+	return proto.Marshal(&dbUTXOEntry)
 }
 
 const hashesLengthSize = 8
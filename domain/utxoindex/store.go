@@ -11,6 +11,11 @@ import (
 )
 
 var utxoIndexBucket = database.MakeBucket([]byte("utxo-index"))
+
+// EntriesBucket returns the database bucket that holds the entries of the UTXO index
+func EntriesBucket() *database.Bucket {
+	return utxoIndexBucket
+}
 var virtualParentsKey = database.MakeBucket([]byte("")).Key([]byte("utxo-index-virtual-parents"))
 var circulatingSupplyKey = database.MakeBucket([]byte("")).Key([]byte("utxo-index-circulating-supply"))
 
@@ -288,7 +293,7 @@ func (uis *utxoIndexStore) getUTXOOutpointEntryPairs(scriptPublicKey *externalap
 		if err != nil {
 			return nil, err
 		}
-		utxoEntry, err := deserializeUTXOEntry(serializedUTXOEntry)
+		utxoEntry, err := deserializeUTXOEntry(serializedUTXOEntry, scriptPublicKey)
 		if err != nil {
 			return nil, err
 		}
@@ -357,12 +362,12 @@ func (uis *utxoIndexStore) initializeCirculatingSompiSupply() error {
 		if err != nil {
 			return err
 		}
-		utxoEntry, err := deserializeUTXOEntry(serializedUTXOEntry)
+		amount, err := deserializeUTXOEntryAmount(serializedUTXOEntry)
 		if err != nil {
 			return err
 		}
 
-		circulatingSompiSupplyInDatabase = circulatingSompiSupplyInDatabase + utxoEntry.Amount()
+		circulatingSompiSupplyInDatabase = circulatingSompiSupplyInDatabase + amount
 	}
 
 	err = uis.database.Put(
@@ -6,6 +6,7 @@ import (
 	"github.com/Kash-Protocol/kashd/infrastructure/db/database"
 	"github.com/Kash-Protocol/kashd/infrastructure/logger"
 	"sync"
+	"sync/atomic"
 )
 
 // UTXOIndex maintains an index between transaction scriptPublicKeys
@@ -14,6 +15,9 @@ type UTXOIndex struct {
 	domain domain.Domain
 	store  *utxoIndexStore
 
+	// virtualDAAScore is the DAA score of the virtual the index was last updated to
+	virtualDAAScore uint64
+
 	mutex sync.Mutex
 }
 
@@ -42,6 +46,12 @@ func New(domain domain.Domain, database database.Database) (*UTXOIndex, error) {
 		if err != nil {
 			return nil, err
 		}
+	} else {
+		// The index is synced with the virtual, so it's at the DAA score of the virtual
+		utxoIndex.virtualDAAScore, err = domain.Consensus().GetVirtualDAAScore()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return utxoIndex, nil
@@ -88,7 +98,13 @@ func (ui *UTXOIndex) Reset() error {
 	}
 
 	// This has to be done last to mark that the reset went smoothly and no reset has to be called next time.
-	return ui.store.updateAndCommitVirtualParentsWithoutTransaction(virtualInfo.ParentHashes)
+	err = ui.store.updateAndCommitVirtualParentsWithoutTransaction(virtualInfo.ParentHashes)
+	if err != nil {
+		return err
+	}
+
+	atomic.StoreUint64(&ui.virtualDAAScore, virtualInfo.DAAScore)
+	return nil
 }
 
 func (ui *UTXOIndex) isSynced() (bool, error) {
@@ -139,11 +155,18 @@ func (ui *UTXOIndex) Update(virtualChangeSet *externalapi.VirtualChangeSet) (*UT
 	if err != nil {
 		return nil, err
 	}
+	atomic.StoreUint64(&ui.virtualDAAScore, virtualChangeSet.VirtualDAAScore)
 
 	log.Tracef("UTXO index updated with the UTXOChanged: %+v", utxoIndexChanges)
 	return utxoIndexChanges, nil
 }
 
+// VirtualDAAScore returns the DAA score of the virtual the UTXO index was last updated to. The index is
+// updated after consensus, so it's behind the virtual of consensus while it processes its changes.
+func (ui *UTXOIndex) VirtualDAAScore() uint64 {
+	return atomic.LoadUint64(&ui.virtualDAAScore)
+}
+
 func (ui *UTXOIndex) addUTXOs(toAdd externalapi.UTXOCollection) error {
 	iterator := toAdd.Iterator()
 	defer iterator.Close()
@@ -5,8 +5,10 @@
 package config
 
 import (
+	"crypto/ed25519"
 	// _ "embed" is necessary for the go:embed feature.
 	_ "embed"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"os"
@@ -30,9 +32,12 @@ import (
 const (
 	defaultConfigFilename      = "kashd.conf"
 	defaultLogLevel            = "info"
+	defaultLogFormat           = "text"
 	defaultLogDirname          = "logs"
 	defaultLogFilename         = "kashd.log"
 	defaultErrLogFilename      = "kashd_err.log"
+	syslogTag                  = "kashd"
+	defaultPeerKeyFilename     = "peer.key"
 	defaultTargetOutboundPeers = 8
 	defaultMaxInboundPeers     = 117
 	defaultBanDuration         = time.Hour * 24
@@ -54,6 +59,36 @@ const (
 	sampleConfigFilename    = "sample-kashd.conf"
 	defaultMaxUTXOCacheSize = 5_000_000_000
 	defaultProtocolVersion  = 5
+	defaultDbType           = "leveldb"
+	defaultMetricsPort      = "9110"
+)
+
+// The supported values of the --p2pencryption option
+const (
+	// P2PEncryptionOpportunistic encrypts connections with every peer that
+	// supports it, and falls back to plaintext for legacy peers
+	P2PEncryptionOpportunistic = "opportunistic"
+
+	// P2PEncryptionRequired refuses to communicate with peers in plaintext
+	P2PEncryptionRequired = "required"
+
+	// P2PEncryptionDisabled always communicates with peers in plaintext
+	P2PEncryptionDisabled = "disabled"
+)
+
+// The supported values of the --onlynet option
+const (
+	// NetworkIPv4 is the IPv4 network
+	NetworkIPv4 = "ipv4"
+
+	// NetworkIPv6 is the IPv6 network
+	NetworkIPv6 = "ipv6"
+
+	// NetworkOnion is the Tor network, reachable through --onion or --proxy
+	NetworkOnion = "onion"
+
+	// NetworkI2P is the I2P network, reachable through --i2pproxy
+	NetworkI2P = "i2p"
 )
 
 var (
@@ -82,7 +117,7 @@ type Flags struct {
 	AppDir                          string        `short:"b" long:"appdir" description:"Directory to store data"`
 	LogDir                          string        `long:"logdir" description:"Directory to log output."`
 	AddPeers                        []string      `short:"a" long:"addpeer" description:"Add a peer to connect with at startup"`
-	ConnectPeers                    []string      `long:"connect" description:"Connect only to the specified peers at startup"`
+	ConnectPeers                    []string      `long:"connect" description:"Connect only to the specified peers at startup -- Use <address>@<identity key> to require the peer to authenticate with the given hex-encoded identity key"`
 	DisableListen                   bool          `long:"nolisten" description:"Disable listening for incoming connections -- NOTE: Listening is automatically disabled if the --connect or --proxy options are used without also specifying listen interfaces via --listen"`
 	Listeners                       []string      `long:"listen" description:"Add an interface/port to listen for connections (default all interfaces port: 16111, testnet: 16211)"`
 	TargetOutboundPeers             int           `long:"outpeers" description:"Target number of outbound peers"`
@@ -106,9 +141,20 @@ type Flags struct {
 	Proxy                           string        `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
 	ProxyUser                       string        `long:"proxyuser" description:"Username for proxy server"`
 	ProxyPass                       string        `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
-	DbType                          string        `long:"dbtype" description:"Database backend to use for the Block DAG"`
+	OnionProxy                      string        `long:"onion" description:"Connect to Tor onion services via SOCKS5 proxy (eg. 127.0.0.1:9050) -- Defaults to --proxy"`
+	OnionProxyUser                  string        `long:"onionuser" description:"Username for onion proxy server"`
+	OnionProxyPass                  string        `long:"onionpass" default-mask:"-" description:"Password for onion proxy server"`
+	NoOnion                         bool          `long:"noonion" description:"Disable connecting to Tor onion services"`
+	I2PProxy                        string        `long:"i2pproxy" description:"Connect to I2P peers via SOCKS5 proxy (eg. 127.0.0.1:4447)"`
+	OnlyNets                        []string      `long:"onlynet" description:"Make outgoing connections only to peers in the given network {ipv4, ipv6, onion, i2p} -- May be specified multiple times"`
+	P2PEncryption                   string        `long:"p2pencryption" description:"Encryption of P2P connections {opportunistic, required, disabled}"`
+	P2PIdentityKeyFile              string        `long:"p2pidentitykey" description:"File containing the persistent identity key this node authenticates itself with to its peers (default: <appdir>/peer.key)"`
+	DbType                          string        `long:"dbtype" description:"Database backend to use for the Block DAG {leveldb, bbolt, memory} -- memory keeps nothing on disk and is meant for testing"`
 	Profile                         string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+	MetricsListen                   string        `long:"metricslisten" description:"Serve Prometheus metrics at /metrics on the given interface/port, e.g. 127.0.0.1:9110 (default port: 9110, disabled by default) -- NOTE the metrics aren't authenticated, so don't expose them publicly"`
 	LogLevel                        string        `short:"d" long:"loglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
+	LogFormat                       string        `long:"logformat" description:"Format of the log output {text, json} -- json writes every message as an object with the time, level, subsystem and message, and the block hash and peer ID when relevant"`
+	Syslog                          bool          `long:"syslog" description:"Also send the log output to the local syslog daemon, or to journald on systemd-based systems (not supported on Windows)"`
 	Upnp                            bool          `long:"upnp" description:"Use UPnP to map our listening port outside of NAT"`
 	MinRelayTxFee                   float64       `long:"minrelaytxfee" description:"The minimum transaction fee in KSH/kB to be considered a non-zero fee."`
 	MaxOrphanTxs                    uint64        `long:"maxorphantx" description:"Max number of orphan transactions to keep in memory"`
@@ -120,7 +166,12 @@ type Flags struct {
 	RelayNonStd                     bool          `long:"relaynonstd" description:"Relay non-standard transactions regardless of the default settings for the active network."`
 	RejectNonStd                    bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
 	ResetDatabase                   bool          `long:"reset-db" description:"Reset database before starting node. It's needed when switching between subnetworks."`
+	ExportSnapshot                  string        `long:"export-snapshot" description:"Write a snapshot of the pruning point, including its UTXO set, to the given file and exit"`
+	ImportSnapshot                  string        `long:"import-snapshot" description:"Bootstrap the node from a pruning point snapshot file created with --export-snapshot. A snapshot the node was already bootstrapped from is skipped"`
+	DryRunMigrations                bool          `long:"dry-run-migrations" description:"Report the changes the pending database migrations would make, without making them, and exit"`
+	NoDBBackup                      bool          `long:"no-db-backup" description:"Migrate the database without backing it up first. A failed migration can then only be recovered from by resyncing"`
 	MaxUTXOCacheSize                uint64        `long:"maxutxocachesize" description:"Max size of loaded UTXO into ram from the disk in bytes"`
+	RandomXVMs                      int           `long:"randomxvms" description:"Number of RandomX VMs used to verify proof of work in parallel. The VMs share one 256 MiB RandomX cache, and each of them adds a 2 MiB scratchpad (default: the number of CPUs)"`
 	UTXOIndex                       bool          `long:"utxoindex" description:"Enable the UTXO index"`
 	IsArchivalNode                  bool          `long:"archival" description:"Run as an archival node: don't delete old block data when moving the pruning point (Warning: heavy disk usage)'"`
 	AllowSubmitBlockWhenNotSynced   bool          `long:"allow-submit-block-when-not-synced" hidden:"true" description:"Allow the node to accept blocks from RPC while not synced (this flag is mainly used for testing)"`
@@ -137,10 +188,16 @@ type Config struct {
 	*Flags
 	Lookup        func(string) ([]net.IP, error)
 	Dial          func(string, string, time.Duration) (net.Conn, error)
+	OnionDial     func(string, string, time.Duration) (net.Conn, error) // nil when onion services are unreachable
+	I2PDial       func(string, string, time.Duration) (net.Conn, error) // nil when I2P is unreachable
 	MiningAddrs   []util.Address
 	MinRelayTxFee util.Amount
 	Whitelists    []*net.IPNet
 	SubnetworkID  *externalapi.DomainSubnetworkID // nil in full nodes
+
+	// PinnedPeerKeys maps the addresses of --connect peers to the
+	// identity keys they are required to authenticate with
+	PinnedPeerKeys map[string][]byte
 }
 
 // ServiceOptions defines the configuration options for the daemon as a service on
@@ -176,6 +233,7 @@ func defaultFlags() *Flags {
 	return &Flags{
 		ConfigFile:           defaultConfigFile,
 		LogLevel:             defaultLogLevel,
+		LogFormat:            defaultLogFormat,
 		TargetOutboundPeers:  defaultTargetOutboundPeers,
 		MaxInboundPeers:      defaultMaxInboundPeers,
 		BanDuration:          defaultBanDuration,
@@ -193,6 +251,8 @@ func defaultFlags() *Flags {
 		MaxUTXOCacheSize:     defaultMaxUTXOCacheSize,
 		ServiceOptions:       &ServiceOptions{},
 		ProtocolVersion:      defaultProtocolVersion,
+		P2PEncryption:        P2PEncryptionOpportunistic,
+		DbType:               defaultDbType,
 	}
 }
 
@@ -328,6 +388,12 @@ func LoadConfig() (*Config, error) {
 	// worry about changing names per network and such.
 	cfg.AppDir = filepath.Join(cfg.AppDir, cfg.NetParams().Name)
 
+	// The P2P identity key is specific to the network as well
+	if cfg.P2PIdentityKeyFile == "" {
+		cfg.P2PIdentityKeyFile = filepath.Join(cfg.AppDir, defaultPeerKeyFilename)
+	}
+	cfg.P2PIdentityKeyFile = cleanAndExpandPath(cfg.P2PIdentityKeyFile)
+
 	// Logs directory is usually under the home directory, unless otherwise specified
 	if cfg.LogDir == "" {
 		cfg.LogDir = filepath.Join(cfg.AppDir, defaultLogDirname)
@@ -340,6 +406,29 @@ func LoadConfig() (*Config, error) {
 		os.Exit(0)
 	}
 
+	logFormat, ok := logger.FormatFromString(cfg.LogFormat)
+	if !ok {
+		str := "%s: The specified log format [%s] is invalid -- supported formats are text and json"
+		err := errors.Errorf(str, funcName, cfg.LogFormat)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+	err = logger.BackendLog.SetFormat(logFormat)
+	if err != nil {
+		err := errors.Errorf("%s: %s", funcName, err.Error())
+		fmt.Fprintln(os.Stderr, err)
+		return nil, err
+	}
+	if cfg.Syslog {
+		err = logger.BackendLog.AddSyslog(syslogTag, logger.LevelInfo)
+		if err != nil {
+			err := errors.Errorf("%s: %s", funcName, err.Error())
+			fmt.Fprintln(os.Stderr, err)
+			return nil, err
+		}
+	}
+
 	// Initialize log rotation. After log rotation has been initialized, the
 	// logger variables may be used.
 	logger.InitLog(filepath.Join(cfg.LogDir, defaultLogFilename), filepath.Join(cfg.LogDir, defaultErrLogFilename))
@@ -518,6 +607,18 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	// Add the default port to the metrics listener address if needed.
+	if cfg.MetricsListen != "" {
+		cfg.MetricsListen, err = network.NormalizeAddress(cfg.MetricsListen, defaultMetricsPort)
+		if err != nil {
+			str := "%s: invalid --metricslisten address: %s"
+			err := errors.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+	}
+
 	// Disallow --addpeer and --connect used together
 	if len(cfg.AddPeers) > 0 && len(cfg.ConnectPeers) > 0 {
 		str := "%s: --addpeer and --connect can not be used together"
@@ -527,6 +628,33 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	// Disallow --export-snapshot and --import-snapshot used together
+	if cfg.ExportSnapshot != "" && cfg.ImportSnapshot != "" {
+		str := "%s: --export-snapshot and --import-snapshot can not be used together"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+	// Default --randomxvms to one RandomX VM per CPU
+	if cfg.RandomXVMs < 0 {
+		str := "%s: --randomxvms must not be negative"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+	if cfg.RandomXVMs == 0 {
+		cfg.RandomXVMs = runtime.NumCPU()
+	}
+
+	if cfg.ExportSnapshot != "" {
+		cfg.ExportSnapshot = cleanAndExpandPath(cfg.ExportSnapshot)
+	}
+	if cfg.ImportSnapshot != "" {
+		cfg.ImportSnapshot = cleanAndExpandPath(cfg.ImportSnapshot)
+	}
+
 	// Add default port to all added peer addresses if needed and remove
 	// duplicate addresses.
 	cfg.AddPeers, err = network.NormalizeAddresses(cfg.AddPeers,
@@ -535,12 +663,41 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	cfg.ConnectPeers, cfg.PinnedPeerKeys, err = parsePeerKeyPins(cfg.ConnectPeers,
+		cfg.NetParams().DefaultPort)
+	if err != nil {
+		str := "%s: %s"
+		err := errors.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
 	cfg.ConnectPeers, err = network.NormalizeAddresses(cfg.ConnectPeers,
 		cfg.NetParams().DefaultPort)
 	if err != nil {
 		return nil, err
 	}
 
+	// Validate the P2P encryption mode.
+	switch cfg.P2PEncryption {
+	case P2PEncryptionOpportunistic, P2PEncryptionRequired:
+	case P2PEncryptionDisabled:
+		if len(cfg.PinnedPeerKeys) > 0 {
+			str := "%s: peer identity keys can not be pinned when P2P encryption is disabled"
+			err := errors.Errorf(str, funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+	default:
+		str := "%s: The p2pencryption option must be one of {%s, %s, %s} -- parsed [%s]"
+		err := errors.Errorf(str, funcName, P2PEncryptionOpportunistic,
+			P2PEncryptionRequired, P2PEncryptionDisabled, cfg.P2PEncryption)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
 	// Setup dial and DNS resolution (lookup) functions depending on the
 	// specified options. The default is to use the standard
 	// net.DialTimeout function as well as the system DNS resolver. When a
@@ -566,6 +723,69 @@ func LoadConfig() (*Config, error) {
 		cfg.Dial = proxy.DialTimeout
 	}
 
+	// Setup the dial functions of overlay networks. Onion services are reached
+	// through --onion if specified, or through --proxy otherwise. Overlay networks
+	// can't be reached directly, so their dial functions are left nil when no
+	// proxy had been specified for them.
+	onionProxy, onionProxyUser, onionProxyPass := cfg.OnionProxy, cfg.OnionProxyUser, cfg.OnionProxyPass
+	if onionProxy == "" {
+		onionProxy, onionProxyUser, onionProxyPass = cfg.Proxy, cfg.ProxyUser, cfg.ProxyPass
+	}
+	if onionProxy != "" && !cfg.NoOnion {
+		_, _, err := net.SplitHostPort(onionProxy)
+		if err != nil {
+			str := "%s: Onion proxy address '%s' is invalid: %s"
+			err := errors.Errorf(str, funcName, onionProxy, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+
+		proxy := &socks.Proxy{
+			Addr:     onionProxy,
+			Username: onionProxyUser,
+			Password: onionProxyPass,
+		}
+		cfg.OnionDial = proxy.DialTimeout
+	}
+	if cfg.I2PProxy != "" {
+		_, _, err := net.SplitHostPort(cfg.I2PProxy)
+		if err != nil {
+			str := "%s: I2P proxy address '%s' is invalid: %s"
+			err := errors.Errorf(str, funcName, cfg.I2PProxy, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+
+		proxy := &socks.Proxy{Addr: cfg.I2PProxy}
+		cfg.I2PDial = proxy.DialTimeout
+	}
+
+	// Validate --onlynet, making sure that every specified network is reachable.
+	for _, onlyNet := range cfg.OnlyNets {
+		var str string
+		switch onlyNet {
+		case NetworkIPv4, NetworkIPv6:
+		case NetworkOnion:
+			if cfg.OnionDial == nil {
+				str = "%s: --onlynet=%s requires either the --onion or the --proxy option"
+			}
+		case NetworkI2P:
+			if cfg.I2PDial == nil {
+				str = "%s: --onlynet=%s requires the --i2pproxy option"
+			}
+		default:
+			str = "%s: The onlynet option must be one of {ipv4, ipv6, onion, i2p} -- parsed [%s]"
+		}
+		if str != "" {
+			err := errors.Errorf(str, funcName, onlyNet)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+	}
+
 	// Warn about missing config file only after all other configuration is
 	// done. This prevents the warning on help messages and invalid
 	// options. Note this should go directly before the return.
@@ -575,6 +795,37 @@ func LoadConfig() (*Config, error) {
 	return cfg, nil
 }
 
+// parsePeerKeyPins splits the optional identity key pin off every
+// <address>@<identity key> entry in the given peers. It returns the
+// peers without their pins, along with a map from every pinned peer's
+// normalized address to its identity key.
+func parsePeerKeyPins(peers []string, defaultPort string) ([]string, map[string][]byte, error) {
+	addresses := make([]string, 0, len(peers))
+	pinnedKeys := make(map[string][]byte)
+	for _, peer := range peers {
+		separatorIndex := strings.LastIndex(peer, "@")
+		if separatorIndex == -1 {
+			addresses = append(addresses, peer)
+			continue
+		}
+
+		address, keyString := peer[:separatorIndex], peer[separatorIndex+1:]
+		key, err := hex.DecodeString(keyString)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, nil, errors.Errorf("the identity key of peer '%s' must be %d hex-encoded bytes",
+				address, ed25519.PublicKeySize)
+		}
+		normalizedAddress, err := network.NormalizeAddress(address, defaultPort)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		addresses = append(addresses, address)
+		pinnedKeys[normalizedAddress] = key
+	}
+	return addresses, pinnedKeys, nil
+}
+
 // createDefaultConfig copies the file sample-kashd.conf to the given destination path,
 // and populates it with some randomly generated RPC username and password.
 func createDefaultConfigFile(destinationPath string) error {
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// OpenFunc opens the database stored in the given path, creating it if
+// it doesn't exist. Backends that don't store their data on disk ignore
+// both the path and the cache size.
+type OpenFunc func(path string, cacheSizeMiB int) (Database, error)
+
+var (
+	backends     = make(map[string]OpenFunc)
+	backendsLock sync.RWMutex
+)
+
+// RegisterBackend makes a database backend available under the given
+// name, which is what the --dbtype option refers to. It is meant to be
+// called from the init function of the package implementing the backend.
+// Panics if a backend with the same name had already been registered.
+func RegisterBackend(dbType string, open OpenFunc) {
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+
+	if _, ok := backends[dbType]; ok {
+		panic(errors.Errorf("database backend %s is already registered", dbType))
+	}
+	backends[dbType] = open
+}
+
+// Open opens the database stored in the given path using the backend
+// registered under dbType
+func Open(dbType string, path string, cacheSizeMiB int) (Database, error) {
+	backendsLock.RLock()
+	open, ok := backends[dbType]
+	backendsLock.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("unknown database type %s. Supported types are {%s}",
+			dbType, strings.Join(Backends(), ", "))
+	}
+	return open(path, cacheSizeMiB)
+}
+
+// Backends returns the names of all the registered database backends,
+// in alphabetical order
+func Backends() []string {
+	backendsLock.RLock()
+	defer backendsLock.RUnlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
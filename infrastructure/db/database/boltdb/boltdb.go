@@ -0,0 +1,165 @@
+package boltdb
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// DBType is the name this backend is registered under
+const DBType = "bbolt"
+
+func init() {
+	database.RegisterBackend(DBType, func(path string, _ int) (database.Database, error) {
+		return NewBoltDB(path)
+	})
+}
+
+const (
+	// dataFileName is the name of the file within the database directory
+	// that bbolt stores all of its data in
+	dataFileName = "data.bolt"
+
+	// compactedFileName is the name of the temporary file Compact writes
+	// the compacted database into
+	compactedFileName = "data.bolt.compacted"
+
+	// compactTxMaxSize is the maximum amount of bytes written in a single
+	// transaction while compacting
+	compactTxMaxSize = 64 * 1024 * 1024
+)
+
+// rootBucketName is the name of the single bolt bucket in which all data
+// is stored. Kashd buckets are merely key prefixes within it, the same way
+// they are in leveldb, so that cursors behave identically in both backends.
+var rootBucketName = []byte("kashd")
+
+// BoltDB defines a thin wrapper around bbolt, a B+tree based
+// on-disk key/value store.
+type BoltDB struct {
+	bolt *bolt.DB
+	path string
+}
+
+// NewBoltDB opens a bbolt instance within the directory defined by the given path.
+func NewBoltDB(path string) (*BoltDB, error) {
+	err := os.MkdirAll(path, 0700)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	db := &BoltDB{path: path}
+	err = db.open()
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (db *BoltDB) open() error {
+	boltDB, err := bolt.Open(filepath.Join(db.path, dataFileName), 0600, Options())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = boltDB.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(rootBucketName)
+		return err
+	})
+	if err != nil {
+		boltDB.Close()
+		return errors.WithStack(err)
+	}
+	db.bolt = boltDB
+	return nil
+}
+
+// Compact compacts the bbolt instance. bbolt never shrinks its data file
+// on its own, so compaction copies all the data into a new file and
+// replaces the old one with it.
+func (db *BoltDB) Compact() error {
+	compactedPath := filepath.Join(db.path, compactedFileName)
+	compactedDB, err := bolt.Open(compactedPath, 0600, Options())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = bolt.Compact(compactedDB, db.bolt, compactTxMaxSize)
+	if err != nil {
+		compactedDB.Close()
+		os.Remove(compactedPath)
+		return errors.WithStack(err)
+	}
+	err = compactedDB.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = db.bolt.Close()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = os.Rename(compactedPath, filepath.Join(db.path, dataFileName))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	log.Debugf("Compacted the bbolt database in %s", db.path)
+	return db.open()
+}
+
+// Close closes the bbolt instance.
+func (db *BoltDB) Close() error {
+	err := db.bolt.Close()
+	return errors.WithStack(err)
+}
+
+// Put sets the value for the given key. It overwrites
+// any previous value for that key.
+func (db *BoltDB) Put(key *database.Key, value []byte) error {
+	err := db.bolt.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(rootBucketName).Put(key.Bytes(), value)
+	})
+	return errors.WithStack(err)
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (db *BoltDB) Get(key *database.Key) ([]byte, error) {
+	var data []byte
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(rootBucketName).Get(key.Bytes())
+		if value == nil {
+			return errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+		}
+		// Values returned by bbolt are only valid throughout the transaction
+		data = append([]byte{}, value...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Has returns true if the database does contains the
+// given key.
+func (db *BoltDB) Has(key *database.Key) (bool, error) {
+	var exists bool
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		exists = tx.Bucket(rootBucketName).Get(key.Bytes()) != nil
+		return nil
+	})
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return exists, nil
+}
+
+// Delete deletes the value for the given key. Will not
+// return an error if the key doesn't exist.
+func (db *BoltDB) Delete(key *database.Key) error {
+	err := db.bolt.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(rootBucketName).Delete(key.Bytes())
+	})
+	return errors.WithStack(err)
+}
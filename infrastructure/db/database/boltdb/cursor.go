@@ -0,0 +1,186 @@
+package boltdb
+
+import (
+	"bytes"
+
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// cursorPageSize is the maximum number of entries a cursor reads
+// within a single bbolt read transaction
+const cursorPageSize = 1000
+
+// BoltDBCursor iterates over a bucket in pages. Every page is read within
+// its own short bbolt read transaction, since a long-lived read transaction
+// would block bbolt from growing its memory map on writes. As a result,
+// writes that happen while the cursor is open may be visible in pages that
+// had not yet been read.
+type BoltDBCursor struct {
+	db     *BoltDB
+	bucket *database.Bucket
+
+	page        []cursorEntry
+	index       int
+	isExhausted bool
+	isStarted   bool
+
+	isClosed bool
+}
+
+type cursorEntry struct {
+	key   []byte
+	value []byte
+}
+
+// Cursor begins a new cursor over the given bucket.
+func (db *BoltDB) Cursor(bucket *database.Bucket) (database.Cursor, error) {
+	return &BoltDBCursor{
+		db:     db,
+		bucket: bucket,
+	}, nil
+}
+
+// seek reads the page starting at the first entry in the cursor's bucket
+// whose key is greater than or equal to the given key, and moves the cursor
+// to its first entry.
+func (c *BoltDBCursor) seek(key []byte) error {
+	c.isStarted = true
+	c.page = c.page[:0]
+	c.index = 0
+
+	prefix := c.bucket.Path()
+	err := c.db.bolt.View(func(tx *bolt.Tx) error {
+		boltCursor := tx.Bucket(rootBucketName).Cursor()
+		for k, v := boltCursor.Seek(key); k != nil && bytes.HasPrefix(k, prefix); k, v = boltCursor.Next() {
+			// Keys and values returned by bbolt are only valid throughout the transaction
+			c.page = append(c.page, cursorEntry{
+				key:   append([]byte{}, k...),
+				value: append([]byte{}, v...),
+			})
+			if len(c.page) == cursorPageSize {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.isExhausted = len(c.page) == 0
+	return nil
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted. Panics if the cursor is closed.
+func (c *BoltDBCursor) Next() bool {
+	if c.isClosed {
+		panic("cannot call next on a closed cursor")
+	}
+
+	if !c.isStarted {
+		return c.mustSeek(c.bucket.Path())
+	}
+	if c.isExhausted {
+		return false
+	}
+
+	c.index++
+	if c.index < len(c.page) {
+		return true
+	}
+	if len(c.page) < cursorPageSize {
+		c.isExhausted = true
+		return false
+	}
+
+	// The smallest key that is greater than the last key in
+	// the page is that key followed by a zero byte
+	lastKey := c.page[len(c.page)-1].key
+	nextKey := make([]byte, len(lastKey)+1)
+	copy(nextKey, lastKey)
+	return c.mustSeek(nextKey)
+}
+
+// mustSeek seeks to the given key and returns whether the cursor points to
+// an entry. The database.Cursor interface does not allow Next and First to
+// return errors, so a failure to read from the database panics, the same way
+// it does for a closed cursor.
+func (c *BoltDBCursor) mustSeek(key []byte) bool {
+	err := c.seek(key)
+	if err != nil {
+		panic(err)
+	}
+	return !c.isExhausted
+}
+
+// First moves the iterator to the first key/value pair. It returns false if
+// such a pair does not exist. Panics if the cursor is closed.
+func (c *BoltDBCursor) First() bool {
+	if c.isClosed {
+		panic("cannot call first on a closed cursor")
+	}
+
+	return c.mustSeek(c.bucket.Path())
+}
+
+// Seek moves the iterator to the first key/value pair whose key is greater
+// than or equal to the given key. It returns ErrNotFound if such pair does not
+// exist.
+func (c *BoltDBCursor) Seek(key *database.Key) error {
+	if c.isClosed {
+		return errors.New("cannot seek a closed cursor")
+	}
+
+	err := c.seek(key.Bytes())
+	if err != nil {
+		return err
+	}
+	if c.isExhausted || !bytes.Equal(c.page[c.index].key, key.Bytes()) {
+		return errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+	}
+	return nil
+}
+
+// Key returns the key of the current key/value pair, or ErrNotFound if done.
+// Note that the key is trimmed to not include the prefix the cursor was opened
+// with. The caller should not modify the contents of the returned slice, and
+// its contents may change on the next call to Next.
+func (c *BoltDBCursor) Key() (*database.Key, error) {
+	if c.isClosed {
+		return nil, errors.New("cannot get the key of a closed cursor")
+	}
+	if !c.isStarted || c.isExhausted {
+		return nil, errors.Wrapf(database.ErrNotFound, "cannot get the "+
+			"key of an exhausted cursor")
+	}
+	suffix := bytes.TrimPrefix(c.page[c.index].key, c.bucket.Path())
+	return c.bucket.Key(suffix), nil
+}
+
+// Value returns the value of the current key/value pair, or ErrNotFound if done.
+// The caller should not modify the contents of the returned slice, and its
+// contents may change on the next call to Next.
+func (c *BoltDBCursor) Value() ([]byte, error) {
+	if c.isClosed {
+		return nil, errors.New("cannot get the value of a closed cursor")
+	}
+	if !c.isStarted || c.isExhausted {
+		return nil, errors.Wrapf(database.ErrNotFound, "cannot get the "+
+			"value of an exhausted cursor")
+	}
+	return c.page[c.index].value, nil
+}
+
+// Close releases associated resources.
+func (c *BoltDBCursor) Close() error {
+	if c.isClosed {
+		return errors.New("cannot close an already closed cursor")
+	}
+	c.isClosed = true
+	c.db = nil
+	c.bucket = nil
+	c.page = nil
+	return nil
+}
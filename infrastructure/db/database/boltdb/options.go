@@ -0,0 +1,20 @@
+package boltdb
+
+import (
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Options is a function that returns a bbolt
+// Options struct for opening a database.
+func Options() *bolt.Options {
+	return &bolt.Options{
+		// Fail instead of blocking forever if another process holds the database
+		Timeout: time.Second,
+		// Match leveldb's NoSync. The database is flushed to disk on Close.
+		NoSync:         true,
+		NoFreelistSync: true,
+		FreelistType:   bolt.FreelistMapType,
+	}
+}
@@ -0,0 +1,143 @@
+package boltdb
+
+import (
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltDBTransaction accumulates writes in a batch, which is applied to the
+// database within a single bbolt read-write transaction on commit. bbolt
+// only allows a single read-write transaction at a time, so holding one
+// open for the lifetime of a kashd transaction would block every other
+// writer.
+//
+// Note that reads are done from the Database directly, so if another transaction changed the data,
+// you will read the new data, and not the one from the time the transaction was opened.
+//
+// Note: As it's currently implemented, if one puts data into the transaction
+// then it will not be available to get within the same transaction.
+type BoltDBTransaction struct {
+	db       *BoltDB
+	batch    []batchOperation
+	isClosed bool
+}
+
+// batchOperation is a single write within a transaction. A nil value
+// denotes a deletion.
+type batchOperation struct {
+	key   []byte
+	value []byte
+}
+
+// Begin begins a new transaction.
+func (db *BoltDB) Begin() (database.Transaction, error) {
+	return &BoltDBTransaction{
+		db:       db,
+		isClosed: false,
+	}, nil
+}
+
+// Commit commits whatever changes were made to the database
+// within this transaction.
+func (tx *BoltDBTransaction) Commit() error {
+	if tx.isClosed {
+		return errors.New("cannot commit a closed transaction")
+	}
+	tx.isClosed = true
+
+	err := tx.db.bolt.Update(func(boltTx *bolt.Tx) error {
+		bucket := boltTx.Bucket(rootBucketName)
+		for _, operation := range tx.batch {
+			var err error
+			if operation.value == nil {
+				err = bucket.Delete(operation.key)
+			} else {
+				err = bucket.Put(operation.key, operation.value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	tx.batch = nil
+	return errors.WithStack(err)
+}
+
+// Rollback rolls back whatever changes were made to the
+// database within this transaction.
+func (tx *BoltDBTransaction) Rollback() error {
+	if tx.isClosed {
+		return errors.New("cannot rollback a closed transaction")
+	}
+
+	tx.isClosed = true
+	tx.batch = nil
+	return nil
+}
+
+// RollbackUnlessClosed rolls back changes that were made to
+// the database within the transaction, unless the transaction
+// had already been closed using either Rollback or Commit.
+func (tx *BoltDBTransaction) RollbackUnlessClosed() error {
+	if tx.isClosed {
+		return nil
+	}
+	return tx.Rollback()
+}
+
+// Put sets the value for the given key. It overwrites
+// any previous value for that key.
+func (tx *BoltDBTransaction) Put(key *database.Key, value []byte) error {
+	if tx.isClosed {
+		return errors.New("cannot put into a closed transaction")
+	}
+
+	// Copy both the key and the value, since the caller may modify them before
+	// the transaction is committed. Note that the copied value is never nil,
+	// even if the given value is empty.
+	tx.batch = append(tx.batch, batchOperation{
+		key:   append([]byte{}, key.Bytes()...),
+		value: append([]byte{}, value...),
+	})
+	return nil
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (tx *BoltDBTransaction) Get(key *database.Key) ([]byte, error) {
+	if tx.isClosed {
+		return nil, errors.New("cannot get from a closed transaction")
+	}
+	return tx.db.Get(key)
+}
+
+// Has returns true if the database does contains the
+// given key.
+func (tx *BoltDBTransaction) Has(key *database.Key) (bool, error) {
+	if tx.isClosed {
+		return false, errors.New("cannot has from a closed transaction")
+	}
+	return tx.db.Has(key)
+}
+
+// Delete deletes the value for the given key. Will not
+// return an error if the key doesn't exist.
+func (tx *BoltDBTransaction) Delete(key *database.Key) error {
+	if tx.isClosed {
+		return errors.New("cannot delete from a closed transaction")
+	}
+
+	tx.batch = append(tx.batch, batchOperation{key: append([]byte{}, key.Bytes()...)})
+	return nil
+}
+
+// Cursor begins a new cursor over the given bucket.
+func (tx *BoltDBTransaction) Cursor(bucket *database.Bucket) (database.Cursor, error) {
+	if tx.isClosed {
+		return nil, errors.New("cannot open a cursor from a closed transaction")
+	}
+
+	return tx.db.Cursor(bucket)
+}
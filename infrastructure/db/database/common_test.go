@@ -6,7 +6,9 @@ import (
 	"testing"
 
 	"github.com/Kash-Protocol/kashd/infrastructure/db/database"
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database/boltdb"
 	"github.com/Kash-Protocol/kashd/infrastructure/db/database/ldb"
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database/memdb"
 )
 
 type databasePrepareFunc func(t *testing.T, testName string) (db database.Database, name string, teardownFunc func())
@@ -16,6 +18,8 @@ type databasePrepareFunc func(t *testing.T, testName string) (db database.Databa
 // See testForAllDatabaseTypes for further details.
 var databasePrepareFuncs = []databasePrepareFunc{
 	prepareLDBForTest,
+	prepareBoltDBForTest,
+	prepareMemoryDBForTest,
 }
 
 func prepareLDBForTest(t *testing.T, testName string) (db database.Database, name string, teardownFunc func()) {
@@ -40,6 +44,40 @@ func prepareLDBForTest(t *testing.T, testName string) (db database.Database, nam
 	return db, "ldb", teardownFunc
 }
 
+func prepareBoltDBForTest(t *testing.T, testName string) (db database.Database, name string, teardownFunc func()) {
+	// Create a temp db to run tests against
+	path, err := ioutil.TempDir("", testName)
+	if err != nil {
+		t.Fatalf("%s: TempDir unexpectedly "+
+			"failed: %s", testName, err)
+	}
+	db, err = boltdb.NewBoltDB(path)
+	if err != nil {
+		t.Fatalf("%s: Open unexpectedly "+
+			"failed: %s", testName, err)
+	}
+	teardownFunc = func() {
+		err = db.Close()
+		if err != nil {
+			t.Fatalf("%s: Close unexpectedly "+
+				"failed: %s", testName, err)
+		}
+	}
+	return db, "bbolt", teardownFunc
+}
+
+func prepareMemoryDBForTest(t *testing.T, testName string) (db database.Database, name string, teardownFunc func()) {
+	db = memdb.NewMemoryDB()
+	teardownFunc = func() {
+		err := db.Close()
+		if err != nil {
+			t.Fatalf("%s: Close unexpectedly "+
+				"failed: %s", testName, err)
+		}
+	}
+	return db, "memory", teardownFunc
+}
+
 // testForAllDatabaseTypes runs the given testFunc for every database
 // type defined in databasePrepareFuncs. This is to make sure that
 // all supported database types adhere to the assumptions defined in
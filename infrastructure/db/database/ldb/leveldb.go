@@ -9,6 +9,15 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
+// DBType is the name this backend is registered under
+const DBType = "leveldb"
+
+func init() {
+	database.RegisterBackend(DBType, func(path string, cacheSizeMiB int) (database.Database, error) {
+		return NewLevelDB(path, cacheSizeMiB)
+	})
+}
+
 // LevelDB defines a thin wrapper around leveldb.
 type LevelDB struct {
 	ldb *leveldb.DB
@@ -48,6 +57,25 @@ func NewLevelDB(path string, cacheSizeMiB int) (*LevelDB, error) {
 	return db, nil
 }
 
+// NewLevelDBReadOnly opens an existing leveldb instance defined by the given path without
+// allowing any writes to it. Unlike NewLevelDB it never attempts to recover a corrupted
+// database, so it's safe to use for inspecting the data directory of a stopped node.
+func NewLevelDBReadOnly(path string, cacheSizeMiB int) (*LevelDB, error) {
+	options := Options()
+	options.BlockCacheCapacity = cacheSizeMiB * opt.MiB
+	options.ReadOnly = true
+	options.ErrorIfMissing = true
+	ldb, err := leveldb.OpenFile(path, &options)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	db := &LevelDB{
+		ldb: ldb,
+	}
+	return db, nil
+}
+
 // Compact compacts the leveldb instance.
 func (db *LevelDB) Compact() error {
 	err := db.ldb.CompactRange(util.Range{Start: nil, Limit: nil})
@@ -60,6 +88,40 @@ func (db *LevelDB) Close() error {
 	return errors.WithStack(err)
 }
 
+// Stats returns the compaction, IO and level statistics of the leveldb instance.
+// This is part of the database.StatsProvider interface.
+func (db *LevelDB) Stats() (*database.Stats, error) {
+	var ldbStats leveldb.DBStats
+	err := db.ldb.Stats(&ldbStats)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	stats := &database.Stats{
+		Compactions: map[string]uint64{
+			"memory":     uint64(ldbStats.MemComp),
+			"level0":     uint64(ldbStats.Level0Comp),
+			"non_level0": uint64(ldbStats.NonLevel0Comp),
+			"seek":       uint64(ldbStats.SeekComp),
+		},
+		Levels:             make([]database.LevelStats, len(ldbStats.LevelSizes)),
+		IOReadBytes:        ldbStats.IORead,
+		IOWriteBytes:       ldbStats.IOWrite,
+		WriteDelayCount:    uint64(ldbStats.WriteDelayCount),
+		WriteDelayDuration: ldbStats.WriteDelayDuration,
+	}
+	for level := range stats.Levels {
+		stats.Levels[level] = database.LevelStats{
+			SizeBytes:            ldbStats.LevelSizes[level],
+			TableCount:           ldbStats.LevelTablesCounts[level],
+			CompactionDuration:   ldbStats.LevelDurations[level],
+			CompactionReadBytes:  ldbStats.LevelRead[level],
+			CompactionWriteBytes: ldbStats.LevelWrite[level],
+		}
+	}
+	return stats, nil
+}
+
 // Put sets the value for the given key. It overwrites
 // any previous value for that key.
 func (db *LevelDB) Put(key *database.Key, value []byte) error {
@@ -151,3 +151,96 @@ func TestLevelDBTransactionSanity(t *testing.T) {
 			"returned unexpected error: %s", err)
 	}
 }
+
+func TestLevelDBReadOnly(t *testing.T) {
+	path, err := ioutil.TempDir("", "TestLevelDBReadOnly")
+	if err != nil {
+		t.Fatalf("TestLevelDBReadOnly: TempDir unexpectedly "+
+			"failed: %s", err)
+	}
+
+	// Opening a database that doesn't exist should fail rather than create it
+	_, err = NewLevelDBReadOnly(path, 8)
+	if err == nil {
+		t.Fatalf("TestLevelDBReadOnly: NewLevelDBReadOnly " +
+			"unexpectedly succeeded for a missing database")
+	}
+
+	ldb, err := NewLevelDB(path, 8)
+	if err != nil {
+		t.Fatalf("TestLevelDBReadOnly: NewLevelDB unexpectedly "+
+			"failed: %s", err)
+	}
+	key := database.MakeBucket(nil).Key([]byte("key"))
+	putData := []byte("Hello world!")
+	err = ldb.Put(key, putData)
+	if err != nil {
+		t.Fatalf("TestLevelDBReadOnly: Put returned "+
+			"unexpected error: %s", err)
+	}
+	err = ldb.Close()
+	if err != nil {
+		t.Fatalf("TestLevelDBReadOnly: Close unexpectedly "+
+			"failed: %s", err)
+	}
+
+	readOnlyLDB, err := NewLevelDBReadOnly(path, 8)
+	if err != nil {
+		t.Fatalf("TestLevelDBReadOnly: NewLevelDBReadOnly unexpectedly "+
+			"failed: %s", err)
+	}
+	defer readOnlyLDB.Close()
+
+	getData, err := readOnlyLDB.Get(key)
+	if err != nil {
+		t.Fatalf("TestLevelDBReadOnly: Get returned "+
+			"unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(getData, putData) {
+		t.Fatalf("TestLevelDBReadOnly: get data and "+
+			"put data are not equal. Put: %s, got: %s",
+			string(putData), string(getData))
+	}
+
+	err = readOnlyLDB.Put(key, []byte("Goodbye world!"))
+	if err == nil {
+		t.Fatalf("TestLevelDBReadOnly: Put unexpectedly " +
+			"succeeded on a read-only database")
+	}
+}
+
+func TestLevelDBStats(t *testing.T) {
+	ldb, teardownFunc := prepareDatabaseForTest(t, "TestLevelDBStats")
+	defer teardownFunc()
+
+	bucket := database.MakeBucket([]byte("bucket"))
+	for i := 0; i < 100; i++ {
+		err := ldb.Put(bucket.Key([]byte{byte(i)}), make([]byte, 1000))
+		if err != nil {
+			t.Fatalf("TestLevelDBStats: Put returned unexpected error: %s", err)
+		}
+	}
+	err := ldb.Compact()
+	if err != nil {
+		t.Fatalf("TestLevelDBStats: Compact returned unexpected error: %s", err)
+	}
+
+	var _ database.StatsProvider = ldb
+	stats, err := ldb.Stats()
+	if err != nil {
+		t.Fatalf("TestLevelDBStats: Stats returned unexpected error: %s", err)
+	}
+	if stats.Compactions["memory"] == 0 {
+		t.Fatalf("TestLevelDBStats: expected the compaction to be counted. Got: %v", stats.Compactions)
+	}
+	if stats.IOWriteBytes == 0 {
+		t.Fatalf("TestLevelDBStats: expected the compaction to write to the storage")
+	}
+	totalSize := int64(0)
+	for _, level := range stats.Levels {
+		totalSize += level.SizeBytes
+	}
+	if totalSize == 0 {
+		t.Fatalf("TestLevelDBStats: expected the compacted data to be stored in the levels. Got: %+v", stats.Levels)
+	}
+}
@@ -0,0 +1,147 @@
+package memdb
+
+import (
+	"bytes"
+
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database"
+	"github.com/google/btree"
+	"github.com/pkg/errors"
+)
+
+// MemoryDBCursor iterates over a snapshot of the database, taken when the
+// cursor was opened, so that later writes do not affect it. This mirrors the
+// behavior of leveldb iterators.
+type MemoryDBCursor struct {
+	snapshot *btree.BTreeG[entry]
+	bucket   *database.Bucket
+
+	// current is nil both before the first call to Next and once the
+	// cursor is exhausted. isStarted tells the two apart.
+	current   *entry
+	isStarted bool
+
+	isClosed bool
+}
+
+// Cursor begins a new cursor over the given bucket.
+func (db *MemoryDB) Cursor(bucket *database.Bucket) (database.Cursor, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.isClosed {
+		return nil, errors.New("cannot open a cursor from a closed database")
+	}
+
+	return &MemoryDBCursor{
+		// Clone is a lazy copy-on-write operation, which makes it cheap
+		// to take a snapshot even of large databases
+		snapshot: db.tree.Clone(),
+		bucket:   bucket,
+	}, nil
+}
+
+// seek moves the cursor to the first entry in its bucket whose key is greater
+// than or equal to the given key, or marks it exhausted if there is none.
+func (c *MemoryDBCursor) seek(key []byte) {
+	c.isStarted = true
+	c.current = nil
+
+	prefix := c.bucket.Path()
+	c.snapshot.AscendGreaterOrEqual(entry{key: key}, func(item entry) bool {
+		if bytes.HasPrefix(item.key, prefix) {
+			c.current = &item
+		}
+		return false
+	})
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted. Panics if the cursor is closed.
+func (c *MemoryDBCursor) Next() bool {
+	if c.isClosed {
+		panic("cannot call next on a closed cursor")
+	}
+
+	if !c.isStarted {
+		c.seek(c.bucket.Path())
+		return c.current != nil
+	}
+	if c.current == nil {
+		return false
+	}
+
+	// The smallest key that is greater than the current key is
+	// the current key followed by a zero byte
+	nextKey := make([]byte, len(c.current.key)+1)
+	copy(nextKey, c.current.key)
+	c.seek(nextKey)
+	return c.current != nil
+}
+
+// First moves the iterator to the first key/value pair. It returns false if
+// such a pair does not exist. Panics if the cursor is closed.
+func (c *MemoryDBCursor) First() bool {
+	if c.isClosed {
+		panic("cannot call first on a closed cursor")
+	}
+
+	c.seek(c.bucket.Path())
+	return c.current != nil
+}
+
+// Seek moves the iterator to the first key/value pair whose key is greater
+// than or equal to the given key. It returns ErrNotFound if such pair does not
+// exist.
+func (c *MemoryDBCursor) Seek(key *database.Key) error {
+	if c.isClosed {
+		return errors.New("cannot seek a closed cursor")
+	}
+
+	c.seek(key.Bytes())
+	if c.current == nil || !bytes.Equal(c.current.key, key.Bytes()) {
+		return errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+	}
+	return nil
+}
+
+// Key returns the key of the current key/value pair, or ErrNotFound if done.
+// Note that the key is trimmed to not include the prefix the cursor was opened
+// with. The caller should not modify the contents of the returned slice, and
+// its contents may change on the next call to Next.
+func (c *MemoryDBCursor) Key() (*database.Key, error) {
+	if c.isClosed {
+		return nil, errors.New("cannot get the key of a closed cursor")
+	}
+	if c.current == nil {
+		return nil, errors.Wrapf(database.ErrNotFound, "cannot get the "+
+			"key of an exhausted cursor")
+	}
+	suffix := bytes.TrimPrefix(c.current.key, c.bucket.Path())
+	return c.bucket.Key(suffix), nil
+}
+
+// Value returns the value of the current key/value pair, or ErrNotFound if done.
+// The caller should not modify the contents of the returned slice, and its
+// contents may change on the next call to Next.
+func (c *MemoryDBCursor) Value() ([]byte, error) {
+	if c.isClosed {
+		return nil, errors.New("cannot get the value of a closed cursor")
+	}
+	if c.current == nil {
+		return nil, errors.Wrapf(database.ErrNotFound, "cannot get the "+
+			"value of an exhausted cursor")
+	}
+	return c.current.value, nil
+}
+
+// Close releases associated resources.
+func (c *MemoryDBCursor) Close() error {
+	if c.isClosed {
+		return errors.New("cannot close an already closed cursor")
+	}
+	c.isClosed = true
+	c.snapshot = nil
+	c.bucket = nil
+	c.current = nil
+	return nil
+}
@@ -0,0 +1,130 @@
+package memdb
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database"
+	"github.com/google/btree"
+	"github.com/pkg/errors"
+)
+
+// DBType is the name this backend is registered under
+const DBType = "memory"
+
+func init() {
+	database.RegisterBackend(DBType, func(_ string, _ int) (database.Database, error) {
+		return NewMemoryDB(), nil
+	})
+}
+
+// btreeDegree is the degree of the underlying b-tree. 32 is what
+// github.com/google/btree recommends for in-memory key/value stores.
+const btreeDegree = 32
+
+type entry struct {
+	key   []byte
+	value []byte
+}
+
+func entryLess(a, b entry) bool {
+	return bytes.Compare(a.key, b.key) < 0
+}
+
+// MemoryDB is a database that keeps all of its data in memory. Its
+// contents are lost once it is closed.
+type MemoryDB struct {
+	tree     *btree.BTreeG[entry]
+	isClosed bool
+	lock     sync.RWMutex
+}
+
+// NewMemoryDB creates a new empty in-memory database.
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		tree: btree.NewG(btreeDegree, entryLess),
+	}
+}
+
+// Compact is a no-op, since there is nothing to compact in memory.
+func (db *MemoryDB) Compact() error {
+	return nil
+}
+
+// Close closes the database and releases all of its data.
+func (db *MemoryDB) Close() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.isClosed {
+		return errors.New("cannot close an already closed database")
+	}
+	db.isClosed = true
+	db.tree = nil
+	return nil
+}
+
+// Put sets the value for the given key. It overwrites
+// any previous value for that key.
+func (db *MemoryDB) Put(key *database.Key, value []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.isClosed {
+		return errors.New("cannot put into a closed database")
+	}
+	db.put(key.Bytes(), value)
+	return nil
+}
+
+// put copies the given key and value into the tree, so that later
+// modifications by the caller do not affect the stored data.
+// It is the caller's responsibility to hold the write lock.
+func (db *MemoryDB) put(key []byte, value []byte) {
+	db.tree.ReplaceOrInsert(entry{
+		key:   append([]byte{}, key...),
+		value: append([]byte{}, value...),
+	})
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (db *MemoryDB) Get(key *database.Key) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.isClosed {
+		return nil, errors.New("cannot get from a closed database")
+	}
+	item, ok := db.tree.Get(entry{key: key.Bytes()})
+	if !ok {
+		return nil, errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+	}
+	// Return a copy, so that the caller may freely modify the returned value
+	return append([]byte{}, item.value...), nil
+}
+
+// Has returns true if the database does contains the
+// given key.
+func (db *MemoryDB) Has(key *database.Key) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.isClosed {
+		return false, errors.New("cannot has from a closed database")
+	}
+	return db.tree.Has(entry{key: key.Bytes()}), nil
+}
+
+// Delete deletes the value for the given key. Will not
+// return an error if the key doesn't exist.
+func (db *MemoryDB) Delete(key *database.Key) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.isClosed {
+		return errors.New("cannot delete from a closed database")
+	}
+	db.tree.Delete(entry{key: key.Bytes()})
+	return nil
+}
@@ -0,0 +1,137 @@
+package memdb
+
+import (
+	"github.com/Kash-Protocol/kashd/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// MemoryDBTransaction accumulates writes in a batch, which is applied to
+// the database atomically on commit.
+//
+// Note that reads are done from the Database directly, so if another transaction changed the data,
+// you will read the new data, and not the one from the time the transaction was opened.
+//
+// Note: As it's currently implemented, if one puts data into the transaction
+// then it will not be available to get within the same transaction.
+type MemoryDBTransaction struct {
+	db       *MemoryDB
+	batch    []batchOperation
+	isClosed bool
+}
+
+// batchOperation is a single write within a transaction. A nil value
+// denotes a deletion.
+type batchOperation struct {
+	key   []byte
+	value []byte
+}
+
+// Begin begins a new transaction.
+func (db *MemoryDB) Begin() (database.Transaction, error) {
+	return &MemoryDBTransaction{
+		db:       db,
+		isClosed: false,
+	}, nil
+}
+
+// Commit commits whatever changes were made to the database
+// within this transaction.
+func (tx *MemoryDBTransaction) Commit() error {
+	if tx.isClosed {
+		return errors.New("cannot commit a closed transaction")
+	}
+	tx.isClosed = true
+
+	tx.db.lock.Lock()
+	defer tx.db.lock.Unlock()
+
+	if tx.db.isClosed {
+		return errors.New("cannot commit into a closed database")
+	}
+	for _, operation := range tx.batch {
+		if operation.value == nil {
+			tx.db.tree.Delete(entry{key: operation.key})
+			continue
+		}
+		tx.db.put(operation.key, operation.value)
+	}
+	tx.batch = nil
+	return nil
+}
+
+// Rollback rolls back whatever changes were made to the
+// database within this transaction.
+func (tx *MemoryDBTransaction) Rollback() error {
+	if tx.isClosed {
+		return errors.New("cannot rollback a closed transaction")
+	}
+
+	tx.isClosed = true
+	tx.batch = nil
+	return nil
+}
+
+// RollbackUnlessClosed rolls back changes that were made to
+// the database within the transaction, unless the transaction
+// had already been closed using either Rollback or Commit.
+func (tx *MemoryDBTransaction) RollbackUnlessClosed() error {
+	if tx.isClosed {
+		return nil
+	}
+	return tx.Rollback()
+}
+
+// Put sets the value for the given key. It overwrites
+// any previous value for that key.
+func (tx *MemoryDBTransaction) Put(key *database.Key, value []byte) error {
+	if tx.isClosed {
+		return errors.New("cannot put into a closed transaction")
+	}
+
+	// Copy both the key and the value, since the caller may modify them before
+	// the transaction is committed. Note that the copied value is never nil,
+	// even if the given value is empty.
+	tx.batch = append(tx.batch, batchOperation{
+		key:   append([]byte{}, key.Bytes()...),
+		value: append([]byte{}, value...),
+	})
+	return nil
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (tx *MemoryDBTransaction) Get(key *database.Key) ([]byte, error) {
+	if tx.isClosed {
+		return nil, errors.New("cannot get from a closed transaction")
+	}
+	return tx.db.Get(key)
+}
+
+// Has returns true if the database does contains the
+// given key.
+func (tx *MemoryDBTransaction) Has(key *database.Key) (bool, error) {
+	if tx.isClosed {
+		return false, errors.New("cannot has from a closed transaction")
+	}
+	return tx.db.Has(key)
+}
+
+// Delete deletes the value for the given key. Will not
+// return an error if the key doesn't exist.
+func (tx *MemoryDBTransaction) Delete(key *database.Key) error {
+	if tx.isClosed {
+		return errors.New("cannot delete from a closed transaction")
+	}
+
+	tx.batch = append(tx.batch, batchOperation{key: append([]byte{}, key.Bytes()...)})
+	return nil
+}
+
+// Cursor begins a new cursor over the given bucket.
+func (tx *MemoryDBTransaction) Cursor(bucket *database.Bucket) (database.Cursor, error) {
+	if tx.isClosed {
+		return nil, errors.New("cannot open a cursor from a closed transaction")
+	}
+
+	return tx.db.Cursor(bucket)
+}
@@ -0,0 +1,35 @@
+package database
+
+import "time"
+
+// StatsProvider is implemented by database backends that collect statistics about their storage engine
+type StatsProvider interface {
+	// Stats returns the current statistics of the database
+	Stats() (*Stats, error)
+}
+
+// Stats are the statistics of a database storage engine. All the counters are cumulative since the
+// database was opened.
+type Stats struct {
+	// Compactions maps every kind of compaction to the number of compactions of that kind
+	Compactions map[string]uint64
+
+	// Levels are the statistics of the levels of the storage engine, for engines that store their data in levels
+	Levels []LevelStats
+
+	IOReadBytes  uint64
+	IOWriteBytes uint64
+
+	// WriteDelayCount is the number of writes that were delayed for compactions to catch up
+	WriteDelayCount    uint64
+	WriteDelayDuration time.Duration
+}
+
+// LevelStats are the statistics of a single storage level
+type LevelStats struct {
+	SizeBytes            int64
+	TableCount           int
+	CompactionDuration   time.Duration
+	CompactionReadBytes  int64
+	CompactionWriteBytes int64
+}
@@ -54,6 +54,7 @@ const logsBuffer = 0
 // subsystems.
 type Backend struct {
 	flag      uint32
+	format    uint32 // atomic
 	isRunning uint32
 	writers   []logWriter
 	writeChan chan logEntry
@@ -91,6 +92,27 @@ func (lw logWriterWrap) LogLevel() Level {
 	return lw.logLevel
 }
 
+// levelWriter is implemented by log writers that handle each level differently,
+// such as syslog, which has a priority for every message
+type levelWriter interface {
+	WriteLevel(level Level, p []byte) error
+}
+
+// SetFormat sets the format the backend writes the log messages in. It's
+// FormatText by default.
+func (b *Backend) SetFormat(format Format) error {
+	if b.IsRunning() {
+		return errors.New("The logger is already running")
+	}
+	atomic.StoreUint32(&b.format, uint32(format))
+	return nil
+}
+
+// Format returns the format the backend writes the log messages in
+func (b *Backend) Format() Format {
+	return Format(atomic.LoadUint32(&b.format))
+}
+
 // AddLogFile adds a file which the log will write into on a certain
 // log level with the default log rotation settings. It'll create the file if it doesn't exist.
 func (b *Backend) AddLogFile(logFile string, logLevel Level) error {
@@ -161,6 +183,10 @@ func (b *Backend) runBlocking() {
 	for log := range b.writeChan {
 		for _, writer := range b.writers {
 			if log.level >= writer.LogLevel() {
+				if levelWriter, ok := writer.(levelWriter); ok {
+					_ = levelWriter.WriteLevel(log.level, log.log)
+					continue
+				}
 				_, _ = writer.Write(log.log)
 			}
 		}
@@ -187,5 +213,6 @@ func (b *Backend) Close() {
 // Backend b. A tag describes the subsystem and is included in all log
 // messages. The logger uses the info verbosity level by default.
 func (b *Backend) Logger(subsystemTag string) *Logger {
-	return &Logger{LevelOff, subsystemTag, b, b.writeChan}
+	level := LevelOff
+	return &Logger{lvl: &level, tag: subsystemTag, b: b, writeChan: b.writeChan}
 }
@@ -19,5 +19,9 @@ are recognized:
 
 	shortfile: Include the filename and line number in all log messages.
 	Overrides longfile.
+
+Backends write messages as text lines by default. With FormatJSON, every message
+is written as a JSON object with the time, level, subsystem and message, and the
+block hash and peer ID attached with Logger.WithBlockHash and Logger.WithPeerID.
 */
 package logger
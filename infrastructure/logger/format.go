@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/Kash-Protocol/kashd/util/mstime"
+)
+
+// Format is the format a Backend writes log messages in
+type Format uint32
+
+// Format constants.
+const (
+	// FormatText writes every message as a line of the form
+	// 'YYYY-MM-DD hh:mm:ss.sss [LVL] TAG: message'
+	FormatText Format = iota
+
+	// FormatJSON writes every message as a JSON object on its own line. See jsonLogEntry
+	// for its fields.
+	FormatJSON
+)
+
+// FormatFromString returns a format based on the input string s. If the input
+// can't be interpreted as a valid format, FormatText and false is returned.
+func FormatFromString(s string) (format Format, ok bool) {
+	switch strings.ToLower(s) {
+	case "text":
+		return FormatText, true
+	case "json":
+		return FormatJSON, true
+	default:
+		return FormatText, false
+	}
+}
+
+// jsonLogEntry is a log message in the JSON format. Fields that weren't
+// attached to the message are omitted.
+type jsonLogEntry struct {
+	Time      string `json:"time"`
+	Level     string `json:"level"`
+	Subsystem string `json:"subsystem"`
+	File      string `json:"file,omitempty"`
+	BlockHash string `json:"blockHash,omitempty"`
+	PeerID    string `json:"peerID,omitempty"`
+	Message   string `json:"message"`
+}
+
+// jsonTimeLayout is RFC 3339 with milliseconds, matching the precision of the text format
+const jsonTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+func (l *Logger) formatJSON(t mstime.Time, lvl Level, tag string, file string, line int, message string) []byte {
+	entry := jsonLogEntry{
+		Time:      t.ToNativeTime().Format(jsonTimeLayout),
+		Level:     lvl.Name(),
+		Subsystem: tag,
+		Message:   message,
+	}
+	if file != "" {
+		entry.File = file + ":" + strconv.Itoa(line)
+	}
+	if l.blockHash != nil {
+		entry.BlockHash = l.blockHash.String()
+	}
+	if l.peerID != nil {
+		entry.PeerID = l.peerID.String()
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, normalLogSize))
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	// Encoding a struct of strings can't fail. Encode terminates the entry with a newline.
+	_ = encoder.Encode(entry)
+	return buf.Bytes()
+}
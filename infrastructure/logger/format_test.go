@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type testWriteCloser struct {
+	bytes.Buffer
+}
+
+func (*testWriteCloser) Close() error {
+	return nil
+}
+
+type testStringer string
+
+func (s testStringer) String() string {
+	return string(s)
+}
+
+func TestFormatJSON(t *testing.T) {
+	backend := NewBackendWithFlags(0)
+	err := backend.SetFormat(FormatJSON)
+	if err != nil {
+		t.Fatalf("SetFormat: %+v", err)
+	}
+	writer := &testWriteCloser{}
+	err = backend.AddLogWriter(writer, LevelTrace)
+	if err != nil {
+		t.Fatalf("AddLogWriter: %+v", err)
+	}
+	err = backend.Run()
+	if err != nil {
+		t.Fatalf("Run: %+v", err)
+	}
+
+	logger := backend.Logger("TEST")
+	logger.SetLevel(LevelInfo)
+	blockLogger := logger.WithBlockHash(testStringer("abcd")).WithPeerID(testStringer("1234"))
+
+	blockLogger.Infof("Accepted block %s <relay>", "abcd")
+	logger.Warn("Peer", "disconnected")
+	blockLogger.Debugf("Not logged")
+
+	// The derived loggers share the level of the logger they were derived from
+	logger.SetLevel(LevelDebug)
+	blockLogger.Debugf("Logged")
+
+	err = backend.SetFormat(FormatText)
+	if err == nil {
+		t.Fatalf("SetFormat unexpectedly succeeded while the backend is running")
+	}
+	backend.Close()
+
+	lines := strings.Split(strings.TrimSuffix(writer.String(), "\n"), "\n")
+	expectedEntries := []jsonLogEntry{
+		{Level: "info", Subsystem: "TEST", BlockHash: "abcd", PeerID: "1234", Message: "Accepted block abcd <relay>"},
+		{Level: "warn", Subsystem: "TEST", Message: "Peer disconnected"},
+		{Level: "debug", Subsystem: "TEST", BlockHash: "abcd", PeerID: "1234", Message: "Logged"},
+	}
+	if len(lines) != len(expectedEntries) {
+		t.Fatalf("expected %d log lines but got %d:\n%s", len(expectedEntries), len(lines), writer.String())
+	}
+	for i, line := range lines {
+		var entry jsonLogEntry
+		err := json.Unmarshal([]byte(line), &entry)
+		if err != nil {
+			t.Fatalf("line %d isn't valid JSON: %s", i, line)
+		}
+		if entry.Time == "" {
+			t.Fatalf("line %d is missing its time: %s", i, line)
+		}
+		entry.Time = ""
+		if entry != expectedEntries[i] {
+			t.Fatalf("unexpected entry in line %d. Want: %+v, got: %+v", i, expectedEntries[i], entry)
+		}
+	}
+}
+
+func TestFormatFromString(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedFormat Format
+		expectedOK     bool
+	}{
+		{"text", FormatText, true},
+		{"JSON", FormatJSON, true},
+		{"xml", FormatText, false},
+	}
+	for _, test := range tests {
+		format, ok := FormatFromString(test.input)
+		if format != test.expectedFormat || ok != test.expectedOK {
+			t.Errorf("FormatFromString(%s): expected (%d, %t) but got (%d, %t)",
+				test.input, test.expectedFormat, test.expectedOK, format, ok)
+		}
+	}
+}
@@ -20,6 +20,10 @@ const (
 // levelStrs defines the human-readable names for each logging level.
 var levelStrs = [...]string{"TRC", "DBG", "INF", "WRN", "ERR", "CRT", "OFF"}
 
+// levelNames defines the full names of each logging level, which are the
+// names LevelFromString accepts.
+var levelNames = [...]string{"trace", "debug", "info", "warn", "error", "critical", "off"}
+
 // LevelFromString returns a level based on the input string s. If the input
 // can't be interpreted as a valid log level, the info level and false is
 // returned.
@@ -52,3 +56,12 @@ func (l Level) String() string {
 	}
 	return levelStrs[l]
 }
+
+// Name returns the full name of the level, e.g. "info", or "off" if the level
+// will not produce any log output.
+func (l Level) Name() string {
+	if l >= LevelOff {
+		return "off"
+	}
+	return levelNames[l]
+}
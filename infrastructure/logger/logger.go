@@ -135,6 +135,16 @@ func SupportedSubsystems() []string {
 	return subsystems
 }
 
+// SubsystemLevel returns the logging level of the provided subsystem, and false
+// if it isn't a valid subsystem
+func SubsystemLevel(subsystemID string) (level Level, ok bool) {
+	logger, ok := getSubsystem(subsystemID)
+	if !ok {
+		return LevelOff, false
+	}
+	return logger.Level(), true
+}
+
 func getSubsystem(tag string) (logger *Logger, ok bool) {
 	subsystemLoggersMutex.Lock()
 	defer subsystemLoggersMutex.Unlock()
@@ -43,10 +43,14 @@ import (
 
 // Logger is a subsystem logger for a Backend.
 type Logger struct {
-	lvl       Level // atomic
+	lvl       *Level // atomic, shared with the loggers derived from this one
 	tag       string
 	b         *Backend
 	writeChan chan<- logEntry
+
+	// blockHash and peerID are optional fields written alongside the message in the JSON format
+	blockHash fmt.Stringer
+	peerID    fmt.Stringer
 }
 
 type logEntry struct {
@@ -146,12 +150,28 @@ func (l *Logger) Writef(logLevel Level, format string, args ...interface{}) {
 
 // Level returns the current logging level
 func (l *Logger) Level() Level {
-	return Level(atomic.LoadUint32((*uint32)(&l.lvl)))
+	return Level(atomic.LoadUint32((*uint32)(l.lvl)))
 }
 
 // SetLevel changes the logging level to the passed level.
 func (l *Logger) SetLevel(level Level) {
-	atomic.StoreUint32((*uint32)(&l.lvl), uint32(level))
+	atomic.StoreUint32((*uint32)(l.lvl), uint32(level))
+}
+
+// WithBlockHash returns a logger of the same subsystem that attaches the given
+// block hash to the messages it logs. The returned logger shares its level with l.
+func (l *Logger) WithBlockHash(blockHash fmt.Stringer) *Logger {
+	derived := *l
+	derived.blockHash = blockHash
+	return &derived
+}
+
+// WithPeerID returns a logger of the same subsystem that attaches the given
+// peer ID to the messages it logs. The returned logger shares its level with l.
+func (l *Logger) WithPeerID(peerID fmt.Stringer) *Logger {
+	derived := *l
+	derived.peerID = peerID
+	return &derived
 }
 
 // Backend returns the log backend
@@ -172,18 +192,24 @@ func (l *Logger) printf(lvl Level, tag string, format string, args ...interface{
 		file, line = callsite(l.b.flag)
 	}
 
-	buf := make([]byte, 0, normalLogSize)
-
-	formatHeader(&buf, t, lvl.String(), tag, file, line)
-	bytesBuf := bytes.NewBuffer(buf)
-	_, _ = fmt.Fprintf(bytesBuf, format, args...)
-	bytesBuf.WriteByte('\n')
+	var log []byte
+	if l.b.Format() == FormatJSON {
+		log = l.formatJSON(t, lvl, tag, file, line, fmt.Sprintf(format, args...))
+	} else {
+		buf := make([]byte, 0, normalLogSize)
+
+		formatHeader(&buf, t, lvl.String(), tag, file, line)
+		bytesBuf := bytes.NewBuffer(buf)
+		_, _ = fmt.Fprintf(bytesBuf, format, args...)
+		bytesBuf.WriteByte('\n')
+		log = bytesBuf.Bytes()
+	}
 
 	if !l.b.IsRunning() {
-		_, _ = fmt.Fprintf(os.Stderr, bytesBuf.String())
+		_, _ = os.Stderr.Write(log)
 		panic("Writing to the logger when it's not running")
 	}
-	l.writeChan <- logEntry{bytesBuf.Bytes(), lvl}
+	l.writeChan <- logEntry{log, lvl}
 }
 
 // print outputs a log message to the writer associated with the backend after
@@ -202,15 +228,22 @@ func (l *Logger) print(lvl Level, tag string, args ...interface{}) {
 		file, line = callsite(l.b.flag)
 	}
 
-	buf := make([]byte, 0, normalLogSize)
-	formatHeader(&buf, t, lvl.String(), tag, file, line)
-	bytesBuf := bytes.NewBuffer(buf)
-	_, _ = fmt.Fprintln(bytesBuf, args...)
+	var log []byte
+	if l.b.Format() == FormatJSON {
+		message := fmt.Sprintln(args...)
+		log = l.formatJSON(t, lvl, tag, file, line, message[:len(message)-1])
+	} else {
+		buf := make([]byte, 0, normalLogSize)
+		formatHeader(&buf, t, lvl.String(), tag, file, line)
+		bytesBuf := bytes.NewBuffer(buf)
+		_, _ = fmt.Fprintln(bytesBuf, args...)
+		log = bytesBuf.Bytes()
+	}
 
 	if !l.b.IsRunning() {
 		panic("Writing to the logger when it's not running")
 	}
-	l.writeChan <- logEntry{bytesBuf.Bytes(), lvl}
+	l.writeChan <- logEntry{log, lvl}
 }
 
 // From stdlib log package.
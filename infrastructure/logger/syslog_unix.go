@@ -0,0 +1,59 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logger
+
+import (
+	"log/syslog"
+
+	"github.com/pkg/errors"
+)
+
+// AddSyslog adds the local syslog daemon as a destination the log will write
+// into on a certain log level. On systemd-based systems journald listens on the
+// same local socket, so this also makes the log available through journalctl.
+// Messages are sent with the given tag and with a priority matching their level.
+func (b *Backend) AddSyslog(tag string, logLevel Level) error {
+	if b.IsRunning() {
+		return errors.New("The logger is already running")
+	}
+	writer, err := syslog.Dial("", "", syslog.LOG_DAEMON|syslog.LOG_INFO, tag)
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to the local syslog daemon")
+	}
+	b.writers = append(b.writers, syslogWriter{writer: writer, logLevel: logLevel})
+	return nil
+}
+
+type syslogWriter struct {
+	writer   *syslog.Writer
+	logLevel Level
+}
+
+func (sw syslogWriter) Write(p []byte) (int, error) {
+	return sw.writer.Write(p)
+}
+
+func (sw syslogWriter) Close() error {
+	return sw.writer.Close()
+}
+
+func (sw syslogWriter) LogLevel() Level {
+	return sw.logLevel
+}
+
+func (sw syslogWriter) WriteLevel(level Level, p []byte) error {
+	message := string(p)
+	switch level {
+	case LevelTrace, LevelDebug:
+		return sw.writer.Debug(message)
+	case LevelInfo:
+		return sw.writer.Info(message)
+	case LevelWarn:
+		return sw.writer.Warning(message)
+	case LevelError:
+		return sw.writer.Err(message)
+	default:
+		return sw.writer.Crit(message)
+	}
+}
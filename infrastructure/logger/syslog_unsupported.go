@@ -0,0 +1,11 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package logger
+
+import "github.com/pkg/errors"
+
+// AddSyslog returns an error since syslog isn't available on this platform.
+func (b *Backend) AddSyslog(tag string, logLevel Level) error {
+	return errors.New("syslog isn't supported on this platform")
+}
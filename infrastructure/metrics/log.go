@@ -0,0 +1,9 @@
+package metrics
+
+import (
+	"github.com/Kash-Protocol/kashd/infrastructure/logger"
+	"github.com/Kash-Protocol/kashd/util/panics"
+)
+
+var log = logger.RegisterSubSystem("MTRC")
+var spawn = panics.GoroutineWrapperFunc(log)
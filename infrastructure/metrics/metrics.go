@@ -0,0 +1,152 @@
+// Package metrics collects the metrics of kashd's components, and serves them over HTTP in the
+// Prometheus exposition format when --metricslisten is set.
+//
+// Components that measure events as they happen (e.g. block processing latencies) update the
+// metrics of this package directly, which is cheap enough to always be done. State that is
+// better read when the metrics are scraped (e.g. the mempool size) is reported by the
+// collectors passed to NewServer.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Namespace is the prefix of the names of all the metrics of kashd
+const Namespace = "kashd"
+
+const (
+	kindHeader = "header"
+	kindBlock  = "block"
+
+	resultAccepted = "accepted"
+	resultRejected = "rejected"
+
+	directionSent     = "sent"
+	directionReceived = "received"
+)
+
+// registry holds the metrics of this package. It's separate from prometheus.DefaultRegisterer
+// so that only kashd's own metrics are served.
+var registry = prometheus.NewRegistry()
+
+var (
+	blocksProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "consensus",
+		Name:      "blocks_processed_total",
+		Help:      "The number of headers and blocks processed by the block processor, by kind and result (accepted or rejected)",
+	}, []string{"kind", "result"})
+
+	blockProcessingDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "consensus",
+		Name:      "block_processing_duration_seconds",
+		Help:      "The time it took the block processor to validate and insert headers and blocks, by kind",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+	}, []string{"kind"})
+
+	randomXHashes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "consensus",
+		Name:      "randomx_hashes_total",
+		Help:      "The number of RandomX hashes calculated to verify proofs of work",
+	})
+
+	messages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "network",
+		Name:      "messages_total",
+		Help:      "The number of messages sent and received, by server (P2P or RPC), direction and message type",
+	}, []string{"server", "direction", "command"})
+
+	messageBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "network",
+		Name:      "message_bytes_total",
+		Help:      "The serialized size of the messages sent and received, by server (P2P or RPC), direction and message type",
+	}, []string{"server", "direction", "command"})
+
+	rpcRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "rpc",
+		Name:      "request_duration_seconds",
+		Help:      "The time it took to handle RPC requests, by command",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"command"})
+
+	ibdProgress = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "ibd",
+		Name:      "progress_ratio",
+		Help:      "The estimated progress (0 to 1) of the current or last IBD, by stage",
+	}, []string{"stage"})
+
+	ibdProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "ibd",
+		Name:      "processed_total",
+		Help:      "The number of block headers and blocks processed during IBD, by stage",
+	}, []string{"stage"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{Namespace: Namespace}),
+		blocksProcessed,
+		blockProcessingDuration,
+		randomXHashes,
+		messages,
+		messageBytes,
+		rpcRequestDuration,
+		ibdProgress,
+		ibdProcessed,
+	)
+}
+
+// ObserveBlockProcessed records that the block processor processed a header-only block or a
+// full block in the given duration. Blocks are rejected when they are invalid, but also when
+// they were already known or have missing parents.
+func ObserveBlockProcessed(isHeaderOnly bool, isAccepted bool, duration time.Duration) {
+	kind := kindBlock
+	if isHeaderOnly {
+		kind = kindHeader
+	}
+	result := resultAccepted
+	if !isAccepted {
+		result = resultRejected
+	}
+	blocksProcessed.WithLabelValues(kind, result).Inc()
+	blockProcessingDuration.WithLabelValues(kind).Observe(duration.Seconds())
+}
+
+// IncRandomXHashes records that a RandomX hash was calculated to verify a proof of work
+func IncRandomXHashes() {
+	randomXHashes.Inc()
+}
+
+// AddMessage records a message of the given serialized size that was sent or received by
+// the server with the given name
+func AddMessage(serverName string, command string, size int, isOutgoing bool) {
+	direction := directionReceived
+	if isOutgoing {
+		direction = directionSent
+	}
+	messages.WithLabelValues(serverName, direction, command).Inc()
+	messageBytes.WithLabelValues(serverName, direction, command).Add(float64(size))
+}
+
+// ObserveRPCRequest records that an RPC request of the given command was handled in the given duration
+func ObserveRPCRequest(command string, duration time.Duration) {
+	rpcRequestDuration.WithLabelValues(command).Observe(duration.Seconds())
+}
+
+// SetIBDProgress records the progress of the given IBD stage, after processedDelta more objects
+// were processed
+func SetIBDProgress(stage string, processedDelta int, progress float64) {
+	ibdProcessed.WithLabelValues(stage).Add(float64(processedDelta))
+	ibdProgress.WithLabelValues(stage).Set(progress)
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestServer(t *testing.T) {
+	testGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "test_gauge",
+		Help:      "A gauge of the test",
+	})
+	testGauge.Set(7)
+
+	server, err := NewServer("127.0.0.1:0", testGauge)
+	if err != nil {
+		t.Fatalf("NewServer: %+v", err)
+	}
+
+	ObserveBlockProcessed(true, true, time.Millisecond)
+	ObserveBlockProcessed(false, false, time.Millisecond)
+	AddMessage("P2P", "Block", 100, false)
+	AddMessage("P2P", "Block", 50, false)
+	ObserveRPCRequest("GetInfoRequest", time.Millisecond)
+	SetIBDProgress("block headers", 10, 0.5)
+
+	recorder := httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(recorder, httptest.NewRequest("GET", metricsPath, nil))
+	body, err := io.ReadAll(recorder.Result().Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %+v", err)
+	}
+
+	expectedLines := []string{
+		`kashd_test_gauge 7`,
+		`kashd_consensus_blocks_processed_total{kind="header",result="accepted"} 1`,
+		`kashd_consensus_blocks_processed_total{kind="block",result="rejected"} 1`,
+		`kashd_network_messages_total{command="Block",direction="received",server="P2P"} 2`,
+		`kashd_network_message_bytes_total{command="Block",direction="received",server="P2P"} 150`,
+		`kashd_rpc_request_duration_seconds_count{command="GetInfoRequest"} 1`,
+		`kashd_ibd_processed_total{stage="block headers"} 10`,
+		`kashd_ibd_progress_ratio{stage="block headers"} 0.5`,
+	}
+	for _, expectedLine := range expectedLines {
+		if !strings.Contains(string(body), expectedLine+"\n") {
+			t.Errorf("metrics don't contain %s. Got:\n%s", expectedLine, body)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsPath is the HTTP path the metrics are served at
+const metricsPath = "/metrics"
+
+// readHeaderTimeout limits how long a scraper may take to send its request headers
+const readHeaderTimeout = 10 * time.Second
+
+// Server serves the metrics of this package and of its collectors over HTTP
+type Server struct {
+	listenAddress string
+	httpServer    *http.Server
+}
+
+// NewServer returns a new Server that listens on the given address once started, and
+// serves the metrics of the given collectors in addition to the metrics of this package
+func NewServer(listenAddress string, serverCollectors ...prometheus.Collector) (*Server, error) {
+	serverRegistry := prometheus.NewRegistry()
+	for _, collector := range serverCollectors {
+		err := serverRegistry.Register(collector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error registering a metrics collector")
+		}
+	}
+
+	handler := promhttp.HandlerFor(prometheus.Gatherers{registry, serverRegistry}, promhttp.HandlerOpts{
+		ErrorLog:      promhttpLogger{},
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, handler)
+
+	return &Server{
+		listenAddress: listenAddress,
+		httpServer: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: readHeaderTimeout,
+		},
+	}, nil
+}
+
+// Start starts listening on the address of the server, and serving the metrics in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.listenAddress)
+	if err != nil {
+		return errors.Wrapf(err, "error listening on %s", s.listenAddress)
+	}
+	log.Infof("Metrics server listening on %s%s", listener.Addr(), metricsPath)
+
+	spawn("metrics.Server.Start", func() {
+		err := s.httpServer.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("Metrics server stopped: %s", err)
+		}
+	})
+	return nil
+}
+
+// Stop stops the server
+func (s *Server) Stop() error {
+	return s.httpServer.Close()
+}
+
+// promhttpLogger logs the errors of collecting metrics, which don't prevent serving the other metrics
+type promhttpLogger struct{}
+
+func (promhttpLogger) Println(v ...interface{}) {
+	log.Warn(v...)
+}
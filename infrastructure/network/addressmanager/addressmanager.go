@@ -7,7 +7,6 @@ package addressmanager
 import (
 	"github.com/Kash-Protocol/kashd/infrastructure/db/database"
 	"github.com/Kash-Protocol/kashd/util/mstime"
-	"net"
 	"sync"
 	"time"
 
@@ -25,30 +24,46 @@ type addressRandomizer interface {
 	RandomAddresses(addresses []*address, count int) []*appmessage.NetAddress
 }
 
-// addressKey represents a pair of IP and port, the IP is always in V6 representation
+// addressKey represents a pair of host and port
 type addressKey struct {
-	port    uint16
-	address ipv6
+	port uint16
+	host hostKey
 }
 
-type address struct {
-	netAddress            *appmessage.NetAddress
-	connectionFailedCount uint64
+// hostKey identifies a host regardless of its port. For IP addresses, the IP
+// is always in V6 representation, and occupies the first 16 bytes of address
+type hostKey struct {
+	network appmessage.NetAddressNetwork
+	address [maxHostAddressLength]byte
 }
 
-type ipv6 [net.IPv6len]byte
+// maxHostAddressLength is the length of the longest supported address - Tor v3 and I2P
+const maxHostAddressLength = 32
 
-func (i ipv6) equal(other ipv6) bool {
-	return i == other
+type address struct {
+	netAddress            *appmessage.NetAddress
+	connectionFailedCount uint64
 }
 
 // ErrAddressNotFound is an error returned from some functions when a
 // given address is not found in the address manager
 var ErrAddressNotFound = errors.New("address not found")
 
-// NetAddressKey returns a key of the ip address to use it in maps.
+// NetAddressKey returns a key of the address to use it in maps.
 func netAddressKey(netAddress *appmessage.NetAddress) addressKey {
-	key := addressKey{port: netAddress.Port}
+	return addressKey{
+		port: netAddress.Port,
+		host: netAddressHostKey(netAddress),
+	}
+}
+
+// netAddressHostKey returns a key of the host of the address to use it in maps.
+func netAddressHostKey(netAddress *appmessage.NetAddress) hostKey {
+	key := hostKey{network: netAddress.Network}
+	if netAddress.IsOverlay() {
+		copy(key.address[:], netAddress.Address)
+		return key
+	}
 	// all IPv4 can be represented as IPv6.
 	copy(key.address[:], netAddress.IP.To16())
 	return key
@@ -160,7 +175,7 @@ func (am *AddressManager) MarkConnectionFailure(address *appmessage.NetAddress)
 	key := netAddressKey(address)
 	entry, ok := am.store.getNotBanned(key)
 	if !ok {
-		return errors.Errorf("address %s is not registered with the address manager", address.TCPAddress())
+		return errors.Errorf("address %s is not registered with the address manager", address)
 	}
 	entry.connectionFailedCount = entry.connectionFailedCount + 1
 
@@ -181,7 +196,7 @@ func (am *AddressManager) MarkConnectionSuccess(address *appmessage.NetAddress)
 	key := netAddressKey(address)
 	entry, ok := am.store.getNotBanned(key)
 	if !ok {
-		return errors.Errorf("address %s is not registered with the address manager", address.TCPAddress())
+		return errors.Errorf("address %s is not registered with the address manager", address)
 	}
 	entry.connectionFailedCount = 0
 	return am.store.updateNotBanned(key, entry)
@@ -211,10 +226,28 @@ func (am *AddressManager) notBannedAddressesWithException(exceptions []*appmessa
 	return am.store.getAllNotBannedNetAddressesWithout(exceptions)
 }
 
-// RandomAddresses returns count addresses at random that aren't banned and aren't in exceptions
+// RandomAddresses returns count addresses at random that aren't banned, aren't in exceptions
+// and belong to a reachable network
 func (am *AddressManager) RandomAddresses(count int, exceptions []*appmessage.NetAddress) []*appmessage.NetAddress {
 	validAddresses := am.notBannedAddressesWithException(exceptions)
-	return am.random.RandomAddresses(validAddresses, count)
+	reachableAddresses := make([]*address, 0, len(validAddresses))
+	for _, address := range validAddresses {
+		if am.IsReachable(address.netAddress) {
+			reachableAddresses = append(reachableAddresses, address)
+		}
+	}
+	return am.random.RandomAddresses(reachableAddresses, count)
+}
+
+// IsReachable returns whether outgoing connections may be made to the given address,
+// according to the networks that are reachable from this node
+func (am *AddressManager) IsReachable(address *appmessage.NetAddress) bool {
+	// A nil ReachableNetworks means that the address manager was not configured
+	// through NewConfig, in which case only IP networks are considered reachable
+	if am.cfg.ReachableNetworks == nil {
+		return !address.IsOverlay()
+	}
+	return am.cfg.ReachableNetworks[NetworkName(address)]
 }
 
 // BestLocalAddress returns the most appropriate local address to use
@@ -232,7 +265,7 @@ func (am *AddressManager) Ban(addressToBan *appmessage.NetAddress) error {
 	keysToDelete := make([]addressKey, 0)
 	for _, address := range am.store.getAllNotBannedNetAddresses() {
 		key := netAddressKey(address)
-		if key.address.equal(keyToBan.address) {
+		if key.host == keyToBan.host {
 			keysToDelete = append(keysToDelete, key)
 		}
 	}
@@ -255,7 +288,7 @@ func (am *AddressManager) Unban(address *appmessage.NetAddress) error {
 	key := netAddressKey(address)
 	if !am.store.isBanned(key) {
 		return errors.Wrapf(ErrAddressNotFound, "address %s "+
-			"is not registered with the address manager as banned", address.TCPAddress())
+			"is not registered with the address manager as banned", address)
 	}
 
 	return am.store.removeBanned(key)
@@ -274,7 +307,7 @@ func (am *AddressManager) IsBanned(address *appmessage.NetAddress) (bool, error)
 	if !am.store.isBanned(key) {
 		if !am.store.isNotBanned(key) {
 			return false, errors.Wrapf(ErrAddressNotFound, "address %s "+
-				"is not registered with the address manager", address.TCPAddress())
+				"is not registered with the address manager", address)
 		}
 		return false, nil
 	}
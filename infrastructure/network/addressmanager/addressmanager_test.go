@@ -372,3 +372,81 @@ func TestOverfillAddressManager(t *testing.T) {
 		}
 	}
 }
+
+func TestOverlayAddresses(t *testing.T) {
+	cfg := config.DefaultConfig()
+
+	datadir := t.TempDir()
+	database, err := ldb.NewLevelDB(datadir, 8)
+	if err != nil {
+		t.Fatalf("Could not create a database: %s", err)
+	}
+	defer database.Close()
+
+	addressManager, err := New(NewConfig(cfg), database)
+	if err != nil {
+		t.Fatalf("Error creating address manager: %s", err)
+	}
+
+	onionAddress, err := appmessage.ParseNetAddress("pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion:16111")
+	if err != nil {
+		t.Fatalf("ParseNetAddress: %s", err)
+	}
+	i2pAddress, err := appmessage.ParseNetAddress("ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p:16111")
+	if err != nil {
+		t.Fatalf("ParseNetAddress: %s", err)
+	}
+	ipAddress := appmessage.NewNetAddressIPPort(net.ParseIP("1.2.3.4"), 16111)
+	invalidOnionAddress := appmessage.NewNetAddressOverlay(appmessage.NetAddressNetworkTorV3, []byte{1, 2, 3}, 16111)
+
+	err = addressManager.AddAddresses(onionAddress, i2pAddress, ipAddress, invalidOnionAddress)
+	if err != nil {
+		t.Fatalf("AddAddresses() failed: %s", err)
+	}
+	if len(addressManager.Addresses()) != 3 {
+		t.Fatalf("Unexpected amount of addresses returned from Addresses(). "+
+			"Want: %d, got: %d", 3, len(addressManager.Addresses()))
+	}
+
+	// Without a proxy, only the IP address is reachable
+	randomAddresses := addressManager.RandomAddresses(3, nil)
+	if len(randomAddresses) != 1 || !randomAddresses[0].IP.Equal(ipAddress.IP) {
+		t.Fatalf("Unexpected addresses returned from RandomAddresses(): %s", randomAddresses)
+	}
+
+	err = addressManager.Ban(onionAddress)
+	if err != nil {
+		t.Fatalf("Ban() failed: %s", err)
+	}
+
+	// Reopen the database and make sure that overlay addresses are restored
+	err = database.Close()
+	if err != nil {
+		t.Fatalf("Close() failed: %s", err)
+	}
+	database, err = ldb.NewLevelDB(datadir, 8)
+	if err != nil {
+		t.Fatalf("Could not create a database: %s", err)
+	}
+	defer database.Close()
+
+	// Allow outgoing connections to onion and I2P addresses only
+	cfg.OnlyNets = []string{config.NetworkOnion, config.NetworkI2P}
+	addressManager, err = New(NewConfig(cfg), database)
+	if err != nil {
+		t.Fatalf("Error creating address manager: %s", err)
+	}
+
+	isBanned, err := addressManager.IsBanned(onionAddress)
+	if err != nil {
+		t.Fatalf("IsBanned() failed: %s", err)
+	}
+	if !isBanned {
+		t.Fatalf("Onion address %s is expected to be banned", onionAddress)
+	}
+
+	randomAddresses = addressManager.RandomAddresses(3, nil)
+	if len(randomAddresses) != 1 || !reflect.DeepEqual(randomAddresses[0], i2pAddress) {
+		t.Fatalf("Unexpected addresses returned from RandomAddresses(): %s", randomAddresses)
+	}
+}
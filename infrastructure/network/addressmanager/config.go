@@ -13,6 +13,10 @@ type Config struct {
 	ExternalIPs      []string
 	Listeners        []string
 	Lookup           func(string) ([]net.IP, error)
+
+	// ReachableNetworks are the networks that outgoing connections may be
+	// made to, by their --onlynet names
+	ReachableNetworks map[string]bool
 }
 
 // NewConfig returns a new address manager Config.
@@ -23,5 +27,29 @@ func NewConfig(cfg *config.Config) *Config {
 		ExternalIPs:      cfg.ExternalIPs,
 		Listeners:        cfg.Listeners,
 		Lookup:           cfg.Lookup,
+
+		ReachableNetworks: reachableNetworks(cfg),
+	}
+}
+
+// reachableNetworks returns the networks specified by --onlynet, or otherwise
+// all the networks this node is able to connect to
+func reachableNetworks(cfg *config.Config) map[string]bool {
+	networks := make(map[string]bool)
+	if len(cfg.OnlyNets) > 0 {
+		for _, network := range cfg.OnlyNets {
+			networks[network] = true
+		}
+		return networks
+	}
+
+	networks[config.NetworkIPv4] = true
+	networks[config.NetworkIPv6] = true
+	if cfg.OnionDial != nil {
+		networks[config.NetworkOnion] = true
+	}
+	if cfg.I2PDial != nil {
+		networks[config.NetworkI2P] = true
 	}
+	return networks
 }
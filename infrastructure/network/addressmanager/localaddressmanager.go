@@ -230,8 +230,13 @@ func (lam *localAddressManager) initListeners() error {
 }
 
 // hostToNetAddress returns a netaddress given a host address. If
-// the host is not an IP address it will be resolved.
+// the host is neither an IP address nor an overlay network address
+// it will be resolved.
 func (lam *localAddressManager) hostToNetAddress(host string, port uint16) (*appmessage.NetAddress, error) {
+	if appmessage.IsOverlayHost(host) {
+		return appmessage.ParseNetAddressHost(host, port)
+	}
+
 	ip := net.ParseIP(host)
 	if ip == nil {
 		ips, err := lam.lookupFunc(host)
@@ -326,6 +331,16 @@ func reachabilityFrom(localAddress, remoteAddress *appmessage.NetAddress, accept
 		return Unreachable
 	}
 
+	// Prefer advertising an address in the same overlay network the remote
+	// peer is in. Addresses across different networks are still advertised,
+	// but only as a last resort.
+	if localAddress.IsOverlay() || remoteAddress.IsOverlay() {
+		if localAddress.Network == remoteAddress.Network {
+			return Private
+		}
+		return Default
+	}
+
 	if IsRFC4380(remoteAddress) {
 		if !IsRoutable(localAddress) {
 			return Default
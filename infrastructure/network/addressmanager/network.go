@@ -5,9 +5,11 @@
 package addressmanager
 
 import (
+	"fmt"
 	"net"
 
 	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/infrastructure/config"
 )
 
 var (
@@ -193,11 +195,39 @@ func IsRFC6598(na *appmessage.NetAddress) bool {
 	return rfc6598Net.Contains(na.IP)
 }
 
+// NetworkName returns the name of the network the address belongs to, as used
+// by the --onlynet option: ipv4, ipv6, onion or i2p
+func NetworkName(na *appmessage.NetAddress) string {
+	switch {
+	case na.Network == appmessage.NetAddressNetworkTorV3:
+		return config.NetworkOnion
+	case na.Network == appmessage.NetAddressNetworkI2P:
+		return config.NetworkI2P
+	case na.IsOverlay():
+		return na.Network.String()
+	case IsIPv4(na):
+		return config.NetworkIPv4
+	default:
+		return config.NetworkIPv6
+	}
+}
+
 // IsValid returns whether or not the passed address is valid. The address is
 // considered invalid under the following circumstances:
 // IPv4: It is either a zero or all bits set address.
 // IPv6: It is either a zero or RFC3849 documentation address.
+// Overlay: It belongs to an unknown network or is of the wrong length.
 func IsValid(na *appmessage.NetAddress) bool {
+	switch na.Network {
+	case appmessage.NetAddressNetworkIP:
+	case appmessage.NetAddressNetworkTorV3:
+		return len(na.Address) == appmessage.TorV3AddressLength
+	case appmessage.NetAddressNetworkI2P:
+		return len(na.Address) == appmessage.I2PAddressLength
+	default:
+		return false
+	}
+
 	// IsUnspecified returns if address is 0, so only all bits set, and
 	// RFC3849 need to be explicitly checked.
 	return na.IP != nil && !(na.IP.IsUnspecified() ||
@@ -206,8 +236,11 @@ func IsValid(na *appmessage.NetAddress) bool {
 
 // IsRoutable returns whether or not the passed address is routable over
 // the public internet. This is true as long as the address is valid and is not
-// in any reserved ranges.
+// in any reserved ranges. Valid overlay addresses are always routable.
 func IsRoutable(na *appmessage.NetAddress, acceptUnroutable bool) bool {
+	if na.IsOverlay() {
+		return IsValid(na)
+	}
 	if acceptUnroutable {
 		return !IsLocal(na)
 	}
@@ -221,8 +254,12 @@ func IsRoutable(na *appmessage.NetAddress, acceptUnroutable bool) bool {
 // GroupKey returns a string representing the network group an address is part
 // of. This is the /16 for IPv4, the /32 (/36 for he.net) for IPv6, the string
 // "local" for a local address, and the string "unroutable" for an unroutable
-// address.
+// address. Overlay addresses are grouped by their network and the first 4 bits
+// of the address, since they carry no information about the peer's location.
 func (am *AddressManager) GroupKey(na *appmessage.NetAddress) string {
+	if na.IsOverlay() && IsValid(na) {
+		return fmt.Sprintf("%s:%x", na.Network, na.Address[0]>>4)
+	}
 	if IsLocal(na) {
 		return "local"
 	}
@@ -203,4 +203,24 @@ func TestGroupKey(t *testing.T) {
 				key, test.expected)
 		}
 	}
+
+	overlayTests := []struct {
+		address  string
+		expected string
+	}{
+		{address: "pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion:16111", expected: "onion:7"},
+		{address: "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.onion:16111", expected: "onion:1"},
+		{address: "ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p:16111", expected: "i2p:a"},
+	}
+	for i, test := range overlayTests {
+		na, err := appmessage.ParseNetAddress(test.address)
+		if err != nil {
+			t.Fatalf("ParseNetAddress: %s", err)
+		}
+		if key := amgr.GroupKey(na); key != test.expected {
+			t.Errorf("TestGroupKey overlay #%d (%s): unexpected group key "+
+				"- got '%s', want '%s'", i, test.address,
+				key, test.expected)
+		}
+	}
 }
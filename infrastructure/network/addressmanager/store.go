@@ -15,14 +15,14 @@ var bannedAddressBucket = database.MakeBucket([]byte("banned-addresses"))
 type addressStore struct {
 	database           database.Database
 	notBannedAddresses map[addressKey]*address
-	bannedAddresses    map[ipv6]*address
+	bannedAddresses    map[hostKey]*address
 }
 
 func newAddressStore(database database.Database) (*addressStore, error) {
 	addressStore := &addressStore{
 		database:           database,
 		notBannedAddresses: map[addressKey]*address{},
-		bannedAddresses:    map[ipv6]*address{},
+		bannedAddresses:    map[hostKey]*address{},
 	}
 	err := addressStore.restoreNotBannedAddresses()
 	if err != nil {
@@ -74,15 +74,14 @@ func (as *addressStore) restoreBannedAddresses() error {
 		if err != nil {
 			return err
 		}
-		var ipv6 ipv6
-		copy(ipv6[:], databaseKey.Suffix())
+		key := as.deserializeHostKey(databaseKey.Suffix())
 
 		serializedNetAddress, err := cursor.Value()
 		if err != nil {
 			return err
 		}
 		netAddress := as.deserializeAddress(serializedNetAddress)
-		as.bannedAddresses[ipv6] = netAddress
+		as.bannedAddresses[key] = netAddress
 	}
 	return nil
 }
@@ -106,7 +105,7 @@ func (as *addressStore) add(key addressKey, address *address) error {
 // updateNotBanned updates the not-banned address collection
 func (as *addressStore) updateNotBanned(key addressKey, address *address) error {
 	if _, ok := as.notBannedAddresses[key]; !ok {
-		return errors.Errorf("address %s is not in the store", address.netAddress.TCPAddress())
+		return errors.Errorf("address %s is not in the store", address.netAddress)
 	}
 
 	as.notBannedAddresses[key] = address
@@ -162,11 +161,11 @@ func (as *addressStore) isNotBanned(key addressKey) bool {
 }
 
 func (as *addressStore) addBanned(key addressKey, address *address) error {
-	if _, ok := as.bannedAddresses[key.address]; ok {
+	if _, ok := as.bannedAddresses[key.host]; ok {
 		return nil
 	}
 
-	as.bannedAddresses[key.address] = address
+	as.bannedAddresses[key.host] = address
 
 	databaseKey := as.bannedDatabaseKey(key)
 	serializedAddress := as.serializeAddress(address)
@@ -174,7 +173,7 @@ func (as *addressStore) addBanned(key addressKey, address *address) error {
 }
 
 func (as *addressStore) removeBanned(key addressKey) error {
-	delete(as.bannedAddresses, key.address)
+	delete(as.bannedAddresses, key.host)
 
 	databaseKey := as.bannedDatabaseKey(key)
 	return as.database.Delete(databaseKey)
@@ -189,12 +188,12 @@ func (as *addressStore) getAllBannedNetAddresses() []*appmessage.NetAddress {
 }
 
 func (as *addressStore) isBanned(key addressKey) bool {
-	_, ok := as.bannedAddresses[key.address]
+	_, ok := as.bannedAddresses[key.host]
 	return ok
 }
 
 func (as *addressStore) getBanned(key addressKey) (*address, bool) {
-	bannedAddress, ok := as.bannedAddresses[key.address]
+	bannedAddress, ok := as.bannedAddresses[key.host]
 	return bannedAddress, ok
 }
 
@@ -214,33 +213,69 @@ func (as *addressStore) notBannedDatabaseKey(key addressKey) *database.Key {
 }
 
 func (as *addressStore) bannedDatabaseKey(key addressKey) *database.Key {
-	return bannedAddressBucket.Key(key.address[:])
+	return bannedAddressBucket.Key(as.serializeHostKey(key.host))
 }
 
-func (as *addressStore) serializeAddressKey(key addressKey) []byte {
-	serializedSize := 16 + 2 // ipv6 + port
+// serializeHostKey serializes IP hosts as their 16 bytes IPv6 representation, as
+// they were serialized before overlay networks were supported. Overlay hosts are
+// serialized as network + address, so their length never collides with IP hosts.
+func (as *addressStore) serializeHostKey(key hostKey) []byte {
+	if key.network == appmessage.NetAddressNetworkIP {
+		serializedKey := make([]byte, net.IPv6len)
+		copy(serializedKey, key.address[:])
+		return serializedKey
+	}
+
+	serializedSize := 1 + maxHostAddressLength // network + address
 	serializedKey := make([]byte, serializedSize)
+	serializedKey[0] = byte(key.network)
+	copy(serializedKey[1:], key.address[:])
+	return serializedKey
+}
 
-	copy(serializedKey[:], key.address[:])
-	binary.LittleEndian.PutUint16(serializedKey[16:], key.port)
+func (as *addressStore) deserializeHostKey(serializedKey []byte) hostKey {
+	var key hostKey
+	if len(serializedKey) == net.IPv6len {
+		copy(key.address[:], serializedKey)
+		return key
+	}
+
+	key.network = appmessage.NetAddressNetwork(serializedKey[0])
+	copy(key.address[:], serializedKey[1:])
+	return key
+}
+
+func (as *addressStore) serializeAddressKey(key addressKey) []byte {
+	serializedHostKey := as.serializeHostKey(key.host)
+	serializedKey := make([]byte, len(serializedHostKey)+2) // host + port
+
+	copy(serializedKey[:], serializedHostKey)
+	binary.LittleEndian.PutUint16(serializedKey[len(serializedHostKey):], key.port)
 
 	return serializedKey
 }
 
 func (as *addressStore) deserializeAddressKey(serializedKey []byte) addressKey {
-	var ip ipv6
-	copy(ip[:], serializedKey[:])
+	hostKeyLength := len(serializedKey) - 2
+	host := as.deserializeHostKey(serializedKey[:hostKeyLength])
 
-	port := binary.LittleEndian.Uint16(serializedKey[16:])
+	port := binary.LittleEndian.Uint16(serializedKey[hostKeyLength:])
 
 	return addressKey{
-		port:    port,
-		address: ip,
+		port: port,
+		host: host,
 	}
 }
 
+// serializedIPAddressSize is the size of a serialized IP address, which is also
+// the size of the common prefix of all serialized addresses
+const serializedIPAddressSize = 16 + 2 + 8 + 8 // ipv6 + port + timestamp + connectionFailedCount
+
 func (as *addressStore) serializeAddress(address *address) []byte {
-	serializedSize := 16 + 2 + 8 + 8 // ipv6 + port + timestamp + connectionFailedCount
+	serializedSize := serializedIPAddressSize
+	if address.netAddress.IsOverlay() {
+		serializedSize += 1 + len(address.netAddress.Address) // network + address
+	}
 	serializedNetAddress := make([]byte, serializedSize)
 
 	copy(serializedNetAddress[:], address.netAddress.IP.To16()[:])
@@ -248,23 +283,34 @@ func (as *addressStore) serializeAddress(address *address) []byte {
 	binary.LittleEndian.PutUint64(serializedNetAddress[18:], uint64(address.netAddress.Timestamp.UnixMilliseconds()))
 	binary.LittleEndian.PutUint64(serializedNetAddress[26:], uint64(address.connectionFailedCount))
 
+	if address.netAddress.IsOverlay() {
+		serializedNetAddress[serializedIPAddressSize] = byte(address.netAddress.Network)
+		copy(serializedNetAddress[serializedIPAddressSize+1:], address.netAddress.Address)
+	}
+
 	return serializedNetAddress
 }
 
 func (as *addressStore) deserializeAddress(serializedAddress []byte) *address {
-	ip := make(net.IP, 16)
-	copy(ip[:], serializedAddress[:])
-
 	port := binary.LittleEndian.Uint16(serializedAddress[16:])
 	timestamp := mstime.UnixMilliseconds(int64(binary.LittleEndian.Uint64(serializedAddress[18:])))
 	connectionFailedCount := binary.LittleEndian.Uint64(serializedAddress[26:])
 
+	netAddress := &appmessage.NetAddress{
+		Port:      port,
+		Timestamp: timestamp,
+	}
+	if len(serializedAddress) > serializedIPAddressSize {
+		netAddress.Network = appmessage.NetAddressNetwork(serializedAddress[serializedIPAddressSize])
+		netAddress.Address = make([]byte, len(serializedAddress)-serializedIPAddressSize-1)
+		copy(netAddress.Address, serializedAddress[serializedIPAddressSize+1:])
+	} else {
+		netAddress.IP = make(net.IP, 16)
+		copy(netAddress.IP[:], serializedAddress[:])
+	}
+
 	return &address{
-		netAddress: &appmessage.NetAddress{
-			IP:        ip,
-			Port:      port,
-			Timestamp: timestamp,
-		},
+		netAddress:            netAddress,
 		connectionFailedCount: connectionFailedCount,
 	}
 }
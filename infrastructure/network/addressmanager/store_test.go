@@ -45,3 +45,43 @@ func TestAddressSerialization(t *testing.T) {
 			"testAddress:%+v\ndeserializedTestAddress:%+v", testAddress, deserializedTestAddress)
 	}
 }
+
+func TestOverlayAddressSerialization(t *testing.T) {
+	addressManager, teardown := newAddressManagerForTest(t, "TestOverlayAddressSerialization")
+	defer teardown()
+	addressStore := addressManager.store
+
+	onionAddress, err := appmessage.ParseNetAddress("pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion:16111")
+	if err != nil {
+		t.Fatalf("ParseNetAddress: %s", err)
+	}
+	i2pAddress, err := appmessage.ParseNetAddress("ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p:16111")
+	if err != nil {
+		t.Fatalf("ParseNetAddress: %s", err)
+	}
+
+	for _, netAddress := range []*appmessage.NetAddress{onionAddress, i2pAddress} {
+		testAddressKey := netAddressKey(netAddress)
+		serializedTestAddressKey := addressStore.serializeAddressKey(testAddressKey)
+		deserializedTestAddressKey := addressStore.deserializeAddressKey(serializedTestAddressKey)
+		if !reflect.DeepEqual(testAddressKey, deserializedTestAddressKey) {
+			t.Fatalf("testAddressKey and deserializedTestAddressKey are not equal\n"+
+				"testAddressKey:%+v\ndeserializedTestAddressKey:%+v", testAddressKey, deserializedTestAddressKey)
+		}
+
+		testHostKey := netAddressHostKey(netAddress)
+		deserializedTestHostKey := addressStore.deserializeHostKey(addressStore.serializeHostKey(testHostKey))
+		if testHostKey != deserializedTestHostKey {
+			t.Fatalf("testHostKey and deserializedTestHostKey are not equal\n"+
+				"testHostKey:%+v\ndeserializedTestHostKey:%+v", testHostKey, deserializedTestHostKey)
+		}
+
+		testAddress := &address{netAddress: netAddress, connectionFailedCount: 3}
+		serializedTestAddress := addressStore.serializeAddress(testAddress)
+		deserializedTestAddress := addressStore.deserializeAddress(serializedTestAddress)
+		if !reflect.DeepEqual(testAddress, deserializedTestAddress) {
+			t.Fatalf("testAddress and deserializedTestAddress are not equal\n"+
+				"testAddress:%+v\ndeserializedTestAddress:%+v", testAddress, deserializedTestAddress)
+		}
+	}
+}
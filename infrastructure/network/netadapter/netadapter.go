@@ -42,7 +42,11 @@ func NewNetAdapter(cfg *config.Config) (*NetAdapter, error) {
 	if err != nil {
 		return nil, err
 	}
-	p2pServer, err := grpcserver.NewP2PServer(cfg.Listeners)
+	p2pSecurityConfig, err := newP2PSecurityConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p2pServer, err := grpcserver.NewP2PServer(cfg.Listeners, p2pSecurityConfig, newP2PDialFunc(cfg))
 	if err != nil {
 		return nil, err
 	}
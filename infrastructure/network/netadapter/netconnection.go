@@ -75,9 +75,20 @@ func (c *NetConnection) IsOutbound() bool {
 	return c.connection.IsOutbound()
 }
 
+// TransportSecurity returns how the traffic of this connection is protected
+func (c *NetConnection) TransportSecurity() server.TransportSecurity {
+	return c.connection.TransportSecurity()
+}
+
+// RemoteIdentityKey returns the identity key the remote peer authenticated
+// with, or nil if the connection is not encrypted
+func (c *NetConnection) RemoteIdentityKey() []byte {
+	return c.connection.RemoteIdentityKey()
+}
+
 // NetAddress returns the NetAddress associated with this connection
 func (c *NetConnection) NetAddress() *appmessage.NetAddress {
-	return appmessage.NewNetAddress(c.connection.Address())
+	return c.connection.Address()
 }
 
 func (c *NetConnection) setOnDisconnectedHandler(onDisconnectedHandler server.OnDisconnectedHandler) {
@@ -0,0 +1,54 @@
+package netadapter
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/Kash-Protocol/kashd/infrastructure/config"
+	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/server/grpcserver"
+	"github.com/pkg/errors"
+)
+
+// defaultP2PDialTimeout is used when the dial context has no deadline
+const defaultP2PDialTimeout = 30 * time.Second
+
+// newP2PDialFunc returns the function that dials P2P peers according to the given config:
+// onion and I2P addresses are routed through their respective proxies, and
+// everything else through the general purpose dial function, which may be a proxy as well
+func newP2PDialFunc(cfg *config.Config) grpcserver.DialFunc {
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		dial := cfg.Dial
+		if dial == nil {
+			dial = net.DialTimeout
+		}
+		if appmessage.IsOverlayHost(host) {
+			netAddress, err := appmessage.ParseNetAddress(address)
+			if err != nil {
+				return nil, err
+			}
+			switch netAddress.Network {
+			case appmessage.NetAddressNetworkTorV3:
+				dial = cfg.OnionDial
+			case appmessage.NetAddressNetworkI2P:
+				dial = cfg.I2PDial
+			}
+			if dial == nil {
+				return nil, errors.Errorf("cannot connect to %s: no proxy was configured for %s addresses",
+					address, netAddress.Network)
+			}
+		}
+
+		timeout := defaultP2PDialTimeout
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+		return dial("tcp", address, timeout)
+	}
+}
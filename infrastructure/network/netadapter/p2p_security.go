@@ -0,0 +1,97 @@
+package netadapter
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+
+	"github.com/Kash-Protocol/kashd/infrastructure/config"
+	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/server/grpcserver"
+	"github.com/pkg/errors"
+)
+
+const identityKeyPEMType = "PRIVATE KEY"
+
+// newP2PSecurityConfig builds the P2P transport security configuration out of the given config
+func newP2PSecurityConfig(cfg *config.Config) (*grpcserver.P2PSecurityConfig, error) {
+	if cfg.P2PEncryption == config.P2PEncryptionDisabled {
+		log.Warnf("P2P encryption is disabled. Traffic with all peers will be sent in plaintext")
+		return &grpcserver.P2PSecurityConfig{}, nil
+	}
+
+	identityKey, err := loadOrCreateIdentityKey(cfg.P2PIdentityKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("P2P identity key: %s", hex.EncodeToString(identityKey.Public().(ed25519.PublicKey)))
+
+	pinnedKeys := make(map[string]ed25519.PublicKey, len(cfg.PinnedPeerKeys))
+	for address, key := range cfg.PinnedPeerKeys {
+		pinnedKeys[address] = key
+	}
+
+	return &grpcserver.P2PSecurityConfig{
+		IdentityKey:       identityKey,
+		RequireEncryption: cfg.P2PEncryption == config.P2PEncryptionRequired,
+		PinnedKeys:        pinnedKeys,
+	}, nil
+}
+
+// loadOrCreateIdentityKey loads the identity key stored in the given file, creating
+// it if it doesn't exist yet. An empty path means that the node has no persistent
+// identity, in which case an ephemeral key is generated.
+func loadOrCreateIdentityKey(path string) (ed25519.PrivateKey, error) {
+	if path == "" {
+		_, identityKey, err := ed25519.GenerateKey(rand.Reader)
+		return identityKey, errors.WithStack(err)
+	}
+
+	pemBytes, err := os.ReadFile(path)
+	if err == nil {
+		return parseIdentityKey(path, pemBytes)
+	}
+	if !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "error reading the P2P identity key from %s", path)
+	}
+
+	_, identityKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(identityKey)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	pemBytes = pem.EncodeToMemory(&pem.Block{Type: identityKeyPEMType, Bytes: keyBytes})
+	err = os.WriteFile(path, pemBytes, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error writing the P2P identity key to %s", path)
+	}
+	log.Infof("Created a new P2P identity key in %s", path)
+
+	return identityKey, nil
+}
+
+func parseIdentityKey(path string, pemBytes []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil || block.Type != identityKeyPEMType {
+		return nil, errors.Errorf("%s does not contain a PEM-encoded private key", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing the P2P identity key in %s", path)
+	}
+	identityKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.Errorf("the P2P identity key in %s is of unsupported type %T", path, key)
+	}
+	return identityKey, nil
+}
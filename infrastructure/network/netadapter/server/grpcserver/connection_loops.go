@@ -3,7 +3,9 @@ package grpcserver
 import (
 	"github.com/Kash-Protocol/kashd/app/appmessage"
 	"github.com/Kash-Protocol/kashd/infrastructure/logger"
+	"github.com/Kash-Protocol/kashd/infrastructure/metrics"
 	"github.com/davecgh/go-spew/spew"
+	"google.golang.org/protobuf/proto"
 	"io"
 	"os"
 	"strconv"
@@ -66,6 +68,7 @@ func (c *gRPCConnection) sendLoop() error {
 		if err != nil {
 			return err
 		}
+		metrics.AddMessage(c.server.name, message.Command().Name(), proto.Size(messageProto), true)
 
 		err = c.send(messageProto)
 		if err != nil {
@@ -93,6 +96,8 @@ func (c *gRPCConnection) receiveLoop() error {
 			return err
 		}
 
+		metrics.AddMessage(c.server.name, message.Command().Name(), proto.Size(protoMessage), false)
+
 		messageNumber++
 		message.SetMessageNumber(messageNumber)
 		message.SetReceivedAt(time.Now())
@@ -1,10 +1,10 @@
 package grpcserver
 
 import (
-	"net"
 	"sync"
 	"sync/atomic"
 
+	"github.com/Kash-Protocol/kashd/app/appmessage"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/router"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/server/grpcserver/protowire"
 	"github.com/pkg/errors"
@@ -17,10 +17,12 @@ import (
 
 type gRPCConnection struct {
 	server                   *gRPCServer
-	address                  *net.TCPAddr
+	address                  *appmessage.NetAddress
 	stream                   grpcStream
 	router                   *router.Router
 	lowLevelClientConnection *grpc.ClientConn
+	transportSecurity        server.TransportSecurity
+	remoteIdentityKey        []byte
 
 	// streamLock protects concurrent access to stream.
 	// Note that it's an RWMutex. Despite what the name
@@ -41,7 +43,7 @@ type grpcStream interface {
 	Recv() (*protowire.KashdMessage, error)
 }
 
-func newConnection(server *gRPCServer, address *net.TCPAddr, stream grpcStream,
+func newConnection(server *gRPCServer, address *appmessage.NetAddress, stream grpcStream,
 	lowLevelClientConnection *grpc.ClientConn) *gRPCConnection {
 	connection := &gRPCConnection{
 		server:                   server,
@@ -123,10 +125,25 @@ func (c *gRPCConnection) Disconnect() {
 	}
 }
 
-func (c *gRPCConnection) Address() *net.TCPAddr {
+func (c *gRPCConnection) Address() *appmessage.NetAddress {
 	return c.address
 }
 
+// TransportSecurity returns how the traffic of this connection is protected
+//
+// This is part of the Connection interface
+func (c *gRPCConnection) TransportSecurity() server.TransportSecurity {
+	return c.transportSecurity
+}
+
+// RemoteIdentityKey returns the identity key the remote peer authenticated
+// with, or nil if the connection is not encrypted
+//
+// This is part of the Connection interface
+func (c *gRPCConnection) RemoteIdentityKey() []byte {
+	return c.remoteIdentityKey
+}
+
 func (c *gRPCConnection) receive() (*protowire.KashdMessage, error) {
 	// We use RLock here and in send() because they can work
 	// in parallel. closeSend(), however, must not have either
@@ -3,6 +3,7 @@ package grpcserver
 import (
 	"context"
 	"fmt"
+	"github.com/Kash-Protocol/kashd/app/appmessage"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/server"
 	"github.com/Kash-Protocol/kashd/util/panics"
 	"github.com/pkg/errors"
@@ -25,10 +26,13 @@ type gRPCServer struct {
 }
 
 // newGRPCServer creates a gRPC server
-func newGRPCServer(listeningAddresses []string, maxMessageSize int, maxInboundConnections int, name string) *gRPCServer {
+func newGRPCServer(listeningAddresses []string, maxMessageSize int, maxInboundConnections int, name string,
+	serverOptions ...grpc.ServerOption) *gRPCServer {
+
 	log.Debugf("Created new %s GRPC server with maxMessageSize %d and maxInboundConnections %d", name, maxMessageSize, maxInboundConnections)
+	serverOptions = append(serverOptions, grpc.MaxRecvMsgSize(maxMessageSize), grpc.MaxSendMsgSize(maxMessageSize))
 	return &gRPCServer{
-		server:                     grpc.NewServer(grpc.MaxRecvMsgSize(maxMessageSize), grpc.MaxSendMsgSize(maxMessageSize)),
+		server:                     grpc.NewServer(serverOptions...),
 		listeningAddresses:         listeningAddresses,
 		name:                       name,
 		maxInboundConnections:      maxInboundConnections,
@@ -109,14 +113,15 @@ func (s *gRPCServer) handleInboundConnection(ctx context.Context, stream grpcStr
 		return errors.Errorf("non-tcp connections are not supported")
 	}
 
-	connection := newConnection(s, tcpAddress, stream, nil)
+	connection := newConnection(s, appmessage.NewNetAddress(tcpAddress), stream, nil)
+	connection.transportSecurity, connection.remoteIdentityKey = transportSecurityFromPeer(peerInfo)
 
 	err = s.onConnectedHandler(connection)
 	if err != nil {
 		return err
 	}
 
-	log.Infof("%s Incoming connection from %s #%d", s.name, peerInfo.Addr, connectionCount)
+	log.Infof("%s Incoming %s connection from %s #%d", s.name, connection.transportSecurity, peerInfo.Addr, connectionCount)
 
 	<-connection.stopChan
 	return nil
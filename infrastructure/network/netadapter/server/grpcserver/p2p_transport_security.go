@@ -0,0 +1,292 @@
+package grpcserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/server"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// P2PSecurityConfig configures the encryption of P2P connections
+type P2PSecurityConfig struct {
+	// IdentityKey is the key this node authenticates itself with to its peers.
+	// A nil IdentityKey disables P2P encryption altogether.
+	IdentityKey ed25519.PrivateKey
+
+	// RequireEncryption makes this node refuse plaintext connections,
+	// both inbound and outbound
+	RequireEncryption bool
+
+	// PinnedKeys maps peer addresses to the identity key they are
+	// expected to present. Connections to pinned peers never fall back
+	// to plaintext.
+	PinnedKeys map[string]ed25519.PublicKey
+}
+
+// tlsRecordTypeHandshake is the first byte of every TLS ClientHello. A plaintext
+// gRPC client always starts with the HTTP/2 connection preface ("PRI * HTTP/2.0")
+// instead, which is how the server tells the two apart.
+const tlsRecordTypeHandshake = 0x16
+
+// p2pAuthInfo is the credentials.AuthInfo attached to every P2P connection
+type p2pAuthInfo struct {
+	credentials.CommonAuthInfo
+	transportSecurity server.TransportSecurity
+	remoteIdentityKey ed25519.PublicKey
+}
+
+func (info *p2pAuthInfo) AuthType() string {
+	return string(info.transportSecurity)
+}
+
+// handshakeError is returned when a handshake fails in a way that retrying the
+// same handshake won't fix. It tells gRPC to give up dialing immediately, so
+// that we may fall back to a plaintext connection.
+type handshakeError struct {
+	error
+}
+
+func (e handshakeError) Temporary() bool {
+	return false
+}
+
+// p2pTransportCredentials implements credentials.TransportCredentials. On the
+// server side it accepts both TLS and plaintext connections on the same port,
+// and on the client side it performs a TLS handshake, or passes the connection
+// through untouched if it was created for a legacy plaintext peer.
+type p2pTransportCredentials struct {
+	securityConfig *P2PSecurityConfig
+	certificate    tls.Certificate
+	isPlaintext    bool
+	pinnedKey      ed25519.PublicKey
+}
+
+func newP2PTransportCredentials(securityConfig *P2PSecurityConfig) (*p2pTransportCredentials, error) {
+	certificate, err := selfSignedCertificate(securityConfig.IdentityKey)
+	if err != nil {
+		return nil, err
+	}
+	return &p2pTransportCredentials{
+		securityConfig: securityConfig,
+		certificate:    certificate,
+	}, nil
+}
+
+// selfSignedCertificate creates a short-lived certificate for the given identity key.
+// The certificate itself carries no trust - peers authenticate each other by the
+// identity key within it, which TLS 1.3 proves ownership of during the handshake.
+func selfSignedCertificate(identityKey ed25519.PrivateKey) (tls.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, errors.WithStack(err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: "kashd"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	publicKey := identityKey.Public()
+	certificateBytes, err := x509.CreateCertificate(rand.Reader, template, template, publicKey, identityKey)
+	if err != nil {
+		return tls.Certificate{}, errors.WithStack(err)
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{certificateBytes},
+		PrivateKey:  identityKey,
+	}, nil
+}
+
+// identityKeyFromCertificates extracts the identity key out of the certificate
+// presented by the remote peer
+func identityKeyFromCertificates(rawCertificates [][]byte) (ed25519.PublicKey, error) {
+	if len(rawCertificates) == 0 {
+		return nil, errors.New("the remote peer did not present an identity certificate")
+	}
+	certificate, err := x509.ParseCertificate(rawCertificates[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing the remote peer's identity certificate")
+	}
+	identityKey, ok := certificate.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.Errorf("unsupported identity key type %T", certificate.PublicKey)
+	}
+	return identityKey, nil
+}
+
+func (c *p2pTransportCredentials) tlsConfig() *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{c.certificate},
+		MinVersion:   tls.VersionTLS13,
+		// Peers use self-signed certificates, so there's no chain of trust to verify.
+		// Instead, the identity key is checked against the pinned key (if any) in
+		// verifyPeerCertificate.
+		InsecureSkipVerify:    true,
+		ClientAuth:            tls.RequireAnyClientCert,
+		VerifyPeerCertificate: c.verifyPeerCertificate,
+	}
+}
+
+func (c *p2pTransportCredentials) verifyPeerCertificate(rawCertificates [][]byte, _ [][]*x509.Certificate) error {
+	identityKey, err := identityKeyFromCertificates(rawCertificates)
+	if err != nil {
+		return err
+	}
+	if c.pinnedKey != nil && !bytes.Equal(identityKey, c.pinnedKey) {
+		return errors.Errorf("the remote peer's identity key %s does not match the pinned key %s",
+			hex.EncodeToString(identityKey), hex.EncodeToString(c.pinnedKey))
+	}
+	return nil
+}
+
+// ClientHandshake implements credentials.TransportCredentials
+func (c *p2pTransportCredentials) ClientHandshake(ctx context.Context, _ string, rawConn net.Conn) (
+	net.Conn, credentials.AuthInfo, error) {
+
+	if c.isPlaintext {
+		return rawConn, c.plaintextAuthInfo(), nil
+	}
+
+	tlsConn := tls.Client(rawConn, c.tlsConfig())
+	err := tlsConn.HandshakeContext(ctx)
+	if err != nil {
+		return nil, nil, handshakeError{errors.Wrap(err, "TLS handshake failed")}
+	}
+	return tlsConn, c.tlsAuthInfo(tlsConn), nil
+}
+
+// ServerHandshake implements credentials.TransportCredentials
+func (c *p2pTransportCredentials) ServerHandshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	conn := newPeekableConn(rawConn)
+	firstByte, err := conn.reader.Peek(1)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error reading the first byte of an incoming connection")
+	}
+
+	if firstByte[0] != tlsRecordTypeHandshake {
+		if c.securityConfig.RequireEncryption {
+			return nil, nil, errors.Errorf("rejecting plaintext connection from %s: "+
+				"P2P encryption is required", rawConn.RemoteAddr())
+		}
+		return conn, c.plaintextAuthInfo(), nil
+	}
+
+	tlsConn := tls.Server(conn, c.tlsConfig())
+	err = tlsConn.Handshake()
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "TLS handshake with %s failed", rawConn.RemoteAddr())
+	}
+	return tlsConn, c.tlsAuthInfo(tlsConn), nil
+}
+
+func (c *p2pTransportCredentials) plaintextAuthInfo() *p2pAuthInfo {
+	return &p2pAuthInfo{
+		CommonAuthInfo:    credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity},
+		transportSecurity: server.TransportSecurityPlaintext,
+	}
+}
+
+func (c *p2pTransportCredentials) tlsAuthInfo(tlsConn *tls.Conn) *p2pAuthInfo {
+	// The identity key had already been validated within verifyPeerCertificate,
+	// so it's safe to ignore the error here
+	identityKey, _ := identityKeyFromCertificates(rawCertificates(tlsConn.ConnectionState()))
+	return &p2pAuthInfo{
+		CommonAuthInfo:    credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity},
+		transportSecurity: server.TransportSecurityTLS,
+		remoteIdentityKey: identityKey,
+	}
+}
+
+func rawCertificates(state tls.ConnectionState) [][]byte {
+	rawCertificates := make([][]byte, len(state.PeerCertificates))
+	for i, certificate := range state.PeerCertificates {
+		rawCertificates[i] = certificate.Raw
+	}
+	return rawCertificates
+}
+
+// Info implements credentials.TransportCredentials
+func (c *p2pTransportCredentials) Info() credentials.ProtocolInfo {
+	securityProtocol := string(server.TransportSecurityTLS)
+	if c.isPlaintext {
+		securityProtocol = string(server.TransportSecurityPlaintext)
+	}
+	return credentials.ProtocolInfo{SecurityProtocol: securityProtocol}
+}
+
+// Clone implements credentials.TransportCredentials
+func (c *p2pTransportCredentials) Clone() credentials.TransportCredentials {
+	clone := *c
+	return &clone
+}
+
+// OverrideServerName implements credentials.TransportCredentials
+func (c *p2pTransportCredentials) OverrideServerName(string) error {
+	return nil
+}
+
+// forAddress returns the client credentials to dial the given address with
+func (c *p2pTransportCredentials) forAddress(address string) *p2pTransportCredentials {
+	clone := *c
+	clone.pinnedKey = c.securityConfig.PinnedKeys[address]
+	return &clone
+}
+
+// plaintext returns the client credentials to dial legacy plaintext peers with
+func (c *p2pTransportCredentials) plaintext() *p2pTransportCredentials {
+	clone := *c
+	clone.isPlaintext = true
+	return &clone
+}
+
+// canFallBackToPlaintext returns whether an outbound connection to the given
+// address may be retried in plaintext after a failed TLS handshake
+func (c *p2pTransportCredentials) canFallBackToPlaintext(address string) bool {
+	_, isPinned := c.securityConfig.PinnedKeys[address]
+	return !isPinned && !c.securityConfig.RequireEncryption
+}
+
+// transportSecurityFromPeer returns the transport security and the remote identity key of the given gRPC peer
+func transportSecurityFromPeer(peerInfo *peer.Peer) (server.TransportSecurity, ed25519.PublicKey) {
+	authInfo, ok := peerInfo.AuthInfo.(*p2pAuthInfo)
+	if !ok {
+		return server.TransportSecurityPlaintext, nil
+	}
+	return authInfo.transportSecurity, authInfo.remoteIdentityKey
+}
+
+// peekableConn is a net.Conn that allows peeking into the incoming data
+// before it is handed over to either TLS or gRPC
+type peekableConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func newPeekableConn(conn net.Conn) *peekableConn {
+	return &peekableConn{
+		Conn:   conn,
+		reader: bufio.NewReader(conn),
+	}
+}
+
+func (c *peekableConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
@@ -0,0 +1,173 @@
+package grpcserver
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/server"
+)
+
+func newTestIdentityKey(t *testing.T) ed25519.PrivateKey {
+	_, identityKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %+v", err)
+	}
+	return identityKey
+}
+
+func startTestP2PServer(t *testing.T, address string, securityConfig *P2PSecurityConfig) (
+	server.P2PServer, chan server.Connection) {
+
+	p2pServer, err := NewP2PServer([]string{address}, securityConfig, nil)
+	if err != nil {
+		t.Fatalf("NewP2PServer: %+v", err)
+	}
+	inboundConnections := make(chan server.Connection, 1)
+	p2pServer.SetOnConnectedHandler(func(connection server.Connection) error {
+		if !connection.IsOutbound() {
+			inboundConnections <- connection
+		}
+		return nil
+	})
+	err = p2pServer.Start()
+	if err != nil {
+		t.Fatalf("Start: %+v", err)
+	}
+	t.Cleanup(func() { p2pServer.Stop() })
+	return p2pServer, inboundConnections
+}
+
+func TestP2PTransportSecurity(t *testing.T) {
+	serverKey := newTestIdentityKey(t)
+	clientKey := newTestIdentityKey(t)
+	otherKey := newTestIdentityKey(t)
+
+	tests := []struct {
+		name                      string
+		serverConfig              *P2PSecurityConfig
+		clientConfig              func(serverAddress string) *P2PSecurityConfig
+		expectedError             bool
+		expectedTransportSecurity server.TransportSecurity
+	}{
+		{
+			name:         "both encrypted",
+			serverConfig: &P2PSecurityConfig{IdentityKey: serverKey},
+			clientConfig: func(string) *P2PSecurityConfig {
+				return &P2PSecurityConfig{IdentityKey: clientKey}
+			},
+			expectedTransportSecurity: server.TransportSecurityTLS,
+		},
+		{
+			name:         "legacy client",
+			serverConfig: &P2PSecurityConfig{IdentityKey: serverKey},
+			clientConfig: func(string) *P2PSecurityConfig {
+				return &P2PSecurityConfig{}
+			},
+			expectedTransportSecurity: server.TransportSecurityPlaintext,
+		},
+		{
+			name:         "legacy server",
+			serverConfig: &P2PSecurityConfig{},
+			clientConfig: func(string) *P2PSecurityConfig {
+				return &P2PSecurityConfig{IdentityKey: clientKey}
+			},
+			expectedTransportSecurity: server.TransportSecurityPlaintext,
+		},
+		{
+			name:         "server requires encryption from a legacy client",
+			serverConfig: &P2PSecurityConfig{IdentityKey: serverKey, RequireEncryption: true},
+			clientConfig: func(string) *P2PSecurityConfig {
+				return &P2PSecurityConfig{}
+			},
+			expectedError: true,
+		},
+		{
+			name:         "client requires encryption from a legacy server",
+			serverConfig: &P2PSecurityConfig{},
+			clientConfig: func(string) *P2PSecurityConfig {
+				return &P2PSecurityConfig{IdentityKey: clientKey, RequireEncryption: true}
+			},
+			expectedError: true,
+		},
+		{
+			name:         "pinned key matches",
+			serverConfig: &P2PSecurityConfig{IdentityKey: serverKey},
+			clientConfig: func(serverAddress string) *P2PSecurityConfig {
+				return &P2PSecurityConfig{
+					IdentityKey: clientKey,
+					PinnedKeys:  map[string]ed25519.PublicKey{serverAddress: serverKey.Public().(ed25519.PublicKey)},
+				}
+			},
+			expectedTransportSecurity: server.TransportSecurityTLS,
+		},
+		{
+			name:         "pinned key mismatches",
+			serverConfig: &P2PSecurityConfig{IdentityKey: serverKey},
+			clientConfig: func(serverAddress string) *P2PSecurityConfig {
+				return &P2PSecurityConfig{
+					IdentityKey: clientKey,
+					PinnedKeys:  map[string]ed25519.PublicKey{serverAddress: otherKey.Public().(ed25519.PublicKey)},
+				}
+			},
+			expectedError: true,
+		},
+		{
+			name:         "pinned peer is a legacy server",
+			serverConfig: &P2PSecurityConfig{},
+			clientConfig: func(serverAddress string) *P2PSecurityConfig {
+				return &P2PSecurityConfig{
+					IdentityKey: clientKey,
+					PinnedKeys:  map[string]ed25519.PublicKey{serverAddress: serverKey.Public().(ed25519.PublicKey)},
+				}
+			},
+			expectedError: true,
+		},
+	}
+
+	for i, test := range tests {
+		serverAddress := fmt.Sprintf("127.0.0.1:%d", 3100+2*i)
+		clientAddress := fmt.Sprintf("127.0.0.1:%d", 3101+2*i)
+
+		_, inboundConnections := startTestP2PServer(t, serverAddress, test.serverConfig)
+		clientConfig := test.clientConfig(serverAddress)
+		client, _ := startTestP2PServer(t, clientAddress, clientConfig)
+
+		outboundConnection, err := client.Connect(serverAddress)
+		if test.expectedError {
+			if err == nil {
+				t.Fatalf("%s: expected an error but got none", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: Connect: %+v", test.name, err)
+		}
+		inboundConnection := <-inboundConnections
+
+		if outboundConnection.TransportSecurity() != test.expectedTransportSecurity {
+			t.Fatalf("%s: expected outbound transport security %s but got %s", test.name,
+				test.expectedTransportSecurity, outboundConnection.TransportSecurity())
+		}
+		if inboundConnection.TransportSecurity() != test.expectedTransportSecurity {
+			t.Fatalf("%s: expected inbound transport security %s but got %s", test.name,
+				test.expectedTransportSecurity, inboundConnection.TransportSecurity())
+		}
+
+		if test.expectedTransportSecurity == server.TransportSecurityTLS {
+			if !bytes.Equal(outboundConnection.RemoteIdentityKey(), test.serverConfig.IdentityKey.Public().(ed25519.PublicKey)) {
+				t.Fatalf("%s: the outbound connection has an unexpected remote identity key", test.name)
+			}
+			if !bytes.Equal(inboundConnection.RemoteIdentityKey(), clientConfig.IdentityKey.Public().(ed25519.PublicKey)) {
+				t.Fatalf("%s: the inbound connection has an unexpected remote identity key", test.name)
+			}
+		} else if outboundConnection.RemoteIdentityKey() != nil || inboundConnection.RemoteIdentityKey() != nil {
+			t.Fatalf("%s: plaintext connections are not expected to have remote identity keys", test.name)
+		}
+
+		outboundConnection.Disconnect()
+		inboundConnection.Disconnect()
+	}
+}
@@ -2,11 +2,14 @@ package grpcserver
 
 import (
 	"context"
+	"github.com/Kash-Protocol/kashd/app/appmessage"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/server"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/server/grpcserver/protowire"
 	"github.com/Kash-Protocol/kashd/util/panics"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/peer"
 	"net"
@@ -16,8 +19,18 @@ import (
 type p2pServer struct {
 	protowire.UnimplementedP2PServer
 	gRPCServer
+
+	// credentials is nil when P2P encryption is disabled
+	credentials *p2pTransportCredentials
+
+	// dialFunc is nil when peers are dialed directly
+	dialFunc DialFunc
 }
 
+// DialFunc opens the underlying network connection to the given peer address,
+// for example through a SOCKS5 proxy
+type DialFunc func(ctx context.Context, address string) (net.Conn, error)
+
 const p2pMaxMessageSize = 1024 * 1024 * 1024 // 1GB
 
 // p2pMaxInboundConnections is the max amount of inbound connections for the P2P server.
@@ -26,10 +39,23 @@ const p2pMaxMessageSize = 1024 * 1024 * 1024 // 1GB
 // is handled in the ConnectionManager instead.
 const p2pMaxInboundConnections = 0
 
-// NewP2PServer creates a new P2PServer
-func NewP2PServer(listeningAddresses []string) (server.P2PServer, error) {
-	gRPCServer := newGRPCServer(listeningAddresses, p2pMaxMessageSize, p2pMaxInboundConnections, "P2P")
-	p2pServer := &p2pServer{gRPCServer: *gRPCServer}
+// NewP2PServer creates a new P2PServer. If dialFunc is nil, peers are dialed directly.
+func NewP2PServer(listeningAddresses []string, securityConfig *P2PSecurityConfig,
+	dialFunc DialFunc) (server.P2PServer, error) {
+
+	var credentials *p2pTransportCredentials
+	var serverOptions []grpc.ServerOption
+	if securityConfig.IdentityKey != nil {
+		var err error
+		credentials, err = newP2PTransportCredentials(securityConfig)
+		if err != nil {
+			return nil, err
+		}
+		serverOptions = append(serverOptions, grpc.Creds(credentials))
+	}
+
+	gRPCServer := newGRPCServer(listeningAddresses, p2pMaxMessageSize, p2pMaxInboundConnections, "P2P", serverOptions...)
+	p2pServer := &p2pServer{gRPCServer: *gRPCServer, credentials: credentials, dialFunc: dialFunc}
 	protowire.RegisterP2PServer(gRPCServer.server, p2pServer)
 	return p2pServer, nil
 }
@@ -45,11 +71,7 @@ func (p *p2pServer) MessageStream(stream protowire.P2P_MessageStreamServer) erro
 func (p *p2pServer) Connect(address string) (server.Connection, error) {
 	log.Debugf("%s Dialing to %s", p.name, address)
 
-	const dialTimeout = 1 * time.Second
-	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
-	defer cancel()
-
-	gRPCClientConnection, err := grpc.DialContext(ctx, address, grpc.WithInsecure(), grpc.WithBlock())
+	gRPCClientConnection, err := p.dial(address)
 	if err != nil {
 		return nil, errors.Wrapf(err, "%s error connecting to %s", p.name, address)
 	}
@@ -65,19 +87,73 @@ func (p *p2pServer) Connect(address string) (server.Connection, error) {
 	if !ok {
 		return nil, errors.Errorf("%s error getting stream peer info from context for %s", p.name, address)
 	}
-	tcpAddress, ok := peerInfo.Addr.(*net.TCPAddr)
-	if !ok {
-		return nil, errors.Errorf("non-tcp addresses are not supported")
+	netAddress, err := remoteNetAddress(peerInfo, address)
+	if err != nil {
+		return nil, err
 	}
 
-	connection := newConnection(&p.gRPCServer, tcpAddress, stream, gRPCClientConnection)
+	connection := newConnection(&p.gRPCServer, netAddress, stream, gRPCClientConnection)
+	connection.transportSecurity, connection.remoteIdentityKey = transportSecurityFromPeer(peerInfo)
 
 	err = p.onConnectedHandler(connection)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Infof("%s Connected to %s", p.name, address)
+	log.Infof("%s Connected to %s over %s", p.name, address, connection.transportSecurity)
 
 	return connection, nil
 }
+
+// dial opens a gRPC client connection to the given address. If P2P encryption
+// is enabled, it first attempts a TLS handshake, and falls back to plaintext
+// for legacy peers when allowed to.
+func (p *p2pServer) dial(address string) (*grpc.ClientConn, error) {
+	if p.credentials == nil {
+		return p.dialWithCredentials(address, insecure.NewCredentials())
+	}
+
+	addressCredentials := p.credentials.forAddress(address)
+	gRPCClientConnection, err := p.dialWithCredentials(address, addressCredentials)
+	if err == nil {
+		return gRPCClientConnection, nil
+	}
+
+	var handshakeErr handshakeError
+	if !errors.As(err, &handshakeErr) || !addressCredentials.canFallBackToPlaintext(address) {
+		return nil, err
+	}
+
+	log.Debugf("%s Could not negotiate encryption with %s, falling back to plaintext: %s", p.name, address, err)
+	return p.dialWithCredentials(address, addressCredentials.plaintext())
+}
+
+func (p *p2pServer) dialWithCredentials(address string,
+	transportCredentials credentials.TransportCredentials) (*grpc.ClientConn, error) {
+
+	const dialTimeout = 1 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(transportCredentials),
+		grpc.WithBlock(), grpc.FailOnNonTempDialError(true)}
+	if p.dialFunc != nil {
+		dialOptions = append(dialOptions, grpc.WithContextDialer(p.dialFunc))
+	}
+	return grpc.DialContext(ctx, address, dialOptions...)
+}
+
+// remoteNetAddress returns the address of the peer connected to over the given
+// dialed address. Connections through a proxy don't expose the remote TCP
+// address, in which case the dialed address itself is used.
+func remoteNetAddress(peerInfo *peer.Peer, address string) (*appmessage.NetAddress, error) {
+	tcpAddress, ok := peerInfo.Addr.(*net.TCPAddr)
+	if ok {
+		return appmessage.NewNetAddress(tcpAddress), nil
+	}
+	netAddress, err := appmessage.ParseNetAddress(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unsupported remote address %s", peerInfo.Addr)
+	}
+	return netAddress, nil
+}
@@ -103,9 +103,14 @@ func (x *NetAddress) toAppMessage() (*appmessage.NetAddress, error) {
 	if x.Port > math.MaxUint16 {
 		return nil, errors.Errorf("port number is larger than %d", math.MaxUint16)
 	}
+	if x.Network > math.MaxUint8 {
+		return nil, errors.Errorf("network ID is larger than %d", math.MaxUint8)
+	}
 	return &appmessage.NetAddress{
 		Timestamp: mstime.UnixMilliseconds(x.Timestamp),
+		Network:   appmessage.NetAddressNetwork(x.Network),
 		IP:        x.Ip,
+		Address:   x.Address,
 		Port:      uint16(x.Port),
 	}, nil
 }
@@ -113,7 +118,9 @@ func (x *NetAddress) toAppMessage() (*appmessage.NetAddress, error) {
 func appMessageNetAddressToProto(address *appmessage.NetAddress) *NetAddress {
 	return &NetAddress{
 		Timestamp: address.Timestamp.UnixMilliseconds(),
+		Network:   uint32(address.Network),
 		Ip:        address.IP,
+		Address:   address.Address,
 		Port:      uint32(address.Port),
 	}
 }
@@ -156,6 +156,10 @@ type KashdMessage struct {
 	//	*KashdMessage_GetMempoolEntriesByAddressesResponse
 	//	*KashdMessage_GetCoinSupplyRequest
 	//	*KashdMessage_GetCoinSupplyResponse
+	//	*KashdMessage_GetLogLevelsRequest
+	//	*KashdMessage_GetLogLevelsResponse
+	//	*KashdMessage_SetLogLevelRequest
+	//	*KashdMessage_SetLogLevelResponse
 	Payload isKashdMessage_Payload `protobuf_oneof:"payload"`
 }
 
@@ -1108,6 +1112,34 @@ func (x *KashdMessage) GetGetCoinSupplyResponse() *GetCoinSupplyResponseMessage
 	return nil
 }
 
+func (x *KashdMessage) GetGetLogLevelsRequest() *GetLogLevelsRequestMessage {
+	if x, ok := x.GetPayload().(*KashdMessage_GetLogLevelsRequest); ok {
+		return x.GetLogLevelsRequest
+	}
+	return nil
+}
+
+func (x *KashdMessage) GetGetLogLevelsResponse() *GetLogLevelsResponseMessage {
+	if x, ok := x.GetPayload().(*KashdMessage_GetLogLevelsResponse); ok {
+		return x.GetLogLevelsResponse
+	}
+	return nil
+}
+
+func (x *KashdMessage) GetSetLogLevelRequest() *SetLogLevelRequestMessage {
+	if x, ok := x.GetPayload().(*KashdMessage_SetLogLevelRequest); ok {
+		return x.SetLogLevelRequest
+	}
+	return nil
+}
+
+func (x *KashdMessage) GetSetLogLevelResponse() *SetLogLevelResponseMessage {
+	if x, ok := x.GetPayload().(*KashdMessage_SetLogLevelResponse); ok {
+		return x.SetLogLevelResponse
+	}
+	return nil
+}
+
 type isKashdMessage_Payload interface {
 	isKashdMessage_Payload()
 }
@@ -1632,6 +1664,22 @@ type KashdMessage_GetCoinSupplyResponse struct {
 	GetCoinSupplyResponse *GetCoinSupplyResponseMessage `protobuf:"bytes,1087,opt,name=getCoinSupplyResponse,proto3,oneof"`
 }
 
+type KashdMessage_GetLogLevelsRequest struct {
+	GetLogLevelsRequest *GetLogLevelsRequestMessage `protobuf:"bytes,1088,opt,name=getLogLevelsRequest,proto3,oneof"`
+}
+
+type KashdMessage_GetLogLevelsResponse struct {
+	GetLogLevelsResponse *GetLogLevelsResponseMessage `protobuf:"bytes,1089,opt,name=getLogLevelsResponse,proto3,oneof"`
+}
+
+type KashdMessage_SetLogLevelRequest struct {
+	SetLogLevelRequest *SetLogLevelRequestMessage `protobuf:"bytes,1090,opt,name=setLogLevelRequest,proto3,oneof"`
+}
+
+type KashdMessage_SetLogLevelResponse struct {
+	SetLogLevelResponse *SetLogLevelResponseMessage `protobuf:"bytes,1091,opt,name=setLogLevelResponse,proto3,oneof"`
+}
+
 func (*KashdMessage_Addresses) isKashdMessage_Payload() {}
 
 func (*KashdMessage_Block) isKashdMessage_Payload() {}
@@ -1892,13 +1940,21 @@ func (*KashdMessage_GetCoinSupplyRequest) isKashdMessage_Payload() {}
 
 func (*KashdMessage_GetCoinSupplyResponse) isKashdMessage_Payload() {}
 
+func (*KashdMessage_GetLogLevelsRequest) isKashdMessage_Payload() {}
+
+func (*KashdMessage_GetLogLevelsResponse) isKashdMessage_Payload() {}
+
+func (*KashdMessage_SetLogLevelRequest) isKashdMessage_Payload() {}
+
+func (*KashdMessage_SetLogLevelResponse) isKashdMessage_Payload() {}
+
 var File_messages_proto protoreflect.FileDescriptor
 
 var file_messages_proto_rawDesc = []byte{
 	0x0a, 0x0e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x12, 0x09, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x77, 0x69, 0x72, 0x65, 0x1a, 0x09, 0x70, 0x32, 0x70,
 	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x09, 0x72, 0x70, 0x63, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x22, 0xbf, 0x6d, 0x0a, 0x0d, 0x4b, 0x61, 0x73, 0x70, 0x61, 0x64, 0x4d, 0x65, 0x73, 0x73,
+	0x6f, 0x22, 0xaf, 0x70, 0x0a, 0x0d, 0x4b, 0x61, 0x73, 0x70, 0x61, 0x64, 0x4d, 0x65, 0x73, 0x73,
 	0x61, 0x67, 0x65, 0x12, 0x3b, 0x0a, 0x09, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x73,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x77, 0x69,
 	0x72, 0x65, 0x2e, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x73, 0x4d, 0x65, 0x73, 0x73,
@@ -2773,6 +2829,29 @@ var file_messages_proto_rawDesc = []byte{
 	0x72, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x69, 0x6e, 0x53, 0x75, 0x70, 0x70, 0x6c, 0x79,
 	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x48,
 	0x00, 0x52, 0x15, 0x67, 0x65, 0x74, 0x43, 0x6f, 0x69, 0x6e, 0x53, 0x75, 0x70, 0x70, 0x6c, 0x79,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5a, 0x0a, 0x13, 0x67, 0x65, 0x74, 0x4c,
+	0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18,
+	0xc0, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x77, 0x69,
+	0x72, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x48, 0x00, 0x52,
+	0x13, 0x67, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x5d, 0x0a, 0x14, 0x67, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65,
+	0x76, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0xc1, 0x08, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x77, 0x69, 0x72, 0x65, 0x2e,
+	0x47, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x48, 0x00, 0x52, 0x14, 0x67,
+	0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x57, 0x0a, 0x12, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76,
+	0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0xc2, 0x08, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x24, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x77, 0x69, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x74,
+	0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x48, 0x00, 0x52, 0x12, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x67,
+	0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x5a, 0x0a, 0x13,
+	0x73, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x18, 0xc3, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x77, 0x69, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76,
+	0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x48, 0x00, 0x52, 0x13, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c,
 	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c,
 	0x6f, 0x61, 0x64, 0x32, 0x50, 0x0a, 0x03, 0x50, 0x32, 0x50, 0x12, 0x49, 0x0a, 0x0d, 0x4d, 0x65,
 	0x73, 0x73, 0x61, 0x67, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x18, 0x2e, 0x70, 0x72,
@@ -2934,6 +3013,10 @@ var file_messages_proto_goTypes = []interface{}{
 	(*GetMempoolEntriesByAddressesResponseMessage)(nil),                // 127: protowire.GetMempoolEntriesByAddressesResponseMessage
 	(*GetCoinSupplyRequestMessage)(nil),                                // 128: protowire.GetCoinSupplyRequestMessage
 	(*GetCoinSupplyResponseMessage)(nil),                               // 129: protowire.GetCoinSupplyResponseMessage
+	(*GetLogLevelsRequestMessage)(nil),                                 // 130: protowire.GetLogLevelsRequestMessage
+	(*GetLogLevelsResponseMessage)(nil),                                // 131: protowire.GetLogLevelsResponseMessage
+	(*SetLogLevelRequestMessage)(nil),                                  // 132: protowire.SetLogLevelRequestMessage
+	(*SetLogLevelResponseMessage)(nil),                                 // 133: protowire.SetLogLevelResponseMessage
 }
 var file_messages_proto_depIdxs = []int32{
 	1,   // 0: protowire.KashdMessage.addresses:type_name -> protowire.AddressesMessage
@@ -3066,15 +3149,19 @@ var file_messages_proto_depIdxs = []int32{
 	127, // 127: protowire.KashdMessage.getMempoolEntriesByAddressesResponse:type_name -> protowire.GetMempoolEntriesByAddressesResponseMessage
 	128, // 128: protowire.KashdMessage.getCoinSupplyRequest:type_name -> protowire.GetCoinSupplyRequestMessage
 	129, // 129: protowire.KashdMessage.getCoinSupplyResponse:type_name -> protowire.GetCoinSupplyResponseMessage
-	0,   // 130: protowire.P2P.MessageStream:input_type -> protowire.KashdMessage
-	0,   // 131: protowire.RPC.MessageStream:input_type -> protowire.KashdMessage
-	0,   // 132: protowire.P2P.MessageStream:output_type -> protowire.KashdMessage
-	0,   // 133: protowire.RPC.MessageStream:output_type -> protowire.KashdMessage
-	132, // [132:134] is the sub-list for method output_type
-	130, // [130:132] is the sub-list for method input_type
-	130, // [130:130] is the sub-list for extension type_name
-	130, // [130:130] is the sub-list for extension extendee
-	0,   // [0:130] is the sub-list for field type_name
+	130, // 130: protowire.KashdMessage.getLogLevelsRequest:type_name -> protowire.GetLogLevelsRequestMessage
+	131, // 131: protowire.KashdMessage.getLogLevelsResponse:type_name -> protowire.GetLogLevelsResponseMessage
+	132, // 132: protowire.KashdMessage.setLogLevelRequest:type_name -> protowire.SetLogLevelRequestMessage
+	133, // 133: protowire.KashdMessage.setLogLevelResponse:type_name -> protowire.SetLogLevelResponseMessage
+	0,   // 134: protowire.P2P.MessageStream:input_type -> protowire.KashdMessage
+	0,   // 135: protowire.RPC.MessageStream:input_type -> protowire.KashdMessage
+	0,   // 136: protowire.P2P.MessageStream:output_type -> protowire.KashdMessage
+	0,   // 137: protowire.RPC.MessageStream:output_type -> protowire.KashdMessage
+	136, // [136:138] is the sub-list for method output_type
+	134, // [134:136] is the sub-list for method input_type
+	134, // [134:134] is the sub-list for extension type_name
+	134, // [134:134] is the sub-list for extension extendee
+	0,   // [0:134] is the sub-list for field type_name
 }
 
 func init() { file_messages_proto_init() }
@@ -3229,6 +3316,10 @@ func file_messages_proto_init() {
 		(*KashdMessage_GetMempoolEntriesByAddressesResponse)(nil),
 		(*KashdMessage_GetCoinSupplyRequest)(nil),
 		(*KashdMessage_GetCoinSupplyResponse)(nil),
+		(*KashdMessage_GetLogLevelsRequest)(nil),
+		(*KashdMessage_GetLogLevelsResponse)(nil),
+		(*KashdMessage_SetLogLevelRequest)(nil),
+		(*KashdMessage_SetLogLevelResponse)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
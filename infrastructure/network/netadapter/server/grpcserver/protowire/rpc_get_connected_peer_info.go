@@ -37,6 +37,8 @@ func (x *KashdMessage_GetConnectedPeerInfoResponse) fromAppMessage(message *appm
 			AdvertisedProtocolVersion: info.AdvertisedProtocolVersion,
 			TimeConnected:             info.TimeConnected,
 			IsIbdPeer:                 info.IsIBDPeer,
+			TransportSecurity:         info.TransportSecurity,
+			IdentityKey:               info.IdentityKey,
 		}
 	}
 	x.GetConnectedPeerInfoResponse = &GetConnectedPeerInfoResponseMessage{
@@ -88,5 +90,7 @@ func (x *GetConnectedPeerInfoMessage) toAppMessage() (*appmessage.GetConnectedPe
 		AdvertisedProtocolVersion: x.AdvertisedProtocolVersion,
 		TimeConnected:             x.TimeOffset,
 		IsIBDPeer:                 x.IsIbdPeer,
+		TransportSecurity:         x.TransportSecurity,
+		IdentityKey:               x.IdentityKey,
 	}, nil
 }
@@ -0,0 +1,130 @@
+package protowire
+
+import (
+	"github.com/Kash-Protocol/kashd/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KashdMessage_GetLogLevelsRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KashdMessage_GetLogLevelsRequest is nil")
+	}
+	return &appmessage.GetLogLevelsRequestMessage{}, nil
+}
+
+func (x *KashdMessage_GetLogLevelsRequest) fromAppMessage(_ *appmessage.GetLogLevelsRequestMessage) error {
+	x.GetLogLevelsRequest = &GetLogLevelsRequestMessage{}
+	return nil
+}
+
+func (x *KashdMessage_GetLogLevelsResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KashdMessage_GetLogLevelsResponse is nil")
+	}
+	return x.GetLogLevelsResponse.toAppMessage()
+}
+
+func (x *GetLogLevelsResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetLogLevelsResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+
+	subsystems := make([]*appmessage.SubsystemLogLevel, len(x.Subsystems))
+	for i, subsystem := range x.Subsystems {
+		subsystems[i], err = subsystem.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &appmessage.GetLogLevelsResponseMessage{
+		Subsystems: subsystems,
+		Error:      rpcErr,
+	}, nil
+}
+
+func (x *KashdMessage_GetLogLevelsResponse) fromAppMessage(message *appmessage.GetLogLevelsResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	subsystems := make([]*SubsystemLogLevel, len(message.Subsystems))
+	for i, subsystem := range message.Subsystems {
+		subsystems[i] = &SubsystemLogLevel{
+			Subsystem: subsystem.Subsystem,
+			Level:     subsystem.Level,
+		}
+	}
+	x.GetLogLevelsResponse = &GetLogLevelsResponseMessage{
+		Subsystems: subsystems,
+		Error:      err,
+	}
+	return nil
+}
+
+func (x *SubsystemLogLevel) toAppMessage() (*appmessage.SubsystemLogLevel, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SubsystemLogLevel is nil")
+	}
+	return &appmessage.SubsystemLogLevel{
+		Subsystem: x.Subsystem,
+		Level:     x.Level,
+	}, nil
+}
+
+func (x *KashdMessage_SetLogLevelRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KashdMessage_SetLogLevelRequest is nil")
+	}
+	return x.SetLogLevelRequest.toAppMessage()
+}
+
+func (x *SetLogLevelRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SetLogLevelRequestMessage is nil")
+	}
+	return &appmessage.SetLogLevelRequestMessage{
+		LogLevel: x.LogLevel,
+	}, nil
+}
+
+func (x *KashdMessage_SetLogLevelRequest) fromAppMessage(message *appmessage.SetLogLevelRequestMessage) error {
+	x.SetLogLevelRequest = &SetLogLevelRequestMessage{LogLevel: message.LogLevel}
+	return nil
+}
+
+func (x *KashdMessage_SetLogLevelResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KashdMessage_SetLogLevelResponse is nil")
+	}
+	return x.SetLogLevelResponse.toAppMessage()
+}
+
+func (x *SetLogLevelResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "SetLogLevelResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+	return &appmessage.SetLogLevelResponseMessage{
+		Error: rpcErr,
+	}, nil
+}
+
+func (x *KashdMessage_SetLogLevelResponse) fromAppMessage(message *appmessage.SetLogLevelResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.SetLogLevelResponse = &SetLogLevelResponseMessage{
+		Error: err,
+	}
+	return nil
+}
@@ -968,6 +968,34 @@ func toRPCPayload(message appmessage.Message) (isKashdMessage_Payload, error) {
 			return nil, err
 		}
 		return payload, nil
+	case *appmessage.GetLogLevelsRequestMessage:
+		payload := new(KashdMessage_GetLogLevelsRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.GetLogLevelsResponseMessage:
+		payload := new(KashdMessage_GetLogLevelsResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.SetLogLevelRequestMessage:
+		payload := new(KashdMessage_SetLogLevelRequest)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case *appmessage.SetLogLevelResponseMessage:
+		payload := new(KashdMessage_SetLogLevelResponse)
+		err := payload.fromAppMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
 	default:
 		return nil, nil
 	}
@@ -2,8 +2,8 @@ package server
 
 import (
 	"fmt"
-	"net"
 
+	"github.com/Kash-Protocol/kashd/app/appmessage"
 	"github.com/Kash-Protocol/kashd/infrastructure/network/netadapter/router"
 )
 
@@ -20,6 +20,19 @@ type OnDisconnectedHandler func()
 // was received from a connection.
 type OnInvalidMessageHandler func(err error)
 
+// TransportSecurity describes how the traffic of a Connection is protected.
+type TransportSecurity string
+
+const (
+	// TransportSecurityPlaintext means that the connection is neither encrypted nor authenticated.
+	// This is what legacy peers that predate P2P encryption fall back to.
+	TransportSecurityPlaintext TransportSecurity = "plaintext"
+
+	// TransportSecurityTLS means that the connection is encrypted with TLS 1.3 using an
+	// ephemeral key exchange, and that the remote peer proved ownership of its identity key.
+	TransportSecurityTLS TransportSecurity = "tls"
+)
+
 // Server represents a server.
 type Server interface {
 	Start() error
@@ -42,5 +55,7 @@ type Connection interface {
 	IsOutbound() bool
 	SetOnDisconnectedHandler(onDisconnectedHandler OnDisconnectedHandler)
 	SetOnInvalidMessageHandler(onInvalidMessageHandler OnInvalidMessageHandler)
-	Address() *net.TCPAddr
+	Address() *appmessage.NetAddress
+	TransportSecurity() TransportSecurity
+	RemoteIdentityKey() []byte
 }
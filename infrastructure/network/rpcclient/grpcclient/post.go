@@ -62,3 +62,13 @@ func (c *GRPCClient) Post(request *protowire.KashdMessage) (*protowire.KashdMess
 	}
 	return response, nil
 }
+
+// Receive waits for the next message that the RPC server sends
+// without a matching request, such as a notification, and returns it
+func (c *GRPCClient) Receive() (*protowire.KashdMessage, error) {
+	message, err := c.stream.Recv()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error receiving a message from the RPC server")
+	}
+	return message, nil
+}
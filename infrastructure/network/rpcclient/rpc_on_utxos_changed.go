@@ -38,3 +38,22 @@ func (c *RPCClient) RegisterForUTXOsChangedNotifications(addresses []string,
 	})
 	return nil
 }
+
+// AddUTXOsChangedNotificationAddresses sends an RPC request that adds the given addresses to the ones
+// the RPC server sends UTXOsChanged notifications for. The notifications are delivered to the handler
+// given to a previous call to RegisterForUTXOsChangedNotifications.
+func (c *RPCClient) AddUTXOsChangedNotificationAddresses(addresses []string) error {
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewNotifyUTXOsChangedRequestMessage(addresses))
+	if err != nil {
+		return err
+	}
+	response, err := c.route(appmessage.CmdNotifyUTXOsChangedResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return err
+	}
+	notifyUTXOsChangedResponse := response.(*appmessage.NotifyUTXOsChangedResponseMessage)
+	if notifyUTXOsChangedResponse.Error != nil {
+		return c.convertRPCError(notifyUTXOsChangedResponse.Error)
+	}
+	return nil
+}
@@ -25,6 +25,7 @@ type RPCClient struct {
 	isClosed             uint32
 	isReconnecting       uint32
 	lastDisconnectedTime time.Time
+	onReconnectedHandler func()
 
 	timeout time.Duration
 }
@@ -118,6 +119,9 @@ func (c *RPCClient) Reconnect() error {
 		if time.Since(c.lastDisconnectedTime) > retryDelay {
 			err := c.connect()
 			if err == nil {
+				if c.onReconnectedHandler != nil {
+					spawn("RPCClient.onReconnectedHandler", c.onReconnectedHandler)
+				}
 				return nil
 			}
 			log.Warnf("Could not automatically reconnect to %s: %s", c.rpcAddress, err)
@@ -150,6 +154,13 @@ func (c *RPCClient) handleClientError(err error) {
 	c.handleClientDisconnected()
 }
 
+// SetOnReconnectedHandler sets a handler function that is called after the client
+// reconnects. Notification registrations don't survive a reconnect, so this is the
+// place to register for them again.
+func (c *RPCClient) SetOnReconnectedHandler(onReconnectedHandler func()) {
+	c.onReconnectedHandler = onReconnectedHandler
+}
+
 // SetTimeout sets the timeout by which to wait for RPC responses
 func (c *RPCClient) SetTimeout(timeout time.Duration) {
 	c.timeout = timeout
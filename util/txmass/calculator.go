@@ -2,7 +2,9 @@ package txmass
 
 import (
 	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
 	"github.com/Kash-Protocol/kashd/domain/consensus/utils/transactionhelper"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
 )
 
 // Calculator exposes methods to calculate the mass of a transaction
@@ -10,14 +12,16 @@ type Calculator struct {
 	massPerTxByte           uint64
 	massPerScriptPubKeyByte uint64
 	massPerSigOp            uint64
+	massPerSpliceOp         uint64
 }
 
 // NewCalculator creates a new instance of Calculator
-func NewCalculator(massPerTxByte, massPerScriptPubKeyByte, massPerSigOp uint64) *Calculator {
+func NewCalculator(massPerTxByte, massPerScriptPubKeyByte, massPerSigOp, massPerSpliceOp uint64) *Calculator {
 	return &Calculator{
 		massPerTxByte:           massPerTxByte,
 		massPerScriptPubKeyByte: massPerScriptPubKeyByte,
 		massPerSigOp:            massPerSigOp,
+		massPerSpliceOp:         massPerSpliceOp,
 	}
 }
 
@@ -30,6 +34,9 @@ func (c *Calculator) MassPerScriptPubKeyByte() uint64 { return c.massPerScriptPu
 // MassPerSigOp returns the mass per SigOp byte configured for this Calculator
 func (c *Calculator) MassPerSigOp() uint64 { return c.massPerSigOp }
 
+// MassPerSpliceOp returns the mass per OP_CAT or OP_SUBSTR configured for this Calculator
+func (c *Calculator) MassPerSpliceOp() uint64 { return c.massPerSpliceOp }
+
 // CalculateTransactionMass calculates the mass of the given transaction
 func (c *Calculator) CalculateTransactionMass(transaction *externalapi.DomainTransaction) uint64 {
 	if transactionhelper.IsCoinBase(transaction) {
@@ -55,8 +62,51 @@ func (c *Calculator) CalculateTransactionMass(transaction *externalapi.DomainTra
 	}
 	massForSigOps := totalSigOpCount * c.massPerSigOp
 
+	// calculate mass for splice operations
+	massForSpliceOps := outputsSpliceOpCount(transaction) * c.massPerSpliceOp
+
 	// Sum all components of mass
-	return massForSize + massForScriptPubKey + massForSigOps
+	return massForSize + massForScriptPubKey + massForSigOps + massForSpliceOps
+}
+
+// CalculateRedeemScriptsSpliceOpMass calculates the mass of the OP_CAT and OP_SUBSTR
+// operations in the redeem scripts executed by the given transaction. Unlike the rest
+// of the transaction mass, it depends on the UTXO entries the transaction spends, so
+// the transaction must be populated with them.
+func (c *Calculator) CalculateRedeemScriptsSpliceOpMass(transaction *externalapi.DomainTransaction) uint64 {
+	if transaction.Version < constants.CovenantsTransactionVersion {
+		return 0
+	}
+
+	spliceOpCount := 0
+	for _, input := range transaction.Inputs {
+		if !txscript.IsPayToScriptHash(input.UTXOEntry.ScriptPublicKey()) {
+			continue
+		}
+		// The redeem script of a pay-to-script-hash input is the last data pushed by its signature script
+		pushedData, err := txscript.PushedData(input.SignatureScript)
+		if err != nil || len(pushedData) == 0 {
+			continue
+		}
+		spliceOpCount += txscript.GetSpliceOpCount(pushedData[len(pushedData)-1])
+	}
+	return uint64(spliceOpCount) * c.massPerSpliceOp
+}
+
+// outputsSpliceOpCount returns the number of OP_CAT and OP_SUBSTR operations in the
+// script public keys of the outputs of a covenants transaction. Splice ops are only
+// enabled for covenants transactions, so the mass of other transaction versions is
+// left unchanged.
+func outputsSpliceOpCount(transaction *externalapi.DomainTransaction) uint64 {
+	if transaction.Version < constants.CovenantsTransactionVersion {
+		return 0
+	}
+
+	spliceOpCount := 0
+	for _, output := range transaction.Outputs {
+		spliceOpCount += txscript.GetSpliceOpCount(output.ScriptPublicKey.Script)
+	}
+	return uint64(spliceOpCount)
 }
 
 // transactionEstimatedSerializedSize is the estimated size of a transaction in some
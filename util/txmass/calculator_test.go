@@ -0,0 +1,131 @@
+package txmass
+
+import (
+	"testing"
+
+	"github.com/Kash-Protocol/kashd/domain/consensus/model/externalapi"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/constants"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/subnetworks"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/txscript"
+	"github.com/Kash-Protocol/kashd/domain/consensus/utils/utxo"
+)
+
+func TestCalculateTransactionMassSpliceOps(t *testing.T) {
+	spliceScript := []byte{txscript.OpCat, txscript.OpSubStr, txscript.OpTrue}
+	redeemSignatureScript, err := txscript.NewScriptBuilder().AddData(spliceScript).Script()
+	if err != nil {
+		t.Fatalf("Script: %+v", err)
+	}
+	payToScriptHashScript, err := txscript.PayToScriptHashScript(spliceScript)
+	if err != nil {
+		t.Fatalf("PayToScriptHashScript: %+v", err)
+	}
+	payToPubKeyScript := make([]byte, 0, 34)
+	payToPubKeyScript = append(payToPubKeyScript, txscript.OpData32)
+	payToPubKeyScript = append(payToPubKeyScript, make([]byte, 32)...)
+	payToPubKeyScript = append(payToPubKeyScript, txscript.OpCheckSig)
+
+	tests := []struct {
+		name                        string
+		version                     uint16
+		signatureScript             []byte
+		spentScriptPublicKey        []byte
+		scriptPublicKey             []byte
+		expectedSpliceOpCount       uint64
+		expectedRedeemSpliceOpCount uint64
+	}{
+		{
+			name:                  "splice ops in the output of a version 0 transaction",
+			version:               constants.MaxTransactionVersion,
+			spentScriptPublicKey:  payToPubKeyScript,
+			scriptPublicKey:       spliceScript,
+			expectedSpliceOpCount: 0,
+		},
+		{
+			name:                  "splice ops in the output of a covenants transaction",
+			version:               constants.CovenantsTransactionVersion,
+			spentScriptPublicKey:  payToPubKeyScript,
+			scriptPublicKey:       spliceScript,
+			expectedSpliceOpCount: 2,
+		},
+		{
+			name:                        "splice ops in the redeem script of a version 0 transaction",
+			version:                     constants.MaxTransactionVersion,
+			signatureScript:             redeemSignatureScript,
+			spentScriptPublicKey:        payToScriptHashScript,
+			expectedRedeemSpliceOpCount: 0,
+		},
+		{
+			name:                        "splice ops in the redeem script of a covenants transaction",
+			version:                     constants.CovenantsTransactionVersion,
+			signatureScript:             redeemSignatureScript,
+			spentScriptPublicKey:        payToScriptHashScript,
+			expectedRedeemSpliceOpCount: 2,
+		},
+		{
+			name:                        "splice ops in the last push of a covenants transaction that doesn't spend a script hash",
+			version:                     constants.CovenantsTransactionVersion,
+			signatureScript:             redeemSignatureScript,
+			spentScriptPublicKey:        payToPubKeyScript,
+			expectedRedeemSpliceOpCount: 0,
+		},
+	}
+
+	const massPerSpliceOp = 100
+	calculator := NewCalculator(1, 10, 1000, massPerSpliceOp)
+	calculatorWithoutSpliceMass := NewCalculator(1, 10, 1000, 0)
+	for _, test := range tests {
+		tx := &externalapi.DomainTransaction{
+			Version: test.version,
+			Inputs: []*externalapi.DomainTransactionInput{{
+				SignatureScript: test.signatureScript,
+				UTXOEntry: utxo.NewUTXOEntry(1, &externalapi.ScriptPublicKey{Script: test.spentScriptPublicKey},
+					false, 0),
+			}},
+			Outputs: []*externalapi.DomainTransactionOutput{{
+				Value:           1,
+				ScriptPublicKey: &externalapi.ScriptPublicKey{Script: test.scriptPublicKey},
+			}},
+			SubnetworkID: subnetworks.SubnetworkIDNative,
+		}
+
+		massForSpliceOps := calculator.CalculateTransactionMass(tx) - calculatorWithoutSpliceMass.CalculateTransactionMass(tx)
+		if massForSpliceOps != test.expectedSpliceOpCount*massPerSpliceOp {
+			t.Errorf("%s: expected splice op mass %d but got %d",
+				test.name, test.expectedSpliceOpCount*massPerSpliceOp, massForSpliceOps)
+		}
+
+		redeemScriptsSpliceOpMass := calculator.CalculateRedeemScriptsSpliceOpMass(tx)
+		if redeemScriptsSpliceOpMass != test.expectedRedeemSpliceOpCount*massPerSpliceOp {
+			t.Errorf("%s: expected redeem scripts splice op mass %d but got %d",
+				test.name, test.expectedRedeemSpliceOpCount*massPerSpliceOp, redeemScriptsSpliceOpMass)
+		}
+	}
+}
+
+func TestCalculateTransactionMassVersionZeroUnchanged(t *testing.T) {
+	spliceScript := []byte{txscript.OpCat, txscript.OpSubStr, txscript.OpTrue}
+	tx := &externalapi.DomainTransaction{
+		Version: 0,
+		Inputs: []*externalapi.DomainTransactionInput{{
+			SignatureScript: []byte{txscript.OpData3, txscript.OpCat, txscript.OpSubStr, txscript.OpTrue},
+			SigOpCount:      1,
+		}},
+		Outputs: []*externalapi.DomainTransactionOutput{{
+			Value:           1,
+			ScriptPublicKey: &externalapi.ScriptPublicKey{Script: spliceScript},
+		}},
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+	}
+
+	// The mass of a version 0 transaction is the mass of its size, its script public keys and its sig ops,
+	// regardless of the splice ops in its scripts
+	expectedSize := transactionEstimatedSerializedSize(tx)
+	expectedScriptPublicKeySize := uint64(2 + len(spliceScript))
+	expectedMass := expectedSize*1 + expectedScriptPublicKeySize*10 + 1*1000
+
+	mass := NewCalculator(1, 10, 1000, 100).CalculateTransactionMass(tx)
+	if mass != expectedMass {
+		t.Fatalf("Expected the mass of the version 0 transaction to be %d, but got %d", expectedMass, mass)
+	}
+}